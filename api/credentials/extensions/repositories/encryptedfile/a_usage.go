@@ -0,0 +1,59 @@
+package encryptedfile
+
+import (
+	"ocm.software/ocm/api/utils/listformat"
+)
+
+var usage = `
+This repository type can be used to access credentials stored in a YAML file
+with encrypted credential values. Every entry of the file describes a consumer
+identity pattern and/or a name together with the credential properties.
+Only the property values are encrypted, so the file can be inspected, diffed
+and kept under version control without revealing the secrets.
+
+The values are encrypted with AES-GCM. The name, the consumer identity and the
+property name of an entry are authenticated together with a value, so that
+encrypted values cannot be moved to other entries unnoticed. The key is either given directly
+(method <code>key</code>, a PEM file with an <code>ENCRYPTION KEY</code> block)
+or derived from a passphrase using scrypt (method <code>passphrase</code>).
+The secret is taken from the key or passphrase file configured for the
+repository or from the credentials provided for the repository (attribute
+<code>` + ATTR_KEY + `</code> or <code>` + ATTR_PASSPHRASE + `</code>).
+It is resolved and the values are decrypted not before credentials are
+requested.
+
+If enabled, the credentials are assigned to the consumer identities
+described by the entries. The identity matcher of the requested consumer
+type is used to evaluate the identity patterns.
+
+The file has the following format:
+
+<pre>
+encryption:
+  method: passphrase
+  salt: &lt;base64 encoded salt>
+  check: ENC[&lt;base64 encoded encrypted check value>]
+entries:
+- name: ghcr
+  consumer:
+    type: OCIRegistry
+    hostname: ghcr.io
+  credentials:
+    username: ENC[...]
+    password: ENC[...]
+</pre>
+
+Values not in the form <code>ENC[...]</code> are rejected, unless the
+repository is configured to allow plain values.
+Such files can be created and maintained with the commands
+<code>ocm add credentials</code> and <code>ocm rotate credentials</code>.
+`
+
+var format = `The repository specification supports the following fields:
+` + listformat.FormatListElements("", listformat.StringElementDescriptionList{
+	"path", "*string*: the file path to the encrypted credentials file",
+	"keyFile", "*string*(optional): the path to the PEM file containing the encryption key",
+	"passphraseFile", "*string*(optional): the path to a file containing the passphrase",
+	"propagateConsumerIdentity", "*bool*(optional): enable consumer id propagation (default true)",
+	"allowPlainValues", "*bool*(optional): accept credential values not in encrypted form (default false)",
+})
@@ -0,0 +1,65 @@
+package encryptedfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/datacontext"
+)
+
+const ATTR_REPOS = "ocm.software/ocm/api/credentials/extensions/repositories/encryptedfile"
+
+type Repositories struct {
+	lock  sync.Mutex
+	repos map[string]*Repository
+}
+
+func newRepositories(datacontext.Context) interface{} {
+	return &Repositories{
+		repos: map[string]*Repository{},
+	}
+}
+
+func (r *Repositories) GetRepository(ctx cpi.Context, spec *RepositorySpec, creds cpi.Credentials) (*Repository, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	key, err := repositoryKey(spec, creds)
+	if err != nil {
+		return nil, err
+	}
+	repo := r.repos[key]
+	if repo == nil {
+		repo, err = NewRepository(ctx, spec, creds)
+		if err != nil {
+			return nil, err
+		}
+		r.repos[key] = repo
+	}
+	return repo, nil
+}
+
+// repositoryKey provides the identity of a repository.
+// All fields of the spec and the credentials used to provide the
+// secret influence the repository behaviour, therefore all of them
+// are used. The credentials are only included as hash.
+func repositoryKey(spec *RepositorySpec, creds cpi.Credentials) (string, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(data)
+	if creds != nil {
+		data, err = json.Marshal(creds.Properties())
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte{0})
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,430 @@
+package encryptedfile
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	"golang.org/x/crypto/scrypt"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/utils"
+	"ocm.software/ocm/api/utils/encrypt"
+	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+const (
+	// METHOD_KEY uses an AES key (PEM block ENCRYPTION KEY) to encrypt
+	// the credential values.
+	METHOD_KEY = "key"
+	// METHOD_PASSPHRASE derives an AES-256 key from a passphrase
+	// using scrypt and a random salt stored in the file.
+	METHOD_PASSPHRASE = "passphrase"
+)
+
+const (
+	// ATTR_PASSPHRASE is the repository credential attribute providing
+	// the passphrase for METHOD_PASSPHRASE.
+	ATTR_PASSPHRASE = "passphrase"
+	// ATTR_KEY is the repository credential attribute providing the
+	// (PEM encoded) key for METHOD_KEY.
+	ATTR_KEY = cpi.ATTR_KEY
+)
+
+const (
+	ENC_PREFIX = "ENC["
+	ENC_SUFFIX = "]"
+)
+
+// scrypt parameters used to derive keys from passphrases.
+const (
+	scryptN   = 1 << 15
+	scryptR   = 8
+	scryptP   = 1
+	saltLen   = 16
+	checkText = "ocm.software/" + Type
+)
+
+// Secret describes the secret used to access the credential
+// values. Either a key or a passphrase has to be given, according
+// to the encryption method of a file.
+type Secret struct {
+	Key        []byte
+	Passphrase string
+}
+
+func (s *Secret) IsEmpty() bool {
+	return s == nil || (len(s.Key) == 0 && s.Passphrase == "")
+}
+
+// Method returns the encryption method matching the secret.
+func (s *Secret) Method() string {
+	if len(s.Key) != 0 {
+		return METHOD_KEY
+	}
+	return METHOD_PASSPHRASE
+}
+
+// ReadSecret provides a secret from a key file or passphrase file.
+func ReadSecret(keyfile, passphrasefile string, fss ...vfs.FileSystem) (*Secret, error) {
+	if keyfile != "" && passphrasefile != "" {
+		return nil, errors.Newf("only key file or passphrase file possible")
+	}
+	if keyfile != "" {
+		data, err := utils.ReadFile(keyfile, fss...)
+		if err != nil {
+			return nil, err
+		}
+		key, err := encrypt.KeyFromAny(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "key file %q", keyfile)
+		}
+		return &Secret{Key: key}, nil
+	}
+	if passphrasefile != "" {
+		data, err := utils.ReadFile(passphrasefile, fss...)
+		if err != nil {
+			return nil, err
+		}
+		return &Secret{Passphrase: strings.TrimRight(string(data), "\r\n")}, nil
+	}
+	return nil, nil
+}
+
+// SecretForCredentials provides a secret from repository credentials.
+func SecretForCredentials(creds cpi.Credentials) (*Secret, error) {
+	if creds == nil {
+		return nil, nil
+	}
+	if creds.ExistsProperty(ATTR_KEY) {
+		key, err := encrypt.KeyFromAny([]byte(creds.GetProperty(ATTR_KEY)))
+		if err != nil {
+			return nil, err
+		}
+		return &Secret{Key: key}, nil
+	}
+	if creds.ExistsProperty(ATTR_PASSPHRASE) {
+		return &Secret{Passphrase: creds.GetProperty(ATTR_PASSPHRASE)}, nil
+	}
+	return nil, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Encryption describes the encryption used for the credential values.
+type Encryption struct {
+	// Method is the encryption method (key or passphrase).
+	Method string `json:"method"`
+	// Salt is the salt used to derive the key from a passphrase.
+	Salt []byte `json:"salt,omitempty"`
+	// Check is an encrypted constant used to validate a given secret.
+	Check string `json:"check,omitempty"`
+}
+
+// Entry describes the credentials for a consumer identity pattern.
+// The credential values are kept encrypted until they are requested.
+type Entry struct {
+	// Name is an optional name used to look up the credentials
+	// by name.
+	Name string `json:"name,omitempty"`
+	// Consumer is the consumer identity pattern the credentials
+	// are provided for.
+	Consumer cpi.ConsumerIdentity `json:"consumer,omitempty"`
+	// Credentials are the (encrypted) credential properties.
+	Credentials map[string]string `json:"credentials"`
+}
+
+// Decrypt provides the clear text credential properties.
+// Values not in encrypted form are rejected, unless explicitly allowed.
+func (e *Entry) Decrypt(key []byte, plain ...bool) (common.Properties, error) {
+	props := common.Properties{}
+	for n, v := range e.Credentials {
+		ad, err := e.associatedData(n)
+		if err != nil {
+			return nil, err
+		}
+		d, err := DecryptValue(key, v, ad, utils.Optional(plain...))
+		if err != nil {
+			return nil, errors.Wrapf(err, "credential property %q", n)
+		}
+		props[n] = d
+	}
+	return props, nil
+}
+
+// associatedData provides the data authenticated together with an encrypted
+// property value. It binds the value to the consumer identity, the name
+// and the property of the entry, so that encrypted values cannot
+// be moved to other entries or properties unnoticed.
+func (e *Entry) associatedData(prop string) ([]byte, error) {
+	return json.Marshal(struct {
+		Name     string               `json:"name,omitempty"`
+		Consumer cpi.ConsumerIdentity `json:"consumer,omitempty"`
+		Property string               `json:"property"`
+	}{e.Name, e.Consumer, prop})
+}
+
+func (e *Entry) encrypt(key []byte, props common.Properties) error {
+	enc := map[string]string{}
+	for n, v := range props {
+		ad, err := e.associatedData(n)
+		if err != nil {
+			return err
+		}
+		enc[n], err = EncryptValue(key, v, ad)
+		if err != nil {
+			return err
+		}
+	}
+	e.Credentials = enc
+	return nil
+}
+
+// File is the content of an encrypted credentials file.
+type File struct {
+	Encryption Encryption `json:"encryption"`
+	Entries    []*Entry   `json:"entries,omitempty"`
+}
+
+// NewFile creates a new empty file using the encryption
+// method matching the given secret. It returns the key
+// to use for the file.
+func NewFile(secret *Secret) (*File, []byte, error) {
+	if secret.IsEmpty() {
+		return nil, nil, errors.Newf("secret required")
+	}
+	f := &File{}
+	key, err := f.setSecret(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, key, nil
+}
+
+// ParseFile parses the YAML or JSON representation of an encrypted credentials file.
+func ParseFile(data []byte) (*File, error) {
+	var f File
+	err := runtime.DefaultYAMLEncoding.Unmarshal(data, &f)
+	if err != nil {
+		return nil, err
+	}
+	switch f.Encryption.Method {
+	case METHOD_KEY, METHOD_PASSPHRASE:
+	default:
+		return nil, errors.ErrUnknown("encryption method", f.Encryption.Method)
+	}
+	return &f, nil
+}
+
+// ReadFile reads an encrypted credentials file.
+func ReadFile(path string, fss ...vfs.FileSystem) (*File, error) {
+	data, err := vfs.ReadFile(utils.FileSystem(fss...), path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := ParseFile(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid credentials file %q", path)
+	}
+	return f, nil
+}
+
+// WriteFile writes the file content readable only for the owner.
+func (f *File) WriteFile(path string, fss ...vfs.FileSystem) error {
+	data, err := runtime.DefaultYAMLEncoding.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return vfs.WriteFile(utils.FileSystem(fss...), path, data, 0o600)
+}
+
+// Key provides the key for the credential values, if the given
+// secret matches the encryption settings of the file.
+func (f *File) Key(secret *Secret) ([]byte, error) {
+	if secret.IsEmpty() {
+		return nil, errors.Newf("secret required for encrypted credentials")
+	}
+	key, err := deriveKey(&f.Encryption, secret)
+	if err != nil {
+		return nil, err
+	}
+	if f.Encryption.Check != "" {
+		c, err := DecryptValue(key, f.Encryption.Check, nil, false)
+		if err != nil || c != checkText {
+			return nil, errors.Newf("secret does not match encrypted credentials")
+		}
+	}
+	return key, nil
+}
+
+// Lookup provides the entry for the given name.
+func (f *File) Lookup(name string) *Entry {
+	for _, e := range f.Entries {
+		if e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// Find provides the entry for the given consumer identity pattern.
+func (f *File) Find(id cpi.ConsumerIdentity) *Entry {
+	for _, e := range f.Entries {
+		if len(e.Consumer) > 0 && e.Consumer.Equals(id) {
+			return e
+		}
+	}
+	return nil
+}
+
+// Set encrypts the given credential properties and adds
+// or replaces the entry for the given name or consumer identity.
+func (f *File) Set(key []byte, name string, id cpi.ConsumerIdentity, props common.Properties) error {
+	if name == "" && len(id) == 0 {
+		return errors.Newf("name or consumer identity required")
+	}
+	entry := &Entry{
+		Name:     name,
+		Consumer: id,
+	}
+	err := entry.encrypt(key, props)
+	if err != nil {
+		return err
+	}
+	for i, e := range f.Entries {
+		if (name != "" && e.Name == name) || (len(id) > 0 && e.Consumer.Equals(id)) {
+			f.Entries[i] = entry
+			return nil
+		}
+	}
+	f.Entries = append(f.Entries, entry)
+	return nil
+}
+
+// Rotate re-encrypts all credential values with a key
+// derived from the given new secret. It returns the new key.
+func (f *File) Rotate(key []byte, secret *Secret) ([]byte, error) {
+	if secret.IsEmpty() {
+		return nil, errors.Newf("new secret required")
+	}
+	var props []common.Properties
+	for _, e := range f.Entries {
+		p, err := e.Decrypt(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "entry %s", e.describe())
+		}
+		props = append(props, p)
+	}
+
+	n := &File{}
+	nkey, err := n.setSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*Entry
+	for i, e := range f.Entries {
+		entry := &Entry{Name: e.Name, Consumer: e.Consumer}
+		err = entry.encrypt(nkey, props[i])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	f.Encryption = n.Encryption
+	f.Entries = entries
+	return nkey, nil
+}
+
+func (f *File) setSecret(secret *Secret) ([]byte, error) {
+	enc := Encryption{Method: secret.Method()}
+	if enc.Method == METHOD_PASSPHRASE {
+		enc.Salt = make([]byte, saltLen)
+		if _, err := io.ReadFull(rand.Reader, enc.Salt); err != nil {
+			return nil, err
+		}
+	}
+	key, err := deriveKey(&enc, secret)
+	if err != nil {
+		return nil, err
+	}
+	enc.Check, err = EncryptValue(key, checkText, nil)
+	if err != nil {
+		return nil, err
+	}
+	f.Encryption = enc
+	return key, nil
+}
+
+func (e *Entry) describe() string {
+	if e.Name != "" {
+		return e.Name
+	}
+	return e.Consumer.String()
+}
+
+func deriveKey(enc *Encryption, secret *Secret) ([]byte, error) {
+	switch enc.Method {
+	case METHOD_KEY:
+		if len(secret.Key) == 0 {
+			return nil, errors.Newf("encryption key required")
+		}
+		if _, err := encrypt.AlgoForKey(secret.Key); err != nil {
+			return nil, err
+		}
+		return secret.Key, nil
+	case METHOD_PASSPHRASE:
+		if secret.Passphrase == "" {
+			return nil, errors.Newf("passphrase required")
+		}
+		return scrypt.Key([]byte(secret.Passphrase), enc.Salt, scryptN, scryptR, scryptP, encrypt.AES_256.KeyLength())
+	default:
+		return nil, errors.ErrUnknown("encryption method", enc.Method)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// IsEncrypted checks whether a value is given in encrypted form.
+func IsEncrypted(v string) bool {
+	return strings.HasPrefix(v, ENC_PREFIX) && strings.HasSuffix(v, ENC_SUFFIX)
+}
+
+// EncryptValue provides the encrypted form (ENC[<base64>]) of a value.
+// The additional data is authenticated, but not encrypted. It must
+// be given again to decrypt the value.
+func EncryptValue(key []byte, v string, ad []byte) (string, error) {
+	data, err := encrypt.EncryptWithData(key, []byte(v), ad)
+	if err != nil {
+		return "", err
+	}
+	return ENC_PREFIX + base64.StdEncoding.EncodeToString(data) + ENC_SUFFIX, nil
+}
+
+// DecryptValue provides the clear text for an encrypted value.
+// Values not in encrypted form are returned as they are, if plain
+// values are allowed. Otherwise, they are rejected.
+func DecryptValue(key []byte, v string, ad []byte, plain bool) (string, error) {
+	if !IsEncrypted(v) {
+		if plain {
+			return v, nil
+		}
+		return "", errors.Newf("value not encrypted")
+	}
+	data, err := base64.StdEncoding.DecodeString(v[len(ENC_PREFIX) : len(v)-len(ENC_SUFFIX)])
+	if err != nil {
+		return "", err
+	}
+	if len(data) < 12 {
+		return "", errors.ErrInvalid("encrypted value")
+	}
+	data, err = encrypt.DecryptWithData(key, data, ad)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
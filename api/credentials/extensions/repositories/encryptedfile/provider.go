@@ -0,0 +1,55 @@
+package encryptedfile
+
+import (
+	"ocm.software/ocm/api/credentials/cpi"
+)
+
+const PROVIDER = "ocm.software/credentialprovider/" + Type
+
+type ConsumerProvider struct {
+	repo *Repository
+}
+
+var _ cpi.ConsumerProvider = (*ConsumerProvider)(nil)
+
+func (p *ConsumerProvider) Unregister(_ cpi.ProviderIdentity) {
+}
+
+func (p *ConsumerProvider) Match(ectx cpi.EvaluationContext, req cpi.ConsumerIdentity, cur cpi.ConsumerIdentity, m cpi.IdentityMatcher) (cpi.CredentialsSource, cpi.ConsumerIdentity) {
+	return p.get(req, cur, m)
+}
+
+func (p *ConsumerProvider) Get(req cpi.ConsumerIdentity) (cpi.CredentialsSource, bool) {
+	creds, _ := p.get(req, nil, cpi.CompleteMatch)
+	return creds, creds != nil
+}
+
+func (p *ConsumerProvider) get(req cpi.ConsumerIdentity, cur cpi.ConsumerIdentity, m cpi.IdentityMatcher) (cpi.CredentialsSource, cpi.ConsumerIdentity) {
+	p.repo.lock.RLock()
+	defer p.repo.lock.RUnlock()
+
+	var creds cpi.CredentialsSource
+	for _, e := range p.repo.file.Entries {
+		if len(e.Consumer) == 0 {
+			continue
+		}
+		if m(req, cur, e.Consumer) {
+			creds = &credentialsSource{p.repo, e}
+			cur = e.Consumer
+		}
+	}
+	return creds, cur
+}
+
+// credentialsSource decrypts the credentials of an entry
+// not before they are requested.
+type credentialsSource struct {
+	repo  *Repository
+	entry *Entry
+}
+
+var _ cpi.CredentialsSource = (*credentialsSource)(nil)
+
+func (c *credentialsSource) Credentials(_ cpi.Context, _ ...cpi.CredentialsSource) (cpi.Credentials, error) {
+	return c.repo.decrypt(c.entry)
+}
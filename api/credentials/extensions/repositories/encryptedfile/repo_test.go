@@ -0,0 +1,227 @@
+package encryptedfile_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/vfs/pkg/memoryfs"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/credentials/cpi"
+	local "ocm.software/ocm/api/credentials/extensions/repositories/encryptedfile"
+	"ocm.software/ocm/api/datacontext/attrs/vfsattr"
+	"ocm.software/ocm/api/tech/oci/identity"
+	"ocm.software/ocm/api/utils/encrypt"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const (
+	CREDS      = "/creds.yaml"
+	KEY        = "/creds.key"
+	PASSPHRASE = "/passphrase"
+)
+
+var _ = Describe("encrypted credentials file", func() {
+	props := common.Properties{
+		cpi.ATTR_USERNAME: "mandelsoft",
+		cpi.ATTR_PASSWORD: "password",
+	}
+	props2 := common.Properties{
+		cpi.ATTR_USERNAME: "mandelsoft",
+		cpi.ATTR_PASSWORD: "token",
+	}
+	ghcr := credentials.NewConsumerIdentity(identity.CONSUMER_TYPE, identity.ID_HOSTNAME, "ghcr.io")
+
+	var ctx credentials.Context
+	var fs vfs.FileSystem
+
+	BeforeEach(func() {
+		ctx = credentials.New()
+		fs = memoryfs.New()
+		vfsattr.Set(ctx, fs)
+	})
+
+	Context("file", func() {
+		It("encrypts values", func() {
+			f, key := Must2(local.NewFile(&local.Secret{Passphrase: "secret"}))
+			MustBeSuccessful(f.Set(key, "ghcr", ghcr, props))
+			MustBeSuccessful(f.WriteFile(CREDS, fs))
+
+			data := Must(vfs.ReadFile(fs, CREDS))
+			Expect(string(data)).NotTo(ContainSubstring("mandelsoft"))
+			Expect(string(data)).To(ContainSubstring("hostname: ghcr.io"))
+
+			f = Must(local.ReadFile(CREDS, fs))
+			Expect(f.Encryption.Method).To(Equal(local.METHOD_PASSPHRASE))
+			Expect(local.IsEncrypted(f.Lookup("ghcr").Credentials[cpi.ATTR_PASSWORD])).To(BeTrue())
+			key = Must(f.Key(&local.Secret{Passphrase: "secret"}))
+			Expect(f.Lookup("ghcr").Decrypt(key)).To(Equal(props))
+			Expect(f.Find(ghcr).Name).To(Equal("ghcr"))
+		})
+
+		It("rejects wrong secret", func() {
+			f, _ := Must2(local.NewFile(&local.Secret{Passphrase: "secret"}))
+			ExpectError(f.Key(&local.Secret{Passphrase: "other"})).To(MatchError("secret does not match encrypted credentials"))
+			ExpectError(f.Key(&local.Secret{Key: Must(encrypt.NewKey(encrypt.AES_256))})).To(MatchError("passphrase required"))
+		})
+
+		It("replaces entries", func() {
+			f, key := Must2(local.NewFile(&local.Secret{Passphrase: "secret"}))
+			MustBeSuccessful(f.Set(key, "", ghcr, props))
+			MustBeSuccessful(f.Set(key, "", ghcr, props2))
+			Expect(len(f.Entries)).To(Equal(1))
+			Expect(f.Find(ghcr).Decrypt(key)).To(Equal(props2))
+		})
+
+		It("rotates secret", func() {
+			f, key := Must2(local.NewFile(&local.Secret{Passphrase: "secret"}))
+			MustBeSuccessful(f.Set(key, "ghcr", ghcr, props))
+			newkey := Must(encrypt.NewKey(encrypt.AES_256))
+			Expect(f.Rotate(key, &local.Secret{Key: newkey})).To(Equal(newkey))
+
+			Expect(f.Encryption.Method).To(Equal(local.METHOD_KEY))
+			Expect(f.Encryption.Salt).To(BeNil())
+			ExpectError(f.Key(&local.Secret{Passphrase: "secret"})).To(MatchError("encryption key required"))
+			key = Must(f.Key(&local.Secret{Key: newkey}))
+			Expect(f.Lookup("ghcr").Decrypt(key)).To(Equal(props))
+		})
+
+		It("binds values to their entries", func() {
+			f, key := Must2(local.NewFile(&local.Secret{Passphrase: "secret"}))
+			MustBeSuccessful(f.Set(key, "ghcr", ghcr, props))
+			MustBeSuccessful(f.Set(key, "other", nil, props2))
+
+			f.Entries[0].Credentials, f.Entries[1].Credentials = f.Entries[1].Credentials, f.Entries[0].Credentials
+			ExpectError(f.Lookup("ghcr").Decrypt(key)).To(MatchError(ContainSubstring("message authentication failed")))
+
+			f.Entries[0].Credentials, f.Entries[1].Credentials = f.Entries[1].Credentials, f.Entries[0].Credentials
+			creds := f.Lookup("ghcr").Credentials
+			creds[cpi.ATTR_USERNAME], creds[cpi.ATTR_PASSWORD] = creds[cpi.ATTR_PASSWORD], creds[cpi.ATTR_USERNAME]
+			ExpectError(f.Lookup("ghcr").Decrypt(key)).To(MatchError(ContainSubstring("message authentication failed")))
+		})
+
+		It("rejects plain values", func() {
+			f, key := Must2(local.NewFile(&local.Secret{Passphrase: "secret"}))
+			MustBeSuccessful(f.Set(key, "ghcr", ghcr, props))
+			f.Lookup("ghcr").Credentials[cpi.ATTR_PASSWORD] = "plain"
+
+			ExpectError(f.Lookup("ghcr").Decrypt(key)).To(MatchError(`credential property "password": value not encrypted`))
+			Expect(f.Lookup("ghcr").Decrypt(key, true)).To(Equal(common.Properties{
+				cpi.ATTR_USERNAME: "mandelsoft",
+				cpi.ATTR_PASSWORD: "plain",
+			}))
+		})
+	})
+
+	Context("repository", func() {
+		var key []byte
+
+		BeforeEach(func() {
+			key = Must(encrypt.NewKey(encrypt.AES_256))
+			MustBeSuccessful(encrypt.WriteKey(key, KEY, fs))
+			f, _ := Must2(local.NewFile(&local.Secret{Key: key}))
+			MustBeSuccessful(f.Set(key, "ghcr", ghcr, props))
+			MustBeSuccessful(f.Set(key, "other", nil, props2))
+			MustBeSuccessful(f.WriteFile(CREDS, fs))
+		})
+
+		It("serializes repo spec", func() {
+			spec := local.NewRepositorySpec(CREDS).WithKeyFile(KEY)
+			Expect(json.Marshal(spec)).To(YAMLEqual(`{"type":"EncryptedCredentialsFile","path":"/creds.yaml","keyFile":"/creds.key"}`))
+		})
+
+		It("resolves repository", func() {
+			repo := Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS).WithKeyFile(KEY)))
+			Expect(reflect.TypeOf(repo).String()).To(Equal("*encryptedfile.Repository"))
+		})
+
+		It("caches repositories by complete spec", func() {
+			repo := Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS).WithKeyFile(KEY)))
+			Expect(Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS).WithKeyFile(KEY)))).To(BeIdenticalTo(repo))
+			Expect(Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS).WithKeyFile("/unknown")))).NotTo(BeIdenticalTo(repo))
+			Expect(Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS, false).WithKeyFile(KEY)))).NotTo(BeIdenticalTo(repo))
+		})
+
+		It("accepts plain values on request", func() {
+			f := Must(local.ReadFile(CREDS, fs))
+			f.Lookup("other").Credentials[cpi.ATTR_PASSWORD] = "plain"
+			MustBeSuccessful(f.WriteFile(CREDS, fs))
+
+			repo := Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS).WithKeyFile(KEY)))
+			ExpectError(repo.LookupCredentials("other")).To(MatchError(ContainSubstring("value not encrypted")))
+
+			repo = Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS).WithKeyFile(KEY).WithPlainValues()))
+			Expect(Must(repo.LookupCredentials("other")).Properties()).To(Equal(common.Properties{
+				cpi.ATTR_USERNAME: "mandelsoft",
+				cpi.ATTR_PASSWORD: "plain",
+			}))
+		})
+
+		It("retrieves credentials by name", func() {
+			repo := Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS).WithKeyFile(KEY)))
+			Expect(repo.ExistsCredentials("other")).To(BeTrue())
+			Expect(repo.ExistsCredentials("unknown")).To(BeFalse())
+			Expect(Must(repo.LookupCredentials("other")).Properties()).To(Equal(props2))
+		})
+
+		It("propagates credentials to consumer identity", func() {
+			Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS).WithKeyFile(KEY)))
+			creds := Must(credentials.CredentialsForConsumer(ctx, credentials.NewConsumerIdentity(identity.CONSUMER_TYPE,
+				identity.ID_HOSTNAME, "ghcr.io",
+				identity.ID_PATHPREFIX, "mandelsoft",
+			)))
+			Expect(creds.Properties()).To(Equal(props))
+		})
+
+		It("decrypts lazily", func() {
+			Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS).WithKeyFile("/unknown")))
+			src := Must(ctx.GetCredentialsForConsumer(ghcr))
+			ExpectError(src.Credentials(ctx)).To(HaveOccurred())
+		})
+
+		It("uses secret from repository credentials", func() {
+			repo := Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS), credentials.DirectCredentials{
+				local.ATTR_KEY: string(encrypt.KeyToPem(key)),
+			}))
+			Expect(Must(repo.LookupCredentials("ghcr")).Properties()).To(Equal(props))
+
+			other := Must(encrypt.NewKey(encrypt.AES_256))
+			repo = Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS), credentials.DirectCredentials{
+				local.ATTR_KEY: string(encrypt.KeyToPem(other)),
+			}))
+			ExpectError(repo.LookupCredentials("ghcr")).To(HaveOccurred())
+		})
+
+		It("keeps consumer providers of specs for the same file", func() {
+			Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS).WithKeyFile(KEY)))
+			Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS).WithKeyFile(KEY).WithPlainValues()))
+			_, trace := Must2(credentials.ExplainCredentialsForConsumer(ctx, credentials.NewConsumerIdentity(identity.CONSUMER_TYPE,
+				identity.ID_HOSTNAME, "ghcr.io",
+			)))
+			var providers []credentials.ProviderIdentity
+			for _, pid := range trace.Providers {
+				if strings.HasPrefix(string(pid), local.PROVIDER+"/") {
+					providers = append(providers, pid)
+				}
+			}
+			Expect(providers).To(HaveLen(2))
+		})
+
+		It("writes credentials", func() {
+			repo := Must(ctx.RepositoryForSpec(local.NewRepositorySpec(CREDS).WithKeyFile(KEY)))
+			Must(repo.WriteCredentials("other", credentials.DirectCredentials(props)))
+			Must(repo.WriteCredentials("new", credentials.DirectCredentials(props2)))
+
+			f := Must(local.ReadFile(CREDS, fs))
+			Expect(len(f.Entries)).To(Equal(3))
+			Expect(f.Lookup("other").Decrypt(key)).To(Equal(props))
+			Expect(f.Lookup("new").Decrypt(key)).To(Equal(props2))
+		})
+	})
+})
@@ -0,0 +1,168 @@
+package encryptedfile
+
+import (
+	"sync"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/datacontext/attrs/vfsattr"
+	"ocm.software/ocm/api/utils"
+)
+
+type Repository struct {
+	lock           sync.RWMutex
+	ctx            cpi.Context
+	id             cpi.ProviderIdentity
+	fs             vfs.FileSystem
+	propagate      bool
+	plain          bool
+	path           string
+	keyFile        string
+	passphraseFile string
+	creds          cpi.Credentials
+	file           *File
+	key            []byte
+}
+
+var _ cpi.Repository = (*Repository)(nil)
+
+// NewRepository creates a repository for an encrypted credentials file.
+// The file is read immediately, but the secret is resolved and the
+// credential values are decrypted not before credentials are requested.
+func NewRepository(ctx cpi.Context, spec *RepositorySpec, creds cpi.Credentials) (*Repository, error) {
+	if spec.Path == "" {
+		return nil, errors.Newf("path for encrypted credentials file required")
+	}
+	path, err := utils.ResolvePath(spec.Path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot resolve path %q", spec.Path)
+	}
+	key, err := repositoryKey(spec, creds)
+	if err != nil {
+		return nil, err
+	}
+	r := &Repository{
+		ctx:            datacontext.InternalContextRef(ctx),
+		id:             cpi.ProviderIdentity(PROVIDER + "/" + key),
+		fs:             vfsattr.Get(ctx),
+		propagate:      utils.AsBool(spec.PropagateConsumerIdentity, true),
+		plain:          spec.AllowPlainValues,
+		path:           path,
+		keyFile:        spec.KeyFile,
+		passphraseFile: spec.PassphraseFile,
+		creds:          creds,
+	}
+	err = r.Read(true)
+	return r, err
+}
+
+func (r *Repository) ExistsCredentials(name string) (bool, error) {
+	err := r.Read(false)
+	if err != nil {
+		return false, err
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.file.Lookup(name) != nil, nil
+}
+
+func (r *Repository) LookupCredentials(name string) (cpi.Credentials, error) {
+	err := r.Read(false)
+	if err != nil {
+		return nil, err
+	}
+	r.lock.RLock()
+	e := r.file.Lookup(name)
+	r.lock.RUnlock()
+	if e == nil {
+		return nil, errors.ErrNotFound(cpi.KIND_CREDENTIALS, name, Type)
+	}
+	return r.decrypt(e)
+}
+
+// WriteCredentials adds or replaces the named credentials and
+// writes the encrypted values back to the file.
+func (r *Repository) WriteCredentials(name string, creds cpi.Credentials) (cpi.Credentials, error) {
+	err := r.Read(false)
+	if err != nil {
+		return nil, err
+	}
+	key, err := r.getKey()
+	if err != nil {
+		return nil, err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var id cpi.ConsumerIdentity
+	if e := r.file.Lookup(name); e != nil {
+		id = e.Consumer
+	}
+	err = r.file.Set(key, name, id, creds.Properties())
+	if err != nil {
+		return nil, err
+	}
+	err = r.file.WriteFile(r.path, r.fs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot write credentials file %q", r.path)
+	}
+	return creds, nil
+}
+
+func (r *Repository) Read(force bool) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !force && r.file != nil {
+		return nil
+	}
+	f, err := ReadFile(r.path, r.fs)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read encrypted credentials file %q", r.path)
+	}
+	r.file = f
+	r.key = nil
+	if r.propagate {
+		r.ctx.RegisterConsumerProvider(r.id, &ConsumerProvider{r})
+	}
+	return nil
+}
+
+func (r *Repository) decrypt(e *Entry) (cpi.Credentials, error) {
+	key, err := r.getKey()
+	if err != nil {
+		return nil, err
+	}
+	props, err := e.Decrypt(key, r.plain)
+	if err != nil {
+		return nil, errors.Wrapf(err, "credentials %s in %q", e.describe(), r.path)
+	}
+	return cpi.NewCredentials(props), nil
+}
+
+// getKey resolves the secret and derives the key on first usage.
+func (r *Repository) getKey() ([]byte, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.key != nil {
+		return r.key, nil
+	}
+	secret, err := ReadSecret(r.keyFile, r.passphraseFile, r.fs)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		secret, err = SecretForCredentials(r.creds)
+		if err != nil {
+			return nil, err
+		}
+	}
+	key, err := r.file.Key(secret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "encrypted credentials file %q", r.path)
+	}
+	r.key = key
+	return key, nil
+}
@@ -0,0 +1,77 @@
+package encryptedfile
+
+import (
+	"fmt"
+
+	"github.com/mandelsoft/goutils/generics"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/utils"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+const (
+	Type   = "EncryptedCredentialsFile"
+	TypeV1 = Type + runtime.VersionSeparator + "v1"
+)
+
+func init() {
+	cpi.RegisterRepositoryType(cpi.NewRepositoryType[*RepositorySpec](Type))
+	cpi.RegisterRepositoryType(cpi.NewRepositoryType[*RepositorySpec](TypeV1, cpi.WithDescription(usage), cpi.WithFormatSpec(format)))
+}
+
+// RepositorySpec describes a credential repository based on a file
+// with encrypted credential values.
+type RepositorySpec struct {
+	runtime.ObjectVersionedType `json:",inline"`
+	Path                        string `json:"path"`
+	KeyFile                     string `json:"keyFile,omitempty"`
+	PassphraseFile              string `json:"passphraseFile,omitempty"`
+	PropagateConsumerIdentity   *bool  `json:"propagateConsumerIdentity,omitempty"`
+	AllowPlainValues            bool   `json:"allowPlainValues,omitempty"`
+}
+
+// NewRepositorySpec creates a new encrypted credentials file RepositorySpec.
+// The secret can be given by a key file or a passphrase file
+// (see WithKeyFile and WithPassphraseFile) or by the credentials
+// used for the repository.
+func NewRepositorySpec(path string, propagate ...bool) *RepositorySpec {
+	var p *bool
+	if len(propagate) > 0 {
+		p = generics.Pointer(utils.OptionalDefaultedBool(true, propagate...))
+	}
+	return &RepositorySpec{
+		ObjectVersionedType:       runtime.NewVersionedTypedObject(Type),
+		Path:                      path,
+		PropagateConsumerIdentity: p,
+	}
+}
+
+func (rs *RepositorySpec) WithKeyFile(path string) *RepositorySpec {
+	rs.KeyFile = path
+	return rs
+}
+
+func (rs *RepositorySpec) WithPassphraseFile(path string) *RepositorySpec {
+	rs.PassphraseFile = path
+	return rs
+}
+
+// WithPlainValues allows credential values not in encrypted form.
+func (rs *RepositorySpec) WithPlainValues(b ...bool) *RepositorySpec {
+	rs.AllowPlainValues = utils.OptionalDefaultedBool(true, b...)
+	return rs
+}
+
+func (rs *RepositorySpec) GetType() string {
+	return Type
+}
+
+func (rs *RepositorySpec) Repository(ctx cpi.Context, creds cpi.Credentials) (cpi.Repository, error) {
+	r := ctx.GetAttributes().GetOrCreateAttribute(ATTR_REPOS, newRepositories)
+	repos, ok := r.(*Repositories)
+	if !ok {
+		return nil, fmt.Errorf("failed to assert type %T to Repositories", r)
+	}
+	return repos.GetRepository(ctx, rs, creds)
+}
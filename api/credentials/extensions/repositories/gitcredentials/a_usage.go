@@ -0,0 +1,49 @@
+package gitcredentials
+
+import (
+	"ocm.software/ocm/api/credentials/extensions/repositories/internal/hostcreds"
+	"ocm.software/ocm/api/utils/listformat"
+)
+
+var usage = `
+This repository type can be used to access credentials provided by git
+credential helpers. Like for the docker credential helpers used by the
+<code>DockerConfig</code> repository, the helper is executed with the
+action <code>get</code> following the git credential helper protocol.
+If no helper is configured, <code>git credential fill</code> is used to
+query the credential helpers configured for git. Helpers are never allowed
+to prompt for credentials.
+
+The helper is specified like the <code>credential.helper</code> setting
+of git: a name is mapped to an executable <code>git-credential-&lt;name></code>,
+a path is executed directly and a value starting with <code>!</code> is
+executed by the shell.
+
+If enabled, the helper is asked for credentials for consumer ids of HTTP
+based consumer types using the <code>hostpath</code> identity matcher,
+by default the types
+` + listformat.FormatList("", hostcreds.DefaultConsumerTypes...) + `
+Because helpers cannot enumerate their credentials, the hostname (and port)
+of the requested consumer id is used. The provided credentials are valid
+for the host and have a lower precedence than explicitly configured
+credentials for the host. Results of the helper are cached. Missing
+credentials and failures are only cached for a limited time (by default
+one minute). The runtime of a helper execution is limited (by default
+30 seconds).
+
+Credentials can be looked up by name with a URL or hostname.
+The username is provided as credential attribute <code>username</code>
+and the password as <code>password</code>. For consumer types requiring
+a token (like <code>Github</code>) the password is additionally provided
+as <code>token</code>.
+`
+
+var format = `The repository specification supports the following fields:
+` + listformat.FormatListElements("", listformat.StringElementDescriptionList{
+	"helper", "*string*(optional): the credential helper to use",
+	"hosts", "*[]string*(optional): the hosts the helper is asked for (default: all)",
+	"consumerTypes", "*[]string*(optional): the consumer types to provide credentials for",
+	"propagateConsumerIdentity", "*bool*(optional): enable consumer id propagation (default true)",
+	"timeout", "*string*(optional): the maximum runtime of a helper execution (default 30s)",
+	"negativeCacheTTL", "*string*(optional): the time missing credentials and failures are cached (default 1m)",
+})
@@ -0,0 +1,155 @@
+package gitcredentials
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+
+	"ocm.software/ocm/api/utils"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const (
+	ATTR_PROTOCOL = "protocol"
+	ATTR_HOST     = "host"
+	ATTR_PATH     = "path"
+	ATTR_USERNAME = "username"
+	ATTR_PASSWORD = "password"
+)
+
+// DEFAULT_TIMEOUT is the maximum runtime of a helper execution.
+const DEFAULT_TIMEOUT = 30 * time.Second
+
+// Request describes a credential request according to the
+// git credential helper protocol.
+type Request struct {
+	Protocol string
+	Host     string
+	Path     string
+}
+
+func (r *Request) String() string {
+	s := r.Protocol + "://" + r.Host
+	if r.Path != "" {
+		s += "/" + r.Path
+	}
+	return s
+}
+
+// validate rejects values breaking the line oriented
+// helper protocol. Otherwise, for example, a path with an encoded
+// newline could inject an additional host attribute and retrieve
+// the credentials of another host.
+func (r *Request) validate() error {
+	for _, f := range []struct{ name, value string }{
+		{ATTR_PROTOCOL, r.Protocol},
+		{ATTR_HOST, r.Host},
+		{ATTR_PATH, r.Path},
+	} {
+		if strings.ContainsAny(f.value, "\n\r\x00") {
+			return errors.ErrInvalid("credential request "+f.name, f.value)
+		}
+	}
+	return nil
+}
+
+func (r *Request) input() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s=%s\n", ATTR_PROTOCOL, r.Protocol)
+	fmt.Fprintf(&b, "%s=%s\n", ATTR_HOST, r.Host)
+	if r.Path != "" {
+		fmt.Fprintf(&b, "%s=%s\n", ATTR_PATH, r.Path)
+	}
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+// Helper executes a git credential helper.
+// If no helper is given, the credential helpers configured
+// for git are used by calling git credential fill.
+// Otherwise, the helper is used like the credential.helper
+// setting of git: a name is mapped to an executable
+// git-credential-<name>, a path is executed directly and a value
+// starting with ! is executed by the shell.
+type Helper struct {
+	helper  string
+	timeout time.Duration
+}
+
+// NewHelper creates a helper execution with an optional maximum runtime.
+// By default, DEFAULT_TIMEOUT is used.
+func NewHelper(helper string, timeout ...time.Duration) *Helper {
+	t := utils.Optional(timeout...)
+	if t <= 0 {
+		t = DEFAULT_TIMEOUT
+	}
+	return &Helper{helper: helper, timeout: t}
+}
+
+func (h *Helper) command(ctx context.Context) *exec.Cmd {
+	switch {
+	case h.helper == "":
+		return exec.CommandContext(ctx, "git", "credential", "fill")
+	case strings.HasPrefix(h.helper, "!"):
+		return exec.CommandContext(ctx, "sh", "-c", h.helper[1:]+" get")
+	case strings.ContainsAny(h.helper, "/\\"):
+		return exec.CommandContext(ctx, h.helper, "get")
+	default:
+		return exec.CommandContext(ctx, "git-credential-"+h.helper, "get")
+	}
+}
+
+// Get provides the credentials for the given request. If the helper
+// does not provide a password, nil is returned.
+func (h *Helper) Get(req *Request) (common.Properties, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+	cmd := h.command(ctx)
+	// never wait for processes started by the helper after a timeout
+	cmd.WaitDelay = time.Second
+	cmd.Stdin = bytes.NewReader(req.input())
+	// never prompt for credentials
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, errors.Wrapf(ctx.Err(), "credential helper timed out after %s", h.timeout)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return nil, errors.Wrapf(err, "credential helper failed: %s", msg)
+		}
+		return nil, errors.Wrapf(err, "credential helper failed")
+	}
+	return parseOutput(out), nil
+}
+
+func parseOutput(data []byte) common.Properties {
+	props := common.Properties{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		if i := strings.Index(line, "="); i > 0 {
+			props[line[:i]] = line[i+1:]
+		}
+	}
+	if props[ATTR_PASSWORD] == "" {
+		return nil
+	}
+	return props
+}
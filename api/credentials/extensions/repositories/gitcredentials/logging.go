@@ -0,0 +1,7 @@
+package gitcredentials
+
+import (
+	ocmlog "ocm.software/ocm/api/utils/logging"
+)
+
+var REALM = ocmlog.DefineSubRealm("git credential helpers as credential repository", "credentials/gitcredentials")
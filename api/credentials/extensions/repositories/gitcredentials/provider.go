@@ -0,0 +1,53 @@
+package gitcredentials
+
+import (
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/credentials/extensions/repositories/internal/hostcreds"
+	"ocm.software/ocm/api/credentials/identity/hostpath"
+	"ocm.software/ocm/api/utils/logging"
+)
+
+const PROVIDER = "ocm.software/credentialprovider/" + Type
+
+type ConsumerProvider struct {
+	repo *Repository
+}
+
+var _ cpi.ConsumerProvider = (*ConsumerProvider)(nil)
+
+func (p *ConsumerProvider) Unregister(_ cpi.ProviderIdentity) {
+}
+
+func (p *ConsumerProvider) Match(ectx cpi.EvaluationContext, req cpi.ConsumerIdentity, cur cpi.ConsumerIdentity, m cpi.IdentityMatcher) (cpi.CredentialsSource, cpi.ConsumerIdentity) {
+	return p.get(req, cur, m)
+}
+
+func (p *ConsumerProvider) Get(req cpi.ConsumerIdentity) (cpi.CredentialsSource, bool) {
+	creds, _ := p.get(req, nil, cpi.CompleteMatch)
+	return creds, creds != nil
+}
+
+// get asks the helper for credentials, if the host based identity
+// would be a better match than the current one. Helpers cannot
+// enumerate their credentials, therefore the identity is derived
+// from the request.
+func (p *ConsumerProvider) get(req cpi.ConsumerIdentity, cur cpi.ConsumerIdentity, m cpi.IdentityMatcher) (cpi.CredentialsSource, cpi.ConsumerIdentity) {
+	typ := req.Type()
+	if !hostcreds.Supports(p.repo.types, typ) || !p.repo.accepts(req[hostpath.ID_HOSTNAME]) {
+		return nil, cur
+	}
+	hreq, host, port := request(req)
+	id := hostcreds.ConsumerIdentity(typ, host, port)
+	if !m(req, cur, id) {
+		return nil, cur
+	}
+	props, err := p.repo.get(hreq)
+	if err != nil {
+		logging.Context().Logger(REALM).LogError(err, "credential helper failed", "request", hreq.String())
+		return nil, cur
+	}
+	if props == nil {
+		return nil, cur
+	}
+	return hostcreds.Credentials(typ, props[ATTR_USERNAME], props[ATTR_PASSWORD]), id
+}
@@ -0,0 +1,179 @@
+package gitcredentials_test
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/credentials/cpi"
+	local "ocm.software/ocm/api/credentials/extensions/repositories/gitcredentials"
+	git "ocm.software/ocm/api/tech/git/identity"
+	github "ocm.software/ocm/api/tech/github/identity"
+	wget "ocm.software/ocm/api/tech/wget/identity"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+// helperScript answers requests for github.com and records
+// the requests in a log file.
+const helperScript = `#!/bin/sh
+[ "$1" = get ] || exit 0
+while read line; do
+  [ -z "$line" ] && break
+  echo "$line" >> "$(dirname "$0")/requests"
+  case "$line" in
+    host=*) host="${line#host=}";;
+  esac
+done
+if [ "$host" = "slow.org" ]; then
+  sleep 1
+fi
+if [ "$host" = "github.com" ]; then
+  echo username=acme
+  echo password=token
+fi
+`
+
+var _ = Describe("git credential helper", func() {
+	var ctx credentials.Context
+	var dir, helper string
+
+	BeforeEach(func() {
+		ctx = credentials.New()
+		dir = GinkgoT().TempDir()
+		helper = filepath.Join(dir, "git-credential-test")
+		MustBeSuccessful(os.WriteFile(helper, []byte(helperScript), 0o700))
+	})
+
+	requests := func() []string {
+		data, err := os.ReadFile(filepath.Join(dir, "requests"))
+		if err != nil {
+			return nil
+		}
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+
+	It("serializes repo spec", func() {
+		spec := local.NewRepositorySpec("store").WithHosts("github.com")
+		Expect(json.Marshal(spec)).To(Equal([]byte(`{"type":"GitCredentialHelper","helper":"store","hosts":["github.com"]}`)))
+	})
+
+	It("retrieves credentials by name", func() {
+		repo := Must(ctx.RepositoryForSpec(local.NewRepositorySpec(helper)))
+		Expect(Must(repo.LookupCredentials("github.com")).Properties()).To(Equal(common.Properties{
+			cpi.ATTR_USERNAME: "acme",
+			cpi.ATTR_PASSWORD: "token",
+		}))
+		Expect(repo.ExistsCredentials("https://other.org/path")).To(BeFalse())
+		Expect(requests()).To(Equal([]string{"protocol=https", "host=github.com", "protocol=https", "host=other.org", "path=path"}))
+	})
+
+	It("propagates credentials to consumer identities", func() {
+		Must(ctx.RepositoryForSpec(local.NewRepositorySpec(helper)))
+
+		creds := Must(credentials.CredentialsForConsumer(ctx, github.GetConsumerId("https://github.com", "acme/repo")))
+		Expect(creds.Properties()).To(Equal(common.Properties{
+			cpi.ATTR_USERNAME: "acme",
+			cpi.ATTR_PASSWORD: "token",
+			cpi.ATTR_TOKEN:    "token",
+		}))
+		creds = Must(credentials.CredentialsForConsumer(ctx, Must(git.GetConsumerId("https://github.com/acme/other"))))
+		Expect(creds.GetProperty(cpi.ATTR_USERNAME)).To(Equal("acme"))
+
+		Expect(credentials.CredentialsForConsumer(ctx, wget.GetConsumerId("https://other.org/file"))).To(BeNil())
+		Expect(credentials.CredentialsForConsumer(ctx, wget.GetConsumerId("https://other.org/file"))).To(BeNil())
+		// results are cached
+		Expect(requests()).To(Equal([]string{"protocol=https", "host=github.com", "protocol=https", "host=other.org"}))
+	})
+
+	It("expires cached missing credentials", func() {
+		repo := Must(ctx.RepositoryForSpec(local.NewRepositorySpec(helper).WithNegativeCacheTTL(10 * time.Millisecond)))
+		Expect(repo.ExistsCredentials("other.org")).To(BeFalse())
+		Expect(repo.ExistsCredentials("github.com")).To(BeTrue())
+		time.Sleep(20 * time.Millisecond)
+		Expect(repo.ExistsCredentials("other.org")).To(BeFalse())
+		Expect(repo.ExistsCredentials("github.com")).To(BeTrue())
+		Expect(requests()).To(Equal([]string{"protocol=https", "host=other.org", "protocol=https", "host=github.com", "protocol=https", "host=other.org"}))
+	})
+
+	It("limits the helper runtime", func() {
+		MustBeSuccessful(os.WriteFile(helper, []byte("#!/bin/sh\nexec sleep 10\n"), 0o700))
+		repo := Must(ctx.RepositoryForSpec(local.NewRepositorySpec(helper).WithTimeout(100 * time.Millisecond)))
+		start := time.Now()
+		ExpectError(repo.LookupCredentials("github.com")).To(MatchError(ContainSubstring("credential helper timed out after 100ms")))
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+	})
+
+	It("rejects injected request attributes", func() {
+		repo := Must(ctx.RepositoryForSpec(local.NewRepositorySpec(helper)))
+		ExpectError(repo.LookupCredentials("https://evil.org/x%0Ahost=github.com")).To(MatchError("credential request path \"x\nhost=github.com\" is invalid"))
+		ExpectError(repo.LookupCredentials("https://evil.org/x%0Dhost=github.com")).To(MatchError(ContainSubstring("credential request path")))
+		ExpectError(repo.LookupCredentials("https://evil.org/x%00")).To(MatchError(ContainSubstring("credential request path")))
+		Expect(requests()).To(BeNil())
+	})
+
+	It("does not block lookups while the helper is running", func() {
+		repo := Must(ctx.RepositoryForSpec(local.NewRepositorySpec(helper)))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(repo.ExistsCredentials("slow.org")).To(BeFalse())
+			}()
+		}
+		time.Sleep(100 * time.Millisecond)
+		start := time.Now()
+		Expect(repo.ExistsCredentials("github.com")).To(BeTrue())
+		Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+		wg.Wait()
+		// concurrent requests for the same host share the helper execution
+		Expect(requests()).To(ConsistOf("protocol=https", "host=slow.org", "protocol=https", "host=github.com"))
+	})
+
+	It("rejects invalid durations", func() {
+		spec := local.NewRepositorySpec(helper)
+		spec.Timeout = "soon"
+		ExpectError(ctx.RepositoryForSpec(spec)).To(MatchError(ContainSubstring("invalid timeout")))
+	})
+
+	It("restricts hosts", func() {
+		Must(ctx.RepositoryForSpec(local.NewRepositorySpec(helper).WithHosts("gitlab.com")))
+		Expect(credentials.CredentialsForConsumer(ctx, github.GetConsumerId("https://github.com", "acme/repo"))).To(BeNil())
+		Expect(requests()).To(BeNil())
+	})
+
+	It("prefers explicit credentials", func() {
+		Must(ctx.RepositoryForSpec(local.NewRepositorySpec(helper)))
+		ctx.SetCredentialsForConsumer(github.GetConsumerId("https://github.com"), credentials.DirectCredentials{
+			cpi.ATTR_TOKEN: "explicit",
+		})
+		creds := Must(credentials.CredentialsForConsumer(ctx, github.GetConsumerId("https://github.com", "acme/repo")))
+		Expect(creds.GetProperty(cpi.ATTR_TOKEN)).To(Equal("explicit"))
+		Expect(requests()).To(BeNil())
+	})
+
+	It("uses git credential fill", func() {
+		if _, err := exec.LookPath("git"); err != nil {
+			Skip("git not available")
+		}
+		cfg := filepath.Join(dir, "gitconfig")
+		MustBeSuccessful(os.WriteFile(cfg, []byte("[credential]\n\thelper = "+helper+"\n"), 0o600))
+		GinkgoT().Setenv("GIT_CONFIG_GLOBAL", cfg)
+		GinkgoT().Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+		repo := Must(ctx.RepositoryForSpec(local.NewRepositorySpec("")))
+		Expect(Must(repo.LookupCredentials("https://github.com")).GetProperty(cpi.ATTR_PASSWORD)).To(Equal("token"))
+		ExpectError(repo.LookupCredentials("https://other.org")).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,176 @@
+package gitcredentials
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+	"golang.org/x/sync/singleflight"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/credentials/extensions/repositories/internal/hostcreds"
+	"ocm.software/ocm/api/credentials/identity/hostpath"
+	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/utils"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+type Repository struct {
+	lock   sync.Mutex
+	calls  singleflight.Group
+	ctx    cpi.Context
+	helper *Helper
+	hosts  []string
+	types  []string
+	// cache keeps the results of the helper (including missing
+	// credentials and failures) to avoid repeated executions.
+	// Missing credentials and failures expire after negativeTTL.
+	cache       map[string]result
+	negativeTTL time.Duration
+}
+
+// DEFAULT_NEGATIVE_CACHE_TTL is the default time missing credentials
+// and helper failures are cached.
+const DEFAULT_NEGATIVE_CACHE_TTL = time.Minute
+
+type result struct {
+	props   common.Properties
+	err     error
+	expires time.Time
+}
+
+func (r *result) valid() bool {
+	return r.expires.IsZero() || time.Now().Before(r.expires)
+}
+
+var _ cpi.Repository = (*Repository)(nil)
+
+func NewRepository(ctx cpi.Context, spec *RepositorySpec) (*Repository, error) {
+	timeout, err := duration("timeout", spec.Timeout, DEFAULT_TIMEOUT)
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := duration("negativeCacheTTL", spec.NegativeCacheTTL, DEFAULT_NEGATIVE_CACHE_TTL)
+	if err != nil {
+		return nil, err
+	}
+	r := &Repository{
+		ctx:         datacontext.InternalContextRef(ctx),
+		helper:      NewHelper(spec.Helper, timeout),
+		hosts:       spec.Hosts,
+		types:       spec.ConsumerTypes,
+		cache:       map[string]result{},
+		negativeTTL: ttl,
+	}
+	if utils.AsBool(spec.PropagateConsumerIdentity, true) {
+		id := PROVIDER + "/" + spec.Helper
+		if len(spec.Hosts) > 0 {
+			id += "/" + strings.Join(spec.Hosts, ",")
+		}
+		r.ctx.RegisterConsumerProvider(cpi.ProviderIdentity(id), &ConsumerProvider{r})
+	}
+	return r, nil
+}
+
+// ExistsCredentials checks whether the helper provides credentials
+// for the given name, which is a URL or a hostname.
+func (r *Repository) ExistsCredentials(name string) (bool, error) {
+	props, err := r.lookup(name)
+	return props != nil, err
+}
+
+func (r *Repository) LookupCredentials(name string) (cpi.Credentials, error) {
+	props, err := r.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if props == nil {
+		return nil, errors.ErrNotFound(cpi.KIND_CREDENTIALS, name, Type)
+	}
+	return hostcreds.Credentials("", props[ATTR_USERNAME], props[ATTR_PASSWORD]), nil
+}
+
+func (r *Repository) WriteCredentials(_ string, _ cpi.Credentials) (cpi.Credentials, error) {
+	return nil, errors.ErrNotSupported("write", "credentials", Type)
+}
+
+func (r *Repository) lookup(name string) (common.Properties, error) {
+	if !strings.Contains(name, "://") {
+		name = "https://" + name
+	}
+	u, err := url.Parse(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid credential name %q", name)
+	}
+	req := &Request{
+		Protocol: u.Scheme,
+		Host:     u.Host,
+		Path:     strings.Trim(u.Path, "/"),
+	}
+	if !r.accepts(u.Hostname()) {
+		return nil, nil
+	}
+	return r.get(req)
+}
+
+func (r *Repository) accepts(host string) bool {
+	return host != "" && (len(r.hosts) == 0 || slices.Contains(r.hosts, host))
+}
+
+// get provides the (cached) result of the helper for a request.
+// The lock is held only to access the cache, concurrent
+// requests for the same key share a single helper execution.
+func (r *Repository) get(req *Request) (common.Properties, error) {
+	key := req.String()
+	if res, ok := r.cached(key); ok {
+		return res.props, res.err
+	}
+	v, _, _ := r.calls.Do(key, func() (interface{}, error) {
+		if res, ok := r.cached(key); ok {
+			return res, nil
+		}
+		props, err := r.helper.Get(req)
+		res := result{props: props, err: err}
+		if props == nil {
+			res.expires = time.Now().Add(r.negativeTTL)
+		}
+		r.lock.Lock()
+		r.cache[key] = res
+		r.lock.Unlock()
+		return res, nil
+	})
+	res := v.(result)
+	return res.props, res.err
+}
+
+func (r *Repository) cached(key string) (result, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	res, ok := r.cache[key]
+	return res, ok && res.valid()
+}
+
+// request provides the helper request for a consumer id
+// and the host based identity the credentials are valid for.
+func request(id cpi.ConsumerIdentity) (*Request, string, string) {
+	host := id[hostpath.ID_HOSTNAME]
+	port := id[hostpath.ID_PORT]
+	scheme := id[hostpath.ID_SCHEME]
+	if scheme == "" {
+		scheme = "https"
+	}
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		port = ""
+	}
+	req := &Request{
+		Protocol: scheme,
+		Host:     host,
+	}
+	if port != "" {
+		req.Host += ":" + port
+	}
+	return req, host, port
+}
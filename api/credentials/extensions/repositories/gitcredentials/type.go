@@ -0,0 +1,102 @@
+package gitcredentials
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/generics"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/utils"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+const (
+	Type   = "GitCredentialHelper"
+	TypeV1 = Type + runtime.VersionSeparator + "v1"
+)
+
+func init() {
+	cpi.RegisterRepositoryType(cpi.NewRepositoryType[*RepositorySpec](Type))
+	cpi.RegisterRepositoryType(cpi.NewRepositoryType[*RepositorySpec](TypeV1, cpi.WithDescription(usage), cpi.WithFormatSpec(format)))
+}
+
+// RepositorySpec describes a credential repository based on
+// git credential helpers.
+type RepositorySpec struct {
+	runtime.ObjectVersionedType `json:",inline"`
+	Helper                      string   `json:"helper,omitempty"`
+	Hosts                       []string `json:"hosts,omitempty"`
+	ConsumerTypes               []string `json:"consumerTypes,omitempty"`
+	PropagateConsumerIdentity   *bool    `json:"propagateConsumerIdentity,omitempty"`
+	// Timeout limits the runtime of a helper execution.
+	Timeout string `json:"timeout,omitempty"`
+	// NegativeCacheTTL limits the time missing credentials and
+	// helper failures are cached.
+	NegativeCacheTTL string `json:"negativeCacheTTL,omitempty"`
+}
+
+// NewRepositorySpec creates a new git credential helper RepositorySpec.
+// If no helper is given, the helpers configured for git are used.
+func NewRepositorySpec(helper string, propagate ...bool) *RepositorySpec {
+	var p *bool
+	if len(propagate) > 0 {
+		p = generics.Pointer(utils.OptionalDefaultedBool(true, propagate...))
+	}
+	return &RepositorySpec{
+		ObjectVersionedType:       runtime.NewVersionedTypedObject(Type),
+		Helper:                    helper,
+		PropagateConsumerIdentity: p,
+	}
+}
+
+// WithHosts restricts the hosts the helper is asked for.
+func (rs *RepositorySpec) WithHosts(hosts ...string) *RepositorySpec {
+	rs.Hosts = hosts
+	return rs
+}
+
+// WithConsumerTypes restricts the consumer types the credentials
+// are provided for.
+func (rs *RepositorySpec) WithConsumerTypes(types ...string) *RepositorySpec {
+	rs.ConsumerTypes = types
+	return rs
+}
+
+// WithTimeout sets the maximum runtime of a helper execution.
+func (rs *RepositorySpec) WithTimeout(d time.Duration) *RepositorySpec {
+	rs.Timeout = d.String()
+	return rs
+}
+
+// WithNegativeCacheTTL sets the time missing credentials
+// and helper failures are cached.
+func (rs *RepositorySpec) WithNegativeCacheTTL(d time.Duration) *RepositorySpec {
+	rs.NegativeCacheTTL = d.String()
+	return rs
+}
+
+func (rs *RepositorySpec) GetType() string {
+	return Type
+}
+
+func (rs *RepositorySpec) Repository(ctx cpi.Context, _ cpi.Credentials) (cpi.Repository, error) {
+	r := ctx.GetAttributes().GetOrCreateAttribute(ATTR_REPOS, newRepositories)
+	repos, ok := r.(*Repositories)
+	if !ok {
+		return nil, fmt.Errorf("failed to assert type %T to Repositories", r)
+	}
+	return repos.GetRepository(ctx, rs)
+}
+
+func duration(field, value string, def time.Duration) (time.Duration, error) {
+	if value == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid %s", field)
+	}
+	return d, nil
+}
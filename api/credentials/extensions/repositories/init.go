@@ -4,9 +4,14 @@ import (
 	_ "ocm.software/ocm/api/credentials/extensions/repositories/aliases"
 	_ "ocm.software/ocm/api/credentials/extensions/repositories/directcreds"
 	_ "ocm.software/ocm/api/credentials/extensions/repositories/dockerconfig"
+	_ "ocm.software/ocm/api/credentials/extensions/repositories/encryptedfile"
 	_ "ocm.software/ocm/api/credentials/extensions/repositories/gardenerconfig"
+	_ "ocm.software/ocm/api/credentials/extensions/repositories/gitcredentials"
+	_ "ocm.software/ocm/api/credentials/extensions/repositories/kubesecrets"
 	_ "ocm.software/ocm/api/credentials/extensions/repositories/memory"
 	_ "ocm.software/ocm/api/credentials/extensions/repositories/memory/config"
+	_ "ocm.software/ocm/api/credentials/extensions/repositories/netrc"
 	_ "ocm.software/ocm/api/credentials/extensions/repositories/npm"
+	_ "ocm.software/ocm/api/credentials/extensions/repositories/oauth2"
 	_ "ocm.software/ocm/api/credentials/extensions/repositories/vault"
 )
@@ -0,0 +1,70 @@
+// Package hostcreds provides the mapping of host based credential
+// sources (like netrc files or git credential helpers) to consumer
+// identities using the hostpath identity matcher.
+package hostcreds
+
+import (
+	"slices"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/credentials/identity/hostpath"
+	git "ocm.software/ocm/api/tech/git/identity"
+	github "ocm.software/ocm/api/tech/github/identity"
+	maven "ocm.software/ocm/api/tech/maven/identity"
+	npm "ocm.software/ocm/api/tech/npm/identity"
+	wget "ocm.software/ocm/api/tech/wget/identity"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+// DefaultConsumerTypes are the HTTP based consumer types using the
+// hostpath identity matcher, which are served by default.
+var DefaultConsumerTypes = []string{
+	wget.CONSUMER_TYPE,
+	maven.CONSUMER_TYPE,
+	npm.CONSUMER_TYPE,
+	github.CONSUMER_TYPE,
+	git.CONSUMER_TYPE,
+}
+
+// tokenTypes are consumer types expecting the password as token.
+var tokenTypes = []string{
+	github.CONSUMER_TYPE,
+}
+
+// ConsumerTypes provides the effective list of consumer types.
+func ConsumerTypes(types []string) []string {
+	if len(types) == 0 {
+		return DefaultConsumerTypes
+	}
+	return types
+}
+
+// Supports checks whether the given consumer type is in the
+// effective list of consumer types.
+func Supports(types []string, typ string) bool {
+	return slices.Contains(ConsumerTypes(types), typ)
+}
+
+// ConsumerIdentity provides a consumer identity for a host (and optional port).
+// An empty host describes an identity matching all hosts.
+func ConsumerIdentity(typ, host, port string) cpi.ConsumerIdentity {
+	id := cpi.NewConsumerIdentity(typ)
+	if host != "" {
+		id[hostpath.ID_HOSTNAME] = host
+	}
+	if port != "" {
+		id[hostpath.ID_PORT] = port
+	}
+	return id
+}
+
+// Credentials provides the credential properties for a consumer type.
+func Credentials(typ, username, password string) cpi.Credentials {
+	props := common.Properties{}
+	props.SetNonEmptyValue(cpi.ATTR_USERNAME, username)
+	props.SetNonEmptyValue(cpi.ATTR_PASSWORD, password)
+	if slices.Contains(tokenTypes, typ) {
+		props.SetNonEmptyValue(cpi.ATTR_TOKEN, password)
+	}
+	return cpi.NewCredentials(props)
+}
@@ -0,0 +1,49 @@
+package kubesecrets
+
+import (
+	"ocm.software/ocm/api/utils/listformat"
+)
+
+var usage = `
+This repository type can be used to access credentials stored in
+Kubernetes secrets of a namespace. The cluster is selected by a
+kubeconfig. If no kubeconfig is specified, the default kubeconfig
+(environment variable <code>KUBECONFIG</code> or <code>~/.kube/config</code>)
+is used, or the in-cluster configuration if running in a pod.
+If no namespace is specified, the namespace of the selected kubeconfig
+context is used.
+
+The following secret types are supported:
+
+- <code>kubernetes.io/dockerconfigjson</code>
+
+  The docker config is evaluated like by the <code>DockerConfig</code>
+  repository. If enabled, the contained registries are provided as
+  consumer ids of type <code>OCIRegistry</code>. Credentials can be looked
+  up by name with the registry host.
+
+- <code>Opaque</code> and <code>kubernetes.io/basic-auth</code>
+
+  The key/value pairs of the secret are provided as credential
+  attributes. If enabled, the credentials are provided for the consumer
+  id described (in JSON) by the annotation <code>` + ANNOTATION_CONSUMER_ID + `</code>.
+  Credentials can be looked up by name with the secret name.
+
+Other secrets are ignored. The secrets may be restricted by a label selector
+and a list of secret names. The secrets are read once when the repository
+is created. If <code>watch</code> is enabled, the secrets are watched
+and changes are taken into account without reconfiguration. This should
+only be used by long-running processes, because the watch is kept open
+until the credential context is finalized.
+`
+
+var format = `The repository specification supports the following fields:
+` + listformat.FormatListElements("", listformat.StringElementDescriptionList{
+	"kubeconfig", "*string*(optional): the path of the kubeconfig used to select the cluster",
+	"context", "*string*(optional): the kubeconfig context to use",
+	"namespace", "*string*(optional): the namespace of the secrets",
+	"labelSelector", "*string*(optional): a label selector for the secrets to use",
+	"secrets", "*[]string*(optional): the names of the secrets to use (default: all)",
+	"watch", "*bool*(optional): watch the secrets for updates (default false)",
+	"propagateConsumerIdentity", "*bool*(optional): enable consumer id propagation (default true)",
+})
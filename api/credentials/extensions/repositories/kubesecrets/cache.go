@@ -0,0 +1,48 @@
+package kubesecrets
+
+import (
+	"sync"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/datacontext"
+)
+
+const ATTR_REPOS = "ocm.software/ocm/api/credentials/extensions/repositories/kubesecrets"
+
+type Repositories struct {
+	lock  sync.Mutex
+	repos map[cpi.ProviderIdentity]*Repository
+}
+
+func newRepositories(datacontext.Context) interface{} {
+	return &Repositories{
+		repos: map[cpi.ProviderIdentity]*Repository{},
+	}
+}
+
+func (r *Repositories) GetRepository(ctx cpi.Context, spec *RepositorySpec) (*Repository, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var err error
+	key := spec.GetKey()
+	repo := r.repos[key]
+	if repo == nil {
+		repo, err = NewRepository(ctx, spec, nil)
+		if err == nil {
+			r.repos[key] = repo
+		}
+	}
+	return repo, err
+}
+
+// Finalize stops watching the secrets when the
+// credential context is finalized.
+func (r *Repositories) Finalize() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, repo := range r.repos {
+		repo.Close()
+	}
+	return nil
+}
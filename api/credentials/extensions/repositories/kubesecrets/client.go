@@ -0,0 +1,50 @@
+package kubesecrets
+
+import (
+	"github.com/mandelsoft/goutils/errors"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"ocm.software/ocm/api/utils"
+)
+
+const DEFAULT_NAMESPACE = "default"
+
+// NewSecretsClient provides a client for the secrets of the namespace
+// configured in the specification. The cluster is selected by the
+// configured kubeconfig and context. Without an explicit kubeconfig,
+// the standard kubeconfig loading rules (environment variable KUBECONFIG
+// or ~/.kube/config) are used, falling back to the in-cluster configuration.
+// If no namespace is configured, the namespace of the selected context is used.
+func NewSecretsClient(spec *RepositorySpec) (corev1client.SecretInterface, string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if spec.KubeConfig != "" {
+		path, err := utils.ResolvePath(spec.KubeConfig)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "cannot resolve path %q", spec.KubeConfig)
+		}
+		rules.ExplicitPath = path
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: spec.Context}
+	cfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+
+	restcfg, err := cfg.ClientConfig()
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "cannot load kubeconfig")
+	}
+	ns := spec.Namespace
+	if ns == "" {
+		ns, _, err = cfg.Namespace()
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "cannot determine namespace")
+		}
+		if ns == "" {
+			ns = DEFAULT_NAMESPACE
+		}
+	}
+	client, err := corev1client.NewForConfig(restcfg)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "cannot create kubernetes client")
+	}
+	return client.Secrets(ns), ns, nil
+}
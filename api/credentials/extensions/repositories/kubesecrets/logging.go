@@ -0,0 +1,10 @@
+package kubesecrets
+
+import (
+	ocmlog "ocm.software/ocm/api/utils/logging"
+)
+
+var (
+	REALM = ocmlog.DefineSubRealm("Kubernetes Secret Access", "credentials", "kubesecrets")
+	log   = ocmlog.DynamicLogger(REALM)
+)
@@ -0,0 +1,49 @@
+package kubesecrets
+
+import (
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/credentials/extensions/repositories/dockerconfig"
+)
+
+const PROVIDER = "ocm.software/credentialprovider/" + Type
+
+type ConsumerProvider struct {
+	repo *Repository
+}
+
+var _ cpi.ConsumerProvider = (*ConsumerProvider)(nil)
+
+func (p *ConsumerProvider) Unregister(id cpi.ProviderIdentity) {
+}
+
+func (p *ConsumerProvider) Match(ectx cpi.EvaluationContext, req cpi.ConsumerIdentity, cur cpi.ConsumerIdentity, m cpi.IdentityMatcher) (cpi.CredentialsSource, cpi.ConsumerIdentity) {
+	return p.get(ectx, req, cur, m)
+}
+
+func (p *ConsumerProvider) Get(req cpi.ConsumerIdentity) (cpi.CredentialsSource, bool) {
+	creds, _ := p.get(nil, req, nil, cpi.CompleteMatch)
+	return creds, creds != nil
+}
+
+// get evaluates the actual secrets in the order of their names.
+// Docker config secrets provide the consumer ids of their registries,
+// key/value secrets the consumer id described by their annotation.
+func (p *ConsumerProvider) get(ectx cpi.EvaluationContext, req cpi.ConsumerIdentity, cur cpi.ConsumerIdentity, m cpi.IdentityMatcher) (cpi.CredentialsSource, cpi.ConsumerIdentity) {
+	var creds cpi.CredentialsSource
+
+	for _, e := range p.repo.getEntries() {
+		if e.docker != nil {
+			var src cpi.CredentialsSource
+			src, cur = dockerconfig.NewConsumerProvider(e.docker).Match(ectx, req, cur, m)
+			if src != nil {
+				creds = src
+			}
+			continue
+		}
+		if len(e.id) > 0 && m(req, cur, e.id) {
+			creds = cpi.NewCredentials(e.props)
+			cur = e.id
+		}
+	}
+	return creds, cur
+}
@@ -0,0 +1,167 @@
+package kubesecrets_test
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/credentials/cpi"
+	local "ocm.software/ocm/api/credentials/extensions/repositories/kubesecrets"
+	"ocm.software/ocm/api/tech/oci/identity"
+	wget "ocm.software/ocm/api/tech/wget/identity"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const NAMESPACE = "ocm"
+
+var dockerconfig = `
+{
+  "auths": {
+    "ghcr.io": {
+      "auth": "bWFuZGVsc29mdDpwYXNzd29yZA=="
+    }
+  }
+}
+`
+
+func dockerSecret(name, data string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: NAMESPACE},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(data)},
+	}
+}
+
+func opaqueSecret(name string, id cpi.ConsumerIdentity, props common.Properties) *corev1.Secret {
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: NAMESPACE},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{},
+	}
+	if id != nil {
+		s.Annotations = map[string]string{local.ANNOTATION_CONSUMER_ID: string(Must(json.Marshal(id)))}
+	}
+	for k, v := range props {
+		s.Data[k] = []byte(v)
+	}
+	return s
+}
+
+var _ = Describe("kubernetes secrets", func() {
+	ghcr := cpi.NewConsumerIdentity(identity.CONSUMER_TYPE, identity.ID_HOSTNAME, "ghcr.io")
+	web := wget.GetConsumerId("https://acme.org/download")
+	webid := cpi.NewConsumerIdentity(wget.CONSUMER_TYPE, identity.ID_HOSTNAME, "acme.org")
+
+	var ctx credentials.Context
+	var secrets corev1client.SecretInterface
+
+	BeforeEach(func() {
+		ctx = credentials.New()
+		secrets = fake.NewClientset(
+			dockerSecret("registry", dockerconfig),
+			opaqueSecret("web", webid, common.Properties{"username": "acme", "password": "secret"}),
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: NAMESPACE}, Type: corev1.SecretTypeTLS},
+		).CoreV1().Secrets(NAMESPACE)
+	})
+
+	AfterEach(func() {
+		MustBeSuccessful(ctx.Finalize())
+	})
+
+	It("serializes repo spec", func() {
+		spec := local.NewRepositorySpec("~/.kube/config", NAMESPACE).WithContext("test").WithWatch(true)
+		data := Must(json.Marshal(spec))
+		Expect(data).To(YAMLEqual(`
+type: KubernetesSecrets
+kubeconfig: ~/.kube/config
+context: test
+namespace: ocm
+watch: true
+`))
+		_, err := ctx.RepositorySpecForConfig(data, nil)
+		MustBeSuccessful(err)
+	})
+
+	It("provides credentials for secrets", func() {
+		repo := Must(local.NewRepository(ctx, local.NewRepositorySpec("", NAMESPACE), secrets))
+
+		Expect(Must(repo.ExistsCredentials("web"))).To(BeTrue())
+		Expect(Must(repo.ExistsCredentials("tls"))).To(BeFalse())
+		Expect(Must(repo.LookupCredentials("web")).Properties()).To(Equal(common.Properties{"username": "acme", "password": "secret"}))
+		Expect(Must(repo.LookupCredentials("ghcr.io")).Properties()).To(Equal(common.Properties{"username": "mandelsoft", "password": "password", "serverAddress": "ghcr.io"}))
+
+		_, err := repo.LookupCredentials("unknown")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("propagates consumer ids", func() {
+		Must(local.NewRepository(ctx, local.NewRepositorySpec("", NAMESPACE), secrets))
+
+		creds := Must(credentials.CredentialsForConsumer(ctx, cpi.NewConsumerIdentity(identity.CONSUMER_TYPE, identity.ID_HOSTNAME, "ghcr.io", identity.ID_PATHPREFIX, "acme"), identity.IdentityMatcher))
+		Expect(creds.Properties()).To(Equal(common.Properties{"username": "mandelsoft", "password": "password", "serverAddress": "ghcr.io"}))
+
+		creds = Must(credentials.CredentialsForConsumer(ctx, web, wget.IdentityMatcher))
+		Expect(creds.Properties()).To(Equal(common.Properties{"username": "acme", "password": "secret"}))
+	})
+
+	It("restricts secrets", func() {
+		Must(local.NewRepository(ctx, local.NewRepositorySpec("", NAMESPACE).WithSecrets("web"), secrets))
+
+		Expect(Must(credentials.CredentialsForConsumer(ctx, ghcr, identity.IdentityMatcher))).To(BeNil())
+		Expect(Must(credentials.CredentialsForConsumer(ctx, web, wget.IdentityMatcher))).NotTo(BeNil())
+	})
+
+	It("omits propagation", func() {
+		repo := Must(local.NewRepository(ctx, local.NewRepositorySpec("", NAMESPACE, false), secrets))
+
+		Expect(Must(repo.ExistsCredentials("web"))).To(BeTrue())
+		Expect(Must(credentials.CredentialsForConsumer(ctx, web, wget.IdentityMatcher))).To(BeNil())
+	})
+
+	It("reads secrets once by default", func() {
+		repo := Must(local.NewRepository(ctx, local.NewRepositorySpec("", NAMESPACE), secrets))
+
+		Must(secrets.Update(context.Background(), opaqueSecret("web", webid, common.Properties{"username": "acme", "password": "rotated"}), metav1.UpdateOptions{}))
+		Consistently(func() common.Properties { return Must(repo.LookupCredentials("web")).Properties() }, 200*time.Millisecond).To(Equal(common.Properties{"username": "acme", "password": "secret"}))
+	})
+
+	It("stops watching when the context is finalized", func() {
+		repo := Must(local.NewRepository(ctx, local.NewRepositorySpec("", NAMESPACE).WithWatch(true), secrets))
+		MustBeSuccessful(ctx.Finalize())
+
+		Must(secrets.Update(context.Background(), opaqueSecret("web", webid, common.Properties{"username": "acme", "password": "rotated"}), metav1.UpdateOptions{}))
+		Consistently(func() common.Properties { return Must(repo.LookupCredentials("web")).Properties() }, 200*time.Millisecond).To(Equal(common.Properties{"username": "acme", "password": "secret"}))
+	})
+
+	It("watches secrets", func() {
+		repo := Must(local.NewRepository(ctx, local.NewRepositorySpec("", NAMESPACE).WithWatch(true), secrets))
+		defer repo.Close()
+
+		lookup := func(id cpi.ConsumerIdentity, m cpi.IdentityMatcher) common.Properties {
+			creds := Must(credentials.CredentialsForConsumer(ctx, id, m))
+			if creds == nil {
+				return nil
+			}
+			return creds.Properties()
+		}
+
+		Must(secrets.Update(context.Background(), opaqueSecret("web", webid, common.Properties{"username": "acme", "password": "rotated"}), metav1.UpdateOptions{}))
+		Eventually(func() common.Properties { return lookup(web, wget.IdentityMatcher) }).To(Equal(common.Properties{"username": "acme", "password": "rotated"}))
+
+		MustBeSuccessful(secrets.Delete(context.Background(), "registry", metav1.DeleteOptions{}))
+		Eventually(func() common.Properties { return lookup(ghcr, identity.IdentityMatcher) }).To(BeNil())
+
+		Must(secrets.Create(context.Background(), dockerSecret("registry", dockerconfig), metav1.CreateOptions{}))
+		Eventually(func() common.Properties { return lookup(ghcr, identity.IdentityMatcher) }).To(Equal(common.Properties{"username": "mandelsoft", "password": "password", "serverAddress": "ghcr.io"}))
+	})
+})
@@ -0,0 +1,246 @@
+package kubesecrets
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/utils"
+)
+
+// RETRY_INTERVAL is the delay used to re-establish a failed watch.
+var RETRY_INTERVAL = 5 * time.Second
+
+type Repository struct {
+	lock      sync.RWMutex
+	ctx       cpi.Context
+	spec      *RepositorySpec
+	namespace string
+	client    corev1client.SecretInterface
+	entries   map[string]*entry
+
+	// lifecycle is valid until the repository is closed.
+	// All requests to the API server are derived from it.
+	lifecycle context.Context
+	cancel    context.CancelFunc
+}
+
+// NewRepository creates a repository for the secrets accessible by the
+// given client. If no client is given, it is created according to the
+// specification (see NewSecretsClient).
+func NewRepository(ctx cpi.Context, spec *RepositorySpec, client corev1client.SecretInterface) (*Repository, error) {
+	ns := spec.Namespace
+	if client == nil {
+		var err error
+		client, ns, err = NewSecretsClient(spec)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r := &Repository{
+		ctx:       datacontext.InternalContextRef(ctx),
+		spec:      spec,
+		namespace: ns,
+		client:    client,
+	}
+
+	r.lifecycle, r.cancel = context.WithCancel(context.Background())
+	version, err := r.list(r.lifecycle)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	if utils.AsBool(spec.Watch) {
+		w, err := r.watch(r.lifecycle, version)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		go r.run(r.lifecycle, w)
+		// stop watching at the latest when the credential context is finalized.
+		r.ctx.Finalizer().WithVoid(r.Close)
+	}
+	if utils.AsBool(spec.PropagateConsumerIdentity, true) {
+		r.ctx.RegisterConsumerProvider(spec.GetKey(), &ConsumerProvider{r})
+	}
+	return r, nil
+}
+
+var _ cpi.Repository = &Repository{}
+
+func (r *Repository) ExistsCredentials(name string) (bool, error) {
+	return r.lookup(name) != nil, nil
+}
+
+func (r *Repository) LookupCredentials(name string) (cpi.Credentials, error) {
+	creds := r.lookup(name)
+	if creds == nil {
+		return nil, errors.ErrNotFound(cpi.KIND_CREDENTIALS, name, r.namespace)
+	}
+	return creds, nil
+}
+
+func (r *Repository) WriteCredentials(name string, creds cpi.Credentials) (cpi.Credentials, error) {
+	return nil, errors.ErrNotSupported("write", "credentials", Type)
+}
+
+// Close ends the lifecycle of the repository and
+// stops watching the secrets.
+func (r *Repository) Close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
+
+// lookup provides the credentials for a secret name or the
+// registry host of a docker config secret.
+func (r *Repository) lookup(name string) cpi.Credentials {
+	for _, e := range r.getEntries() {
+		if creds := e.lookup(name); creds != nil {
+			return creds
+		}
+	}
+	return nil
+}
+
+// getEntries provides the actual entries ordered by secret name.
+func (r *Repository) getEntries() []*entry {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	list := make([]*entry, 0, len(r.entries))
+	for _, e := range r.entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+	return list
+}
+
+func (r *Repository) accepts(secret *corev1.Secret) bool {
+	return len(r.spec.Secrets) == 0 || slices.Contains(r.spec.Secrets, secret.Name)
+}
+
+// newEntry maps an accepted secret to a repository entry. Invalid
+// secrets are logged and ignored.
+func (r *Repository) newEntry(secret *corev1.Secret) *entry {
+	if !r.accepts(secret) {
+		return nil
+	}
+	e, err := newEntry(secret)
+	if err != nil {
+		log.Error("ignoring invalid secret", "namespace", r.namespace, "secret", secret.Name, "error", err)
+	}
+	return e
+}
+
+func (r *Repository) update(secret *corev1.Secret) {
+	e := r.newEntry(secret)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if e == nil {
+		delete(r.entries, secret.Name)
+	} else {
+		r.entries[secret.Name] = e
+	}
+}
+
+func (r *Repository) delete(secret *corev1.Secret) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.entries, secret.Name)
+}
+
+// list reads all secrets and replaces the actual entries.
+// It provides the resource version to start watching from.
+func (r *Repository) list(ctx context.Context) (string, error) {
+	list, err := r.client.List(ctx, metav1.ListOptions{LabelSelector: r.spec.LabelSelector})
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot list secrets in namespace %q", r.namespace)
+	}
+	entries := map[string]*entry{}
+	for i := range list.Items {
+		if e := r.newEntry(&list.Items[i]); e != nil {
+			entries[e.name] = e
+		}
+	}
+	r.lock.Lock()
+	r.entries = entries
+	r.lock.Unlock()
+	return list.ResourceVersion, nil
+}
+
+func (r *Repository) watch(ctx context.Context, version string) (watch.Interface, error) {
+	w, err := r.client.Watch(ctx, metav1.ListOptions{LabelSelector: r.spec.LabelSelector, ResourceVersion: version})
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot watch secrets in namespace %q", r.namespace)
+	}
+	return w, nil
+}
+
+// run processes the secret events until the repository is closed.
+// If the watch fails or is closed by the server, the secrets are
+// listed again and a new watch is started.
+func (r *Repository) run(ctx context.Context, w watch.Interface) {
+	for {
+		r.process(ctx, w)
+		w.Stop()
+		for w = nil; w == nil; {
+			if ctx.Err() != nil {
+				return
+			}
+			version, err := r.list(ctx)
+			if err == nil {
+				w, err = r.watch(ctx, version)
+			}
+			if err != nil {
+				log.Info("cannot watch secrets, retrying", "namespace", r.namespace, "error", err)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(RETRY_INTERVAL):
+				}
+			}
+		}
+	}
+}
+
+func (r *Repository) process(ctx context.Context, w watch.Interface) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-w.ResultChan():
+			if !ok || ctx.Err() != nil {
+				// events pending when the repository is closed are ignored
+				return
+			}
+			switch ev.Type {
+			case watch.Added, watch.Modified:
+				if secret, ok := ev.Object.(*corev1.Secret); ok {
+					r.update(secret)
+				}
+			case watch.Deleted:
+				if secret, ok := ev.Object.(*corev1.Secret); ok {
+					r.delete(secret)
+				}
+			case watch.Error:
+				log.Info("watching secrets failed", "namespace", r.namespace, "error", apierrors.FromObject(ev.Object))
+				return
+			}
+		}
+	}
+}
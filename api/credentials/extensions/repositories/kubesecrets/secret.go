@@ -0,0 +1,86 @@
+package kubesecrets
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/mandelsoft/goutils/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+// ANNOTATION_CONSUMER_ID is the secret annotation used to describe the
+// consumer identity (in JSON) the credentials of a key/value secret
+// are provided for.
+const ANNOTATION_CONSUMER_ID = "ocm.software/consumer-identity"
+
+// entry describes the credentials provided by a secret.
+type entry struct {
+	name string
+	// id is the consumer identity for key/value secrets.
+	id cpi.ConsumerIdentity
+	// props are the credential properties of key/value secrets.
+	props common.Properties
+	// docker is the docker config of dockerconfigjson secrets.
+	docker *configfile.ConfigFile
+}
+
+// newEntry maps a secret to the provided credentials. Secrets of
+// unsupported types are ignored (nil is returned).
+func newEntry(secret *corev1.Secret) (*entry, error) {
+	e := &entry{name: secret.Name}
+	switch secret.Type {
+	case corev1.SecretTypeDockerConfigJson:
+		data, ok := secret.Data[corev1.DockerConfigJsonKey]
+		if !ok {
+			return nil, errors.ErrRequired("secret key", corev1.DockerConfigJsonKey)
+		}
+		cfg, err := config.LoadFromReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid docker config")
+		}
+		e.docker = cfg
+	case corev1.SecretTypeOpaque, corev1.SecretTypeBasicAuth, "":
+		e.props = common.Properties{}
+		for k, v := range secret.Data {
+			e.props[k] = string(v)
+		}
+		if s := secret.Annotations[ANNOTATION_CONSUMER_ID]; s != "" {
+			var id cpi.ConsumerIdentity
+			if err := json.Unmarshal([]byte(s), &id); err != nil {
+				return nil, errors.ErrInvalidWrap(err, "annotation", ANNOTATION_CONSUMER_ID)
+			}
+			e.id = id
+		}
+	default:
+		return nil, nil
+	}
+	return e, nil
+}
+
+func (e *entry) lookup(name string) cpi.Credentials {
+	if e.docker != nil {
+		if _, ok := e.docker.AuthConfigs[name]; !ok {
+			return nil
+		}
+		auth, err := e.docker.GetAuthConfig(name)
+		if err != nil {
+			return nil
+		}
+		props := common.Properties{}
+		props.SetNonEmptyValue(cpi.ATTR_USERNAME, auth.Username)
+		props.SetNonEmptyValue(cpi.ATTR_PASSWORD, auth.Password)
+		props.SetNonEmptyValue(cpi.ATTR_SERVER_ADDRESS, auth.ServerAddress)
+		props.SetNonEmptyValue(cpi.ATTR_IDENTITY_TOKEN, auth.IdentityToken)
+		props.SetNonEmptyValue(cpi.ATTR_REGISTRY_TOKEN, auth.RegistryToken)
+		return cpi.NewCredentials(props)
+	}
+	if name == e.name {
+		return cpi.NewCredentials(e.props)
+	}
+	return nil
+}
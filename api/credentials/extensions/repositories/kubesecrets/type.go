@@ -0,0 +1,94 @@
+package kubesecrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/mandelsoft/goutils/generics"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+const (
+	Type   = "KubernetesSecrets"
+	TypeV1 = Type + runtime.VersionSeparator + "v1"
+)
+
+func init() {
+	cpi.RegisterRepositoryType(cpi.NewRepositoryType[*RepositorySpec](Type))
+	cpi.RegisterRepositoryType(cpi.NewRepositoryType[*RepositorySpec](TypeV1, cpi.WithDescription(usage), cpi.WithFormatSpec(format)))
+}
+
+// RepositorySpec describes a credential repository based on the
+// secrets of a Kubernetes namespace.
+type RepositorySpec struct {
+	runtime.ObjectVersionedType `json:",inline"`
+	KubeConfig                  string   `json:"kubeconfig,omitempty"`
+	Context                     string   `json:"context,omitempty"`
+	Namespace                   string   `json:"namespace,omitempty"`
+	LabelSelector               string   `json:"labelSelector,omitempty"`
+	Secrets                     []string `json:"secrets,omitempty"`
+	Watch                       *bool    `json:"watch,omitempty"`
+	PropagateConsumerIdentity   *bool    `json:"propagateConsumerIdentity,omitempty"`
+}
+
+// NewRepositorySpec creates a new Kubernetes secrets RepositorySpec for
+// a namespace of the cluster selected by the kubeconfig. If no kubeconfig
+// is given, the default kubeconfig or the in-cluster configuration is used.
+func NewRepositorySpec(kubeconfig string, namespace string, propagate ...bool) *RepositorySpec {
+	var p *bool
+	if len(propagate) > 0 {
+		p = generics.Pointer(propagate[0])
+	}
+	return &RepositorySpec{
+		ObjectVersionedType:       runtime.NewVersionedTypedObject(Type),
+		KubeConfig:                kubeconfig,
+		Namespace:                 namespace,
+		PropagateConsumerIdentity: p,
+	}
+}
+
+func (a *RepositorySpec) WithContext(name string) *RepositorySpec {
+	a.Context = name
+	return a
+}
+
+func (a *RepositorySpec) WithLabelSelector(sel string) *RepositorySpec {
+	a.LabelSelector = sel
+	return a
+}
+
+func (a *RepositorySpec) WithSecrets(names ...string) *RepositorySpec {
+	a.Secrets = slices.Clone(names)
+	return a
+}
+
+func (a *RepositorySpec) WithWatch(b bool) *RepositorySpec {
+	a.Watch = generics.Pointer(b)
+	return a
+}
+
+func (a *RepositorySpec) GetType() string {
+	return Type
+}
+
+func (a *RepositorySpec) Repository(ctx cpi.Context, creds cpi.Credentials) (cpi.Repository, error) {
+	r := ctx.GetAttributes().GetOrCreateAttribute(ATTR_REPOS, newRepositories)
+	repos, ok := r.(*Repositories)
+	if !ok {
+		return nil, fmt.Errorf("failed to assert type %T to Repositories", r)
+	}
+	return repos.GetRepository(ctx, a)
+}
+
+func (a *RepositorySpec) GetKey() cpi.ProviderIdentity {
+	spec := *a
+	spec.PropagateConsumerIdentity = nil
+	data, err := json.Marshal(&spec)
+	if err == nil {
+		return cpi.ProviderIdentity(PROVIDER + "/" + string(data))
+	}
+	return cpi.ProviderIdentity(PROVIDER + "/" + spec.KubeConfig + "/" + spec.Namespace)
+}
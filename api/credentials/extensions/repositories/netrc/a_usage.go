@@ -0,0 +1,36 @@
+package netrc
+
+import (
+	"ocm.software/ocm/api/credentials/extensions/repositories/internal/hostcreds"
+	"ocm.software/ocm/api/utils/listformat"
+)
+
+var usage = `
+This repository type can be used to access credentials stored in a netrc
+file (<code>~/.netrc</code>) as used by curl, wget, git and many other
+tools. If enabled, the machine entries are assigned to consumer ids of
+HTTP based consumer types using the <code>hostpath</code> identity matcher,
+by default the types
+` + listformat.FormatList("", hostcreds.DefaultConsumerTypes...) + `
+The machine name is used as hostname (optionally with a port separated by
+a colon). The <code>default</code> entry would match all hosts, including
+hosts requested by untrusted component versions. Therefore, it is only used
+if explicitly enabled with the field <code>useDefault</code>. It has
+a lower precedence than dedicated entries.
+
+The netrc file is not read by default, it must explicitly be configured
+as credential repository.
+
+The login is provided as credential attribute <code>username</code>
+and the password as <code>password</code>. For consumer types requiring
+a token (like <code>Github</code>) the password is additionally provided
+as <code>token</code>.
+`
+
+var format = `The repository specification supports the following fields:
+` + listformat.FormatListElements("", listformat.StringElementDescriptionList{
+	"netrcFile", "*string*: the file path to a netrc file",
+	"consumerTypes", "*[]string*(optional): the consumer types to provide credentials for",
+	"useDefault", "*bool*(optional): provide the default entry for all hosts (default false)",
+	"propagateConsumerIdentity", "*bool*(optional): enable consumer id propagation",
+})
@@ -0,0 +1,46 @@
+package netrc
+
+import (
+	"encoding/json"
+	"sync"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/datacontext"
+)
+
+const ATTR_REPOS = "ocm.software/ocm/api/credentials/extensions/repositories/netrc"
+
+type Repositories struct {
+	lock  sync.Mutex
+	repos map[string]*Repository
+}
+
+func newRepositories(datacontext.Context) interface{} {
+	return &Repositories{
+		repos: map[string]*Repository{},
+	}
+}
+
+// GetRepository provides the repository for a specification.
+// Repositories are cached for the complete specification, because
+// the consumer types and the propagation settings influence the
+// provided consumer ids.
+func (r *Repositories) GetRepository(ctx cpi.Context, spec *RepositorySpec) (*Repository, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	key := string(data)
+	repo := r.repos[key]
+	if repo == nil {
+		repo, err = NewRepository(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		r.repos[key] = repo
+	}
+	return repo, nil
+}
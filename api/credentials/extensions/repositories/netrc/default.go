@@ -0,0 +1,39 @@
+package netrc
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/mandelsoft/filepath/pkg/filepath"
+)
+
+const (
+	// ENV_NETRC is the environment variable used to specify
+	// a non-default netrc file.
+	ENV_NETRC = "NETRC"
+)
+
+// The default netrc file is intentionally not registered as default
+// configuration. Its credentials would silently be provided to any host
+// requested, for example, by resources of untrusted component versions.
+// It must explicitly be configured as credential repository.
+
+// ConfigFileName provides the platform specific name of the netrc file.
+func ConfigFileName() string {
+	if runtime.GOOS == "windows" {
+		return "_netrc"
+	}
+	return ".netrc"
+}
+
+// DefaultConfig provides the path of the default netrc file.
+func DefaultConfig() (string, error) {
+	if p := os.Getenv(ENV_NETRC); p != "" {
+		return p, nil
+	}
+	d, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, ConfigFileName()), nil
+}
@@ -0,0 +1,183 @@
+package netrc
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mandelsoft/goutils/errors"
+)
+
+// Machine is an entry of a netrc file. The default entry
+// has an empty name.
+type Machine struct {
+	Name     string
+	Login    string
+	Password string
+	Account  string
+}
+
+// IsDefault checks whether the entry is the default entry.
+func (m *Machine) IsDefault() bool {
+	return m.Name == ""
+}
+
+// HostPort splits the machine name into host and optional port.
+func (m *Machine) HostPort() (string, string) {
+	if i := strings.LastIndex(m.Name, ":"); i > 0 {
+		return m.Name[:i], m.Name[i+1:]
+	}
+	return m.Name, ""
+}
+
+// Netrc is the content of a netrc file.
+type Netrc struct {
+	Machines []*Machine
+}
+
+// Machine provides the entry for the given name. If no dedicated
+// entry is found, the default entry is returned, if present.
+func (n *Netrc) Machine(name string) *Machine {
+	var def *Machine
+	for _, m := range n.Machines {
+		if m.Name == name && !m.IsDefault() {
+			return m
+		}
+		if m.IsDefault() {
+			def = m
+		}
+	}
+	return def
+}
+
+// Parse parses the content of a netrc file.
+// Macro definitions (macdef) are ignored.
+func Parse(data []byte) (*Netrc, error) {
+	var (
+		n   Netrc
+		cur *Machine
+	)
+	t := &tokenizer{data: string(data)}
+	for {
+		tok, ok, err := t.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		switch tok {
+		case "machine":
+			name, err := t.value(tok)
+			if err != nil {
+				return nil, err
+			}
+			cur = &Machine{Name: name}
+			n.Machines = append(n.Machines, cur)
+		case "default":
+			cur = &Machine{}
+			n.Machines = append(n.Machines, cur)
+		case "login", "password", "account":
+			v, err := t.value(tok)
+			if err != nil {
+				return nil, err
+			}
+			if cur == nil {
+				return nil, errors.Newf("%s without machine", tok)
+			}
+			switch tok {
+			case "login":
+				cur.Login = v
+			case "password":
+				cur.Password = v
+			default:
+				cur.Account = v
+			}
+		case "macdef":
+			if _, err := t.value(tok); err != nil {
+				return nil, err
+			}
+			t.skipMacro()
+		default:
+			return nil, errors.Newf("unexpected token %q", tok)
+		}
+	}
+	return &n, nil
+}
+
+type tokenizer struct {
+	data string
+	pos  int
+}
+
+func (t *tokenizer) value(key string) (string, error) {
+	v, ok, err := t.next()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errors.Newf("missing value for %s", key)
+	}
+	return v, nil
+}
+
+func (t *tokenizer) next() (string, bool, error) {
+	for t.pos < len(t.data) {
+		c := rune(t.data[t.pos])
+		if c == '#' {
+			t.skipLine()
+			continue
+		}
+		if !unicode.IsSpace(c) {
+			break
+		}
+		t.pos++
+	}
+	if t.pos >= len(t.data) {
+		return "", false, nil
+	}
+	if t.data[t.pos] == '"' {
+		return t.quoted()
+	}
+	start := t.pos
+	for t.pos < len(t.data) && !unicode.IsSpace(rune(t.data[t.pos])) {
+		t.pos++
+	}
+	return t.data[start:t.pos], true, nil
+}
+
+func (t *tokenizer) quoted() (string, bool, error) {
+	var b strings.Builder
+	t.pos++
+	for t.pos < len(t.data) {
+		c := t.data[t.pos]
+		t.pos++
+		switch c {
+		case '"':
+			return b.String(), true, nil
+		case '\\':
+			if t.pos < len(t.data) {
+				b.WriteByte(t.data[t.pos])
+				t.pos++
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", false, errors.Newf("unterminated quoted token")
+}
+
+func (t *tokenizer) skipLine() {
+	for t.pos < len(t.data) && t.data[t.pos] != '\n' {
+		t.pos++
+	}
+}
+
+// skipMacro skips a macro definition, which ends with an empty line.
+func (t *tokenizer) skipMacro() {
+	i := strings.Index(t.data[t.pos:], "\n\n")
+	if i < 0 {
+		t.pos = len(t.data)
+	} else {
+		t.pos += i + 2
+	}
+}
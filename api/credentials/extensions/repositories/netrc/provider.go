@@ -0,0 +1,51 @@
+package netrc
+
+import (
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/credentials/extensions/repositories/internal/hostcreds"
+)
+
+const PROVIDER = "ocm.software/credentialprovider/" + Type
+
+type ConsumerProvider struct {
+	netrc  *Netrc
+	types  []string
+	useDef bool
+}
+
+var _ cpi.ConsumerProvider = (*ConsumerProvider)(nil)
+
+func (p *ConsumerProvider) Unregister(_ cpi.ProviderIdentity) {
+}
+
+func (p *ConsumerProvider) Match(ectx cpi.EvaluationContext, req cpi.ConsumerIdentity, cur cpi.ConsumerIdentity, m cpi.IdentityMatcher) (cpi.CredentialsSource, cpi.ConsumerIdentity) {
+	return p.get(req, cur, m)
+}
+
+func (p *ConsumerProvider) Get(req cpi.ConsumerIdentity) (cpi.CredentialsSource, bool) {
+	creds, _ := p.get(req, nil, cpi.CompleteMatch)
+	return creds, creds != nil
+}
+
+func (p *ConsumerProvider) get(req cpi.ConsumerIdentity, cur cpi.ConsumerIdentity, m cpi.IdentityMatcher) (cpi.CredentialsSource, cpi.ConsumerIdentity) {
+	typ := req.Type()
+	if !hostcreds.Supports(p.types, typ) {
+		return nil, cur
+	}
+
+	var creds cpi.CredentialsSource
+	for _, e := range p.netrc.Machines {
+		if e.IsDefault() && !p.useDef {
+			// the default entry would match any host, it is only
+			// used on explicit request.
+			continue
+		}
+		host, port := e.HostPort()
+		id := hostcreds.ConsumerIdentity(typ, host, port)
+		if m(req, cur, id) {
+			creds = hostcreds.Credentials(typ, e.Login, e.Password)
+			cur = id
+		}
+	}
+	return creds, cur
+}
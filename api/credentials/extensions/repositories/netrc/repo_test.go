@@ -0,0 +1,134 @@
+package netrc_test
+
+import (
+	"encoding/json"
+	"reflect"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/credentials/cpi"
+	local "ocm.software/ocm/api/credentials/extensions/repositories/netrc"
+	github "ocm.software/ocm/api/tech/github/identity"
+	maven "ocm.software/ocm/api/tech/maven/identity"
+	oci "ocm.software/ocm/api/tech/oci/identity"
+	wget "ocm.software/ocm/api/tech/wget/identity"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+var _ = Describe("netrc", func() {
+	var ctx credentials.Context
+
+	BeforeEach(func() {
+		ctx = credentials.New()
+	})
+
+	Context("parser", func() {
+		It("parses entries", func() {
+			n := Must(local.Parse([]byte(`
+machine a.org login a password "p w"
+# comment
+macdef upload
+put file
+
+machine b.org
+login b
+password pb
+account acc
+default login anonymous
+`)))
+			Expect(n.Machines).To(Equal([]*local.Machine{
+				{Name: "a.org", Login: "a", Password: "p w"},
+				{Name: "b.org", Login: "b", Password: "pb", Account: "acc"},
+				{Login: "anonymous"},
+			}))
+			Expect(n.Machine("b.org").Login).To(Equal("b"))
+			Expect(n.Machine("c.org").IsDefault()).To(BeTrue())
+		})
+
+		It("rejects invalid content", func() {
+			ExpectError(local.Parse([]byte(`login a`))).To(MatchError("login without machine"))
+			ExpectError(local.Parse([]byte(`machine a.org login`))).To(MatchError("missing value for login"))
+			ExpectError(local.Parse([]byte(`machine a.org user a`))).To(MatchError(`unexpected token "user"`))
+		})
+	})
+
+	Context("repository", func() {
+		specdata := `{"type":"Netrc","netrcFile":"testdata/netrc"}`
+
+		It("serializes repo spec", func() {
+			spec := local.NewRepositorySpec("testdata/netrc")
+			Expect(json.Marshal(spec)).To(Equal([]byte(specdata)))
+		})
+
+		It("resolves repository", func() {
+			repo := Must(ctx.RepositoryForConfig([]byte(specdata), nil))
+			Expect(reflect.TypeOf(repo).String()).To(Equal("*netrc.Repository"))
+		})
+
+		It("retrieves credentials", func() {
+			repo := Must(ctx.RepositoryForConfig([]byte(specdata), nil))
+			Expect(Must(repo.LookupCredentials("repo.acme.org")).Properties()).To(Equal(common.Properties{
+				cpi.ATTR_USERNAME: "mandelsoft",
+				cpi.ATTR_PASSWORD: "password",
+			}))
+			Expect(repo.ExistsCredentials("other.org")).To(BeFalse())
+			Expect(repo.ExistsCredentials("")).To(BeTrue())
+		})
+
+		It("propagates credentials to consumer identities", func() {
+			Must(ctx.RepositoryForConfig([]byte(specdata), nil))
+
+			creds := Must(credentials.CredentialsForConsumer(ctx, wget.GetConsumerId("https://repo.acme.org/some/path")))
+			Expect(creds.Properties()).To(Equal(common.Properties{
+				cpi.ATTR_USERNAME: "mandelsoft",
+				cpi.ATTR_PASSWORD: "password",
+			}))
+
+			creds = Must(credentials.CredentialsForConsumer(ctx, github.GetConsumerId("https://github.com", "acme/repo")))
+			Expect(creds.Properties()).To(Equal(common.Properties{
+				cpi.ATTR_USERNAME: "acme",
+				cpi.ATTR_PASSWORD: `a "quoted" token`,
+				cpi.ATTR_TOKEN:    `a "quoted" token`,
+			}))
+
+			creds = Must(credentials.CredentialsForConsumer(ctx, wget.GetConsumerId("http://localhost:8080/path")))
+			Expect(creds.GetProperty(cpi.ATTR_USERNAME)).To(Equal("local"))
+
+			Expect(credentials.CredentialsForConsumer(ctx, Must(maven.GetConsumerId("https://other.org/maven", "org.acme")))).To(BeNil())
+		})
+
+		It("propagates default entry on request", func() {
+			Must(ctx.RepositoryForSpec(local.NewRepositorySpec("testdata/netrc").WithUseDefault(true)))
+
+			creds := Must(credentials.CredentialsForConsumer(ctx, Must(maven.GetConsumerId("https://other.org/maven", "org.acme"))))
+			Expect(creds.GetProperty(cpi.ATTR_USERNAME)).To(Equal("anonymous"))
+			creds = Must(credentials.CredentialsForConsumer(ctx, wget.GetConsumerId("https://repo.acme.org/some/path")))
+			Expect(creds.GetProperty(cpi.ATTR_USERNAME)).To(Equal("mandelsoft"))
+		})
+
+		It("caches repositories per specification", func() {
+			r1 := Must(ctx.RepositoryForSpec(local.NewRepositorySpec("testdata/netrc")))
+			r2 := Must(ctx.RepositoryForSpec(local.NewRepositorySpec("testdata/netrc").WithConsumerTypes(wget.CONSUMER_TYPE)))
+			Expect(r1).NotTo(BeIdenticalTo(r2))
+			Expect(Must(ctx.RepositoryForSpec(local.NewRepositorySpec("testdata/netrc")))).To(BeIdenticalTo(r1))
+		})
+
+		It("ignores other consumer types", func() {
+			Must(ctx.RepositoryForConfig([]byte(specdata), nil))
+			Expect(credentials.CredentialsForConsumer(ctx, oci.GetConsumerId("repo.acme.org", "repo"))).To(BeNil())
+		})
+
+		It("prefers explicit credentials", func() {
+			Must(ctx.RepositoryForSpec(local.NewRepositorySpec("testdata/netrc").WithConsumerTypes(wget.CONSUMER_TYPE)))
+			ctx.SetCredentialsForConsumer(wget.GetConsumerId("https://repo.acme.org/some"), credentials.DirectCredentials{
+				cpi.ATTR_USERNAME: "explicit",
+			})
+			creds := Must(credentials.CredentialsForConsumer(ctx, wget.GetConsumerId("https://repo.acme.org/some/path")))
+			Expect(creds.GetProperty(cpi.ATTR_USERNAME)).To(Equal("explicit"))
+			Expect(credentials.CredentialsForConsumer(ctx, github.GetConsumerId("https://github.com", "acme/repo"))).To(BeNil())
+		})
+	})
+})
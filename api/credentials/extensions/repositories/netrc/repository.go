@@ -0,0 +1,107 @@
+package netrc
+
+import (
+	"sync"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/datacontext/attrs/vfsattr"
+	"ocm.software/ocm/api/utils"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+type Repository struct {
+	lock      sync.RWMutex
+	ctx       cpi.Context
+	fs        vfs.FileSystem
+	key       cpi.ProviderIdentity
+	path      string
+	types     []string
+	useDef    bool
+	propagate bool
+	netrc     *Netrc
+}
+
+var _ cpi.Repository = (*Repository)(nil)
+
+func NewRepository(ctx cpi.Context, spec *RepositorySpec) (*Repository, error) {
+	if spec.NetrcFile == "" {
+		return nil, errors.New("netrc path not provided")
+	}
+	p, err := utils.ResolvePath(spec.NetrcFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot resolve path %q", spec.NetrcFile)
+	}
+	r := &Repository{
+		ctx:       datacontext.InternalContextRef(ctx),
+		fs:        vfsattr.Get(ctx),
+		key:       spec.GetKey(),
+		path:      p,
+		types:     spec.ConsumerTypes,
+		useDef:    utils.AsBool(spec.UseDefault),
+		propagate: utils.AsBool(spec.PropagateConsumerIdentity, true),
+	}
+	err = r.Read(true)
+	return r, err
+}
+
+// ExistsCredentials checks for a machine entry. The name of
+// the default entry is the empty string.
+func (r *Repository) ExistsCredentials(name string) (bool, error) {
+	err := r.Read(false)
+	if err != nil {
+		return false, err
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	m := r.netrc.Machine(name)
+	return m != nil && m.Name == name, nil
+}
+
+func (r *Repository) LookupCredentials(name string) (cpi.Credentials, error) {
+	exists, err := r.ExistsCredentials(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.ErrNotFound(cpi.KIND_CREDENTIALS, name, Type)
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return newCredentials(r.netrc.Machine(name)), nil
+}
+
+func (r *Repository) WriteCredentials(_ string, _ cpi.Credentials) (cpi.Credentials, error) {
+	return nil, errors.ErrNotSupported("write", "credentials", Type)
+}
+
+func (r *Repository) Read(force bool) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !force && r.netrc != nil {
+		return nil
+	}
+	data, err := vfs.ReadFile(r.fs, r.path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read netrc file %q", r.path)
+	}
+	n, err := Parse(data)
+	if err != nil {
+		return errors.Wrapf(err, "invalid netrc file %q", r.path)
+	}
+	r.netrc = n
+	if r.propagate {
+		r.ctx.RegisterConsumerProvider(r.key, &ConsumerProvider{n, r.types, r.useDef})
+	}
+	return nil
+}
+
+func newCredentials(m *Machine) cpi.Credentials {
+	props := common.Properties{}
+	props.SetNonEmptyValue(cpi.ATTR_USERNAME, m.Login)
+	props.SetNonEmptyValue(cpi.ATTR_PASSWORD, m.Password)
+	return cpi.NewCredentials(props)
+}
@@ -0,0 +1,88 @@
+package netrc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mandelsoft/goutils/generics"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/utils"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+const (
+	Type   = "Netrc"
+	TypeV1 = Type + runtime.VersionSeparator + "v1"
+)
+
+func init() {
+	cpi.RegisterRepositoryType(cpi.NewRepositoryType[*RepositorySpec](Type))
+	cpi.RegisterRepositoryType(cpi.NewRepositoryType[*RepositorySpec](TypeV1, cpi.WithDescription(usage), cpi.WithFormatSpec(format)))
+}
+
+// RepositorySpec describes a netrc file based credential repository.
+type RepositorySpec struct {
+	runtime.ObjectVersionedType `json:",inline"`
+	NetrcFile                   string   `json:"netrcFile,omitempty"`
+	ConsumerTypes               []string `json:"consumerTypes,omitempty"`
+	UseDefault                  *bool    `json:"useDefault,omitempty"`
+	PropagateConsumerIdentity   *bool    `json:"propagateConsumerIdentity,omitempty"`
+}
+
+// NewRepositorySpec creates a new netrc RepositorySpec.
+// If no path is given, the default netrc file is used.
+func NewRepositorySpec(path string, propagate ...bool) *RepositorySpec {
+	var p *bool
+	if path == "" {
+		d, err := DefaultConfig()
+		if err == nil {
+			path = d
+		}
+	}
+	if len(propagate) > 0 {
+		p = generics.Pointer(utils.OptionalDefaultedBool(true, propagate...))
+	}
+	return &RepositorySpec{
+		ObjectVersionedType:       runtime.NewVersionedTypedObject(Type),
+		NetrcFile:                 path,
+		PropagateConsumerIdentity: p,
+	}
+}
+
+// WithConsumerTypes restricts the consumer types the credentials
+// are provided for.
+func (rs *RepositorySpec) WithConsumerTypes(types ...string) *RepositorySpec {
+	rs.ConsumerTypes = types
+	return rs
+}
+
+// WithUseDefault enables the propagation of the default entry
+// for all hosts not covered by a dedicated machine entry.
+func (rs *RepositorySpec) WithUseDefault(b bool) *RepositorySpec {
+	rs.UseDefault = generics.Pointer(b)
+	return rs
+}
+
+func (rs *RepositorySpec) GetType() string {
+	return Type
+}
+
+func (rs *RepositorySpec) Repository(ctx cpi.Context, _ cpi.Credentials) (cpi.Repository, error) {
+	r := ctx.GetAttributes().GetOrCreateAttribute(ATTR_REPOS, newRepositories)
+	repos, ok := r.(*Repositories)
+	if !ok {
+		return nil, fmt.Errorf("failed to assert type %T to Repositories", r)
+	}
+	return repos.GetRepository(ctx, rs)
+}
+
+func (rs *RepositorySpec) GetKey() cpi.ProviderIdentity {
+	spec := *rs
+	spec.PropagateConsumerIdentity = nil
+	data, err := json.Marshal(&spec)
+	if err == nil {
+		return cpi.ProviderIdentity(PROVIDER + "/" + string(data))
+	}
+	return cpi.ProviderIdentity(PROVIDER + "/" + spec.NetrcFile)
+}
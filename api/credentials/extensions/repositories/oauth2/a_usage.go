@@ -0,0 +1,61 @@
+package oauth2
+
+import (
+	"ocm.software/ocm/api/credentials/extensions/repositories/oauth2/identity"
+	"ocm.software/ocm/api/utils/listformat"
+)
+
+var usage = `
+This repository type can be used to provide short-lived access tokens
+requested from an OAuth2 token endpoint. Two flows are supported:
+
+- <code>` + FLOW_CLIENT_CREDENTIALS + `</code>: the OAuth2 client credentials grant.
+  It requires a client id and a client secret.
+- <code>` + FLOW_TOKEN_EXCHANGE + `</code>: the OAuth2 token exchange grant
+  (RFC 8693). A subject token, for example the OIDC token of a CI job,
+  is exchanged for an access token, for example a registry token.
+  The subject token is read from a file, an environment variable or
+  the credentials for the token endpoint. It is read again for every
+  exchange, so rotated tokens are picked up.
+
+The client secret (attribute <code>` + identity.ATTR_CLIENTSECRET + `</code>) and
+the subject token (attribute <code>` + identity.ATTR_SUBJECT_TOKEN + `</code>) are
+taken from the credentials given for the repository or from the credentials
+configured for the consumer type <code>` + identity.CONSUMER_TYPE + `</code>
+describing the token endpoint.
+
+The token endpoint must use HTTPS. Plain HTTP is only accepted if the
+field <code>insecure</code> is set, because client secrets and tokens
+would be transferred unprotected.
+
+Tokens are requested not before credentials are required. They are cached
+until they expire and are refreshed transparently, using the refresh token
+if provided by the token endpoint, otherwise by executing the flow again.
+
+The token is provided for the configured consumer identity patterns with
+the credential attributes <code>token</code> and <code>identityToken</code>.
+If a username is configured, it is additionally provided as
+<code>username</code>/<code>password</code> pair, as required for helm
+repositories or OCI registries accepting tokens as password.
+The identity matcher of the requested consumer type is used to evaluate
+the identity patterns. The token can also be looked up by any name.
+`
+
+var format = `The repository specification supports the following fields:
+` + listformat.FormatListElements("", listformat.StringElementDescriptionList{
+	"tokenURL", "*string*: the URL of the token endpoint",
+	"insecure", "*bool*(optional): permit a token endpoint using plain HTTP",
+	"flow", "*string*(optional): the flow to use (<code>" + FLOW_CLIENT_CREDENTIALS + "</code> (default) or <code>" + FLOW_TOKEN_EXCHANGE + "</code>)",
+	"clientId", "*string*(optional): the OAuth2 client id",
+	"authStyle", "*string*(optional): client authentication (<code>" + AUTH_STYLE_BASIC + "</code> (default) or <code>" + AUTH_STYLE_POST + "</code>)",
+	"scopes", "*[]string*(optional): the requested scopes",
+	"audience", "*string*(optional): the requested audience",
+	"resource", "*string*(optional): the requested resource",
+	"subjectTokenType", "*string*(optional): the type of the subject token (default <code>" + TOKEN_TYPE_JWT + "</code>)",
+	"subjectTokenFile", "*string*(optional): the path of a file containing the subject token",
+	"subjectTokenEnv", "*string*(optional): the name of an environment variable containing the subject token",
+	"requestedTokenType", "*string*(optional): the requested token type for the token exchange",
+	"username", "*string*(optional): the username provided together with the token",
+	"consumers", "*[]map[string]string*: the consumer identity patterns the token is provided for",
+	"propagateConsumerIdentity", "*bool*(optional): enable consumer id propagation (default true)",
+})
@@ -0,0 +1,47 @@
+package oauth2
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/datacontext"
+)
+
+const ATTR_REPOS = "ocm.software/ocm/api/credentials/extensions/repositories/oauth2"
+
+// Repositories caches the repositories, and therefore the
+// issued tokens, per repository specification and credentials.
+type Repositories struct {
+	lock  sync.Mutex
+	repos map[cpi.ProviderIdentity]*Repository
+}
+
+func newRepositories(datacontext.Context) interface{} {
+	return &Repositories{
+		repos: map[cpi.ProviderIdentity]*Repository{},
+	}
+}
+
+func (r *Repositories) GetRepository(ctx cpi.Context, spec *RepositorySpec, creds cpi.Credentials) (*Repository, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var err error
+	key := spec.GetKey()
+	if creds != nil {
+		data, _ := json.Marshal(creds.Properties())
+		h := sha256.Sum256(data)
+		key = cpi.ProviderIdentity(string(key) + "/" + hex.EncodeToString(h[:]))
+	}
+	repo := r.repos[key]
+	if repo == nil {
+		repo, err = NewRepository(ctx, spec, creds)
+		if err == nil {
+			r.repos[key] = repo
+		}
+	}
+	return repo, err
+}
@@ -0,0 +1,108 @@
+package identity
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/credentials/identity/hostpath"
+	"ocm.software/ocm/api/utils/listformat"
+)
+
+const CONSUMER_TYPE = "OAuth2TokenEndpoint"
+
+// identity properties.
+const (
+	ID_HOSTNAME   = hostpath.ID_HOSTNAME
+	ID_SCHEME     = hostpath.ID_SCHEME
+	ID_PORT       = hostpath.ID_PORT
+	ID_PATHPREFIX = hostpath.ID_PATHPREFIX
+	ID_CLIENTID   = "clientId"
+)
+
+// credential properties.
+const (
+	ATTR_CLIENTID      = "clientId"
+	ATTR_CLIENTSECRET  = "clientSecret"
+	ATTR_SUBJECT_TOKEN = "subjectToken"
+)
+
+var identityMatcher = hostpath.IdentityMatcher(CONSUMER_TYPE)
+
+func IdentityMatcher(request, cur, id cpi.ConsumerIdentity) bool {
+	if id[ID_CLIENTID] != "" && id[ID_CLIENTID] != request[ID_CLIENTID] {
+		return false
+	}
+	return identityMatcher(request, cur, id)
+}
+
+func init() {
+	attrs := listformat.FormatListElements("", listformat.StringElementDescriptionList{
+		ATTR_CLIENTID, "OAuth2 client id (if not configured for the repository)",
+		ATTR_CLIENTSECRET, "OAuth2 client secret",
+		ATTR_SUBJECT_TOKEN, "subject token used for the token exchange flow",
+	})
+	ids := listformat.FormatListElements("", listformat.StringElementDescriptionList{
+		ID_HOSTNAME, "token endpoint host",
+		ID_SCHEME, "(optional) URL scheme",
+		ID_PORT, "(optional) server port",
+		ID_PATHPREFIX, "(optional) path prefix of the token endpoint",
+		ID_CLIENTID, "(optional) OAuth2 client id",
+	})
+	cpi.RegisterStandardIdentity(CONSUMER_TYPE, IdentityMatcher,
+		`OAuth2 token endpoint credential matcher
+
+This matcher matches credentials for an OAuth2 token endpoint used
+by the <code>OAuth2</code> credential repository to request short-lived
+access tokens.
+It uses the following identity attributes:
+`+ids,
+		attrs)
+}
+
+func GetConsumerId(tokenurl string, clientid string) (cpi.ConsumerIdentity, error) {
+	if tokenurl == "" {
+		return nil, errors.Newf("token url must be given")
+	}
+	u, err := url.Parse(tokenurl)
+	if err != nil {
+		return nil, errors.ErrInvalidWrap(err, "token url", tokenurl)
+	}
+
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		if strings.LastIndex(host, ":") >= 0 {
+			return nil, errors.ErrInvalidWrap(err, "token url", tokenurl)
+		}
+		host = u.Host
+	}
+
+	id := cpi.ConsumerIdentity{
+		cpi.ID_TYPE: CONSUMER_TYPE,
+		ID_HOSTNAME: host,
+	}
+	if u.Scheme != "" {
+		id[ID_SCHEME] = u.Scheme
+	}
+	if port != "" {
+		id[ID_PORT] = port
+	}
+	if p := strings.Trim(u.Path, "/"); p != "" {
+		id[ID_PATHPREFIX] = p
+	}
+	if clientid != "" {
+		id[ID_CLIENTID] = clientid
+	}
+	return id, nil
+}
+
+func GetCredentials(ctx cpi.ContextProvider, tokenurl, clientid string) (cpi.Credentials, error) {
+	id, err := GetConsumerId(tokenurl, clientid)
+	if err != nil {
+		return nil, err
+	}
+	return cpi.CredentialsForConsumer(ctx.CredentialsContext(), id, IdentityMatcher)
+}
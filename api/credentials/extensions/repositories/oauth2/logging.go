@@ -0,0 +1,10 @@
+package oauth2
+
+import (
+	ocmlog "ocm.software/ocm/api/utils/logging"
+)
+
+var (
+	REALM = ocmlog.DefineSubRealm("OAuth2 Token Access", "credentials", "oauth2")
+	log   = ocmlog.DynamicLogger(REALM)
+)
@@ -0,0 +1,57 @@
+package oauth2
+
+import (
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/credentials/extensions/repositories/oauth2/identity"
+)
+
+const PROVIDER = "ocm.software/credentialprovider/" + Type
+
+// ConsumerProvider provides the access token of a repository
+// for the consumer identity patterns configured for the repository.
+type ConsumerProvider struct {
+	repo *Repository
+}
+
+var _ cpi.ConsumerProvider = (*ConsumerProvider)(nil)
+
+func (p *ConsumerProvider) Unregister(_ cpi.ProviderIdentity) {
+}
+
+func (p *ConsumerProvider) Match(ectx cpi.EvaluationContext, req cpi.ConsumerIdentity, cur cpi.ConsumerIdentity, m cpi.IdentityMatcher) (cpi.CredentialsSource, cpi.ConsumerIdentity) {
+	return p.get(req, cur, m)
+}
+
+func (p *ConsumerProvider) Get(req cpi.ConsumerIdentity) (cpi.CredentialsSource, bool) {
+	creds, _ := p.get(req, nil, cpi.CompleteMatch)
+	return creds, creds != nil
+}
+
+func (p *ConsumerProvider) get(req cpi.ConsumerIdentity, cur cpi.ConsumerIdentity, m cpi.IdentityMatcher) (cpi.CredentialsSource, cpi.ConsumerIdentity) {
+	// tokens are never provided for token endpoints to avoid
+	// recursive token requests.
+	if req.Type() == identity.CONSUMER_TYPE {
+		return nil, cur
+	}
+
+	var creds cpi.CredentialsSource
+	for _, id := range p.repo.spec.Consumers {
+		if m(req, cur, id) {
+			creds = &credentialsSource{p.repo}
+			cur = id
+		}
+	}
+	return creds, cur
+}
+
+// credentialsSource requests the token not before
+// the credentials are required.
+type credentialsSource struct {
+	repo *Repository
+}
+
+var _ cpi.CredentialsSource = (*credentialsSource)(nil)
+
+func (c *credentialsSource) Credentials(_ cpi.Context, _ ...cpi.CredentialsSource) (cpi.Credentials, error) {
+	return c.repo.Credentials()
+}
@@ -0,0 +1,321 @@
+package oauth2_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/vfs/pkg/memoryfs"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/credentials/cpi"
+	local "ocm.software/ocm/api/credentials/extensions/repositories/oauth2"
+	"ocm.software/ocm/api/credentials/extensions/repositories/oauth2/identity"
+	"ocm.software/ocm/api/datacontext/attrs/vfsattr"
+	helmidentity "ocm.software/ocm/api/tech/helm/identity"
+	ociidentity "ocm.software/ocm/api/tech/oci/identity"
+	wgetidentity "ocm.software/ocm/api/tech/wget/identity"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const (
+	CLIENT = "ocm"
+	SECRET = "client-secret"
+)
+
+// tokenServer is a stand-in for an OAuth2 token endpoint.
+type tokenServer struct {
+	lock      sync.Mutex
+	requests  []url.Values
+	expiresIn int
+	refresh   bool
+	count     int
+}
+
+func (s *tokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	params := r.PostForm
+	if u, p, ok := r.BasicAuth(); ok {
+		params.Set("basic_auth", u+":"+p)
+	}
+	s.requests = append(s.requests, params)
+
+	switch params.Get("grant_type") {
+	case local.GRANT_CLIENT_CREDENTIALS:
+		if params.Get("basic_auth") != CLIENT+":"+SECRET {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+	case local.GRANT_TOKEN_EXCHANGE, local.GRANT_REFRESH_TOKEN:
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.count++
+	resp := map[string]interface{}{
+		"access_token": fmt.Sprintf("token-%d", s.count),
+		"token_type":   "Bearer",
+	}
+	if s.expiresIn > 0 {
+		resp["expires_in"] = s.expiresIn
+	}
+	if s.refresh {
+		resp["refresh_token"] = fmt.Sprintf("refresh-%d", s.count)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *tokenServer) Requests() []url.Values {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.requests
+}
+
+var _ = Describe("oauth2 credentials", func() {
+	ocireg := credentials.NewConsumerIdentity(ociidentity.CONSUMER_TYPE, ociidentity.ID_HOSTNAME, "registry.acme.org")
+
+	var ctx credentials.Context
+	var fs vfs.FileSystem
+	var token *tokenServer
+	var server *httptest.Server
+	var tokenURL string
+
+	BeforeEach(func() {
+		ctx = credentials.New()
+		fs = memoryfs.New()
+		vfsattr.Set(ctx, fs)
+		token = &tokenServer{expiresIn: 3600}
+		server = httptest.NewServer(token)
+		tokenURL = server.URL + "/oauth/token"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("client credentials", func() {
+		It("requests and caches token", func() {
+			spec := local.NewRepositorySpec(tokenURL, CLIENT, ocireg).WithInsecure().WithScopes("pull", "push")
+			repo := Must(ctx.RepositoryForSpec(spec, cpi.DirectCredentials{identity.ATTR_CLIENTSECRET: SECRET}))
+
+			Expect(Must(repo.LookupCredentials("any")).Properties()).To(Equal(common.Properties{
+				cpi.ATTR_TOKEN:          "token-1",
+				cpi.ATTR_IDENTITY_TOKEN: "token-1",
+			}))
+			Expect(Must(repo.LookupCredentials("any")).GetProperty(cpi.ATTR_TOKEN)).To(Equal("token-1"))
+
+			Expect(token.Requests()).To(Equal([]url.Values{{
+				"grant_type": {local.GRANT_CLIENT_CREDENTIALS},
+				"scope":      {"pull push"},
+				"basic_auth": {CLIENT + ":" + SECRET},
+			}}))
+		})
+
+		It("uses credentials of token endpoint consumer", func() {
+			ctx.SetCredentialsForConsumer(Must(identity.GetConsumerId(server.URL, "")),
+				credentials.DirectCredentials{identity.ATTR_CLIENTSECRET: SECRET})
+
+			spec := local.NewRepositorySpec(tokenURL, CLIENT, ocireg).WithInsecure().WithUsername("oauth2")
+			Must(ctx.RepositoryForSpec(spec))
+
+			creds := Must(ociidentity.GetCredentials(ctx, "registry.acme.org", "acme/repo"))
+			Expect(creds.Properties()).To(Equal(common.Properties{
+				cpi.ATTR_USERNAME:       "oauth2",
+				cpi.ATTR_PASSWORD:       "token-1",
+				cpi.ATTR_TOKEN:          "token-1",
+				cpi.ATTR_IDENTITY_TOKEN: "token-1",
+			}))
+		})
+
+		It("posts client credentials", func() {
+			spec := local.NewRepositorySpec(tokenURL, CLIENT).WithInsecure()
+			spec.AuthStyle = local.AUTH_STYLE_POST
+			repo := Must(ctx.RepositoryForSpec(spec, cpi.DirectCredentials{identity.ATTR_CLIENTSECRET: SECRET}))
+
+			ExpectError(repo.LookupCredentials("any")).To(MatchError(fmt.Sprintf("token request to %q failed: invalid_client", tokenURL)))
+			Expect(token.Requests()[0]).To(Equal(url.Values{
+				"grant_type":    {local.GRANT_CLIENT_CREDENTIALS},
+				"client_id":     {CLIENT},
+				"client_secret": {SECRET},
+			}))
+		})
+
+		It("fails without secret", func() {
+			repo := Must(ctx.RepositoryForSpec(local.NewRepositorySpec(tokenURL, CLIENT).WithInsecure()))
+			ExpectError(repo.LookupCredentials("any")).To(MatchError(fmt.Sprintf("client id and secret required for flow \"client_credentials\" of %s", tokenURL)))
+			Expect(token.Requests()).To(BeEmpty())
+		})
+	})
+
+	Context("token exchange", func() {
+		It("exchanges subject token from file", func() {
+			MustBeSuccessful(vfs.WriteFile(fs, "/token", []byte("ci-job-token\n"), 0o600))
+			spec := local.NewRepositorySpec(tokenURL, "", ocireg).WithInsecure().
+				WithTokenExchange("").
+				WithSubjectTokenFile("/token").
+				WithAudience("registry.acme.org")
+			spec.RequestedTokenType = local.TOKEN_TYPE_ACCESS_TOKEN
+			Must(ctx.RepositoryForSpec(spec))
+
+			creds := Must(ociidentity.GetCredentials(ctx, "registry.acme.org", "acme/repo"))
+			Expect(creds.GetProperty(cpi.ATTR_IDENTITY_TOKEN)).To(Equal("token-1"))
+			Expect(token.Requests()).To(Equal([]url.Values{{
+				"grant_type":           {local.GRANT_TOKEN_EXCHANGE},
+				"subject_token":        {"ci-job-token"},
+				"subject_token_type":   {local.TOKEN_TYPE_JWT},
+				"requested_token_type": {local.TOKEN_TYPE_ACCESS_TOKEN},
+				"audience":             {"registry.acme.org"},
+			}}))
+		})
+
+		It("exchanges subject token from credentials", func() {
+			spec := local.NewRepositorySpec(tokenURL, CLIENT).WithInsecure().WithTokenExchange(local.TOKEN_TYPE_ID_TOKEN)
+			repo := Must(ctx.RepositoryForSpec(spec, cpi.DirectCredentials{identity.ATTR_SUBJECT_TOKEN: "id-token"}))
+
+			Expect(Must(repo.LookupCredentials("any")).GetProperty(cpi.ATTR_TOKEN)).To(Equal("token-1"))
+			Expect(token.Requests()).To(Equal([]url.Values{{
+				"grant_type":         {local.GRANT_TOKEN_EXCHANGE},
+				"client_id":          {CLIENT},
+				"subject_token":      {"id-token"},
+				"subject_token_type": {local.TOKEN_TYPE_ID_TOKEN},
+			}}))
+		})
+
+		It("fails without subject token", func() {
+			spec := local.NewRepositorySpec(tokenURL, "").WithInsecure().WithTokenExchange("").WithSubjectTokenFile("/token")
+			repo := Must(ctx.RepositoryForSpec(spec))
+			ExpectError(repo.LookupCredentials("any")).To(MatchError(ContainSubstring("cannot read subject token file \"/token\"")))
+		})
+	})
+
+	Context("expiry", func() {
+		It("refreshes expired token", func() {
+			// tokens expiring within the expiry skew are treated as expired.
+			token.expiresIn = 5
+			token.refresh = true
+			spec := local.NewRepositorySpec(tokenURL, CLIENT).WithInsecure()
+			repo := Must(ctx.RepositoryForSpec(spec, cpi.DirectCredentials{identity.ATTR_CLIENTSECRET: SECRET}))
+
+			Expect(Must(repo.LookupCredentials("any")).GetProperty(cpi.ATTR_TOKEN)).To(Equal("token-1"))
+			Expect(Must(repo.LookupCredentials("any")).GetProperty(cpi.ATTR_TOKEN)).To(Equal("token-2"))
+			Expect(Must(repo.LookupCredentials("any")).GetProperty(cpi.ATTR_TOKEN)).To(Equal("token-3"))
+
+			requests := token.Requests()
+			Expect(len(requests)).To(Equal(3))
+			Expect(requests[1]).To(Equal(url.Values{
+				"grant_type":    {local.GRANT_REFRESH_TOKEN},
+				"refresh_token": {"refresh-1"},
+				"basic_auth":    {CLIENT + ":" + SECRET},
+			}))
+			Expect(requests[2].Get("refresh_token")).To(Equal("refresh-2"))
+		})
+
+		It("repeats flow without refresh token", func() {
+			token.expiresIn = 5
+			spec := local.NewRepositorySpec(tokenURL, CLIENT).WithInsecure()
+			repo := Must(ctx.RepositoryForSpec(spec, cpi.DirectCredentials{identity.ATTR_CLIENTSECRET: SECRET}))
+
+			Expect(Must(repo.LookupCredentials("any")).GetProperty(cpi.ATTR_TOKEN)).To(Equal("token-1"))
+			Expect(Must(repo.LookupCredentials("any")).GetProperty(cpi.ATTR_TOKEN)).To(Equal("token-2"))
+			for _, r := range token.Requests() {
+				Expect(r.Get("grant_type")).To(Equal(local.GRANT_CLIENT_CREDENTIALS))
+			}
+		})
+
+		It("shares cached token in context", func() {
+			spec := local.NewRepositorySpec(tokenURL, CLIENT, ocireg).WithInsecure()
+			creds := cpi.DirectCredentials{identity.ATTR_CLIENTSECRET: SECRET}
+			repo := Must(ctx.RepositoryForSpec(spec, creds))
+			Expect(Must(ctx.RepositoryForSpec(spec, creds))).To(BeIdenticalTo(repo))
+
+			Must(repo.LookupCredentials("any"))
+			Must(ociidentity.GetCredentials(ctx, "registry.acme.org", "acme/repo"))
+			Expect(len(token.Requests())).To(Equal(1))
+		})
+	})
+
+	Context("consumers", func() {
+		BeforeEach(func() {
+			ctx.SetCredentialsForConsumer(Must(identity.GetConsumerId(tokenURL, CLIENT)),
+				credentials.DirectCredentials{identity.ATTR_CLIENTSECRET: SECRET})
+			spec := local.NewRepositorySpec(tokenURL, CLIENT,
+				ocireg,
+				credentials.NewConsumerIdentity(helmidentity.CONSUMER_TYPE, helmidentity.ID_HOSTNAME, "charts.acme.org"),
+				credentials.NewConsumerIdentity(wgetidentity.CONSUMER_TYPE, wgetidentity.ID_HOSTNAME, "files.acme.org"),
+			).WithInsecure().WithUsername("oauth2")
+			Must(ctx.RepositoryForSpec(spec))
+		})
+
+		It("provides token for oci", func() {
+			creds := Must(ociidentity.GetCredentials(ctx, "registry.acme.org:443", "acme/repo"))
+			Expect(creds.GetProperty(cpi.ATTR_PASSWORD)).To(Equal("token-1"))
+			Expect(Must(ociidentity.GetCredentials(ctx, "ghcr.io", "acme/repo"))).To(BeNil())
+		})
+
+		It("provides token for helm", func() {
+			props := helmidentity.GetCredentials(ctx, "https://charts.acme.org/stable", "chart")
+			Expect(props).To(Equal(common.Properties{
+				cpi.ATTR_USERNAME:       "oauth2",
+				cpi.ATTR_PASSWORD:       "token-1",
+				cpi.ATTR_TOKEN:          "token-1",
+				cpi.ATTR_IDENTITY_TOKEN: "token-1",
+			}))
+		})
+
+		It("provides token for wget", func() {
+			creds := Must(cpi.CredentialsForConsumer(ctx, wgetidentity.GetConsumerId("https://files.acme.org/a/b.tgz")))
+			Expect(creds.GetProperty(cpi.ATTR_IDENTITY_TOKEN)).To(Equal("token-1"))
+			Expect(len(token.Requests())).To(Equal(1))
+		})
+
+		It("does not provide token for token endpoint", func() {
+			Expect(Must(cpi.CredentialsForConsumer(ctx, Must(identity.GetConsumerId(tokenURL, CLIENT)))).GetProperty(cpi.ATTR_TOKEN)).To(Equal(""))
+		})
+	})
+
+	Context("spec", func() {
+		It("deserializes", func() {
+			data := `
+type: OAuth2
+tokenURL: https://auth.acme.org/token
+flow: token_exchange
+subjectTokenEnv: CI_JOB_JWT
+consumers:
+- type: OCIRegistry
+  hostname: registry.acme.org
+`
+			spec := Must(ctx.RepositorySpecForConfig([]byte(data), nil)).(*local.RepositorySpec)
+			Expect(spec.Flow).To(Equal(local.FLOW_TOKEN_EXCHANGE))
+			Expect(spec.SubjectTokenEnv).To(Equal("CI_JOB_JWT"))
+			Expect(spec.Consumers).To(Equal([]cpi.ConsumerIdentity{ocireg}))
+		})
+
+		It("rejects plain http token endpoint", func() {
+			ExpectError(ctx.RepositoryForSpec(local.NewRepositorySpec(tokenURL, CLIENT))).To(MatchError(`token url "` + tokenURL + `" does not use https (option insecure required)`))
+		})
+
+		It("rejects invalid flow", func() {
+			spec := local.NewRepositorySpec(tokenURL, CLIENT).WithInsecure()
+			spec.Flow = "password"
+			ExpectError(ctx.RepositoryForSpec(spec)).To(MatchError("flow \"password\" not supported by OAuth2"))
+		})
+	})
+})
@@ -0,0 +1,262 @@
+package oauth2
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/credentials/extensions/repositories/oauth2/identity"
+	"ocm.software/ocm/api/credentials/internal"
+	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/datacontext/attrs/httptimeoutattr"
+	"ocm.software/ocm/api/datacontext/attrs/vfsattr"
+	"ocm.software/ocm/api/utils"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+type Repository struct {
+	lock     sync.Mutex
+	ctx      cpi.Context
+	fs       vfs.FileSystem
+	spec     *RepositorySpec
+	id       cpi.ConsumerIdentity
+	creds    cpi.Credentials
+	endpoint *endpoint
+	token    *Token
+}
+
+var (
+	_ cpi.Repository               = (*Repository)(nil)
+	_ cpi.ConsumerIdentityProvider = (*Repository)(nil)
+)
+
+// NewRepository creates a repository for an OAuth2 token endpoint.
+// Tokens are not requested before credentials are required.
+func NewRepository(ctx cpi.Context, spec *RepositorySpec, creds cpi.Credentials) (*Repository, error) {
+	if spec.TokenURL == "" {
+		return nil, errors.ErrRequired("token url")
+	}
+	u, err := url.Parse(spec.TokenURL)
+	if err != nil {
+		return nil, errors.ErrInvalidWrap(err, "token url", spec.TokenURL)
+	}
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if !spec.Insecure {
+			return nil, errors.Newf("token url %q does not use https (option insecure required)", spec.TokenURL)
+		}
+	default:
+		return nil, errors.ErrInvalid("token url", spec.TokenURL)
+	}
+	switch spec.Flow {
+	case FLOW_CLIENT_CREDENTIALS, FLOW_TOKEN_EXCHANGE:
+	default:
+		return nil, errors.ErrNotSupported("flow", spec.Flow, Type)
+	}
+	switch spec.AuthStyle {
+	case "", AUTH_STYLE_BASIC, AUTH_STYLE_POST:
+	default:
+		return nil, errors.ErrNotSupported("auth style", spec.AuthStyle, Type)
+	}
+	id, err := identity.GetConsumerId(spec.TokenURL, spec.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	r := &Repository{
+		ctx:   datacontext.InternalContextRef(ctx),
+		fs:    vfsattr.Get(ctx),
+		spec:  spec,
+		id:    id,
+		creds: creds,
+		endpoint: &endpoint{
+			http:      &http.Client{Timeout: httptimeoutattr.Get(ctx)},
+			tokenURL:  spec.TokenURL,
+			authStyle: spec.AuthStyle,
+		},
+	}
+	if utils.AsBool(spec.PropagateConsumerIdentity, true) && len(spec.Consumers) > 0 {
+		ctx.RegisterConsumerProvider(spec.GetKey(), &ConsumerProvider{r})
+	}
+	return r, nil
+}
+
+// ExistsCredentials reports whether a token can be provided.
+// The token is independent of the requested name.
+func (r *Repository) ExistsCredentials(name string) (bool, error) {
+	_, err := r.Token()
+	return err == nil, err
+}
+
+// LookupCredentials provides the actual token credentials.
+// The token is independent of the requested name.
+func (r *Repository) LookupCredentials(name string) (cpi.Credentials, error) {
+	return r.Credentials()
+}
+
+func (r *Repository) WriteCredentials(name string, creds cpi.Credentials) (cpi.Credentials, error) {
+	return nil, errors.ErrNotSupported("write", "credentials", Type)
+}
+
+func (r *Repository) GetConsumerId(uctx ...internal.UsageContext) internal.ConsumerIdentity {
+	return r.id
+}
+
+func (r *Repository) GetIdentityMatcher() string {
+	return identity.CONSUMER_TYPE
+}
+
+// Credentials provides the credentials for the actual access token.
+// The token is provided as token and identity token. If a username is
+// configured, it is additionally provided as username/password pair.
+func (r *Repository) Credentials() (cpi.Credentials, error) {
+	t, err := r.Token()
+	if err != nil {
+		return nil, err
+	}
+	props := common.Properties{
+		cpi.ATTR_TOKEN:          t.AccessToken,
+		cpi.ATTR_IDENTITY_TOKEN: t.AccessToken,
+	}
+	if r.spec.Username != "" {
+		props[cpi.ATTR_USERNAME] = r.spec.Username
+		props[cpi.ATTR_PASSWORD] = t.AccessToken
+	}
+	return cpi.NewCredentials(props), nil
+}
+
+// Token provides a valid access token. The token is cached until
+// it expires. An expired token is refreshed using the refresh token,
+// if provided by the token endpoint, otherwise the configured flow
+// is executed again.
+func (r *Repository) Token() (*Token, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	if r.token.Valid(now) {
+		return r.token, nil
+	}
+
+	creds, err := r.getCredentials()
+	if err != nil {
+		return nil, err
+	}
+	client := clientAuth{
+		id:     r.spec.ClientID,
+		secret: creds.GetProperty(identity.ATTR_CLIENTSECRET),
+	}
+	if client.id == "" {
+		client.id = creds.GetProperty(identity.ATTR_CLIENTID)
+	}
+
+	if r.token != nil && r.token.RefreshToken != "" {
+		t, err := r.endpoint.request(context.Background(), client, url.Values{
+			"grant_type":    {GRANT_REFRESH_TOKEN},
+			"refresh_token": {r.token.RefreshToken},
+		}, now)
+		if err == nil {
+			log.Debug("refreshed access token", "url", r.spec.TokenURL)
+			if t.RefreshToken == "" {
+				t.RefreshToken = r.token.RefreshToken
+			}
+			r.token = t
+			return t, nil
+		}
+		log.Info("token refresh failed, requesting new token", "url", r.spec.TokenURL, "error", err.Error())
+	}
+
+	params, err := r.grant(client, creds)
+	if err != nil {
+		return nil, err
+	}
+	t, err := r.endpoint.request(context.Background(), client, params, now)
+	if err != nil {
+		r.token = nil
+		return nil, err
+	}
+	log.Debug("requested access token", "url", r.spec.TokenURL, "flow", r.spec.Flow)
+	r.token = t
+	return t, nil
+}
+
+// grant provides the request parameters for the configured flow.
+func (r *Repository) grant(client clientAuth, creds cpi.Credentials) (url.Values, error) {
+	params := url.Values{}
+	switch r.spec.Flow {
+	case FLOW_CLIENT_CREDENTIALS:
+		if client.id == "" || client.secret == "" {
+			return nil, errors.Newf("client id and secret required for flow %q of %s", r.spec.Flow, r.spec.TokenURL)
+		}
+		params.Set("grant_type", GRANT_CLIENT_CREDENTIALS)
+	case FLOW_TOKEN_EXCHANGE:
+		token, err := r.subjectToken(creds)
+		if err != nil {
+			return nil, err
+		}
+		params.Set("grant_type", GRANT_TOKEN_EXCHANGE)
+		params.Set("subject_token", token)
+		params.Set("subject_token_type", r.spec.SubjectTokenType)
+		if r.spec.RequestedTokenType != "" {
+			params.Set("requested_token_type", r.spec.RequestedTokenType)
+		}
+	}
+	if len(r.spec.Scopes) > 0 {
+		params.Set("scope", strings.Join(r.spec.Scopes, " "))
+	}
+	if r.spec.Audience != "" {
+		params.Set("audience", r.spec.Audience)
+	}
+	if r.spec.Resource != "" {
+		params.Set("resource", r.spec.Resource)
+	}
+	return params, nil
+}
+
+// subjectToken provides the subject token for the token exchange.
+// It is read for every exchange, because such tokens (for example
+// the OIDC tokens of CI jobs) are typically short-lived, also.
+func (r *Repository) subjectToken(creds cpi.Credentials) (string, error) {
+	var token string
+	switch {
+	case r.spec.SubjectTokenFile != "":
+		data, err := utils.ReadFile(r.spec.SubjectTokenFile, r.fs)
+		if err != nil {
+			return "", errors.Wrapf(err, "cannot read subject token file %q", r.spec.SubjectTokenFile)
+		}
+		token = strings.TrimSpace(string(data))
+	case r.spec.SubjectTokenEnv != "":
+		token = strings.TrimSpace(os.Getenv(r.spec.SubjectTokenEnv))
+	default:
+		token = creds.GetProperty(identity.ATTR_SUBJECT_TOKEN)
+	}
+	if token == "" {
+		return "", errors.Newf("subject token required for flow %q of %s", r.spec.Flow, r.spec.TokenURL)
+	}
+	return token, nil
+}
+
+// getCredentials provides the credentials used to authenticate at
+// the token endpoint. These are the credentials given for the repository
+// or the credentials configured for the consumer id of the token endpoint.
+func (r *Repository) getCredentials() (cpi.Credentials, error) {
+	if r.creds != nil {
+		return r.creds, nil
+	}
+	creds, err := cpi.CredentialsForConsumer(r.ctx, r.id, identity.IdentityMatcher)
+	if err != nil {
+		return nil, err
+	}
+	if creds == nil {
+		return cpi.NewCredentials(nil), nil
+	}
+	return creds, nil
+}
@@ -0,0 +1,160 @@
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+)
+
+const (
+	// FLOW_CLIENT_CREDENTIALS uses the OAuth2 client credentials grant (RFC 6749).
+	FLOW_CLIENT_CREDENTIALS = "client_credentials"
+	// FLOW_TOKEN_EXCHANGE uses the OAuth2 token exchange grant (RFC 8693)
+	// to exchange a subject token, e.g. an OIDC token of a CI job,
+	// for an access token.
+	FLOW_TOKEN_EXCHANGE = "token_exchange"
+)
+
+const (
+	GRANT_CLIENT_CREDENTIALS = "client_credentials"
+	GRANT_TOKEN_EXCHANGE     = "urn:ietf:params:oauth:grant-type:token-exchange"
+	GRANT_REFRESH_TOKEN      = "refresh_token"
+)
+
+const (
+	TOKEN_TYPE_JWT          = "urn:ietf:params:oauth:token-type:jwt"
+	TOKEN_TYPE_ID_TOKEN     = "urn:ietf:params:oauth:token-type:id_token"
+	TOKEN_TYPE_ACCESS_TOKEN = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+const (
+	AUTH_STYLE_BASIC = "basic"
+	AUTH_STYLE_POST  = "post"
+)
+
+// expirySkew is the time span before the expiration of a token
+// it is already treated as expired to avoid using tokens which
+// expire while they are in use.
+const expirySkew = 10 * time.Second
+
+// Token is an access token issued by a token endpoint.
+type Token struct {
+	AccessToken     string
+	TokenType       string
+	RefreshToken    string
+	IssuedTokenType string
+	// Expiry is the expiration time of the access token.
+	// The zero value means that the token does not expire.
+	Expiry time.Time
+}
+
+// Valid checks whether the token is present and not expired.
+func (t *Token) Valid(now time.Time) bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || now.Add(expirySkew).Before(t.Expiry)
+}
+
+type tokenResponse struct {
+	AccessToken     string      `json:"access_token"`
+	TokenType       string      `json:"token_type,omitempty"`
+	RefreshToken    string      `json:"refresh_token,omitempty"`
+	IssuedTokenType string      `json:"issued_token_type,omitempty"`
+	ExpiresIn       json.Number `json:"expires_in,omitempty"`
+}
+
+type errorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// endpoint is a minimal client for an OAuth2 token endpoint.
+type endpoint struct {
+	http      *http.Client
+	tokenURL  string
+	authStyle string
+}
+
+// clientAuth describes the authentication of an OAuth2 client.
+type clientAuth struct {
+	id     string
+	secret string
+}
+
+// request performs a token request with the given grant parameters.
+// The client is authenticated according to the configured auth style.
+// Public clients (without secret) just pass their client id.
+func (c *endpoint) request(ctx context.Context, cl clientAuth, params url.Values, now time.Time) (*Token, error) {
+	if cl.secret != "" && c.authStyle != AUTH_STYLE_POST {
+		cl.id = url.QueryEscape(cl.id)
+		cl.secret = url.QueryEscape(cl.secret)
+	} else {
+		if cl.id != "" {
+			params.Set("client_id", cl.id)
+		}
+		if cl.secret != "" {
+			params.Set("client_secret", cl.secret)
+		}
+		cl = clientAuth{}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if cl.secret != "" {
+		req.SetBasicAuth(cl.id, cl.secret)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "token request to %q failed", c.tokenURL)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read token response from %q", c.tokenURL)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var e errorResponse
+		if json.Unmarshal(body, &e) == nil && e.Error != "" {
+			if e.ErrorDescription != "" {
+				return nil, errors.Newf("token request to %q failed: %s: %s", c.tokenURL, e.Error, e.ErrorDescription)
+			}
+			return nil, errors.Newf("token request to %q failed: %s", c.tokenURL, e.Error)
+		}
+		return nil, errors.Newf("token request to %q failed: %s", c.tokenURL, resp.Status)
+	}
+
+	var r tokenResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, errors.Wrapf(err, "invalid token response from %q", c.tokenURL)
+	}
+	if r.AccessToken == "" {
+		return nil, errors.Newf("token response from %q contains no access token", c.tokenURL)
+	}
+	t := &Token{
+		AccessToken:     r.AccessToken,
+		TokenType:       r.TokenType,
+		RefreshToken:    r.RefreshToken,
+		IssuedTokenType: r.IssuedTokenType,
+	}
+	if r.ExpiresIn != "" {
+		secs, err := r.ExpiresIn.Int64()
+		if err != nil {
+			return nil, errors.ErrInvalidWrap(err, "expires_in", r.ExpiresIn.String())
+		}
+		if secs > 0 {
+			t.Expiry = now.Add(time.Duration(secs) * time.Second)
+		}
+	}
+	return t, nil
+}
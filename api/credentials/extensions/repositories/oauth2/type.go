@@ -0,0 +1,146 @@
+package oauth2
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/mandelsoft/goutils/generics"
+
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/credentials/extensions/repositories/oauth2/identity"
+	"ocm.software/ocm/api/credentials/internal"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+const (
+	Type   = "OAuth2"
+	TypeV1 = Type + runtime.VersionSeparator + "v1"
+)
+
+func init() {
+	cpi.RegisterRepositoryType(cpi.NewRepositoryType[*RepositorySpec](Type))
+	cpi.RegisterRepositoryType(cpi.NewRepositoryType[*RepositorySpec](TypeV1, cpi.WithDescription(usage), cpi.WithFormatSpec(format)))
+}
+
+// RepositorySpec describes a credential repository providing
+// short-lived access tokens requested from an OAuth2 token endpoint.
+type RepositorySpec struct {
+	runtime.ObjectVersionedType `json:",inline"`
+	TokenURL                    string                 `json:"tokenURL"`
+	Insecure                    bool                   `json:"insecure,omitempty"`
+	Flow                        string                 `json:"flow,omitempty"`
+	ClientID                    string                 `json:"clientId,omitempty"`
+	AuthStyle                   string                 `json:"authStyle,omitempty"`
+	Scopes                      []string               `json:"scopes,omitempty"`
+	Audience                    string                 `json:"audience,omitempty"`
+	Resource                    string                 `json:"resource,omitempty"`
+	SubjectTokenType            string                 `json:"subjectTokenType,omitempty"`
+	SubjectTokenFile            string                 `json:"subjectTokenFile,omitempty"`
+	SubjectTokenEnv             string                 `json:"subjectTokenEnv,omitempty"`
+	RequestedTokenType          string                 `json:"requestedTokenType,omitempty"`
+	Username                    string                 `json:"username,omitempty"`
+	Consumers                   []cpi.ConsumerIdentity `json:"consumers,omitempty"`
+	PropagateConsumerIdentity   *bool                  `json:"propagateConsumerIdentity,omitempty"`
+}
+
+var _ cpi.ConsumerIdentityProvider = (*RepositorySpec)(nil)
+
+// NewRepositorySpec creates a new OAuth2 RepositorySpec using the
+// client credentials flow. The access tokens are provided for the
+// given consumer identity patterns.
+func NewRepositorySpec(tokenurl string, clientid string, consumers ...cpi.ConsumerIdentity) *RepositorySpec {
+	return &RepositorySpec{
+		ObjectVersionedType: runtime.NewVersionedTypedObject(Type),
+		TokenURL:            tokenurl,
+		ClientID:            clientid,
+		Consumers:           slices.Clone(consumers),
+	}
+}
+
+// WithInsecure permits a token endpoint not using HTTPS.
+// Client secrets and tokens are then transferred unprotected.
+func (a *RepositorySpec) WithInsecure() *RepositorySpec {
+	a.Insecure = true
+	return a
+}
+
+// WithTokenExchange switches to the token exchange flow using the given
+// subject token type. If empty, TOKEN_TYPE_JWT is used.
+func (a *RepositorySpec) WithTokenExchange(subjectTokenType string) *RepositorySpec {
+	a.Flow = FLOW_TOKEN_EXCHANGE
+	a.SubjectTokenType = subjectTokenType
+	return a
+}
+
+func (a *RepositorySpec) WithSubjectTokenFile(path string) *RepositorySpec {
+	a.SubjectTokenFile = path
+	return a
+}
+
+func (a *RepositorySpec) WithSubjectTokenEnv(name string) *RepositorySpec {
+	a.SubjectTokenEnv = name
+	return a
+}
+
+func (a *RepositorySpec) WithScopes(scopes ...string) *RepositorySpec {
+	a.Scopes = slices.Clone(scopes)
+	return a
+}
+
+func (a *RepositorySpec) WithAudience(audience string) *RepositorySpec {
+	a.Audience = audience
+	return a
+}
+
+func (a *RepositorySpec) WithUsername(name string) *RepositorySpec {
+	a.Username = name
+	return a
+}
+
+func (a *RepositorySpec) WithPropagation(b bool) *RepositorySpec {
+	a.PropagateConsumerIdentity = generics.Pointer(b)
+	return a
+}
+
+func (a *RepositorySpec) GetType() string {
+	return Type
+}
+
+func (a *RepositorySpec) Repository(ctx cpi.Context, creds cpi.Credentials) (cpi.Repository, error) {
+	r := ctx.GetAttributes().GetOrCreateAttribute(ATTR_REPOS, newRepositories)
+	repos, ok := r.(*Repositories)
+	if !ok {
+		return nil, fmt.Errorf("failed to assert type %T to Repositories", r)
+	}
+	spec := *a
+	if spec.Flow == "" {
+		spec.Flow = FLOW_CLIENT_CREDENTIALS
+	}
+	if spec.Flow == FLOW_TOKEN_EXCHANGE && spec.SubjectTokenType == "" {
+		spec.SubjectTokenType = TOKEN_TYPE_JWT
+	}
+	return repos.GetRepository(ctx, &spec, creds)
+}
+
+func (a *RepositorySpec) GetKey() cpi.ProviderIdentity {
+	spec := *a
+	spec.PropagateConsumerIdentity = nil
+	data, err := json.Marshal(&spec)
+	if err == nil {
+		return cpi.ProviderIdentity(PROVIDER + "/" + string(data))
+	}
+	return cpi.ProviderIdentity(PROVIDER + "/" + spec.TokenURL)
+}
+
+func (a *RepositorySpec) GetConsumerId(uctx ...internal.UsageContext) internal.ConsumerIdentity {
+	id, err := identity.GetConsumerId(a.TokenURL, a.ClientID)
+	if err != nil {
+		return nil
+	}
+	return id
+}
+
+func (a *RepositorySpec) GetIdentityMatcher() string {
+	return identity.CONSUMER_TYPE
+}
@@ -123,6 +123,19 @@ func RequiredCredentialsForConsumer(ctx ContextProvider, id ConsumerIdentity, ma
 	return internal.CredentialsForConsumer(ctx, id, true, matchers...)
 }
 
+type (
+	MatchTrace      = internal.MatchTrace
+	MatchEvaluation = internal.MatchEvaluation
+)
+
+// ExplainCredentialsForConsumer resolves the credentials source for a consumer
+// and provides a trace describing the evaluated consumer providers and
+// identity matcher evaluations. The trace is provided even if no
+// credentials are found.
+func ExplainCredentialsForConsumer(ctx ContextProvider, id ConsumerIdentity, matchers ...IdentityMatcher) (CredentialsSource, *MatchTrace, error) {
+	return internal.ExplainCredentialsForConsumer(ctx, id, matchers...)
+}
+
 var (
 	CompleteMatch = internal.CompleteMatch
 	NoMatch       = internal.NoMatch
@@ -95,8 +95,9 @@ func (c *_consumers) Get(id ConsumerIdentity) (CredentialsSource, bool) {
 // identities.
 func (c *_consumers) Match(ectx EvaluationContext, pattern ConsumerIdentity, cur ConsumerIdentity, m IdentityMatcher) (CredentialsSource, ConsumerIdentity) {
 	var found *_consumer
+	trace := getMatchTrace(ectx)
 	for _, s := range c.data {
-		if m(pattern, cur, s.identity) {
+		if trace.matcher(s.providerId, true, m)(pattern, cur, s.identity) {
 			found = s
 			cur = s.identity
 		}
@@ -238,7 +239,7 @@ func (u *UnwindStack) Unwrap() error {
 	return u.error
 }
 
-func (p *consumerProviderRegistry) catchedMatch(ectx EvaluationContext, sub ConsumerProvider, pattern ConsumerIdentity, cur ConsumerIdentity, m IdentityMatcher) (cs CredentialsSource, ci ConsumerIdentity) {
+func (p *consumerProviderRegistry) catchedMatch(ectx EvaluationContext, pid ProviderIdentity, sub ConsumerProvider, pattern ConsumerIdentity, cur ConsumerIdentity, m IdentityMatcher) (cs CredentialsSource, ci ConsumerIdentity) {
 	defer exception.CatchError(func(err error) {
 		log.Trace("caught unwind stack error: {{error}}", "error", err)
 		cs = nil
@@ -246,7 +247,11 @@ func (p *consumerProviderRegistry) catchedMatch(ectx EvaluationContext, sub Cons
 	}, exception.ByPrototypes(&UnwindStack{}))
 	log.Trace("pattern: {{pattern}}\ncontext: {{context}}",
 		"pattern", pattern, "context", ectx)
+	trace := getMatchTrace(ectx)
 	ectx, useprov, _ := p.checkHandleProvider(ectx, sub, pattern)
+	if trace != nil {
+		trace.addProvider(pid, !useprov)
+	}
 	if !useprov {
 		return nil, cur
 	}
@@ -259,9 +264,10 @@ func (p *consumerProviderRegistry) Match(ectx EvaluationContext, pattern Consume
 	defer p.lock.RUnlock()
 
 	credsrc, cur := p.explicit.Match(ectx, pattern, cur, m)
-	for _, sub := range p.providers {
+	trace := getMatchTrace(ectx)
+	for pid, sub := range p.providers {
 		var f CredentialsSource
-		f, cur = p.catchedMatch(ectx, sub, pattern, cur, m)
+		f, cur = p.catchedMatch(ectx, pid, sub, pattern, cur, trace.matcher(pid, false, m))
 		if f != nil {
 			credsrc = f
 		}
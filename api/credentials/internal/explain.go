@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"sync"
+
+	"github.com/mandelsoft/goutils/errors"
+)
+
+// MatchEvaluation describes the evaluation of an identity matcher
+// for a consumer identity offered by a consumer provider during
+// the resolution of credentials for a consumer.
+type MatchEvaluation struct {
+	// Provider is the identity of the consumer provider offering the
+	// consumer identity. For explicitly configured consumers, it is the
+	// (optional) provider identity used to set the credentials.
+	Provider ProviderIdentity
+	// Explicit indicates an explicitly configured consumer.
+	Explicit bool
+	// Identity is the offered consumer identity (pattern).
+	Identity ConsumerIdentity
+	// Current is the best matching consumer identity found before
+	// this evaluation.
+	Current ConsumerIdentity
+	// Matches reports whether the identity matches the requested
+	// identity, regardless of previously found matches.
+	Matches bool
+	// Selected reports whether the identity has been accepted as
+	// better match than the current one.
+	Selected bool
+}
+
+// MatchTrace records the evaluation of identity matchers during
+// the resolution of credentials for a consumer.
+// Only the evaluations on the top level are recorded, credential
+// requests issued by consumer providers for their own needs are
+// not traced.
+type MatchTrace struct {
+	lock sync.Mutex
+	// Providers lists the consumer providers in evaluation order.
+	Providers []ProviderIdentity
+	// Skipped lists consumer providers skipped to avoid recursion.
+	Skipped []ProviderIdentity
+	// Evaluations lists all matcher evaluations in evaluation order.
+	Evaluations []*MatchEvaluation
+	// Default is set if no identity matched and the credentials
+	// for the empty consumer identity are used.
+	Default bool
+}
+
+// Selected provides the finally selected evaluation, or nil
+// if no configured identity matched.
+func (t *MatchTrace) Selected() *MatchEvaluation {
+	var found *MatchEvaluation
+	for _, e := range t.Evaluations {
+		if e.Selected {
+			found = e
+		}
+	}
+	return found
+}
+
+func (t *MatchTrace) addProvider(pid ProviderIdentity, skipped bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.Providers = append(t.Providers, pid)
+	if skipped {
+		t.Skipped = append(t.Skipped, pid)
+	}
+}
+
+// matcher wraps an identity matcher to record its evaluations.
+func (t *MatchTrace) matcher(pid ProviderIdentity, explicit bool, m IdentityMatcher) IdentityMatcher {
+	if t == nil {
+		return m
+	}
+	return func(pattern, cur, id ConsumerIdentity) bool {
+		r := m(pattern, cur, id)
+		e := &MatchEvaluation{
+			Provider: pid,
+			Explicit: explicit,
+			Identity: id,
+			Current:  cur,
+			Matches:  r || len(cur) > 0 && m(pattern, nil, id),
+			Selected: r,
+		}
+		t.lock.Lock()
+		t.Evaluations = append(t.Evaluations, e)
+		t.lock.Unlock()
+		return r
+	}
+}
+
+// getMatchTrace provides the match trace for the top level evaluation.
+// Nested evaluations (with a credential recursion) are not traced.
+func getMatchTrace(ectx EvaluationContext) *MatchTrace {
+	if ectx == nil || len(GetEvaluationContextFor[CredentialRecursion](ectx)) > 0 {
+		return nil
+	}
+	return GetEvaluationContextFor[*MatchTrace](ectx)
+}
+
+// ExplainCredentialsForConsumer resolves the credentials source for a consumer
+// like GetCredentialsForConsumer and additionally provides a trace describing the
+// evaluated consumer providers and identity matcher evaluations.
+// The trace is provided even if no credentials could be found.
+func ExplainCredentialsForConsumer(ctx ContextProvider, identity ConsumerIdentity, matchers ...IdentityMatcher) (CredentialsSource, *MatchTrace, error) {
+	trace := &MatchTrace{}
+	ectx := SetEvaluationContextFor(&evaluationContext{}, trace)
+	src, err := ctx.CredentialsContext().getCredentialsForConsumer(ectx, identity, matchers...)
+	if err != nil && !errors.IsErrUnknown(err) {
+		return nil, trace, errors.Wrapf(err, "lookup credentials failed for %s", identity)
+	}
+	if src != nil && trace.Selected() == nil {
+		trace.Default = true
+	}
+	return src, trace, err
+}
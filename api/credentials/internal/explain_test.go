@@ -0,0 +1,96 @@
+package internal_test
+
+import (
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/goutils/errors"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/credentials/identity/hostpath"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+type provider struct {
+	id    cpi.ConsumerIdentity
+	creds cpi.CredentialsSource
+}
+
+func (p *provider) Unregister(id cpi.ProviderIdentity) {
+}
+
+func (p *provider) Get(id cpi.ConsumerIdentity) (cpi.CredentialsSource, bool) {
+	if id.Equals(p.id) {
+		return p.creds, true
+	}
+	return nil, false
+}
+
+func (p *provider) Match(ectx cpi.EvaluationContext, id cpi.ConsumerIdentity, cur cpi.ConsumerIdentity, m cpi.IdentityMatcher) (cpi.CredentialsSource, cpi.ConsumerIdentity) {
+	if m(id, cur, p.id) {
+		return p.creds, p.id
+	}
+	return nil, cur
+}
+
+var _ = Describe("explain credentials", func() {
+	const PROVIDER = cpi.ProviderIdentity("test/provider")
+
+	matcher := hostpath.IdentityMatcher("OCIRegistry")
+	host := credentials.NewConsumerIdentity("OCIRegistry", hostpath.ID_HOSTNAME, "ghcr.io")
+	repo := credentials.NewConsumerIdentity("OCIRegistry", hostpath.ID_HOSTNAME, "ghcr.io", hostpath.ID_PATHPREFIX, "acme")
+	req := credentials.NewConsumerIdentity("OCIRegistry", hostpath.ID_HOSTNAME, "ghcr.io", hostpath.ID_PATHPREFIX, "acme/repo")
+
+	var ctx credentials.Context
+
+	BeforeEach(func() {
+		ctx = credentials.New()
+		ctx.SetCredentialsForConsumer(host, credentials.DirectCredentials{"username": "host"})
+		ctx.RegisterConsumerProvider(PROVIDER, &provider{repo, credentials.DirectCredentials{"username": "repo"}})
+	})
+
+	It("traces selection", func() {
+		src, trace := Must2(credentials.ExplainCredentialsForConsumer(ctx, req, matcher))
+		Expect(Must(src.Credentials(ctx)).Properties()).To(Equal(common.Properties{"username": "repo"}))
+
+		Expect(trace.Providers).To(Equal([]cpi.ProviderIdentity{PROVIDER}))
+		Expect(trace.Skipped).To(BeEmpty())
+		Expect(trace.Default).To(BeFalse())
+		Expect(trace.Evaluations).To(Equal([]*credentials.MatchEvaluation{
+			{Explicit: true, Identity: host, Matches: true, Selected: true},
+			{Provider: PROVIDER, Identity: repo, Current: host, Matches: true, Selected: true},
+		}))
+		Expect(trace.Selected()).To(BeIdenticalTo(trace.Evaluations[1]))
+	})
+
+	It("traces less specific match", func() {
+		ctx.RegisterConsumerProvider(PROVIDER, &provider{host, credentials.DirectCredentials{"username": "provider"}})
+		ctx.SetCredentialsForConsumer(repo, credentials.DirectCredentials{"username": "repo"})
+		src, trace := Must2(credentials.ExplainCredentialsForConsumer(ctx, req, matcher))
+		Expect(Must(src.Credentials(ctx)).Properties()).To(Equal(common.Properties{"username": "repo"}))
+
+		e := trace.Evaluations[len(trace.Evaluations)-1]
+		Expect(e.Provider).To(Equal(PROVIDER))
+		Expect(e.Matches).To(BeTrue())
+		Expect(e.Selected).To(BeFalse())
+		Expect(trace.Selected().Identity).To(Equal(repo))
+	})
+
+	It("traces default credentials", func() {
+		ctx.SetCredentialsForConsumer(credentials.ConsumerIdentity{}, credentials.DirectCredentials{"username": "default"})
+		src, trace := Must2(credentials.ExplainCredentialsForConsumer(ctx, credentials.NewConsumerIdentity("OCIRegistry", hostpath.ID_HOSTNAME, "quay.io"), matcher))
+		Expect(Must(src.Credentials(ctx)).Properties()).To(Equal(common.Properties{"username": "default"}))
+		Expect(trace.Selected()).To(BeNil())
+		Expect(trace.Default).To(BeTrue())
+	})
+
+	It("traces missing credentials", func() {
+		src, trace, err := credentials.ExplainCredentialsForConsumer(ctx, credentials.NewConsumerIdentity("OCIRegistry", hostpath.ID_HOSTNAME, "quay.io"), matcher)
+		Expect(errors.IsErrUnknownKind(err, credentials.KIND_CONSUMER)).To(BeTrue())
+		Expect(src).To(BeNil())
+		Expect(trace.Selected()).To(BeNil())
+		Expect(len(trace.Evaluations)).To(Equal(2))
+	})
+})
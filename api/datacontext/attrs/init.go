@@ -3,6 +3,8 @@ package attrs
 import (
 	_ "ocm.software/ocm/api/datacontext/attrs/httptimeoutattr"
 	_ "ocm.software/ocm/api/datacontext/attrs/logforward"
+	_ "ocm.software/ocm/api/datacontext/attrs/progressattr"
+	_ "ocm.software/ocm/api/datacontext/attrs/retryattr"
 	_ "ocm.software/ocm/api/datacontext/attrs/rootcertsattr"
 	_ "ocm.software/ocm/api/datacontext/attrs/tmpcache"
 	_ "ocm.software/ocm/api/datacontext/attrs/vfsattr"
@@ -0,0 +1,80 @@
+package progressattr
+
+import (
+	"fmt"
+
+	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/utils/progress"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+const (
+	ATTR_KEY   = "ocm.software/ocm/api/datacontext/attrs/progress"
+	ATTR_SHORT = "progress"
+)
+
+func init() {
+	datacontext.RegisterAttributeType(ATTR_KEY, AttributeType{}, ATTR_SHORT)
+}
+
+type AttributeType struct{}
+
+func (a AttributeType) Name() string {
+	return ATTR_KEY
+}
+
+func (a AttributeType) Description() string {
+	return `
+*progress.Reporter*
+The progress reporter used by long-running operations (like transfers,
+downloads, signing or adding component versions) to report progress events.
+Handlers can be subscribed to observe the events. This attribute
+can only be set programmatically.
+`
+}
+
+func (a AttributeType) Encode(v interface{}, marshaller runtime.Marshaler) ([]byte, error) {
+	if _, ok := v.(progress.Reporter); !ok {
+		return nil, fmt.Errorf("progress reporter required")
+	}
+	return nil, nil
+}
+
+func (a AttributeType) Decode(data []byte, unmarshaller runtime.Unmarshaler) (interface{}, error) {
+	return nil, fmt.Errorf("progress reporter cannot be configured")
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Get returns the progress reporter configured for a context, or nil.
+func Get(ctx datacontext.Context) progress.Reporter {
+	if ctx == nil {
+		return nil
+	}
+	v := ctx.GetAttributes().GetAttribute(ATTR_KEY)
+	if v == nil {
+		return nil
+	}
+	return v.(progress.Reporter)
+}
+
+func Set(ctx datacontext.Context, r progress.Reporter) error {
+	return ctx.GetAttributes().SetAttribute(ATTR_KEY, r)
+}
+
+// Subscribe subscribes a handler to the progress reporter of a context.
+// If there is no reporter configured, a new one is created.
+// The returned function removes the subscription again.
+func Subscribe(ctx datacontext.Context, h progress.Handler) func() {
+	r := ctx.GetAttributes().GetOrCreateAttribute(ATTR_KEY, func(datacontext.Context) interface{} {
+		return progress.NewReporter()
+	}).(progress.Reporter)
+	return r.Subscribe(h)
+}
+
+// For provides a tracker for an element processed by an operation
+// in the given context. If there is no reporter configured, nil
+// is returned, which is a valid tracker discarding all events.
+func For(ctx datacontext.Context, operation, kind, component, element string) *progress.Tracker {
+	return progress.For(Get(ctx), operation, kind, component, element)
+}
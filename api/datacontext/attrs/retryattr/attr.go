@@ -0,0 +1,119 @@
+package retryattr
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mandelsoft/logging"
+
+	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/datacontext/attrs/progressattr"
+	"ocm.software/ocm/api/utils/progress"
+	"ocm.software/ocm/api/utils/retry"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+const (
+	ATTR_KEY   = "ocm.software/ocm/api/datacontext/attrs/retry"
+	ATTR_SHORT = "retry"
+)
+
+func init() {
+	datacontext.RegisterAttributeType(ATTR_KEY, AttributeType{}, ATTR_SHORT)
+}
+
+// AttributeType implements the datacontext.AttributeType interface for
+// the retry attribute.
+type AttributeType struct{}
+
+func (a AttributeType) Name() string {
+	return ATTR_KEY
+}
+
+func (a AttributeType) Description() string {
+	return `
+*retry policy*
+Configures the retry policy for transient failures (server errors,
+429 Too Many Requests, timeouts and connection resets) of requests
+to OCI registries and other remote endpoints used by access methods
+and repository implementations:
+
+<pre>
+    maxAttempts: 5
+    initialBackoff: 250ms
+    maxBackoff: 10s
+    factor: 2
+    jitter: 0.2
+</pre>
+
+The wait time between two attempts is increased exponentially by
+<code>factor</code>, starting with <code>initialBackoff</code>
+and limited by <code>maxBackoff</code>. It is randomly varied by the
+fraction given by <code>jitter</code> (0 disables the variation).
+A <code>Retry-After</code> header provided by the server is honored up to
+<code>maxBackoff</code>. Only requests with idempotent methods are retried.
+Setting <code>maxAttempts</code> to 1 disables retries. Fields not set are
+defaulted to the values shown above.
+`
+}
+
+func (a AttributeType) Encode(v interface{}, marshaller runtime.Marshaler) ([]byte, error) {
+	p, ok := v.(*retry.Policy)
+	if !ok {
+		return nil, fmt.Errorf("retry policy required for %s, got %T", ATTR_SHORT, v)
+	}
+	return marshaller.Marshal(p)
+}
+
+func (a AttributeType) Decode(data []byte, unmarshaller runtime.Unmarshaler) (interface{}, error) {
+	var p retry.Policy
+	if err := unmarshaller.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", ATTR_SHORT, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ATTR_SHORT, err)
+	}
+	return &p, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Get returns the effective retry policy configured for the context.
+// If not set, the default policy is returned.
+func Get(ctx datacontext.Context) *retry.Policy {
+	if ctx == nil {
+		return retry.DefaultPolicy()
+	}
+	a := ctx.GetAttributes().GetAttribute(ATTR_KEY)
+	if a == nil {
+		return retry.DefaultPolicy()
+	}
+	return a.(*retry.Policy).Complete()
+}
+
+// Set stores the retry policy attribute in the context.
+func Set(ctx datacontext.Context, p *retry.Policy) error {
+	return ctx.GetAttributes().SetAttribute(ATTR_KEY, p)
+}
+
+// Transport provides an http.RoundTripper applying the retry policy
+// of the context to the requests executed by the given base transport.
+// Every retry is logged with the given logger and reported to the
+// progress reporter of the context.
+func Transport(ctx datacontext.Context, base http.RoundTripper, logger logging.Logger) http.RoundTripper {
+	return Observe(ctx, retry.NewTransport(base, Get(ctx), logger))
+}
+
+// Observe reports the retries of a retrying transport to the progress
+// reporter configured for the given context (see progressattr).
+// The reporter is evaluated for every retry, so it may be configured
+// after the transport has been created.
+func Observe(ctx datacontext.Context, t *retry.Transport) *retry.Transport {
+	if ctx == nil {
+		return t
+	}
+	t.OnRetry = func(req *http.Request, attempt int, cause error) {
+		progressattr.For(ctx, progress.OPERATION_REQUEST, progress.KIND_URL, "", retry.RedactURL(req.URL)).Retry(attempt, cause)
+	}
+	return t
+}
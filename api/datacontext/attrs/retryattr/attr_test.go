@@ -0,0 +1,107 @@
+package retryattr_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"ocm.software/ocm/api/config"
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/datacontext/attrs/progressattr"
+	"ocm.software/ocm/api/datacontext/attrs/retryattr"
+	"ocm.software/ocm/api/utils/progress"
+	"ocm.software/ocm/api/utils/retry"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+var _ = Describe("retry attribute", func() {
+	attr := retryattr.AttributeType{}
+	enc := runtime.DefaultJSONEncoding
+
+	It("defaults to the default policy", func() {
+		Expect(retryattr.Get(datacontext.New(nil))).To(Equal(retry.DefaultPolicy()))
+		Expect(retryattr.Get(nil)).To(Equal(retry.DefaultPolicy()))
+	})
+
+	It("sets and completes policy", func() {
+		ctx := datacontext.New(nil)
+		MustBeSuccessful(retryattr.Set(ctx, &retry.Policy{MaxAttempts: 2}))
+		p := retryattr.Get(ctx)
+		Expect(p.MaxAttempts).To(Equal(2))
+		Expect(time.Duration(p.MaxBackoff)).To(Equal(retry.DEFAULT_MAX_BACKOFF))
+	})
+
+	It("decodes policy", func() {
+		v := Must(attr.Decode([]byte(`{"maxAttempts":3,"initialBackoff":"1s","maxBackoff":"1m"}`), enc))
+		Expect(v).To(Equal(&retry.Policy{
+			MaxAttempts:    3,
+			InitialBackoff: retry.Duration(time.Second),
+			MaxBackoff:     retry.Duration(time.Minute),
+		}))
+		Expect(string(Must(attr.Encode(v, enc)))).To(Equal(`{"maxAttempts":3,"initialBackoff":"1s","maxBackoff":"1m0s"}`))
+	})
+
+	It("rejects invalid policy", func() {
+		ExpectError(attr.Decode([]byte(`{"jitter":3}`), enc)).To(MatchError("invalid retry: jitter must be between 0 and 1"))
+		ExpectError(attr.Decode([]byte(`{"maxBackoff":"1Gb"}`), enc)).To(HaveOccurred())
+	})
+
+	It("reports retries to progress reporter", func() {
+		var count atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if count.Add(1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		ctx := credentials.New()
+		var events []progress.Event
+		unsubscribe := progressattr.Subscribe(ctx, progress.HandlerFunc(func(e *progress.Event) { events = append(events, *e) }))
+		defer unsubscribe()
+
+		client := &http.Client{Transport: retryattr.Observe(ctx, retry.NewTransport(nil, &retry.Policy{MaxAttempts: 2, InitialBackoff: retry.Duration(time.Millisecond)}, nil))}
+		resp := Must(client.Get(server.URL + "/path"))
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Type).To(Equal(progress.EVENT_RETRY))
+		Expect(events[0].Operation).To(Equal(progress.OPERATION_REQUEST))
+		Expect(events[0].Kind).To(Equal(progress.KIND_URL))
+		Expect(events[0].Element).To(Equal(server.URL + "/path"))
+		Expect(events[0].Attempt).To(Equal(2))
+		Expect(events[0].Error).To(Equal("503 Service Unavailable"))
+	})
+
+	Context("config", func() {
+		var ctx config.Context
+
+		BeforeEach(func() {
+			ctx = config.WithSharedAttributes(datacontext.New(nil)).New()
+		})
+
+		It("applies policy from config", func() {
+			cfg := Must(ctx.GetConfigForData([]byte(`{"type":"retry.config.ocm.software/v1alpha1","maxAttempts":7,"maxBackoff":"1m"}`), nil))
+			MustBeSuccessful(ctx.ApplyConfig(cfg, "config file"))
+
+			p := retryattr.Get(credentials.WithConfigs(ctx).New())
+			Expect(p.MaxAttempts).To(Equal(7))
+			Expect(time.Duration(p.MaxBackoff)).To(Equal(time.Minute))
+			Expect(time.Duration(p.InitialBackoff)).To(Equal(retry.DEFAULT_INITIAL_BACKOFF))
+		})
+
+		It("rejects invalid config", func() {
+			Expect(ctx.ApplyConfig(retryattr.NewConfig(&retry.Policy{Factor: 0.5}), "test")).To(HaveOccurred())
+		})
+	})
+})
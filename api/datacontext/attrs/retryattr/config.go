@@ -0,0 +1,76 @@
+package retryattr
+
+import (
+	"github.com/mandelsoft/goutils/errors"
+
+	cfgcpi "ocm.software/ocm/api/config/cpi"
+	"ocm.software/ocm/api/utils/retry"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+const (
+	ConfigType         = "retry" + cfgcpi.OCM_CONFIG_TYPE_SUFFIX
+	ConfigTypeV1Alpha1 = ConfigType + runtime.VersionSeparator + "v1alpha1"
+)
+
+func init() {
+	cfgcpi.RegisterConfigType(cfgcpi.NewConfigType[*Config](ConfigType, configUsage))
+	cfgcpi.RegisterConfigType(cfgcpi.NewConfigType[*Config](ConfigTypeV1Alpha1, configUsage))
+}
+
+// Config describes the configuration of the retry policy.
+type Config struct {
+	runtime.ObjectVersionedType `json:",inline"`
+	retry.Policy                `json:",inline"`
+}
+
+// NewConfig creates a new retry config for the given policy.
+func NewConfig(p *retry.Policy) *Config {
+	c := &Config{
+		ObjectVersionedType: runtime.NewVersionedTypedObject(ConfigType),
+	}
+	if p != nil {
+		c.Policy = *p
+	}
+	return c
+}
+
+func (a *Config) GetType() string {
+	return ConfigType
+}
+
+func (a *Config) ApplyTo(ctx cfgcpi.Context, target interface{}) error {
+	t, ok := target.(cfgcpi.Context)
+	if !ok {
+		return cfgcpi.ErrNoContext(ConfigType)
+	}
+	if err := a.Policy.Validate(); err != nil {
+		return errors.Wrapf(err, "invalid retry policy")
+	}
+	p := a.Policy
+	return errors.Wrapf(t.GetAttributes().SetAttribute(ATTR_KEY, &p), "applying config failed")
+}
+
+const configUsage = `
+The config type <code>` + ConfigType + `</code> can be used to configure
+the retry policy for transient failures of requests to OCI registries
+and other remote endpoints:
+
+<pre>
+    type: ` + ConfigType + `
+    maxAttempts: 5
+    initialBackoff: 250ms
+    maxBackoff: 10s
+    factor: 2
+    jitter: 0.2
+</pre>
+
+Server errors, 429 Too Many Requests, timeouts and connection resets are
+retried up to <code>maxAttempts</code> times (including the initial attempt).
+The wait time is increased exponentially by <code>factor</code>, starting with
+<code>initialBackoff</code> and limited by <code>maxBackoff</code>, and varied
+randomly by the fraction given by <code>jitter</code> (0 disables the variation).
+A <code>Retry-After</code> header sent by the server is honored up to
+<code>maxBackoff</code>. Only requests with idempotent methods are retried.
+Fields not set are defaulted to the values shown above.
+`
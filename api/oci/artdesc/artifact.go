@@ -25,6 +25,7 @@ const (
 	MediaTypeDockerSchema2ManifestList = images.MediaTypeDockerSchema2ManifestList
 
 	MediaTypeImageConfig = ociv1.MediaTypeImageConfig
+	MediaTypeEmptyJSON   = ociv1.MediaTypeEmptyJSON
 )
 
 var legacy = false
@@ -220,6 +221,44 @@ func (d *Artifact) modifyAnnotation(mod func(annos *map[string]string)) error {
 	return nil
 }
 
+// GetSubject returns the descriptor of the artifact referred to
+// by the subject field (OCI 1.1), or nil.
+func (d *Artifact) GetSubject() *Descriptor {
+	switch {
+	case d.manifest != nil:
+		return d.manifest.Subject
+	case d.index != nil:
+		return d.index.Subject
+	default:
+		return nil
+	}
+}
+
+// SetSubject sets the artifact referred to by the subject field.
+func (d *Artifact) SetSubject(s *Descriptor) error {
+	switch {
+	case d.manifest != nil:
+		d.manifest.SetSubject(s)
+	case d.index != nil:
+		d.index.SetSubject(s)
+	default:
+		return errors.Newf("void artifact access")
+	}
+	return nil
+}
+
+// GetArtifactType returns the artifact type as used for referrers.
+func (d *Artifact) GetArtifactType() string {
+	switch {
+	case d.manifest != nil:
+		return d.manifest.GetArtifactType()
+	case d.index != nil:
+		return d.index.ArtifactType
+	default:
+		return ""
+	}
+}
+
 func (d *Artifact) ToBlobAccess() (blobaccess.BlobAccess, error) {
 	if d.IsManifest() {
 		return d.manifest.Blob()
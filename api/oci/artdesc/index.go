@@ -101,6 +101,16 @@ func (i *Index) AddManifest(d *Descriptor) {
 	i.Manifests = append(i.Manifests, *d)
 }
 
+// SetSubject sets the artifact referred to by this index.
+func (i *Index) SetSubject(s *Descriptor) {
+	if s == nil {
+		i.Subject = nil
+		return
+	}
+	d := *s
+	i.Subject = &d
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 func DecodeIndex(data []byte) (*Index, error) {
@@ -101,6 +101,26 @@ func (m *Manifest) DeleteAnnotation(name string) {
 	}
 }
 
+// SetSubject sets the artifact referred to by this manifest.
+func (m *Manifest) SetSubject(s *Descriptor) {
+	if s == nil {
+		m.Subject = nil
+		return
+	}
+	d := *s
+	m.Subject = &d
+}
+
+// GetArtifactType returns the artifact type of the manifest.
+// According to the OCI 1.1 spec, this is the config media type,
+// if no explicit artifact type is given.
+func (m *Manifest) GetArtifactType() string {
+	if m.ArtifactType != "" {
+		return m.ArtifactType
+	}
+	return m.Config.MediaType
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 func DecodeManifest(data []byte) (*Manifest, error) {
@@ -19,6 +19,52 @@ func DefaultBlobDescriptor(blob blobaccess.BlobAccess) *Descriptor {
 	}
 }
 
+// ReferrerDescriptor provides the descriptor used to list an artifact
+// as referrer of its subject.
+func ReferrerDescriptor(blob blobaccess.BlobAccess, art *Artifact) *Descriptor {
+	d := DefaultBlobDescriptor(blob)
+	d.ArtifactType = art.GetArtifactType()
+	switch {
+	case art.manifest != nil:
+		d.Annotations = art.manifest.Annotations
+	case art.index != nil:
+		d.Annotations = art.index.Annotations
+	}
+	return d
+}
+
+// ReferrersTag provides the tag used by the referrers tag schema
+// to store the referrers index for the artifact with the given digest
+// in registries not supporting the referrers API. Like described by the
+// distribution spec, the algorithm is truncated to 32 and the encoded
+// digest to 64 characters.
+func ReferrersTag(d digest.Digest) string {
+	alg := d.Algorithm().String()
+	if len(alg) > 32 {
+		alg = alg[:32]
+	}
+	enc := d.Encoded()
+	if len(enc) > 64 {
+		enc = enc[:64]
+	}
+	return alg + "-" + enc
+}
+
+// FilterReferrers returns the referrer descriptors with the given
+// artifact type. If no type is given, the list is returned as it is.
+func FilterReferrers(list []Descriptor, artifactType string) []Descriptor {
+	if artifactType == "" {
+		return list
+	}
+	var result []Descriptor
+	for _, d := range list {
+		if d.ArtifactType == artifactType {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
 func IsDigest(version string) (bool, digest.Digest) {
 	if strings.HasPrefix(version, "@") {
 		return true, digest.Digest(version[1:])
@@ -1,9 +1,13 @@
 package artdesc_test
 
 import (
+	"strings"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/opencontainers/go-digest"
+
 	"ocm.software/ocm/api/oci/artdesc"
 )
 
@@ -17,4 +21,9 @@ var _ = Describe("utils", func() {
 		Expect(artdesc.ToDescriptorMediaType(artdesc.ToContentMediaType(artdesc.MediaTypeImageManifest) + "+tar+gzip")).To(Equal(artdesc.MediaTypeImageManifest))
 		Expect(artdesc.ToDescriptorMediaType(artdesc.ToContentMediaType(artdesc.MediaTypeImageIndex) + "+tar+gzip")).To(Equal(artdesc.MediaTypeImageIndex))
 	})
+
+	It("provides referrers tag", func() {
+		Expect(artdesc.ReferrersTag("sha256:0c4abdb72cf59cb4b77f4aacb4775f9f546ebc3face189b2224a966c8826ca9f")).To(Equal("sha256-0c4abdb72cf59cb4b77f4aacb4775f9f546ebc3face189b2224a966c8826ca9f"))
+		Expect(artdesc.ReferrersTag(digest.Digest("sha512:" + strings.Repeat("a", 128)))).To(Equal("sha512-" + strings.Repeat("a", 64)))
+	})
 })
@@ -36,6 +36,8 @@ type (
 	BlobSink                         = internal.BlobSink
 	NamespaceLister                  = internal.NamespaceLister
 	NamespaceAccess                  = internal.NamespaceAccess
+	ArtifactDeleter                  = internal.ArtifactDeleter
+	ReferrersLister                  = internal.ReferrersLister
 	ManifestAccess                   = internal.ManifestAccess
 	IndexAccess                      = internal.IndexAccess
 	BlobAccess                       = internal.BlobAccess
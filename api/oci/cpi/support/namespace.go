@@ -4,6 +4,7 @@ import (
 	"github.com/mandelsoft/goutils/errors"
 	"github.com/opencontainers/go-digest"
 
+	"ocm.software/ocm/api/oci/artdesc"
 	"ocm.software/ocm/api/oci/cpi"
 	"ocm.software/ocm/api/utils/accessio"
 	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
@@ -108,6 +109,23 @@ func (i *namespaceAccessImpl) AddArtifact(artifact cpi.Artifact, tags ...string)
 	return i.NamespaceContainer.AddArtifact(artifact, tags...)
 }
 
+func (i *namespaceAccessImpl) DeleteArtifact(vers string) error {
+	if i.IsReadOnly() {
+		return accessio.ErrReadOnly
+	}
+	if d, ok := i.NamespaceContainer.(cpi.ArtifactDeleter); ok {
+		return d.DeleteArtifact(vers)
+	}
+	return errors.ErrNotSupported("artifact deletion", i.GetNamespace())
+}
+
+func (i *namespaceAccessImpl) ListReferrers(digest digest.Digest, artifactType string) ([]artdesc.Descriptor, error) {
+	if r, ok := i.NamespaceContainer.(cpi.ReferrersLister); ok {
+		return r.ListReferrers(digest, artifactType)
+	}
+	return cpi.ListReferrersByTagSchema(i, digest, artifactType)
+}
+
 func (i *namespaceAccessImpl) NewArtifact(arts ...cpi.Artifact) (cpi.ArtifactAccess, error) {
 	return i.NamespaceContainer.NewArtifact(i, arts...)
 }
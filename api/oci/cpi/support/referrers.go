@@ -0,0 +1,29 @@
+package support
+
+import (
+	"github.com/opencontainers/go-digest"
+
+	"ocm.software/ocm/api/oci/artdesc"
+	"ocm.software/ocm/api/oci/cpi"
+	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
+)
+
+// ReadReferrer reads the artifact with the given digest from a blob source
+// and provides its descriptor as used for the referrers list, together with
+// the descriptor of its subject (nil, if there is no subject).
+func ReadReferrer(src cpi.BlobSource, d digest.Digest) (*artdesc.Descriptor, *artdesc.Descriptor, error) {
+	_, acc, err := src.GetBlobData(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer acc.Close()
+	data, err := acc.Get()
+	if err != nil {
+		return nil, nil, err
+	}
+	art, err := artdesc.Decode(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return artdesc.ReferrerDescriptor(blobaccess.ForData(art.MimeType(), data), art), art.GetSubject(), nil
+}
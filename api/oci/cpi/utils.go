@@ -3,7 +3,12 @@ package cpi
 import (
 	"strings"
 
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/opencontainers/go-digest"
+
+	"ocm.software/ocm/api/oci/artdesc"
 	"ocm.software/ocm/api/oci/grammar"
+	"ocm.software/ocm/api/oci/internal"
 )
 
 type StringList []string
@@ -61,3 +66,24 @@ func FilterChildren(closure bool, prefix string, list []string) []string {
 	}
 	return result
 }
+
+// ListReferrersByTagSchema lists the referrers of an artifact using the
+// referrers tag schema. It is used as fallback for namespaces not
+// supporting the referrers API. The referrers are taken from the image
+// index tagged with artdesc.ReferrersTag.
+func ListReferrersByTagSchema(ns internal.NamespaceAccessImpl, d digest.Digest, artifactType string) ([]artdesc.Descriptor, error) {
+	tag := artdesc.ReferrersTag(d)
+	ok, err := ns.HasArtifact(tag)
+	if err != nil || !ok {
+		return nil, err
+	}
+	art, err := ns.GetArtifact(tag)
+	if err != nil {
+		return nil, err
+	}
+	defer art.Close()
+	if !art.IsIndex() {
+		return nil, errors.Newf("referrers tag %s is no index", tag)
+	}
+	return artdesc.FilterReferrers(art.IndexAccess().GetDescriptor().Manifests, artifactType), nil
+}
@@ -238,6 +238,27 @@ func (n *namespaceAccessView) ListTags() (list []string, err error) {
 	return list, err
 }
 
+func (n *namespaceAccessView) DeleteArtifact(vers string) error {
+	return n.Execute(func() error {
+		if d, ok := n.impl.(internal.ArtifactDeleter); ok {
+			return d.DeleteArtifact(vers)
+		}
+		return errors.ErrNotSupported("artifact deletion", n.impl.GetNamespace())
+	})
+}
+
+func (n *namespaceAccessView) ListReferrers(digest digest.Digest, artifactType string) (list []artdesc.Descriptor, err error) {
+	err = n.Execute(func() error {
+		if r, ok := n.impl.(internal.ReferrersLister); ok {
+			list, err = r.ListReferrers(digest, artifactType)
+		} else {
+			list, err = ListReferrersByTagSchema(n.impl, digest, artifactType)
+		}
+		return err
+	})
+	return list, err
+}
+
 func (n *namespaceAccessView) NewArtifact(artifact ...Artifact) (acc internal.ArtifactAccess, err error) {
 	err = n.Execute(func() error {
 		acc, err = n.impl.NewArtifact(artifact...)
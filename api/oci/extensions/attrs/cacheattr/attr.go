@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/mandelsoft/goutils/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	"ocm.software/ocm/api/datacontext"
 	"ocm.software/ocm/api/utils"
@@ -29,11 +30,46 @@ func (a AttributeType) Name() string {
 
 func (a AttributeType) Description() string {
 	return `
-*string*
-Filesystem folder to use for caching OCI blobs
+*string* or *object*
+Filesystem folder to use for caching OCI blobs and the content of
+access methods. The cache is content-addressed and may be shared among
+multiple contexts and processes.
+
+Instead of a plain folder name, an object with the following fields
+can be used to configure the cache:
+
+- **<code>path</code>** *string*: the cache folder
+- **<code>maxSize</code>** *string*: the maximum size of the cache (for example <code>10Gi</code>)
+- **<code>eviction</code>** *string*: the eviction policy used if the maximum
+  size is exceeded (<code>lru</code> (default) or <code>lfu</code>)
+- **<code>verify</code>** *bool*: verify the digest of cached blobs on read
 `
 }
 
+// Config is the structured attribute value.
+type Config struct {
+	Path     string `json:"path"`
+	MaxSize  string `json:"maxSize,omitempty"`
+	Eviction string `json:"eviction,omitempty"`
+	Verify   bool   `json:"verify,omitempty"`
+}
+
+// Options provides the cache options described by the config.
+func (c *Config) Options() (*accessio.CacheOptions, error) {
+	opts := &accessio.CacheOptions{
+		Eviction: accessio.EvictionPolicy(c.Eviction),
+		Verify:   c.Verify,
+	}
+	if c.MaxSize != "" {
+		q, err := resource.ParseQuantity(c.MaxSize)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cache size %q", c.MaxSize)
+		}
+		opts.MaxSize = q.Value()
+	}
+	return opts, opts.Validate()
+}
+
 func (a AttributeType) Encode(v interface{}, marshaller runtime.Marshaler) ([]byte, error) {
 	if _, ok := v.(accessio.BlobCache); !ok {
 		return nil, fmt.Errorf("accessio.BlobCache required")
@@ -42,22 +78,32 @@ func (a AttributeType) Encode(v interface{}, marshaller runtime.Marshaler) ([]by
 }
 
 func (a AttributeType) Decode(data []byte, unmarshaller runtime.Unmarshaler) (interface{}, error) {
-	var value string
-	err := unmarshaller.Unmarshal(data, &value)
-	if value != "" {
-		value, err = utils.ResolvePath(value)
-		if err != nil {
-			return nil, err
-		}
-		// TODO: This should use the virtual filesystem.
-		err = os.MkdirAll(value, 0o700)
-		if err == nil {
-			return accessio.NewStaticBlobCache(value)
-		}
-	} else if err == nil {
-		err = errors.Newf("file path missing")
+	var cfg Config
+	err := unmarshaller.Unmarshal(data, &cfg.Path)
+	if err != nil {
+		err = unmarshaller.Unmarshal(data, &cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	value := cfg.Path
+	if value == "" {
+		return nil, errors.Newf("file path missing")
+	}
+	opts, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+	value, err = utils.ResolvePath(value)
+	if err != nil {
+		return nil, err
+	}
+	// TODO: This should use the virtual filesystem.
+	err = os.MkdirAll(value, 0o700)
+	if err != nil {
+		return nil, err
 	}
-	return value, err
+	return accessio.NewManagedBlobCache(value, opts)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -53,4 +53,16 @@ var _ = Describe("attribute", func() {
 		Expect(err).To(Succeed())
 		Expect(reflect.TypeOf(cache).String()).To(Equal("*accessio.blobCache"))
 	})
+	It("parses config", func() {
+		dir := os.TempDir()
+		cache, err := cacheattr.AttributeType{}.Decode([]byte(`{"path": "`+dir+`", "maxSize": "1Ki", "eviction": "lfu", "verify": true}`), runtime.DefaultYAMLEncoding)
+		Expect(err).To(Succeed())
+		Expect(reflect.TypeOf(cache).String()).To(Equal("*accessio.blobCache"))
+	})
+
+	It("rejects invalid config", func() {
+		dir := os.TempDir()
+		_, err := cacheattr.AttributeType{}.Decode([]byte(`{"path": "`+dir+`", "eviction": "random"}`), runtime.DefaultYAMLEncoding)
+		Expect(err).To(MatchError(`eviction policy "random" is invalid`))
+	})
 })
@@ -62,6 +62,8 @@ func RetrieveDigest(idx *artdesc.Index, ref string) digest.Digest {
 
 ////////////////////////////////////////////////////////////////////////////////
 
+var _ cpi.ReferrersLister = (*ArtifactSet)(nil)
+
 type ArtifactSet struct {
 	cpi.NamespaceAccess
 	container *namespaceContainer
@@ -99,6 +101,10 @@ func (a *ArtifactSet) HasAnnotation(name string) bool {
 	return a.container.HasAnnotation(name)
 }
 
+func (a *ArtifactSet) ListReferrers(digest digest.Digest, artifactType string) ([]artdesc.Descriptor, error) {
+	return a.container.ListReferrers(digest, artifactType)
+}
+
 func (a *ArtifactSet) SetMainArtifact(version string) {
 	if version != "" {
 		a.Annotate(MAINARTIFACT_ANNOTATION, version)
@@ -437,17 +443,45 @@ func (a *namespaceContainer) AddPlatformArtifact(artifact cpi.Artifact, platform
 		return nil, err
 	}
 
-	idx.Manifests = append(idx.Manifests, cpi.Descriptor{
+	desc := cpi.Descriptor{
 		MediaType:   blob.MimeType(),
 		Digest:      blob.Digest(),
 		Size:        blob.Size(),
 		URLs:        nil,
 		Annotations: nil,
 		Platform:    platform,
-	})
+	}
+	if artifact.Artifact().GetSubject() != nil {
+		desc.ArtifactType = artifact.Artifact().GetArtifactType()
+	}
+	idx.Manifests = append(idx.Manifests, desc)
 	return blob, nil
 }
 
+// ListReferrers lists the artifacts of the set referring to the
+// given artifact by their subject field.
+func (a *namespaceContainer) ListReferrers(d digest.Digest, artifactType string) ([]artdesc.Descriptor, error) {
+	if a.IsClosed() {
+		return nil, accessio.ErrClosed
+	}
+	result := []artdesc.Descriptor{}
+	done := map[digest.Digest]bool{}
+	for _, e := range a.GetIndex().Manifests {
+		if done[e.Digest] {
+			continue
+		}
+		done[e.Digest] = true
+		desc, subject, err := support.ReadReferrer(a.base, e.Digest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "artifact %s", e.Digest)
+		}
+		if subject != nil && subject.Digest == d {
+			result = append(result, *desc)
+		}
+	}
+	return artdesc.FilterReferrers(result, artifactType), nil
+}
+
 func (a *namespaceContainer) NewArtifact(i support.NamespaceAccessImpl, artifact ...cpi.Artifact) (cpi.ArtifactAccess, error) {
 	if a.IsClosed() {
 		return nil, accessio.ErrClosed
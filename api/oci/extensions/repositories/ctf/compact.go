@@ -0,0 +1,154 @@
+package ctf
+
+import (
+	"io"
+	"sort"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	"github.com/opencontainers/go-digest"
+
+	"ocm.software/ocm/api/oci/artdesc"
+	"ocm.software/ocm/api/oci/cpi"
+	"ocm.software/ocm/api/utils/accessio"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+// CompactionResult describes the blobs of a CTF not referenced
+// by any artifact found in the index.
+type CompactionResult struct {
+	// Blobs lists the file names of the unreferenced blobs.
+	Blobs []string
+	// Size is the accumulated size of the unreferenced blobs.
+	Size int64
+}
+
+// UnreferencedBlobs determines the blobs not referenced anymore by
+// any artifact listed in the archive index.
+func (r *Repository) UnreferencedBlobs() (*CompactionResult, error) {
+	if r.IsClosed() {
+		return nil, cpi.ErrClosed
+	}
+	return r.impl.UnreferencedBlobs()
+}
+
+// Compact removes all blobs not referenced anymore by any artifact
+// listed in the archive index. For archive formats (tar or tgz) the
+// archive is rewritten when the repository is closed.
+func (r *Repository) Compact() (*CompactionResult, error) {
+	if r.IsClosed() {
+		return nil, cpi.ErrClosed
+	}
+	return r.impl.Compact()
+}
+
+func (r *RepositoryImpl) UnreferencedBlobs() (*CompactionResult, error) {
+	r.base.Lock()
+	defer r.base.Unlock()
+	return r.unreferencedBlobs()
+}
+
+func (r *RepositoryImpl) Compact() (*CompactionResult, error) {
+	if r.IsReadOnly() {
+		return nil, accessio.ErrReadOnly
+	}
+	r.base.Lock()
+	defer r.base.Unlock()
+
+	result, err := r.unreferencedBlobs()
+	if err != nil {
+		return nil, err
+	}
+	fs := r.base.Access().GetFileSystem()
+	for _, n := range result.Blobs {
+		err = fs.Remove(r.base.BlobPath(n))
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot remove blob %s", n)
+		}
+	}
+	return result, nil
+}
+
+func (r *RepositoryImpl) unreferencedBlobs() (*CompactionResult, error) {
+	used, err := r.referencedBlobs()
+	if err != nil {
+		return nil, err
+	}
+
+	fs := r.base.Access().GetFileSystem()
+	entries, err := vfs.ReadDir(fs, BlobsDirectoryName)
+	if err != nil {
+		if vfs.IsErrNotExist(err) {
+			return &CompactionResult{}, nil
+		}
+		return nil, err
+	}
+
+	result := &CompactionResult{}
+	for _, e := range entries {
+		if e.IsDir() || used[e.Name()] {
+			continue
+		}
+		result.Blobs = append(result.Blobs, e.Name())
+		result.Size += e.Size()
+	}
+	sort.Strings(result.Blobs)
+	return result, nil
+}
+
+// referencedBlobs determines the file names of all blobs reachable
+// from the artifacts found in the index.
+func (r *RepositoryImpl) referencedBlobs() (map[string]bool, error) {
+	used := map[string]bool{}
+	queue := r.getIndex().GetDigests()
+
+	for len(queue) > 0 {
+		d := queue[0]
+		queue = queue[1:]
+		name := common.DigestToFileName(d)
+		if used[name] {
+			continue
+		}
+		used[name] = true
+
+		art, err := r.readArtifact(d)
+		if err != nil {
+			return nil, errors.Wrapf(err, "artifact %s", d)
+		}
+		if m, err := art.Manifest(); err == nil {
+			used[common.DigestToFileName(m.Config.Digest)] = true
+			for _, l := range m.Layers {
+				used[common.DigestToFileName(l.Digest)] = true
+			}
+			if m.Subject != nil {
+				queue = append(queue, m.Subject.Digest)
+			}
+		}
+		if i, err := art.Index(); err == nil {
+			for _, m := range i.Manifests {
+				queue = append(queue, m.Digest)
+			}
+			if i.Subject != nil {
+				queue = append(queue, i.Subject.Digest)
+			}
+		}
+	}
+	return used, nil
+}
+
+func (r *RepositoryImpl) readArtifact(d digest.Digest) (*artdesc.Artifact, error) {
+	_, acc, err := r.base.GetBlobData(d)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := acc.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return artdesc.Decode(data)
+}
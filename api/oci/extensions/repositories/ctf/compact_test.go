@@ -0,0 +1,138 @@
+package ctf_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/oci/extensions/repositories/ctf/testhelper"
+
+	"github.com/mandelsoft/vfs/pkg/osfs"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/oci"
+	"ocm.software/ocm/api/oci/extensions/repositories/ctf"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+	"ocm.software/ocm/api/utils/blobaccess"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+var _ = Describe("ctf compaction", func() {
+	var tempfs vfs.FileSystem
+
+	garbage := blobaccess.ForString("", "garbage")
+	GARBAGE := common.DigestToFileName(garbage.Digest())
+
+	BeforeEach(func() {
+		tempfs = Must(osfs.NewTempFileSystem())
+	})
+
+	AfterEach(func() {
+		vfs.Cleanup(tempfs)
+	})
+
+	fill := func(path string, fmt accessio.Option) {
+		r := Must(ctf.Open(oci.DefaultContext(), accessobj.ACC_CREATE, path, 0o700, fmt, accessio.PathFileSystem(tempfs)))
+		n := Must(r.LookupNamespace("mandelsoft/test"))
+		DefaultManifestFill(n)
+		MustBeSuccessful(n.AddBlob(garbage))
+		MustBeSuccessful(n.Close())
+		MustBeSuccessful(r.Close())
+	}
+
+	blobs := func(path string) []string {
+		var result []string
+		for _, fi := range Must(vfs.ReadDir(tempfs, path+"/"+ctf.BlobsDirectoryName)) {
+			result = append(result, fi.Name())
+		}
+		return result
+	}
+
+	It("determines unreferenced blobs", func() {
+		fill("test", accessobj.FormatDirectory)
+
+		r := Must(ctf.Open(oci.DefaultContext(), accessobj.ACC_READONLY, "test", 0, accessio.PathFileSystem(tempfs)))
+		defer Close(r, "repo")
+
+		res := Must(r.UnreferencedBlobs())
+		Expect(res.Blobs).To(ConsistOf(GARBAGE))
+		Expect(res.Size).To(Equal(int64(len("garbage"))))
+
+		ExpectError(r.Compact()).To(MatchError(accessio.ErrReadOnly))
+	})
+
+	It("compacts directory", func() {
+		fill("test", accessobj.FormatDirectory)
+		Expect(blobs("test")).To(ContainElement(GARBAGE))
+
+		r := Must(ctf.Open(oci.DefaultContext(), accessobj.ACC_WRITABLE, "test", 0, accessio.PathFileSystem(tempfs)))
+		res := Must(r.Compact())
+		Expect(res.Blobs).To(ConsistOf(GARBAGE))
+		MustBeSuccessful(r.Close())
+
+		Expect(blobs("test")).To(ConsistOf(
+			"sha256."+DIGEST_MANIFEST,
+			"sha256."+DIGEST_CONFIG,
+			"sha256."+DIGEST_LAYER))
+
+		r = Must(ctf.Open(oci.DefaultContext(), accessobj.ACC_READONLY, "test", 0, accessio.PathFileSystem(tempfs)))
+		defer Close(r, "repo")
+		art := Must(r.LookupArtifact("mandelsoft/test", TAG))
+		defer Close(art, "artifact")
+		CheckArtifact(art)
+	})
+
+	It("removes blobs of deleted artifacts", func() {
+		fill("test", accessobj.FormatDirectory)
+
+		r := Must(ctf.Open(oci.DefaultContext(), accessobj.ACC_WRITABLE, "test", 0, accessio.PathFileSystem(tempfs)))
+		n := Must(r.LookupNamespace("mandelsoft/test"))
+		MustBeSuccessful(oci.DeleteArtifact(n, TAG))
+		MustBeSuccessful(n.Close())
+		res := Must(r.Compact())
+		Expect(res.Blobs).To(ConsistOf(
+			GARBAGE,
+			"sha256."+DIGEST_MANIFEST,
+			"sha256."+DIGEST_CONFIG,
+			"sha256."+DIGEST_LAYER))
+		MustBeSuccessful(r.Close())
+
+		Expect(blobs("test")).To(BeEmpty())
+	})
+
+	It("rewrites tgz archive", func() {
+		fill("test.tgz", accessobj.FormatTGZ)
+
+		r := Must(ctf.Open(oci.DefaultContext(), accessobj.ACC_WRITABLE, "test.tgz", 0, accessio.PathFileSystem(tempfs)))
+		res := Must(r.Compact())
+		Expect(res.Blobs).To(ConsistOf(GARBAGE))
+		MustBeSuccessful(r.Close())
+
+		file := Must(tempfs.Open("test.tgz"))
+		defer Close(file, "file")
+		zip := Must(gzip.NewReader(file))
+		defer Close(zip, "zip")
+		tr := tar.NewReader(zip)
+
+		files := []string{}
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			MustBeSuccessful(err)
+			if header.Typeflag == tar.TypeReg {
+				files = append(files, header.Name)
+			}
+		}
+		Expect(files).To(ConsistOf(
+			ctf.ArtifactIndexFileName,
+			"blobs/sha256."+DIGEST_MANIFEST,
+			"blobs/sha256."+DIGEST_CONFIG,
+			"blobs/sha256."+DIGEST_LAYER))
+	})
+})
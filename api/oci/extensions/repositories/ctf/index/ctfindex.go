@@ -15,10 +15,12 @@ type ArtifactIndex struct {
 }
 
 type ArtifactMeta struct {
-	Repository string        `json:"repository"`
-	Tag        string        `json:"tag,omitempty"`
-	Digest     digest.Digest `json:"digest,omitempty"`
-	MediaType  string        `json:"mediaType,omitempty"`
+	Repository   string        `json:"repository"`
+	Tag          string        `json:"tag,omitempty"`
+	Digest       digest.Digest `json:"digest,omitempty"`
+	MediaType    string        `json:"mediaType,omitempty"`
+	ArtifactType string        `json:"artifactType,omitempty"`
+	Subject      digest.Digest `json:"subject,omitempty"`
 }
 
 func Decode(data []byte) (*ArtifactIndex, error) {
@@ -128,6 +128,41 @@ func (r *RepositoryIndex) addArtifactInfo(m ArtifactMeta) {
 	}
 }
 
+// RemoveArtifact removes the artifact described by the given reference
+// (tag or digest) together with all its tags from a repository.
+// It returns false, if there is no such artifact.
+func (r *RepositoryIndex) RemoveArtifact(repo, reference string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	m := r.getArtifactInfo(repo, reference)
+	if m == nil {
+		return false
+	}
+	versions := r.byRepository[repo]
+	for k, e := range versions {
+		if e.Digest == m.Digest {
+			delete(versions, k)
+		}
+	}
+	if len(versions) == 0 {
+		delete(r.byRepository, repo)
+	}
+
+	var list []*ArtifactMeta
+	for _, e := range r.byDigest[m.Digest] {
+		if e.Repository != repo {
+			list = append(list, e)
+		}
+	}
+	if len(list) == 0 {
+		delete(r.byDigest, m.Digest)
+	} else {
+		r.byDigest[m.Digest] = list
+	}
+	return true
+}
+
 func (r *RepositoryIndex) HasArtifact(repo, tag string) bool {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
@@ -182,6 +217,37 @@ func (r *RepositoryIndex) GetArtifacts(repo string) []string {
 	return result
 }
 
+// GetDigests returns the digests of all artifacts found in the index.
+func (r *RepositoryIndex) GetDigests() []digest.Digest {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	result := make([]digest.Digest, 0, len(r.byDigest))
+	for d := range r.byDigest {
+		result = append(result, d)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// GetReferrers returns the digests of the artifacts of a repository
+// referring to the artifact with the given digest by their subject field.
+func (r *RepositoryIndex) GetReferrers(repo string, subject digest.Digest) []digest.Digest {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var result []digest.Digest
+	found := map[digest.Digest]bool{}
+	for _, m := range r.byRepository[repo] {
+		if m.Subject == subject && !found[m.Digest] {
+			found[m.Digest] = true
+			result = append(result, m.Digest)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
 func (r *RepositoryIndex) GetArtifactInfos(digest digest.Digest) []*ArtifactMeta {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
@@ -238,10 +304,12 @@ func (r *RepositoryIndex) GetDescriptor() *ArtifactIndex {
 			vers := repo[name]
 			if "@"+vers.Digest.String() != name || vers.Tag == "" {
 				d := &ArtifactMeta{
-					Repository: vers.Repository,
-					Tag:        vers.Tag,
-					Digest:     vers.Digest,
-					MediaType:  vers.MediaType,
+					Repository:   vers.Repository,
+					Tag:          vers.Tag,
+					Digest:       vers.Digest,
+					MediaType:    vers.MediaType,
+					ArtifactType: vers.ArtifactType,
+					Subject:      vers.Subject,
 				}
 				index.Index = append(index.Index, *d)
 			}
@@ -4,6 +4,8 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	. "ocm.software/ocm/api/oci/extensions/repositories/ctf/index"
+
+	"github.com/opencontainers/go-digest"
 )
 
 var _ = Describe("index", func() {
@@ -210,4 +212,70 @@ var _ = Describe("index", func() {
 			})
 		})
 	})
+
+	Context("removal", func() {
+		It("removes all tags of an artifact", func() {
+			a1 := NewMeta("repo1", "v1", "digest1")
+			a2 := NewMeta("repo1", "v2", "digest1")
+			a3 := NewMeta("repo1", "v3", "digest2")
+			rindex.AddArtifactInfo(a1)
+			rindex.AddArtifactInfo(a2)
+			rindex.AddArtifactInfo(a3)
+
+			Expect(rindex.RemoveArtifact("repo1", "v1")).To(BeTrue())
+			Expect(rindex.GetArtifactInfo("repo1", "v1")).To(BeNil())
+			Expect(rindex.GetArtifactInfo("repo1", "v2")).To(BeNil())
+			Expect(rindex.GetArtifactInfo("repo1", "digest1")).To(BeNil())
+			Expect(rindex.GetArtifactInfos("digest1")).To(BeEmpty())
+			Expect(rindex.GetDescriptor().Index).To(Equal([]ArtifactMeta{
+				*a3,
+			}))
+			Expect(rindex.RemoveArtifact("repo1", "v1")).To(BeFalse())
+		})
+
+		It("keeps shared entries of other repositories", func() {
+			a1 := NewMeta("repo1", "v1", "digest1")
+			a2 := NewMeta("repo2", "v2", "digest1")
+			rindex.AddArtifactInfo(a1)
+			rindex.AddArtifactInfo(a2)
+
+			Expect(rindex.RemoveArtifact("repo1", "digest1")).To(BeTrue())
+			Expect(rindex.RepositoryList()).To(ConsistOf("repo2"))
+			Expect(rindex.GetArtifactInfos("digest1")).To(ConsistOf(a2))
+			Expect(rindex.GetDescriptor().Index).To(Equal([]ArtifactMeta{
+				*a2,
+			}))
+		})
+	})
+
+	Context("referrers", func() {
+		var rindex *RepositoryIndex
+
+		BeforeEach(func() {
+			rindex = NewRepositoryIndex()
+		})
+
+		It("finds referrers", func() {
+			a1 := NewMeta("repo1", "v1", "digest1")
+			r1 := NewMeta("repo1", "", "digest2")
+			r1.Subject = "digest1"
+			r1.ArtifactType = "sbom"
+			r2 := NewMeta("repo2", "", "digest3")
+			r2.Subject = "digest1"
+			rindex.AddArtifactInfo(a1)
+			rindex.AddArtifactInfo(r1)
+			rindex.AddArtifactInfo(r2)
+			Expect(rindex.AddTagsFor("repo1", "digest2", "sbom")).To(Succeed())
+
+			Expect(rindex.GetReferrers("repo1", "digest1")).To(Equal([]digest.Digest{"digest2"}))
+			Expect(rindex.GetReferrers("repo2", "digest1")).To(Equal([]digest.Digest{"digest3"}))
+			Expect(rindex.GetReferrers("repo1", "digest2")).To(BeEmpty())
+
+			e := *r1
+			e.Tag = "sbom"
+			Expect(rindex.GetDescriptor().Index).To(Equal([]ArtifactMeta{
+				e, *a1, *r2,
+			}))
+		})
+	})
 })
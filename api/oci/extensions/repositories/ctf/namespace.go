@@ -4,6 +4,7 @@ import (
 	"github.com/mandelsoft/goutils/errors"
 	"github.com/opencontainers/go-digest"
 
+	"ocm.software/ocm/api/oci/artdesc"
 	"ocm.software/ocm/api/oci/cpi"
 	"ocm.software/ocm/api/oci/cpi/support"
 	"ocm.software/ocm/api/oci/extensions/repositories/ctf/index"
@@ -20,7 +21,11 @@ type namespaceContainer struct {
 	repo *RepositoryImpl
 }
 
-var _ support.NamespaceContainer = (*namespaceContainer)(nil)
+var (
+	_ support.NamespaceContainer = (*namespaceContainer)(nil)
+	_ cpi.ArtifactDeleter        = (*namespaceContainer)(nil)
+	_ cpi.ReferrersLister        = (*namespaceContainer)(nil)
+)
 
 func newNamespaceContainer(repo *RepositoryImpl) support.NamespaceContainer {
 	return &namespaceContainer{
@@ -76,15 +81,46 @@ func (n *namespaceContainer) AddArtifact(artifact cpi.Artifact, tags ...string)
 	if err != nil {
 		return nil, err
 	}
-	n.repo.getIndex().AddArtifactInfo(&index.ArtifactMeta{
+	meta := &index.ArtifactMeta{
 		Repository: n.impl.GetNamespace(),
 		Tag:        "",
 		Digest:     blob.Digest(),
 		MediaType:  blob.MimeType(),
-	})
+	}
+	if s := artifact.Artifact().GetSubject(); s != nil {
+		meta.Subject = s.Digest
+		meta.ArtifactType = artifact.Artifact().GetArtifactType()
+	}
+	n.repo.getIndex().AddArtifactInfo(meta)
 	return blob, n.AddTags(blob.Digest(), tags...)
 }
 
+// ListReferrers lists the artifacts of the namespace referring to the
+// given artifact. Referrers are recorded in the index of the archive.
+func (n *namespaceContainer) ListReferrers(d digest.Digest, artifactType string) ([]artdesc.Descriptor, error) {
+	result := []artdesc.Descriptor{}
+	for _, r := range n.repo.getIndex().GetReferrers(n.impl.GetNamespace(), d) {
+		desc, _, err := support.ReadReferrer(n.repo.base, r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "referrer %s", r)
+		}
+		result = append(result, *desc)
+	}
+	return artdesc.FilterReferrers(result, artifactType), nil
+}
+
+// DeleteArtifact removes the artifact and all its tags from the index.
+// The blobs are kept, they are removed by a compaction of the archive.
+func (n *namespaceContainer) DeleteArtifact(vers string) error {
+	if n.IsReadOnly() {
+		return accessio.ErrReadOnly
+	}
+	if !n.repo.getIndex().RemoveArtifact(n.impl.GetNamespace(), vers) {
+		return errors.ErrNotFound(cpi.KIND_OCIARTIFACT, vers, n.impl.GetNamespace())
+	}
+	return nil
+}
+
 func (n *namespaceContainer) AddTags(digest digest.Digest, tags ...string) error {
 	return n.repo.getIndex().AddTagsFor(n.impl.GetNamespace(), digest, tags...)
 }
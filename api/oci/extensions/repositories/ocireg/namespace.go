@@ -30,7 +30,11 @@ type NamespaceContainer struct {
 	checked  bool
 }
 
-var _ support.NamespaceContainer = (*NamespaceContainer)(nil)
+var (
+	_ support.NamespaceContainer = (*NamespaceContainer)(nil)
+	_ cpi.ArtifactDeleter        = (*NamespaceContainer)(nil)
+	_ cpi.ReferrersLister        = (*NamespaceContainer)(nil)
+)
 
 func NewNamespace(repo *RepositoryImpl, name string) (cpi.NamespaceAccess, error) {
 	ref := repo.GetRef(name, "")
@@ -248,6 +252,49 @@ func (n *NamespaceContainer) AddTags(digest digest.Digest, tags ...string) error
 	return nil
 }
 
+// DeleteArtifact deletes the manifest described by the given version.
+// Depending on the registry, this deletes all tags referring to this
+// manifest. Layers are removed by the garbage collection of the registry.
+func (n *NamespaceContainer) DeleteArtifact(vers string) error {
+	if n.IsReadOnly() {
+		return accessio.ErrReadOnly
+	}
+	d, ok := n.resolver.(oras.DeleterProvider)
+	if !ok {
+		return errors.ErrNotSupported("artifact deletion", n.impl.GetNamespace())
+	}
+	ref := n.repo.GetRef(n.impl.GetNamespace(), vers)
+	n.repo.GetContext().Logger().Debug("delete artifact", "ref", ref)
+	_, desc, err := n.resolver.Resolve(context.Background(), ref)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return errors.ErrNotFound(cpi.KIND_OCIARTIFACT, ref, n.impl.GetNamespace())
+		}
+		return err
+	}
+	deleter, err := d.Deleter(context.Background(), ref)
+	if err != nil {
+		return err
+	}
+	return deleter.Delete(context.Background(), desc)
+}
+
+// ListReferrers lists the referrers of an artifact using the referrers API
+// of the registry, or the referrers tag schema, if not supported.
+func (n *NamespaceContainer) ListReferrers(d digest.Digest, artifactType string) ([]artdesc.Descriptor, error) {
+	r, ok := n.resolver.(oras.ReferrersProvider)
+	if !ok {
+		return cpi.ListReferrersByTagSchema(n.impl, d, artifactType)
+	}
+	ref := n.repo.GetRef(n.impl.GetNamespace(), d.String())
+	n.repo.GetContext().Logger().Debug("list referrers", "ref", ref, "artifactType", artifactType)
+	lister, err := r.Referrers(context.Background(), ref)
+	if err != nil {
+		return nil, err
+	}
+	return lister.List(context.Background(), artdesc.Descriptor{Digest: d}, artifactType)
+}
+
 func (n *NamespaceContainer) NewArtifact(i support.NamespaceAccessImpl, art ...cpi.Artifact) (cpi.ArtifactAccess, error) {
 	if n.IsReadOnly() {
 		return nil, accessio.ErrReadOnly
@@ -13,10 +13,10 @@ import (
 	"github.com/mandelsoft/logging"
 	"github.com/moby/locker"
 	"oras.land/oras-go/v2/registry/remote/auth"
-	"oras.land/oras-go/v2/registry/remote/retry"
 
 	"ocm.software/ocm/api/credentials"
 	"ocm.software/ocm/api/datacontext/attrs/httptimeoutattr"
+	"ocm.software/ocm/api/datacontext/attrs/retryattr"
 	"ocm.software/ocm/api/datacontext/attrs/rootcertsattr"
 	"ocm.software/ocm/api/oci/artdesc"
 	"ocm.software/ocm/api/oci/cpi"
@@ -155,7 +155,7 @@ func (r *RepositoryImpl) getResolver(comp string) (oras.Resolver, error) {
 	}
 
 	client := &http.Client{
-		Transport: retry.NewTransport(nil),
+		Transport: retryattr.Transport(r.GetContext(), nil, logger),
 		Timeout:   httptimeoutattr.Get(r.GetContext()),
 	}
 	client.Transport = ocmlog.NewRoundTripper(client.Transport, logger)
@@ -176,9 +176,9 @@ func (r *RepositoryImpl) getResolver(comp string) (oras.Resolver, error) {
 				return rootCAs
 			}(),
 		}
-		client.Transport = ocmlog.NewRoundTripper(retry.NewTransport(&http.Transport{
+		client.Transport = ocmlog.NewRoundTripper(retryattr.Transport(r.GetContext(), &http.Transport{
 			TLSClientConfig: conf,
-		}), logger)
+		}, logger), logger)
 	}
 
 	authClient := &auth.Client{
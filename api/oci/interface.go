@@ -33,6 +33,8 @@ type (
 	Artifact                         = internal.Artifact
 	NamespaceLister                  = internal.NamespaceLister
 	NamespaceAccess                  = internal.NamespaceAccess
+	ArtifactDeleter                  = internal.ArtifactDeleter
+	ReferrersLister                  = internal.ReferrersLister
 	ManifestAccess                   = internal.ManifestAccess
 	IndexAccess                      = internal.IndexAccess
 	BlobAccess                       = internal.BlobAccess
@@ -73,6 +73,23 @@ type NamespaceAccessImpl interface {
 	io.Closer
 }
 
+// ArtifactDeleter is an optional interface for namespace implementations
+// supporting the deletion of artifacts.
+type ArtifactDeleter interface {
+	// DeleteArtifact deletes the artifact described by the given
+	// version (tag or digest) together with all its tags.
+	DeleteArtifact(vers string) error
+}
+
+// ReferrersLister is an optional interface for namespace implementations
+// natively supporting the OCI 1.1 referrers API.
+type ReferrersLister interface {
+	// ListReferrers lists the descriptors of the artifacts referring
+	// to the artifact with the given digest by their subject field.
+	// If an artifact type is given, only referrers of this type are listed.
+	ListReferrers(digest digest.Digest, artifactType string) ([]artdesc.Descriptor, error)
+}
+
 type NamespaceAccess interface {
 	resource.ResourceView[NamespaceAccess]
 
@@ -0,0 +1,291 @@
+// Package cosign supports signatures and attachments of OCI artifacts
+// stored according to the tag scheme used by cosign
+// (<algorithm>-<hex digest>.<suffix>). Signatures are stored as simple
+// signing payloads, which can be verified by cosign and admission
+// controllers based on it.
+package cosign
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/opencontainers/go-digest"
+	"github.com/sigstore/cosign/v3/pkg/oci/static"
+	"github.com/sigstore/cosign/v3/pkg/types"
+	"github.com/sigstore/sigstore/pkg/signature/payload"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/oci/artdesc"
+	"ocm.software/ocm/api/oci/cpi"
+	"ocm.software/ocm/api/oci/tools/transfer"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/dsse"
+	"ocm.software/ocm/api/tech/signing/signutils"
+	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
+)
+
+const (
+	SignatureSuffix   = "sig"
+	AttestationSuffix = "att"
+	SBOMSuffix        = "sbom"
+)
+
+const (
+	SignatureAnnotation = static.SignatureAnnotationKey
+	SignatureMediaType  = types.SimpleSigningMediaType
+)
+
+// Suffixes are the tag suffixes used for attachments of an artifact.
+var Suffixes = []string{SignatureSuffix, AttestationSuffix, SBOMSuffix}
+
+// Payload is the simple signing payload signed by a cosign signature.
+type Payload = payload.SimpleContainerImage
+
+// Tag provides the tag used to store the attachment with the given suffix
+// for the artifact with the given digest.
+func Tag(d digest.Digest, suffix string) string {
+	return fmt.Sprintf("%s-%s.%s", d.Algorithm(), d.Encoded(), suffix)
+}
+
+// TransferAttachments copies the signatures, attestations and SBOMs
+// stored for the artifact with the given digest from the source
+// namespace to the target namespace. It returns the transferred tags.
+func TransferAttachments(src, tgt cpi.NamespaceAccess, d digest.Digest) ([]string, error) {
+	var tags []string
+	for _, s := range Suffixes {
+		tag := Tag(d, s)
+		ok, err := src.HasArtifact(tag)
+		if err != nil {
+			return tags, errors.Wrapf(err, "cannot check %s", tag)
+		}
+		if !ok {
+			continue
+		}
+		art, err := src.GetArtifact(tag)
+		if err != nil {
+			return tags, errors.Wrapf(err, "cannot access %s", tag)
+		}
+		err = transfer.TransferArtifact(art, tgt, tag)
+		art.Close()
+		if err != nil {
+			return tags, errors.Wrapf(err, "cannot transfer %s", tag)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// NewPayload creates the simple signing payload for an artifact
+// with the given (docker) reference and digest. The optional
+// annotations are added to the optional section of the payload.
+func NewPayload(ref string, d digest.Digest, optional map[string]interface{}) *Payload {
+	return &Payload{
+		Critical: payload.Critical{
+			Identity: payload.Identity{
+				DockerReference: ref,
+			},
+			Image: payload.Image{
+				DockerManifestDigest: d.String(),
+			},
+			Type: payload.CosignSignatureType,
+		},
+		Optional: optional,
+	}
+}
+
+// Sign signs the artifact with the given digest stored in a namespace
+// and stores the signature as cosign signature in this namespace.
+// The payload is hashed with the hash function of the signing context and
+// signed with the given signer. Signatures already stored for the artifact
+// are kept.
+func Sign(cctx credentials.Context, ns cpi.NamespaceAccess, ref string, d digest.Digest, optional map[string]interface{}, signer signing.Signer, sctx signing.SigningContext) error {
+	data, err := json.Marshal(NewPayload(ref, d, optional))
+	if err != nil {
+		return err
+	}
+	if !sctx.GetHash().Available() {
+		return errors.Newf("hash function %s not available", sctx.GetHash())
+	}
+	h := sctx.GetHash().New()
+	h.Write(data)
+	sig, err := signer.Sign(cctx, hex.EncodeToString(h.Sum(nil)), sctx)
+	if err != nil {
+		return err
+	}
+	raw, err := dsse.SignatureBytes(sig)
+	if err != nil {
+		return err
+	}
+
+	tag := Tag(d, SignatureSuffix)
+	layer := blobaccess.ForData(SignatureMediaType, data)
+	encoded := base64.StdEncoding.EncodeToString(raw)
+	desc := &artdesc.Descriptor{
+		MediaType: SignatureMediaType,
+		Annotations: map[string]string{
+			SignatureAnnotation: encoded,
+		},
+	}
+
+	art, err := ns.NewArtifact()
+	if err != nil {
+		return err
+	}
+	defer art.Close()
+	m := art.ManifestAccess()
+
+	var diffs []digest.Digest
+	oldart, err := getSignatureArtifact(ns, tag)
+	if err != nil {
+		return err
+	}
+	if oldart != nil {
+		defer oldart.Close()
+		old := oldart.ManifestAccess()
+		for _, l := range old.GetDescriptor().Layers {
+			if l.Digest == layer.Digest() && l.Annotations[SignatureAnnotation] == encoded {
+				continue
+			}
+			blob, err := old.GetBlob(l.Digest)
+			if err != nil {
+				return errors.Wrapf(err, "cannot access signature layer %s", l.Digest)
+			}
+			_, err = m.AddLayer(blob, &l)
+			if err != nil {
+				return err
+			}
+			diffs = append(diffs, l.Digest)
+		}
+	}
+	_, err = m.AddLayer(layer, desc)
+	if err != nil {
+		return err
+	}
+	diffs = append(diffs, layer.Digest())
+
+	config, err := json.Marshal(newConfig(diffs))
+	if err != nil {
+		return err
+	}
+	err = m.SetConfigBlob(blobaccess.ForData(artdesc.MediaTypeImageConfig, config), nil)
+	if err != nil {
+		return err
+	}
+	blob, err := ns.AddArtifact(art, tag)
+	if err != nil {
+		return errors.Wrapf(err, "cannot store signature %s", tag)
+	}
+	return blob.Close()
+}
+
+// Verify verifies the signatures stored for the artifact with the
+// given digest in a namespace. It succeeds if at least one signature
+// can be verified with the given verifier and the public key of the
+// signing context, and returns the payload of this signature.
+func Verify(ns cpi.NamespaceAccess, d digest.Digest, verifier signing.Verifier, sctx signing.SigningContext) (*Payload, error) {
+	tag := Tag(d, SignatureSuffix)
+	art, err := getSignatureArtifact(ns, tag)
+	if err != nil {
+		return nil, err
+	}
+	if art == nil {
+		return nil, errors.ErrNotFound("signature", tag, ns.GetNamespace())
+	}
+	defer art.Close()
+	m := art.ManifestAccess()
+
+	list := errors.ErrListf("no valid signature found for %s", d)
+	for _, l := range m.GetDescriptor().Layers {
+		if l.MediaType != SignatureMediaType {
+			continue
+		}
+		p, err := verifyLayer(m, &l, d, verifier, sctx)
+		if err == nil {
+			return p, nil
+		}
+		list.Add(errors.Wrapf(err, "layer %s", l.Digest))
+	}
+	if list.Len() == 0 {
+		return nil, errors.Newf("no signature layer found for %s", d)
+	}
+	return nil, list.Result()
+}
+
+func verifyLayer(m cpi.ManifestAccess, l *artdesc.Descriptor, d digest.Digest, verifier signing.Verifier, sctx signing.SigningContext) (*Payload, error) {
+	blob, err := m.GetBlob(l.Digest)
+	if err != nil {
+		return nil, err
+	}
+	defer blob.Close()
+	data, err := blob.Get()
+	if err != nil {
+		return nil, err
+	}
+	var p Payload
+	err = json.Unmarshal(data, &p)
+	if err != nil {
+		return nil, errors.ErrInvalidWrap(err, "signature payload")
+	}
+	if p.Critical.Image.DockerManifestDigest != d.String() {
+		return nil, errors.Newf("payload describes digest %s", p.Critical.Image.DockerManifestDigest)
+	}
+	raw, err := base64.StdEncoding.DecodeString(l.Annotations[SignatureAnnotation])
+	if err != nil || len(raw) == 0 {
+		return nil, errors.ErrInvalid("signature annotation")
+	}
+	if !sctx.GetHash().Available() {
+		return nil, errors.Newf("hash function %s not available", sctx.GetHash())
+	}
+	h := sctx.GetHash().New()
+	h.Write(data)
+	sig := &signing.Signature{
+		Value:     string(signutils.SignatureBytesToPem(verifier.Algorithm(), raw)),
+		MediaType: signutils.MediaTypePEM,
+		Algorithm: verifier.Algorithm(),
+	}
+	err = verifier.Verify(hex.EncodeToString(h.Sum(nil)), sig, sctx)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func getSignatureArtifact(ns cpi.NamespaceAccess, tag string) (cpi.ArtifactAccess, error) {
+	ok, err := ns.HasArtifact(tag)
+	if err != nil || !ok {
+		return nil, err
+	}
+	art, err := ns.GetArtifact(tag)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot access %s", tag)
+	}
+	if !art.IsManifest() {
+		art.Close()
+		return nil, errors.Newf("%s is no manifest", tag)
+	}
+	return art, nil
+}
+
+// config is the image configuration used for signature artifacts.
+type config struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	RootFS       rootFS `json:"rootfs"`
+}
+
+type rootFS struct {
+	Type    string          `json:"type"`
+	DiffIDs []digest.Digest `json:"diff_ids"`
+}
+
+func newConfig(diffs []digest.Digest) *config {
+	return &config{
+		RootFS: rootFS{
+			Type:    "layers",
+			DiffIDs: diffs,
+		},
+	}
+}
@@ -0,0 +1,132 @@
+package cosign_test
+
+import (
+	"crypto"
+	gorsa "crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/helper/builder"
+	. "ocm.software/ocm/api/oci/testhelper"
+
+	"github.com/opencontainers/go-digest"
+
+	"ocm.software/ocm/api/oci"
+	"ocm.software/ocm/api/oci/extensions/repositories/ctf"
+	"ocm.software/ocm/api/oci/tools/cosign"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+)
+
+const (
+	OUT     = "/tmp/res"
+	OCIPATH = "/tmp/oci"
+)
+
+var _ = Describe("cosign signatures", func() {
+	var env *Builder
+	var repo oci.Repository
+	var ns oci.NamespaceAccess
+
+	dig := digest.Digest("sha256:" + D_OCIMANIFEST1)
+
+	BeforeEach(func() {
+		env = NewBuilder()
+		env.OCICommonTransport(OCIPATH, accessio.FormatDirectory, func() {
+			OCIManifest1(env)
+		})
+		repo = Must(ctf.Open(env, accessobj.ACC_WRITABLE, OCIPATH, 0, env))
+		ns = Must(repo.LookupNamespace(OCINAMESPACE))
+	})
+
+	AfterEach(func() {
+		Close(ns, "namespace")
+		Close(repo, "repository")
+		env.Cleanup()
+	})
+
+	sign := func(priv interface{}, optional map[string]interface{}) {
+		sctx := &signing.DefaultSigningContext{
+			Hash:       crypto.SHA256,
+			PrivateKey: priv,
+		}
+		MustBeSuccessful(cosign.Sign(env.CredentialsContext(), ns, "acme.org/"+OCINAMESPACE, dig, optional, rsa.NewHandler(), sctx))
+	}
+
+	verify := func(pub interface{}) (*cosign.Payload, error) {
+		sctx := &signing.DefaultSigningContext{
+			Hash:      crypto.SHA256,
+			PublicKey: pub,
+		}
+		return cosign.Verify(ns, dig, rsa.NewHandler(), sctx)
+	}
+
+	It("uses the cosign tag scheme", func() {
+		Expect(cosign.Tag(dig, cosign.SignatureSuffix)).To(Equal("sha256-" + D_OCIMANIFEST1 + ".sig"))
+	})
+
+	It("signs and verifies an artifact", func() {
+		priv, pub := Must2(rsa.CreateKeyPair())
+		sign(priv, map[string]interface{}{"creator": "acme"})
+
+		p := Must(verify(pub))
+		Expect(p.Critical.Type).To(Equal("cosign container image signature"))
+		Expect(p.Critical.Identity.DockerReference).To(Equal("acme.org/" + OCINAMESPACE))
+		Expect(p.Critical.Image.DockerManifestDigest).To(Equal(dig.String()))
+		Expect(p.Optional).To(HaveKeyWithValue("creator", "acme"))
+
+		// signature must be verifiable by standard tools
+		art := Must(ns.GetArtifact(cosign.Tag(dig, cosign.SignatureSuffix)))
+		defer Close(art, "signature")
+		m := art.ManifestAccess()
+		Expect(m.GetDescriptor().Layers).To(HaveLen(1))
+		l := m.GetDescriptor().Layers[0]
+		Expect(l.MediaType).To(Equal(cosign.SignatureMediaType))
+		data := Must(Must(m.GetBlob(l.Digest)).Get())
+		raw := Must(base64.StdEncoding.DecodeString(l.Annotations[cosign.SignatureAnnotation]))
+		hash := sha256.Sum256(data)
+		MustBeSuccessful(gorsa.VerifyPKCS1v15(pub.(*gorsa.PublicKey), crypto.SHA256, hash[:], raw))
+	})
+
+	It("keeps existing signatures", func() {
+		priv1, pub1 := Must2(rsa.CreateKeyPair())
+		priv2, pub2 := Must2(rsa.CreateKeyPair())
+		sign(priv1, nil)
+		sign(priv2, nil)
+
+		art := Must(ns.GetArtifact(cosign.Tag(dig, cosign.SignatureSuffix)))
+		defer Close(art, "signature")
+		Expect(art.ManifestAccess().GetDescriptor().Layers).To(HaveLen(2))
+		Must(verify(pub1))
+		Must(verify(pub2))
+	})
+
+	It("rejects wrong key", func() {
+		priv, _ := Must2(rsa.CreateKeyPair())
+		_, other := Must2(rsa.CreateKeyPair())
+		sign(priv, nil)
+		ExpectError(verify(other)).To(MatchError(ContainSubstring("no valid signature found for " + dig.String())))
+	})
+
+	It("transfers attachments", func() {
+		priv, pub := Must2(rsa.CreateKeyPair())
+		sign(priv, nil)
+
+		tgt := Must(ctf.Create(env, accessobj.ACC_WRITABLE|accessobj.ACC_CREATE, OUT, 0o700, accessio.FormatDirectory, env))
+		defer Close(tgt, "target")
+		tns := Must(tgt.LookupNamespace(OCINAMESPACE))
+		defer Close(tns, "target namespace")
+
+		Expect(cosign.TransferAttachments(ns, tns, dig)).To(ConsistOf(cosign.Tag(dig, cosign.SignatureSuffix)))
+		sctx := &signing.DefaultSigningContext{
+			Hash:      crypto.SHA256,
+			PublicKey: pub,
+		}
+		Must(cosign.Verify(tns, dig, rsa.NewHandler(), sctx))
+	})
+})
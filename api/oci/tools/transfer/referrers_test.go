@@ -0,0 +1,172 @@
+package transfer_test
+
+import (
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/helper/builder"
+	. "ocm.software/ocm/api/oci/testhelper"
+
+	"github.com/opencontainers/go-digest"
+
+	"ocm.software/ocm/api/oci"
+	"ocm.software/ocm/api/oci/artdesc"
+	"ocm.software/ocm/api/oci/cpi"
+	"ocm.software/ocm/api/oci/extensions/repositories/artifactset"
+	"ocm.software/ocm/api/oci/extensions/repositories/ctf"
+	"ocm.software/ocm/api/oci/tools/transfer"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
+	"ocm.software/ocm/api/utils/mime"
+)
+
+const (
+	SBOM_TYPE = "application/spdx+json"
+	SIG_TYPE  = "application/vnd.dev.sigstore.bundle.v0.3+json"
+)
+
+func AddReferrer(ns oci.NamespaceAccess, subject *artdesc.Descriptor, artifactType, content string) *artdesc.Descriptor {
+	art := Must(ns.NewArtifact())
+	defer Close(art, "referrer")
+	m := art.ManifestAccess()
+	MustBeSuccessful(m.SetConfigBlob(blobaccess.ForString(artdesc.MediaTypeEmptyJSON, "{}"), nil))
+	Must(m.AddLayer(blobaccess.ForString(mime.MIME_TEXT, content), nil))
+	MustBeSuccessful(m.Modify(func(d *artdesc.Manifest) error {
+		d.ArtifactType = artifactType
+		d.SetSubject(subject)
+		return nil
+	}))
+	blob := Must(ns.AddArtifact(art))
+	defer Close(blob, "referrer blob")
+	return artdesc.DefaultBlobDescriptor(blob)
+}
+
+func Digests(list []artdesc.Descriptor) []digest.Digest {
+	var result []digest.Digest
+	for _, d := range list {
+		result = append(result, d.Digest)
+	}
+	return result
+}
+
+var _ = Describe("transfer OCI referrers", func() {
+	var env *Builder
+	var subject, sbom, sig, sbomsig *artdesc.Descriptor
+
+	BeforeEach(func() {
+		env = NewBuilder()
+		env.OCICommonTransport(OCIPATH, accessio.FormatDirectory, func() {
+			OCIManifest1(env)
+		})
+
+		repo := Must(ctf.Open(env.OCIContext(), accessobj.ACC_WRITABLE, OCIPATH, 0, env))
+		defer Close(repo, "source")
+		ns := Must(repo.LookupNamespace(OCINAMESPACE))
+		defer Close(ns, "source namespace")
+
+		art := Must(ns.GetArtifact(OCIVERSION))
+		defer Close(art, "subject")
+		subject = artdesc.DefaultBlobDescriptor(Must(art.Blob()))
+
+		sbom = AddReferrer(ns, subject, SBOM_TYPE, "sbom")
+		sig = AddReferrer(ns, subject, SIG_TYPE, "signature")
+		sbomsig = AddReferrer(ns, sbom, SIG_TYPE, "sbom signature")
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("lists referrers of a CTF", func() {
+		repo := Must(ctf.Open(env.OCIContext(), accessobj.ACC_READONLY, OCIPATH, 0, env))
+		defer Close(repo, "source")
+		ns := Must(repo.LookupNamespace(OCINAMESPACE))
+		defer Close(ns, "source namespace")
+
+		list := Must(oci.ListReferrers(ns, subject.Digest))
+		Expect(Digests(list)).To(ConsistOf(sbom.Digest, sig.Digest))
+		for _, d := range list {
+			if d.Digest == sbom.Digest {
+				Expect(d.ArtifactType).To(Equal(SBOM_TYPE))
+				Expect(d.MediaType).To(Equal(artdesc.MediaTypeImageManifest))
+				Expect(d.Size).To(Equal(sbom.Size))
+			}
+		}
+		Expect(Digests(Must(oci.ListReferrers(ns, subject.Digest, SIG_TYPE)))).To(ConsistOf(sig.Digest))
+		Expect(Digests(Must(oci.ListReferrers(ns, sbom.Digest)))).To(ConsistOf(sbomsig.Digest))
+		Expect(Must(oci.ListReferrers(ns, sig.Digest))).To(BeEmpty())
+	})
+
+	It("lists referrers according to the tag schema", func() {
+		repo := Must(ctf.Open(env.OCIContext(), accessobj.ACC_WRITABLE, OCIPATH, 0, env))
+		defer Close(repo, "source")
+		ns := Must(repo.LookupNamespace(OCINAMESPACE))
+		defer Close(ns, "source namespace")
+
+		Expect(cpi.ListReferrersByTagSchema(ns, subject.Digest, "")).To(BeEmpty())
+
+		idx := artdesc.NewIndexArtifact()
+		i := Must(idx.Index())
+		s := *sig
+		s.ArtifactType = SIG_TYPE
+		i.AddManifest(&s)
+		b := *sbom
+		b.ArtifactType = SBOM_TYPE
+		i.AddManifest(&b)
+		Must(ns.AddArtifact(idx, artdesc.ReferrersTag(subject.Digest)))
+
+		Expect(Digests(Must(cpi.ListReferrersByTagSchema(ns, subject.Digest, "")))).To(Equal([]digest.Digest{sig.Digest, sbom.Digest}))
+		Expect(Digests(Must(cpi.ListReferrersByTagSchema(ns, subject.Digest, SBOM_TYPE)))).To(Equal([]digest.Digest{sbom.Digest}))
+	})
+
+	It("transfers referrers to a CTF", func() {
+		repo := Must(ctf.Open(env.OCIContext(), accessobj.ACC_READONLY, OCIPATH, 0, env))
+		defer Close(repo, "source")
+		ns := Must(repo.LookupNamespace(OCINAMESPACE))
+		defer Close(ns, "source namespace")
+		art := Must(ns.GetArtifact(OCIVERSION))
+		defer Close(art, "source artifact")
+
+		tgt := Must(ctf.Create(env.OCIContext(), accessobj.ACC_WRITABLE|accessobj.ACC_CREATE, OUT, 0o700, accessio.FormatDirectory, env))
+		tns := Must(tgt.LookupNamespace(OCINAMESPACE))
+
+		MustBeSuccessful(transfer.TransferArtifact(art, tns, OCIVERSION))
+		Expect(transfer.TransferReferrers(ns, subject.Digest, tns)).To(ConsistOf(sbom.Digest, sig.Digest, sbomsig.Digest))
+		MustBeSuccessful(tns.Close())
+		MustBeSuccessful(tgt.Close())
+
+		tgt = Must(ctf.Open(env.OCIContext(), accessobj.ACC_READONLY, OUT, 0, env))
+		defer Close(tgt, "target")
+		tns = Must(tgt.LookupNamespace(OCINAMESPACE))
+		defer Close(tns, "target namespace")
+
+		Expect(Digests(Must(oci.ListReferrers(tns, subject.Digest)))).To(ConsistOf(sbom.Digest, sig.Digest))
+		Expect(Digests(Must(oci.ListReferrers(tns, sbom.Digest)))).To(ConsistOf(sbomsig.Digest))
+		ref := Must(tns.GetArtifact(sbomsig.Digest.String()))
+		defer Close(ref, "referrer")
+		Expect(ref.GetDescriptor().GetSubject().Digest).To(Equal(sbom.Digest))
+	})
+
+	It("transfers selected referrers to an artifact set", func() {
+		repo := Must(ctf.Open(env.OCIContext(), accessobj.ACC_READONLY, OCIPATH, 0, env))
+		defer Close(repo, "source")
+		ns := Must(repo.LookupNamespace(OCINAMESPACE))
+		defer Close(ns, "source namespace")
+		art := Must(ns.GetArtifact(OCIVERSION))
+		defer Close(art, "source artifact")
+
+		opts := Must(accessio.AccessOptions(nil, accessio.PathFileSystem(env.FileSystem())))
+		set := Must(artifactset.FormatDirectory.Create(OUT, opts, 0o700))
+		MustBeSuccessful(transfer.TransferArtifact(art, set, OCIVERSION))
+		Expect(transfer.TransferReferrers(ns, subject.Digest, set, SIG_TYPE)).To(ConsistOf(sig.Digest))
+		MustBeSuccessful(set.Close())
+
+		set = Must(artifactset.Open(accessobj.ACC_READONLY, OUT, 0, env))
+		defer Close(set, "artifact set")
+		list := Must(oci.ListReferrers(set, subject.Digest))
+		Expect(Digests(list)).To(ConsistOf(sig.Digest))
+		Expect(list[0].ArtifactType).To(Equal(SIG_TYPE))
+		Expect(set.GetIndex().GetBlobDescriptor(sig.Digest).ArtifactType).To(Equal(SIG_TYPE))
+	})
+})
@@ -140,3 +140,58 @@ func TransferManifest(art cpi.ManifestAccess, set cpi.ArtifactSink, tags ...stri
 	}
 	return blob.Close()
 }
+
+// TransferReferrers transfers the artifacts referring to the artifact with
+// the given digest (OCI 1.1 subject field) from the source to the target
+// namespace. Referrers of transferred referrers are transferred, also.
+// If artifact types are given, only referrers of these types are
+// transferred. It returns the digests of the transferred artifacts.
+func TransferReferrers(src cpi.NamespaceAccess, d digest.Digest, tgt cpi.ArtifactSink, artifactTypes ...string) ([]digest.Digest, error) {
+	lister, ok := src.(cpi.ReferrersLister)
+	if !ok {
+		return nil, errors.ErrNotSupported("referrers", src.GetNamespace())
+	}
+
+	var result []digest.Digest
+	done := map[digest.Digest]bool{d: true}
+	queue := []digest.Digest{d}
+	for len(queue) > 0 {
+		subject := queue[0]
+		queue = queue[1:]
+		list, err := lister.ListReferrers(subject, "")
+		if err != nil {
+			return result, errors.Wrapf(err, "listing referrers of %s", subject)
+		}
+		for _, r := range list {
+			if done[r.Digest] || !matchArtifactType(r.ArtifactType, artifactTypes) {
+				continue
+			}
+			done[r.Digest] = true
+			logging.Logger().Debug("transfer OCI referrer", "subject", subject, "digest", r.Digest, "artifactType", r.ArtifactType)
+			art, err := src.GetArtifact(r.Digest.String())
+			if err != nil {
+				return result, errors.Wrapf(err, "getting referrer %s", r.Digest)
+			}
+			err = TransferArtifact(art, tgt)
+			art.Close()
+			if err != nil {
+				return result, errors.Wrapf(err, "transferring referrer %s", r.Digest)
+			}
+			result = append(result, r.Digest)
+			queue = append(queue, r.Digest)
+		}
+	}
+	return result, nil
+}
+
+func matchArtifactType(t string, types []string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, e := range types {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/general"
+	"github.com/opencontainers/go-digest"
+
 	"ocm.software/ocm/api/credentials/cpi"
 	"ocm.software/ocm/api/oci/artdesc"
 	"ocm.software/ocm/api/oci/grammar"
@@ -33,6 +37,26 @@ func StandardOCIRef(host, repository, version string) string {
 	return fmt.Sprintf("%s%s%s%s%s", host, grammar.RepositorySeparator, repository, sep, version)
 }
 
+// DeleteArtifact deletes an artifact together with all its tags
+// from a namespace, if supported by the repository implementation.
+func DeleteArtifact(ns NamespaceAccess, vers string) error {
+	if d, ok := ns.(ArtifactDeleter); ok {
+		return d.DeleteArtifact(vers)
+	}
+	return errors.ErrNotSupported("artifact deletion", ns.GetNamespace())
+}
+
+// ListReferrers lists the descriptors of the artifacts referring to the
+// artifact with the given digest (OCI 1.1 subject field). If an artifact type
+// is given, only referrers of this type are listed. Namespaces not supporting
+// the referrers API are evaluated according to the referrers tag schema.
+func ListReferrers(ns NamespaceAccess, d digest.Digest, artifactType ...string) ([]artdesc.Descriptor, error) {
+	if r, ok := ns.(ReferrersLister); ok {
+		return r.ListReferrers(d, general.Optional(artifactType...))
+	}
+	return nil, errors.ErrNotSupported("referrers", ns.GetNamespace())
+}
+
 func IsIntermediate(spec RepositorySpec) bool {
 	if s, ok := spec.(IntermediateRepositorySpecAspect); ok {
 		return s.IsIntermediate()
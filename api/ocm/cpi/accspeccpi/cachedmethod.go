@@ -0,0 +1,184 @@
+package accspeccpi
+
+import (
+	"io"
+	"sync"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/opencontainers/go-digest"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/oci/extensions/attrs/cacheattr"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/extensions/attrs/cacheaccessattr"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/hasher/sha256"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
+)
+
+// genericBlobDigestV1 is the normalization algorithm of digests
+// describing the plain blob content.
+const genericBlobDigestV1 = "genericBlobDigest/v1"
+
+// CacheableAccessSpec can be implemented by access specifications
+// describing immutable external content, which may be kept in
+// a content cache configured for a context.
+// Access methods for OCI based specifications (ociArtifact, ociBlob)
+// are already cached on the level of the OCI blobs. The content of other
+// access methods can be cached by configuring their types with
+// the cacheaccessattr attribute.
+type CacheableAccessSpec interface {
+	IsContentCacheable() bool
+}
+
+// CachedAccessMethod wraps an access method by a method using the
+// content cache configured for the given context. The content is only
+// cached for an expected digest describing the plain blob content (for
+// example, the digest of a resource). It is looked up in the cache by this
+// digest, and content read from the original method is only served
+// from the cache, if it matches this digest.
+// If no cache is configured, no usable digest is given or the access
+// specification is not cacheable, the original method is returned.
+func CachedAccessMethod(ctx datacontext.Context, m AccessMethod, expected *metav1.DigestSpec) (AccessMethod, error) {
+	cache := cacheattr.Get(ctx)
+	if cache == nil || m.IsLocal() {
+		return m, nil
+	}
+	switch GetAccessMethodImplementation(m).(type) {
+	case *cachedMethod, *cachedMethodWithDigest:
+		return m, nil
+	}
+	if !isContentCacheable(ctx, m) {
+		return m, nil
+	}
+	d := blobDigest(expected)
+	if d == "" {
+		return m, nil
+	}
+	err := cache.Ref()
+	if err != nil {
+		return nil, err
+	}
+	impl := &cachedMethod{
+		cache:  cache,
+		method: m,
+		digest: d,
+	}
+	if _, ok := GetAccessMethodImplementation(m).(DigestSpecProvider); ok {
+		return AccessMethodForImplementation(&cachedMethodWithDigest{impl}, nil)
+	}
+	return AccessMethodForImplementation(impl, nil)
+}
+
+func isContentCacheable(ctx datacontext.Context, m AccessMethod) bool {
+	if c, ok := m.AccessSpec().(CacheableAccessSpec); ok && c.IsContentCacheable() {
+		return true
+	}
+	return cacheaccessattr.IsCached(ctx, m.GetKind())
+}
+
+// blobDigest provides the content digest used by blob caches for
+// a digest describing the plain blob content. For other digests
+// an empty digest is returned.
+func blobDigest(d *metav1.DigestSpec) digest.Digest {
+	if d == nil || d.NormalisationAlgorithm != genericBlobDigestV1 || signing.NormalizeHashAlgorithm(d.HashAlgorithm) != sha256.Algorithm {
+		return ""
+	}
+	dig := digest.NewDigestFromEncoded(digest.SHA256, d.Value)
+	if dig.Validate() != nil {
+		return ""
+	}
+	return dig
+}
+
+type cachedMethod struct {
+	lock   sync.Mutex
+	cache  accessio.BlobCache
+	method AccessMethod
+	digest digest.Digest
+}
+
+var (
+	_ AccessMethodImpl                     = (*cachedMethod)(nil)
+	_ credentials.ConsumerIdentityProvider = (*cachedMethod)(nil)
+)
+
+// getData provides the cached content. If the content cannot be
+// cached, the original access method is used.
+func (m *cachedMethod) getData() blobaccess.DataAccess {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	_, data, err := m.cache.GetBlobData(m.digest)
+	if err == nil {
+		return data
+	}
+	blob := blobaccess.ForDataAccess("", -1, m.method.MimeType(), blobaccess.DataAccessForReaderFunction(m.method.Reader, m.digest.String()))
+	_, d, err := m.cache.AddBlob(blob)
+	// content not matching the expected digest is never looked up,
+	// the digest verification of the consumer will fail.
+	if err == nil && d == m.digest {
+		_, data, err = m.cache.GetBlobData(d)
+		if err == nil {
+			return data
+		}
+	}
+	return m.method
+}
+
+func (m *cachedMethod) Get() ([]byte, error) {
+	return m.getData().Get()
+}
+
+func (m *cachedMethod) Reader() (io.ReadCloser, error) {
+	return m.getData().Reader()
+}
+
+func (m *cachedMethod) Close() error {
+	list := errors.ErrListf("closing cached access method")
+	list.Add(m.method.Close())
+	list.Add(m.cache.Unref())
+	return list.Result()
+}
+
+func (m *cachedMethod) IsLocal() bool {
+	return m.method.IsLocal()
+}
+
+func (m *cachedMethod) GetKind() string {
+	return m.method.GetKind()
+}
+
+func (m *cachedMethod) AccessSpec() AccessSpec {
+	return m.method.AccessSpec()
+}
+
+func (m *cachedMethod) MimeType() string {
+	return m.method.MimeType()
+}
+
+func (m *cachedMethod) GetConsumerId(uctx ...credentials.UsageContext) credentials.ConsumerIdentity {
+	if p, ok := m.method.(credentials.ConsumerIdentityProvider); ok {
+		return p.GetConsumerId(uctx...)
+	}
+	return nil
+}
+
+func (m *cachedMethod) GetIdentityMatcher() string {
+	if p, ok := m.method.(credentials.ConsumerIdentityProvider); ok {
+		return p.GetIdentityMatcher()
+	}
+	return ""
+}
+
+type cachedMethodWithDigest struct {
+	*cachedMethod
+}
+
+var _ DigestSpecProvider = (*cachedMethodWithDigest)(nil)
+
+func (m *cachedMethodWithDigest) GetDigestSpec() (*metav1.DigestSpec, error) {
+	return GetAccessMethodImplementation(m.method).(DigestSpecProvider).GetDigestSpec()
+}
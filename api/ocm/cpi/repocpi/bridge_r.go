@@ -52,6 +52,25 @@ func SetBlobLimit(i RepositoryImpl, s int64) bool {
 	return false
 }
 
+// ComponentVersionDeleter is an optional interface, which
+// may be implemented to support the deletion of component versions.
+type ComponentVersionDeleter interface {
+	DeleteComponentVersion(name string, version string) error
+}
+
+// DeleteComponentVersion deletes a component version from a repository,
+// if this is supported by the repository implementation.
+func DeleteComponentVersion(r cpi.Repository, name string, version string) error {
+	impl, err := GetRepositoryImplementation(r)
+	if err != nil {
+		return err
+	}
+	if d, ok := impl.(ComponentVersionDeleter); ok {
+		return d.DeleteComponentVersion(name, version)
+	}
+	return errors.ErrNotSupported("component version deletion", r.GetSpecification().GetKind())
+}
+
 type _repositoryBridgeBase = resource.ResourceImplBase[cpi.Repository]
 
 type repositoryBridge struct {
@@ -7,6 +7,7 @@ import (
 
 	"ocm.software/ocm/api/credentials"
 	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
 	"ocm.software/ocm/api/ocm/cpi/accspeccpi"
 	cpi "ocm.software/ocm/api/ocm/internal"
 	"ocm.software/ocm/api/ocm/plugin/descriptor"
@@ -28,6 +29,8 @@ type ComponentVersionProvider interface {
 type ComponentVersionBasedAccessProvider struct {
 	vers   ComponentVersionAccess
 	access compdesc.AccessSpec
+	// digest is the expected digest of the content, if known.
+	digest *metav1.DigestSpec
 }
 
 var (
@@ -71,7 +74,15 @@ func (r *ComponentVersionBasedAccessProvider) AccessMethod() (AccessMethod, erro
 	if err != nil {
 		return nil, err
 	}
-	return acc.AccessMethod(r.vers)
+	m, err := acc.AccessMethod(r.vers)
+	if err != nil {
+		return nil, err
+	}
+	cm, err := accspeccpi.CachedAccessMethod(r.vers.GetContext(), m, r.digest)
+	if err != nil {
+		return nil, errors.Join(err, m.Close())
+	}
+	return cm, nil
 }
 
 func (r *ComponentVersionBasedAccessProvider) BlobAccess() (BlobAccess, error) {
@@ -233,7 +244,9 @@ func (b *artifactAccessProvider[M]) GetComponentVersion() (ComponentVersionAcces
 var _ ResourceAccess = (*artifactAccessProvider[ResourceMeta])(nil)
 
 func NewResourceAccess(componentVersion ComponentVersionAccess, accessSpec compdesc.AccessSpec, meta ResourceMeta) ResourceAccess {
-	return NewResourceAccessForProvider(&meta, NewBaseAccess(componentVersion, accessSpec))
+	prov := NewBaseAccess(componentVersion, accessSpec)
+	prov.digest = meta.Digest
+	return NewResourceAccessForProvider(&meta, prov)
 }
 
 func NewResourceAccessForProvider(meta *ResourceMeta, prov AccessProvider) ResourceAccess {
@@ -0,0 +1,7 @@
+package github
+
+import (
+	ocmlog "ocm.software/ocm/api/utils/logging"
+)
+
+var REALM = ocmlog.DefineSubRealm("access method for GitHub repositories", "accessmethod/github")
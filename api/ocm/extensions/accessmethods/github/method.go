@@ -15,6 +15,7 @@ import (
 	"golang.org/x/oauth2"
 
 	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/datacontext/attrs/retryattr"
 	"ocm.software/ocm/api/ocm/cpi/accspeccpi"
 	"ocm.software/ocm/api/tech/github/identity"
 	"ocm.software/ocm/api/utils/accessio"
@@ -125,19 +126,23 @@ func (a *AccessSpec) AccessMethod(c accspeccpi.ComponentVersionAccess) (accspecc
 	return accspeccpi.AccessMethodForImplementation(newMethod(c, a))
 }
 
-func (a *AccessSpec) createHTTPClient(token string) *http.Client {
+func (a *AccessSpec) createHTTPClient(octx accspeccpi.Context, token string) *http.Client {
+	client := a.client
+	if client == nil {
+		client = newRetryingClient(octx)
+	}
 	if token != "" {
 		ts := oauth2.StaticTokenSource(
 			&oauth2.Token{AccessToken: token},
 		)
-		ctx := context.Background()
-		// set up the test client if we have one
-		if a.client != nil {
-			ctx = context.WithValue(ctx, oauth2.HTTPClient, a.client)
-		}
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
 		return oauth2.NewClient(ctx, ts)
 	}
-	return a.client
+	return client
+}
+
+func newRetryingClient(octx accspeccpi.Context) *http.Client {
+	return &http.Client{Transport: retryattr.Transport(octx, nil, octx.Logger(REALM))}
 }
 
 // RepositoryService defines capabilities of a GitHub repository.
@@ -185,7 +190,7 @@ func newMethod(c accspeccpi.ComponentVersionAccess, a *AccessSpec) (*accessMetho
 	}
 
 	var client *github.Client
-	httpclient := a.createHTTPClient(token)
+	httpclient := a.createHTTPClient(c.GetContext(), token)
 
 	if u.Hostname() == "github.com" {
 		client = github.NewClient(httpclient)
@@ -286,7 +291,7 @@ func (m *accessMethod) setup() error {
 		return fmt.Errorf("failed to get download link: %w", err)
 	}
 
-	d := hd.NewDownloader(link)
+	d := hd.NewDownloaderWithClient(link, newRetryingClient(m.compvers.GetContext()))
 	if m.spec.downloader != nil {
 		d = m.spec.downloader
 	}
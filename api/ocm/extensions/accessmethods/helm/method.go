@@ -71,6 +71,10 @@ func (a *AccessSpec) GlobalAccessSpec(ctx accspeccpi.Context) accspeccpi.AccessS
 	return a
 }
 
+func (a *AccessSpec) IsContentCacheable() bool {
+	return true
+}
+
 func (a *AccessSpec) GetMimeType() string {
 	return helm.ChartMediaType
 }
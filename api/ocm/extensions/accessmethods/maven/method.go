@@ -5,6 +5,7 @@ import (
 
 	"github.com/mandelsoft/goutils/optionutils"
 
+	"ocm.software/ocm/api/datacontext/attrs/retryattr"
 	"ocm.software/ocm/api/datacontext/attrs/vfsattr"
 	"ocm.software/ocm/api/ocm/cpi/accspeccpi"
 	"ocm.software/ocm/api/tech/maven"
@@ -86,6 +87,10 @@ func (a *AccessSpec) GlobalAccessSpec(_ accspeccpi.Context) accspeccpi.AccessSpe
 	return a
 }
 
+func (a *AccessSpec) IsContentCacheable() bool {
+	return true
+}
+
 // GetReferenceHint returns the reference hint for the Maven (mvn) artifact.
 func (a *AccessSpec) GetReferenceHint(_ accspeccpi.ComponentVersionAccess) string {
 	if a.IsPackage() {
@@ -110,6 +115,7 @@ func (a *AccessSpec) AccessMethod(cv accspeccpi.ComponentVersionAccess) (accspec
 		return mavenblob.BlobAccessForCoords(repo, &a.Coordinates,
 			mavenblob.WithCredentialContext(octx),
 			mavenblob.WithLoggingContext(octx),
+			mavenblob.WithRetryPolicy(retryattr.Get(octx)),
 			mavenblob.WithCachingFileSystem(vfsattr.Get(octx)))
 	}
 	return accspeccpi.AccessMethodForImplementation(accspeccpi.NewDefaultMethodImpl(cv, a, "", a.MimeType(), factory), nil)
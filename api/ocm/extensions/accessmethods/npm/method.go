@@ -57,6 +57,10 @@ func (a *AccessSpec) GlobalAccessSpec(_ accspeccpi.Context) accspeccpi.AccessSpe
 	return a
 }
 
+func (a *AccessSpec) IsContentCacheable() bool {
+	return true
+}
+
 func (a *AccessSpec) GetReferenceHint(_ accspeccpi.ComponentVersionAccess) string {
 	return a.Package + ":" + a.Version
 }
@@ -281,6 +281,22 @@ func (m *accessMethod) GetArtifact() (oci.ArtifactAccess, *oci.RefSpec, error) {
 	return art, m.ref, err
 }
 
+// GetRepository provides the OCI repository and the reference of the
+// accessed artifact. The repository must be closed by the caller.
+func (m *accessMethod) GetRepository() (oci.Repository, *oci.RefSpec, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.ref == nil {
+		return nil, nil, errors.ErrClosed("access method")
+	}
+	repo, err := m.repo.Dup()
+	if err != nil {
+		return nil, nil, err
+	}
+	return repo, m.ref, nil
+}
+
 func (m *accessMethod) getArtifact() error {
 	if m.art == nil && m.err == nil && m.ref != nil {
 		art, err := m.repo.LookupArtifact(m.ref.Repository, m.ref.Version())
@@ -0,0 +1,143 @@
+package wget_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/vfs/pkg/osfs"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/helper/builder"
+	"ocm.software/ocm/api/oci/extensions/attrs/cacheattr"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/extensions/accessmethods/wget"
+	"ocm.software/ocm/api/ocm/extensions/attrs/cacheaccessattr"
+	"ocm.software/ocm/api/ocm/extensions/digester/digesters/blob"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+	"ocm.software/ocm/api/utils/mime"
+)
+
+func digest(content string) string {
+	d := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(d[:])
+}
+
+var _ = Describe("content cache", func() {
+	var env *builder.Builder
+	var server *httptest.Server
+	var requests atomic.Int64
+	var content atomic.Value
+	var tempfs vfs.FileSystem
+
+	BeforeEach(func() {
+		content.Store("some content")
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Header().Set("Content-Type", mime.MIME_TEXT)
+			fmt.Fprint(w, content.Load())
+		}))
+		tempfs = Must(osfs.NewTempFileSystem())
+		env = builder.NewBuilder()
+		env.OCMCommonTransport("/ctf", accessio.FormatDirectory, func() {
+			env.Component("acme.org/test", func() {
+				env.Version("1.0.0", func() {
+					env.Provider("acme.org")
+					env.Resource("content", "1.0.0", "PlainText", metav1.ExternalRelation, func() {
+						env.Access(wget.New(server.URL + "/content"))
+						env.Digest(digest("some content"), "SHA-256", blob.GenericBlobDigestV1)
+					})
+				})
+			})
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+		vfs.Cleanup(tempfs)
+		env.Cleanup()
+	})
+
+	Context("with cache", func() {
+		var cache accessio.BlobCache
+		var read func() string
+
+		BeforeEach(func() {
+			cache = Must(accessio.NewManagedBlobCache("cache", nil, tempfs))
+			MustBeSuccessful(cacheattr.Set(env.OCMContext(), cache))
+
+			repo := Must(ctf.Open(env, accessobj.ACC_READONLY, "/ctf", 0, env))
+			DeferCleanup(func() { Close(repo, "repo") })
+			cv := Must(repo.LookupComponentVersion("acme.org/test", "1.0.0"))
+			DeferCleanup(func() { Close(cv, "cv") })
+
+			read = func() string {
+				r := Must(cv.GetResourceByIndex(0))
+				m := Must(r.AccessMethod())
+				defer Close(m, "method")
+				return string(Must(m.Get()))
+			}
+		})
+
+		AfterEach(func() {
+			MustBeSuccessful(cache.Unref())
+		})
+
+		It("does not cache wget content by default", func() {
+			base := requests.Load()
+			Expect(read()).To(Equal("some content"))
+			Expect(read()).To(Equal("some content"))
+			Expect(requests.Load()).To(Equal(base + 2))
+		})
+
+		It("serves content from cache", func() {
+			MustBeSuccessful(cacheaccessattr.Set(env.OCMContext(), wget.Type))
+
+			base := requests.Load()
+			Expect(read()).To(Equal("some content"))
+			Expect(requests.Load()).To(Equal(base + 1))
+			Expect(read()).To(Equal("some content"))
+			Expect(requests.Load()).To(Equal(base + 1))
+
+			stats := Must(cache.(accessio.StatisticsCache).Statistics())
+			Expect(stats.Hits).To(Equal(int64(2)))
+			Expect(stats.Misses).To(Equal(int64(1)))
+			Expect(stats.Entries).To(Equal(1))
+		})
+
+		It("does not serve content not matching the resource digest", func() {
+			MustBeSuccessful(cacheaccessattr.Set(env.OCMContext(), wget.Type))
+			content.Store("modified content")
+
+			base := requests.Load()
+			Expect(read()).To(Equal("modified content"))
+			Expect(read()).To(Equal("modified content"))
+			Expect(requests.Load()).To(Equal(base + 2))
+		})
+	})
+
+	It("accesses content without cache", func() {
+		repo := Must(ctf.Open(env, accessobj.ACC_READONLY, "/ctf", 0, env))
+		defer Close(repo, "repo")
+		cv := Must(repo.LookupComponentVersion("acme.org/test", "1.0.0"))
+		defer Close(cv, "cv")
+
+		base := requests.Load()
+		for i := 0; i < 2; i++ {
+			r := Must(cv.GetResourceByIndex(0))
+			m := Must(r.AccessMethod())
+			Expect(string(Must(m.Get()))).To(Equal("some content"))
+			MustBeSuccessful(m.Close())
+		}
+		Expect(requests.Load()).To(Equal(base + 2))
+	})
+})
@@ -8,6 +8,7 @@ import (
 	"github.com/mandelsoft/goutils/optionutils"
 
 	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/datacontext/attrs/retryattr"
 	"ocm.software/ocm/api/ocm/cpi/accspeccpi"
 	"ocm.software/ocm/api/tech/wget/identity"
 	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
@@ -145,7 +146,8 @@ func (m *accessMethod) getBlob() (blobaccess.BlobAccess, error) {
 		wget.WithHeader(m.spec.Header),
 		wget.WithVerb(m.spec.Verb),
 		wget.WithBody(m.spec.Body),
-		wget.WithNoRedirect(m.spec.NoRedirect))
+		wget.WithNoRedirect(m.spec.NoRedirect),
+		wget.WithRetryPolicy(retryattr.Get(m.comp.GetContext())))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,90 @@
+package wget_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"ocm.software/ocm/api/datacontext/attrs/retryattr"
+	"ocm.software/ocm/api/helper/builder"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/extensions/accessmethods/wget"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+	"ocm.software/ocm/api/utils/mime"
+	"ocm.software/ocm/api/utils/retry"
+)
+
+var _ = Describe("retry", func() {
+	var env *builder.Builder
+	var server *httptest.Server
+	var requests atomic.Int64
+	var failures atomic.Int64
+
+	BeforeEach(func() {
+		failures.Store(0)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			if failures.Add(-1) >= 0 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.Header().Set("Content-Type", mime.MIME_TEXT)
+			fmt.Fprint(w, "some content")
+		}))
+		env = builder.NewBuilder()
+		env.OCMCommonTransport("/ctf", accessio.FormatDirectory, func() {
+			env.Component("acme.org/test", func() {
+				env.Version("1.0.0", func() {
+					env.Provider("acme.org")
+					env.Resource("content", "1.0.0", "PlainText", metav1.ExternalRelation, func() {
+						env.Access(wget.New(server.URL + "/content"))
+					})
+				})
+			})
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+		env.Cleanup()
+	})
+
+	get := func() ([]byte, error) {
+		repo := Must(ctf.Open(env, accessobj.ACC_READONLY, "/ctf", 0, env))
+		defer Close(repo, "repo")
+		cv := Must(repo.LookupComponentVersion("acme.org/test", "1.0.0"))
+		defer Close(cv, "cv")
+		r := Must(cv.GetResourceByIndex(0))
+		m := Must(r.AccessMethod())
+		defer Close(m, "method")
+		// the builder already accessed the content to calculate the digest,
+		// so failures are started now.
+		requests.Store(0)
+		failures.Store(2)
+		return m.Get()
+	}
+
+	It("retries transient server errors", func() {
+		MustBeSuccessful(retryattr.Set(env.OCMContext(), &retry.Policy{
+			MaxAttempts:    3,
+			InitialBackoff: retry.Duration(time.Millisecond),
+		}))
+		Expect(string(Must(get()))).To(Equal("some content"))
+		Expect(requests.Load()).To(Equal(int64(3)))
+	})
+
+	It("does not retry if retries are disabled", func() {
+		MustBeSuccessful(retryattr.Set(env.OCMContext(), retry.NoRetry()))
+		data, _ := get()
+		Expect(string(data)).NotTo(Equal("some content"))
+		Expect(requests.Load()).To(Equal(int64(1)))
+	})
+})
@@ -0,0 +1,69 @@
+package cacheaccessattr
+
+import (
+	"fmt"
+	"slices"
+
+	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+const (
+	ATTR_KEY   = "ocm.software/ocm/cacheaccesstypes"
+	ATTR_SHORT = "cacheaccesstypes"
+)
+
+func init() {
+	datacontext.RegisterAttributeType(ATTR_KEY, AttributeType{}, ATTR_SHORT)
+}
+
+type AttributeType struct{}
+
+func (a AttributeType) Name() string {
+	return ATTR_KEY
+}
+
+func (a AttributeType) Description() string {
+	return `
+*[]string*
+Additional access method types (for example <code>wget</code>), whose content
+is kept in the content cache configured with attribute <code>cache</code>.
+By default, only the content of access methods addressing versioned artifacts
+(<code>helm</code>, <code>maven</code> and <code>npm</code>) is cached.
+Content is only cached for resources with a digest, and it is always
+verified against this digest.
+`
+}
+
+func (a AttributeType) Encode(v interface{}, marshaller runtime.Marshaler) ([]byte, error) {
+	if _, ok := v.([]string); !ok {
+		return nil, fmt.Errorf("string list required")
+	}
+	return marshaller.Marshal(v)
+}
+
+func (a AttributeType) Decode(data []byte, unmarshaller runtime.Unmarshaler) (interface{}, error) {
+	var value []string
+	err := unmarshaller.Unmarshal(data, &value)
+	return value, err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func Get(ctx datacontext.Context) []string {
+	a := ctx.GetAttributes().GetAttribute(ATTR_KEY)
+	if a == nil {
+		return nil
+	}
+	return a.([]string)
+}
+
+// IsCached checks whether the content of the given access method
+// type is configured to be cached.
+func IsCached(ctx datacontext.Context, kind string) bool {
+	return slices.Contains(Get(ctx), kind)
+}
+
+func Set(ctx datacontext.Context, types ...string) error {
+	return ctx.GetAttributes().SetAttribute(ATTR_KEY, types)
+}
@@ -0,0 +1,37 @@
+package cacheaccessattr_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"ocm.software/ocm/api/config"
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/oci"
+	"ocm.software/ocm/api/ocm"
+	me "ocm.software/ocm/api/ocm/extensions/attrs/cacheaccessattr"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+var _ = Describe("attribute", func() {
+	var ctx ocm.Context
+	var cfgctx config.Context
+
+	BeforeEach(func() {
+		cfgctx = config.WithSharedAttributes(datacontext.New(nil)).New()
+		credctx := credentials.WithConfigs(cfgctx).New()
+		ocictx := oci.WithCredentials(credctx).New()
+		ctx = ocm.WithOCIRepositories(ocictx).New()
+	})
+
+	It("local setting", func() {
+		Expect(me.Get(ctx)).To(BeNil())
+		Expect(me.Set(ctx, "wget")).To(Succeed())
+		Expect(me.IsCached(ctx, "wget")).To(BeTrue())
+		Expect(me.IsCached(ctx, "npm")).To(BeFalse())
+	})
+
+	It("parses string list", func() {
+		Expect(me.AttributeType{}.Decode([]byte(`["wget"]`), runtime.DefaultJSONEncoding)).To(Equal([]string{"wget"}))
+	})
+})
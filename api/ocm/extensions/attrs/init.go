@@ -1,6 +1,7 @@
 package attrs
 
 import (
+	_ "ocm.software/ocm/api/ocm/extensions/attrs/cacheaccessattr"
 	_ "ocm.software/ocm/api/ocm/extensions/attrs/compatattr"
 	_ "ocm.software/ocm/api/ocm/extensions/attrs/hashattr"
 	_ "ocm.software/ocm/api/ocm/extensions/attrs/keepblobattr"
@@ -77,6 +77,7 @@ type Config struct {
 	Issuers                     map[string]Issuer  `json:"issuers,omitempty"`
 	RootCertificates            []KeySpec          `json:"rootCertificates,omitempty"`
 	TSAUrl                      string             `json:"tsaURL,omitempty"`
+	TSARootCertificates         []KeySpec          `json:"tsaRootCertificates,omitempty"`
 }
 
 type RawData []byte
@@ -174,6 +175,10 @@ func (a *Config) AddRootCertificateFile(name string, fss ...vfs.FileSystem) {
 	a.RootCertificates = append(a.RootCertificates, KeySpec{Path: name, FileSystem: utils.Optional(fss...)})
 }
 
+func (a *Config) AddTSARootCertificateFile(name string, fss ...vfs.FileSystem) {
+	a.TSARootCertificates = append(a.TSARootCertificates, KeySpec{Path: name, FileSystem: utils.Optional(fss...)})
+}
+
 func (a *Config) addKeyData(set *map[string]KeySpec, name string, data []byte) {
 	if *set == nil {
 		*set = map[string]KeySpec{}
@@ -250,6 +255,20 @@ func (a *Config) ApplyToRegistry(registry signing.Registry) error {
 	if a.TSAUrl != "" {
 		registry.SetTSAUrl(a.TSAUrl)
 	}
+	if len(a.TSARootCertificates) > 0 {
+		pool := registry.TSARootCerts()
+		for i, k := range a.TSARootCertificates {
+			cert, err := k.Get()
+			if err != nil {
+				return errors.Wrapf(err, "cannot get TSA root certificate %d", i)
+			}
+			pool, err = signutils.AddCertificateToPool(pool, cert)
+			if err != nil {
+				return errors.Wrapf(err, "invalid TSA root certificate %d", i)
+			}
+		}
+		registry.SetTSARootCerts(pool)
+	}
 	return nil
 }
 
@@ -272,6 +291,9 @@ public and private keys. A key value might be given by one of the fields:
        ...
     rootCertificates:
       - path: &lt;file path>
+    tsaURL: &lt;url of timestamp authority>
+    tsaRootCertificates:
+      - path: &lt;file path>
 
     issuers:
        &lt;name>:
@@ -293,4 +315,13 @@ signatures. They support the following fields:
 At least the given values must be present in the certificate
 to be accepted for a successful signature validation.
 
+The <code>tsaURL</code> describes the timestamp authority used to timestamp
+signatures. Besides the URL of an RFC 3161 server, it might be the name of
+a timestamp authority provided by a plugin or
+<code>file:&lt;path></code> describing a local timestamp authority given by
+a PEM file containing its private key and certificate chain.
+
+If <code>tsaRootCertificates</code> are given, timestamps of signatures are
+verified offline against these root certificates.
+
 `
@@ -5,6 +5,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 
 	"github.com/mandelsoft/filepath/pkg/filepath"
@@ -13,6 +14,7 @@ import (
 	mlog "github.com/mandelsoft/logging"
 	"github.com/mandelsoft/vfs/pkg/vfs"
 
+	"ocm.software/ocm/api/datacontext/attrs/retryattr"
 	"ocm.software/ocm/api/ocm/cpi"
 	access "ocm.software/ocm/api/ocm/extensions/accessmethods/maven"
 	resourcetypes "ocm.software/ocm/api/ocm/extensions/artifacttypes"
@@ -66,6 +68,7 @@ func (b *artifactHandler) StoreBlob(blob cpi.BlobAccess, resourceType string, hi
 
 	// setup logger
 	log := log.WithValues("repository", repo.String())
+	repo = repo.WithHTTPClient(&http.Client{Transport: retryattr.Transport(ctx.GetContext(), nil, log)})
 	// identify artifact
 	coords, err := maven.Parse(hint)
 	if err != nil {
@@ -4,9 +4,11 @@ import (
 	"github.com/mandelsoft/goutils/optionutils"
 	"github.com/mandelsoft/vfs/pkg/vfs"
 
+	"ocm.software/ocm/api/datacontext/attrs/progressattr"
 	"ocm.software/ocm/api/ocm/cpi"
 	"ocm.software/ocm/api/utils"
 	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/progress"
 )
 
 type Option = optionutils.Option[*Options]
@@ -67,3 +69,10 @@ func DownloadResource(ctx cpi.ContextProvider, r cpi.ResourceAccess, path string
 	_, tgt, err := For(ctx).Download(pr, r, path, fs)
 	return tgt, err
 }
+
+// Tracker provides the progress tracker used to report the download
+// of a resource. Download handlers should use it to report the
+// bytes written.
+func Tracker(racc cpi.ResourceAccess) *progress.Tracker {
+	return progressattr.For(racc.GetOCMContext(), progress.OPERATION_DOWNLOAD, progress.KIND_RESOURCE, "", racc.Meta().GetName())
+}
@@ -8,6 +8,7 @@ import (
 
 	"ocm.software/ocm/api/ocm/cpi"
 	"ocm.software/ocm/api/ocm/extensions/download"
+	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
 	common "ocm.software/ocm/api/utils/misc"
 )
 
@@ -30,6 +31,7 @@ func (_ Handler) Download(p common.Printer, racc cpi.ResourceAccess, path string
 	if err != nil {
 		return true, "", wrapErr(err, racc)
 	}
+	rd = download.Tracker(racc).ReadCloser(rd, blobaccess.BLOB_UNKNOWN_SIZE)
 	defer rd.Close()
 	if path == "" {
 		path = racc.Meta().GetName()
@@ -9,6 +9,7 @@ import (
 	"ocm.software/ocm/api/ocm/cpi"
 	resourcetypes "ocm.software/ocm/api/ocm/extensions/artifacttypes"
 	"ocm.software/ocm/api/ocm/extensions/download"
+	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
 	"ocm.software/ocm/api/utils/compression"
 	"ocm.software/ocm/api/utils/mime"
 	common "ocm.software/ocm/api/utils/misc"
@@ -37,6 +38,7 @@ func (_ Handler) Download(p common.Printer, racc cpi.ResourceAccess, path string
 	if err != nil {
 		return true, "", wrapErr(err, racc)
 	}
+	rd = download.Tracker(racc).ReadCloser(rd, blobaccess.BLOB_UNKNOWN_SIZE)
 	defer rd.Close()
 
 	r, _, err := compression.AutoDecompress(rd)
@@ -140,7 +140,11 @@ func (r *_registry) getHandlers(arttype, mediatype string) MultiHandler {
 	return list
 }
 
-func (r *_registry) Download(p common.Printer, racc cpi.ResourceAccess, path string, fs vfs.FileSystem) (bool, string, error) {
+func (r *_registry) Download(p common.Printer, racc cpi.ResourceAccess, path string, fs vfs.FileSystem) (ok bool, eff string, err error) {
+	tracker := Tracker(racc)
+	tracker.Started()
+	defer func() { tracker.Finished(err) }()
+
 	p = common.AssurePrinter(p)
 	art := racc.Meta().GetType()
 	m, err := racc.AccessMethod()
@@ -155,7 +159,11 @@ func (r *_registry) Download(p common.Printer, racc cpi.ResourceAccess, path str
 	return r.download(r.LookupHandler(ALL, ""), p, racc, path, fs)
 }
 
-func (r *_registry) DownloadAsBlob(p common.Printer, racc cpi.ResourceAccess, path string, fs vfs.FileSystem) (bool, string, error) {
+func (r *_registry) DownloadAsBlob(p common.Printer, racc cpi.ResourceAccess, path string, fs vfs.FileSystem) (ok bool, eff string, err error) {
+	tracker := Tracker(racc)
+	tracker.Started()
+	defer func() { tracker.Finished(err) }()
+
 	return r.download(r.LookupHandler(ALL, ""), p, racc, path, fs)
 }
 
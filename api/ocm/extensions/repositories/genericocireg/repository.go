@@ -21,6 +21,8 @@ import (
 	"ocm.software/ocm/api/ocm/cpi/repocpi"
 	"ocm.software/ocm/api/ocm/extensions/repositories/genericocireg/componentmapping"
 	"ocm.software/ocm/api/ocm/extensions/repositories/genericocireg/config"
+	"ocm.software/ocm/api/utils/accessio"
+	common "ocm.software/ocm/api/utils/misc"
 )
 
 type OCIBasedRepository interface {
@@ -59,6 +61,7 @@ var (
 	_ repocpi.RepositoryImpl               = (*RepositoryImpl)(nil)
 	_ credentials.ConsumerIdentityProvider = (*RepositoryImpl)(nil)
 	_ config.Configurable                  = (*RepositoryImpl)(nil)
+	_ repocpi.ComponentVersionDeleter      = (*RepositoryImpl)(nil)
 )
 
 // NewRepository creates a new OCM repository based on any OCI abstraction from
@@ -238,6 +241,34 @@ func (r *RepositoryImpl) ExistsComponentVersion(name string, version string) (bo
 	return false, nil
 }
 
+// DeleteComponentVersion deletes the OCI artifact used to store a component
+// version. Artifacts referenced by its resources and stored in other
+// namespaces are not affected.
+func (r *RepositoryImpl) DeleteComponentVersion(name string, version string) error {
+	if r.IsReadOnly() {
+		return accessio.ErrReadOnly
+	}
+	namespace, err := r.MapComponentNameToNamespace(name)
+	if err != nil {
+		return err
+	}
+	tag, err := toTag(version)
+	if err != nil {
+		return err
+	}
+	ns, err := r.ocirepo.LookupNamespace(namespace)
+	if err != nil {
+		return err
+	}
+	defer ns.Close()
+
+	err = oci.DeleteArtifact(ns, tag)
+	if errors.IsErrNotFound(err) {
+		return errors.ErrNotFound(cpi.KIND_COMPONENTVERSION, common.NewNameVersion(name, version).String())
+	}
+	return err
+}
+
 func (r *RepositoryImpl) LookupComponent(name string) (*repocpi.ComponentAccessInfo, error) {
 	return newComponentAccess(r, name, true)
 }
@@ -0,0 +1,225 @@
+package sbom
+
+import (
+	"crypto"
+	"fmt"
+	"time"
+
+	"ocm.software/ocm/api/ocm/extensions/artifacttypes"
+	"ocm.software/ocm/api/utils"
+	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/version"
+)
+
+const CYCLONEDX_SPEC_VERSION = "1.5"
+
+// The CycloneDX model covers the subset of the CycloneDX 1.5 JSON
+// specification required to describe component version graphs.
+
+type CycloneDXDocument struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	Version      int                   `json:"version"`
+	Metadata     CycloneDXMetadata     `json:"metadata"`
+	Components   []*CycloneDXComponent `json:"components,omitempty"`
+	Dependencies []CycloneDXDependency `json:"dependencies,omitempty"`
+}
+
+type CycloneDXMetadata struct {
+	Timestamp string              `json:"timestamp"`
+	Tools     CycloneDXTools      `json:"tools"`
+	Component *CycloneDXComponent `json:"component,omitempty"`
+}
+
+type CycloneDXTools struct {
+	Components []*CycloneDXComponent `json:"components"`
+}
+
+type CycloneDXComponent struct {
+	BOMRef     string                `json:"bom-ref,omitempty"`
+	Type       string                `json:"type"`
+	Supplier   *CycloneDXSupplier    `json:"supplier,omitempty"`
+	Group      string                `json:"group,omitempty"`
+	Name       string                `json:"name"`
+	Version    string                `json:"version,omitempty"`
+	Hashes     []CycloneDXHash       `json:"hashes,omitempty"`
+	Properties []CycloneDXProperty   `json:"properties,omitempty"`
+	Components []*CycloneDXComponent `json:"components,omitempty"`
+}
+
+type CycloneDXSupplier struct {
+	Name string `json:"name"`
+}
+
+type CycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type CycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// CycloneDX converts the SBOM into a CycloneDX document. Every component
+// version is represented by an application component nesting its resources
+// and sources. Component references are mapped to dependencies.
+func (s *SBOM) CycloneDX() *CycloneDXDocument {
+	doc := &CycloneDXDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: CYCLONEDX_SPEC_VERSION,
+		Version:     1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: s.Timestamp.Format(time.RFC3339),
+			Tools: CycloneDXTools{
+				Components: []*CycloneDXComponent{{
+					Type:    "application",
+					Group:   "ocm.software",
+					Name:    "ocm",
+					Version: version.Get().String(),
+				}},
+			},
+		},
+	}
+
+	known := map[common.NameVersion]bool{}
+	for _, c := range s.Components {
+		known[c.NameVersion()] = true
+	}
+	for i, c := range s.Components {
+		comp := cyclonedxComponent(c)
+		if i == 0 {
+			doc.Metadata.Component = comp
+		} else {
+			doc.Components = append(doc.Components, comp)
+		}
+
+		dep := CycloneDXDependency{Ref: comp.BOMRef}
+		for _, r := range c.References {
+			nv := r.NameVersion()
+			if !known[nv] {
+				// referenced component versions not included into the SBOM
+				// are described without content to keep the dependency
+				// graph consistent.
+				known[nv] = true
+				doc.Components = append(doc.Components, &CycloneDXComponent{
+					BOMRef:  cyclonedxRef(nv),
+					Type:    "application",
+					Name:    nv.GetName(),
+					Version: nv.GetVersion(),
+				})
+				doc.Dependencies = append(doc.Dependencies, CycloneDXDependency{Ref: cyclonedxRef(nv)})
+			}
+			dep.DependsOn = appendUnique(dep.DependsOn, cyclonedxRef(nv))
+		}
+		doc.Dependencies = append(doc.Dependencies, dep)
+	}
+	return doc
+}
+
+func cyclonedxRef(nv common.NameVersion) string {
+	return "ocm:" + nv.String()
+}
+
+func cyclonedxComponent(c *Component) *CycloneDXComponent {
+	ref := cyclonedxRef(c.NameVersion())
+	comp := &CycloneDXComponent{
+		BOMRef:  ref,
+		Type:    "application",
+		Name:    c.Name,
+		Version: c.Version,
+	}
+	if c.Provider != "" {
+		comp.Supplier = &CycloneDXSupplier{Name: c.Provider}
+	}
+	for _, l := range c.Labels {
+		comp.Properties = append(comp.Properties, CycloneDXProperty{Name: "ocm:label:" + l.Name, Value: string(l.Value)})
+	}
+	for _, r := range c.References {
+		comp.Properties = append(comp.Properties, CycloneDXProperty{
+			Name:  "ocm:reference:" + r.Identity.String(),
+			Value: r.NameVersion().String(),
+		})
+	}
+	for _, l := range [][]*Artifact{c.Resources, c.Sources} {
+		for _, a := range l {
+			comp.Components = append(comp.Components, cyclonedxArtifact(ref, a))
+		}
+	}
+	return comp
+}
+
+func cyclonedxArtifact(parent string, a *Artifact) *CycloneDXComponent {
+	comp := &CycloneDXComponent{
+		BOMRef:  fmt.Sprintf("%s/%s/%s", parent, a.Kind, a.Identity.String()),
+		Type:    cyclonedxType(a),
+		Name:    a.Name,
+		Version: a.Version,
+	}
+	if a.Digest != nil {
+		if alg := cyclonedxHashAlgorithm(a.Digest.HashAlgorithm); alg != "" {
+			comp.Hashes = append(comp.Hashes, CycloneDXHash{Algorithm: alg, Content: a.Digest.Value})
+		}
+	}
+	prop := func(name, value string) {
+		if value != "" {
+			comp.Properties = append(comp.Properties, CycloneDXProperty{Name: "ocm:" + name, Value: value})
+		}
+	}
+	prop("kind", a.Kind)
+	prop("type", a.Type)
+	prop("relation", a.Relation)
+	for _, k := range utils.StringMapKeys(a.Identity.ExtraIdentity()) {
+		prop("identity:"+k, a.Identity[k])
+	}
+	if a.Digest != nil {
+		prop("digest", a.Digest.String())
+	}
+	prop("accessType", a.AccessType)
+	prop("access", a.Access)
+	prop("accessSpec", string(a.AccessSpec))
+	for _, l := range a.Labels {
+		prop("label:"+l.Name, string(l.Value))
+	}
+	return comp
+}
+
+func cyclonedxType(a *Artifact) string {
+	if a.Kind == KIND_RESOURCE {
+		switch a.Type {
+		case artifacttypes.OCI_IMAGE, artifacttypes.OCI_ARTIFACT:
+			return "container"
+		case artifacttypes.EXECUTABLE:
+			return "application"
+		}
+	}
+	return "file"
+}
+
+func cyclonedxHashAlgorithm(alg string) string {
+	switch alg {
+	case crypto.SHA1.String():
+		return "SHA-1"
+	case crypto.SHA256.String():
+		return "SHA-256"
+	case crypto.SHA384.String():
+		return "SHA-384"
+	case crypto.SHA512.String():
+		return "SHA-512"
+	}
+	return ""
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, e := range list {
+		if e == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
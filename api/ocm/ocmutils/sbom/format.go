@@ -0,0 +1,35 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/mandelsoft/goutils/errors"
+)
+
+const (
+	FORMAT_CYCLONEDX = "cyclonedx"
+	FORMAT_SPDX      = "spdx"
+)
+
+var Formats = []string{FORMAT_CYCLONEDX, FORMAT_SPDX}
+
+// Write renders the SBOM as JSON document in the given format.
+func Write(w io.Writer, s *SBOM, format string) error {
+	var doc interface{}
+	switch format {
+	case "", FORMAT_CYCLONEDX:
+		doc = s.CycloneDX()
+	case FORMAT_SPDX:
+		doc = s.SPDX()
+	default:
+		return errors.ErrInvalid("sbom format", format)
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
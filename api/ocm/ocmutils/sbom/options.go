@@ -0,0 +1,76 @@
+package sbom
+
+import (
+	"time"
+
+	"github.com/mandelsoft/goutils/generics"
+	"github.com/mandelsoft/goutils/optionutils"
+
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/utils"
+)
+
+type Option = optionutils.Option[*Options]
+
+type Options struct {
+	// Recursive enables the inclusion of the component versions
+	// referenced by the root component version.
+	Recursive *bool
+	// Resolver is used as fallback to look up referenced component
+	// versions not found in the repository of the referencing one.
+	Resolver ocm.ComponentVersionResolver
+	// Timestamp is the creation time recorded in the documents.
+	// It defaults to the current time.
+	Timestamp *time.Time
+}
+
+var _ Option = (*Options)(nil)
+
+func (o *Options) ApplyTo(opts *Options) {
+	optionutils.ApplyOption(o.Recursive, &opts.Recursive)
+	optionutils.Transfer(&opts.Resolver, o.Resolver)
+	optionutils.ApplyOption(o.Timestamp, &opts.Timestamp)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type recursive bool
+
+// Recursive follows the component references and includes the referenced
+// component versions into the SBOM.
+func Recursive(b ...bool) Option {
+	return recursive(utils.OptionalDefaultedBool(true, b...))
+}
+
+func (r recursive) ApplyTo(t *Options) {
+	t.Recursive = generics.Pointer(bool(r))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type resolver struct {
+	ocm.ComponentVersionResolver
+}
+
+// Resolver sets a fallback resolver used to look up referenced
+// component versions.
+func Resolver(r ocm.ComponentVersionResolver) Option {
+	return resolver{r}
+}
+
+func (r resolver) ApplyTo(t *Options) {
+	t.Resolver = r.ComponentVersionResolver
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type timestamp time.Time
+
+// Timestamp sets the creation time recorded in the generated documents.
+func Timestamp(t time.Time) Option {
+	return timestamp(t)
+}
+
+func (t timestamp) ApplyTo(o *Options) {
+	o.Timestamp = generics.Pointer(time.Time(t))
+}
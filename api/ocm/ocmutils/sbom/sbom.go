@@ -0,0 +1,251 @@
+// Package sbom provides the generation of software bills of materials
+// (SBOM) for component versions. The component version graph is
+// described by a format-neutral model, which can be rendered as
+// CycloneDX or SPDX document.
+package sbom
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/optionutils"
+
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const (
+	KIND_RESOURCE = "resource"
+	KIND_SOURCE   = "source"
+)
+
+// SBOM describes the content of a component version graph.
+type SBOM struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Root is the component version the SBOM has been generated for.
+	Root common.NameVersion `json:"root"`
+	// Components lists the included component versions, the root
+	// component version first.
+	Components []*Component `json:"components"`
+}
+
+// Component describes a single component version.
+type Component struct {
+	Name       string        `json:"name"`
+	Version    string        `json:"version"`
+	Provider   string        `json:"provider,omitempty"`
+	Labels     metav1.Labels `json:"labels,omitempty"`
+	Resources  []*Artifact   `json:"resources,omitempty"`
+	Sources    []*Artifact   `json:"sources,omitempty"`
+	References []*Reference  `json:"references,omitempty"`
+}
+
+func (c *Component) NameVersion() common.NameVersion {
+	return common.NewNameVersion(c.Name, c.Version)
+}
+
+// Artifact describes a resource or source of a component version.
+type Artifact struct {
+	Kind     string             `json:"kind"`
+	Identity metav1.Identity    `json:"identity"`
+	Name     string             `json:"name"`
+	Version  string             `json:"version,omitempty"`
+	Type     string             `json:"type"`
+	Relation string             `json:"relation,omitempty"`
+	Digest   *metav1.DigestSpec `json:"digest,omitempty"`
+	// AccessType is the type of the access specification.
+	AccessType string `json:"accessType,omitempty"`
+	// Access is a human-readable description of the access location.
+	Access string `json:"access,omitempty"`
+	// AccessSpec is the serialized access specification.
+	AccessSpec json.RawMessage `json:"accessSpec,omitempty"`
+	Labels     metav1.Labels   `json:"labels,omitempty"`
+}
+
+// Reference describes a reference to another component version.
+type Reference struct {
+	Identity      metav1.Identity    `json:"identity"`
+	Name          string             `json:"name"`
+	ComponentName string             `json:"componentName"`
+	Version       string             `json:"version"`
+	Digest        *metav1.DigestSpec `json:"digest,omitempty"`
+	Labels        metav1.Labels      `json:"labels,omitempty"`
+}
+
+func (r *Reference) NameVersion() common.NameVersion {
+	return common.NewNameVersion(r.ComponentName, r.Version)
+}
+
+// Get provides the included component version with the given
+// name and version.
+func (s *SBOM) Get(nv common.NameVersion) *Component {
+	for _, c := range s.Components {
+		if c.NameVersion() == nv {
+			return c
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Generate creates an SBOM for the given component version.
+// By default, only the given component version is described. With option
+// Recursive the transitive closure of referenced component versions is
+// included, also. Referenced component versions are looked up in the
+// repository of the referencing component version, first, and then
+// using the optional resolver.
+func Generate(cv ocm.ComponentVersionAccess, opts ...Option) (*SBOM, error) {
+	eff := optionutils.EvalOptions(opts...)
+
+	s := &SBOM{
+		Root: common.VersionedElementKey(cv),
+	}
+	if eff.Timestamp != nil {
+		s.Timestamp = eff.Timestamp.UTC()
+	} else {
+		s.Timestamp = time.Now().UTC()
+	}
+
+	g := &generator{
+		opts:    eff,
+		sbom:    s,
+		visited: map[common.NameVersion]bool{},
+	}
+	err := g.handle(cv, common.History{})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+type generator struct {
+	opts    *Options
+	sbom    *SBOM
+	visited map[common.NameVersion]bool
+}
+
+func (g *generator) handle(cv ocm.ComponentVersionAccess, h common.History) error {
+	nv := common.VersionedElementKey(cv)
+	if err := h.Add(ocm.KIND_COMPONENTVERSION, nv); err != nil {
+		return err
+	}
+	if g.visited[nv] {
+		return nil
+	}
+	g.visited[nv] = true
+
+	c, err := describe(cv)
+	if err != nil {
+		return errors.Wrapf(err, "%s", h)
+	}
+	g.sbom.Components = append(g.sbom.Components, c)
+
+	if !optionutils.AsBool(g.opts.Recursive) {
+		return nil
+	}
+	for _, r := range c.References {
+		if g.visited[r.NameVersion()] {
+			continue
+		}
+		ref, err := g.lookup(cv, r)
+		if err != nil {
+			return errors.Wrapf(err, "%s", h)
+		}
+		err = g.handle(ref, h)
+		ref.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) lookup(cv ocm.ComponentVersionAccess, r *Reference) (ocm.ComponentVersionAccess, error) {
+	ref, err := cv.Repository().LookupComponentVersion(r.ComponentName, r.Version)
+	if err == nil {
+		return ref, nil
+	}
+	if !errors.IsErrNotFound(err) || g.opts.Resolver == nil {
+		return nil, err
+	}
+	ref, err = g.opts.Resolver.LookupComponentVersion(r.ComponentName, r.Version)
+	if err != nil {
+		return nil, err
+	}
+	if ref == nil {
+		return nil, errors.ErrNotFound(ocm.KIND_COMPONENTVERSION, r.NameVersion().String())
+	}
+	return ref, nil
+}
+
+func describe(cv ocm.ComponentVersionAccess) (*Component, error) {
+	cd := cv.GetDescriptor()
+	c := &Component{
+		Name:     cd.Name,
+		Version:  cd.Version,
+		Provider: string(cd.Provider.Name),
+		Labels:   cd.Labels.Copy(),
+	}
+
+	for i := range cd.Resources {
+		r := &cd.Resources[i]
+		a, err := artifact(cv, KIND_RESOURCE, &r.ElementMeta, r.GetIdentity(cd.Resources), r.Type, r.Access)
+		if err != nil {
+			return nil, err
+		}
+		a.Relation = string(r.Relation)
+		a.Digest = r.Digest.Copy()
+		c.Resources = append(c.Resources, a)
+	}
+	for i := range cd.Sources {
+		s := &cd.Sources[i]
+		a, err := artifact(cv, KIND_SOURCE, &s.ElementMeta, s.GetIdentity(cd.Sources), s.Type, s.Access)
+		if err != nil {
+			return nil, err
+		}
+		c.Sources = append(c.Sources, a)
+	}
+	for i := range cd.References {
+		r := &cd.References[i]
+		c.References = append(c.References, &Reference{
+			Identity:      r.GetIdentity(cd.References),
+			Name:          r.Name,
+			ComponentName: r.ComponentName,
+			Version:       r.Version,
+			Digest:        r.Digest.Copy(),
+			Labels:        r.Labels.Copy(),
+		})
+	}
+	return c, nil
+}
+
+func artifact(cv ocm.ComponentVersionAccess, kind string, meta *compdesc.ElementMeta, id metav1.Identity, typ string, acc compdesc.AccessSpec) (*Artifact, error) {
+	a := &Artifact{
+		Kind:     kind,
+		Identity: id,
+		Name:     meta.Name,
+		Version:  meta.Version,
+		Type:     typ,
+		Labels:   meta.Labels.Copy(),
+	}
+	if acc == nil {
+		return a, nil
+	}
+	a.AccessType = acc.GetType()
+	data, err := json.Marshal(acc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s", kind, id)
+	}
+	a.AccessSpec = data
+
+	spec, err := cv.GetContext().AccessSpecForSpec(acc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s", kind, id)
+	}
+	a.Access = spec.Describe(cv.GetContext())
+	return a, nil
+}
@@ -0,0 +1,211 @@
+package sbom_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/helper/builder"
+	. "ocm.software/ocm/api/ocm/testhelper"
+
+	"ocm.software/ocm/api/ocm"
+	v1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/extensions/accessmethods/ociartifact"
+	resourcetypes "ocm.software/ocm/api/ocm/extensions/artifacttypes"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/ocm/ocmutils/sbom"
+	"ocm.software/ocm/api/utils/accessio"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const (
+	ARCH     = "/tmp/ctf"
+	ARCH2    = "/tmp/ctf2"
+	PROVIDER = "acme.org"
+	VERSION  = "v1"
+	COMP     = "acme.org/app"
+	COMP2    = "acme.org/lib"
+	COMP3    = "acme.org/base"
+)
+
+var _ = Describe("SBOM generation", func() {
+	var env *Builder
+	var repo ocm.Repository
+	var cv ocm.ComponentVersionAccess
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	BeforeEach(func() {
+		env = NewBuilder()
+
+		env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+			env.ComponentVersion(COMP, VERSION, func() {
+				env.Provider(PROVIDER)
+				env.Label("purpose", "test")
+				env.Resource("testdata", "", resourcetypes.PLAIN_TEXT, v1.LocalRelation, func() {
+					env.BlobStringData("text/plain", S_TESTDATA)
+					env.Label("license", "Apache-2.0")
+				})
+				env.Resource("image", VERSION, resourcetypes.OCI_IMAGE, v1.ExternalRelation, func() {
+					env.ExtraIdentity("platform", "linux/amd64")
+					env.ModificationOptions(ocm.SkipDigest())
+					env.Access(ociartifact.New("ghcr.io/acme/image:v1"))
+				})
+				env.Source("src", VERSION, resourcetypes.DIRECTORY_TREE, func() {
+					env.ModificationOptions(ocm.SkipDigest())
+					env.Access(ociartifact.New("ghcr.io/acme/src:v1"))
+				})
+				env.Reference("lib", COMP2, VERSION)
+				env.Reference("base", COMP3, VERSION)
+			})
+			env.ComponentVersion(COMP2, VERSION, func() {
+				env.Provider(PROVIDER)
+				env.Reference("base", COMP3, VERSION)
+			})
+			env.ComponentVersion(COMP3, VERSION, func() {
+				env.Provider(PROVIDER)
+			})
+		})
+
+		spec := Must(ctf.NewRepositorySpec(ctf.ACC_READONLY, ARCH, env))
+		repo = Must(env.OCMContext().RepositoryForSpec(spec))
+		cv = Must(repo.LookupComponentVersion(COMP, VERSION))
+	})
+
+	AfterEach(func() {
+		MustBeSuccessful(cv.Close())
+		MustBeSuccessful(repo.Close())
+		env.Cleanup()
+	})
+
+	It("describes a single component version", func() {
+		s := Must(sbom.Generate(cv, sbom.Timestamp(ts)))
+
+		Expect(s.Timestamp).To(Equal(ts))
+		Expect(s.Root).To(Equal(common.NewNameVersion(COMP, VERSION)))
+		Expect(len(s.Components)).To(Equal(1))
+
+		c := s.Components[0]
+		Expect(c.Provider).To(Equal(PROVIDER))
+		Expect(len(c.Labels)).To(Equal(1))
+		Expect(len(c.Resources)).To(Equal(2))
+		Expect(len(c.Sources)).To(Equal(1))
+		Expect(len(c.References)).To(Equal(2))
+
+		r := c.Resources[0]
+		Expect(r.Kind).To(Equal(sbom.KIND_RESOURCE))
+		Expect(r.Digest).To(Equal(DS_TESTDATA))
+		Expect(r.AccessType).To(Equal("localBlob"))
+		Expect(r.Access).To(HavePrefix("Local blob sha256:" + D_TESTDATA))
+		data, ok := r.Labels.Get("license")
+		Expect(ok).To(BeTrue())
+		Expect(string(data)).To(Equal(`"Apache-2.0"`))
+
+		r = c.Resources[1]
+		Expect(r.Identity).To(Equal(v1.Identity{"name": "image", "platform": "linux/amd64"}))
+		Expect(r.Relation).To(Equal(string(v1.ExternalRelation)))
+		Expect(r.Access).To(Equal("OCI artifact ghcr.io/acme/image:v1"))
+
+		Expect(c.Sources[0].Kind).To(Equal(sbom.KIND_SOURCE))
+		Expect(c.References[0].NameVersion()).To(Equal(common.NewNameVersion(COMP2, VERSION)))
+	})
+
+	It("follows references", func() {
+		s := Must(sbom.Generate(cv, sbom.Recursive(), sbom.Timestamp(ts)))
+
+		var names []string
+		for _, c := range s.Components {
+			names = append(names, c.NameVersion().String())
+		}
+		Expect(names).To(Equal([]string{COMP + ":" + VERSION, COMP2 + ":" + VERSION, COMP3 + ":" + VERSION}))
+	})
+
+	It("fails for missing references", func() {
+		MustBeSuccessful(cv.Close())
+		MustBeSuccessful(repo.Close())
+
+		env.OCMCommonTransport(ARCH2, accessio.FormatDirectory, func() {
+			env.ComponentVersion(COMP, VERSION, func() {
+				env.Reference("lib", COMP2, VERSION)
+			})
+		})
+		spec := Must(ctf.NewRepositorySpec(ctf.ACC_READONLY, ARCH2, env))
+		repo = Must(env.OCMContext().RepositoryForSpec(spec))
+		cv = Must(repo.LookupComponentVersion(COMP, VERSION))
+
+		ExpectError(sbom.Generate(cv, sbom.Recursive())).To(MatchError(ContainSubstring("component version \"" + COMP2 + ":" + VERSION + "\" not found")))
+	})
+
+	It("renders CycloneDX", func() {
+		s := Must(sbom.Generate(cv, sbom.Recursive(), sbom.Timestamp(ts)))
+		doc := s.CycloneDX()
+
+		Expect(doc.BOMFormat).To(Equal("CycloneDX"))
+		Expect(doc.Metadata.Timestamp).To(Equal("2024-01-02T03:04:05Z"))
+		root := doc.Metadata.Component
+		Expect(root.BOMRef).To(Equal("ocm:" + COMP + ":" + VERSION))
+		Expect(root.Supplier.Name).To(Equal(PROVIDER))
+		Expect(len(root.Components)).To(Equal(3))
+		Expect(root.Components[0].Hashes).To(Equal([]sbom.CycloneDXHash{{Algorithm: "SHA-256", Content: D_TESTDATA}}))
+		Expect(root.Components[1].Type).To(Equal("container"))
+		Expect(root.Components[1].Properties).To(ContainElement(sbom.CycloneDXProperty{Name: "ocm:identity:platform", Value: "linux/amd64"}))
+		Expect(root.Components[2].Properties).To(ContainElement(sbom.CycloneDXProperty{Name: "ocm:kind", Value: sbom.KIND_SOURCE}))
+		Expect(len(doc.Components)).To(Equal(2))
+		Expect(doc.Dependencies).To(Equal([]sbom.CycloneDXDependency{
+			{Ref: "ocm:" + COMP + ":" + VERSION, DependsOn: []string{"ocm:" + COMP2 + ":" + VERSION, "ocm:" + COMP3 + ":" + VERSION}},
+			{Ref: "ocm:" + COMP2 + ":" + VERSION, DependsOn: []string{"ocm:" + COMP3 + ":" + VERSION}},
+			{Ref: "ocm:" + COMP3 + ":" + VERSION},
+		}))
+	})
+
+	It("keeps dependency graph consistent without recursion", func() {
+		s := Must(sbom.Generate(cv, sbom.Timestamp(ts)))
+		doc := s.CycloneDX()
+
+		Expect(len(doc.Components)).To(Equal(2))
+		Expect(doc.Components[0].Components).To(BeNil())
+		Expect(len(doc.Dependencies)).To(Equal(3))
+	})
+
+	It("renders SPDX", func() {
+		s := Must(sbom.Generate(cv, sbom.Recursive(), sbom.Timestamp(ts)))
+		doc := s.SPDX()
+
+		Expect(doc.SPDXVersion).To(Equal("SPDX-2.3"))
+		Expect(doc.Name).To(Equal(COMP + ":" + VERSION))
+		Expect(len(doc.Packages)).To(Equal(6))
+
+		root := doc.Packages[0]
+		Expect(root.SPDXID).To(Equal("SPDXRef-ocm-acme.org-app-v1"))
+		Expect(root.Supplier).To(Equal("Organization: " + PROVIDER))
+
+		p := doc.Packages[1]
+		Expect(p.Checksums).To(Equal([]sbom.SPDXChecksum{{Algorithm: "SHA256", Value: D_TESTDATA}}))
+		Expect(p.PrimaryPackagePurpose).To(Equal("FILE"))
+		Expect(doc.Packages[2].PrimaryPackagePurpose).To(Equal("CONTAINER"))
+		Expect(doc.Packages[2].SourceInfo).To(Equal("OCI artifact ghcr.io/acme/image:v1"))
+		Expect(doc.Packages[3].PrimaryPackagePurpose).To(Equal("SOURCE"))
+
+		Expect(doc.Relationships).To(ContainElements(
+			sbom.SPDXRelationship{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: root.SPDXID},
+			sbom.SPDXRelationship{Element: root.SPDXID, Type: "CONTAINS", Related: p.SPDXID},
+			sbom.SPDXRelationship{Element: root.SPDXID, Type: "DEPENDS_ON", Related: "SPDXRef-ocm-acme.org-lib-v1"},
+			sbom.SPDXRelationship{Element: "SPDXRef-ocm-acme.org-lib-v1", Type: "DEPENDS_ON", Related: "SPDXRef-ocm-acme.org-base-v1"},
+		))
+	})
+
+	It("writes documents", func() {
+		s := Must(sbom.Generate(cv, sbom.Timestamp(ts)))
+
+		for _, f := range sbom.Formats {
+			var buf bytes.Buffer
+			MustBeSuccessful(sbom.Write(&buf, s, f))
+			var doc map[string]interface{}
+			MustBeSuccessful(json.Unmarshal(buf.Bytes(), &doc))
+		}
+		ExpectError(sbom.Write(&bytes.Buffer{}, s, "xml")).To(MatchError(`sbom format "xml" is invalid`))
+	})
+})
@@ -0,0 +1,270 @@
+package sbom
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"time"
+
+	"ocm.software/ocm/api/ocm/extensions/artifacttypes"
+	"ocm.software/ocm/api/utils"
+	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/version"
+)
+
+const (
+	SPDX_VERSION   = "SPDX-2.3"
+	SPDX_NAMESPACE = "https://ocm.software/spdx"
+
+	SPDX_NOASSERTION = "NOASSERTION"
+)
+
+// The SPDX model covers the subset of the SPDX 2.3 JSON
+// specification required to describe component version graphs.
+
+type SPDXDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+	Packages          []*SPDXPackage     `json:"packages"`
+	Relationships     []SPDXRelationship `json:"relationships"`
+}
+
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type SPDXPackage struct {
+	SPDXID                string           `json:"SPDXID"`
+	Name                  string           `json:"name"`
+	VersionInfo           string           `json:"versionInfo,omitempty"`
+	Supplier              string           `json:"supplier,omitempty"`
+	DownloadLocation      string           `json:"downloadLocation"`
+	FilesAnalyzed         bool             `json:"filesAnalyzed"`
+	Checksums             []SPDXChecksum   `json:"checksums,omitempty"`
+	LicenseConcluded      string           `json:"licenseConcluded"`
+	LicenseDeclared       string           `json:"licenseDeclared"`
+	CopyrightText         string           `json:"copyrightText"`
+	SourceInfo            string           `json:"sourceInfo,omitempty"`
+	PrimaryPackagePurpose string           `json:"primaryPackagePurpose,omitempty"`
+	Comment               string           `json:"comment,omitempty"`
+	Annotations           []SPDXAnnotation `json:"annotations,omitempty"`
+}
+
+type SPDXChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+type SPDXAnnotation struct {
+	Annotator      string `json:"annotator"`
+	AnnotationDate string `json:"annotationDate"`
+	AnnotationType string `json:"annotationType"`
+	Comment        string `json:"comment"`
+}
+
+type SPDXRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+// SPDX converts the SBOM into an SPDX document. Every component version,
+// resource and source is represented by a package. The component version
+// packages CONTAIN their resources and sources and DEPEND_ON the
+// referenced component versions. Labels and access information are kept
+// as annotations.
+func (s *SBOM) SPDX() *SPDXDocument {
+	created := s.Timestamp.Format(time.RFC3339)
+	tool := "Tool: ocm-" + version.Get().String()
+
+	ns := sha256.Sum256([]byte(s.Root.String() + "@" + created))
+	doc := &SPDXDocument{
+		SPDXVersion:       SPDX_VERSION,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              s.Root.String(),
+		DocumentNamespace: fmt.Sprintf("%s/%s-%s", SPDX_NAMESPACE, s.Root.String(), hex.EncodeToString(ns[:8])),
+		CreationInfo: SPDXCreationInfo{
+			Created:  created,
+			Creators: []string{tool},
+		},
+	}
+
+	g := &spdxGenerator{
+		doc:  doc,
+		ids:  map[string]string{},
+		used: map[string]bool{},
+		annotation: func(comment string) SPDXAnnotation {
+			return SPDXAnnotation{
+				Annotator:      tool,
+				AnnotationDate: created,
+				AnnotationType: "OTHER",
+				Comment:        comment,
+			}
+		},
+	}
+
+	for _, c := range s.Components {
+		g.component(c)
+	}
+	for i, c := range s.Components {
+		id := g.id(cyclonedxRef(c.NameVersion()))
+		if i == 0 {
+			g.relation(doc.SPDXID, "DESCRIBES", id)
+		}
+		for _, l := range [][]*Artifact{c.Resources, c.Sources} {
+			for _, a := range l {
+				g.relation(id, "CONTAINS", g.id(spdxArtifactKey(c, a)))
+			}
+		}
+		for _, r := range c.References {
+			nv := r.NameVersion()
+			if s.Get(nv) == nil {
+				g.stub(nv)
+			}
+			g.relation(id, "DEPENDS_ON", g.id(cyclonedxRef(nv)))
+		}
+	}
+	return doc
+}
+
+var spdxInvalid = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+type spdxGenerator struct {
+	doc        *SPDXDocument
+	ids        map[string]string
+	used       map[string]bool
+	annotation func(comment string) SPDXAnnotation
+}
+
+// id provides a unique SPDX identifier for an element key.
+// Characters not allowed in SPDX identifiers are replaced,
+// conflicts caused by this mapping are resolved by a counter suffix.
+func (g *spdxGenerator) id(key string) string {
+	if id, ok := g.ids[key]; ok {
+		return id
+	}
+	base := "SPDXRef-" + spdxInvalid.ReplaceAllString(key, "-")
+	id := base
+	for i := 2; g.used[id]; i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	g.ids[key] = id
+	g.used[id] = true
+	return id
+}
+
+func (g *spdxGenerator) relation(elem, typ, related string) {
+	for _, r := range g.doc.Relationships {
+		if r.Element == elem && r.Type == typ && r.Related == related {
+			return
+		}
+	}
+	g.doc.Relationships = append(g.doc.Relationships, SPDXRelationship{Element: elem, Type: typ, Related: related})
+}
+
+func (g *spdxGenerator) stub(nv common.NameVersion) {
+	key := cyclonedxRef(nv)
+	if _, ok := g.ids[key]; ok {
+		return
+	}
+	g.doc.Packages = append(g.doc.Packages, spdxPackage(g.id(key), nv.GetName(), nv.GetVersion(), "APPLICATION"))
+}
+
+func (g *spdxGenerator) component(c *Component) {
+	p := spdxPackage(g.id(cyclonedxRef(c.NameVersion())), c.Name, c.Version, "APPLICATION")
+	if c.Provider != "" {
+		p.Supplier = "Organization: " + c.Provider
+	}
+	for _, l := range c.Labels {
+		p.Annotations = append(p.Annotations, g.annotation(fmt.Sprintf("ocm:label:%s=%s", l.Name, string(l.Value))))
+	}
+	g.doc.Packages = append(g.doc.Packages, p)
+
+	for _, l := range [][]*Artifact{c.Resources, c.Sources} {
+		for _, a := range l {
+			g.artifact(c, a)
+		}
+	}
+}
+
+func (g *spdxGenerator) artifact(c *Component, a *Artifact) {
+	p := spdxPackage(g.id(spdxArtifactKey(c, a)), a.Name, a.Version, spdxPurpose(a))
+	if a.Digest != nil {
+		if alg := spdxHashAlgorithm(a.Digest.HashAlgorithm); alg != "" {
+			p.Checksums = append(p.Checksums, SPDXChecksum{Algorithm: alg, Value: a.Digest.Value})
+		}
+	}
+	p.SourceInfo = a.Access
+	p.Comment = fmt.Sprintf("ocm %s of type %s with identity %s", a.Kind, a.Type, a.Identity.String())
+
+	annotate := func(name, value string) {
+		if value != "" {
+			p.Annotations = append(p.Annotations, g.annotation(fmt.Sprintf("ocm:%s=%s", name, value)))
+		}
+	}
+	annotate("relation", a.Relation)
+	for _, k := range utils.StringMapKeys(a.Identity.ExtraIdentity()) {
+		annotate("identity:"+k, a.Identity[k])
+	}
+	if a.Digest != nil {
+		annotate("digest", a.Digest.String())
+	}
+	annotate("accessType", a.AccessType)
+	annotate("accessSpec", string(a.AccessSpec))
+	for _, l := range a.Labels {
+		annotate("label:"+l.Name, string(l.Value))
+	}
+	g.doc.Packages = append(g.doc.Packages, p)
+}
+
+func spdxArtifactKey(c *Component, a *Artifact) string {
+	return fmt.Sprintf("%s/%s/%s", cyclonedxRef(c.NameVersion()), a.Kind, a.Identity.String())
+}
+
+func spdxPackage(id, name, vers, purpose string) *SPDXPackage {
+	return &SPDXPackage{
+		SPDXID:                id,
+		Name:                  name,
+		VersionInfo:           vers,
+		DownloadLocation:      SPDX_NOASSERTION,
+		LicenseConcluded:      SPDX_NOASSERTION,
+		LicenseDeclared:       SPDX_NOASSERTION,
+		CopyrightText:         SPDX_NOASSERTION,
+		PrimaryPackagePurpose: purpose,
+	}
+}
+
+func spdxPurpose(a *Artifact) string {
+	if a.Kind == KIND_SOURCE {
+		return "SOURCE"
+	}
+	switch a.Type {
+	case artifacttypes.OCI_IMAGE, artifacttypes.OCI_ARTIFACT:
+		return "CONTAINER"
+	case artifacttypes.EXECUTABLE:
+		return "APPLICATION"
+	}
+	return "FILE"
+}
+
+func spdxHashAlgorithm(alg string) string {
+	switch alg {
+	case crypto.SHA1.String():
+		return "SHA1"
+	case crypto.SHA256.String():
+		return "SHA256"
+	case crypto.SHA384.String():
+		return "SHA384"
+	case crypto.SHA512.String():
+		return "SHA512"
+	}
+	return ""
+}
@@ -112,6 +112,19 @@ func (p *pluginImpl) GetValueMappingDescriptor(name string) *descriptor.ValueMer
 	return nil
 }
 
+func (p *pluginImpl) GetTimestampAuthorityDescriptor(name string) *descriptor.TimestampAuthorityDescriptor {
+	if !p.IsValid() {
+		return nil
+	}
+
+	for _, a := range p.descriptor.TimestampAuthorities {
+		if a.Name == name {
+			return &a
+		}
+	}
+	return nil
+}
+
 func (p *pluginImpl) GetLabelMergeSpecification(name, version string) *descriptor.LabelMergeSpecification {
 	if !p.IsValid() {
 		return nil
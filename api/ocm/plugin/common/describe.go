@@ -80,6 +80,11 @@ func DescribePluginDescriptorCapabilities(reg api.ActionTypeRegistry, d *descrip
 		out.Printf("Config Types for CLI Command Extensions:\n")
 		DescribeConfigTypes(d, out)
 	}
+	if len(d.TimestampAuthorities) > 0 {
+		out.Printf("\n")
+		out.Printf("Timestamp Authorities:\n")
+		DescribeTimestampAuthorities(d, out)
+	}
 }
 
 type MethodInfo struct {
@@ -285,6 +290,21 @@ func DescribeValueMergeHandlers(d *descriptor.Descriptor, out common.Printer) {
 	}
 }
 
+func DescribeTimestampAuthorities(d *descriptor.Descriptor, out common.Printer) {
+	authorities := map[string]descriptor.TimestampAuthorityDescriptor{}
+	for _, a := range d.TimestampAuthorities {
+		authorities[a.GetName()] = a
+	}
+
+	for _, n := range utils.StringMapKeys(authorities) {
+		a := authorities[n]
+		out.Printf("- Name: %s\n", n)
+		if a.Description != "" {
+			out.Printf("%s\n", utils.IndentLines(a.Description, "    "))
+		}
+	}
+}
+
 func DescribeLabelMergeSpecifications(d *descriptor.Descriptor, out common.Printer) {
 	handlers := map[string]descriptor.LabelMergeSpecification{}
 	for _, h := range d.LabelMergeSpecifications {
@@ -14,6 +14,8 @@ const (
 	KIND_ACTION       = action.KIND_ACTION
 	KIND_VALUESET     = "value set"
 	KIND_PURPOSE      = "purposet"
+
+	KIND_TIMESTAMP_AUTHORITY = "timestamp authority"
 )
 
 var REALM = ocmlog.DefineSubRealm("OCM plugin handling", "plugins")
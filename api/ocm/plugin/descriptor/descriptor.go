@@ -16,15 +16,16 @@ type Descriptor struct {
 	Long           string `json:"description"`
 	ForwardLogging bool   `json:"forwardLogging"`
 
-	Actions                  []ActionDescriptor                `json:"actions,omitempty"`
-	AccessMethods            []AccessMethodDescriptor          `json:"accessMethods,omitempty"`
-	Uploaders                List[UploaderDescriptor]          `json:"uploaders,omitempty"`
-	Downloaders              List[DownloaderDescriptor]        `json:"downloaders,omitempty"`
-	ValueMergeHandlers       List[ValueMergeHandlerDescriptor] `json:"valueMergeHandlers,omitempty"`
-	LabelMergeSpecifications List[LabelMergeSpecification]     `json:"labelMergeSpecifications,omitempty"`
-	ValueSets                List[ValueSetDescriptor]          `json:"valuesets,omitempty"`
-	Commands                 List[CommandDescriptor]           `json:"commands,omitempty"`
-	ConfigTypes              List[ConfigTypeDescriptor]        `json:"configTypes,omitempty"`
+	Actions                  []ActionDescriptor                 `json:"actions,omitempty"`
+	AccessMethods            []AccessMethodDescriptor           `json:"accessMethods,omitempty"`
+	Uploaders                List[UploaderDescriptor]           `json:"uploaders,omitempty"`
+	Downloaders              List[DownloaderDescriptor]         `json:"downloaders,omitempty"`
+	ValueMergeHandlers       List[ValueMergeHandlerDescriptor]  `json:"valueMergeHandlers,omitempty"`
+	LabelMergeSpecifications List[LabelMergeSpecification]      `json:"labelMergeSpecifications,omitempty"`
+	ValueSets                List[ValueSetDescriptor]           `json:"valuesets,omitempty"`
+	Commands                 List[CommandDescriptor]            `json:"commands,omitempty"`
+	ConfigTypes              List[ConfigTypeDescriptor]         `json:"configTypes,omitempty"`
+	TimestampAuthorities     List[TimestampAuthorityDescriptor] `json:"timestampAuthorities,omitempty"`
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -58,6 +59,9 @@ func (d *Descriptor) Capabilities() []string {
 	if len(d.ConfigTypes) > 0 {
 		caps = append(caps, "Config Types")
 	}
+	if len(d.TimestampAuthorities) > 0 {
+		caps = append(caps, "Timestamp Authorities")
+	}
 	return caps
 }
 
@@ -167,6 +171,21 @@ func (a ValueMergeHandlerDescriptor) GetDescription() string {
 
 ////////////////////////////////////////////////////////////////////////////////
 
+type TimestampAuthorityDescriptor struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+func (a TimestampAuthorityDescriptor) GetName() string {
+	return a.Name
+}
+
+func (a TimestampAuthorityDescriptor) GetDescription() string {
+	return a.Description
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
 type LabelMergeSpecification struct {
 	Name                               string `json:"name"`
 	Version                            string `json:"version,omitempty"`
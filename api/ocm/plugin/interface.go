@@ -28,6 +28,8 @@ type (
 	ValueSetDescriptor          = descriptor.ValueSetDescriptor
 	CommandDescriptor           = descriptor.CommandDescriptor
 
+	TimestampAuthorityDescriptor = descriptor.TimestampAuthorityDescriptor
+
 	AccessSpecInfo       = internal.AccessSpecInfo
 	UploadTargetSpecInfo = internal.UploadTargetSpecInfo
 )
@@ -8,7 +8,9 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/InfiniteLoopSpace/go_S-MIME/asn1"
 	"github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/goutils/finalizer"
 	mlog "github.com/mandelsoft/logging"
@@ -19,7 +21,9 @@ import (
 	"ocm.software/ocm/api/credentials/identity/hostpath"
 	"ocm.software/ocm/api/datacontext/attrs/clicfgattr"
 	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/extensions/attrs/signingattr"
 	"ocm.software/ocm/api/ocm/plugin/cache"
+	"ocm.software/ocm/api/ocm/plugin/descriptor"
 	"ocm.software/ocm/api/ocm/plugin/ppi"
 	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/accessmethod"
 	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/accessmethod/compose"
@@ -31,6 +35,8 @@ import (
 	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/download"
 	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/mergehandler"
 	merge "ocm.software/ocm/api/ocm/plugin/ppi/cmds/mergehandler/execute"
+	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/timestampauthority"
+	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/timestampauthority/timestamp"
 	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/upload"
 	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/upload/put"
 	uplval "ocm.software/ocm/api/ocm/plugin/ppi/cmds/upload/validate"
@@ -38,6 +44,7 @@ import (
 	vscompose "ocm.software/ocm/api/ocm/plugin/ppi/cmds/valueset/compose"
 	vsval "ocm.software/ocm/api/ocm/plugin/ppi/cmds/valueset/validate"
 	"ocm.software/ocm/api/ocm/valuemergehandler"
+	"ocm.software/ocm/api/tech/signing/tsa"
 	"ocm.software/ocm/api/utils/cobrautils/flagsets"
 	"ocm.software/ocm/api/utils/cobrautils/logopts/logging"
 	"ocm.software/ocm/api/utils/runtime"
@@ -207,6 +214,32 @@ func (p *pluginImpl) MergeValue(specification *valuemergehandler.Specification,
 	return r.Modified, &r.Value, nil
 }
 
+func (p *pluginImpl) Timestamp(name string, mi *tsa.MessageImprint) (*tsa.TimeStamp, time.Time, error) {
+	desc := p.GetTimestampAuthorityDescriptor(name)
+	if desc == nil {
+		return nil, time.Time{}, errors.ErrNotSupported(descriptor.KIND_TIMESTAMP_AUTHORITY, name, KIND_PLUGIN, p.Name())
+	}
+	input, err := asn1.Marshal(*mi)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var buf bytes.Buffer
+	_, err = p.Exec(bytes.NewReader(input), &buf, timestampauthority.Name, timestamp.Name, name)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrapf(err, "plugin %s", p.Name())
+	}
+	ts, err := tsa.FromPem(buf.Bytes())
+	if err != nil {
+		return nil, time.Time{}, errors.Wrapf(err, "plugin %s", p.Name())
+	}
+	t, err := tsa.Verify(mi, ts, true, signingattr.Get(p.ctx).TSARootCerts())
+	if err != nil {
+		return nil, time.Time{}, errors.Wrapf(err, "cannot verify timestamp from plugin %s", p.Name())
+	}
+	return ts, *t, nil
+}
+
 func (p *pluginImpl) Action(spec ppi.ActionSpec, creds json.RawMessage) (ppi.ActionResult, error) {
 	desc := p.GetActionDescriptor(spec.GetKind())
 	if desc == nil {
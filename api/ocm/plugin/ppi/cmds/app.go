@@ -16,6 +16,7 @@ import (
 	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/download"
 	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/info"
 	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/mergehandler"
+	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/timestampauthority"
 	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/topics/descriptor"
 	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/upload"
 	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/valueset"
@@ -66,6 +67,7 @@ func NewPluginCommand(p ppi.Plugin) *PluginCommand {
 	cmd.AddCommand(download.New(p))
 	cmd.AddCommand(valueset.New(p))
 	cmd.AddCommand(command.New(p))
+	cmd.AddCommand(timestampauthority.New(p))
 
 	cmd.InitDefaultHelpCmd()
 	help := cobrautils.GetHelpCommand(cmd)
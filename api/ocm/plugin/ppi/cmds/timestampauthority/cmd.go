@@ -0,0 +1,21 @@
+package timestampauthority
+
+import (
+	"github.com/spf13/cobra"
+
+	"ocm.software/ocm/api/ocm/plugin/ppi"
+	"ocm.software/ocm/api/ocm/plugin/ppi/cmds/timestampauthority/timestamp"
+)
+
+const Name = "timestampauthority"
+
+func New(p ppi.Plugin) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   Name,
+		Short: "timestamp authority operations",
+		Long:  `This command group provides all commands used to implement timestamp authorities.`,
+	}
+
+	cmd.AddCommand(timestamp.New(p))
+	return cmd
+}
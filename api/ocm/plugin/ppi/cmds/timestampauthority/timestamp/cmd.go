@@ -0,0 +1,93 @@
+package timestamp
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/InfiniteLoopSpace/go_S-MIME/asn1"
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"ocm.software/ocm/api/ocm/plugin/descriptor"
+	"ocm.software/ocm/api/ocm/plugin/ppi"
+	"ocm.software/ocm/api/tech/signing/tsa"
+)
+
+const (
+	Name = "timestamp"
+)
+
+func New(p ppi.Plugin) *cobra.Command {
+	opts := Options{}
+
+	cmd := &cobra.Command{
+		Use:   Name + " <name>",
+		Short: "issue a timestamp",
+		Long: `
+This command issues an RFC 3161 timestamp with the timestamp authority given
+by its name. The message imprint to timestamp is taken from *stdin* as DER
+encoded ASN.1 structure.
+
+The command has to provide the signed timestamp token as PEM block of type
+<code>` + tsa.PRM_BLOCK_TYPE + `</code> on *stdout*.
+`,
+		Args: cobra.ExactArgs(1),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Complete(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return Command(p, cmd, &opts)
+		},
+	}
+	opts.AddFlags(cmd.Flags())
+	return cmd
+}
+
+type Options struct {
+	Name string
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+}
+
+func (o *Options) Complete(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("timestamp authority name missing")
+	}
+	o.Name = args[0]
+	return nil
+}
+
+func Command(p ppi.Plugin, cmd *cobra.Command, opts *Options) error {
+	a := p.GetTimestampAuthority(opts.Name)
+	if a == nil {
+		return errors.ErrUnknown(descriptor.KIND_TIMESTAMP_AUTHORITY, opts.Name)
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	var mi tsa.MessageImprint
+	rest, err := asn1.Unmarshal(data, &mi)
+	if err != nil {
+		return errors.Wrapf(err, "invalid message imprint")
+	}
+	if len(rest) > 0 {
+		return errors.Newf("invalid message imprint: trailing data")
+	}
+
+	ts, err := a.Timestamp(p, &mi)
+	if err != nil {
+		return err
+	}
+	data, err = tsa.ToPem(ts)
+	if err != nil {
+		return err
+	}
+	cmd.Printf("%s", string(data))
+	return nil
+}
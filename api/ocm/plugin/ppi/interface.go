@@ -13,6 +13,7 @@ import (
 	"ocm.software/ocm/api/ocm/extensions/accessmethods/options"
 	"ocm.software/ocm/api/ocm/plugin/descriptor"
 	"ocm.software/ocm/api/ocm/plugin/internal"
+	"ocm.software/ocm/api/tech/signing/tsa"
 	"ocm.software/ocm/api/utils/runtime"
 )
 
@@ -65,6 +66,9 @@ type Plugin interface {
 	RegisterValueMergeHandler(h ValueMergeHandler) error
 	GetValueMergeHandler(name string) ValueMergeHandler
 
+	RegisterTimestampAuthority(a TimestampAuthority) error
+	GetTimestampAuthority(name string) TimestampAuthority
+
 	RegisterValueSet(h ValueSet) error
 	DecodeValueSet(purpose string, data []byte) (runtime.TypedObject, error)
 	GetValueSet(purpose, name, version string) ValueSet
@@ -162,6 +166,15 @@ type ValueMergeHandler interface {
 	Execute(p Plugin, local Value, inbound Value, config json.RawMessage) (result ValueMergeResult, err error)
 }
 
+// TimestampAuthority is a timestamp authority issuing RFC 3161 timestamps
+// provided by a plugin.
+type TimestampAuthority interface {
+	Name() string
+	Description() string
+
+	Timestamp(p Plugin, mi *tsa.MessageImprint) (*tsa.TimeStamp, error)
+}
+
 type ValueSet interface {
 	runtime.TypedObjectDecoder[AccessSpec]
 
@@ -43,6 +43,8 @@ type plugin struct {
 	mergehandlers map[string]ValueMergeHandler
 	mergespecs    map[string]*descriptor.LabelMergeSpecification
 
+	authorities map[string]TimestampAuthority
+
 	valuesets map[string]map[string]ValueSet
 	setScheme map[string]runtime.Scheme[runtime.TypedObject, runtime.TypedObjectDecoder[runtime.TypedObject]]
 
@@ -70,6 +72,8 @@ func NewPlugin(name string, version string) Plugin {
 		mergehandlers: map[string]ValueMergeHandler{},
 		mergespecs:    map[string]*descriptor.LabelMergeSpecification{},
 
+		authorities: map[string]TimestampAuthority{},
+
 		valuesets: map[string]map[string]ValueSet{},
 		setScheme: map[string]runtime.Scheme[runtime.TypedObject, runtime.TypedObjectDecoder[runtime.TypedObject]]{},
 
@@ -401,6 +405,26 @@ func (p *plugin) GetValueMergeHandler(name string) ValueMergeHandler {
 	return p.mergehandlers[name]
 }
 
+////////////////////////////////////////////////////////////////////////////////
+
+func (p *plugin) RegisterTimestampAuthority(a TimestampAuthority) error {
+	if p.GetTimestampAuthority(a.Name()) != nil {
+		return errors.ErrAlreadyExists("timestamp authority", a.Name())
+	}
+
+	td := descriptor.TimestampAuthorityDescriptor{
+		Name:        a.Name(),
+		Description: a.Description(),
+	}
+	p.descriptor.TimestampAuthorities = append(p.descriptor.TimestampAuthorities, td)
+	p.authorities[a.Name()] = a
+	return nil
+}
+
+func (p *plugin) GetTimestampAuthority(name string) TimestampAuthority {
+	return p.authorities[name]
+}
+
 func (p *plugin) RegisterLabelMergeSpecification(name, version string, spec *metav1.MergeAlgorithmSpecification, desc string) error {
 	e := descriptor.LabelMergeSpecification{
 		Name:                        name,
@@ -2,6 +2,7 @@ package registration
 
 import (
 	"slices"
+	"time"
 
 	"ocm.software/ocm/api/config/plugin"
 	"ocm.software/ocm/api/datacontext/action"
@@ -11,6 +12,7 @@ import (
 	pluginaccess "ocm.software/ocm/api/ocm/extensions/accessmethods/plugin"
 	pluginaction "ocm.software/ocm/api/ocm/extensions/actionhandler/plugin"
 	"ocm.software/ocm/api/ocm/extensions/attrs/plugincacheattr"
+	"ocm.software/ocm/api/ocm/extensions/attrs/signingattr"
 	pluginupload "ocm.software/ocm/api/ocm/extensions/blobhandler/handlers/generic/plugin"
 	"ocm.software/ocm/api/ocm/extensions/download"
 	plugindownload "ocm.software/ocm/api/ocm/extensions/download/handlers/plugin"
@@ -20,6 +22,7 @@ import (
 	"ocm.software/ocm/api/ocm/valuemergehandler"
 	pluginmerge "ocm.software/ocm/api/ocm/valuemergehandler/handlers/plugin"
 	"ocm.software/ocm/api/ocm/valuemergehandler/hpi"
+	"ocm.software/ocm/api/tech/signing/tsa"
 	"ocm.software/ocm/api/utils/runtime"
 )
 
@@ -59,6 +62,16 @@ func RegisterExtensions(ctxp ocm.ContextProvider) error {
 			}
 		}
 
+		for _, a := range p.GetDescriptor().TimestampAuthorities {
+			name := a.Name
+			logger.Info("registering timestamp authority",
+				"plugin", p.Name(),
+				"name", name)
+			signingattr.Get(ctx).RegisterTimestampAuthority(name, tsa.ClientFunc(func(mi *tsa.MessageImprint) (*tsa.TimeStamp, time.Time, error) {
+				return p.Timestamp(name, mi)
+			}))
+		}
+
 		for _, m := range p.GetDescriptor().AccessMethods {
 			name := m.Name
 			if m.Version != "" {
@@ -0,0 +1,260 @@
+// Package diff provides a semantic comparison of two component
+// descriptors. Elements (resources, sources and references) are
+// matched by their element identity, and changes of the access
+// specification are distinguished from changes of the content digest.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+type ChangeType string
+
+const (
+	ADDED   ChangeType = "added"
+	REMOVED ChangeType = "removed"
+	CHANGED ChangeType = "changed"
+)
+
+const (
+	KIND_RESOURCE  = "resource"
+	KIND_SOURCE    = "source"
+	KIND_REFERENCE = "reference"
+)
+
+// FieldChange describes the change of a single property.
+// Old and New are empty for a property not present in
+// the first or second element.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// ElementDiff describes the change of a resource, source or reference.
+type ElementDiff struct {
+	Kind     string          `json:"kind"`
+	Identity metav1.Identity `json:"identity"`
+	Change   ChangeType      `json:"change"`
+	// AccessChanged indicates a modified access specification.
+	AccessChanged bool `json:"accessChanged,omitempty"`
+	// DigestChanged indicates modified content, which is only detectable
+	// if digests are available for both elements.
+	DigestChanged bool          `json:"digestChanged,omitempty"`
+	Fields        []FieldChange `json:"fields,omitempty"`
+}
+
+// SignatureDiff describes the change of a signature.
+type SignatureDiff struct {
+	Name   string        `json:"name"`
+	Change ChangeType    `json:"change"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// Diff describes the semantic differences between two
+// component versions.
+type Diff struct {
+	From       string          `json:"from"`
+	To         string          `json:"to"`
+	Fields     []FieldChange   `json:"fields,omitempty"`
+	Resources  []ElementDiff   `json:"resources,omitempty"`
+	Sources    []ElementDiff   `json:"sources,omitempty"`
+	References []ElementDiff   `json:"references,omitempty"`
+	Signatures []SignatureDiff `json:"signatures,omitempty"`
+}
+
+// IsEmpty reports whether both component versions are equal.
+func (d *Diff) IsEmpty() bool {
+	return len(d.Fields) == 0 && len(d.Resources) == 0 && len(d.Sources) == 0 &&
+		len(d.References) == 0 && len(d.Signatures) == 0
+}
+
+// Compare compares two component descriptors.
+func Compare(a, b *compdesc.ComponentDescriptor) *Diff {
+	d := &Diff{
+		From: common.VersionedElementKey(a).String(),
+		To:   common.VersionedElementKey(b).String(),
+	}
+
+	d.Fields = compareString(d.Fields, "name", a.Name, b.Name)
+	d.Fields = compareString(d.Fields, "version", a.Version, b.Version)
+	d.Fields = compareString(d.Fields, "provider", string(a.Provider.Name), string(b.Provider.Name))
+	d.Fields = compareLabels(d.Fields, "provider label", a.Provider.Labels, b.Provider.Labels)
+	d.Fields = compareLabels(d.Fields, "label", a.Labels, b.Labels)
+	d.Fields = compareString(d.Fields, "creationTime", timestamp(a.CreationTime), timestamp(b.CreationTime))
+
+	d.Resources = compareElements(KIND_RESOURCE, a.Resources, b.Resources, compareResource)
+	d.Sources = compareElements(KIND_SOURCE, a.Sources, b.Sources, compareSource)
+	d.References = compareElements(KIND_REFERENCE, a.References, b.References, compareReference)
+	d.Signatures = compareSignatures(a.Signatures, b.Signatures)
+	return d
+}
+
+func compareElements(kind string, a, b compdesc.ElementListAccessor, cmp func(d *ElementDiff, a, b compdesc.ElementMetaAccessor)) []ElementDiff {
+	var result []ElementDiff
+	for i := 0; i < a.Len(); i++ {
+		ea := a.Get(i)
+		id := ea.GetMeta().GetIdentity(a)
+		eb := compdesc.GetByIdentity(b, id)
+		if eb == nil {
+			result = append(result, ElementDiff{Kind: kind, Identity: id, Change: REMOVED})
+			continue
+		}
+		d := ElementDiff{Kind: kind, Identity: id, Change: CHANGED}
+		d.Fields = compareMeta(d.Fields, ea, eb)
+		cmp(&d, ea, eb)
+		if len(d.Fields) > 0 {
+			result = append(result, d)
+		}
+	}
+	for i := 0; i < b.Len(); i++ {
+		eb := b.Get(i)
+		id := eb.GetMeta().GetIdentity(b)
+		if compdesc.GetByIdentity(a, id) == nil {
+			result = append(result, ElementDiff{Kind: kind, Identity: id, Change: ADDED})
+		}
+	}
+	return result
+}
+
+func compareMeta(fields []FieldChange, ea, eb compdesc.ElementMetaAccessor) []FieldChange {
+	a, b := ea.GetMeta(), eb.GetMeta()
+	fields = compareString(fields, "version", a.GetVersion(), b.GetVersion())
+	return compareLabels(fields, "label", a.GetLabels(), b.GetLabels())
+}
+
+func compareResource(d *ElementDiff, ea, eb compdesc.ElementMetaAccessor) {
+	a, b := ea.(*compdesc.Resource), eb.(*compdesc.Resource)
+	d.Fields = compareString(d.Fields, "type", a.Type, b.Type)
+	d.Fields = compareString(d.Fields, "relation", string(a.Relation), string(b.Relation))
+	d.Fields = compareString(d.Fields, "srcRefs", asJSON(a.SourceRefs), asJSON(b.SourceRefs))
+	d.Fields, d.AccessChanged = compareAccess(d.Fields, a.Access, b.Access)
+	d.Fields, d.DigestChanged = compareDigest(d.Fields, a.Digest, b.Digest)
+}
+
+func compareSource(d *ElementDiff, ea, eb compdesc.ElementMetaAccessor) {
+	a, b := ea.(*compdesc.Source), eb.(*compdesc.Source)
+	d.Fields = compareString(d.Fields, "type", a.Type, b.Type)
+	d.Fields, d.AccessChanged = compareAccess(d.Fields, a.Access, b.Access)
+}
+
+func compareReference(d *ElementDiff, ea, eb compdesc.ElementMetaAccessor) {
+	a, b := ea.(*compdesc.Reference), eb.(*compdesc.Reference)
+	d.Fields = compareString(d.Fields, "componentName", a.ComponentName, b.ComponentName)
+	d.Fields, d.DigestChanged = compareDigest(d.Fields, a.Digest, b.Digest)
+}
+
+func compareSignatures(a, b metav1.Signatures) []SignatureDiff {
+	var result []SignatureDiff
+	for _, sa := range a {
+		sb := b.GetByName(sa.Name)
+		if sb == nil {
+			result = append(result, SignatureDiff{Name: sa.Name, Change: REMOVED})
+			continue
+		}
+		var fields []FieldChange
+		fields = compareString(fields, "digest", sa.Digest.String(), sb.Digest.String())
+		fields = compareString(fields, "algorithm", sa.Signature.Algorithm, sb.Signature.Algorithm)
+		fields = compareString(fields, "issuer", sa.Signature.Issuer, sb.Signature.Issuer)
+		fields = compareString(fields, "value", sa.Signature.Value, sb.Signature.Value)
+		fields = compareString(fields, "timestamp", signatureTime(sa.Timestamp), signatureTime(sb.Timestamp))
+		if len(fields) > 0 {
+			result = append(result, SignatureDiff{Name: sa.Name, Change: CHANGED, Fields: fields})
+		}
+	}
+	for _, sb := range b {
+		if a.GetByName(sb.Name) == nil {
+			result = append(result, SignatureDiff{Name: sb.Name, Change: ADDED})
+		}
+	}
+	return result
+}
+
+func compareString(fields []FieldChange, name, a, b string) []FieldChange {
+	if a == b {
+		return fields
+	}
+	return append(fields, FieldChange{Field: name, Old: a, New: b})
+}
+
+func compareLabels(fields []FieldChange, kind string, a, b metav1.Labels) []FieldChange {
+	for _, la := range a {
+		lb := b.GetDef(la.Name)
+		if lb == nil {
+			fields = append(fields, FieldChange{Field: kind + " " + la.Name, Old: labelValue(&la)})
+			continue
+		}
+		fields = compareString(fields, kind+" "+la.Name, labelValue(&la), labelValue(lb))
+	}
+	for _, lb := range b {
+		if a.GetDef(lb.Name) == nil {
+			fields = append(fields, FieldChange{Field: kind + " " + lb.Name, New: labelValue(&lb)})
+		}
+	}
+	return fields
+}
+
+func compareAccess(fields []FieldChange, a, b compdesc.AccessSpec) ([]FieldChange, bool) {
+	sa, sb := asJSON(a), asJSON(b)
+	if sa == sb {
+		return fields, false
+	}
+	return append(fields, FieldChange{Field: "access", Old: sa, New: sb}), true
+}
+
+func compareDigest(fields []FieldChange, a, b *metav1.DigestSpec) ([]FieldChange, bool) {
+	if a.Equal(b) {
+		return fields, false
+	}
+	fields = append(fields, FieldChange{Field: "digest", Old: digest(a), New: digest(b)})
+	return fields, a != nil && b != nil && !a.IsNone() && !b.IsNone()
+}
+
+func labelValue(l *metav1.Label) string {
+	v := string(l.Value)
+	if l.Version != "" {
+		v += " (version " + l.Version + ")"
+	}
+	if l.Signing {
+		v += " (signing)"
+	}
+	return v
+}
+
+func digest(d *metav1.DigestSpec) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
+
+func timestamp(t *metav1.Timestamp) string {
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
+
+func signatureTime(t *metav1.TimestampSpec) string {
+	if t == nil || t.Time == nil {
+		return ""
+	}
+	return t.Time.String()
+}
+
+func asJSON(o interface{}) string {
+	if o == nil || reflect.ValueOf(o).Kind() == reflect.Pointer && reflect.ValueOf(o).IsNil() {
+		return ""
+	}
+	data, err := json.Marshal(o)
+	if err != nil {
+		return fmt.Sprintf("%v", o)
+	}
+	return string(data)
+}
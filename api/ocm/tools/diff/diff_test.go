@@ -0,0 +1,163 @@
+package diff_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/helper/builder"
+
+	"ocm.software/ocm/api/ocm"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/extensions/accessmethods/ociartifact"
+	"ocm.software/ocm/api/ocm/extensions/artifacttypes"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/ocm/tools/diff"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+)
+
+const (
+	ARCH      = "/tmp/ctf"
+	COMPONENT = "acme.org/test"
+	V1        = "v1"
+	V2        = "v2"
+)
+
+const (
+	D_IMAGE1 = "0000000000000000000000000000000000000000000000000000000000000001"
+	D_IMAGE2 = "0000000000000000000000000000000000000000000000000000000000000002"
+)
+
+var _ = Describe("component version diff", func() {
+	var env *Builder
+	var repo ocm.Repository
+	var cv1, cv2 ocm.ComponentVersionAccess
+
+	BeforeEach(func() {
+		env = NewBuilder()
+		env.ModificationOptions(ocm.SkipVerify())
+
+		env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+			env.Component(COMPONENT, func() {
+				env.Version(V1, func() {
+					env.Provider("acme.org")
+					env.Label("purpose", "test")
+					env.Resource("image", V1, artifacttypes.OCI_IMAGE, metav1.ExternalRelation, func() {
+						env.Access(ociartifact.New("ghcr.io/acme/image:v1"))
+						env.Digest(D_IMAGE1, "SHA-256", "ociArtifactDigest/v1")
+					})
+					env.Resource("moved", V1, artifacttypes.OCI_IMAGE, metav1.ExternalRelation, func() {
+						env.Access(ociartifact.New("ghcr.io/acme/moved:v1"))
+						env.Digest(D_IMAGE1, "SHA-256", "ociArtifactDigest/v1")
+					})
+					env.Resource("obsolete", V1, artifacttypes.OCI_IMAGE, metav1.ExternalRelation, func() {
+						env.Access(ociartifact.New("ghcr.io/acme/obsolete:v1"))
+						env.Digest(D_IMAGE1, "SHA-256", "ociArtifactDigest/v1")
+					})
+				})
+				env.Version(V2, func() {
+					env.Provider("acme.org")
+					env.Label("purpose", "prod")
+					env.Resource("image", V2, artifacttypes.OCI_IMAGE, metav1.ExternalRelation, func() {
+						env.Access(ociartifact.New("ghcr.io/acme/image:v2"))
+						env.Digest(D_IMAGE2, "SHA-256", "ociArtifactDigest/v1")
+					})
+					env.Resource("moved", V1, artifacttypes.OCI_IMAGE, metav1.ExternalRelation, func() {
+						env.Access(ociartifact.New("mirror.acme.org/acme/moved:v1"))
+						env.Digest(D_IMAGE1, "SHA-256", "ociArtifactDigest/v1")
+					})
+					env.Resource("new", V2, artifacttypes.OCI_IMAGE, metav1.ExternalRelation, func() {
+						env.Access(ociartifact.New("ghcr.io/acme/new:v2"))
+						env.Digest(D_IMAGE1, "SHA-256", "ociArtifactDigest/v1")
+					})
+				})
+			})
+		})
+		repo = Must(ctf.Open(env.OCMContext(), accessobj.ACC_READONLY, ARCH, 0, env))
+		cv1 = Must(repo.LookupComponentVersion(COMPONENT, V1))
+		cv2 = Must(repo.LookupComponentVersion(COMPONENT, V2))
+	})
+
+	AfterEach(func() {
+		Close(cv1, "cv1")
+		Close(cv2, "cv2")
+		Close(repo, "repo")
+		env.Cleanup()
+	})
+
+	It("detects no differences", func() {
+		d := diff.Compare(cv1.GetDescriptor(), cv1.GetDescriptor())
+		Expect(d.IsEmpty()).To(BeTrue())
+	})
+
+	It("compares resources by identity", func() {
+		d := diff.Compare(cv1.GetDescriptor(), cv2.GetDescriptor())
+		Expect(d.IsEmpty()).To(BeFalse())
+		Expect(d.Resources).To(HaveLen(4))
+
+		image := d.Resources[0]
+		Expect(image.Identity).To(Equal(metav1.NewIdentity("image")))
+		Expect(image.Change).To(Equal(diff.CHANGED))
+		Expect(image.AccessChanged).To(BeTrue())
+		Expect(image.DigestChanged).To(BeTrue())
+
+		moved := d.Resources[1]
+		Expect(moved.Identity).To(Equal(metav1.NewIdentity("moved")))
+		Expect(moved.AccessChanged).To(BeTrue())
+		Expect(moved.DigestChanged).To(BeFalse())
+
+		Expect(d.Resources[2].Identity).To(Equal(metav1.NewIdentity("obsolete")))
+		Expect(d.Resources[2].Change).To(Equal(diff.REMOVED))
+		Expect(d.Resources[3].Identity).To(Equal(metav1.NewIdentity("new")))
+		Expect(d.Resources[3].Change).To(Equal(diff.ADDED))
+	})
+
+	It("renders text", func() {
+		var buf bytes.Buffer
+		MustBeSuccessful(diff.Write(&buf, diff.Compare(cv1.GetDescriptor(), cv2.GetDescriptor()), diff.FORMAT_TEXT))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+comparing acme.org/test:v1 with acme.org/test:v2
+~ version: v1 -> v2
+~ label purpose: "test" -> "prod"
+resource "name"="image" changed (access changed, content changed)
+  ~ version: v1 -> v2
+  ~ access: {"imageReference":"ghcr.io/acme/image:v1","type":"ociArtifact"} -> {"imageReference":"ghcr.io/acme/image:v2","type":"ociArtifact"}
+  ~ digest: SHA-256:` + D_IMAGE1 + `[ociArtifactDigest/v1] -> SHA-256:` + D_IMAGE2 + `[ociArtifactDigest/v1]
+resource "name"="moved" changed (access changed)
+  ~ access: {"imageReference":"ghcr.io/acme/moved:v1","type":"ociArtifact"} -> {"imageReference":"mirror.acme.org/acme/moved:v1","type":"ociArtifact"}
+resource "name"="obsolete" removed
+resource "name"="new" added
+`))
+	})
+
+	It("renders markdown", func() {
+		var buf bytes.Buffer
+		d := diff.Compare(cv1.GetDescriptor(), cv2.GetDescriptor())
+		d.Resources = d.Resources[2:]
+		MustBeSuccessful(diff.Write(&buf, d, diff.FORMAT_MARKDOWN))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+## Changes from ` + "`acme.org/test:v1` to `acme.org/test:v2`" + `
+
+### Component
+
+- version: ` + "`v1` → `v2`" + `
+- label purpose: ` + "`\"test\"` → `\"prod\"`" + `
+
+### Resources
+
+- ` + "`\"name\"=\"obsolete\"`" + `: removed
+- ` + "`\"name\"=\"new\"`" + `: added
+`))
+	})
+
+	It("renders json", func() {
+		var buf bytes.Buffer
+		MustBeSuccessful(diff.Write(&buf, diff.Compare(cv1.GetDescriptor(), cv2.GetDescriptor()), diff.FORMAT_JSON))
+		var d diff.Diff
+		MustBeSuccessful(json.Unmarshal(buf.Bytes(), &d))
+		Expect(d).To(Equal(*diff.Compare(cv1.GetDescriptor(), cv2.GetDescriptor())))
+	})
+})
@@ -0,0 +1,134 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+)
+
+const (
+	FORMAT_TEXT     = "text"
+	FORMAT_JSON     = "json"
+	FORMAT_MARKDOWN = "markdown"
+)
+
+var Formats = []string{FORMAT_TEXT, FORMAT_JSON, FORMAT_MARKDOWN}
+
+// Write renders the diff in the given format.
+func Write(w io.Writer, d *Diff, format string) error {
+	switch format {
+	case "", FORMAT_TEXT:
+		return WriteText(w, d)
+	case FORMAT_JSON:
+		data, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	case FORMAT_MARKDOWN:
+		return WriteMarkdown(w, d)
+	default:
+		return errors.ErrInvalid("diff format", format)
+	}
+}
+
+// WriteText renders the diff as human-readable text.
+func WriteText(w io.Writer, d *Diff) error {
+	fmt.Fprintf(w, "comparing %s with %s\n", d.From, d.To)
+	if d.IsEmpty() {
+		fmt.Fprintf(w, "no differences\n")
+		return nil
+	}
+	writeTextFields(w, "", d.Fields)
+	for _, l := range [][]ElementDiff{d.Resources, d.Sources, d.References} {
+		for _, e := range l {
+			fmt.Fprintf(w, "%s %s %s%s\n", e.Kind, e.Identity, e.Change, elementHints(&e))
+			writeTextFields(w, "  ", e.Fields)
+		}
+	}
+	for _, s := range d.Signatures {
+		fmt.Fprintf(w, "signature %s %s\n", s.Name, s.Change)
+		writeTextFields(w, "  ", s.Fields)
+	}
+	return nil
+}
+
+func writeTextFields(w io.Writer, gap string, fields []FieldChange) {
+	for _, f := range fields {
+		switch {
+		case f.Old == "":
+			fmt.Fprintf(w, "%s+ %s: %s\n", gap, f.Field, f.New)
+		case f.New == "":
+			fmt.Fprintf(w, "%s- %s: %s\n", gap, f.Field, f.Old)
+		default:
+			fmt.Fprintf(w, "%s~ %s: %s -> %s\n", gap, f.Field, f.Old, f.New)
+		}
+	}
+}
+
+// WriteMarkdown renders the diff as markdown, suitable for
+// release notes or pull request descriptions.
+func WriteMarkdown(w io.Writer, d *Diff) error {
+	fmt.Fprintf(w, "## Changes from `%s` to `%s`\n", d.From, d.To)
+	if d.IsEmpty() {
+		fmt.Fprintf(w, "\nNo differences.\n")
+		return nil
+	}
+	if len(d.Fields) > 0 {
+		fmt.Fprintf(w, "\n### Component\n\n")
+		writeMarkdownFields(w, "", d.Fields)
+	}
+	writeMarkdownElements(w, "Resources", d.Resources)
+	writeMarkdownElements(w, "Sources", d.Sources)
+	writeMarkdownElements(w, "References", d.References)
+	if len(d.Signatures) > 0 {
+		fmt.Fprintf(w, "\n### Signatures\n\n")
+		for _, s := range d.Signatures {
+			fmt.Fprintf(w, "- `%s`: %s\n", s.Name, s.Change)
+			writeMarkdownFields(w, "  ", s.Fields)
+		}
+	}
+	return nil
+}
+
+func writeMarkdownElements(w io.Writer, title string, list []ElementDiff) {
+	if len(list) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n### %s\n\n", title)
+	for _, e := range list {
+		fmt.Fprintf(w, "- `%s`: %s%s\n", e.Identity, e.Change, elementHints(&e))
+		writeMarkdownFields(w, "  ", e.Fields)
+	}
+}
+
+func writeMarkdownFields(w io.Writer, gap string, fields []FieldChange) {
+	for _, f := range fields {
+		switch {
+		case f.Old == "":
+			fmt.Fprintf(w, "%s- %s: added `%s`\n", gap, f.Field, f.New)
+		case f.New == "":
+			fmt.Fprintf(w, "%s- %s: removed `%s`\n", gap, f.Field, f.Old)
+		default:
+			fmt.Fprintf(w, "%s- %s: `%s` → `%s`\n", gap, f.Field, f.Old, f.New)
+		}
+	}
+}
+
+func elementHints(e *ElementDiff) string {
+	var hints []string
+	if e.AccessChanged {
+		hints = append(hints, "access changed")
+	}
+	if e.DigestChanged {
+		hints = append(hints, "content changed")
+	}
+	if len(hints) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(hints, ", ") + ")"
+}
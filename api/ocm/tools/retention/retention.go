@@ -0,0 +1,276 @@
+// Package retention evaluates retention policies for component versions
+// stored in an OCM repository and deletes the component versions not
+// retained anymore.
+package retention
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/set"
+
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/compdesc"
+	"ocm.software/ocm/api/ocm/cpi/repocpi"
+	"ocm.software/ocm/api/ocm/resolvers"
+	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/semverutils"
+)
+
+// Policy describes the rules used to decide whether a component
+// version is retained. A component version is deleted, if it is
+// expired according to all configured expiration rules and not
+// protected by any rule.
+type Policy struct {
+	// KeepLast is the number of latest versions (in semver order)
+	// retained for every component. A value of 0 disables this rule.
+	KeepLast int
+	// ExpiredBefore expires versions created before this point in time.
+	// Versions without creation time are never expired by this rule.
+	// A zero value disables this rule.
+	ExpiredBefore time.Time
+	// KeepSigned protects signed component versions.
+	KeepSigned bool
+}
+
+func (p *Policy) Validate() error {
+	if p.KeepLast < 0 {
+		return errors.ErrInvalid("version limit", fmt.Sprintf("%d", p.KeepLast))
+	}
+	if p.KeepLast == 0 && p.ExpiredBefore.IsZero() {
+		return errors.New("retention policy requires a version limit or an expiration time")
+	}
+	return nil
+}
+
+// Entry describes the decision for a dedicated component version.
+type Entry struct {
+	Component string `json:"component"`
+	Version   string `json:"version"`
+	Reason    string `json:"reason"`
+}
+
+func (e *Entry) String() string {
+	return fmt.Sprintf("%s:%s", e.Component, e.Version)
+}
+
+// Report describes the retained and deleted component versions.
+type Report struct {
+	Retained []*Entry `json:"retained,omitempty"`
+	Deleted  []*Entry `json:"deleted,omitempty"`
+}
+
+type version struct {
+	entry      *Entry
+	keep       bool
+	references compdesc.References
+}
+
+// Evaluate evaluates the retention policy for the given components (or all
+// components, if no component is given) of a repository. Component versions
+// referenced by a retained component version are retained, too. This
+// includes references from the (untouched) versions of components of the
+// repository not selected for evaluation. References are followed through
+// the given resolver, which may be nil, to catch references leaving and
+// re-entering the repository.
+func Evaluate(repo ocm.Repository, policy *Policy, resolver ocm.ComponentVersionResolver, components ...string) (*Report, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	selected := set.New[string](components...)
+
+	var all []string
+	lister := repo.ComponentLister()
+	if lister != nil {
+		list, err := lister.GetComponents("", true)
+		if err != nil {
+			return nil, err
+		}
+		all = list
+	} else {
+		if len(components) == 0 {
+			return nil, errors.ErrNotSupported("component listing", repo.GetSpecification().GetKind())
+		}
+		all = components
+	}
+	if len(components) == 0 {
+		selected.Add(all...)
+	} else {
+		// selected components might not be listed, yet.
+		all = set.New[string](all...).Add(components...).AsArray()
+	}
+	sort.Strings(all)
+
+	var versions []*version
+	index := map[common.NameVersion]*version{}
+	for _, name := range all {
+		list, err := evaluateComponent(repo, policy, name, selected.Contains(name))
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range list {
+			versions = append(versions, v)
+			index[common.NewNameVersion(v.entry.Component, v.entry.Version)] = v
+		}
+	}
+
+	err := retainReferenced(repo, resolver, versions, index)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	for _, v := range versions {
+		if !selected.Contains(v.entry.Component) {
+			continue
+		}
+		if v.keep {
+			report.Retained = append(report.Retained, v.entry)
+		} else {
+			report.Deleted = append(report.Deleted, v.entry)
+		}
+	}
+	return report, nil
+}
+
+// evaluateComponent evaluates the policy for the versions of a component.
+// Versions of components not selected are always retained. They are
+// required to follow their references.
+func evaluateComponent(repo ocm.Repository, policy *Policy, name string, selected bool) ([]*version, error) {
+	c, err := repo.LookupComponent(name)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	vers, err := c.ListVersions()
+	if err != nil {
+		return nil, errors.Wrapf(err, "component %s", name)
+	}
+	cache := semverutils.VersionCache{}
+	sort.Slice(vers, func(a, b int) bool {
+		return cache.Compare(vers[a], vers[b]) > 0
+	})
+
+	var result []*version
+	for i, vn := range vers {
+		cv, err := c.LookupVersion(vn)
+		if err != nil {
+			return nil, errors.Wrapf(err, "component version %s:%s", name, vn)
+		}
+		cd := cv.GetDescriptor()
+		v := &version{
+			entry:      &Entry{Component: name, Version: vn},
+			references: cd.References.Copy(),
+		}
+		if selected {
+			v.keep, v.entry.Reason = policy.decide(i, cd)
+			if _, err := cache.Get(vn); err != nil {
+				v.keep, v.entry.Reason = true, "no semantic version"
+			}
+		} else {
+			v.keep, v.entry.Reason = true, "not selected"
+		}
+		result = append(result, v)
+		err = cv.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (p *Policy) decide(index int, cd *compdesc.ComponentDescriptor) (bool, string) {
+	if p.KeepSigned && len(cd.Signatures) > 0 {
+		return true, "signed"
+	}
+	if p.KeepLast > 0 && index < p.KeepLast {
+		return true, fmt.Sprintf("one of the latest %d versions", p.KeepLast)
+	}
+	if !p.ExpiredBefore.IsZero() {
+		if cd.CreationTime == nil {
+			return true, "no creation time"
+		}
+		if !cd.CreationTime.Time().Before(p.ExpiredBefore) {
+			return true, "not expired"
+		}
+		return false, "expired"
+	}
+	return false, fmt.Sprintf("exceeds version limit %d", p.KeepLast)
+}
+
+// retainReferenced marks all component versions reachable from retained
+// component versions as retained.
+func retainReferenced(repo ocm.Repository, resolver ocm.ComponentVersionResolver, versions []*version, index map[common.NameVersion]*version) error {
+	if resolver == nil {
+		resolver = repo
+	} else {
+		resolver = resolvers.NewCompoundResolver(repo, resolver)
+	}
+
+	type node struct {
+		nv   common.NameVersion
+		refs compdesc.References
+	}
+
+	visited := map[common.NameVersion]bool{}
+	var queue []node
+	for _, v := range versions {
+		if v.keep {
+			nv := common.NewNameVersion(v.entry.Component, v.entry.Version)
+			visited[nv] = true
+			queue = append(queue, node{nv, v.references})
+		}
+	}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, ref := range n.refs {
+			nv := common.NewNameVersion(ref.ComponentName, ref.Version)
+			if visited[nv] {
+				continue
+			}
+			visited[nv] = true
+			if v := index[nv]; v != nil {
+				if !v.keep {
+					v.keep = true
+					v.entry.Reason = fmt.Sprintf("referenced by %s", n.nv)
+				}
+				queue = append(queue, node{nv, v.references})
+				continue
+			}
+			// follow foreign references, they might refer back into the repository.
+			cv, err := resolver.LookupComponentVersion(ref.ComponentName, ref.Version)
+			if err != nil {
+				if errors.IsErrNotFound(err) {
+					continue
+				}
+				return errors.Wrapf(err, "reference %s of %s", nv, n.nv)
+			}
+			if cv == nil {
+				continue
+			}
+			refs := cv.GetDescriptor().References.Copy()
+			err = cv.Close()
+			if err != nil {
+				return err
+			}
+			queue = append(queue, node{nv, refs})
+		}
+	}
+	return nil
+}
+
+// Execute deletes the component versions listed as deleted in the report.
+func Execute(printer common.Printer, repo ocm.Repository, report *Report) error {
+	printer = common.AssurePrinter(printer)
+	list := errors.ErrListf("deleting component versions")
+	for _, e := range report.Deleted {
+		printer.Printf("deleting %s (%s)\n", e, e.Reason)
+		list.Add(errors.Wrapf(repocpi.DeleteComponentVersion(repo, e.Component, e.Version), "%s", e))
+	}
+	return list.Result()
+}
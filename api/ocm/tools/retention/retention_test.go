@@ -0,0 +1,151 @@
+package retention_test
+
+import (
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/helper/builder"
+
+	"ocm.software/ocm/api/ocm"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/ocm/tools/retention"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const (
+	ARCH       = "/tmp/ctf"
+	COMPONENT  = "acme.org/test"
+	COMPONENT2 = "acme.org/app"
+)
+
+func modify(repo ocm.Repository, name, vers string, f func(cv ocm.ComponentVersionAccess)) {
+	cv := Must(repo.LookupComponentVersion(name, vers))
+	f(cv)
+	MustBeSuccessful(cv.Close())
+}
+
+func entries(list []*retention.Entry) []string {
+	var result []string
+	for _, e := range list {
+		result = append(result, e.String()+": "+e.Reason)
+	}
+	return result
+}
+
+var _ = Describe("retention", func() {
+	var env *Builder
+	var repo ocm.Repository
+
+	BeforeEach(func() {
+		env = NewBuilder()
+
+		env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+			env.Component(COMPONENT, func() {
+				for _, v := range []string{"1.0.0", "1.1.0", "1.2.0", "1.10.0"} {
+					env.Version(v, func() {
+						env.Provider("acme.org")
+					})
+				}
+			})
+			env.Component(COMPONENT2, func() {
+				env.Version("1.0.0", func() {
+					env.Provider("acme.org")
+					env.Reference("base", COMPONENT, "1.0.0")
+				})
+			})
+		})
+		repo = Must(ctf.Open(env.OCMContext(), accessobj.ACC_WRITABLE, ARCH, 0, env))
+	})
+
+	AfterEach(func() {
+		Close(repo, "repo")
+		env.Cleanup()
+	})
+
+	It("rejects empty policy", func() {
+		ExpectError(retention.Evaluate(repo, &retention.Policy{KeepSigned: true}, nil)).To(MatchError("retention policy requires a version limit or an expiration time"))
+	})
+
+	It("keeps latest and referenced versions", func() {
+		report := Must(retention.Evaluate(repo, &retention.Policy{KeepLast: 1}, nil))
+		Expect(entries(report.Retained)).To(Equal([]string{
+			"acme.org/app:1.0.0: one of the latest 1 versions",
+			"acme.org/test:1.10.0: one of the latest 1 versions",
+			"acme.org/test:1.0.0: referenced by acme.org/app:1.0.0",
+		}))
+		Expect(entries(report.Deleted)).To(Equal([]string{
+			"acme.org/test:1.2.0: exceeds version limit 1",
+			"acme.org/test:1.1.0: exceeds version limit 1",
+		}))
+	})
+
+	It("keeps signed versions", func() {
+		modify(repo, COMPONENT, "1.1.0", func(cv ocm.ComponentVersionAccess) {
+			cv.GetDescriptor().Signatures = append(cv.GetDescriptor().Signatures, metav1.Signature{Name: "acme"})
+		})
+		report := Must(retention.Evaluate(repo, &retention.Policy{KeepLast: 1, KeepSigned: true}, nil, COMPONENT))
+		Expect(entries(report.Deleted)).To(Equal([]string{
+			"acme.org/test:1.2.0: exceeds version limit 1",
+		}))
+	})
+
+	It("expires old versions", func() {
+		now := time.Now()
+		for i, v := range []string{"1.0.0", "1.1.0", "1.2.0", "1.10.0"} {
+			t := metav1.NewTimestampFor(now.AddDate(0, 0, -10*(4-i)))
+			modify(repo, COMPONENT, v, func(cv ocm.ComponentVersionAccess) {
+				cv.GetDescriptor().CreationTime = &t
+			})
+		}
+		report := Must(retention.Evaluate(repo, &retention.Policy{ExpiredBefore: now.AddDate(0, 0, -25)}, nil, COMPONENT))
+		Expect(entries(report.Deleted)).To(Equal([]string{
+			"acme.org/test:1.1.0: expired",
+		}))
+
+		report = Must(retention.Evaluate(repo, &retention.Policy{KeepLast: 3, ExpiredBefore: now.AddDate(0, 0, -25)}, nil, COMPONENT))
+		Expect(report.Deleted).To(BeEmpty())
+	})
+
+	It("keeps versions referenced by other components", func() {
+		report := Must(retention.Evaluate(repo, &retention.Policy{KeepLast: 1}, nil, COMPONENT))
+		Expect(entries(report.Retained)).To(Equal([]string{
+			"acme.org/test:1.10.0: one of the latest 1 versions",
+			"acme.org/test:1.0.0: referenced by acme.org/app:1.0.0",
+		}))
+		Expect(entries(report.Deleted)).To(Equal([]string{
+			"acme.org/test:1.2.0: exceeds version limit 1",
+			"acme.org/test:1.1.0: exceeds version limit 1",
+		}))
+
+		report = Must(retention.Evaluate(repo, &retention.Policy{KeepLast: 1}, nil, COMPONENT2))
+		Expect(entries(report.Retained)).To(Equal([]string{
+			"acme.org/app:1.0.0: one of the latest 1 versions",
+		}))
+		Expect(report.Deleted).To(BeEmpty())
+	})
+
+	It("deletes component versions", func() {
+		report := Must(retention.Evaluate(repo, &retention.Policy{KeepLast: 1}, nil))
+		p, buf := common.NewBufferedPrinter()
+		MustBeSuccessful(retention.Execute(p, repo, report))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+deleting acme.org/test:1.2.0 (exceeds version limit 1)
+deleting acme.org/test:1.1.0 (exceeds version limit 1)
+`))
+		c := Must(repo.LookupComponent(COMPONENT))
+		Expect(c.ListVersions()).To(ConsistOf("1.0.0", "1.10.0"))
+		Close(c, "component")
+		Close(repo, "repo")
+
+		repo = Must(ctf.Open(env.OCMContext(), accessobj.ACC_READONLY, ARCH, 0, env))
+		c = Must(repo.LookupComponent(COMPONENT))
+		defer Close(c, "component")
+		Expect(c.ListVersions()).To(ConsistOf("1.0.0", "1.10.0"))
+		ExpectError(repo.LookupComponentVersion(COMPONENT, "1.2.0")).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,390 @@
+package signing
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/dsse"
+	"ocm.software/ocm/api/utils"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const KIND_ATTESTATION = "signature attestation"
+
+const (
+	// InTotoPayloadType is the DSSE payload type used for in-toto statements.
+	InTotoPayloadType = "application/vnd.in-toto+json"
+	// InTotoStatementType is the type of in-toto v1 statements.
+	InTotoStatementType = "https://in-toto.io/Statement/v1"
+	// SignaturePredicateType is the predicate type used to describe
+	// an OCM component version signature.
+	SignaturePredicateType = "https://ocm.software/attestations/signature/v1"
+)
+
+const (
+	ANNOTATION_KIND          = "ocm.software/kind"
+	ANNOTATION_IDENTITY      = "ocm.software/identity"
+	ANNOTATION_NORMALISATION = "ocm.software/normalisationAlgorithm"
+)
+
+// Statement is an in-toto v1 statement describing an OCM signature.
+// The first subject describes the normalized component descriptor,
+// the other subjects describe the digests of the resources.
+type Statement struct {
+	Type          string              `json:"_type"`
+	Subject       []Subject           `json:"subject"`
+	PredicateType string              `json:"predicateType"`
+	Predicate     *SignaturePredicate `json:"predicate"`
+}
+
+// Subject is an in-toto resource descriptor used as statement subject.
+type Subject struct {
+	Name        string                 `json:"name"`
+	Digest      map[string]string      `json:"digest"`
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+}
+
+// SignaturePredicate describes the OCM signature attested by a statement.
+type SignaturePredicate struct {
+	Component string           `json:"component"`
+	Version   string           `json:"version"`
+	Signature metav1.Signature `json:"signature"`
+}
+
+// GetName returns the component name of the statement.
+func (s *Statement) GetName() string {
+	return s.Predicate.Component
+}
+
+// GetVersion returns the component version of the statement.
+func (s *Statement) GetVersion() string {
+	return s.Predicate.Version
+}
+
+// InTotoDigestName maps an OCM hash algorithm name to the
+// name used in in-toto digest sets (for example, sha256).
+func InTotoDigestName(algo string) string {
+	return strings.ToLower(strings.ReplaceAll(signing.NormalizeHashAlgorithm(algo), "-", ""))
+}
+
+func subjectFor(name string, digest *metav1.DigestSpec, annotations map[string]interface{}) Subject {
+	annotations[ANNOTATION_NORMALISATION] = digest.NormalisationAlgorithm
+	return Subject{
+		Name:        name,
+		Digest:      map[string]string{InTotoDigestName(digest.HashAlgorithm): digest.Value},
+		Annotations: annotations,
+	}
+}
+
+// NewStatement creates an in-toto statement for a signature of a
+// component descriptor.
+func NewStatement(cd *compdesc.ComponentDescriptor, sig *metav1.Signature) *Statement {
+	st := &Statement{
+		Type:          InTotoStatementType,
+		PredicateType: SignaturePredicateType,
+		Predicate: &SignaturePredicate{
+			Component: cd.GetName(),
+			Version:   cd.GetVersion(),
+			Signature: *sig.Copy(),
+		},
+	}
+	st.Subject = append(st.Subject, subjectFor(common.VersionedElementKey(cd).String(), &sig.Digest, map[string]interface{}{
+		ANNOTATION_KIND: ocm.KIND_COMPONENTVERSION,
+	}))
+	for _, r := range cd.Resources {
+		if r.Digest == nil || r.Digest.Value == "" || r.Digest.Value == metav1.NoDigest {
+			continue
+		}
+		st.Subject = append(st.Subject, subjectFor(r.Name, r.Digest, map[string]interface{}{
+			ANNOTATION_KIND:     ocm.KIND_RESOURCE,
+			ANNOTATION_IDENTITY: r.GetIdentity(cd.Resources),
+		}))
+	}
+	return st
+}
+
+// ParseStatement provides the statement of a DSSE envelope. It does
+// not verify the envelope (see VerifyAttestation).
+func ParseStatement(env *dsse.Envelope) (*Statement, error) {
+	if env.PayloadType != InTotoPayloadType {
+		return nil, errors.ErrInvalid(KIND_ATTESTATION, "payload type "+env.PayloadType)
+	}
+	data, err := env.DecodePayload()
+	if err != nil {
+		return nil, err
+	}
+	var st Statement
+	err = json.Unmarshal(data, &st)
+	if err != nil {
+		return nil, errors.ErrInvalidWrap(err, KIND_ATTESTATION)
+	}
+	if st.Type != InTotoStatementType {
+		return nil, errors.ErrInvalid(KIND_ATTESTATION, "statement type "+st.Type)
+	}
+	if st.PredicateType != SignaturePredicateType {
+		return nil, errors.ErrInvalid(KIND_ATTESTATION, "predicate type "+st.PredicateType)
+	}
+	if st.Predicate == nil || st.Predicate.Component == "" || st.Predicate.Version == "" {
+		return nil, errors.ErrInvalid(KIND_ATTESTATION, "component version missing")
+	}
+	sig := &st.Predicate.Signature
+	if sig.Name == "" || sig.Digest.Value == "" || sig.Signature.Value == "" {
+		return nil, errors.ErrInvalid(KIND_ATTESTATION, "incomplete signature")
+	}
+	if len(st.Subject) == 0 || st.Subject[0].Digest[InTotoDigestName(sig.Digest.HashAlgorithm)] != sig.Digest.Value {
+		return nil, errors.ErrInvalid(KIND_ATTESTATION, "component descriptor subject does not match signature digest")
+	}
+	return &st, nil
+}
+
+// LoadAttestation reads a DSSE envelope from a file.
+func LoadAttestation(path string, fss ...vfs.FileSystem) (*dsse.Envelope, error) {
+	eff, err := utils.ResolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := vfs.ReadFile(utils.FileSystem(fss...), eff)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read %s %q", KIND_ATTESTATION, path)
+	}
+	env, err := dsse.Parse(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", path)
+	}
+	return env, nil
+}
+
+// SaveAttestation writes a DSSE envelope as JSON document to a file.
+func SaveAttestation(env *dsse.Envelope, path string, fss ...vfs.FileSystem) error {
+	eff, err := utils.ResolvePath(path)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+	return vfs.WriteFile(utils.FileSystem(fss...), eff, data, 0o644)
+}
+
+// CreateAttestation exports the signature with the given name of a
+// component version as in-toto statement wrapped into a DSSE envelope.
+// The digest of the component version is recalculated with the algorithms
+// of the signature and must still match the signed digest.
+// The envelope is signed with the private key for the signature name
+// provided by the options. By default, the signature algorithm of the
+// exported signature is used.
+func CreateAttestation(cv ocm.ComponentVersionAccess, name string, optlist ...Option) (*dsse.Envelope, error) {
+	nv := common.VersionedElementKey(cv)
+	cd := cv.GetDescriptor()
+	i := cd.GetSignatureIndex(name)
+	if i < 0 {
+		return nil, errors.ErrNotFound(compdesc.KIND_SIGNATURE, name, nv.String())
+	}
+	sig := cd.Signatures[i].Copy()
+
+	var opts Options
+
+	opts.Eval(SignatureName(name))
+	opts.Eval(optlist...)
+
+	if opts.VerifySignature {
+		return nil, errors.Newf("impossible verification option set for attestation")
+	}
+	if opts.Signer == nil && opts.SignAlgo == "" {
+		opts.SignAlgo = sig.Signature.Algorithm
+	}
+	if opts.Hasher == nil && opts.HashAlgo == "" {
+		opts.HashAlgo = sig.Digest.HashAlgorithm
+	}
+	err := opts.Complete(cv.GetContext())
+	if err != nil {
+		return nil, errors.Wrapf(err, "inconsistent options for attestation")
+	}
+
+	err = checkSignatureDigest(cv, sig, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(NewStatement(cd, sig))
+	if err != nil {
+		return nil, err
+	}
+	priv, err := opts.PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	env := dsse.NewEnvelope(InTotoPayloadType, payload)
+	sctx := &signing.DefaultSigningContext{
+		Hash:       opts.Hasher.Crypto(),
+		PrivateKey: priv,
+		PublicKey:  opts.PublicKey(name),
+		RootCerts:  opts.RootCerts,
+		Issuer:     opts.GetIssuer(),
+	}
+	err = env.Sign(cv.GetContext().CredentialsContext(), name, opts.Signer, sctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot sign %s", KIND_ATTESTATION)
+	}
+	return env, nil
+}
+
+// VerifyAttestation verifies a DSSE envelope created by CreateAttestation.
+// The envelope and the contained OCM signature are verified with the public
+// key for the signature name provided by the options or the certificate
+// provided by the OCM signature. It returns the verified statement.
+func VerifyAttestation(ctx ocm.ContextProvider, env *dsse.Envelope, optlist ...Option) (*Statement, error) {
+	st, err := ParseStatement(env)
+	if err != nil {
+		return nil, err
+	}
+	sig := &st.Predicate.Signature
+
+	var opts Options
+
+	opts.Eval(optlist...)
+
+	if opts.Signer != nil || opts.SignAlgo != "" {
+		return nil, errors.Newf("impossible signer option set for attestation verification")
+	}
+	if opts.Hasher == nil && opts.HashAlgo == "" {
+		opts.HashAlgo = sig.Digest.HashAlgorithm
+	}
+	err = opts.Complete(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "inconsistent options for attestation verification")
+	}
+
+	if info := verifySignatureValue(sig, &opts); info.err != nil {
+		return nil, errors.Wrapf(info.err, "signature %q", sig.Name)
+	}
+
+	verifier := opts.Registry.GetVerifier(sig.Signature.Algorithm)
+	if verifier == nil {
+		return nil, errors.ErrUnknown(compdesc.KIND_VERIFY_ALGORITHM, sig.Signature.Algorithm)
+	}
+	sctx := &signing.DefaultSigningContext{
+		Hash:      opts.Hasher.Crypto(),
+		RootCerts: opts.RootCerts,
+		Issuer:    opts.IssuerFor(sig.Name),
+		PublicKey: opts.PublicKey(sig.Name),
+	}
+	if sctx.PublicKey == nil {
+		sctx.PublicKey, err = GetPublicKeyFromSignature(sig, sctx, &opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "public key from signature")
+		}
+	}
+	err = env.Verify(sig.Name, verifier, sctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s for %s", KIND_ATTESTATION, common.VersionedElementKey(st))
+	}
+	return st, nil
+}
+
+// CheckAttestation checks whether a (verified) statement matches a component
+// version. The digest of the component version is recalculated with the
+// algorithms of the attested signature, and the resource subjects must
+// match the resource digests of the component version.
+func CheckAttestation(cv ocm.ComponentVersionAccess, st *Statement, optlist ...Option) error {
+	nv := common.VersionedElementKey(cv)
+	if st.GetName() != cv.GetName() || st.GetVersion() != cv.GetVersion() {
+		return errors.Newf("%s for %s does not match component version %s", KIND_ATTESTATION, common.VersionedElementKey(st), nv)
+	}
+
+	var opts Options
+
+	opts.Eval(optlist...)
+	opts.Signer = nil
+	opts.SignAlgo = ""
+	opts.VerifySignature = false
+	err := opts.Complete(cv.GetContext())
+	if err != nil {
+		return errors.Wrapf(err, "inconsistent options for attestation check")
+	}
+
+	err = checkSignatureDigest(cv, &st.Predicate.Signature, &opts)
+	if err != nil {
+		return err
+	}
+
+	cd := cv.GetDescriptor()
+	for _, s := range st.Subject[1:] {
+		if s.Annotations[ANNOTATION_KIND] != ocm.KIND_RESOURCE {
+			continue
+		}
+		id := metav1.Identity{}
+		if m, ok := s.Annotations[ANNOTATION_IDENTITY].(map[string]interface{}); ok {
+			for k, v := range m {
+				id[k] = fmt.Sprint(v)
+			}
+		} else {
+			id[compdesc.SystemIdentityName] = s.Name
+		}
+		r, err := cd.GetResourceByIdentity(id)
+		if err != nil {
+			return errors.Wrapf(err, "subject %q", s.Name)
+		}
+		if r.Digest == nil || s.Digest[InTotoDigestName(r.Digest.HashAlgorithm)] != r.Digest.Value {
+			return errors.Newf("digest of resource %s does not match attested digest", id)
+		}
+	}
+	return nil
+}
+
+// ImportAttestation verifies a DSSE envelope created by CreateAttestation
+// and adds the attested signature to the component version. Like for
+// AddSignatures the digest of the component version must still
+// match the signed digest.
+func ImportAttestation(cv ocm.ComponentVersionAccess, env *dsse.Envelope, optlist ...Option) error {
+	st, err := VerifyAttestation(cv.GetContext(), env, optlist...)
+	if err != nil {
+		return err
+	}
+	err = CheckAttestation(cv, st, optlist...)
+	if err != nil {
+		return err
+	}
+	sig := st.Predicate.Signature
+	req := &SigningRequest{
+		Component:  st.GetName(),
+		Version:    st.GetVersion(),
+		Digest:     sig.Digest,
+		Signatures: metav1.Signatures{sig},
+	}
+	return AddSignatures(cv, req, optlist...)
+}
+
+// checkSignatureDigest recalculates the digest of a component version
+// with the algorithms of a signature and checks it against the signed
+// digest.
+func checkSignatureDigest(cv ocm.ComponentVersionAccess, sig *metav1.Signature, opts *Options) error {
+	dopts := opts.Dup()
+	dopts.Signer = nil
+	dopts.SignAlgo = ""
+	dopts.VerifySignature = false
+	dopts.Update = false
+	dopts.Recursively = true
+	dopts.Verify = true
+	dopts.Hasher = nil
+	dopts.HashAlgo = sig.Digest.HashAlgorithm
+	dopts.NormalizationAlgo = sig.Digest.NormalisationAlgorithm
+
+	spec, err := Apply(nil, nil, cv, dopts)
+	if err != nil {
+		return err
+	}
+	if spec.Value != sig.Digest.Value {
+		return errors.Newf("component version %s has been modified: digest %s does not match signed digest %s", common.VersionedElementKey(cv), spec.Value, sig.Digest.Value)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package signing_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/helper/builder"
+	. "ocm.software/ocm/api/ocm/testhelper"
+	. "ocm.software/ocm/api/ocm/tools/signing"
+
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/tech/signing/dsse"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/tech/signing/signutils"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+)
+
+var _ = Describe("signature attestations", func() {
+	var env *Builder
+	var priv signutils.GenericPrivateKey
+	var pub signutils.GenericPublicKey
+
+	BeforeEach(func() {
+		env = NewBuilder()
+		priv, pub = Must2(rsa.CreateKeyPair())
+
+		env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+			env.Component(COMPONENTA, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+					TestDataResource(env)
+				})
+			})
+		})
+
+		withVersion(env, accessobj.ACC_WRITABLE, func(cv ocm.ComponentVersionAccess) {
+			Must(SignComponentVersion(cv, NAME, PrivateKey(NAME, priv)))
+		})
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	create := func() *dsse.Envelope {
+		var e *dsse.Envelope
+		withVersion(env, accessobj.ACC_READONLY, func(cv ocm.ComponentVersionAccess) {
+			e = Must(CreateAttestation(cv, NAME, PrivateKey(NAME, priv)))
+		})
+		return e
+	}
+
+	It("creates and verifies an attestation", func() {
+		e := create()
+		Expect(e.PayloadType).To(Equal(InTotoPayloadType))
+		Expect(e.Signatures).To(HaveLen(1))
+		Expect(e.Signatures[0].KeyID).To(Equal(NAME))
+
+		st := Must(VerifyAttestation(env, e, PublicKey(NAME, pub)))
+		Expect(st.GetName()).To(Equal(COMPONENTA))
+		Expect(st.PredicateType).To(Equal(SignaturePredicateType))
+		Expect(st.Subject).To(HaveLen(2))
+		Expect(st.Subject[0].Name).To(Equal(COMPONENTA + ":" + VERSION))
+		Expect(st.Subject[0].Digest).To(HaveKeyWithValue("sha256", st.Predicate.Signature.Digest.Value))
+		Expect(st.Subject[1].Name).To(Equal("testdata"))
+		Expect(st.Subject[1].Digest).To(HaveKeyWithValue("sha256", D_TESTDATA))
+		Expect(st.Subject[1].Annotations).To(HaveKeyWithValue(ANNOTATION_NORMALISATION, "genericBlobDigest/v1"))
+
+		withVersion(env, accessobj.ACC_READONLY, func(cv ocm.ComponentVersionAccess) {
+			MustBeSuccessful(CheckAttestation(cv, st))
+		})
+	})
+
+	It("rejects attestation with wrong key", func() {
+		e := create()
+		_, other := Must2(rsa.CreateKeyPair())
+		ExpectError(VerifyAttestation(env, e, PublicKey(NAME, other))).To(MatchError(ContainSubstring(`signature "mandelsoft": signature verification failed`)))
+	})
+
+	It("rejects modified statement", func() {
+		e := create()
+		st := Must(ParseStatement(e))
+		st.Subject[1].Digest["sha256"] = "0000"
+		e.Payload = base64.StdEncoding.EncodeToString(Must(json.Marshal(st)))
+		ExpectError(VerifyAttestation(env, e, PublicKey(NAME, pub))).To(MatchError(ContainSubstring(`signature attestation for github.com/mandelsoft/test:v1: signature "mandelsoft": signature verification failed`)))
+		withVersion(env, accessobj.ACC_READONLY, func(cv ocm.ComponentVersionAccess) {
+			Expect(CheckAttestation(cv, st)).To(MatchError(`digest of resource "name"="testdata" does not match attested digest`))
+		})
+	})
+
+	It("imports an attestation", func() {
+		e := create()
+		withVersion(env, accessobj.ACC_WRITABLE, func(cv ocm.ComponentVersionAccess) {
+			cv.GetDescriptor().Signatures = nil
+			MustBeSuccessful(cv.Update())
+		})
+		withVersion(env, accessobj.ACC_WRITABLE, func(cv ocm.ComponentVersionAccess) {
+			Expect(cv.GetDescriptor().Signatures).To(BeEmpty())
+			MustBeSuccessful(ImportAttestation(cv, e, PublicKey(NAME, pub)))
+		})
+		withVersion(env, accessobj.ACC_READONLY, func(cv ocm.ComponentVersionAccess) {
+			Expect(cv.GetDescriptor().GetSignatureIndex(NAME)).To(Equal(0))
+			Must(VerifyComponentVersion(cv, NAME, PublicKey(NAME, pub)))
+		})
+	})
+
+	It("rejects unknown signature", func() {
+		withVersion(env, accessobj.ACC_READONLY, func(cv ocm.ComponentVersionAccess) {
+			ExpectError(CreateAttestation(cv, "other", PrivateKey("other", priv))).To(MatchError(`signature "other" not found in github.com/mandelsoft/test:v1`))
+		})
+	})
+})
+
+func withVersion(env *Builder, mode accessobj.AccessMode, f func(cv ocm.ComponentVersionAccess)) {
+	src := Must(ctf.Open(env, mode, ARCH, 0, env))
+	defer Close(src, "source")
+	cv := Must(src.LookupComponentVersion(COMPONENTA, VERSION))
+	defer Close(cv, "version")
+	f(cv)
+}
@@ -1,12 +1,17 @@
 package signing
 
 import (
+	"sync"
+
 	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/set"
 
 	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/compdesc"
 	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
 	"ocm.software/ocm/api/ocm/extensions/attrs/signingattr"
 	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	common "ocm.software/ocm/api/utils/misc"
 )
 
 func SignComponentVersion(cv ocm.ComponentVersionAccess, name string, optlist ...Option) (*metav1.DigestSpec, error) {
@@ -55,3 +60,83 @@ func VerifyComponentVersion(cv ocm.ComponentVersionAccess, name string, optlist
 	}
 	return Apply(nil, nil, cv, &opts)
 }
+
+// VerifyDescriptorSignature verifies the named signature of a component
+// descriptor against the descriptor itself. In contrast to
+// VerifyComponentVersion, referenced component versions and resources
+// are not resolved, they are only covered by their digests found in the
+// descriptor. The options must be completed.
+func VerifyDescriptorSignature(cd *compdesc.ComponentDescriptor, name string, opts *Options) error {
+	sig := cd.SelectSignatureByName(name)
+	if sig == nil {
+		return errors.ErrNotFound(compdesc.KIND_SIGNATURE, name, common.VersionedElementKey(cd).String())
+	}
+	info := verifySignatureForPolicy(compdesc.NewCompDescDigests(cd), sig, opts)
+	return errors.Wrapf(info.err, "signature %q", name)
+}
+
+// CheckTrustPolicy checks the component version and all referenced
+// component versions against the given trust policy. Signatures are
+// verified with the public keys configured by the options or the
+// certificates provided by the signatures.
+func CheckTrustPolicy(cv ocm.ComponentVersionAccess, policy *TrustPolicy, optlist ...Option) error {
+	return checkTrustPolicy(nil, cv, policy, optlist...)
+}
+
+func checkTrustPolicy(state *WalkingState, cv ocm.ComponentVersionAccess, policy *TrustPolicy, optlist ...Option) error {
+	var opts Options
+
+	opts.Eval(
+		Recursive(),
+	)
+	opts.Eval(optlist...)
+	opts.TrustPolicy = policy
+
+	if opts.Signer != nil {
+		return errors.Newf("impossible signer option set for policy check")
+	}
+	err := opts.Complete(cv.GetContext())
+	if err != nil {
+		return errors.Wrapf(err, "inconsistent options for policy check")
+	}
+	_, err = Apply(nil, state, cv, &opts)
+	return err
+}
+
+// TrustPolicyChecker checks multiple component versions against a trust
+// policy. Because a check covers all referenced component versions, a
+// successful check is remembered for the complete checked closure to avoid
+// repeated verifications of shared component versions.
+type TrustPolicyChecker struct {
+	lock    sync.Mutex
+	policy  *TrustPolicy
+	checked set.Set[common.NameVersion]
+}
+
+func NewTrustPolicyChecker(policy *TrustPolicy) *TrustPolicyChecker {
+	return &TrustPolicyChecker{
+		policy:  policy,
+		checked: set.New[common.NameVersion](),
+	}
+}
+
+// Check checks the component version and all referenced component versions
+// against the trust policy, if it has not already been covered by a
+// previous check.
+func (c *TrustPolicyChecker) Check(cv ocm.ComponentVersionAccess, optlist ...Option) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.checked.Contains(common.VersionedElementKey(cv)) {
+		return nil
+	}
+	state := DefaultWalkingState(cv.GetContext())
+	err := checkTrustPolicy(state, cv, c.policy, optlist...)
+	if err != nil {
+		return err
+	}
+	for nv := range state.Closure {
+		c.checked.Add(nv)
+	}
+	return nil
+}
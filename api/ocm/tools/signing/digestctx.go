@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/sliceutils"
 
 	"ocm.software/ocm/api/ocm"
 	"ocm.software/ocm/api/ocm/compdesc"
@@ -202,6 +203,9 @@ func (dc *DigestContext) ValidFor(ctx *DigestContext) bool {
 }
 
 func (dc *DigestContext) determineSignatureInfo(state WalkingState, cv ocm.ComponentVersionAccess, opts *Options) (*Options, error) {
+	if opts.Threshold > 0 {
+		return dc.determineThresholdSignatureInfo(state, opts)
+	}
 	if opts.SignatureName() != "" {
 		// determine digester type
 		var found bool
@@ -269,3 +273,33 @@ func (dc *DigestContext) determineSignatureInfo(state WalkingState, cv ocm.Compo
 	}
 	return opts, nil
 }
+
+// determineThresholdSignatureInfo selects the verifiable signatures for
+// a threshold verification. Only the configured signatures are candidates,
+// missing ones are tolerated as long as the threshold can still be reached.
+// The first present signature determines the digester type.
+func (dc *DigestContext) determineThresholdSignatureInfo(state WalkingState, opts *Options) (*Options, error) {
+	var signatures []string
+	for _, n := range sliceutils.AppendUnique([]string(nil), opts.SignatureNames...) {
+		i := dc.Descriptor.GetSignatureIndex(n)
+		if i < 0 {
+			opts.Printer.Printf("Warning: signature %q not found in %s\n", n, state.History)
+			continue
+		}
+		st := DigesterType(&dc.Descriptor.Signatures[i].Digest)
+		if dc.DigestType.IsInitial() {
+			dc.DigestType = st
+		}
+		if dc.DigestType == st {
+			signatures = append(signatures, n)
+		} else {
+			opts.Printer.Printf("Warning: digest type %s for signature %q in %s does not match (signature ignored)\n", dc.DigestType.String(), n, state.History)
+		}
+	}
+	if len(signatures) < opts.Threshold {
+		return nil, errors.Newf("%d of %d required signature(s) found", len(signatures), opts.Threshold)
+	}
+	opts = opts.Dup()
+	opts.SignatureNames = signatures
+	return opts, nil
+}
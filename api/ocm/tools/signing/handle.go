@@ -3,6 +3,7 @@ package signing
 import (
 	"context"
 	"crypto"
+	"crypto/x509"
 	"encoding/hex"
 	"fmt"
 	"reflect"
@@ -13,8 +14,11 @@ import (
 	"github.com/mandelsoft/goutils/general"
 	"github.com/mandelsoft/goutils/generics"
 	"github.com/mandelsoft/goutils/maputils"
+	"github.com/mandelsoft/goutils/sliceutils"
 	"github.com/mandelsoft/logging"
 
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/datacontext/attrs/progressattr"
 	"ocm.software/ocm/api/ocm"
 	"ocm.software/ocm/api/ocm/compdesc"
 	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
@@ -23,6 +27,7 @@ import (
 	"ocm.software/ocm/api/tech/signing/signutils"
 	"ocm.software/ocm/api/tech/signing/tsa"
 	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/progress"
 )
 
 var REALM = logging.NewRealm("signing")
@@ -172,7 +177,27 @@ func apply(ctx context.Context, state WalkingState, cv ocm.ComponentVersionAcces
 			return dc, nil
 		}
 	}
-	return _apply(ctx, state, nv, cv, vi, opts)
+	tracker := progressattr.For(cv.GetContext(), progressOperation(opts), progress.KIND_COMPONENTVERSION, "", nv.String())
+	tracker.Started()
+	dc, err := _apply(ctx, state, nv, cv, vi, opts)
+	tracker.Finished(err)
+	return dc, err
+}
+
+// policyDigest provides the signature digest determining the digest
+// context for a pure policy evaluation.
+func policyDigest(cd *compdesc.ComponentDescriptor, opts *Options) *metav1.DigestSpec {
+	if opts.DoSign() || opts.TrustPolicy == nil {
+		return nil
+	}
+	return opts.TrustPolicy.DigestFor(cd)
+}
+
+func progressOperation(opts *Options) string {
+	if opts.DoSign() {
+		return progress.OPERATION_SIGN
+	}
+	return progress.OPERATION_VERIFY
 }
 
 func _apply(cctx context.Context, state WalkingState, nv common.NameVersion, cv ocm.ComponentVersionAccess, vi *VersionInfo, opts *Options) (*DigestContext, error) { //nolint: maintidx // yes
@@ -196,9 +221,14 @@ func _apply(cctx context.Context, state WalkingState, nv common.NameVersion, cv
 		mode := GetDigestMode(cv.GetDescriptor(), opts.DigestMode)
 		opts = opts.WithDigestMode(mode)
 		if opts.DoSign() || !opts.DoVerify() {
-			ctx.DigestType = ocm.DigesterType{
-				HashAlgorithm:          opts.Hasher.Algorithm(),
-				NormalizationAlgorithm: opts.NormalizationAlgo,
+			if dig := policyDigest(cv.GetDescriptor(), opts); dig != nil {
+				// policy evaluation requires the digest context used for the existing signatures
+				ctx.DigestType = DigesterType(dig)
+			} else {
+				ctx.DigestType = ocm.DigesterType{
+					HashAlgorithm:          opts.Hasher.Algorithm(),
+					NormalizationAlgorithm: opts.NormalizationAlgo,
+				}
 			}
 		} else {
 			var err error
@@ -253,7 +283,7 @@ func _apply(cctx context.Context, state WalkingState, nv common.NameVersion, cv
 	if len(signatureNames) == 0 && opts.Keyless {
 		return nil, errors.New("signature not provided")
 	}
-	if opts.DoVerify() && !opts.DoSign() {
+	if opts.DoVerify() && !opts.DoSign() && opts.Threshold == 0 {
 		for _, n := range signatureNames {
 			f := cd.GetSignatureIndex(n)
 			if f < 0 {
@@ -294,7 +324,7 @@ func _apply(cctx context.Context, state WalkingState, nv common.NameVersion, cv
 	}
 
 	if opts.DoVerify() {
-		dig, err := doVerify(digests, state, signatureNames, opts)
+		dig, verified, err := doVerify(digests, state, signatureNames, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -302,7 +332,12 @@ func _apply(cctx context.Context, state WalkingState, nv common.NameVersion, cv
 			spec = dig
 		}
 
-		addVerified(state, cd, opts, signatureNames...)
+		addVerified(state, cd, opts, verified...)
+	}
+	if opts.TrustPolicy != nil && !opts.DoSign() {
+		if err := opts.TrustPolicy.Evaluate(digests, opts); err != nil {
+			return nil, err
+		}
 	}
 	err := ctx.Propagate(spec)
 	if err != nil {
@@ -311,70 +346,14 @@ func _apply(cctx context.Context, state WalkingState, nv common.NameVersion, cv
 
 	found := cd.GetSignatureIndex(opts.SignatureName())
 	if opts.DoSign() && (!opts.DoVerify() || found == -1) {
-		priv, err := opts.PrivateKey()
+		signature, err := createSignature(cv.GetContext().CredentialsContext(), ctx.Digest, opts)
 		if err != nil {
 			return nil, err
 		}
-		sctx := &signing.DefaultSigningContext{
-			Hash:       opts.Hasher.Crypto(),
-			PrivateKey: priv,
-			PublicKey:  opts.PublicKey(opts.SignatureName()),
-			RootCerts:  opts.RootCerts,
-			Issuer:     opts.GetIssuer(),
-		}
-		sig, err := opts.Signer.Sign(cv.GetContext().CredentialsContext(), ctx.Digest.Value, sctx)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed signing component descriptor")
-		}
-		if sig.Issuer != "" {
-			iss, err := signutils.ParseDN(sig.Issuer)
-			if err != nil {
-				return nil, errors.Wrapf(err, "signature issuer")
-			}
-			if sctx.Issuer != nil {
-				if err := signutils.MatchDN(*iss, *sctx.Issuer); err != nil {
-					return nil, errors.Newf("signature issuer %q does not match intended issuer %q", sig.Issuer, sctx.Issuer)
-				}
-			}
-		}
-		signature := metav1.Signature{
-			Name:   opts.SignatureName(),
-			Digest: *ctx.Digest,
-			Signature: metav1.SignatureSpec{
-				Algorithm: sig.Algorithm,
-				Value:     sig.Value,
-				MediaType: sig.MediaType,
-				Issuer:    sig.Issuer,
-			},
-		}
-
-		if url := opts.EffectiveTSAUrl(); url != "" {
-			h, d, err := DigestInfo(opts, ctx.Digest)
-			if err != nil {
-				return nil, err
-			}
-			mi, err := tsa.NewMessageImprint(h, d)
-			if err != nil {
-				return nil, err
-			}
-
-			ts, t, err := tsa.Request(url, mi)
-			if err != nil {
-				return nil, err
-			}
-			data, err := tsa.ToPem(ts)
-			if err != nil {
-				return nil, err
-			}
-			signature.Timestamp = &metav1.TimestampSpec{
-				Value: string(data),
-				Time:  generics.Pointer(compdesc.NewTimestampFor(t)),
-			}
-		}
 		if found >= 0 {
-			cd.Signatures[found] = signature
+			cd.Signatures[found] = *signature
 		} else {
-			cd.Signatures = append(cd.Signatures, signature)
+			cd.Signatures = append(cd.Signatures, *signature)
 		}
 		addVerified(state, cd, opts, signatureNames...)
 	}
@@ -406,6 +385,77 @@ func _apply(cctx context.Context, state WalkingState, nv common.NameVersion, cv
 	return ctx, nil
 }
 
+// createSignature signs the given component descriptor digest with the
+// signer and key configured by the options. If configured, a timestamp
+// is requested from a TSA for the digest.
+func createSignature(cctx credentials.Context, digest *metav1.DigestSpec, opts *Options) (*metav1.Signature, error) {
+	priv, err := opts.PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	sctx := &signing.DefaultSigningContext{
+		Hash:       opts.Hasher.Crypto(),
+		PrivateKey: priv,
+		PublicKey:  opts.PublicKey(opts.SignatureName()),
+		RootCerts:  opts.RootCerts,
+		Issuer:     opts.GetIssuer(),
+	}
+	sig, err := opts.Signer.Sign(cctx, digest.Value, sctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed signing component descriptor")
+	}
+	if sig.Issuer != "" {
+		iss, err := signutils.ParseDN(sig.Issuer)
+		if err != nil {
+			return nil, errors.Wrapf(err, "signature issuer")
+		}
+		if sctx.Issuer != nil {
+			if err := signutils.MatchDN(*iss, *sctx.Issuer); err != nil {
+				return nil, errors.Newf("signature issuer %q does not match intended issuer %q", sig.Issuer, sctx.Issuer)
+			}
+		}
+	}
+	signature := metav1.Signature{
+		Name:   opts.SignatureName(),
+		Digest: *digest,
+		Signature: metav1.SignatureSpec{
+			Algorithm: sig.Algorithm,
+			Value:     sig.Value,
+			MediaType: sig.MediaType,
+			Issuer:    sig.Issuer,
+		},
+	}
+
+	authority, err := opts.EffectiveTSA()
+	if err != nil {
+		return nil, errors.Wrapf(err, "timestamp authority")
+	}
+	if authority != nil {
+		h, d, err := DigestInfo(opts, digest)
+		if err != nil {
+			return nil, err
+		}
+		mi, err := tsa.NewMessageImprint(h, d)
+		if err != nil {
+			return nil, err
+		}
+
+		ts, t, err := authority.Timestamp(mi)
+		if err != nil {
+			return nil, err
+		}
+		data, err := tsa.ToPem(ts)
+		if err != nil {
+			return nil, err
+		}
+		signature.Timestamp = &metav1.TimestampSpec{
+			Value: string(data),
+			Time:  generics.Pointer(compdesc.NewTimestampFor(t)),
+		}
+	}
+	return &signature, nil
+}
+
 func checkDigest(orig *metav1.DigestSpec, act *metav1.DigestSpec) bool {
 	if orig != nil {
 		algo := signing.NormalizeHashAlgorithm(orig.HashAlgorithm)
@@ -442,76 +492,132 @@ func DigestInfo(opts *Options, d *metav1.DigestSpec) (crypto.Hash, []byte, error
 	return hasher.Crypto(), data, nil
 }
 
-func doVerify(digests *compdesc.CompDescDigests, state WalkingState, signatureNames []string, opts *Options) (*metav1.DigestSpec, error) {
+func doVerify(digests *compdesc.CompDescDigests, state WalkingState, signatureNames []string, opts *Options) (*metav1.DigestSpec, []string, error) {
 	var spec *metav1.DigestSpec
 
-	sctx := &signing.DefaultSigningContext{
-		Hash:      opts.Hasher.Crypto(),
-		RootCerts: opts.RootCerts,
-	}
-
 	found := []string{}
-	for _, n := range signatureNames {
+	// for a threshold verification every public key is counted only once.
+	keys := map[string]string{}
+	count := 0
+	for _, n := range sliceutils.AppendUnique([]string{}, signatureNames...) {
 		f := digests.Descriptor().GetSignatureIndex(n)
 		if f < 0 {
 			continue
 		}
 		sig := &digests.Descriptor().Signatures[f]
 
-		sctx.Issuer = opts.IssuerFor(n)
-		if !opts.Keyless {
-			sctx.PublicKey = opts.PublicKey(n)
-			if sctx.PublicKey == nil {
-				var err error
-
-				opts.Printer.Printf("no public key found for signature %q -> extract key from signature\n", n)
-				sctx.PublicKey, err = GetPublicKeyFromSignature(sig, sctx, opts)
-				if err != nil {
-					return nil, errors.Wrapf(err, "public key from signature")
-				}
-			}
-		}
-		verifier := opts.Registry.GetVerifier(sig.Signature.Algorithm)
-		if verifier == nil {
-			if opts.SignatureConfigured(n) {
-				return nil, errors.ErrUnknown(compdesc.KIND_VERIFY_ALGORITHM, n)
+		ok, key, err := verifyNamedSignature(digests, state, sig, opts)
+		if err != nil {
+			if opts.Threshold == 0 {
+				return nil, nil, err
 			}
-			opts.Printer.Printf("Warning: no verifier (%s) found for signature %q in %s\n", sig.Signature.Algorithm, n, state.History)
+			opts.Printer.Printf("Warning: %s in %s\n", err, state.History)
 			continue
 		}
-
-		hasher := opts.Registry.GetHasher(sig.Digest.HashAlgorithm)
-		if hasher == nil {
-			return nil, errors.ErrUnknown(compdesc.KIND_HASH_ALGORITHM, sig.Digest.HashAlgorithm)
-		}
-
-		_, digest, err := digests.Get(sig.Digest.NormalisationAlgorithm, hasher)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed hashing component descriptor")
-		}
-		if sig.Digest.Value != digest {
-			return nil, errors.Newf("signature digest (%s) does not match found digest (%s)", sig.Digest.Value, digest)
-		}
-
-		sctx.Hash = hasher.Crypto()
-		err = verifier.Verify(sig.Digest.Value, sig.ConvertToSigning(), sctx)
-		if err != nil {
-			return nil, errors.Wrapf(err, "signature %q", n)
+		if !ok {
+			continue
 		}
 		found = append(found, n)
+		if id := publicKeyIdentity(key); id != "" {
+			if other, ok := keys[id]; ok {
+				if opts.Threshold > 0 {
+					opts.Printer.Printf("Warning: signature %q uses the same public key as signature %q in %s (not counted)\n", n, other, state.History)
+				}
+			} else {
+				keys[id] = n
+				count++
+			}
+		} else if opts.Threshold > 0 {
+			// the same signer could meet the threshold using several signature names.
+			opts.Printer.Printf("Warning: public key of signature %q cannot be identified in %s (not counted)\n", n, state.History)
+		}
 		if opts.SignatureName() == sig.Name {
 			d := sig.Digest
 			d.HashAlgorithm = signing.NormalizeHashAlgorithm(d.HashAlgorithm)
 			spec = &d
 		}
 	}
+	if opts.Threshold > 0 && count < opts.Threshold {
+		return nil, nil, errors.Newf("%d signature(s) with distinct keys verified, but %d required", count, opts.Threshold)
+	}
 	if len(found) == 0 {
 		if !opts.DoSign() {
-			return nil, errors.Newf("no verifiable signature found")
+			return nil, nil, errors.Newf("no verifiable signature found")
 		}
 	}
 
-	return spec, nil
+	return spec, found, nil
+}
+
+// publicKeyIdentity provides a comparable representation of a public key.
+// It is empty, if the key is unknown or cannot be serialized.
+func publicKeyIdentity(key signutils.GenericPublicKey) string {
+	if key == nil {
+		return ""
+	}
+	pub, err := signutils.GetPublicKey(key)
+	if err != nil {
+		return ""
+	}
+	data, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// verifyNamedSignature verifies a dedicated signature of a component descriptor.
+// It returns false, if the signature is ignored, because no verifier is known
+// for the signature algorithm. Additionally, the public key used for the
+// verification is provided, if known.
+func verifyNamedSignature(digests *compdesc.CompDescDigests, state WalkingState, sig *compdesc.Signature, opts *Options) (bool, signutils.GenericPublicKey, error) {
+	n := sig.Name
+	sctx := &signing.DefaultSigningContext{
+		Hash:      opts.Hasher.Crypto(),
+		RootCerts: opts.RootCerts,
+		Issuer:    opts.IssuerFor(n),
+	}
+
+	if !opts.Keyless {
+		sctx.PublicKey = opts.PublicKey(n)
+		if sctx.PublicKey == nil {
+			var err error
+
+			opts.Printer.Printf("no public key found for signature %q -> extract key from signature\n", n)
+			sctx.PublicKey, err = GetPublicKeyFromSignature(sig, sctx, opts)
+			if err != nil {
+				return false, nil, errors.Wrapf(err, "public key from signature")
+			}
+		}
+	}
+	verifier := opts.Registry.GetVerifier(sig.Signature.Algorithm)
+	if verifier == nil {
+		if opts.SignatureConfigured(n) {
+			return false, nil, errors.ErrUnknown(compdesc.KIND_VERIFY_ALGORITHM, n)
+		}
+		opts.Printer.Printf("Warning: no verifier (%s) found for signature %q in %s\n", sig.Signature.Algorithm, n, state.History)
+		return false, nil, nil
+	}
+
+	hasher := opts.Registry.GetHasher(sig.Digest.HashAlgorithm)
+	if hasher == nil {
+		return false, nil, errors.ErrUnknown(compdesc.KIND_HASH_ALGORITHM, sig.Digest.HashAlgorithm)
+	}
+
+	_, digest, err := digests.Get(sig.Digest.NormalisationAlgorithm, hasher)
+	if err != nil {
+		return false, nil, errors.Wrapf(err, "failed hashing component descriptor")
+	}
+	if sig.Digest.Value != digest {
+		return false, nil, errors.Newf("signature digest (%s) does not match found digest (%s)", sig.Digest.Value, digest)
+	}
+
+	sctx.Hash = hasher.Crypto()
+	err = verifier.Verify(sig.Digest.Value, sig.ConvertToSigning(), sctx)
+	if err != nil {
+		return false, nil, errors.Wrapf(err, "signature %q", n)
+	}
+	return true, sctx.PublicKey, nil
 }
 
 func GetPublicKeyFromSignature(sig *compdesc.Signature, sctx signing.SigningContext, opts *Options) (signutils.GenericPublicKey, error) {
@@ -533,21 +639,17 @@ func GetPublicKeyFromSignature(sig *compdesc.Signature, sctx signing.SigningCont
 
 	var timestamp *time.Time
 	if sig.Timestamp != nil {
-		ts, err := tsa.FromPem([]byte(sig.Timestamp.Value))
+		timestamp, err = verifyTimestamp(sig, sctx, opts)
 		if err != nil {
-			return nil, errors.Wrapf(err, "signature timestamp")
-		}
-		h, d, err := DigestInfo(opts, &sig.Digest)
-		if err != nil {
-			return nil, errors.Wrapf(err, "signature digest")
+			return nil, err
 		}
-		mi, err := tsa.NewMessageImprint(h, d)
-		if err != nil {
-			return nil, errors.Wrapf(err, "signature digest")
+	}
+	if opts.RequireTimestamp {
+		if timestamp == nil {
+			return nil, errors.Newf("signature %q is not timestamped", sig.Name)
 		}
-		timestamp, err = tsa.Verify(mi, ts, false, sctx.GetRootCerts())
-		if err != nil {
-			return nil, errors.Wrapf(err, "signature timestamp verification")
+		if timestamp.After(cert.NotAfter) {
+			return nil, errors.Newf("signature %q timestamped (%s) after certificate expiration (%s)", sig.Name, timestamp.UTC().Format(time.RFC3339), cert.NotAfter.UTC().Format(time.RFC3339))
 		}
 	}
 
@@ -558,6 +660,33 @@ func GetPublicKeyFromSignature(sig *compdesc.Signature, sctx signing.SigningCont
 	return cert.PublicKey, nil
 }
 
+// verifyTimestamp verifies the timestamp of a signature and provides
+// the signing time certified by the timestamp authority.
+func verifyTimestamp(sig *compdesc.Signature, sctx signing.SigningContext, opts *Options) (*time.Time, error) {
+	ts, err := tsa.FromPem([]byte(sig.Timestamp.Value))
+	if err != nil {
+		return nil, errors.Wrapf(err, "signature timestamp")
+	}
+	h, d, err := DigestInfo(opts, &sig.Digest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "signature digest")
+	}
+	mi, err := tsa.NewMessageImprint(h, d)
+	if err != nil {
+		return nil, errors.Wrapf(err, "signature digest")
+	}
+	var timestamp *time.Time
+	if opts.TSARootCerts != nil {
+		timestamp, err = tsa.VerifyOffline(mi, ts, opts.TSARootCerts)
+	} else {
+		timestamp, err = tsa.Verify(mi, ts, false, sctx.GetRootCerts())
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "signature timestamp verification")
+	}
+	return timestamp, nil
+}
+
 func calculateReferenceDigests(cctx context.Context, state WalkingState, opts *Options, legacy bool) (rerr error) {
 	var finalize finalizer.Finalizer
 	defer finalize.FinalizeWithErrorPropagation(&rerr)
@@ -688,7 +817,10 @@ func calculateResourceDigests(cctx context.Context, state WalkingState, cv ocm.C
 		if rdigest != nil {
 			req = []ocm.DigesterType{DigesterType(rdigest)}
 		}
+		tracker := progressattr.For(octx, progressOperation(opts), progress.KIND_RESOURCE, common.VersionedElementKey(cv).String(), meta.Name)
+		tracker.Started()
 		digest, err := blobdigesters.DetermineDigests(res.Meta().GetType(), opts.Hasher, opts.Registry, meth, req...)
+		tracker.Finished(err)
 		if err != nil {
 			return errors.Wrapf(err, resMsg(raw, acc.Describe(octx), "failed determining digest for resource"))
 		}
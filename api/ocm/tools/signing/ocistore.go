@@ -0,0 +1,92 @@
+package signing
+
+import (
+	"fmt"
+
+	"github.com/mandelsoft/goutils/errors"
+
+	"ocm.software/ocm/api/oci"
+	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
+)
+
+const (
+	VerifiedStoreConfigMimeType = "application/vnd.ocm.software.verified-store.config.v1+json"
+	VerifiedStoreLayerMimeType  = "application/vnd.ocm.software.verified-store.v1+yaml"
+)
+
+// PushVerifiedStore stores the content of a VerifiedStore as OCI artifact
+// with the given tag in a namespace of an OCI repository (for example, an
+// OCI registry or a CTF). This can be used to share verified component
+// versions among several environments.
+// It returns the digest of the created artifact.
+func PushVerifiedStore(repo oci.Repository, namespace, tag string, s VerifiedStore) (string, error) {
+	data, err := EncodeVerifiedStore(s)
+	if err != nil {
+		return "", err
+	}
+
+	ns, err := repo.LookupNamespace(namespace)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot access namespace %q", namespace)
+	}
+	defer ns.Close()
+
+	art, err := ns.NewArtifact()
+	if err != nil {
+		return "", err
+	}
+	defer art.Close()
+
+	m := art.ManifestAccess()
+	err = m.SetConfigBlob(blobaccess.ForString(VerifiedStoreConfigMimeType, "{}"), nil)
+	if err != nil {
+		return "", err
+	}
+	_, err = m.AddLayer(blobaccess.ForData(VerifiedStoreLayerMimeType, data), nil)
+	if err != nil {
+		return "", err
+	}
+	blob, err := ns.AddArtifact(art, tag)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot store verified store artifact in %s:%s", namespace, tag)
+	}
+	defer blob.Close()
+	return blob.Digest().String(), nil
+}
+
+// PullVerifiedStore reads a VerifiedStore stored with PushVerifiedStore
+// from an OCI repository. The version may be a tag or a digest.
+// The result is a memory based store, which can be merged
+// into other stores with MergeVerifiedStores. Its entries are not
+// authenticated; use VerifyStoreEntries before merging it
+// into a trusted store.
+func PullVerifiedStore(repo oci.Repository, namespace, version string) (VerifiedStore, error) {
+	art, err := repo.LookupArtifact(namespace, version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot access verified store artifact %s:%s", namespace, version)
+	}
+	defer art.Close()
+
+	m := art.ManifestAccess()
+	if m == nil {
+		return nil, fmt.Errorf("verified store artifact is no manifest artifact")
+	}
+	if m.GetDescriptor().Config.MediaType != VerifiedStoreConfigMimeType {
+		return nil, fmt.Errorf("artifact %s:%s has unexpected mime type %q", namespace, version, m.GetDescriptor().Config.MediaType)
+	}
+	for _, l := range m.GetDescriptor().Layers {
+		if l.MediaType == VerifiedStoreLayerMimeType {
+			blob, err := m.GetBlob(l.Digest)
+			if err != nil {
+				return nil, err
+			}
+			data, err := blob.Get()
+			blob.Close()
+			if err != nil {
+				return nil, err
+			}
+			return DecodeVerifiedStore(data)
+		}
+	}
+	return nil, fmt.Errorf("artifact %s:%s contains no verified store", namespace, version)
+}
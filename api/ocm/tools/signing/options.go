@@ -8,8 +8,11 @@ import (
 	"github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/goutils/general"
 	"github.com/mandelsoft/goutils/generics"
+	"github.com/mandelsoft/goutils/sliceutils"
+	"github.com/mandelsoft/vfs/pkg/vfs"
 
 	"ocm.software/ocm/api/datacontext/attrs/rootcertsattr"
+	"ocm.software/ocm/api/datacontext/attrs/vfsattr"
 	"ocm.software/ocm/api/ocm"
 	"ocm.software/ocm/api/ocm/compdesc"
 	"ocm.software/ocm/api/ocm/extensions/attrs/signingattr"
@@ -17,6 +20,7 @@ import (
 	"ocm.software/ocm/api/tech/signing"
 	"ocm.software/ocm/api/tech/signing/hasher/sha256"
 	"ocm.software/ocm/api/tech/signing/signutils"
+	"ocm.software/ocm/api/tech/signing/tsa"
 	"ocm.software/ocm/api/utils"
 	common "ocm.software/ocm/api/utils/misc"
 )
@@ -430,6 +434,53 @@ func (o *tsaOpt) ApplySigningOption(opts *Options) {
 	}
 }
 
+type tsaClientOpt struct {
+	client tsa.Client
+}
+
+// TimestampAuthority enables the usage of a dedicated timestamp authority,
+// for example a local one (see tsa.NewLocalAuthority).
+func TimestampAuthority(c tsa.Client) Option {
+	return &tsaClientOpt{c}
+}
+
+func (o *tsaClientOpt) ApplySigningOption(opts *Options) {
+	opts.TSA = o.client
+	opts.UseTSA = o.client != nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type tsaRootCerts struct {
+	pool signutils.GenericCertificatePool
+}
+
+// TSARootCertificates provides root certificates used to verify
+// timestamps of signatures without network access.
+func TSARootCertificates(pool signutils.GenericCertificatePool) Option {
+	return &tsaRootCerts{pool}
+}
+
+func (o *tsaRootCerts) ApplySigningOption(opts *Options) {
+	opts.TSARootCerts = o.pool
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type requireTimestamp struct {
+	flag bool
+}
+
+// RequireTimestamp requests signatures based on certificates to be
+// timestamped before the signing certificate expired.
+func RequireTimestamp(flag ...bool) Option {
+	return &requireTimestamp{utils.GetOptionFlag(flag...)}
+}
+
+func (o *requireTimestamp) ApplySigningOption(opts *Options) {
+	opts.RequireTimestamp = o.flag
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 type verifyedstore struct {
@@ -448,6 +499,38 @@ func (o *verifyedstore) ApplySigningOption(opts *Options) {
 
 ////////////////////////////////////////////////////////////////////////////////
 
+type trustpolicy struct {
+	policy *TrustPolicy
+}
+
+// UseTrustPolicy configures a trust policy, which has to be fulfilled
+// by all verified component versions.
+func UseTrustPolicy(p *TrustPolicy) Option {
+	return &trustpolicy{p}
+}
+
+func (o *trustpolicy) ApplySigningOption(opts *Options) {
+	opts.TrustPolicy = o.policy
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type threshold struct {
+	count int
+}
+
+// Threshold requires at least count of the configured signatures
+// to be verifiable (M-of-N verification) instead of all of them.
+func Threshold(count int) Option {
+	return &threshold{count}
+}
+
+func (o *threshold) ApplySigningOption(opts *Options) {
+	opts.Threshold = o.count
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
 type Options struct {
 	Printer           common.Printer
 	Update            bool
@@ -470,10 +553,23 @@ type Options struct {
 	Keyless           bool
 	TSAUrl            string
 	UseTSA            bool
+	TSA               tsa.Client
+	TSARootCerts      signutils.GenericCertificatePool
+
+	// RequireTimestamp requires signatures based on certificates to be
+	// timestamped before the signing certificate expired.
+	RequireTimestamp bool
 
 	effectiveRegistry signing.Registry
+	fs                vfs.FileSystem
 
 	VerifiedStore VerifiedStore
+	TrustPolicy   *TrustPolicy
+
+	// Threshold is the minimum number of the configured signatures
+	// required to be verifiable. If zero, all configured signatures
+	// must be verifiable.
+	Threshold int
 }
 
 var _ Option = (*Options)(nil)
@@ -541,6 +637,21 @@ func (o *Options) ApplySigningOption(opts *Options) {
 	if o.UseTSA {
 		opts.UseTSA = o.UseTSA
 	}
+	if o.TSA != nil {
+		opts.TSA = o.TSA
+	}
+	if o.TSARootCerts != nil {
+		opts.TSARootCerts = o.TSARootCerts
+	}
+	if o.RequireTimestamp {
+		opts.RequireTimestamp = o.RequireTimestamp
+	}
+	if o.TrustPolicy != nil {
+		opts.TrustPolicy = o.TrustPolicy
+	}
+	if o.Threshold != 0 {
+		opts.Threshold = o.Threshold
+	}
 }
 
 // Complete takes either nil, an ocm.ContextProvider or a signing.Registry.
@@ -567,6 +678,7 @@ func (o *Options) Complete(ctx interface{}) error {
 	}
 
 	o.Printer = common.AssurePrinter(o.Printer)
+	o.fs = vfsattr.Get(ocmctx)
 
 	if o.Registry == nil {
 		o.Registry = reg
@@ -591,6 +703,17 @@ func (o *Options) Complete(ctx interface{}) error {
 		o.RootCerts = pool
 	}
 
+	if o.TSARootCerts == nil {
+		o.TSARootCerts = o.effectiveRegistry.TSARootCerts()
+	}
+	if o.TSARootCerts != nil {
+		pool, err := signutils.GetCertPool(o.TSARootCerts, false)
+		if err != nil {
+			return errors.Wrapf(err, "TSA root certificates")
+		}
+		o.TSARootCerts = pool
+	}
+
 	if o.SkipAccessTypes == nil {
 		o.SkipAccessTypes = map[string]bool{}
 	}
@@ -616,6 +739,14 @@ func (o *Options) Complete(ctx interface{}) error {
 	if o.DigestMode == "" {
 		o.DigestMode = DIGESTMODE_LOCAL
 	}
+	// a signature name must not be counted twice for a threshold verification.
+	o.SignatureNames = sliceutils.AppendUnique([]string(nil), o.SignatureNames...)
+	if o.Threshold < 0 {
+		return errors.Newf("invalid signature threshold %d", o.Threshold)
+	}
+	if o.Threshold > 0 && o.Threshold > len(o.SignatureNames) {
+		return errors.Newf("signature threshold %d exceeds number of configured signatures (%d)", o.Threshold, len(o.SignatureNames))
+	}
 	if !o.Keyless {
 		if o.Signer != nil && !o.VerifySignature {
 			if pub := o.PublicKey(o.SignatureName()); pub != nil {
@@ -739,6 +870,24 @@ func (o *Options) EffectiveTSAUrl() string {
 	return ""
 }
 
+// EffectiveTSA provides the timestamp authority to use, if TSA mode
+// is enabled. The TSA URL may be the name of a timestamp authority
+// registered at the signing registry. Local authorities are read from
+// the filesystem of the context used to complete the options.
+func (o *Options) EffectiveTSA() (tsa.Client, error) {
+	if !o.UseTSA {
+		return nil, nil
+	}
+	if o.TSA != nil {
+		return o.TSA, nil
+	}
+	url := o.EffectiveTSAUrl()
+	if c := o.effectiveRegistry.GetTimestampAuthority(url); c != nil {
+		return c, nil
+	}
+	return tsa.NewClient(url, o.fs)
+}
+
 func (o *Options) Dup() *Options {
 	opts := *o
 	return &opts
@@ -0,0 +1,408 @@
+package signing
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/signutils"
+	"ocm.software/ocm/api/utils"
+	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+const (
+	KIND_TRUST_POLICY      = "trust policy"
+	KIND_TRUST_POLICY_RULE = "trust policy rule"
+)
+
+// TrustPolicy describes the signature requirements for component versions.
+// Every rule applies to the component versions whose component name matches
+// one of its component patterns. A component version must fulfill all
+// rules applying to it. If Strict is set, component versions not covered
+// by any rule are rejected.
+type TrustPolicy struct {
+	Strict bool        `json:"strict,omitempty"`
+	Rules  []TrustRule `json:"rules"`
+}
+
+// TrustRule describes the signature requirements for a set of components.
+// Components is a list of glob patterns for component names, where
+// * matches any sequence of characters (including /) and ? a single character.
+//
+// A signature qualifies for a rule, if it can be verified and
+//   - it is listed in Signatures (if given)
+//   - the subject of the certificate used to verify it matches one of the
+//     Issuers (if given). Issuers are distinguished names, where the
+//     given fields must match the certificate subject.
+//   - it has a valid timestamp (if RequireTimestamp is set)
+//   - its digest uses one of the DigestAlgorithms (if given)
+//
+// All signatures listed in Signatures must qualify and the number of
+// qualifying signatures must be at least MinSignatures (default 1).
+type TrustRule struct {
+	Name             string   `json:"name"`
+	Components       []string `json:"components"`
+	Signatures       []string `json:"signatures,omitempty"`
+	Issuers          []string `json:"issuers,omitempty"`
+	MinSignatures    int      `json:"minSignatures,omitempty"`
+	RequireTimestamp bool     `json:"requireTimestamp,omitempty"`
+	DigestAlgorithms []string `json:"digestAlgorithms,omitempty"`
+}
+
+// ParseTrustPolicy parses and validates a YAML or JSON trust policy document.
+func ParseTrustPolicy(data []byte) (*TrustPolicy, error) {
+	var p TrustPolicy
+	err := runtime.DefaultYAMLEncoding.Unmarshal(data, &p)
+	if err != nil {
+		return nil, errors.ErrInvalidWrap(err, KIND_TRUST_POLICY)
+	}
+	err = p.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// LoadTrustPolicy reads a trust policy from a file.
+func LoadTrustPolicy(path string, fss ...vfs.FileSystem) (*TrustPolicy, error) {
+	eff, err := utils.ResolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := vfs.ReadFile(utils.FileSystem(fss...), eff)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read %s %q", KIND_TRUST_POLICY, path)
+	}
+	p, err := ParseTrustPolicy(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", path)
+	}
+	return p, nil
+}
+
+// Validate checks the consistency of a trust policy.
+func (p *TrustPolicy) Validate() error {
+	list := errors.ErrListf("invalid %s", KIND_TRUST_POLICY)
+	names := map[string]bool{}
+	for i, r := range p.Rules {
+		if r.Name == "" {
+			list.Add(fmt.Errorf("rule %d: name required", i+1))
+			continue
+		}
+		if names[r.Name] {
+			list.Add(fmt.Errorf("rule %q: duplicate rule name", r.Name))
+		}
+		names[r.Name] = true
+		list.Add(r.validate())
+	}
+	return list.Result()
+}
+
+func (r *TrustRule) validate() error {
+	if len(r.Components) == 0 {
+		return fmt.Errorf("rule %q: at least one component pattern required", r.Name)
+	}
+	for _, c := range r.Components {
+		if c == "" {
+			return fmt.Errorf("rule %q: empty component pattern", r.Name)
+		}
+	}
+	for _, i := range r.Issuers {
+		if _, err := signutils.ParseDN(i); err != nil {
+			return errors.Wrapf(err, "rule %q: issuer %q", r.Name, i)
+		}
+	}
+	if r.MinSignatures < 0 {
+		return fmt.Errorf("rule %q: negative minimum number of signatures", r.Name)
+	}
+	return nil
+}
+
+// Matches checks whether the rule applies to the given component name.
+func (r *TrustRule) Matches(component string) bool {
+	for _, c := range r.Components {
+		if matchGlob(c, component) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchGlob(pattern, name string) bool {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, `.*`)
+	expr = strings.ReplaceAll(expr, `\?`, `.`)
+	ok, err := regexp.MatchString("^"+expr+"$", name)
+	return ok && err == nil
+}
+
+// RulesFor provides the rules applying to the given component name.
+func (p *TrustPolicy) RulesFor(component string) []*TrustRule {
+	var rules []*TrustRule
+	for i := range p.Rules {
+		if p.Rules[i].Matches(component) {
+			rules = append(rules, &p.Rules[i])
+		}
+	}
+	return rules
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// TrustPolicyViolation describes the reasons a component version
+// does not fulfill a trust policy rule.
+type TrustPolicyViolation struct {
+	Component common.NameVersion
+	Rule      string
+	Reasons   []string
+}
+
+func (e *TrustPolicyViolation) Error() string {
+	if e.Rule == "" {
+		return fmt.Sprintf("component version %q violates %s: %s", e.Component, KIND_TRUST_POLICY, strings.Join(e.Reasons, "; "))
+	}
+	return fmt.Sprintf("component version %q violates %s %q: %s", e.Component, KIND_TRUST_POLICY_RULE, e.Rule, strings.Join(e.Reasons, "; "))
+}
+
+// IsTrustPolicyViolation checks whether the given error is or contains
+// a trust policy violation.
+func IsTrustPolicyViolation(err error) bool {
+	var v *TrustPolicyViolation
+	return errors.As(err, &v)
+}
+
+// signatureInfo keeps the verification result for a signature.
+type signatureInfo struct {
+	sig     *compdesc.Signature
+	err     error
+	subject *pkix.Name
+	tsa     bool
+}
+
+// Evaluate checks the signatures of a component descriptor against the
+// policy. The given digests must provide the digest information
+// for the component descriptor the signatures have been created for.
+// For every violated rule a TrustPolicyViolation is reported.
+func (p *TrustPolicy) Evaluate(digests *compdesc.CompDescDigests, opts *Options) error {
+	cd := digests.Descriptor()
+	nv := common.VersionedElementKey(cd)
+
+	rules := p.RulesFor(cd.GetName())
+	if len(rules) == 0 {
+		if p.Strict {
+			return &TrustPolicyViolation{Component: nv, Reasons: []string{"no rule found for component"}}
+		}
+		return nil
+	}
+
+	infos := map[string]*signatureInfo{}
+	for i := range cd.Signatures {
+		sig := &cd.Signatures[i]
+		infos[sig.Name] = verifySignatureForPolicy(digests, sig, opts)
+	}
+
+	list := errors.ErrListf("%s", KIND_TRUST_POLICY)
+	for _, r := range rules {
+		if v := r.evaluate(nv, infos); v != nil {
+			list.Add(v)
+		}
+	}
+	return list.Result()
+}
+
+func (r *TrustRule) evaluate(nv common.NameVersion, infos map[string]*signatureInfo) *TrustPolicyViolation {
+	var reasons []string
+
+	for _, n := range r.Signatures {
+		info := infos[n]
+		if info == nil {
+			reasons = append(reasons, fmt.Sprintf("required signature %q not found", n))
+			continue
+		}
+		if msg := r.disqualifies(info); msg != "" {
+			reasons = append(reasons, fmt.Sprintf("required signature %q %s", n, msg))
+		}
+	}
+
+	count := 0
+	for _, info := range infos {
+		if len(r.Signatures) > 0 && !slices.Contains(r.Signatures, info.sig.Name) {
+			continue
+		}
+		if r.disqualifies(info) == "" {
+			count++
+		}
+	}
+	min := r.MinSignatures
+	if min == 0 {
+		min = 1
+	}
+	if count < min {
+		reasons = append(reasons, fmt.Sprintf("%d qualifying signature(s) found, but %d required", count, min))
+	}
+
+	if len(reasons) == 0 {
+		return nil
+	}
+	return &TrustPolicyViolation{Component: nv, Rule: r.Name, Reasons: reasons}
+}
+
+// disqualifies provides the reason, why a signature does not qualify for
+// a rule, or an empty string, if it qualifies.
+func (r *TrustRule) disqualifies(info *signatureInfo) string {
+	if info.err != nil {
+		return fmt.Sprintf("cannot be verified: %s", info.err)
+	}
+	if !r.acceptsDigest(info.sig.Digest.HashAlgorithm) {
+		return fmt.Sprintf("uses disallowed digest algorithm %q", info.sig.Digest.HashAlgorithm)
+	}
+	if r.RequireTimestamp && !info.tsa {
+		return "has no timestamp"
+	}
+	if len(r.Issuers) > 0 {
+		if info.subject == nil {
+			return "is not based on a certificate"
+		}
+		found := false
+		for _, i := range r.Issuers {
+			dn, err := signutils.ParseDN(i)
+			if err == nil && signutils.MatchDN(*info.subject, *dn) == nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("issued by disallowed subject %q", signutils.DNAsString(*info.subject))
+		}
+	}
+	return ""
+}
+
+// acceptsDigest checks whether the rule accepts the given
+// digest algorithm.
+func (r *TrustRule) acceptsDigest(algo string) bool {
+	if len(r.DigestAlgorithms) == 0 {
+		return true
+	}
+	algo = signing.NormalizeHashAlgorithm(algo)
+	for _, a := range r.DigestAlgorithms {
+		if signing.NormalizeHashAlgorithm(a) == algo {
+			return true
+		}
+	}
+	return false
+}
+
+// DigestFor provides the signature digest determining the digest context
+// used to evaluate the policy for a component descriptor. The first
+// signature using a digest algorithm accepted by all rules applying
+// to the component is preferred. If there is none, the first signature
+// is used, nil is returned for an unsigned component descriptor.
+func (p *TrustPolicy) DigestFor(cd *compdesc.ComponentDescriptor) *metav1.DigestSpec {
+	if len(cd.Signatures) == 0 {
+		return nil
+	}
+	rules := p.RulesFor(cd.GetName())
+outer:
+	for i := range cd.Signatures {
+		for _, r := range rules {
+			if !r.acceptsDigest(cd.Signatures[i].Digest.HashAlgorithm) {
+				continue outer
+			}
+		}
+		return &cd.Signatures[i].Digest
+	}
+	return &cd.Signatures[0].Digest
+}
+
+// verifySignatureForPolicy verifies a signature and determines the
+// information required to evaluate policy rules. Public keys are taken from
+// the options or from a certificate provided by the signature.
+func verifySignatureForPolicy(digests *compdesc.CompDescDigests, sig *compdesc.Signature, opts *Options) *signatureInfo {
+	info := &signatureInfo{sig: sig}
+
+	hasher := opts.Registry.GetHasher(sig.Digest.HashAlgorithm)
+	if hasher == nil {
+		info.err = errors.ErrUnknown(compdesc.KIND_HASH_ALGORITHM, sig.Digest.HashAlgorithm)
+		return info
+	}
+	_, digest, err := digests.Get(sig.Digest.NormalisationAlgorithm, hasher)
+	if err != nil {
+		info.err = errors.Wrapf(err, "failed hashing component descriptor")
+		return info
+	}
+	if sig.Digest.Value != digest {
+		info.err = errors.Newf("signature digest (%s) does not match found digest (%s)", sig.Digest.Value, digest)
+		return info
+	}
+	return verifySignatureValue(sig, opts)
+}
+
+// verifySignatureValue verifies the signature value and an optional timestamp
+// of a signature for the digest stated by the signature.
+func verifySignatureValue(sig *compdesc.Signature, opts *Options) *signatureInfo {
+	var err error
+
+	info := &signatureInfo{sig: sig}
+
+	hasher := opts.Registry.GetHasher(sig.Digest.HashAlgorithm)
+	if hasher == nil {
+		info.err = errors.ErrUnknown(compdesc.KIND_HASH_ALGORITHM, sig.Digest.HashAlgorithm)
+		return info
+	}
+
+	verifier := opts.Registry.GetVerifier(sig.Signature.Algorithm)
+	if verifier == nil {
+		info.err = errors.ErrUnknown(compdesc.KIND_VERIFY_ALGORITHM, sig.Signature.Algorithm)
+		return info
+	}
+
+	sctx := &signing.DefaultSigningContext{
+		Hash:      hasher.Crypto(),
+		RootCerts: opts.RootCerts,
+		Issuer:    opts.IssuerFor(sig.Name),
+	}
+
+	var cert *x509.Certificate
+	if !opts.Keyless {
+		sctx.PublicKey = opts.PublicKey(sig.Name)
+		if sctx.PublicKey == nil {
+			sctx.PublicKey, err = GetPublicKeyFromSignature(sig, sctx, opts)
+			if err != nil {
+				info.err = errors.Wrapf(err, "public key from signature")
+				return info
+			}
+			_, _, certs, _ := signutils.GetSignatureFromPem([]byte(sig.Signature.Value))
+			cert, _, _ = signutils.GetCertificate(certs, false)
+		} else {
+			cert, _, _ = signutils.GetCertificate(sctx.PublicKey, false)
+		}
+	}
+
+	err = verifier.Verify(sig.Digest.Value, sig.ConvertToSigning(), sctx)
+	if err != nil {
+		info.err = err
+		return info
+	}
+	if cert != nil {
+		info.subject = &cert.Subject
+	}
+	if sig.Timestamp != nil {
+		if _, err := verifyTimestamp(sig, sctx, opts); err != nil {
+			info.err = err
+			return info
+		}
+		info.tsa = true
+	}
+	return info
+}
@@ -0,0 +1,338 @@
+package signing_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/helper/builder"
+	. "ocm.software/ocm/api/ocm/testhelper"
+	. "ocm.software/ocm/api/ocm/tools/signing"
+
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/ocm/tools/transfer"
+	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler/standard"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/tech/signing/hasher/sha256"
+	"ocm.software/ocm/api/tech/signing/hasher/sha512"
+	"ocm.software/ocm/api/tech/signing/signutils"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+)
+
+const POLICY_TARGET = "/tmp/target"
+
+var _ = Describe("trust policy", func() {
+	Context("parsing", func() {
+		It("parses policy", func() {
+			p := Must(ParseTrustPolicy([]byte(`
+strict: true
+rules:
+  - name: mandelsoft
+    components:
+      - github.com/mandelsoft/*
+    signatures:
+      - test
+    issuers:
+      - CN=mandelsoft,O=acme
+    minSignatures: 2
+    requireTimestamp: true
+    digestAlgorithms:
+      - SHA-256
+`)))
+			Expect(p).To(Equal(&TrustPolicy{
+				Strict: true,
+				Rules: []TrustRule{
+					{
+						Name:             "mandelsoft",
+						Components:       []string{"github.com/mandelsoft/*"},
+						Signatures:       []string{"test"},
+						Issuers:          []string{"CN=mandelsoft,O=acme"},
+						MinSignatures:    2,
+						RequireTimestamp: true,
+						DigestAlgorithms: []string{"SHA-256"},
+					},
+				},
+			}))
+		})
+
+		It("rejects invalid rules", func() {
+			ExpectError(ParseTrustPolicy([]byte(`
+rules:
+  - components:
+      - acme.org/*
+  - name: a
+    components:
+      - acme.org/*
+  - name: a
+`))).To(MatchError(`invalid trust policy: {rule 1: name required, rule "a": duplicate rule name, rule "a": at least one component pattern required}`))
+		})
+
+		It("matches component names", func() {
+			r := &TrustRule{Name: "r", Components: []string{"acme.org/*", "other.org/comp?"}}
+			Expect(r.Matches("acme.org/a/b")).To(BeTrue())
+			Expect(r.Matches("acme.org")).To(BeFalse())
+			Expect(r.Matches("other.org/comp1")).To(BeTrue())
+			Expect(r.Matches("other.org/comp12")).To(BeFalse())
+		})
+	})
+
+	Context("digest selection", func() {
+		cd := compdesc.New(COMPONENTA, VERSION)
+		cd.Signatures = []compdesc.Signature{
+			{Name: SIGNATURE, Digest: metav1.DigestSpec{HashAlgorithm: sha256.Algorithm, NormalisationAlgorithm: compdesc.JsonNormalisationV3, Value: "256"}},
+			{Name: SIGNATURE2, Digest: metav1.DigestSpec{HashAlgorithm: sha512.Algorithm, NormalisationAlgorithm: compdesc.JsonNormalisationV3, Value: "512"}},
+		}
+
+		It("prefers digests accepted by the policy", func() {
+			policy := &TrustPolicy{
+				Rules: []TrustRule{
+					{Name: "other", Components: []string{COMPONENTB}, DigestAlgorithms: []string{sha256.Algorithm}},
+					{Name: "sha512", Components: []string{COMPONENTA}, DigestAlgorithms: []string{sha512.Algorithm}},
+				},
+			}
+			Expect(policy.DigestFor(cd)).To(Equal(&cd.Signatures[1].Digest))
+		})
+
+		It("falls back to first signature", func() {
+			Expect((&TrustPolicy{}).DigestFor(cd)).To(Equal(&cd.Signatures[0].Digest))
+			policy := &TrustPolicy{
+				Rules: []TrustRule{
+					{Name: "md5", Components: []string{COMPONENTA}, DigestAlgorithms: []string{"MD5"}},
+				},
+			}
+			Expect(policy.DigestFor(cd)).To(Equal(&cd.Signatures[0].Digest))
+			Expect(policy.DigestFor(compdesc.New(COMPONENTA, VERSION))).To(BeNil())
+		})
+	})
+
+	Context("evaluation", func() {
+		var env *Builder
+
+		BeforeEach(func() {
+			env = NewBuilder()
+			env.RSAKeyPair(SIGNATURE, SIGNATURE2)
+
+			env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+				env.Component(COMPONENTA, func() {
+					env.Version(VERSION, func() {
+						env.Provider(PROVIDER)
+						TestDataResource(env)
+					})
+				})
+				env.Component(COMPONENTB, func() {
+					env.Version(VERSION, func() {
+						env.Provider(PROVIDER)
+						OtherDataResource(env)
+						env.Reference("ref", COMPONENTA, VERSION)
+					})
+				})
+			})
+
+			src := Must(ctf.Open(env, accessobj.ACC_WRITABLE, ARCH, 0, env))
+			defer Close(src, "source")
+			cv := Must(src.LookupComponentVersion(COMPONENTB, VERSION))
+			defer Close(cv, "version")
+			Must(SignComponentVersion(cv, SIGNATURE, Resolver(src)))
+		})
+
+		AfterEach(func() {
+			env.Cleanup()
+		})
+
+		check := func(policy *TrustPolicy, opts ...Option) error {
+			src := Must(ctf.Open(env, accessobj.ACC_READONLY, ARCH, 0, env))
+			defer Close(src, "source")
+			cv := Must(src.LookupComponentVersion(COMPONENTB, VERSION))
+			defer Close(cv, "version")
+			return CheckTrustPolicy(cv, policy, append([]Option{Resolver(src)}, opts...)...)
+		}
+
+		It("accepts fulfilled policy", func() {
+			MustBeSuccessful(check(&TrustPolicy{
+				Rules: []TrustRule{
+					{
+						Name:             "all",
+						Components:       []string{"github.com/mandelsoft/*"},
+						Signatures:       []string{SIGNATURE},
+						DigestAlgorithms: []string{"SHA-256"},
+					},
+				},
+			}))
+		})
+
+		It("ignores unmatched components", func() {
+			MustBeSuccessful(check(&TrustPolicy{
+				Rules: []TrustRule{
+					{Name: "other", Components: []string{"acme.org/*"}, Signatures: []string{SIGNATURE2}},
+				},
+			}))
+		})
+
+		It("rejects unmatched components in strict mode", func() {
+			err := check(&TrustPolicy{
+				Strict: true,
+				Rules: []TrustRule{
+					{Name: "other", Components: []string{"acme.org/*"}},
+				},
+			})
+			Expect(IsTrustPolicyViolation(err)).To(BeTrue())
+			Expect(err).To(MatchError(`github.com/mandelsoft/ref:v1: failed applying to component reference ref[github.com/mandelsoft/test:v1]: ` +
+				`github.com/mandelsoft/ref:v1->github.com/mandelsoft/test:v1: component version "github.com/mandelsoft/test:v1" violates trust policy: no rule found for component`))
+		})
+
+		It("reports all violated rules", func() {
+			err := check(&TrustPolicy{
+				Rules: []TrustRule{
+					{Name: "names", Components: []string{COMPONENTB}, Signatures: []string{SIGNATURE, SIGNATURE2}},
+					{Name: "count", Components: []string{COMPONENTB}, MinSignatures: 2},
+					{Name: "constraints", Components: []string{COMPONENTB}, RequireTimestamp: true, Issuers: []string{"CN=mandelsoft"}, DigestAlgorithms: []string{"SHA-512"}},
+				},
+			})
+			Expect(IsTrustPolicyViolation(err)).To(BeTrue())
+			Expect(err).To(MatchError(`github.com/mandelsoft/ref:v1: trust policy: {` +
+				`component version "github.com/mandelsoft/ref:v1" violates trust policy rule "names": required signature "second" not found, ` +
+				`component version "github.com/mandelsoft/ref:v1" violates trust policy rule "count": 1 qualifying signature(s) found, but 2 required, ` +
+				`component version "github.com/mandelsoft/ref:v1" violates trust policy rule "constraints": 0 qualifying signature(s) found, but 1 required}`))
+		})
+
+		It("reports disqualified required signatures", func() {
+			err := check(&TrustPolicy{
+				Rules: []TrustRule{
+					{Name: "tsa", Components: []string{COMPONENTB}, Signatures: []string{SIGNATURE}, RequireTimestamp: true},
+				},
+			})
+			Expect(err).To(MatchError(`github.com/mandelsoft/ref:v1: trust policy: component version "github.com/mandelsoft/ref:v1" violates trust policy rule "tsa": required signature "test" has no timestamp; 0 qualifying signature(s) found, but 1 required`))
+		})
+
+		It("evaluates referenced component versions", func() {
+			err := check(&TrustPolicy{
+				Rules: []TrustRule{
+					{Name: "nested", Components: []string{COMPONENTA}, Signatures: []string{SIGNATURE2}},
+				},
+			})
+			Expect(err).To(MatchError(ContainSubstring(`component version "github.com/mandelsoft/test:v1" violates trust policy rule "nested": required signature "second" not found; 0 qualifying signature(s) found, but 1 required`)))
+		})
+
+		It("checks covered component versions once", func() {
+			src := Must(ctf.Open(env, accessobj.ACC_READONLY, ARCH, 0, env))
+			defer Close(src, "source")
+			cvb := Must(src.LookupComponentVersion(COMPONENTB, VERSION))
+			defer Close(cvb, "version")
+			cva := Must(src.LookupComponentVersion(COMPONENTA, VERSION))
+			defer Close(cva, "version")
+
+			policy := &TrustPolicy{
+				Rules: []TrustRule{
+					{Name: "root", Components: []string{COMPONENTB}, Signatures: []string{SIGNATURE}},
+				},
+			}
+			checker := NewTrustPolicyChecker(policy)
+			MustBeSuccessful(checker.Check(cvb, Resolver(src)))
+
+			// the referenced version is already covered by the check of the root
+			policy.Rules = append(policy.Rules, TrustRule{Name: "nested", Components: []string{COMPONENTA}, Signatures: []string{SIGNATURE2}})
+			MustBeSuccessful(checker.Check(cva, Resolver(src)))
+			Expect(NewTrustPolicyChecker(policy).Check(cva, Resolver(src))).To(MatchError(ContainSubstring(`violates trust policy rule "nested"`)))
+		})
+
+		It("rejects transfer of untrusted component versions", func() {
+			src := Must(ctf.Open(env, accessobj.ACC_READONLY, ARCH, 0, env))
+			defer Close(src, "source")
+			cv := Must(src.LookupComponentVersion(COMPONENTB, VERSION))
+			defer Close(cv, "version")
+			tgt := Must(ctf.Create(env, accessobj.ACC_WRITABLE|accessobj.ACC_CREATE, POLICY_TARGET, 0o700, accessio.FormatDirectory, env))
+			defer Close(tgt, "target")
+
+			policy := &TrustPolicy{
+				Rules: []TrustRule{
+					{Name: "names", Components: []string{COMPONENTB}, Signatures: []string{SIGNATURE2}},
+				},
+			}
+			handler := Must(standard.New(standard.TrustPolicy(policy)))
+			err := transfer.TransferVersion(nil, nil, cv, tgt, handler)
+			Expect(IsTrustPolicyViolation(err)).To(BeTrue())
+			ok, _ := tgt.ExistsComponentVersion(COMPONENTB, VERSION)
+			Expect(ok).To(BeFalse())
+
+			policy.Rules[0].Signatures = []string{SIGNATURE}
+			MustBeSuccessful(transfer.TransferVersion(nil, nil, cv, tgt, handler))
+			Expect(Must(tgt.ExistsComponentVersion(COMPONENTB, VERSION))).To(BeTrue())
+		})
+	})
+
+	Context("certificates", func() {
+		var env *Builder
+		var pool *x509.CertPool
+
+		BeforeEach(func() {
+			env = NewBuilder()
+
+			capriv, capub := Must2(rsa.Handler{}.CreateKeyPair())
+			spec := &signutils.Specification{
+				IsCA:         true,
+				PublicKey:    capub,
+				CAPrivateKey: capriv,
+				Subject:      pkix.Name{CommonName: "ca-authority"},
+				Usages:       signutils.Usages{x509.ExtKeyUsageCodeSigning},
+				Validity:     10 * time.Hour,
+			}
+			ca, _ := Must2(signutils.CreateCertificate(spec))
+
+			priv, pub := Must2(rsa.Handler{}.CreateKeyPair())
+			spec.Subject = pkix.Name{CommonName: NAME, Organization: []string{"acme"}}
+			spec.RootCAs = ca
+			spec.CAChain = ca
+			spec.PublicKey = pub
+			spec.IsCA = false
+			cert, _ := Must2(signutils.CreateCertificate(spec))
+
+			pool = x509.NewCertPool()
+			pool.AddCert(ca)
+
+			env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+				env.Component(COMPONENTA, func() {
+					env.Version(VERSION, func() {
+						env.Provider(PROVIDER)
+						TestDataResource(env)
+					})
+				})
+			})
+
+			src := Must(ctf.Open(env, accessobj.ACC_WRITABLE, ARCH, 0, env))
+			defer Close(src, "source")
+			cv := Must(src.LookupComponentVersion(COMPONENTA, VERSION))
+			defer Close(cv, "version")
+			Must(SignComponentVersion(cv, NAME, PrivateKey(NAME, priv), PublicKey(NAME, cert), RootCertificates(pool)))
+		})
+
+		AfterEach(func() {
+			env.Cleanup()
+		})
+
+		check := func(issuers ...string) error {
+			src := Must(ctf.Open(env, accessobj.ACC_READONLY, ARCH, 0, env))
+			defer Close(src, "source")
+			cv := Must(src.LookupComponentVersion(COMPONENTA, VERSION))
+			defer Close(cv, "version")
+			return CheckTrustPolicy(cv, &TrustPolicy{
+				Rules: []TrustRule{
+					{Name: "issuer", Components: []string{COMPONENTA}, Issuers: issuers},
+				},
+			}, RootCertificates(pool))
+		}
+
+		It("accepts matching certificate subject", func() {
+			MustBeSuccessful(check("O=acme", "CN=other"))
+		})
+
+		It("rejects other certificate subject", func() {
+			Expect(check("CN=other")).To(MatchError(`github.com/mandelsoft/test:v1: trust policy: component version "github.com/mandelsoft/test:v1" violates trust policy rule "issuer": 0 qualifying signature(s) found, but 1 required`))
+		})
+	})
+})
@@ -0,0 +1,263 @@
+package signing
+
+import (
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/ocm"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/extensions/attrs/signingattr"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/utils"
+	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+const KIND_SIGNING_REQUEST = "signing request"
+
+// SigningRequest describes a detached signing request for a component version.
+// It contains the digest of the normalized component descriptor, which can be
+// signed offline by different parties without access to the component
+// version. The gathered signatures can later be added to the component version
+// with AddSignatures.
+type SigningRequest struct {
+	Component  string            `json:"component"`
+	Version    string            `json:"version"`
+	Digest     metav1.DigestSpec `json:"digest"`
+	Signatures metav1.Signatures `json:"signatures,omitempty"`
+}
+
+// GetName returns the component name of the request.
+func (r *SigningRequest) GetName() string {
+	return r.Component
+}
+
+// GetVersion returns the component version of the request.
+func (r *SigningRequest) GetVersion() string {
+	return r.Version
+}
+
+// GetSignatureIndex returns the index of the signature with the given name
+// or -1, if it is not present.
+func (r *SigningRequest) GetSignatureIndex(name string) int {
+	for i, s := range r.Signatures {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetSignature adds a signature to the request. An existing signature
+// with the same name is replaced.
+func (r *SigningRequest) SetSignature(sig metav1.Signature) {
+	if i := r.GetSignatureIndex(sig.Name); i >= 0 {
+		r.Signatures[i] = sig
+	} else {
+		r.Signatures = append(r.Signatures, sig)
+	}
+}
+
+// ParseSigningRequest parses a YAML or JSON signing request.
+func ParseSigningRequest(data []byte) (*SigningRequest, error) {
+	var r SigningRequest
+	err := runtime.DefaultYAMLEncoding.Unmarshal(data, &r)
+	if err != nil {
+		return nil, errors.ErrInvalidWrap(err, KIND_SIGNING_REQUEST)
+	}
+	if r.Component == "" || r.Version == "" {
+		return nil, errors.ErrInvalid(KIND_SIGNING_REQUEST, "component version missing")
+	}
+	if r.Digest.HashAlgorithm == "" || r.Digest.NormalisationAlgorithm == "" || r.Digest.Value == "" {
+		return nil, errors.ErrInvalid(KIND_SIGNING_REQUEST, "incomplete digest")
+	}
+	return &r, nil
+}
+
+// LoadSigningRequest reads a signing request from a file.
+func LoadSigningRequest(path string, fss ...vfs.FileSystem) (*SigningRequest, error) {
+	eff, err := utils.ResolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := vfs.ReadFile(utils.FileSystem(fss...), eff)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read %s %q", KIND_SIGNING_REQUEST, path)
+	}
+	r, err := ParseSigningRequest(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", path)
+	}
+	return r, nil
+}
+
+// SaveSigningRequest writes a signing request as YAML document to a file.
+func SaveSigningRequest(r *SigningRequest, path string, fss ...vfs.FileSystem) error {
+	eff, err := utils.ResolvePath(path)
+	if err != nil {
+		return err
+	}
+	data, err := runtime.DefaultYAMLEncoding.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return vfs.WriteFile(utils.FileSystem(fss...), eff, data, 0o644)
+}
+
+// CreateSigningRequest calculates the digest of a component version
+// (including the digests of its resources and references) and provides
+// a signing request for it. The digest is calculated with the hash and
+// normalization algorithm configured by the options. The component version
+// is not modified, as long as no Update option is given.
+func CreateSigningRequest(cv ocm.ComponentVersionAccess, optlist ...Option) (*SigningRequest, error) {
+	var opts Options
+
+	opts.Eval(
+		Recursive(),
+		VerifyDigests(),
+	)
+	opts.Eval(optlist...)
+
+	if opts.Signer != nil {
+		return nil, errors.Newf("impossible signer option set for signing request")
+	}
+	if opts.VerifySignature {
+		return nil, errors.Newf("impossible verification option set for signing request")
+	}
+	err := opts.Complete(cv.GetContext())
+	if err != nil {
+		return nil, errors.Wrapf(err, "inconsistent options for signing request")
+	}
+	spec, err := Apply(nil, nil, cv, &opts)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningRequest{
+		Component: cv.GetName(),
+		Version:   cv.GetVersion(),
+		Digest:    *spec,
+	}, nil
+}
+
+// SignSigningRequest signs the digest of a signing request with the
+// given signature name and adds the signature to the request. The
+// private key (and optionally the signer) is taken from the options.
+// This does not require access to the component version.
+func SignSigningRequest(ctx ocm.ContextProvider, req *SigningRequest, name string, optlist ...Option) error {
+	var opts Options
+
+	opts.Eval(SignatureName(name))
+	opts.Eval(optlist...)
+
+	if opts.VerifySignature {
+		return errors.Newf("impossible verification option set for signing")
+	}
+	if opts.Signer == nil && opts.SignAlgo == "" {
+		opts.Signer = signingattr.Get(ctx.OCMContext()).GetSigner(rsa.Algorithm)
+	}
+	if opts.Hasher == nil && opts.HashAlgo == "" {
+		opts.HashAlgo = req.Digest.HashAlgorithm
+	}
+	err := opts.Complete(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "inconsistent options for signing")
+	}
+	if signing.NormalizeHashAlgorithm(opts.Hasher.Algorithm()) != signing.NormalizeHashAlgorithm(req.Digest.HashAlgorithm) {
+		return errors.Newf("hash algorithm %q does not match digest of signing request (%s)", opts.Hasher.Algorithm(), req.Digest.HashAlgorithm)
+	}
+	sig, err := createSignature(ctx.OCMContext().CredentialsContext(), &req.Digest, &opts)
+	if err != nil {
+		return err
+	}
+	req.SetSignature(*sig)
+	return nil
+}
+
+// AddSignatures adds the signatures gathered by a signing request to the
+// component version the request has been created for. The digest of the
+// component version is recalculated with the algorithms of the request
+// and must still match the requested digest. Additionally, all signatures
+// must be verifiable with the public keys configured by the options or the
+// certificates provided by the signatures. Digests of resources and
+// references are updated in the component version like for signing.
+func AddSignatures(cv ocm.ComponentVersionAccess, req *SigningRequest, optlist ...Option) error {
+	nv := common.VersionedElementKey(cv)
+	if req.Component != cv.GetName() || req.Version != cv.GetVersion() {
+		return errors.Newf("%s for %s does not match component version %s", KIND_SIGNING_REQUEST, common.VersionedElementKey(req), nv)
+	}
+	if len(req.Signatures) == 0 {
+		return errors.Newf("no signatures found in %s for %s", KIND_SIGNING_REQUEST, nv)
+	}
+
+	var opts Options
+
+	opts.Eval(
+		Update(),
+		Recursive(),
+		VerifyDigests(),
+	)
+	opts.Eval(optlist...)
+
+	if opts.Signer != nil {
+		return errors.Newf("impossible signer option set for adding signatures")
+	}
+	if opts.VerifySignature {
+		return errors.Newf("impossible verification option set for adding signatures")
+	}
+	opts.Hasher = nil
+	opts.HashAlgo = req.Digest.HashAlgorithm
+	opts.NormalizationAlgo = req.Digest.NormalisationAlgorithm
+	err := opts.Complete(cv.GetContext())
+	if err != nil {
+		return errors.Wrapf(err, "inconsistent options for adding signatures")
+	}
+
+	for i := range req.Signatures {
+		sig := &req.Signatures[i]
+		if !sameDigest(&sig.Digest, &req.Digest) {
+			return errors.Newf("signature %q does not match digest of %s", sig.Name, KIND_SIGNING_REQUEST)
+		}
+		if info := verifySignatureValue(sig, &opts); info.err != nil {
+			return errors.Wrapf(info.err, "signature %q", sig.Name)
+		}
+	}
+
+	// the digest must be checked before anything is updated
+	update := opts.Update
+	opts.Update = false
+	spec, err := Apply(nil, nil, cv, &opts)
+	if err != nil {
+		return err
+	}
+	if spec.Value != req.Digest.Value {
+		return errors.Newf("component version %s has been modified: digest %s does not match %s digest %s", nv, spec.Value, KIND_SIGNING_REQUEST, req.Digest.Value)
+	}
+	if update {
+		opts.Update = true
+		_, err = Apply(nil, nil, cv, &opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	cd := cv.GetDescriptor()
+	for _, sig := range req.Signatures {
+		if i := cd.GetSignatureIndex(sig.Name); i >= 0 {
+			cd.Signatures[i] = sig
+		} else {
+			cd.Signatures = append(cd.Signatures, sig)
+		}
+	}
+	err = cv.Update()
+	if err != nil && !errors.Is(err, ocm.ErrTempVersion) {
+		return err
+	}
+	return nil
+}
+
+func sameDigest(a, b *metav1.DigestSpec) bool {
+	return signing.NormalizeHashAlgorithm(a.HashAlgorithm) == signing.NormalizeHashAlgorithm(b.HashAlgorithm) &&
+		a.NormalisationAlgorithm == b.NormalisationAlgorithm &&
+		a.Value == b.Value
+}
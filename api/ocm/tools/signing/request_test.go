@@ -0,0 +1,226 @@
+package signing_test
+
+import (
+	"fmt"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/helper/builder"
+	. "ocm.software/ocm/api/ocm/testhelper"
+	. "ocm.software/ocm/api/ocm/tools/signing"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/ocm"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/extensions/attrs/signingattr"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+)
+
+const SIGNATURE3 = "third"
+
+const ALGO_OPAQUE = "opaque-test"
+
+// opaqueKey is a public key without serialization.
+type opaqueKey struct{}
+
+// opaqueHandler accepts any key. Its keys cannot be identified.
+type opaqueHandler struct{}
+
+func (opaqueHandler) Algorithm() string {
+	return ALGO_OPAQUE
+}
+
+func (opaqueHandler) Sign(cctx credentials.Context, digest string, sctx signing.SigningContext) (*signing.Signature, error) {
+	return &signing.Signature{Value: digest, MediaType: "text/plain", Algorithm: ALGO_OPAQUE}, nil
+}
+
+func (opaqueHandler) Verify(digest string, sig *signing.Signature, sctx signing.SigningContext) error {
+	if sig.Value != digest {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+var _ = Describe("signing requests", func() {
+	var env *Builder
+
+	BeforeEach(func() {
+		env = NewBuilder()
+		env.RSAKeyPair(SIGNATURE)
+		env.RSAKeyPair(SIGNATURE2)
+		env.RSAKeyPair(SIGNATURE3)
+
+		env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+			env.Component(COMPONENTA, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+					TestDataResource(env)
+				})
+			})
+			env.Component(COMPONENTB, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+					OtherDataResource(env)
+					env.Reference("ref", COMPONENTA, VERSION)
+				})
+			})
+		})
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	withVersion := func(mode accessobj.AccessMode, f func(src ocm.Repository, cv ocm.ComponentVersionAccess)) {
+		src := Must(ctf.Open(env, mode, ARCH, 0, env))
+		defer Close(src, "source")
+		cv := Must(src.LookupComponentVersion(COMPONENTB, VERSION))
+		defer Close(cv, "version")
+		f(src, cv)
+	}
+
+	createRequest := func() *SigningRequest {
+		var req *SigningRequest
+		withVersion(accessobj.ACC_READONLY, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			req = Must(CreateSigningRequest(cv, Resolver(src)))
+			Expect(cv.GetDescriptor().Signatures).To(BeEmpty())
+		})
+		return req
+	}
+
+	It("signs component version offline", func() {
+		req := createRequest()
+		Expect(req.Component).To(Equal(COMPONENTB))
+		Expect(req.Version).To(Equal(VERSION))
+		Expect(req.Digest.HashAlgorithm).To(Equal("SHA-256"))
+
+		MustBeSuccessful(SaveSigningRequest(req, "/request.yaml", env))
+		req = Must(LoadSigningRequest("/request.yaml", env))
+
+		MustBeSuccessful(SignSigningRequest(env, req, SIGNATURE))
+		MustBeSuccessful(SignSigningRequest(env, req, SIGNATURE2))
+		Expect(req.Signatures).To(HaveLen(2))
+
+		withVersion(accessobj.ACC_WRITABLE, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			MustBeSuccessful(AddSignatures(cv, req, Resolver(src)))
+		})
+
+		withVersion(accessobj.ACC_READONLY, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			Expect(cv.GetDescriptor().Signatures).To(HaveLen(2))
+			Expect(cv.GetDescriptor().Signatures[0].Digest).To(Equal(req.Digest))
+			Must(VerifyComponentVersion(cv, SIGNATURE, Resolver(src)))
+			Must(VerifyComponentVersion(cv, SIGNATURE2, Resolver(src)))
+		})
+	})
+
+	It("rejects signatures for modified component version", func() {
+		req := createRequest()
+		MustBeSuccessful(SignSigningRequest(env, req, SIGNATURE))
+
+		withVersion(accessobj.ACC_WRITABLE, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			MustBeSuccessful(cv.GetDescriptor().Labels.Set("modified", true, metav1.WithSigning()))
+			MustBeSuccessful(cv.Update())
+		})
+		withVersion(accessobj.ACC_WRITABLE, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			Expect(AddSignatures(cv, req, Resolver(src))).To(MatchError(ContainSubstring("component version github.com/mandelsoft/ref:v1 has been modified")))
+			Expect(cv.GetDescriptor().Signatures).To(BeEmpty())
+		})
+		withVersion(accessobj.ACC_READONLY, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			Expect(cv.GetDescriptor().Signatures).To(BeEmpty())
+			Expect(cv.GetDescriptor().References[0].Digest).To(BeNil())
+		})
+	})
+
+	It("rejects unverifiable signatures", func() {
+		req := createRequest()
+		MustBeSuccessful(SignSigningRequest(env, req, SIGNATURE))
+
+		_, pub := Must2(rsa.Handler{}.CreateKeyPair())
+		withVersion(accessobj.ACC_WRITABLE, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			Expect(AddSignatures(cv, req, Resolver(src), PublicKey(SIGNATURE, pub))).To(MatchError(`signature "test": signature verification failed, crypto/rsa: verification error`))
+			Expect(cv.GetDescriptor().Signatures).To(BeEmpty())
+		})
+	})
+
+	It("counts signatures with the same public key once", func() {
+		reg := signingattr.Get(env.OCMContext())
+		priv, pub := reg.GetPrivateKey(SIGNATURE), reg.GetPublicKey(SIGNATURE)
+
+		req := createRequest()
+		MustBeSuccessful(SignSigningRequest(env, req, SIGNATURE))
+		MustBeSuccessful(SignSigningRequest(env, req, SIGNATURE3, PrivateKey(SIGNATURE3, priv), PublicKey(SIGNATURE3, pub)))
+		withVersion(accessobj.ACC_WRITABLE, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			MustBeSuccessful(AddSignatures(cv, req, Resolver(src), PublicKey(SIGNATURE3, pub)))
+		})
+		withVersion(accessobj.ACC_READONLY, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			_, err := VerifyComponentVersion(cv, SIGNATURE, Resolver(src), VerifySignature(SIGNATURE3), PublicKey(SIGNATURE3, pub), Threshold(2))
+			Expect(err).To(MatchError(`github.com/mandelsoft/ref:v1: 1 signature(s) with distinct keys verified, but 2 required`))
+		})
+	})
+
+	It("does not count signatures with unidentifiable public keys for a threshold", func() {
+		reg := signing.NewRegistry(signing.NewHandlerRegistry(signing.DefaultHandlerRegistry()), signing.NewKeyRegistry())
+		reg.RegisterSignatureHandler(opaqueHandler{})
+
+		withVersion(accessobj.ACC_WRITABLE, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			for _, n := range []string{SIGNATURE, SIGNATURE2} {
+				Must(SignComponentVersion(cv, n, Resolver(src), Registry(reg), Signer(opaqueHandler{}), PrivateKey(n, opaqueKey{}), PublicKey(n, opaqueKey{})))
+			}
+		})
+		withVersion(accessobj.ACC_READONLY, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			opts := []Option{Resolver(src), Registry(reg), PublicKey(SIGNATURE, opaqueKey{}), PublicKey(SIGNATURE2, opaqueKey{})}
+			Must(VerifyComponentVersion(cv, SIGNATURE, opts...))
+			_, err := VerifyComponentVersion(cv, SIGNATURE, append(opts, VerifySignature(SIGNATURE2), Threshold(2))...)
+			Expect(err).To(MatchError(`github.com/mandelsoft/ref:v1: 0 signature(s) with distinct keys verified, but 2 required`))
+		})
+	})
+
+	Context("threshold", func() {
+		BeforeEach(func() {
+			req := createRequest()
+			MustBeSuccessful(SignSigningRequest(env, req, SIGNATURE))
+			MustBeSuccessful(SignSigningRequest(env, req, SIGNATURE2))
+			withVersion(accessobj.ACC_WRITABLE, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+				MustBeSuccessful(AddSignatures(cv, req, Resolver(src)))
+			})
+		})
+
+		verify := func(threshold int, opts ...Option) error {
+			var err error
+			withVersion(accessobj.ACC_READONLY, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+				_, err = VerifyComponentVersion(cv, SIGNATURE, append([]Option{Resolver(src), VerifySignature(SIGNATURE2), VerifySignature(SIGNATURE3), Threshold(threshold)}, opts...)...)
+			})
+			return err
+		}
+
+		It("accepts 2 of 3 signatures", func() {
+			MustBeSuccessful(verify(2))
+		})
+
+		It("rejects 3 of 3 signatures", func() {
+			Expect(verify(3)).To(MatchError(`github.com/mandelsoft/ref:v1: failed to determine signature info: 2 of 3 required signature(s) found`))
+		})
+
+		It("ignores failing signatures", func() {
+			_, pub := Must2(rsa.Handler{}.CreateKeyPair())
+			MustBeSuccessful(verify(1, PublicKey(SIGNATURE2, pub)))
+			Expect(verify(2, PublicKey(SIGNATURE2, pub))).To(MatchError(`github.com/mandelsoft/ref:v1: 1 signature(s) with distinct keys verified, but 2 required`))
+		})
+
+		It("rejects threshold exceeding signatures", func() {
+			Expect(verify(4)).To(MatchError(`inconsistent options for verification: signature threshold 4 exceeds number of configured signatures (3)`))
+		})
+
+		It("counts duplicate signature names once", func() {
+			withVersion(accessobj.ACC_READONLY, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+				_, err := VerifyComponentVersion(cv, SIGNATURE, Resolver(src), VerifySignature(SIGNATURE), Threshold(2))
+				Expect(err).To(MatchError(`inconsistent options for verification: signature threshold 2 exceeds number of configured signatures (1)`))
+			})
+		})
+	})
+})
@@ -0,0 +1,84 @@
+package signing
+
+import (
+	"github.com/mandelsoft/goutils/errors"
+
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/extensions/attrs/signingattr"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+)
+
+// ResignComponentVersion supports the rotation of signing keys. It verifies
+// the existing signature oldName of a component version (and the digests of
+// all referenced component versions) and adds a new signature newName for
+// the component version. The public key for the old signature and the private
+// key for the new signature are taken from the options, therefore both
+// signatures must use different names. If remove is set, the old signature
+// is removed from the component version after the new one has been added.
+// If the new signature already exists, it is verified instead of recreated.
+//
+// Referenced component versions are not re-signed.
+func ResignComponentVersion(cv ocm.ComponentVersionAccess, oldName, newName string, remove bool, optlist ...Option) (*metav1.DigestSpec, error) {
+	if oldName == "" || newName == "" {
+		return nil, errors.Newf("old and new signature name required")
+	}
+	if oldName == newName {
+		return nil, errors.Newf("new signature name must differ from old signature name %q", oldName)
+	}
+	if cv.GetDescriptor().GetSignatureIndex(oldName) < 0 {
+		return nil, errors.ErrNotFound(compdesc.KIND_SIGNATURE, oldName)
+	}
+
+	var opts Options
+	opts.Eval(optlist...)
+
+	vopts := opts.Dup()
+	vopts.Signer = nil
+	vopts.SignAlgo = ""
+	vopts.SignatureNames = []string{oldName}
+	vopts.VerifySignature = true
+	vopts.Verify = true
+	vopts.Recursively = true
+	vopts.Update = false
+	err := vopts.Complete(cv.GetContext())
+	if err != nil {
+		return nil, errors.Wrapf(err, "inconsistent options for verification")
+	}
+	_, err = Apply(nil, nil, cv, vopts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "verification of signature %q failed", oldName)
+	}
+
+	sopts := opts.Dup()
+	sopts.SignatureNames = []string{newName}
+	// an already existing new signature is verified instead of recreated.
+	sopts.VerifySignature = cv.GetDescriptor().GetSignatureIndex(newName) >= 0
+	sopts.Verify = true
+	sopts.Recursively = false
+	sopts.Update = true
+	if sopts.Signer == nil && sopts.SignAlgo == "" {
+		sopts.Signer = signingattr.Get(cv.GetContext()).GetSigner(rsa.Algorithm)
+	}
+	err = sopts.Complete(cv.GetContext())
+	if err != nil {
+		return nil, errors.Wrapf(err, "inconsistent options for signing")
+	}
+	d, err := Apply(nil, nil, cv, sopts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "signing with %q failed", newName)
+	}
+
+	if remove {
+		cd := cv.GetDescriptor()
+		if i := cd.GetSignatureIndex(oldName); i >= 0 {
+			cd.Signatures = append(cd.Signatures[:i], cd.Signatures[i+1:]...)
+			err = cv.Update()
+			if err != nil && !errors.Is(err, ocm.ErrTempVersion) {
+				return nil, err
+			}
+		}
+	}
+	return d, nil
+}
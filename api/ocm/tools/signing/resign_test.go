@@ -0,0 +1,123 @@
+package signing_test
+
+import (
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/helper/builder"
+	. "ocm.software/ocm/api/ocm/testhelper"
+	. "ocm.software/ocm/api/ocm/tools/signing"
+
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+)
+
+var _ = Describe("re-signing", func() {
+	var env *Builder
+
+	BeforeEach(func() {
+		env = NewBuilder()
+		env.RSAKeyPair(SIGNATURE, SIGNATURE2)
+
+		env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+			env.Component(COMPONENTA, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+					TestDataResource(env)
+				})
+			})
+			env.Component(COMPONENTB, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+					OtherDataResource(env)
+					env.Reference("ref", COMPONENTA, VERSION)
+				})
+			})
+		})
+
+		src := Must(ctf.Open(env, accessobj.ACC_WRITABLE, ARCH, 0, env))
+		defer Close(src, "source")
+		cv := Must(src.LookupComponentVersion(COMPONENTB, VERSION))
+		defer Close(cv, "version")
+		Must(SignComponentVersion(cv, SIGNATURE, Resolver(src)))
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	withVersion := func(mode accessobj.AccessMode, f func(src ocm.Repository, cv ocm.ComponentVersionAccess)) {
+		src := Must(ctf.Open(env, mode, ARCH, 0, env))
+		defer Close(src, "source")
+		cv := Must(src.LookupComponentVersion(COMPONENTB, VERSION))
+		defer Close(cv, "version")
+		f(src, cv)
+	}
+
+	signatures := func() []string {
+		var names []string
+		withVersion(accessobj.ACC_READONLY, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			for _, s := range cv.GetDescriptor().Signatures {
+				names = append(names, s.Name)
+			}
+			Must(VerifyComponentVersion(cv, names[len(names)-1], Resolver(src)))
+		})
+		return names
+	}
+
+	It("adds new signature", func() {
+		withVersion(accessobj.ACC_WRITABLE, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			Must(ResignComponentVersion(cv, SIGNATURE, SIGNATURE2, false, Resolver(src)))
+		})
+		Expect(signatures()).To(Equal([]string{SIGNATURE, SIGNATURE2}))
+	})
+
+	It("replaces old signature", func() {
+		withVersion(accessobj.ACC_WRITABLE, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			Must(ResignComponentVersion(cv, SIGNATURE, SIGNATURE2, true, Resolver(src)))
+		})
+		Expect(signatures()).To(Equal([]string{SIGNATURE2}))
+	})
+
+	It("rejects unverifiable old signature", func() {
+		_, pub := Must2(rsa.Handler{}.CreateKeyPair())
+		withVersion(accessobj.ACC_WRITABLE, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			ExpectError(ResignComponentVersion(cv, SIGNATURE, SIGNATURE2, true, Resolver(src), PublicKey(SIGNATURE, pub))).To(
+				MatchError(ContainSubstring(`verification of signature "test" failed: `)))
+		})
+		Expect(signatures()).To(Equal([]string{SIGNATURE}))
+	})
+
+	It("verifies existing new signature", func() {
+		priv, pub := Must2(rsa.Handler{}.CreateKeyPair())
+		var value string
+		withVersion(accessobj.ACC_WRITABLE, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			Must(SignComponentVersion(cv, SIGNATURE2, Resolver(src), PrivateKey(SIGNATURE2, priv), PublicKey(SIGNATURE2, pub)))
+			value = cv.GetDescriptor().Signatures[1].Signature.Value
+		})
+
+		withVersion(accessobj.ACC_WRITABLE, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			ExpectError(ResignComponentVersion(cv, SIGNATURE, SIGNATURE2, true, Resolver(src))).To(
+				MatchError(ContainSubstring(`signature "second": signature verification failed`)))
+		})
+		withVersion(accessobj.ACC_WRITABLE, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			Expect(cv.GetDescriptor().Signatures).To(HaveLen(2))
+			Must(ResignComponentVersion(cv, SIGNATURE, SIGNATURE2, false, Resolver(src), PublicKey(SIGNATURE2, pub)))
+		})
+		withVersion(accessobj.ACC_READONLY, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			Expect(cv.GetDescriptor().Signatures).To(HaveLen(2))
+			Expect(cv.GetDescriptor().Signatures[1].Signature.Value).To(Equal(value))
+			Must(VerifyComponentVersion(cv, SIGNATURE2, Resolver(src), PublicKey(SIGNATURE2, pub)))
+		})
+	})
+
+	It("rejects identical signature names", func() {
+		withVersion(accessobj.ACC_WRITABLE, func(src ocm.Repository, cv ocm.ComponentVersionAccess) {
+			ExpectError(ResignComponentVersion(cv, SIGNATURE, SIGNATURE, true, Resolver(src))).To(
+				MatchError(`new signature name must differ from old signature name "test"`))
+		})
+	})
+})
@@ -1,8 +1,10 @@
 package signing
 
 import (
+	"bytes"
 	"io"
 	"os"
+	"slices"
 	"sync"
 
 	"github.com/mandelsoft/filepath/pkg/filepath"
@@ -30,6 +32,11 @@ import (
 // component versions (see NewVerifiedStore).
 type VerifiedStore interface {
 	Add(cd *compdesc.ComponentDescriptor, signatures ...string)
+	// AddEntry adds a complete entry, for example taken from another store.
+	// If the store already contains the same descriptor, the signatures
+	// are merged and the latest verification time is kept. Otherwise,
+	// the existing entry is replaced.
+	AddEntry(e *StorageEntry)
 	Remove(n common.VersionedElement)
 	Get(n common.VersionedElement) *compdesc.ComponentDescriptor
 	GetEntry(n common.VersionedElement) *StorageEntry
@@ -165,9 +172,35 @@ func (v *verifiedStore) Add(cd *compdesc.ComponentDescriptor, signatures ...stri
 	for _, e := range signatures {
 		old.Signatures = sliceutils.AppendUnique(old.Signatures, e)
 	}
+	old.Verified = metav1.NewTimestampP()
 	v.storage.ComponentVersions[key] = old
 }
 
+func (v *verifiedStore) AddEntry(e *StorageEntry) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if v.storage == nil {
+		v.storage = &StorageDescriptor{}
+	}
+	if v.storage.ComponentVersions == nil {
+		v.storage.ComponentVersions = map[string]*StorageEntry{}
+	}
+	key := common.VersionedElementKey(e.Descriptor).String()
+	old := v.storage.ComponentVersions[key]
+	if old == nil || !equalDescriptors(old.Descriptor, e.Descriptor) {
+		v.storage.ComponentVersions[key] = e.Copy()
+		return
+	}
+	for _, s := range e.Signatures {
+		old.Signatures = sliceutils.AppendUnique(old.Signatures, s)
+	}
+	if e.Verified != nil && (old.Verified == nil || old.Verified.Time().Before(e.Verified.Time())) {
+		t := *e.Verified
+		old.Verified = &t
+	}
+}
+
 func (v *verifiedStore) Remove(n common.VersionedElement) {
 	v.lock.Lock()
 	defer v.lock.Unlock()
@@ -216,11 +249,46 @@ func (v *verifiedStore) GetResourceDigestByIndex(n common.VersionedElement, idx
 	return cd.Resources[idx].Digest
 }
 
+// equalDescriptors compares two stored descriptors. Descriptors read
+// from a serialized store use generic access specifications, therefore
+// the serialized forms are compared if the structural comparison fails.
+func equalDescriptors(a, b *compdesc.GenericComponentDescriptor) bool {
+	if a.Descriptor().Equal(b.Descriptor()) {
+		return true
+	}
+	da, err := runtime.DefaultJSONEncoding.Marshal(a)
+	if err != nil {
+		return false
+	}
+	db, err := runtime.DefaultJSONEncoding.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(da, db)
+}
+
 type StorageEntry struct {
 	Signatures []string                             `json:"signatures,omitempty"`
+	Verified   *metav1.Timestamp                    `json:"verified,omitempty"`
 	Descriptor *compdesc.GenericComponentDescriptor `json:"descriptor"`
 }
 
+// Copy provides a deep copy of a storage entry.
+func (e *StorageEntry) Copy() *StorageEntry {
+	if e == nil {
+		return nil
+	}
+	n := &StorageEntry{
+		Signatures: slices.Clone(e.Signatures),
+		Descriptor: (*compdesc.GenericComponentDescriptor)(e.Descriptor.Descriptor().Copy()),
+	}
+	if e.Verified != nil {
+		t := *e.Verified
+		n.Verified = &t
+	}
+	return n
+}
+
 type StorageDescriptor struct {
 	ComponentVersions map[string]*StorageEntry `json:"componentVersions,omitempty"`
 }
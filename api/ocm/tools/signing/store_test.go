@@ -64,10 +64,12 @@ var _ = Describe("Store", func() {
 				ComponentVersions: map[string]*signing.StorageEntry{
 					common.VersionedElementKey(cd1).String(): {
 						Signatures: []string{"a"},
+						Verified:   store.GetEntry(cd1).Verified,
 						Descriptor: (*compdesc.GenericComponentDescriptor)(cd1),
 					},
 					common.VersionedElementKey(cd2).String(): {
 						Signatures: []string{"b", "c"},
+						Verified:   store.GetEntry(cd2).Verified,
 						Descriptor: (*compdesc.GenericComponentDescriptor)(cd2),
 					},
 				},
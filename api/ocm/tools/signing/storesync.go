@@ -0,0 +1,274 @@
+package signing
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+const KIND_VERIFIED_ENTRY = "verified component version"
+
+// EncodeVerifiedStore provides the serialized form of the content
+// of a VerifiedStore. It can be imported again with DecodeVerifiedStore
+// or used as file for NewVerifiedStore.
+func EncodeVerifiedStore(s VerifiedStore) ([]byte, error) {
+	desc := StorageDescriptor{}
+	for _, nv := range s.Entries() {
+		e := s.GetEntry(nv)
+		if e == nil {
+			continue
+		}
+		if desc.ComponentVersions == nil {
+			desc.ComponentVersions = map[string]*StorageEntry{}
+		}
+		desc.ComponentVersions[nv.String()] = e
+	}
+	return runtime.DefaultYAMLEncoding.Marshal(&desc)
+}
+
+// DecodeVerifiedStore creates a memory based VerifiedStore
+// from the serialized form provided by EncodeVerifiedStore.
+func DecodeVerifiedStore(data []byte) (VerifiedStore, error) {
+	var desc StorageDescriptor
+	err := runtime.DefaultYAMLEncoding.Unmarshal(data, &desc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid verified store")
+	}
+	s := NewLocalVerifiedStore()
+	for k, e := range desc.ComponentVersions {
+		if e == nil || e.Descriptor == nil {
+			return nil, errors.Newf("invalid verified store: entry %q without descriptor", k)
+		}
+		s.AddEntry(e)
+	}
+	return s, nil
+}
+
+// MergeVerifiedStores adds the entries of the source stores to the target
+// store. Entries for the same component version are merged, if they describe
+// the same component descriptor. Otherwise, they are reported as conflict,
+// unless overwrite is set, which replaces the target entry.
+// It returns the list of added or updated component versions.
+// The entries are taken as they are. Sources from untrusted locations
+// should be filtered with VerifyStoreEntries first.
+func MergeVerifiedStores(target VerifiedStore, overwrite bool, sources ...VerifiedStore) ([]common.NameVersion, error) {
+	var merged []common.NameVersion
+
+	list := errors.ErrListf("conflicting verified entries")
+	for _, src := range sources {
+		for _, nv := range src.Entries() {
+			e := src.GetEntry(nv)
+			if e == nil {
+				continue
+			}
+			old := target.GetEntry(nv)
+			if old != nil {
+				if !equalDescriptors(old.Descriptor, e.Descriptor) {
+					if !overwrite {
+						list.Add(errors.ErrAlreadyExists(KIND_VERIFIED_ENTRY, nv.String()))
+						continue
+					}
+					target.Remove(nv)
+				} else if !entryUpdated(old, e) {
+					continue
+				}
+			}
+			target.AddEntry(e)
+			if !slices.Contains(merged, nv) {
+				merged = append(merged, nv)
+			}
+		}
+	}
+	slices.SortFunc(merged, common.NameVersion.Compare)
+	return merged, list.Result()
+}
+
+// VerifyStoreEntries checks the authenticity of the entries of a verified
+// store taken from an untrusted source (for example, a file or an OCI
+// artifact of another environment) before it is merged into a trusted store.
+// An entry is accepted, if one of its signatures can be verified with the
+// public keys provided by the options (or the certificate provided by the
+// signature). Entries without verifiable signature are accepted, if they
+// are referenced by an accepted entry or an entry of the optional trusted
+// store with a digest matching their component descriptor.
+// It returns a memory based store with the accepted entries and the list of
+// rejected component versions.
+func VerifyStoreEntries(ctx ocm.ContextProvider, src VerifiedStore, trusted VerifiedStore, optlist ...Option) (VerifiedStore, []common.NameVersion, error) {
+	var opts Options
+
+	opts.Eval(optlist...)
+	opts.Signer = nil
+	opts.SignAlgo = ""
+	opts.VerifySignature = false
+	err := opts.Complete(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "inconsistent options for verification")
+	}
+
+	state := NewWalkingState()
+	accepted := NewLocalVerifiedStore()
+
+	var work []*compdesc.ComponentDescriptor
+	for _, nv := range src.Entries() {
+		e := src.GetEntry(nv)
+		if e == nil {
+			continue
+		}
+		cd := e.Descriptor.Descriptor()
+		var verified []string
+		for _, n := range e.Signatures {
+			i := cd.GetSignatureIndex(n)
+			if i < 0 {
+				continue
+			}
+			ok, _, err := verifyNamedSignature(compdesc.NewCompDescDigests(cd), state, &cd.Signatures[i], &opts)
+			if err != nil {
+				opts.Printer.Printf("Warning: %s for %s\n", err, nv)
+				continue
+			}
+			if ok {
+				verified = append(verified, n)
+			}
+		}
+		if len(verified) > 0 {
+			// only the actually verified signatures are taken over.
+			accepted.AddEntry(withSignatures(e, verified...))
+			work = append(work, cd)
+		}
+	}
+	if trusted != nil {
+		for _, nv := range trusted.Entries() {
+			if cd := trusted.Get(nv); cd != nil {
+				work = append(work, cd)
+			}
+		}
+	}
+
+	// accept the component versions referenced by accepted ones.
+	for len(work) > 0 {
+		cd := work[0]
+		work = work[1:]
+		for _, ref := range cd.References {
+			nv := common.NewNameVersion(ref.ComponentName, ref.Version)
+			e := src.GetEntry(nv)
+			if e == nil || ref.Digest == nil || accepted.GetEntry(nv) != nil {
+				continue
+			}
+			hasher := opts.Registry.GetHasher(ref.Digest.HashAlgorithm)
+			if hasher == nil {
+				continue
+			}
+			refcd := e.Descriptor.Descriptor()
+			digest, err := compdesc.Hash(refcd, ref.Digest.NormalisationAlgorithm, hasher.Create())
+			if err != nil || digest != ref.Digest.Value {
+				continue
+			}
+			// like for a regular verification, referenced component
+			// versions are not verified by a signature.
+			accepted.AddEntry(withSignatures(e))
+			work = append(work, refcd)
+		}
+	}
+
+	var rejected []common.NameVersion
+	for _, nv := range src.Entries() {
+		if accepted.GetEntry(nv) == nil {
+			rejected = append(rejected, nv)
+		}
+	}
+	slices.SortFunc(rejected, common.NameVersion.Compare)
+	return accepted, rejected, nil
+}
+
+func entryUpdated(old, n *StorageEntry) bool {
+	for _, s := range n.Signatures {
+		if !slices.Contains(old.Signatures, s) {
+			return true
+		}
+	}
+	return n.Verified != nil && (old.Verified == nil || old.Verified.Time().Before(n.Verified.Time()))
+}
+
+// PruneVerifiedStore removes all entries from a store, which have been
+// verified before the given time. A zero time matches all entries.
+// Entries without verification time (created by older versions)
+// are always considered outdated. If component names are given,
+// only entries for those components are considered.
+// It returns the list of removed component versions.
+func PruneVerifiedStore(s VerifiedStore, before time.Time, components ...string) []common.NameVersion {
+	var pruned []common.NameVersion
+
+	for _, nv := range s.Entries() {
+		if len(components) > 0 && !slices.Contains(components, nv.GetName()) {
+			continue
+		}
+		e := s.GetEntry(nv)
+		if e == nil {
+			continue
+		}
+		if before.IsZero() || e.Verified == nil || e.Verified.Time().Before(before) {
+			s.Remove(nv)
+			pruned = append(pruned, nv)
+		}
+	}
+	slices.SortFunc(pruned, common.NameVersion.Compare)
+	return pruned
+}
+
+// VerifiedResource describes a resource of a verified
+// component version.
+type VerifiedResource struct {
+	ComponentVersion common.NameVersion
+	Identity         metav1.Identity
+	Digest           *metav1.DigestSpec
+}
+
+// FindResourcesByDigest provides all resources of verified component versions
+// with the given digest value. The digest may be given with an algorithm
+// prefix separated by a colon (for example, sha256:...). The prefix is
+// ignored for the comparison, because the algorithm names used for
+// OCM digests differ from the ones used for OCI digests.
+func FindResourcesByDigest(s VerifiedStore, digest string) []VerifiedResource {
+	var result []VerifiedResource
+
+	if i := strings.LastIndex(digest, ":"); i >= 0 {
+		digest = digest[i+1:]
+	}
+	digest = strings.ToLower(digest)
+
+	entries := s.Entries()
+	slices.SortFunc(entries, common.NameVersion.Compare)
+	for _, nv := range entries {
+		cd := s.Get(nv)
+		if cd == nil {
+			continue
+		}
+		for i := range cd.Resources {
+			r := &cd.Resources[i]
+			if r.Digest != nil && strings.ToLower(r.Digest.Value) == digest {
+				result = append(result, VerifiedResource{
+					ComponentVersion: nv,
+					Identity:         r.GetIdentity(cd.Resources),
+					Digest:           r.Digest.Copy(),
+				})
+			}
+		}
+	}
+	return result
+}
+
+// withSignatures provides a copy of a store entry
+// with the given signature names.
+func withSignatures(e *StorageEntry, names ...string) *StorageEntry {
+	n := e.Copy()
+	n.Signatures = names
+	return n
+}
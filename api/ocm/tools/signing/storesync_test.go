@@ -0,0 +1,264 @@
+package signing_test
+
+import (
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/helper/builder"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/oci/extensions/repositories/ctf"
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/compdesc/versions/ocm.software/v3alpha1"
+	"ocm.software/ocm/api/ocm/extensions/accessmethods/localblob"
+	"ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/tech/signing/hasher/sha256"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+func verifiedDescriptor(name, provider string, digests ...string) *compdesc.ComponentDescriptor {
+	cd := compdesc.DefaultComponent(&compdesc.ComponentDescriptor{
+		Metadata: compdesc.Metadata{
+			ConfiguredVersion: v3alpha1.SchemaVersion,
+		},
+		ComponentSpec: compdesc.ComponentSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:    name,
+				Version: VERSION,
+				Provider: metav1.Provider{
+					Name: metav1.ProviderName(provider),
+				},
+			},
+		},
+	})
+	for i, d := range digests {
+		cd.Resources = append(cd.Resources, compdesc.Resource{
+			ResourceMeta: compdesc.ResourceMeta{
+				ElementMeta: compdesc.ElementMeta{
+					Name:    "res" + string(rune('a'+i)),
+					Version: VERSION,
+				},
+				Type:     "blob",
+				Relation: metav1.LocalRelation,
+				Digest: &metav1.DigestSpec{
+					HashAlgorithm:          "SHA-256",
+					NormalisationAlgorithm: "genericBlobDigest/v1",
+					Value:                  d,
+				},
+			},
+			Access: localblob.New("blob", "", "text/plain", nil),
+		})
+	}
+	return cd
+}
+
+func referenceTo(cd *compdesc.ComponentDescriptor) compdesc.Reference {
+	return compdesc.Reference{
+		ElementMeta: compdesc.ElementMeta{
+			Name:    "ref",
+			Version: cd.Version,
+		},
+		ComponentName: cd.Name,
+		Digest: &metav1.DigestSpec{
+			HashAlgorithm:          sha256.Algorithm,
+			NormalisationAlgorithm: compdesc.JsonNormalisationV1,
+			Value:                  Must(compdesc.Hash(cd, compdesc.JsonNormalisationV1, sha256.Handler{}.Create())),
+		},
+	}
+}
+
+func signDescriptor(cd *compdesc.ComponentDescriptor, name string, priv interface{}) *compdesc.ComponentDescriptor {
+	MustBeSuccessful(compdesc.Sign(credentials.DefaultContext(), cd, priv, rsa.NewHandler(), sha256.Handler{}, name, "acme.org"))
+	return cd
+}
+
+var _ = Describe("verified store synchronization", func() {
+	cda := verifiedDescriptor(COMPONENTA, "acme.org", "0123", "abcd")
+	cdb := verifiedDescriptor(COMPONENTB, "acme.org", "abcd")
+	cdbmod := verifiedDescriptor(COMPONENTB, "other.org", "abcd")
+
+	It("exports and imports a store", func() {
+		store := signing.NewLocalVerifiedStore()
+		store.Add(cda, "a")
+		store.Add(cdb, "b")
+
+		data := Must(signing.EncodeVerifiedStore(store))
+		imported := Must(signing.DecodeVerifiedStore(data))
+		Expect(imported.Entries()).To(ConsistOf(common.VersionedElementKey(cda), common.VersionedElementKey(cdb)))
+		Expect(imported.GetEntry(cda)).To(YAMLEqual(store.GetEntry(cda)))
+		Expect(imported.Get(cdb)).To(YAMLEqual(cdb))
+
+		merged := Must(signing.MergeVerifiedStores(store, false, imported))
+		Expect(merged).To(BeEmpty())
+	})
+
+	It("merges stores", func() {
+		target := signing.NewLocalVerifiedStore()
+		target.Add(cda, "a")
+
+		src1 := signing.NewLocalVerifiedStore()
+		src1.Add(cda, "a")
+		src1.Add(cdb, "b")
+		src2 := signing.NewLocalVerifiedStore()
+		src2.Add(cda, "other")
+
+		merged := Must(signing.MergeVerifiedStores(target, false, src1, src2))
+		Expect(merged).To(ConsistOf(common.VersionedElementKey(cda), common.VersionedElementKey(cdb)))
+		Expect(target.GetEntry(cda).Signatures).To(Equal([]string{"a", "other"}))
+		Expect(target.GetEntry(cdb).Signatures).To(Equal([]string{"b"}))
+
+		merged = Must(signing.MergeVerifiedStores(target, false, src1))
+		Expect(merged).To(BeEmpty())
+	})
+
+	It("detects conflicts", func() {
+		target := signing.NewLocalVerifiedStore()
+		target.Add(cdb, "b")
+
+		src := signing.NewLocalVerifiedStore()
+		src.Add(cdbmod, "mod")
+		src.Add(cda, "a")
+
+		merged, err := signing.MergeVerifiedStores(target, false, src)
+		Expect(err).To(MatchError(ContainSubstring(`verified component version "` + COMPONENTB + `:` + VERSION + `" already exists`)))
+		Expect(merged).To(ConsistOf(common.VersionedElementKey(cda)))
+		Expect(target.Get(cdb).Equal(cdb)).To(BeTrue())
+
+		merged = Must(signing.MergeVerifiedStores(target, true, src))
+		Expect(merged).To(ConsistOf(common.VersionedElementKey(cdb)))
+		Expect(target.Get(cdb).Equal(cdbmod)).To(BeTrue())
+		Expect(target.GetEntry(cdb).Signatures).To(Equal([]string{"mod"}))
+	})
+
+	It("prunes a store", func() {
+		store := signing.NewLocalVerifiedStore()
+		store.Add(cda, "a")
+		store.Add(cdb, "b")
+		old := store.GetEntry(cda).Copy()
+		old.Verified = metav1.NewTimestampPFor(time.Now().Add(-48 * time.Hour))
+		store.Remove(cda)
+		store.AddEntry(old)
+
+		Expect(signing.PruneVerifiedStore(store, time.Now().Add(-24*time.Hour))).To(ConsistOf(common.VersionedElementKey(cda)))
+		Expect(store.Entries()).To(ConsistOf(common.VersionedElementKey(cdb)))
+
+		Expect(signing.PruneVerifiedStore(store, time.Time{}, COMPONENTA)).To(BeEmpty())
+		Expect(signing.PruneVerifiedStore(store, time.Time{}, COMPONENTB)).To(ConsistOf(common.VersionedElementKey(cdb)))
+		Expect(store.Entries()).To(BeEmpty())
+	})
+
+	It("finds resources by digest", func() {
+		store := signing.NewLocalVerifiedStore()
+		store.Add(cda, "a")
+		store.Add(cdb, "b")
+
+		Expect(signing.FindResourcesByDigest(store, "sha256:ABCD")).To(Equal([]signing.VerifiedResource{
+			{
+				ComponentVersion: common.VersionedElementKey(cdb),
+				Identity:         metav1.Identity{"name": "resa"},
+				Digest:           cdb.Resources[0].Digest,
+			},
+			{
+				ComponentVersion: common.VersionedElementKey(cda),
+				Identity:         metav1.Identity{"name": "resb"},
+				Digest:           cda.Resources[1].Digest,
+			},
+		}))
+		Expect(signing.FindResourcesByDigest(store, "0123")).To(HaveLen(1))
+		Expect(signing.FindResourcesByDigest(store, "4567")).To(BeEmpty())
+	})
+
+	Context("verification", func() {
+		priv, pub := Must2(rsa.Handler{}.CreateKeyPair())
+		other, _ := Must2(rsa.Handler{}.CreateKeyPair())
+
+		It("accepts verifiable entries, only", func() {
+			root := verifiedDescriptor(COMPONENTA, "acme.org", "0123")
+			root.References = append(root.References, referenceTo(cdb))
+			signDescriptor(root, "a", priv)
+			forged := signDescriptor(verifiedDescriptor(COMPONENTC, "acme.org", "4567"), "a", other)
+			unsigned := verifiedDescriptor("acme.org/unsigned", "acme.org")
+
+			src := signing.NewLocalVerifiedStore()
+			src.Add(root, "a")
+			src.Add(cdb)
+			src.Add(forged, "a")
+			src.Add(unsigned, "a")
+
+			accepted, rejected := Must2(signing.VerifyStoreEntries(nil, src, nil, signing.PublicKey("a", pub)))
+			Expect(accepted.Entries()).To(ConsistOf(common.VersionedElementKey(root), common.VersionedElementKey(cdb)))
+			Expect(rejected).To(Equal([]common.NameVersion{common.VersionedElementKey(unsigned), common.VersionedElementKey(forged)}))
+		})
+
+		It("takes over verified signature names, only", func() {
+			root := verifiedDescriptor(COMPONENTA, "acme.org", "0123")
+			root.References = append(root.References, referenceTo(cdb))
+			signDescriptor(root, "a", priv)
+			signDescriptor(root, "b", other)
+
+			src := signing.NewLocalVerifiedStore()
+			src.Add(root, "a", "b", "c")
+			src.Add(cdb, "a")
+
+			accepted, rejected := Must2(signing.VerifyStoreEntries(nil, src, nil, signing.PublicKey("a", pub), signing.PublicKey("b", pub)))
+			Expect(rejected).To(BeEmpty())
+			Expect(accepted.GetEntry(root).Signatures).To(Equal([]string{"a"}))
+			Expect(accepted.GetEntry(cdb).Signatures).To(BeEmpty())
+		})
+
+		It("accepts entries referenced by trusted entries", func() {
+			root := verifiedDescriptor(COMPONENTA, "acme.org", "0123")
+			root.References = append(root.References, referenceTo(cdb))
+			trusted := signing.NewLocalVerifiedStore()
+			trusted.Add(root)
+
+			src := signing.NewLocalVerifiedStore()
+			src.Add(cdb)
+
+			accepted, rejected := Must2(signing.VerifyStoreEntries(nil, src, trusted))
+			Expect(accepted.Entries()).To(ConsistOf(common.VersionedElementKey(cdb)))
+			Expect(rejected).To(BeEmpty())
+
+			src = signing.NewLocalVerifiedStore()
+			src.Add(cdbmod)
+			accepted, rejected = Must2(signing.VerifyStoreEntries(nil, src, trusted))
+			Expect(accepted.Entries()).To(BeEmpty())
+			Expect(rejected).To(ConsistOf(common.VersionedElementKey(cdbmod)))
+		})
+	})
+
+	Context("oci", func() {
+		var env *Builder
+
+		BeforeEach(func() {
+			env = NewBuilder()
+			env.OCICommonTransport(ARCH, accessio.FormatDirectory)
+		})
+
+		AfterEach(func() {
+			env.Cleanup()
+		})
+
+		It("pushes and pulls a store", func() {
+			store := signing.NewLocalVerifiedStore()
+			store.Add(cda, "a")
+			store.Add(cdb, "b")
+
+			repo := Must(ctf.Open(env, accessobj.ACC_WRITABLE, ARCH, 0, env))
+			Must(signing.PushVerifiedStore(repo, "verified", "latest", store))
+			MustBeSuccessful(repo.Close())
+
+			repo = Must(ctf.Open(env, accessobj.ACC_READONLY, ARCH, 0, env))
+			defer Close(repo, "repo")
+			pulled := Must(signing.PullVerifiedStore(repo, "verified", "latest"))
+			Expect(pulled.Entries()).To(ConsistOf(common.VersionedElementKey(cda), common.VersionedElementKey(cdb)))
+			Expect(pulled.GetEntry(cdb)).To(YAMLEqual(store.GetEntry(cdb)))
+		})
+	})
+})
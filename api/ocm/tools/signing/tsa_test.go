@@ -0,0 +1,166 @@
+package signing_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/helper/builder"
+	. "ocm.software/ocm/api/ocm/testhelper"
+	. "ocm.software/ocm/api/ocm/tools/signing"
+
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/extensions/attrs/signingattr"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/tech/signing/signutils"
+	"ocm.software/ocm/api/tech/signing/tsa"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+)
+
+func createCA(name string, usage x509.ExtKeyUsage) (*x509.Certificate, signutils.GenericPrivateKey) {
+	priv, pub := Must2(rsa.Handler{}.CreateKeyPair())
+	notBefore := time.Now().Add(-5 * time.Hour)
+	ca, _ := Must2(signutils.CreateCertificate(&signutils.Specification{
+		IsCA:         true,
+		NotBefore:    &notBefore,
+		PublicKey:    pub,
+		CAPrivateKey: priv,
+		Subject:      pkix.Name{CommonName: name},
+		Usages:       signutils.Usages{usage},
+		Validity:     20 * time.Hour,
+	}))
+	return ca, priv
+}
+
+func createCert(ca *x509.Certificate, capriv signutils.GenericPrivateKey, name string, usage x509.ExtKeyUsage, notBefore time.Time, validity time.Duration) (*x509.Certificate, []byte, signutils.GenericPrivateKey) {
+	priv, pub := Must2(rsa.Handler{}.CreateKeyPair())
+	cert, data := Must2(signutils.CreateCertificate(&signutils.Specification{
+		RootCAs:      ca,
+		CAChain:      ca,
+		PublicKey:    pub,
+		CAPrivateKey: capriv,
+		Subject:      pkix.Name{CommonName: name},
+		Usages:       signutils.Usages{usage},
+		NotBefore:    &notBefore,
+		Validity:     validity,
+	}))
+	return cert, data, priv
+}
+
+var _ = Describe("timestamp authorities", func() {
+	var env *Builder
+	var authority *tsa.LocalAuthority
+	var tsaPem []byte
+	var tsaPriv signutils.GenericPrivateKey
+	var tsaPool *x509.CertPool
+	var pool *x509.CertPool
+	var ca *x509.Certificate
+	var capriv signutils.GenericPrivateKey
+
+	BeforeEach(func() {
+		env = NewBuilder()
+
+		tsaCA, tsaCAPriv := createCA("tsa-authority", x509.ExtKeyUsageTimeStamping)
+		_, tsaPem, tsaPriv = createCert(tsaCA, tsaCAPriv, "local-tsa", x509.ExtKeyUsageTimeStamping, time.Now(), 5*time.Hour)
+		authority = Must(tsa.NewLocalAuthority(tsaPriv, tsaPem))
+		tsaPool = x509.NewCertPool()
+		tsaPool.AddCert(tsaCA)
+
+		ca, capriv = createCA("ca-authority", x509.ExtKeyUsageCodeSigning)
+		pool = x509.NewCertPool()
+		pool.AddCert(ca)
+
+		env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+			env.Component(COMPONENTA, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+					TestDataResource(env)
+				})
+			})
+		})
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	withVersion := func(mode accessobj.AccessMode, f func(cv ocm.ComponentVersionAccess)) {
+		src := Must(ctf.Open(env, mode, ARCH, 0, env))
+		defer Close(src, "source")
+		cv := Must(src.LookupComponentVersion(COMPONENTA, VERSION))
+		defer Close(cv, "version")
+		f(cv)
+	}
+
+	sign := func(notBefore time.Time, validity time.Duration, opts ...Option) {
+		_, data, priv := createCert(ca, capriv, NAME, x509.ExtKeyUsageCodeSigning, notBefore, validity)
+		withVersion(accessobj.ACC_WRITABLE, func(cv ocm.ComponentVersionAccess) {
+			opts = append(opts, PrivateKey(NAME, priv), PublicKey(NAME, data), RootCertificates(pool))
+			Must(SignComponentVersion(cv, NAME, opts...))
+		})
+	}
+
+	verify := func(opts ...Option) error {
+		var err error
+		withVersion(accessobj.ACC_READONLY, func(cv ocm.ComponentVersionAccess) {
+			opts = append(opts, RootCertificates(pool))
+			_, err = VerifyComponentVersion(cv, NAME, opts...)
+		})
+		return err
+	}
+
+	It("signs with local authority and verifies offline", func() {
+		sign(time.Now(), time.Hour, TimestampAuthority(authority))
+		withVersion(accessobj.ACC_READONLY, func(cv ocm.ComponentVersionAccess) {
+			sig := cv.GetDescriptor().Signatures[0]
+			Expect(sig.Timestamp).NotTo(BeNil())
+			Expect(time.Since(sig.Timestamp.Time.Time()).Minutes()).To(BeNumerically("<", 2))
+		})
+		MustBeSuccessful(verify(TSARootCertificates(tsaPool), RequireTimestamp()))
+	})
+
+	It("rejects timestamp of unknown authority", func() {
+		sign(time.Now(), time.Hour, TimestampAuthority(authority))
+		other, _ := createCA("other", x509.ExtKeyUsageTimeStamping)
+		Expect(verify(TSARootCertificates(other))).To(MatchError(ContainSubstring("signature timestamp verification: timestamp authority certificate: x509: certificate signed by unknown authority")))
+	})
+
+	It("uses named authority", func() {
+		signingattr.Get(env).RegisterTimestampAuthority("local", authority)
+		sign(time.Now(), time.Hour, UseTSA(), TSAUrl("local"))
+		MustBeSuccessful(verify(TSARootCertificates(tsaPool), RequireTimestamp()))
+	})
+
+	It("uses local authority from file", func() {
+		data := append(pem.EncodeToMemory(signutils.PemBlockForPrivateKey(tsaPriv)), tsaPem...)
+		MustBeSuccessful(vfs.WriteFile(env, "/tsa.pem", data, 0o600))
+		sign(time.Now(), time.Hour, TimestampAuthority(Must(tsa.NewLocalAuthorityFromFile("/tsa.pem", env))))
+		MustBeSuccessful(verify(TSARootCertificates(tsaPool), RequireTimestamp()))
+	})
+
+	It("uses local authority URL from context filesystem", func() {
+		data := append(pem.EncodeToMemory(signutils.PemBlockForPrivateKey(tsaPriv)), tsaPem...)
+		MustBeSuccessful(vfs.WriteFile(env, "/tsa.pem", data, 0o600))
+		sign(time.Now(), time.Hour, UseTSA(), TSAUrl(tsa.LOCAL_PREFIX+"/tsa.pem"))
+		MustBeSuccessful(verify(TSARootCertificates(tsaPool), RequireTimestamp()))
+	})
+
+	It("requires timestamp", func() {
+		sign(time.Now(), time.Hour)
+		Expect(verify(RequireTimestamp())).To(MatchError(ContainSubstring(`signature "mandelsoft" is not timestamped`)))
+		MustBeSuccessful(verify())
+	})
+
+	It("rejects timestamp after certificate expiration", func() {
+		sign(time.Now().Add(-2*time.Hour), time.Hour, TimestampAuthority(authority))
+		Expect(verify(TSARootCertificates(tsaPool), RequireTimestamp())).To(MatchError(ContainSubstring(`signature "mandelsoft" timestamped`)))
+	})
+})
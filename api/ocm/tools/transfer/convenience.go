@@ -27,7 +27,8 @@ func Transfer(cv ocm.ComponentVersionAccess, tgt ocm.Repository, optlist ...Tran
 	if err != nil {
 		return err
 	}
-	return TransferVersionWithContext(common.WithPrinter(context.Background(), local.printer), nil, cv, tgt, h)
+	ctx := WithJournalContext(common.WithPrinter(context.Background(), local.printer), local.journal)
+	return TransferVersionWithContext(ctx, nil, cv, tgt, h)
 }
 
 // TransferWithContext uses the transfer handler based on the given options to control
@@ -45,5 +46,6 @@ func TransferWithContext(ctx context.Context, cv ocm.ComponentVersionAccess, tgt
 	if local.printer != nil {
 		ctx = common.WithPrinter(ctx, local.printer)
 	}
+	ctx = WithJournalContext(ctx, local.journal)
 	return TransferVersionWithContext(ctx, nil, cv, tgt, h)
 }
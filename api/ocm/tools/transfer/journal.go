@@ -0,0 +1,268 @@
+package transfer
+
+import (
+	"context"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/utils"
+	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+// Journal records the progress of a transfer operation.
+// It keeps track of completely transferred component versions and
+// of artifacts, which have been transferred into a non-local
+// (for example, OCI image) location. A transfer run using a journal
+// filled by a former (failed) run skips all work already done.
+//
+// Completed component versions are skipped if they are still found
+// in the target repository. Resources are reused if their
+// digest matches the journaled digest. Local blobs and sources are
+// not journaled, because they are stored together with the component
+// version, which is only written when all its artifacts are transferred.
+//
+// A typical implementation is a file based journal, which is persisted
+// after every update (see NewJournal).
+type Journal interface {
+	IsVersionTransferred(n common.VersionedElement) bool
+	VersionTransferred(n common.VersionedElement) error
+
+	GetArtifactAccess(n common.VersionedElement, kind string, id metav1.Identity, digest *metav1.DigestSpec) compdesc.AccessSpec
+	ArtifactTransferred(n common.VersionedElement, kind string, id metav1.Identity, digest *metav1.DigestSpec, acc compdesc.AccessSpec) error
+
+	Entries() []common.NameVersion
+
+	Reset() error
+	Load() error
+	Save() error
+}
+
+type journal struct {
+	lock    sync.Mutex
+	storage *JournalDescriptor
+	fs      vfs.FileSystem
+	file    string
+}
+
+var _ Journal = (*journal)(nil)
+
+// NewLocalJournal creates a memory based Journal.
+func NewLocalJournal() Journal {
+	return &journal{storage: &JournalDescriptor{}}
+}
+
+// NewJournal loads or creates a new filesystem based Journal.
+func NewJournal(path string, fss ...vfs.FileSystem) (Journal, error) {
+	eff, err := utils.ResolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &journal{
+		fs:   utils.FileSystem(fss...),
+		file: eff,
+	}
+
+	err = j.Load()
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *journal) Load() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.fs == nil {
+		return nil
+	}
+
+	var storage JournalDescriptor
+	f, err := j.fs.Open(j.file)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	} else {
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		err = runtime.DefaultYAMLEncoding.Unmarshal(data, &storage)
+		if err != nil {
+			return errors.Wrapf(err, "invalid transfer journal %q", j.file)
+		}
+	}
+	j.storage = &storage
+	return nil
+}
+
+func (j *journal) Save() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.save()
+}
+
+func (j *journal) save() error {
+	if j.fs == nil {
+		return nil
+	}
+
+	data, err := runtime.DefaultYAMLEncoding.Marshal(j.storage)
+	if err != nil {
+		return err
+	}
+	return vfs.WriteFile(j.fs, j.file, data, 0o600)
+}
+
+func (j *journal) Reset() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.storage = &JournalDescriptor{}
+	return j.save()
+}
+
+func (j *journal) Entries() []common.NameVersion {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	entries := make([]common.NameVersion, 0, len(j.storage.ComponentVersions))
+	for key, entry := range j.storage.ComponentVersions {
+		if entry.Completed {
+			if nv, err := common.ParseNameVersion(key); err == nil {
+				entries = append(entries, nv)
+			}
+		}
+	}
+	return entries
+}
+
+func (j *journal) entry(n common.VersionedElement) *JournalEntry {
+	if j.storage.ComponentVersions == nil {
+		j.storage.ComponentVersions = map[string]*JournalEntry{}
+	}
+	key := common.VersionedElementKey(n).String()
+	e := j.storage.ComponentVersions[key]
+	if e == nil {
+		e = &JournalEntry{}
+		j.storage.ComponentVersions[key] = e
+	}
+	return e
+}
+
+func (j *journal) IsVersionTransferred(n common.VersionedElement) bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	e := j.storage.ComponentVersions[common.VersionedElementKey(n).String()]
+	return e != nil && e.Completed
+}
+
+func (j *journal) VersionTransferred(n common.VersionedElement) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	e := j.entry(n)
+	e.Completed = true
+	e.Artifacts = nil
+	return j.save()
+}
+
+func (j *journal) GetArtifactAccess(n common.VersionedElement, kind string, id metav1.Identity, digest *metav1.DigestSpec) compdesc.AccessSpec {
+	if digest == nil {
+		return nil
+	}
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	e := j.storage.ComponentVersions[common.VersionedElementKey(n).String()]
+	if e == nil {
+		return nil
+	}
+	for _, a := range e.Artifacts {
+		if a.Kind == kind && a.Identity.Equals(id) && a.Digest.Equal(digest) {
+			return a.Access
+		}
+	}
+	return nil
+}
+
+func (j *journal) ArtifactTransferred(n common.VersionedElement, kind string, id metav1.Identity, digest *metav1.DigestSpec, acc compdesc.AccessSpec) error {
+	if digest == nil || acc == nil {
+		return nil
+	}
+	u, err := runtime.ToUnstructuredVersionedTypedObject(acc)
+	if err != nil {
+		return err
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	e := j.entry(n)
+	a := JournalArtifact{
+		Kind:     kind,
+		Identity: id.Copy(),
+		Digest:   digest.Copy(),
+		Access:   u,
+	}
+	for i, o := range e.Artifacts {
+		if o.Kind == kind && o.Identity.Equals(id) {
+			e.Artifacts[i] = a
+			return j.save()
+		}
+	}
+	e.Artifacts = append(e.Artifacts, a)
+	return j.save()
+}
+
+// JournalArtifact describes an artifact already transferred to
+// its final location.
+type JournalArtifact struct {
+	Kind     string                                    `json:"kind"`
+	Identity metav1.Identity                           `json:"identity"`
+	Digest   *metav1.DigestSpec                        `json:"digest"`
+	Access   *runtime.UnstructuredVersionedTypedObject `json:"access"`
+}
+
+type JournalEntry struct {
+	Completed bool              `json:"completed,omitempty"`
+	Artifacts []JournalArtifact `json:"artifacts,omitempty"`
+}
+
+type JournalDescriptor struct {
+	ComponentVersions map[string]*JournalEntry `json:"componentVersions,omitempty"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+var journalKey = reflect.TypeOf(journal{})
+
+// WithJournalContext provides a context with a transfer journal,
+// which is used by TransferVersionWithContext.
+func WithJournalContext(ctx context.Context, j Journal) context.Context {
+	if j == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, journalKey, j)
+}
+
+// GetJournal returns the transfer journal configured for the
+// given context, or nil.
+func GetJournal(ctx context.Context) Journal {
+	j := ctx.Value(journalKey)
+	if j == nil {
+		return nil
+	}
+	return j.(Journal)
+}
@@ -0,0 +1,110 @@
+package transfer_test
+
+import (
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/helper/builder"
+	. "ocm.software/ocm/api/ocm/testhelper"
+
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/extensions/accessmethods/ociartifact"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/ocm/tools/transfer"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const (
+	ARCH      = "/tmp/ctf"
+	OUT       = "/tmp/res"
+	JOURNAL   = "/tmp/journal"
+	COMPONENT = "acme.org/test"
+	VERSION   = "v1"
+)
+
+var _ = Describe("transfer journal", func() {
+	var env *Builder
+
+	BeforeEach(func() {
+		env = NewBuilder()
+
+		env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+			env.Component(COMPONENT, func() {
+				env.Version(VERSION, func() {
+					env.Provider("acme.org")
+					TestDataResource(env)
+				})
+			})
+		})
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("persists journal entries", func() {
+		nv := common.NewNameVersion(COMPONENT, VERSION)
+		id := metav1.NewIdentity("image")
+		digest := &metav1.DigestSpec{HashAlgorithm: "SHA-256", NormalisationAlgorithm: "genericBlobDigest/v1", Value: "0815"}
+
+		j := Must(transfer.NewJournal(JOURNAL, env))
+		MustBeSuccessful(j.ArtifactTransferred(nv, "resource", id, digest, ociartifact.New("ghcr.io/acme/image:v1")))
+		Expect(Must(vfs.Exists(env, JOURNAL))).To(BeTrue())
+
+		j = Must(transfer.NewJournal(JOURNAL, env))
+		Expect(j.IsVersionTransferred(nv)).To(BeFalse())
+		Expect(j.GetArtifactAccess(nv, "resource", id, digest)).NotTo(BeNil())
+		Expect(j.GetArtifactAccess(nv, "resource", id, &metav1.DigestSpec{HashAlgorithm: "SHA-256", Value: "0816"})).To(BeNil())
+		Expect(j.GetArtifactAccess(nv, "source", id, digest)).To(BeNil())
+
+		MustBeSuccessful(j.VersionTransferred(nv))
+		j = Must(transfer.NewJournal(JOURNAL, env))
+		Expect(j.IsVersionTransferred(nv)).To(BeTrue())
+		Expect(j.GetArtifactAccess(nv, "resource", id, digest)).To(BeNil())
+		Expect(j.Entries()).To(ConsistOf(nv))
+
+		MustBeSuccessful(j.Reset())
+		j = Must(transfer.NewJournal(JOURNAL, env))
+		Expect(j.Entries()).To(BeEmpty())
+	})
+
+	It("skips journaled component versions", func() {
+		src := Must(ctf.Open(env.OCMContext(), accessobj.ACC_READONLY, ARCH, 0, env))
+		defer Close(src, "source")
+		cv := Must(src.LookupComponentVersion(COMPONENT, VERSION))
+		defer Close(cv, "source cv")
+		tgt := Must(ctf.Create(env.OCMContext(), accessobj.ACC_WRITABLE|accessobj.ACC_CREATE, OUT, 0o700, accessio.FormatDirectory, env))
+		defer Close(tgt, "target")
+
+		j := Must(transfer.NewJournal(JOURNAL, env))
+		MustBeSuccessful(transfer.Transfer(cv, tgt, transfer.WithJournal(j)))
+		Expect(j.IsVersionTransferred(cv)).To(BeTrue())
+
+		p, buf := common.NewBufferedPrinter()
+		j = Must(transfer.NewJournal(JOURNAL, env))
+		MustBeSuccessful(transfer.Transfer(cv, tgt, transfer.WithJournal(j), transfer.WithPrinter(p)))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+transferring version "acme.org/test:v1"...
+  version "acme.org/test:v1" already transferred (journal) -> skip transport
+`))
+	})
+
+	It("transfers journaled component versions missing in target", func() {
+		src := Must(ctf.Open(env.OCMContext(), accessobj.ACC_READONLY, ARCH, 0, env))
+		defer Close(src, "source")
+		cv := Must(src.LookupComponentVersion(COMPONENT, VERSION))
+		defer Close(cv, "source cv")
+		tgt := Must(ctf.Create(env.OCMContext(), accessobj.ACC_WRITABLE|accessobj.ACC_CREATE, OUT, 0o700, accessio.FormatDirectory, env))
+		defer Close(tgt, "target")
+
+		j := transfer.NewLocalJournal()
+		MustBeSuccessful(j.VersionTransferred(cv))
+
+		MustBeSuccessful(transfer.Transfer(cv, tgt, transfer.WithJournal(j)))
+		Expect(Must(tgt.ExistsComponentVersion(COMPONENT, VERSION))).To(BeTrue())
+	})
+})
@@ -28,6 +28,10 @@ type (
 	// over the transfer process, whose general flow is handled by
 	// a uniform Transfer function.
 	TransferHandler = transferhandler.TransferHandler
+
+	// VersionValidator is an optional interface for a TransferHandler
+	// used to validate source component versions before they are transferred.
+	VersionValidator = transferhandler.VersionValidator
 )
 
 // Local options do not relate to the transfer handler, but directly to the
@@ -37,6 +41,7 @@ type (
 // the transferhandler.TransferOptionsCreator interface.
 type localOptions struct {
 	printer common.Printer
+	journal Journal
 }
 
 func (opts *localOptions) Eval(optlist ...transferhandler.TransferOption) error {
@@ -59,11 +64,22 @@ func WithPrinter(p common.Printer) transferhandler.TransferOption {
 	}
 }
 
+// WithJournal provides a transfer journal used to record the progress
+// of the transfer. Work already recorded in the journal is skipped.
+func WithJournal(j Journal) transferhandler.TransferOption {
+	return &localOptions{
+		journal: j,
+	}
+}
+
 func (l *localOptions) ApplyTransferOption(options TransferOptions) error {
 	if t, ok := options.(*localOptions); ok {
 		if l.printer != nil {
 			t.printer = l.printer
 		}
+		if l.journal != nil {
+			t.journal = l.journal
+		}
 	}
 	return nil
 }
@@ -0,0 +1,309 @@
+package transfer
+
+import (
+	"context"
+
+	"github.com/mandelsoft/goutils/errors"
+
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/cpi/accspeccpi"
+	"ocm.software/ocm/api/ocm/extensions/accessmethods/none"
+	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler/standard"
+	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+// PlanAction describes the action planned for a component version
+// or artifact.
+type PlanAction string
+
+const (
+	// PLAN_CREATE creates a component version not yet present in the target.
+	PLAN_CREATE PlanAction = "create"
+	// PLAN_OVERWRITE replaces a differing component version in the target.
+	PLAN_OVERWRITE PlanAction = "overwrite"
+	// PLAN_UPDATE updates volatile or artifact information of
+	// a component version present in the target.
+	PLAN_UPDATE PlanAction = "update"
+	// PLAN_SKIP leaves a component version in the target untouched.
+	PLAN_SKIP PlanAction = "skip"
+	// PLAN_ABORT describes a component version, whose transfer would fail,
+	// because it differs from the version present in the target.
+	PLAN_ABORT PlanAction = "abort"
+
+	// PLAN_COPY copies an artifact by value.
+	PLAN_COPY PlanAction = "copy"
+	// PLAN_REFERENCE keeps the access of an artifact (by-reference transport).
+	PLAN_REFERENCE PlanAction = "reference"
+	// PLAN_PRESENT reuses an artifact already present in the target.
+	PLAN_PRESENT PlanAction = "present"
+	// PLAN_KEEP keeps the artifact of a component version only updated in
+	// its volatile properties.
+	PLAN_KEEP PlanAction = "keep"
+)
+
+// Plan describes the actions a transfer would execute.
+type Plan struct {
+	ComponentVersions []*PlannedVersion `json:"componentVersions"`
+	// Size is the estimated number of bytes copied by value.
+	Size int64 `json:"estimatedSize,omitempty"`
+	// UnknownSizes is the number of artifacts copied by value
+	// without known size.
+	UnknownSizes int `json:"unknownSizes,omitempty"`
+}
+
+// PlannedVersion describes the action planned for a dedicated
+// component version.
+type PlannedVersion struct {
+	Component  string             `json:"component"`
+	Version    string             `json:"version"`
+	Action     PlanAction         `json:"action"`
+	Reason     string             `json:"reason,omitempty"`
+	References []string           `json:"references,omitempty"`
+	Resources  []*PlannedArtifact `json:"resources,omitempty"`
+	Sources    []*PlannedArtifact `json:"sources,omitempty"`
+}
+
+// PlannedArtifact describes the action planned for a resource or source.
+type PlannedArtifact struct {
+	Name       string          `json:"name"`
+	Identity   metav1.Identity `json:"identity"`
+	Type       string          `json:"type"`
+	AccessType string          `json:"accessType"`
+	Action     PlanAction      `json:"action"`
+	Size       int64           `json:"size,omitempty"`
+}
+
+// Planner evaluates the transfer of component versions without
+// modifying the target. It walks the same graph as TransferVersion
+// and consults the transfer handler for the same decisions.
+type Planner struct {
+	closure       TransportClosure
+	plan          *Plan
+	estimateSizes bool
+}
+
+// NewPlanner creates a planner. If estimateSizes is set, the size of
+// artifacts copied by value is determined, which might require to read
+// the artifact content.
+func NewPlanner(closure TransportClosure, estimateSizes bool) *Planner {
+	if closure == nil {
+		closure = TransportClosure{}
+	}
+	return &Planner{
+		closure:       closure,
+		plan:          &Plan{},
+		estimateSizes: estimateSizes,
+	}
+}
+
+// Plan returns the plan gathered so far.
+func (p *Planner) Plan() *Plan {
+	return p.plan
+}
+
+// PlanTransfer evaluates the plan for the transfer of a component version
+// using the transfer handler based on the given options. The plan includes
+// the size estimation for artifacts copied by value.
+func PlanTransfer(cv ocm.ComponentVersionAccess, tgt ocm.Repository, optlist ...TransferOption) (*Plan, error) {
+	h, err := NewTransferHandler(optlist...)
+	if err != nil {
+		return nil, err
+	}
+	p := NewPlanner(nil, true)
+	err = p.PlanVersion(context.Background(), cv, tgt, h)
+	if err != nil {
+		return nil, err
+	}
+	return p.Plan(), nil
+}
+
+// PlanVersion adds the planned actions for the transfer of the given
+// component version to the plan. The target repository may be nil,
+// if it does not exist, yet.
+func (p *Planner) PlanVersion(ctx context.Context, src ocm.ComponentVersionAccess, tgt ocm.Repository, handler TransferHandler) error {
+	return p.planVersion(ctx, NewWalkingState(p.closure), src, tgt, handler)
+}
+
+func (p *Planner) planVersion(ctx context.Context, state WalkingState, src ocm.ComponentVersionAccess, tgt ocm.Repository, handler TransferHandler) error {
+	if err := common.IsContextCanceled(ctx); err != nil {
+		return err
+	}
+	nv := common.VersionedElementKey(src)
+	if ok, err := state.Add(ocm.KIND_COMPONENTVERSION, nv); !ok {
+		return err
+	}
+	if handler == nil {
+		var err error
+		handler, err = standard.New(standard.Overwrite())
+		if err != nil {
+			return err
+		}
+	}
+
+	d := src.GetDescriptor()
+	entry := &PlannedVersion{
+		Component: nv.GetName(),
+		Version:   nv.GetVersion(),
+		Action:    PLAN_CREATE,
+	}
+	p.plan.ComponentVersions = append(p.plan.ComponentVersions, entry)
+
+	var cur *compdesc.ComponentDescriptor
+	if tgt != nil {
+		t, err := tgt.LookupComponentVersion(src.GetName(), src.GetVersion())
+		if err != nil {
+			if !errors.IsErrNotFound(err) {
+				return errors.Wrapf(err, "%s: lookup target version", state.History)
+			}
+		} else {
+			defer t.Close()
+			cur = t.GetDescriptor().Copy()
+			err = p.planVersionAction(entry, src, t, handler)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if entry.Action == PLAN_ABORT || entry.Reason == reasonSkippedUpdate {
+		// like TransferVersion, references are not considered anymore.
+		return nil
+	}
+
+	for _, ref := range d.References {
+		cv, shdlr, err := handler.TransferVersion(src.Repository(), src, &ref, tgt)
+		if err != nil {
+			return errors.Wrapf(err, "%s: nested component %s[%s:%s]",
+				state.History, ref.GetName(), ref.ComponentName, ref.GetVersion())
+		}
+		if cv != nil {
+			entry.References = append(entry.References, common.VersionedElementKey(cv).String())
+			err = p.planVersion(ctx, state, cv, tgt, shdlr)
+			cv.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	switch entry.Action {
+	case PLAN_SKIP, PLAN_ABORT:
+		return nil
+	case PLAN_CREATE:
+		cur = nil
+	}
+	keep := entry.Action == PLAN_UPDATE && entry.Reason == reasonVolatile
+	return p.planArtifacts(entry, src, cur, handler, keep)
+}
+
+// planVersionAction determines the action for a component version
+// already present in the target following the rules of transferVersion.
+func (p *Planner) planVersionAction(entry *PlannedVersion, src, t ocm.ComponentVersionAccess, handler TransferHandler) error {
+	dec, err := decideVersion(src, t, handler)
+	if err != nil {
+		return err
+	}
+	entry.Action = dec.action
+	entry.Reason = dec.reason
+	if dec.action == PLAN_OVERWRITE && dec.reason != reasonEnforced && dec.reason != reasonVolatileOverwrite {
+		entry.Reason += " (transport enforced by overwrite option)"
+	}
+	return nil
+}
+
+func (p *Planner) planArtifacts(entry *PlannedVersion, src ocm.ComponentVersionAccess, cur *compdesc.ComponentDescriptor, handler TransferHandler, keep bool) error {
+	d := src.GetDescriptor()
+	for _, r := range src.GetResources() {
+		a, err := r.Access()
+		if err != nil {
+			return err
+		}
+		pa := &PlannedArtifact{
+			Name:       r.Meta().GetName(),
+			Identity:   r.Meta().GetIdentity(d.Resources),
+			Type:       r.Meta().GetType(),
+			AccessType: a.GetType(),
+			Action:     PLAN_REFERENCE,
+		}
+		entry.Resources = append(entry.Resources, pa)
+		if keep {
+			pa.Action = PLAN_KEEP
+			continue
+		}
+
+		byValue := a.IsLocal(src.GetContext())
+		if !byValue && !none.IsNone(a.GetKind()) {
+			byValue, err = handler.TransferResource(src, a, r)
+			if err != nil {
+				return err
+			}
+		}
+		if !byValue {
+			continue
+		}
+		if cur != nil {
+			old, err := cur.GetResourceByIdentity(pa.Identity)
+			if err == nil && old.Digest != nil && old.Digest.Equal(r.Meta().Digest) && !needsTransport(src.GetContext(), r, &old) {
+				pa.Action = PLAN_PRESENT
+				continue
+			}
+		}
+		pa.Action = PLAN_COPY
+		p.estimate(src, a, pa)
+	}
+
+	for _, s := range src.GetSources() {
+		a, err := s.Access()
+		if err != nil {
+			return err
+		}
+		pa := &PlannedArtifact{
+			Name:       s.Meta().GetName(),
+			Identity:   s.Meta().GetIdentity(d.Sources),
+			Type:       s.Meta().GetType(),
+			AccessType: a.GetType(),
+			Action:     PLAN_REFERENCE,
+		}
+		entry.Sources = append(entry.Sources, pa)
+		if keep {
+			pa.Action = PLAN_KEEP
+			continue
+		}
+
+		byValue := a.IsLocal(src.GetContext())
+		if !byValue && !none.IsNone(a.GetKind()) {
+			byValue, err = handler.TransferSource(src, a, s)
+			if err != nil {
+				return err
+			}
+		}
+		if byValue {
+			// sources do not have digests so far, so they have to be copied, always.
+			pa.Action = PLAN_COPY
+			p.estimate(src, a, pa)
+		}
+	}
+	return nil
+}
+
+func (p *Planner) estimate(src ocm.ComponentVersionAccess, a ocm.AccessSpec, pa *PlannedArtifact) {
+	pa.Size = blobaccess.BLOB_UNKNOWN_SIZE
+	if p.estimateSizes {
+		if m, err := a.AccessMethod(src); err == nil {
+			if blob, err := accspeccpi.BlobAccessForAccessMethod(m); err == nil {
+				pa.Size = blob.Size()
+				blob.Close()
+			}
+			m.Close()
+		}
+	}
+	if pa.Size == blobaccess.BLOB_UNKNOWN_SIZE {
+		pa.Size = 0
+		p.plan.UnknownSizes++
+	} else {
+		p.plan.Size += pa.Size
+	}
+}
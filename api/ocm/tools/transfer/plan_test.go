@@ -0,0 +1,120 @@
+package transfer_test
+
+import (
+	"context"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/helper/builder"
+	. "ocm.software/ocm/api/ocm/testhelper"
+
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/ocm/tools/transfer"
+	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler/standard"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+	"ocm.software/ocm/api/utils/runtime"
+)
+
+const (
+	COMPONENT2 = "acme.org/test2"
+)
+
+var _ = Describe("transfer plan", func() {
+	var env *Builder
+	var src ocm.Repository
+	var cv ocm.ComponentVersionAccess
+
+	BeforeEach(func() {
+		env = NewBuilder()
+
+		env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+			env.Component(COMPONENT, func() {
+				env.Version(VERSION, func() {
+					env.Provider("acme.org")
+					TestDataResource(env)
+				})
+			})
+			env.Component(COMPONENT2, func() {
+				env.Version(VERSION, func() {
+					env.Provider("acme.org")
+					env.Reference("ref", COMPONENT, VERSION)
+				})
+			})
+		})
+		src = Must(ctf.Open(env.OCMContext(), accessobj.ACC_READONLY, ARCH, 0, env))
+		cv = Must(src.LookupComponentVersion(COMPONENT2, VERSION))
+	})
+
+	AfterEach(func() {
+		Close(cv, "source cv")
+		Close(src, "source")
+		env.Cleanup()
+	})
+
+	It("plans transfer into missing target", func() {
+		p := transfer.NewPlanner(nil, true)
+		MustBeSuccessful(p.PlanVersion(context.Background(), cv, nil, Must(standard.New(standard.Recursive()))))
+
+		data := Must(runtime.DefaultYAMLEncoding.Marshal(p.Plan()))
+		Expect(data).To(YAMLEqual(`
+componentVersions:
+- action: create
+  component: acme.org/test2
+  references:
+  - acme.org/test:v1
+  version: v1
+- action: create
+  component: acme.org/test
+  resources:
+  - accessType: localBlob
+    action: copy
+    identity:
+      name: testdata
+    name: testdata
+    size: 8
+    type: PlainText
+  version: v1
+estimatedSize: 8
+`))
+	})
+
+	It("plans transfer into existing target", func() {
+		tgt := Must(ctf.Create(env.OCMContext(), accessobj.ACC_WRITABLE|accessobj.ACC_CREATE, OUT, 0o700, accessio.FormatDirectory, env))
+		defer Close(tgt, "target")
+
+		cv1 := Must(src.LookupComponentVersion(COMPONENT, VERSION))
+		defer Close(cv1, "source cv")
+		MustBeSuccessful(transfer.Transfer(cv1, tgt))
+
+		plan := Must(transfer.PlanTransfer(cv, tgt, standard.Recursive()))
+		data := Must(runtime.DefaultYAMLEncoding.Marshal(plan))
+		Expect(data).To(YAMLEqual(`
+componentVersions:
+- action: create
+  component: acme.org/test2
+  references:
+  - acme.org/test:v1
+  version: v1
+- action: skip
+  component: acme.org/test
+  reason: already present
+  version: v1
+`))
+	})
+
+	It("estimates sizes", func() {
+		plan := Must(transfer.PlanTransfer(cv, nil, standard.Recursive()))
+		Expect(plan.Size).To(Equal(int64(8)))
+		Expect(plan.UnknownSizes).To(Equal(0))
+		Expect(plan.ComponentVersions[1].Resources[0].Size).To(Equal(int64(8)))
+	})
+
+	It("plans transfer without recursion", func() {
+		plan := Must(transfer.PlanTransfer(cv, nil))
+		Expect(plan.ComponentVersions).To(HaveLen(1))
+		Expect(plan.ComponentVersions[0].References).To(BeNil())
+	})
+})
@@ -3,13 +3,17 @@ package transfer
 import (
 	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 
 	"github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/goutils/finalizer"
 	"github.com/mandelsoft/logging"
 
+	"ocm.software/ocm/api/datacontext/attrs/progressattr"
 	"ocm.software/ocm/api/ocm"
 	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
 	ocmcpi "ocm.software/ocm/api/ocm/cpi"
 	"ocm.software/ocm/api/ocm/extensions/accessmethods/none"
 	cpi "ocm.software/ocm/api/ocm/internal"
@@ -18,22 +22,52 @@ import (
 	"ocm.software/ocm/api/utils/concurrency"
 	"ocm.software/ocm/api/utils/errkind"
 	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/progress"
 	runtimeutil "ocm.software/ocm/api/utils/runtime"
 )
 
-type WalkingState = common.WalkingState[*struct{}, interface{}]
-
 type TransportClosure = common.NameVersionInfo[*struct{}]
 
-func TransferVersion(printer common.Printer, closure TransportClosure, src ocmcpi.ComponentVersionAccess, tgt ocmcpi.Repository, handler TransferHandler) error {
-	return TransferVersionWithContext(common.WithPrinter(context.Background(), common.AssurePrinter(printer)), closure, src, tgt, handler)
+// WalkingState is the state used to walk the component version graph.
+// Copies of a state share the transport closure, which is shared
+// among component versions transferred concurrently, and the lock
+// guarding it.
+type WalkingState struct {
+	common.WalkingState[*struct{}, interface{}]
+	closureLock *sync.Mutex
 }
 
-func TransferVersionWithContext(ctx context.Context, closure TransportClosure, src ocmcpi.ComponentVersionAccess, tgt ocmcpi.Repository, handler TransferHandler) error {
+func NewWalkingState(closure TransportClosure) WalkingState {
 	if closure == nil {
 		closure = TransportClosure{}
 	}
-	state := WalkingState{Closure: closure}
+	return WalkingState{
+		WalkingState: common.WalkingState[*struct{}, interface{}]{Closure: closure},
+		closureLock:  &sync.Mutex{},
+	}
+}
+
+func (s *WalkingState) addToClosure(nv common.NameVersion) (bool, error) {
+	s.closureLock.Lock()
+	defer s.closureLock.Unlock()
+	return s.Add(ocm.KIND_COMPONENTVERSION, nv)
+}
+
+// workerPrinter tags the output of a task with the worker
+// executing it, if the task is executed by a worker pool.
+func workerPrinter(ctx context.Context, printer common.Printer) common.Printer {
+	if idx, ok := concurrency.WorkerIndex(ctx); ok {
+		return common.AssurePrinter(printer).AddGap(fmt.Sprintf("[worker %d] ", idx))
+	}
+	return printer
+}
+
+func TransferVersion(printer common.Printer, closure TransportClosure, src ocmcpi.ComponentVersionAccess, tgt ocmcpi.Repository, handler TransferHandler) error {
+	return TransferVersionWithContext(common.WithPrinter(context.Background(), common.AssurePrinter(printer)), closure, src, tgt, handler)
+}
+
+func TransferVersionWithContext(ctx context.Context, closure TransportClosure, src ocmcpi.ComponentVersionAccess, tgt ocmcpi.Repository, handler TransferHandler) error {
+	state := NewWalkingState(closure)
 	return transferVersion(ctx, Logger(src), state, src, tgt, handler)
 }
 
@@ -45,11 +79,22 @@ func transferVersion(ctx context.Context, log logging.Logger, state WalkingState
 	}
 	nv := common.VersionedElementKey(src)
 	log = log.WithValues("history", state.History.String(), "version", nv)
-	if ok, err := state.Add(ocm.KIND_COMPONENTVERSION, nv); !ok {
+	if ok, err := state.addToClosure(nv); !ok {
 		return err
 	}
 	log.Info("transferring version")
 	printer.Printf("transferring version %q...\n", nv)
+	tracker := progressattr.For(src.GetContext(), progress.OPERATION_TRANSFER, progress.KIND_COMPONENTVERSION, "", nv.String())
+	tracker.Started()
+	defer func() { tracker.Finished(rerr) }()
+	journal := GetJournal(ctx)
+	if journal != nil && journal.IsVersionTransferred(nv) {
+		if ok, err := tgt.ExistsComponentVersion(src.GetName(), src.GetVersion()); ok && err == nil {
+			log.Info("version already transferred according to journal")
+			printer.Printf("  version %q already transferred (journal) -> skip transport\n", nv)
+			return nil
+		}
+	}
 	if handler == nil {
 		var err error
 		handler, err = standard.New(standard.Overwrite())
@@ -58,6 +103,12 @@ func transferVersion(ctx context.Context, log logging.Logger, state WalkingState
 		}
 	}
 
+	if v, ok := handler.(VersionValidator); ok {
+		if err := v.ValidateVersion(src); err != nil {
+			return errors.Wrapf(err, "%s: validation failed", state.History)
+		}
+	}
+
 	var finalize finalizer.Finalizer
 	defer finalize.FinalizeWithErrorPropagation(&rerr)
 
@@ -69,7 +120,6 @@ func transferVersion(ctx context.Context, log logging.Logger, state WalkingState
 	}
 	finalize.Close(comp, "closing target component")
 
-	var ok bool
 	t, err := comp.LookupVersion(src.GetVersion())
 	finalize.Close(t, "existing target version")
 
@@ -94,71 +144,39 @@ func transferVersion(ctx context.Context, log logging.Logger, state WalkingState
 			finalize.Close(t, "new target version")
 		}
 	} else {
-		ok, err = handler.EnforceTransport(src, t)
+		var dec *versionDecision
+		dec, err = decideVersion(src, t, handler)
 		if err != nil {
 			return err
 		}
-		if ok {
+		switch dec.reason {
+		case reasonEnforced:
 			//  execute transport as if the component version were not present
 			//  on the target side.
-		} else {
-			// determine transport mode for component version present
-			// on the target side.
-			if eq := d.Equivalent(t.GetDescriptor()); eq.IsHashEqual() {
-				if eq.IsEquivalent() {
-					if !needsResourceTransport(src, d, t.GetDescriptor(), handler) {
-						printer.Printf("  version %q already present -> skip transport\n", nv)
-						doTransport = false
-					} else {
-						printer.Printf("  version %q already present -> but requires resource transport\n", nv)
-					}
-				} else {
-					ok, err = handler.UpdateVersion(src, t)
-					if err != nil {
-						return err
-					}
-					if !ok {
-						printer.Printf("  version %q requires update of volatile data, but skipped\n", nv)
-						return nil
-					}
-					ok, err = handler.OverwriteVersion(src, t)
-					if ok {
-						printer.Printf("  warning: version %q already present, but transport enforced by overwrite option)\n", nv)
-						doMerge = false
-						doCopy = true
-					} else {
-						printer.Printf("  updating volatile properties of %q\n", nv)
-						doMerge = true
-						doCopy = false
-					}
-				}
+		case reasonPresent:
+			printer.Printf("  version %q already present -> skip transport\n", nv)
+			doTransport = false
+		case reasonResources:
+			printer.Printf("  version %q already present -> but requires resource transport\n", nv)
+		case reasonSkippedUpdate:
+			printer.Printf("  version %q requires update of volatile data, but skipped\n", nv)
+			return nil
+		case reasonVolatileOverwrite:
+			printer.Printf("  warning: version %q already present, but transport enforced by overwrite option)\n", nv)
+			doMerge = false
+			doCopy = true
+		case reasonVolatile:
+			printer.Printf("  updating volatile properties of %q\n", nv)
+			doMerge = true
+			doCopy = false
+		default:
+			msg := "  version %q already present, but " + dec.reason
+			if dec.action == PLAN_OVERWRITE {
+				doMerge = false
+				printer.Printf("warning: "+msg+" (transport enforced by overwrite option)\n", nv)
 			} else {
-				msg := "  version %q already present, but"
-				if eq.IsLocalHashEqual() {
-					if eq.IsArtifactDetectable() {
-						msg += " differs because some artifact digests are changed"
-					} else {
-						msg += " might differ, because not all artifact digests are known"
-					}
-				} else {
-					if eq.IsArtifactDetectable() {
-						if eq.IsArtifactEqual() {
-							msg += " differs because signature relevant properties have been changed"
-						} else {
-							msg += " differs because some artifacts and signature relevant properties have been changed"
-						}
-					} else {
-						msg += "differs because signature relevant properties have been changed (and not all artifact digests are known)"
-					}
-				}
-				ok, err = handler.OverwriteVersion(src, t)
-				if ok {
-					doMerge = false
-					printer.Printf("warning: "+msg+" (transport enforced by overwrite option)\n", nv)
-				} else {
-					printer.Printf(msg+" -> transport aborted (use option overwrite option to enforce transport)\n", nv)
-					return errors.ErrAlreadyExists(ocm.KIND_COMPONENTVERSION, nv.String())
-				}
+				printer.Printf(msg+" -> transport aborted (use option overwrite option to enforce transport)\n", nv)
+				return errors.ErrAlreadyExists(ocm.KIND_COMPONENTVERSION, nv.String())
 			}
 		}
 	}
@@ -211,12 +229,101 @@ func transferVersion(ctx context.Context, log logging.Logger, state WalkingState
 		log.Info("  adding component version")
 		list.Add(comp.AddVersion(t))
 	}
+	if journal != nil && list.Result() == nil {
+		list.Add(journal.VersionTransferred(nv))
+	}
 	return list.Result()
 }
 
+const (
+	reasonEnforced          = "transport enforced"
+	reasonPresent           = "already present"
+	reasonResources         = "already present, but requires resource transport"
+	reasonSkippedUpdate     = "requires update of volatile data, but skipped"
+	reasonVolatile          = "update of volatile properties"
+	reasonVolatileOverwrite = "volatile data changed, transport enforced by overwrite option"
+)
+
+// versionDecision describes the handling of a component version
+// already present in the target.
+type versionDecision struct {
+	action PlanAction
+	reason string
+}
+
+// decideVersion determines the handling of a component version already
+// present in the target. It is shared by the transfer and the transfer
+// planner to keep their decisions consistent.
+func decideVersion(src, t ocm.ComponentVersionAccess, handler TransferHandler) (*versionDecision, error) {
+	ok, err := handler.EnforceTransport(src, t)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return &versionDecision{PLAN_OVERWRITE, reasonEnforced}, nil
+	}
+
+	d := src.GetDescriptor()
+	eq := d.Equivalent(t.GetDescriptor())
+	if eq.IsHashEqual() {
+		if eq.IsEquivalent() {
+			if !needsResourceTransport(src, d, t.GetDescriptor(), handler) {
+				return &versionDecision{PLAN_SKIP, reasonPresent}, nil
+			}
+			return &versionDecision{PLAN_UPDATE, reasonResources}, nil
+		}
+		ok, err = handler.UpdateVersion(src, t)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return &versionDecision{PLAN_SKIP, reasonSkippedUpdate}, nil
+		}
+		ok, err = handler.OverwriteVersion(src, t)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &versionDecision{PLAN_OVERWRITE, reasonVolatileOverwrite}, nil
+		}
+		return &versionDecision{PLAN_UPDATE, reasonVolatile}, nil
+	}
+
+	var reason string
+	if eq.IsLocalHashEqual() {
+		if eq.IsArtifactDetectable() {
+			reason = "differs because some artifact digests are changed"
+		} else {
+			reason = "might differ, because not all artifact digests are known"
+		}
+	} else {
+		if eq.IsArtifactDetectable() {
+			if eq.IsArtifactEqual() {
+				reason = "differs because signature relevant properties have been changed"
+			} else {
+				reason = "differs because some artifacts and signature relevant properties have been changed"
+			}
+		} else {
+			reason = "differs because signature relevant properties have been changed (and not all artifact digests are known)"
+		}
+	}
+	ok, err = handler.OverwriteVersion(src, t)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return &versionDecision{PLAN_OVERWRITE, reason}, nil
+	}
+	return &versionDecision{PLAN_ABORT, reason}, nil
+}
+
 func transferReferences(ctx context.Context, log logging.Logger, state WalkingState, src ocmcpi.ComponentVersionAccess, tgt ocmcpi.Repository, handler TransferHandler, d *compdesc.ComponentDescriptor) error {
 	if len(d.References) > 0 {
 		if err := concurrency.RunInWorkerPool(ctx, src.GetContext(), d.References, func(ctx context.Context, ref compdesc.Reference) error {
+			// the history is extended by every nested transfer, so it
+			// must not be shared among concurrently executed tasks.
+			state := state
+			state.History = state.History.Copy()
 			return transferReference(ctx, log, state, src, tgt, handler, ref)
 		}); err != nil {
 			return err
@@ -233,7 +340,11 @@ func transferReference(ctx context.Context, log logging.Logger, state WalkingSta
 	}
 	if cv != nil {
 		defer cv.Close()
-		if err := transferVersion(common.AddPrinterGap(ctx, "  "),
+		gap := "  "
+		if idx, ok := concurrency.WorkerIndex(ctx); ok {
+			gap += fmt.Sprintf("[worker %d] ", idx)
+		}
+		if err := transferVersion(common.AddPrinterGap(ctx, gap),
 			log.WithValues("ref", ref.Name), state, cv, tgt, shdlr); err != nil {
 			return errors.Wrapf(err, "%s: transferring reference %s[%s:%s]",
 				state.History, ref.GetName(), ref.ComponentName, ref.GetVersion())
@@ -312,7 +423,7 @@ func copyVersionWithWorkers(
 ) error {
 	type transferTask struct {
 		id   string
-		exec func(ctx context.Context) error
+		exec func(ctx context.Context, printer common.Printer) error
 	}
 
 	var tasks []transferTask
@@ -321,26 +432,33 @@ func copyVersionWithWorkers(
 	for i, r := range src.GetResources() {
 		tasks = append(tasks, transferTask{
 			id: fmt.Sprintf("resource-%d", i),
-			exec: func(ctx context.Context) error {
-				return copyResource(src, finalize, hist, handler, curDesc, srcDesc, printer, log, target, r, i)
+			exec: func(ctx context.Context, printer common.Printer) error {
+				return copyResource(ctx, src, finalize, hist, handler, curDesc, srcDesc, printer, log, target, r, i)
 			},
 		})
 	}
 	for i, s := range src.GetSources() {
 		tasks = append(tasks, transferTask{
 			id: fmt.Sprintf("source-%d", i),
-			exec: func(ctx context.Context) error {
+			exec: func(ctx context.Context, printer common.Printer) error {
 				return copySource(ctx, src, hist, s, handler, printer, log, i, target)
 			},
 		})
 	}
 
-	// Run all tasks using the generic worker pool
+	// Run all tasks using the generic worker pool.
+	// Resources and sources are updated in place in the target descriptor,
+	// so the resulting descriptor does not depend on the execution order.
+	var finished atomic.Int32
 	return concurrency.RunInWorkerPool(ctx, src.GetContext(), tasks, func(ctx context.Context, t transferTask) error {
 		log.Debug("starting transfer task", "task", t.id)
-		if err := t.exec(ctx); err != nil {
+		p := workerPrinter(ctx, printer)
+		if err := t.exec(ctx, p); err != nil {
 			return fmt.Errorf("%s failed: %w", t.id, err)
 		}
+		if _, ok := concurrency.WorkerIndex(ctx); ok {
+			p.Printf("...finished %s (%d/%d)\n", t.id, finished.Add(1), len(tasks))
+		}
 		return nil
 	})
 }
@@ -384,7 +502,7 @@ func copySource(cctx context.Context, src ocm.ComponentVersionAccess, hist commo
 	return nil
 }
 
-func copyResource(src ocm.ComponentVersionAccess, finalize *finalizer.Finalizer, hist common.History, handler TransferHandler, currentDesc, sourceDesc *compdesc.ComponentDescriptor, printer common.Printer, log logging.Logger, t ocm.ComponentVersionAccess, r cpi.ResourceAccess, i int) error {
+func copyResource(cctx context.Context, src ocm.ComponentVersionAccess, finalize *finalizer.Finalizer, hist common.History, handler TransferHandler, currentDesc, sourceDesc *compdesc.ComponentDescriptor, printer common.Printer, log logging.Logger, t ocm.ComponentVersionAccess, r cpi.ResourceAccess, i int) error {
 	nested := finalize.Nested()
 	a, err := r.Access()
 	if err != nil {
@@ -424,8 +542,25 @@ func copyResource(src ocm.ComponentVersionAccess, finalize *finalizer.Finalizer,
 				msgs = append(msgs, "overwrite")
 			}
 		}
+		id := r.Meta().GetIdentity(sourceDesc.Resources)
+		journal := GetJournal(cctx)
+		if journal != nil {
+			if acc := journal.GetArtifactAccess(src, "resource", id, r.Meta().Digest); acc != nil {
+				if err := t.SetResource(r.Meta(), acc, ocm.ModifyElement(), ocm.SkipVerify(), ocm.DisableExtraIdentityDefaulting()); err != nil {
+					return fmt.Errorf("failed to set resource based on journaled access method %d: %w", i, err)
+				}
+				notifyArtifactInfo(printer, log, "resource", i, r.Meta(), hint, "already transferred (journal)")
+				return nil
+			}
+		}
 		notifyArtifactInfo(printer, log, "resource", i, r.Meta(), hint, msgs...)
-		return handler.HandleTransferResource(r, m, hint, t)
+		if err := handler.HandleTransferResource(r, m, hint, t); err != nil {
+			return err
+		}
+		if journal != nil {
+			return journalResource(journal, src, t, id, r.Meta().Digest)
+		}
+		return nil
 	}
 
 	if err := t.SetResource(r.Meta(), old.Access, ocm.ModifyElement(), ocm.SkipVerify(), ocm.DisableExtraIdentityDefaulting()); err != nil {
@@ -434,3 +569,22 @@ func copyResource(src ocm.ComponentVersionAccess, finalize *finalizer.Finalizer,
 	notifyArtifactInfo(printer, log, "resource", i, r.Meta(), hint, "already present")
 	return nil
 }
+
+// journalResource records the access of a transferred resource in the
+// journal, if it describes a location outside the target component version.
+// Local blobs are only persisted together with the component version and
+// therefore cannot be reused by a subsequent transfer.
+func journalResource(journal Journal, src, t ocm.ComponentVersionAccess, id metav1.Identity, digest *metav1.DigestSpec) error {
+	if digest == nil {
+		return nil
+	}
+	r, err := t.GetDescriptor().GetResourceByIdentity(id)
+	if err != nil {
+		return err
+	}
+	acc, err := t.GetContext().AccessSpecForSpec(r.Access)
+	if err != nil || acc.IsLocal(t.GetContext()) {
+		return nil
+	}
+	return journal.ArtifactTransferred(src, "resource", id, digest, r.Access)
+}
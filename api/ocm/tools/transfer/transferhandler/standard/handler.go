@@ -1,29 +1,51 @@
 package standard
 
 import (
+	"sync"
 	"time"
 
 	"github.com/mandelsoft/goutils/errors"
 
+	"ocm.software/ocm/api/datacontext/attrs/progressattr"
 	"ocm.software/ocm/api/ocm"
 	"ocm.software/ocm/api/ocm/compdesc"
 	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
 	"ocm.software/ocm/api/ocm/cpi"
 	"ocm.software/ocm/api/ocm/cpi/accspeccpi"
 	"ocm.software/ocm/api/ocm/resolvers"
+	"ocm.software/ocm/api/ocm/tools/signing"
 	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler"
 	"ocm.software/ocm/api/utils/accessio"
+	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/progress"
 )
 
 type Handler struct {
 	opts *Options
+	// policy is shared by copies of the handler to check
+	// every component version only once.
+	policy *policyChecker
+}
+
+type policyChecker struct {
+	lock    sync.Mutex
+	checker *signing.TrustPolicyChecker
+}
+
+func (c *policyChecker) get(policy *signing.TrustPolicy) *signing.TrustPolicyChecker {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.checker == nil {
+		c.checker = signing.NewTrustPolicyChecker(policy)
+	}
+	return c.checker
 }
 
 func NewDefaultHandler(opts *Options) *Handler {
 	if opts == nil {
 		opts = &Options{}
 	}
-	return &Handler{opts: opts}
+	return &Handler{opts: opts, policy: &policyChecker{}}
 }
 
 func New(opts ...transferhandler.TransferOption) (transferhandler.TransferHandler, error) {
@@ -61,6 +83,23 @@ func (h *Handler) TransferVersion(repo ocm.Repository, src ocm.ComponentVersionA
 	return nil, nil, nil
 }
 
+var _ transferhandler.VersionValidator = (*Handler)(nil)
+
+// ValidateVersion checks a source component version against the configured
+// trust policy.
+func (h *Handler) ValidateVersion(src ocm.ComponentVersionAccess) error {
+	policy, opts := h.opts.GetTrustPolicy()
+	if policy == nil {
+		return nil
+	}
+	resolver := resolvers.NewCompoundResolver(src.Repository(), h.opts.GetResolver())
+	opts = append([]signing.Option{signing.Resolver(resolver)}, opts...)
+	if h.policy == nil {
+		return signing.CheckTrustPolicy(src, policy, opts...)
+	}
+	return h.policy.get(policy).Check(src, opts...)
+}
+
 func (h *Handler) TransferResource(src ocm.ComponentVersionAccess, a ocm.AccessSpec, r ocm.ResourceAccess) (bool, error) {
 	if h.opts.IsAccessTypeOmitted(a.GetType()) {
 		return false, nil
@@ -80,28 +119,57 @@ func (h *Handler) TransferSource(src ocm.ComponentVersionAccess, a ocm.AccessSpe
 	return h.opts.IsSourcesByValue(), nil
 }
 
-func (h *Handler) HandleTransferResource(r ocm.ResourceAccess, m cpi.AccessMethod, hint string, t ocm.ComponentVersionAccess) error {
+func (h *Handler) HandleTransferResource(r ocm.ResourceAccess, m cpi.AccessMethod, hint string, t ocm.ComponentVersionAccess) (rerr error) {
+	tracker := progressattr.For(t.GetContext(), progress.OPERATION_TRANSFER, progress.KIND_RESOURCE, common.VersionedElementKey(t).String(), r.Meta().GetName())
+	tracker.Started()
+	defer func() { tracker.Finished(rerr) }()
+
 	blob, err := accspeccpi.BlobAccessForAccessMethod(m)
 	if err != nil {
 		return err
 	}
 	defer blob.Close()
-	return accessio.Retry(h.opts.GetRetries(), time.Second, func() error {
+	blob = progress.AnnotatedBlobAccess(tracker, blob)
+	err = retry(tracker, h.opts.GetRetries(), func() error {
 		return t.SetResourceBlob(r.Meta(), blob, hint, h.GlobalAccess(t.GetContext(), m), ocm.SkipVerify(), ocm.DisableExtraIdentityDefaulting())
 	})
+	if err != nil {
+		return err
+	}
+	return h.handleOCIAttachments(r, m, t)
 }
 
-func (h *Handler) HandleTransferSource(r ocm.SourceAccess, m cpi.AccessMethod, hint string, t ocm.ComponentVersionAccess) error {
+func (h *Handler) HandleTransferSource(r ocm.SourceAccess, m cpi.AccessMethod, hint string, t ocm.ComponentVersionAccess) (rerr error) {
+	tracker := progressattr.For(t.GetContext(), progress.OPERATION_TRANSFER, progress.KIND_SOURCE, common.VersionedElementKey(t).String(), r.Meta().GetName())
+	tracker.Started()
+	defer func() { tracker.Finished(rerr) }()
+
 	blob, err := accspeccpi.BlobAccessForAccessMethod(m)
 	if err != nil {
 		return err
 	}
 	defer blob.Close()
-	return accessio.Retry(h.opts.GetRetries(), time.Second, func() error {
+	blob = progress.AnnotatedBlobAccess(tracker, blob)
+	return retry(tracker, h.opts.GetRetries(), func() error {
 		return t.SetSourceBlob(r.Meta(), blob, hint, h.GlobalAccess(t.GetContext(), m), ocm.DisableExtraIdentityDefaulting())
 	})
 }
 
+// retry executes f with the configured number of retries.
+// Retries are reported to the given progress tracker.
+func retry(t *progress.Tracker, cnt int, f func() error) error {
+	var last error
+	attempt := 0
+	return accessio.Retry(cnt, time.Second, func() error {
+		attempt++
+		if attempt > 1 {
+			t.Retry(attempt, last)
+		}
+		last = f()
+		return last
+	})
+}
+
 func (h *Handler) GlobalAccess(ctx ocm.Context, m ocm.AccessMethod) ocm.AccessSpec {
 	if h.opts.IsKeepGlobalAccess() {
 		return m.AccessSpec().GlobalAccessSpec(ctx)
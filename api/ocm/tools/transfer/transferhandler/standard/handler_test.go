@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	. "github.com/mandelsoft/goutils/testutils"
 	. "github.com/onsi/ginkgo/v2"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/mandelsoft/goutils/finalizer"
 
+	"ocm.software/ocm/api/datacontext/attrs/progressattr"
 	"ocm.software/ocm/api/oci"
 	"ocm.software/ocm/api/oci/artdesc"
 	"ocm.software/ocm/api/oci/extensions/repositories/artifactset"
@@ -38,6 +40,7 @@ import (
 	"ocm.software/ocm/api/utils/blobaccess"
 	"ocm.software/ocm/api/utils/mime"
 	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/progress"
 	"ocm.software/ocm/api/utils/runtime"
 )
 
@@ -595,6 +598,91 @@ warning:   version "github.com/mandelsoft/test:v1" already present, but differs
 			_ = buf
 		})
 
+		It("reports transfer progress", func() {
+			var lock sync.Mutex
+			var events []progress.Event
+			unsubscribe := progressattr.Subscribe(env.OCMContext(), progress.HandlerFunc(func(e *progress.Event) {
+				lock.Lock()
+				defer lock.Unlock()
+				events = append(events, *e)
+			}))
+			defer unsubscribe()
+
+			src := Must(ctf.Open(env.OCMContext(), accessobj.ACC_READONLY, ARCH, 0, env))
+			defer Close(src, "source")
+			cv := Must(src.LookupComponentVersion(COMPONENT, VERSION))
+			defer Close(cv, "source cv")
+
+			tgt := Must(ctf.Create(env.OCMContext(), accessobj.ACC_WRITABLE|accessobj.ACC_CREATE, OUT+"_progress", 0o700, accessio.FormatDirectory, env))
+			defer Close(tgt, "target")
+
+			MustBeSuccessful(transfer.Transfer(cv, tgt, standard.ResourcesByValue()))
+
+			types := map[string][]progress.EventType{}
+			for _, e := range events {
+				Expect(e.Operation).To(Equal(progress.OPERATION_TRANSFER))
+				Expect(e.Error).To(BeEmpty())
+				key := e.Kind + " " + e.Element
+				if e.Type != progress.EVENT_BYTES || len(types[key]) == 0 || types[key][len(types[key])-1] != progress.EVENT_BYTES {
+					types[key] = append(types[key], e.Type)
+				}
+			}
+			Expect(types[progress.KIND_COMPONENTVERSION+" "+COMPONENT+":"+VERSION]).To(Equal([]progress.EventType{progress.EVENT_STARTED, progress.EVENT_FINISHED}))
+			Expect(types[progress.KIND_RESOURCE+" testdata"]).To(Equal([]progress.EventType{progress.EVENT_STARTED, progress.EVENT_BYTES, progress.EVENT_FINISHED}))
+		})
+
+		It("transfers shared references concurrently", func() {
+			const ARCH3 = "/tmp/ctf3"
+			env.OCMCommonTransport(ARCH3, accessio.FormatDirectory, func() {
+				for _, c := range []string{"a", "b", "c", "d"} {
+					env.Component("acme.org/"+c, func() {
+						env.Version(VERSION, func() {
+							env.Provider(PROVIDER)
+							switch c {
+							case "a":
+								env.Reference("ref-b", "acme.org/b", VERSION)
+								env.Reference("ref-c", "acme.org/c", VERSION)
+							case "b", "c":
+								env.Reference("ref-d", "acme.org/d", VERSION)
+							}
+							for i := 0; i < 3; i++ {
+								env.Resource(fmt.Sprintf("data%d", i), "", resourcetypes.PLAIN_TEXT, metav1.LocalRelation, func() {
+									env.BlobStringData(mime.MIME_TEXT, fmt.Sprintf("%s-%d", c, i))
+								})
+							}
+						})
+					})
+				}
+			})
+			Expect(maxworkersattr.Set(env.OCMContext(), 4)).To(Succeed())
+
+			src := Must(ctf.Open(env.OCMContext(), accessobj.ACC_READONLY, ARCH3, 0, env))
+			defer Close(src, "source")
+			cv := Must(src.LookupComponentVersion("acme.org/a", VERSION))
+			defer Close(cv, "source cv")
+
+			tgt := Must(ctf.Create(env.OCMContext(), accessobj.ACC_WRITABLE|accessobj.ACC_CREATE, OUT+"_refs", 0o700, accessio.FormatDirectory, env))
+			defer Close(tgt, "target")
+
+			p, buf := common.NewBufferedPrinter()
+			ctx := common.WithPrinter(context.Background(), p)
+			MustBeSuccessful(transfer.TransferWithContext(ctx, cv, tgt, standard.Recursive(), &optionsChecker{}))
+
+			Expect(buf.String()).To(ContainSubstring("[worker "))
+			Expect(buf.String()).To(ContainSubstring("...finished resource-2 "))
+			for _, c := range []string{"a", "b", "c", "d"} {
+				scv := Must(src.LookupComponentVersion("acme.org/"+c, VERSION))
+				tcv := Must(tgt.LookupComponentVersion("acme.org/"+c, VERSION))
+				Expect(len(tcv.GetDescriptor().Resources)).To(Equal(3))
+				for i, r := range tcv.GetDescriptor().Resources {
+					Expect(r.Name).To(Equal(scv.GetDescriptor().Resources[i].Name))
+					Expect(r.Digest).To(Equal(scv.GetDescriptor().Resources[i].Digest))
+				}
+				MustBeSuccessful(tcv.Close())
+				MustBeSuccessful(scv.Close())
+			}
+		})
+
 		It("honors cancellation during concurrent transfer", func() {
 			Expect(maxworkersattr.Set(env.OCMContext(), 4)).To(Succeed())
 
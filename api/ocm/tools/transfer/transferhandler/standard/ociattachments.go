@@ -0,0 +1,221 @@
+package standard
+
+import (
+	"path"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/finalizer"
+	"github.com/opencontainers/go-digest"
+
+	"ocm.software/ocm/api/oci"
+	"ocm.software/ocm/api/oci/tools/cosign"
+	"ocm.software/ocm/api/oci/tools/transfer"
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/cpi"
+	"ocm.software/ocm/api/ocm/cpi/accspeccpi"
+	"ocm.software/ocm/api/ocm/extensions/accessmethods/ociartifact"
+	"ocm.software/ocm/api/ocm/extensions/digester/digesters/artifact"
+	"ocm.software/ocm/api/ocm/tools/signing"
+	signingcpi "ocm.software/ocm/api/tech/signing"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+// Annotations used in the optional section of signatures
+// derived from component version signatures.
+const (
+	OCI_ANNOTATION_COMPONENT = "software.ocm.component"
+	OCI_ANNOTATION_VERSION   = "software.ocm.version"
+	OCI_ANNOTATION_RESOURCE  = "software.ocm.resource"
+	OCI_ANNOTATION_SIGNATURE = "software.ocm.signature"
+	OCI_ANNOTATION_DIGEST    = "software.ocm.digest"
+)
+
+// handleOCIAttachments transfers the cosign attachments and OCI referrers
+// of a resource transferred by value and/or signs the transferred OCI
+// artifact, if configured. Only resources finally stored as OCI artifact in an OCI
+// repository are handled.
+func (h *Handler) handleOCIAttachments(r ocm.ResourceAccess, m cpi.AccessMethod, t ocm.ComponentVersionAccess) (rerr error) {
+	name, sopts := h.opts.GetOCIArtifactSigning()
+	if !h.opts.IsTransferOCIAttachments() && name == "" {
+		return nil
+	}
+
+	var finalize finalizer.Finalizer
+	defer errors.PropagateError(&rerr, finalize.Finalize)
+
+	cd := t.GetDescriptor()
+	id := r.Meta().GetIdentity(cd.Resources)
+	res, err := cd.GetResourceByIdentity(id)
+	if err != nil {
+		return err
+	}
+	tgt, err := lookupOCIArtifact(t, res.Access, &finalize)
+	if err != nil || tgt == nil {
+		return err
+	}
+	art, ref, err := tgt.GetArtifact()
+	if err != nil {
+		return err
+	}
+	finalize.Close(art)
+	dig := art.Digest()
+
+	repo, _, err := tgt.GetRepository()
+	if err != nil {
+		return err
+	}
+	finalize.Close(repo)
+	ns, err := repo.LookupNamespace(ref.Repository)
+	if err != nil {
+		return err
+	}
+	finalize.Close(ns)
+
+	if h.opts.IsTransferOCIAttachments() {
+		if src, ok := accspeccpi.GetAccessMethodImplementation(m).(ociartifact.AccessMethodImpl); ok {
+			err = transferOCIAttachments(src, ns, dig)
+			if err != nil {
+				return errors.Wrapf(err, "resource %s: cannot transfer OCI attachments", id)
+			}
+		}
+	}
+
+	if name != "" {
+		err = signOCIArtifact(t, &res, ns, dockerReference(ref), dig, name, sopts...)
+		if err != nil {
+			return errors.Wrapf(err, "resource %s: cannot sign OCI artifact", id)
+		}
+	}
+	return nil
+}
+
+// lookupOCIArtifact provides the OCI artifact access method for an access
+// specification of a component version, if it describes an OCI artifact
+// (directly or by its global access).
+func lookupOCIArtifact(cv ocm.ComponentVersionAccess, spec compdesc.AccessSpec, finalize *finalizer.Finalizer) (ociartifact.AccessMethodImpl, error) {
+	ctx := cv.GetContext()
+	acc, err := ctx.AccessSpecForSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	for acc != nil {
+		m, err := acc.AccessMethod(cv)
+		if err != nil {
+			return nil, err
+		}
+		finalize.Close(m)
+		if impl, ok := accspeccpi.GetAccessMethodImplementation(m).(ociartifact.AccessMethodImpl); ok {
+			return impl, nil
+		}
+		g := acc.GlobalAccessSpec(ctx)
+		if g == acc {
+			break
+		}
+		acc = g
+	}
+	return nil, nil
+}
+
+func transferOCIAttachments(src ociartifact.AccessMethodImpl, tgt oci.NamespaceAccess, dig digest.Digest) error {
+	repo, ref, err := src.GetRepository()
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+	ns, err := repo.LookupNamespace(ref.Repository)
+	if err != nil {
+		return err
+	}
+	defer ns.Close()
+	_, err = cosign.TransferAttachments(ns, tgt, dig)
+	if err != nil {
+		return err
+	}
+	_, err = transfer.TransferReferrers(ns, dig, tgt)
+	return err
+}
+
+// signOCIArtifact derives a cosign signature for an OCI artifact from the
+// given component version signature. The component version signature and
+// the artifact digest described by the resource must be valid for the
+// transferred artifact, otherwise the derived signature would vouch for
+// content never covered by the component version signature.
+func signOCIArtifact(cv ocm.ComponentVersionAccess, res *compdesc.Resource, ns oci.NamespaceAccess, ref string, dig digest.Digest, name string, sopts ...signing.Option) error {
+	cd := cv.GetDescriptor()
+	i := cd.GetSignatureIndex(name)
+	if i < 0 {
+		return errors.ErrNotFound(compdesc.KIND_SIGNATURE, name, common.VersionedElementKey(cv).String())
+	}
+	sig := &cd.Signatures[i]
+
+	err := checkArtifactDigest(res.Digest, dig)
+	if err != nil {
+		return err
+	}
+
+	var opts signing.Options
+
+	opts.Eval(signing.SignatureName(name))
+	opts.Eval(sopts...)
+	if opts.Signer == nil && opts.SignAlgo == "" {
+		opts.SignAlgo = sig.Signature.Algorithm
+	}
+	opts.VerifySignature = false
+	err = opts.Complete(cv.GetContext())
+	if err != nil {
+		return err
+	}
+	err = signing.VerifyDescriptorSignature(cd, name, &opts)
+	if err != nil {
+		return err
+	}
+	hasher := opts.Registry.GetHasher(dig.Algorithm().String())
+	if hasher == nil {
+		return errors.ErrUnknown(compdesc.KIND_HASH_ALGORITHM, dig.Algorithm().String())
+	}
+	priv, err := opts.PrivateKey()
+	if err != nil {
+		return err
+	}
+	sctx := &signingcpi.DefaultSigningContext{
+		Hash:       hasher.Crypto(),
+		PrivateKey: priv,
+		PublicKey:  opts.PublicKey(name),
+		RootCerts:  opts.RootCerts,
+		Issuer:     opts.GetIssuer(),
+	}
+	optional := map[string]interface{}{
+		OCI_ANNOTATION_COMPONENT: cv.GetName(),
+		OCI_ANNOTATION_VERSION:   cv.GetVersion(),
+		OCI_ANNOTATION_RESOURCE:  res.GetIdentity(cd.Resources).String(),
+		OCI_ANNOTATION_SIGNATURE: name,
+		OCI_ANNOTATION_DIGEST:    sig.Digest.HashAlgorithm + ":" + sig.Digest.Value,
+	}
+	return cosign.Sign(cv.GetContext().CredentialsContext(), ns, ref, dig, optional, opts.Signer, sctx)
+}
+
+// checkArtifactDigest checks whether the resource digest describes the
+// given OCI artifact digest.
+func checkArtifactDigest(rd *metav1.DigestSpec, dig digest.Digest) error {
+	if rd == nil {
+		return errors.Newf("no resource digest found")
+	}
+	if rd.NormalisationAlgorithm != artifact.OciArtifactDigestV1 && rd.NormalisationAlgorithm != artifact.LegacyOciArtifactDigestV1 {
+		return errors.Newf("resource digest normalization %q does not describe an OCI artifact", rd.NormalisationAlgorithm)
+	}
+	if signingcpi.NormalizeHashAlgorithm(rd.HashAlgorithm) != signingcpi.NormalizeHashAlgorithm(dig.Algorithm().String()) || rd.Value != dig.Encoded() {
+		return errors.Newf("artifact digest %s does not match resource digest %s:%s", dig, rd.HashAlgorithm, rd.Value)
+	}
+	return nil
+}
+
+// dockerReference provides the repository reference used as identity
+// for cosign signatures.
+func dockerReference(ref *oci.RefSpec) string {
+	if ref.Host == "" {
+		return ref.Repository
+	}
+	return path.Join(ref.Host, ref.Repository)
+}
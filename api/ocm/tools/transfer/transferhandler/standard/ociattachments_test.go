@@ -0,0 +1,179 @@
+package standard_test
+
+import (
+	"crypto"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/helper/builder"
+	. "ocm.software/ocm/api/oci/testhelper"
+
+	"github.com/opencontainers/go-digest"
+
+	"ocm.software/ocm/api/oci"
+	"ocm.software/ocm/api/oci/artdesc"
+	ocictf "ocm.software/ocm/api/oci/extensions/repositories/ctf"
+	"ocm.software/ocm/api/oci/tools/cosign"
+	"ocm.software/ocm/api/ocm/cpi"
+	"ocm.software/ocm/api/ocm/extensions/attrs/preferrelativeattr"
+	storagecontext "ocm.software/ocm/api/ocm/extensions/blobhandler/handlers/oci"
+	"ocm.software/ocm/api/ocm/extensions/blobhandler/handlers/oci/ocirepo"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/ocm/extensions/repositories/genericocireg"
+	ocmsign "ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/ocm/tools/transfer"
+	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler"
+	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler/standard"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/tech/signing/signutils"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+)
+
+var _ = Describe("oci attachments", func() {
+	var env *Builder
+	var priv signutils.GenericPrivateKey
+	var pub signutils.GenericPublicKey
+
+	dig := digest.Digest("sha256:" + D_OCIMANIFEST1)
+	sigtag := cosign.Tag(dig, cosign.SignatureSuffix)
+
+	BeforeEach(func() {
+		env = NewBuilder()
+		priv, pub = Must2(rsa.CreateKeyPair())
+
+		FakeOCIRepo(env, OCIPATH, OCIHOST)
+		env.OCICommonTransport(OCIPATH, accessio.FormatDirectory, func() {
+			OCIManifest1(env)
+			env.Namespace(OCINAMESPACE, func() {
+				env.Manifest(sigtag, func() {
+					env.Config(func() {
+						env.BlobStringData(artdesc.MediaTypeImageConfig, "{}")
+					})
+					env.Layer(func() {
+						env.BlobStringData(cosign.SignatureMediaType, "original signature")
+					})
+				})
+			})
+		})
+
+		env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+			env.ComponentVersion(COMPONENT, VERSION, func() {
+				env.Provider(PROVIDER)
+				OCIArtifactResource1(env, "artifact", OCIHOST)
+			})
+		})
+
+		src := Must(ctf.Open(env, accessobj.ACC_WRITABLE, ARCH, 0, env))
+		defer Close(src, "source")
+		cv := Must(src.LookupComponentVersion(COMPONENT, VERSION))
+		defer Close(cv, "version")
+		Must(ocmsign.SignComponentVersion(cv, SIGNATURE, ocmsign.PrivateKey(SIGNATURE, priv)))
+
+		env.OCMContext().BlobHandlers().Register(ocirepo.NewArtifactHandler(func(ctx *storagecontext.StorageContext) string { return "" }),
+			cpi.ForRepo(oci.CONTEXT_TYPE, ocictf.Type), cpi.ForMimeType(artdesc.ToContentMediaType(artdesc.MediaTypeImageManifest)))
+		preferrelativeattr.Set(env.OCMContext(), true)
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	doTransfer := func(check func(ns oci.NamespaceAccess), opts ...transferhandler.TransferOption) {
+		src := Must(ctf.Open(env, accessobj.ACC_READONLY, ARCH, 0, env))
+		defer Close(src, "source")
+		cv := Must(src.LookupComponentVersion(COMPONENT, VERSION))
+		defer Close(cv, "version")
+		tgt := Must(ctf.Create(env, accessobj.ACC_WRITABLE|accessobj.ACC_CREATE, OUT, 0o700, accessio.FormatDirectory, env))
+		defer Close(tgt, "target")
+
+		handler := Must(standard.New(append(opts, standard.ResourcesByValue())...))
+		MustBeSuccessful(transfer.TransferVersion(nil, nil, cv, tgt, handler))
+
+		repo := genericocireg.GetOCIRepository(tgt)
+		ns := Must(repo.LookupNamespace(OCINAMESPACE))
+		defer Close(ns, "namespace")
+		check(ns)
+	}
+
+	verify := func(ns oci.NamespaceAccess) (*cosign.Payload, error) {
+		sctx := &signing.DefaultSigningContext{
+			Hash:      crypto.SHA256,
+			PublicKey: pub,
+		}
+		return cosign.Verify(ns, dig, rsa.NewHandler(), sctx)
+	}
+
+	It("omits attachments by default", func() {
+		doTransfer(func(ns oci.NamespaceAccess) {
+			Expect(ns.HasArtifact(OCIVERSION)).To(BeTrue())
+			Expect(ns.HasArtifact(sigtag)).To(BeFalse())
+		})
+	})
+
+	It("transfers attachments", func() {
+		doTransfer(func(ns oci.NamespaceAccess) {
+			art := Must(ns.GetArtifact(sigtag))
+			defer Close(art, "signature")
+			Expect(art.ManifestAccess().GetDescriptor().Layers).To(HaveLen(1))
+			ExpectError(verify(ns)).To(MatchError(ContainSubstring("no valid signature found")))
+		}, standard.OCIAttachments())
+	})
+
+	It("signs artifacts", func() {
+		doTransfer(func(ns oci.NamespaceAccess) {
+			art := Must(ns.GetArtifact(sigtag))
+			defer Close(art, "signature")
+			Expect(art.ManifestAccess().GetDescriptor().Layers).To(HaveLen(2))
+
+			p := Must(verify(ns))
+			Expect(p.Critical.Identity.DockerReference).To(Equal(OCINAMESPACE))
+			Expect(p.Critical.Image.DockerManifestDigest).To(Equal(dig.String()))
+			Expect(p.Optional).To(HaveKeyWithValue(standard.OCI_ANNOTATION_COMPONENT, COMPONENT))
+			Expect(p.Optional).To(HaveKeyWithValue(standard.OCI_ANNOTATION_SIGNATURE, SIGNATURE))
+			Expect(p.Optional).To(HaveKeyWithValue(standard.OCI_ANNOTATION_RESOURCE, `"name"="artifact"`))
+		}, standard.OCIAttachments(), standard.SignOCIArtifacts(SIGNATURE, ocmsign.PrivateKey(SIGNATURE, priv), ocmsign.PublicKey(SIGNATURE, pub)))
+	})
+
+	transferSigned := func(sopts ...ocmsign.Option) error {
+		src := Must(ctf.Open(env, accessobj.ACC_READONLY, ARCH, 0, env))
+		defer Close(src, "source")
+		cv := Must(src.LookupComponentVersion(COMPONENT, VERSION))
+		defer Close(cv, "version")
+		tgt := Must(ctf.Create(env, accessobj.ACC_WRITABLE|accessobj.ACC_CREATE, OUT, 0o700, accessio.FormatDirectory, env))
+		defer Close(tgt, "target")
+
+		handler := Must(standard.New(standard.ResourcesByValue(), standard.SignOCIArtifacts(SIGNATURE, append([]ocmsign.Option{ocmsign.PrivateKey(SIGNATURE, priv)}, sopts...)...)))
+		return transfer.TransferVersion(nil, nil, cv, tgt, handler)
+	}
+
+	It("rejects signing with unverifiable component version signature", func() {
+		_, other := Must2(rsa.CreateKeyPair())
+		Expect(transferSigned(ocmsign.PublicKey(SIGNATURE, other))).To(MatchError(ContainSubstring(`cannot sign OCI artifact: signature "` + SIGNATURE + `"`)))
+	})
+
+	It("rejects signing for artifacts not matching the resource digest", func() {
+		src := Must(ctf.Open(env, accessobj.ACC_WRITABLE, ARCH, 0, env))
+		cv := Must(src.LookupComponentVersion(COMPONENT, VERSION))
+		cv.GetDescriptor().Resources[0].Digest.Value = D_OCIMANIFEST2
+		MustBeSuccessful(cv.Update())
+		Close(cv, "version")
+		Close(src, "source")
+
+		Expect(transferSigned(ocmsign.PublicKey(SIGNATURE, pub))).To(MatchError(ContainSubstring(`cannot sign OCI artifact: artifact digest ` + dig.String() + ` does not match resource digest`)))
+	})
+
+	It("rejects signing without component version signature", func() {
+		src := Must(ctf.Open(env, accessobj.ACC_READONLY, ARCH, 0, env))
+		defer Close(src, "source")
+		cv := Must(src.LookupComponentVersion(COMPONENT, VERSION))
+		defer Close(cv, "version")
+		tgt := Must(ctf.Create(env, accessobj.ACC_WRITABLE|accessobj.ACC_CREATE, OUT, 0o700, accessio.FormatDirectory, env))
+		defer Close(tgt, "target")
+
+		handler := Must(standard.New(standard.ResourcesByValue(), standard.SignOCIArtifacts("other", ocmsign.PrivateKey("other", priv))))
+		ExpectError(transfer.TransferVersion(nil, nil, cv, tgt, handler)).To(MatchError(ContainSubstring(`cannot sign OCI artifact: signature "other" not found`)))
+	})
+})
@@ -10,6 +10,7 @@ import (
 	"github.com/mandelsoft/goutils/sliceutils"
 
 	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/tools/signing"
 	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler"
 	"ocm.software/ocm/api/utils/runtime"
 )
@@ -32,6 +33,11 @@ type Options struct {
 	omitAccessTypes   set.Set[string]
 	omitArtifactTypes set.Set[string]
 	resolver          ocm.ComponentVersionResolver
+	trustPolicy       *signing.TrustPolicy
+	signingOptions    []signing.Option
+	ociAttachments    *bool
+	ociSignature      string
+	ociSigningOptions []signing.Option
 }
 
 var (
@@ -49,6 +55,9 @@ var (
 	_ KeepGlobalAccessOption      = (*Options)(nil)
 	_ OmitAccessTypesOption       = (*Options)(nil)
 	_ OmitArtifactTypesOption     = (*Options)(nil)
+	_ TrustPolicyOption           = (*Options)(nil)
+	_ OCIAttachmentsOption        = (*Options)(nil)
+	_ OCIArtifactSigningOption    = (*Options)(nil)
 )
 
 type TransferOptionsCreator = transferhandler.SpecializedOptionsCreator[*Options, Options]
@@ -127,6 +136,21 @@ func (o *Options) ApplyTransferOption(target transferhandler.TransferOptions) er
 			opts.SetResolver(o.resolver)
 		}
 	}
+	if o.trustPolicy != nil {
+		if opts, ok := target.(TrustPolicyOption); ok {
+			opts.SetTrustPolicy(o.trustPolicy, o.signingOptions...)
+		}
+	}
+	if o.ociAttachments != nil {
+		if opts, ok := target.(OCIAttachmentsOption); ok {
+			opts.SetTransferOCIAttachments(*o.ociAttachments)
+		}
+	}
+	if o.ociSignature != "" {
+		if opts, ok := target.(OCIArtifactSigningOption); ok {
+			opts.SetOCIArtifactSigning(o.ociSignature, o.ociSigningOptions...)
+		}
+	}
 	return nil
 }
 
@@ -217,6 +241,32 @@ func (o *Options) GetResolver() ocm.ComponentVersionResolver {
 	return o.resolver
 }
 
+func (o *Options) SetTrustPolicy(policy *signing.TrustPolicy, opts ...signing.Option) {
+	o.trustPolicy = policy
+	o.signingOptions = opts
+}
+
+func (o *Options) GetTrustPolicy() (*signing.TrustPolicy, []signing.Option) {
+	return o.trustPolicy, o.signingOptions
+}
+
+func (o *Options) SetTransferOCIAttachments(transfer bool) {
+	o.ociAttachments = &transfer
+}
+
+func (o *Options) IsTransferOCIAttachments() bool {
+	return optionutils.AsBool(o.ociAttachments)
+}
+
+func (o *Options) SetOCIArtifactSigning(name string, opts ...signing.Option) {
+	o.ociSignature = name
+	o.ociSigningOptions = opts
+}
+
+func (o *Options) GetOCIArtifactSigning() (string, []signing.Option) {
+	return o.ociSignature, o.ociSigningOptions
+}
+
 func (o *Options) SetStopOnExistingVersion(stopOnExistingVersion bool) {
 	o.stopOnExisting = &stopOnExistingVersion
 }
@@ -661,3 +711,103 @@ func AddOmittedArtifactTypes(list ...string) transferhandler.TransferOption {
 		list: slices.Clone(list),
 	}
 }
+
+///////////////////////////////////////////////////////////////////////////////
+
+type TrustPolicyOption interface {
+	SetTrustPolicy(policy *signing.TrustPolicy, opts ...signing.Option)
+	GetTrustPolicy() (*signing.TrustPolicy, []signing.Option)
+}
+
+type trustPolicyOption struct {
+	TransferOptionsCreator
+	policy *signing.TrustPolicy
+	opts   []signing.Option
+}
+
+func (o *trustPolicyOption) ApplyTransferOption(to transferhandler.TransferOptions) error {
+	if eff, ok := to.(TrustPolicyOption); ok {
+		eff.SetTrustPolicy(o.policy, o.opts...)
+		return nil
+	} else {
+		return errors.ErrNotSupported(transferhandler.KIND_TRANSFEROPTION, "trust policy")
+	}
+}
+
+// TrustPolicy requires transferred component versions to fulfill
+// the given trust policy. The signing options are used to configure
+// the signature verification (for example public keys or root certificates).
+func TrustPolicy(policy *signing.TrustPolicy, opts ...signing.Option) transferhandler.TransferOption {
+	return &trustPolicyOption{
+		policy: policy,
+		opts:   opts,
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+type OCIAttachmentsOption interface {
+	SetTransferOCIAttachments(bool)
+	IsTransferOCIAttachments() bool
+}
+
+type ociAttachmentsOption struct {
+	TransferOptionsCreator
+	transfer bool
+}
+
+func (o *ociAttachmentsOption) ApplyTransferOption(to transferhandler.TransferOptions) error {
+	if eff, ok := to.(OCIAttachmentsOption); ok {
+		eff.SetTransferOCIAttachments(o.transfer)
+		return nil
+	} else {
+		return errors.ErrNotSupported(transferhandler.KIND_TRANSFEROPTION, "oci attachments")
+	}
+}
+
+// OCIAttachments enables the transfer of the signatures, attestations and
+// SBOMs stored along with OCI artifacts according to the cosign tag scheme
+// (sha256-<digest>.sig) or referring to them by the OCI 1.1 subject
+// field, when OCI artifact resources are transferred by value into an
+// OCI repository.
+func OCIAttachments(args ...bool) transferhandler.TransferOption {
+	return &ociAttachmentsOption{
+		transfer: optionutils.GetOptionFlag(args...),
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+type OCIArtifactSigningOption interface {
+	SetOCIArtifactSigning(name string, opts ...signing.Option)
+	GetOCIArtifactSigning() (string, []signing.Option)
+}
+
+type ociArtifactSigningOption struct {
+	TransferOptionsCreator
+	name string
+	opts []signing.Option
+}
+
+func (o *ociArtifactSigningOption) ApplyTransferOption(to transferhandler.TransferOptions) error {
+	if eff, ok := to.(OCIArtifactSigningOption); ok {
+		eff.SetOCIArtifactSigning(o.name, o.opts...)
+		return nil
+	} else {
+		return errors.ErrNotSupported(transferhandler.KIND_TRANSFEROPTION, "oci artifact signing")
+	}
+}
+
+// SignOCIArtifacts requests cosign compatible signatures for OCI artifact
+// resources transferred by value into an OCI repository. They are derived
+// from the component version signature with the given name, which must
+// exist: the payload signs the artifact digest and describes the component
+// version and its signed digest. The signing options must provide the
+// private key for the signature name. By default, the algorithm of the
+// component version signature is used.
+func SignOCIArtifacts(name string, opts ...signing.Option) transferhandler.TransferOption {
+	return &ociArtifactSigningOption{
+		name: name,
+		opts: opts,
+	}
+}
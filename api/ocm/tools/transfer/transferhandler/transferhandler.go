@@ -105,6 +105,12 @@ type TransferHandler interface {
 	HandleTransferSource(r ocm.SourceAccess, m cpi.AccessMethod, hint string, t ocm.ComponentVersionAccess) error
 }
 
+// VersionValidator is an optional interface for a TransferHandler
+// used to validate a source component version before it is transferred.
+type VersionValidator interface {
+	ValidateVersion(src ocm.ComponentVersionAccess) error
+}
+
 func ApplyOptions(set TransferOptions, opts ...TransferOption) error {
 	list := errors.ErrListf("transfer options")
 	for _, o := range opts {
@@ -60,6 +60,14 @@ func NewUrlRepository(repoUrl string, fss ...vfs.FileSystem) (*Repository, error
 	}}, nil
 }
 
+// WithHTTPClient provides a copy of the repository using the given
+// http client to access remote locations.
+func (r *Repository) WithHTTPClient(client *http.Client) *Repository {
+	n := *r
+	n.client = client
+	return &n
+}
+
 func (r *Repository) Url() (string, error) {
 	if r.url != "" {
 		return r.url, nil
@@ -152,14 +160,18 @@ func (r *Repository) Upload(coords *Coordinates, reader ioutils.DupReadCloser, c
 		}
 		return nil
 	}
-	reader, err := reader.Dup()
+	body, err := reader.Dup()
 	if rerr != nil {
 		return err
 	}
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, loc.String(), reader)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, loc.String(), body)
 	if err != nil {
 		return err
 	}
+	// enable retries by providing the content again
+	req.GetBody = func() (io.ReadCloser, error) {
+		return reader.Dup()
+	}
 	if creds != nil {
 		err = creds.SetForRequest(req)
 		if err != nil {
@@ -172,8 +184,7 @@ func (r *Repository) Upload(coords *Coordinates, reader ioutils.DupReadCloser, c
 	}
 
 	// Execute the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := loc.httpClient().Do(req)
 	if err != nil {
 		return err
 	}
@@ -316,15 +327,23 @@ func filesAndHashes(fileList []string) map[string]crypto.Hash {
 }
 
 type Location struct {
-	url  string
-	path string
-	fs   vfs.FileSystem
+	url    string
+	path   string
+	fs     vfs.FileSystem
+	client *http.Client
 }
 
 func (l *Location) MarshalJSON() ([]byte, error) {
 	return json.Marshal(l.String())
 }
 
+func (l *Location) httpClient() *http.Client {
+	if l.client != nil {
+		return l.client
+	}
+	return &http.Client{}
+}
+
 func (l *Location) IsFileSystem() bool {
 	return l.path != ""
 }
@@ -397,8 +416,7 @@ func (l *Location) GetReader(creds Credentials, tweakIndexOf ...func(loc *Locati
 	if tweakIndexOf != nil {
 		tweakIndexOf[0](nil, req) // tweak the request if necessary
 	}
-	httpClient := &http.Client{}
-	resp, err := httpClient.Do(req)
+	resp, err := l.httpClient().Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -27,7 +27,11 @@ type Client struct {
 	logger    logging.Logger
 }
 
-var _ Resolver = &Client{}
+var (
+	_ Resolver          = &Client{}
+	_ DeleterProvider   = &Client{}
+	_ ReferrersProvider = &Client{}
+)
 
 func New(opts ClientOptions) *Client {
 	return &Client{client: opts.Client, plainHTTP: opts.PlainHTTP, logger: opts.Logger}
@@ -45,6 +49,14 @@ func (c *Client) Lister(ctx context.Context, ref string) (Lister, error) {
 	return &OrasLister{client: c.client, ref: ref, plainHTTP: c.plainHTTP}, nil
 }
 
+func (c *Client) Deleter(ctx context.Context, ref string) (Deleter, error) {
+	return &OrasDeleter{client: c.client, ref: ref, plainHTTP: c.plainHTTP}, nil
+}
+
+func (c *Client) Referrers(ctx context.Context, ref string) (Referrers, error) {
+	return &OrasReferrers{client: c.client, ref: ref, plainHTTP: c.plainHTTP}, nil
+}
+
 func (c *Client) Resolve(ctx context.Context, ref string) (string, ociv1.Descriptor, error) {
 	src, err := createRepository(ref, c.client, c.plainHTTP)
 	if err != nil {
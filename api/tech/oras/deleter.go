@@ -0,0 +1,33 @@
+package oras
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/containerd/containerd/errdefs"
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	oraserr "oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+type OrasDeleter struct {
+	client    *auth.Client
+	ref       string
+	plainHTTP bool
+}
+
+func (c *OrasDeleter) Delete(ctx context.Context, desc ociv1.Descriptor) error {
+	src, err := createRepository(c.ref, c.client, c.plainHTTP)
+	if err != nil {
+		return fmt.Errorf("failed to resolve ref %q: %w", c.ref, err)
+	}
+
+	if err := src.Delete(ctx, desc); err != nil {
+		if errors.Is(err, oraserr.ErrNotFound) {
+			return errdefs.ErrNotFound
+		}
+		return fmt.Errorf("failed to delete manifest %q: %w", desc.Digest, err)
+	}
+	return nil
+}
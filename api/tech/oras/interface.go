@@ -55,6 +55,32 @@ type Pusher interface {
 	Push(ctx context.Context, d ocispec.Descriptor, src Source) error
 }
 
+// Deleter deletes content.
+type Deleter interface {
+	// Delete deletes the manifest identified by the descriptor.
+	Delete(ctx context.Context, desc ocispec.Descriptor) error
+}
+
+// DeleterProvider is an optional interface for resolvers
+// supporting the deletion of manifests.
+type DeleterProvider interface {
+	Deleter(ctx context.Context, ref string) (Deleter, error)
+}
+
+// Referrers lists referrers.
+type Referrers interface {
+	// List lists the descriptors of the manifests referring to the
+	// manifest described by the given descriptor (OCI 1.1 subject field).
+	// If an artifact type is given, only referrers of this type are listed.
+	List(ctx context.Context, desc ocispec.Descriptor, artifactType string) ([]ocispec.Descriptor, error)
+}
+
+// ReferrersProvider is an optional interface for resolvers
+// supporting the listing of referrers.
+type ReferrersProvider interface {
+	Referrers(ctx context.Context, ref string) (Referrers, error)
+}
+
 type Lister interface {
 	List(context.Context) ([]string, error)
 }
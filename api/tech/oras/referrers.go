@@ -0,0 +1,33 @@
+package oras
+
+import (
+	"context"
+	"fmt"
+
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+type OrasReferrers struct {
+	client    *auth.Client
+	ref       string
+	plainHTTP bool
+}
+
+// List uses the referrers API of the registry. If it is not supported,
+// the referrers tag schema is used as fallback.
+func (c *OrasReferrers) List(ctx context.Context, desc ociv1.Descriptor, artifactType string) ([]ociv1.Descriptor, error) {
+	src, err := createRepository(c.ref, c.client, c.plainHTTP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ref %q: %w", c.ref, err)
+	}
+
+	result := []ociv1.Descriptor{}
+	if err := src.Referrers(ctx, desc, artifactType, func(referrers []ociv1.Descriptor) error {
+		result = append(result, referrers...)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list referrers of %q: %w", desc.Digest, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,192 @@
+// Package dsse implements the Dead Simple Signing Envelope (DSSE)
+// (see https://github.com/secure-systems-lab/dsse) based on the
+// signature handlers of the signing package.
+package dsse
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mandelsoft/goutils/errors"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/ed25519"
+	"ocm.software/ocm/api/tech/signing/signutils"
+)
+
+const KIND_ENVELOPE = "DSSE envelope"
+
+// Envelope is a DSSE envelope.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a signature of a DSSE envelope.
+// Sig is the base64 encoded raw signature.
+type Signature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   string `json:"sig"`
+}
+
+// PAE provides the pre-authentication encoding of a payload, which
+// is the message finally signed.
+func PAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// NewEnvelope creates an unsigned envelope for a payload.
+func NewEnvelope(payloadType string, payload []byte) *Envelope {
+	return &Envelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+	}
+}
+
+// Parse parses a JSON encoded envelope.
+func Parse(data []byte) (*Envelope, error) {
+	var e Envelope
+	err := json.Unmarshal(data, &e)
+	if err != nil {
+		return nil, errors.ErrInvalidWrap(err, KIND_ENVELOPE)
+	}
+	if e.PayloadType == "" {
+		return nil, errors.ErrInvalid(KIND_ENVELOPE, "payload type missing")
+	}
+	if _, err := e.DecodePayload(); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// DecodePayload provides the decoded payload of the envelope.
+func (e *Envelope) DecodePayload() ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		return nil, errors.ErrInvalidWrap(err, KIND_ENVELOPE, "payload")
+	}
+	return data, nil
+}
+
+// digest provides the hex encoded hash of the pre-authentication
+// encoding used as digest for the signature handlers.
+// Ed25519 signs the message itself instead of a hash, therefore
+// the pre-authentication encoding is passed unhashed for this algorithm.
+func (e *Envelope) digest(algo string, sctx signing.SigningContext) (string, error) {
+	payload, err := e.DecodePayload()
+	if err != nil {
+		return "", err
+	}
+	if algo == ed25519.Algorithm {
+		return hex.EncodeToString(PAE(e.PayloadType, payload)), nil
+	}
+	if !sctx.GetHash().Available() {
+		return "", errors.Newf("hash function %s not available", sctx.GetHash())
+	}
+	h := sctx.GetHash().New()
+	h.Write(PAE(e.PayloadType, payload))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Sign adds a signature for the given key id to the envelope.
+// The pre-authentication encoding is hashed with the hash function of
+// the signing context and signed with the given signer. For RSA and
+// ECDSA this is the signature expected by other DSSE implementations.
+// For Ed25519 the pre-authentication encoding is signed directly.
+// The signer must provide a hex encoded or PEM signature.
+func (e *Envelope) Sign(cctx credentials.Context, keyid string, signer signing.Signer, sctx signing.SigningContext) error {
+	digest, err := e.digest(signer.Algorithm(), sctx)
+	if err != nil {
+		return err
+	}
+	sig, err := signer.Sign(cctx, digest, sctx)
+	if err != nil {
+		return err
+	}
+	raw, err := SignatureBytes(sig)
+	if err != nil {
+		return err
+	}
+	e.Signatures = append(e.Signatures, Signature{
+		KeyID: keyid,
+		Sig:   base64.StdEncoding.EncodeToString(raw),
+	})
+	return nil
+}
+
+// Verify verifies the signatures of the envelope for the given key id
+// with the given verifier. If no key id is given, all signatures
+// are considered. The envelope is accepted, if at least one of the
+// considered signatures can be verified. Other signatures, for example,
+// of other signers of a multi-signer envelope, are ignored.
+func (e *Envelope) Verify(keyid string, verifier signing.Verifier, sctx signing.SigningContext) error {
+	digest, err := e.digest(verifier.Algorithm(), sctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i, s := range e.Signatures {
+		if keyid != "" && s.KeyID != keyid {
+			continue
+		}
+		err := e.verify(i, digest, verifier, sctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	switch len(errs) {
+	case 0:
+		if keyid != "" {
+			return errors.ErrNotFound("signature", keyid, KIND_ENVELOPE)
+		}
+		return errors.Newf("%s not signed", KIND_ENVELOPE)
+	case 1:
+		return errs[0]
+	default:
+		list := errors.ErrListf("none of %d signatures verified", len(errs))
+		for _, err := range errs {
+			list.Add(err)
+		}
+		return list.Result()
+	}
+}
+
+func (e *Envelope) verify(i int, digest string, verifier signing.Verifier, sctx signing.SigningContext) error {
+	s := e.Signatures[i]
+	raw, err := base64.StdEncoding.DecodeString(s.Sig)
+	if err != nil {
+		return errors.ErrInvalidWrap(err, "signature", fmt.Sprintf("%d", i))
+	}
+	sig := &signing.Signature{
+		Value:     string(signutils.SignatureBytesToPem(verifier.Algorithm(), raw)),
+		MediaType: signutils.MediaTypePEM,
+		Algorithm: verifier.Algorithm(),
+	}
+	err = verifier.Verify(digest, sig, sctx)
+	if err != nil && s.KeyID != "" {
+		return errors.Wrapf(err, "signature %q", s.KeyID)
+	}
+	return err
+}
+
+// SignatureBytes provides the raw signature bytes of a signature
+// provided by a signature handler.
+func SignatureBytes(sig *signing.Signature) ([]byte, error) {
+	if sig.MediaType == signutils.MediaTypePEM {
+		raw, _, _, err := signutils.GetSignatureFromPem([]byte(sig.Value))
+		if err != nil {
+			return nil, err
+		}
+		return raw, nil
+	}
+	raw, err := hex.DecodeString(sig.Value)
+	if err != nil {
+		return nil, errors.Newf("signature media type %q not supported for DSSE", sig.MediaType)
+	}
+	return raw, nil
+}
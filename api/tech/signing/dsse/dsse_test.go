@@ -0,0 +1,129 @@
+package dsse_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	ssldsse "github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/dsse"
+	ecdsahdlr "ocm.software/ocm/api/tech/signing/handlers/ecdsa"
+	ed25519hdlr "ocm.software/ocm/api/tech/signing/handlers/ed25519"
+	rsahdlr "ocm.software/ocm/api/tech/signing/handlers/rsa"
+)
+
+const PAYLOAD_TYPE = "http://example.com/HelloWorld"
+
+// ed25519SignerVerifier is a plain DSSE signer and verifier
+// as used by other supply chain tools.
+type ed25519SignerVerifier struct {
+	keyid string
+	priv  ed25519.PrivateKey
+	pub   ed25519.PublicKey
+}
+
+func (s *ed25519SignerVerifier) Sign(_ context.Context, data []byte) ([]byte, error) {
+	return ed25519.Sign(s.priv, data), nil
+}
+
+func (s *ed25519SignerVerifier) Verify(_ context.Context, data, sig []byte) error {
+	if !ed25519.Verify(s.pub, data, sig) {
+		return fmt.Errorf("signature verification failed")
+	}
+	return nil
+}
+
+func (s *ed25519SignerVerifier) KeyID() (string, error) {
+	return s.keyid, nil
+}
+
+func (s *ed25519SignerVerifier) Public() crypto.PublicKey {
+	return s.pub
+}
+
+var _ = Describe("DSSE", func() {
+	It("provides the pre-authentication encoding", func() {
+		Expect(string(dsse.PAE(PAYLOAD_TYPE, []byte("hello world")))).To(Equal("DSSEv1 29 http://example.com/HelloWorld 11 hello world"))
+	})
+
+	It("signs and verifies with rsa", func() {
+		priv, pub := Must2(rsahdlr.Handler{}.CreateKeyPair())
+		env := dsse.NewEnvelope(PAYLOAD_TYPE, []byte("hello world"))
+		MustBeSuccessful(env.Sign(credentials.DefaultContext(), "test", rsahdlr.NewHandler(), &signing.DefaultSigningContext{Hash: crypto.SHA256, PrivateKey: priv}))
+
+		env = Must(dsse.Parse(Must(json.Marshal(env))))
+		MustBeSuccessful(env.Verify("test", rsahdlr.NewHandler(), &signing.DefaultSigningContext{Hash: crypto.SHA256, PublicKey: pub}))
+		Expect(env.Verify("other", rsahdlr.NewHandler(), &signing.DefaultSigningContext{Hash: crypto.SHA256, PublicKey: pub})).To(MatchError(`signature "other" not found in DSSE envelope`))
+
+		// compatible with plain DSSE verification
+		hash := sha256.Sum256(dsse.PAE(PAYLOAD_TYPE, []byte("hello world")))
+		sig := Must(base64.StdEncoding.DecodeString(env.Signatures[0].Sig))
+		MustBeSuccessful(rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA256, hash[:], sig))
+
+		other, _ := Must2(rsahdlr.Handler{}.CreateKeyPair())
+		ExpectError(env.Verify("test", rsahdlr.NewHandler(), &signing.DefaultSigningContext{Hash: crypto.SHA256, PublicKey: &other.(*rsa.PrivateKey).PublicKey})).To(HaveOccurred())
+	})
+
+	It("signs and verifies with ecdsa", func() {
+		priv, pub := Must2(ecdsahdlr.NewHandler().(signing.KeyPairCreator).CreateKeyPair())
+		env := dsse.NewEnvelope(PAYLOAD_TYPE, []byte("hello world"))
+		MustBeSuccessful(env.Sign(credentials.DefaultContext(), "", ecdsahdlr.NewHandler(), &signing.DefaultSigningContext{Hash: crypto.SHA256, PrivateKey: priv}))
+		MustBeSuccessful(env.Verify("", ecdsahdlr.NewHandler(), &signing.DefaultSigningContext{Hash: crypto.SHA256, PublicKey: pub}))
+
+		hash := sha256.Sum256(dsse.PAE(PAYLOAD_TYPE, []byte("hello world")))
+		sig := Must(base64.StdEncoding.DecodeString(env.Signatures[0].Sig))
+		Expect(ecdsa.VerifyASN1(pub.(*ecdsa.PublicKey), hash[:], sig)).To(BeTrue())
+	})
+
+	It("is compatible with other DSSE implementations for ed25519", func() {
+		pub, priv := Must2(ed25519.GenerateKey(nil))
+		sv := &ed25519SignerVerifier{keyid: "test", priv: priv, pub: pub}
+
+		env := dsse.NewEnvelope(PAYLOAD_TYPE, []byte("hello world"))
+		MustBeSuccessful(env.Sign(credentials.DefaultContext(), "test", ed25519hdlr.Handler{}, &signing.DefaultSigningContext{Hash: crypto.SHA256, PrivateKey: priv}))
+		MustBeSuccessful(env.Verify("test", ed25519hdlr.Handler{}, &signing.DefaultSigningContext{Hash: crypto.SHA256, PublicKey: pub}))
+
+		var sslenv ssldsse.Envelope
+		MustBeSuccessful(json.Unmarshal(Must(json.Marshal(env)), &sslenv))
+		verifier := Must(ssldsse.NewEnvelopeVerifier(sv))
+		Expect(Must(verifier.Verify(context.Background(), &sslenv))).To(HaveLen(1))
+
+		signer := Must(ssldsse.NewEnvelopeSigner(sv))
+		env = Must(dsse.Parse(Must(json.Marshal(Must(signer.SignPayload(context.Background(), PAYLOAD_TYPE, []byte("hello other world")))))))
+		MustBeSuccessful(env.Verify("test", ed25519hdlr.Handler{}, &signing.DefaultSigningContext{Hash: crypto.SHA256, PublicKey: pub}))
+	})
+
+	It("verifies envelopes of multiple signers", func() {
+		priv1, pub1 := Must2(rsahdlr.Handler{}.CreateKeyPair())
+		priv2, pub2 := Must2(rsahdlr.Handler{}.CreateKeyPair())
+		_, pub3 := Must2(rsahdlr.Handler{}.CreateKeyPair())
+		env := dsse.NewEnvelope(PAYLOAD_TYPE, []byte("hello world"))
+		MustBeSuccessful(env.Sign(credentials.DefaultContext(), "", rsahdlr.NewHandler(), &signing.DefaultSigningContext{Hash: crypto.SHA256, PrivateKey: priv1}))
+		MustBeSuccessful(env.Sign(credentials.DefaultContext(), "", rsahdlr.NewHandler(), &signing.DefaultSigningContext{Hash: crypto.SHA256, PrivateKey: priv2}))
+
+		MustBeSuccessful(env.Verify("", rsahdlr.NewHandler(), &signing.DefaultSigningContext{Hash: crypto.SHA256, PublicKey: pub1}))
+		MustBeSuccessful(env.Verify("", rsahdlr.NewHandler(), &signing.DefaultSigningContext{Hash: crypto.SHA256, PublicKey: pub2}))
+		Expect(env.Verify("", rsahdlr.NewHandler(), &signing.DefaultSigningContext{Hash: crypto.SHA256, PublicKey: pub3})).To(MatchError(ContainSubstring("none of 2 signatures verified")))
+	})
+
+	It("rejects modified payload", func() {
+		priv, pub := Must2(rsahdlr.Handler{}.CreateKeyPair())
+		env := dsse.NewEnvelope(PAYLOAD_TYPE, []byte("hello world"))
+		MustBeSuccessful(env.Sign(credentials.DefaultContext(), "test", rsahdlr.NewHandler(), &signing.DefaultSigningContext{Hash: crypto.SHA256, PrivateKey: priv}))
+		env.Payload = base64.StdEncoding.EncodeToString([]byte("hello other world"))
+		Expect(env.Verify("test", rsahdlr.NewHandler(), &signing.DefaultSigningContext{Hash: crypto.SHA256, PublicKey: pub})).To(MatchError(ContainSubstring(`signature "test": signature verification failed`)))
+	})
+})
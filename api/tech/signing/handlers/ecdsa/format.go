@@ -0,0 +1,61 @@
+package ecdsa
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+
+	"ocm.software/ocm/api/tech/signing/signutils"
+)
+
+func GetPublicKey(key interface{}) (*ecdsa.PublicKey, *pkix.Name, error) {
+	var err error
+	if data, ok := key.([]byte); ok {
+		key, err = ParseKey(data)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return k, nil, nil
+	case *ecdsa.PrivateKey:
+		return &k.PublicKey, nil, nil
+	case *x509.Certificate:
+		if p, ok := k.PublicKey.(*ecdsa.PublicKey); ok {
+			return p, &k.Subject, nil
+		}
+		return nil, nil, fmt.Errorf("unknown key public key %T in certificate", k.PublicKey)
+	default:
+		return nil, nil, fmt.Errorf("unknown key specification %T", k)
+	}
+}
+
+func GetPrivateKey(key interface{}) (*ecdsa.PrivateKey, error) {
+	k, err := signutils.GetPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := k.(*ecdsa.PrivateKey); ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("unknown key specification %T", k)
+}
+
+// ParseKey parses a PEM encoded ECDSA key or a certificate
+// providing an ECDSA public key.
+func ParseKey(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid key format (expected pem block)")
+	}
+	switch block.Type {
+	case "EC PRIVATE KEY", "PRIVATE KEY":
+		return signutils.ParsePrivateKey(data)
+	case signutils.CertificatePEMBlockType:
+		return x509.ParseCertificate(block.Bytes)
+	}
+	return signutils.ParsePublicKey(data)
+}
@@ -0,0 +1,201 @@
+package ecdsa
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/mandelsoft/goutils/errors"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/signutils"
+)
+
+const (
+	// AlgorithmP256 defines the type for the ECDSA signature algorithm
+	// using the NIST P-256 curve.
+	AlgorithmP256 = "ECDSA-P256"
+	// AlgorithmP384 defines the type for the ECDSA signature algorithm
+	// using the NIST P-384 curve.
+	AlgorithmP384 = "ECDSA-P384"
+)
+
+// MediaType defines the media type for a plain ASN.1 encoded ECDSA signature.
+const MediaType = "application/vnd.ocm.signature.ecdsa"
+
+// MediaTypePEM is used if the signature contains the public key certificate chain.
+const MediaTypePEM = signutils.MediaTypePEM
+
+func init() {
+	signing.DefaultHandlerRegistry().RegisterSigner(AlgorithmP256, NewHandler())
+	signing.DefaultHandlerRegistry().RegisterSigner(AlgorithmP384, NewHandlerFor(P384))
+}
+
+type (
+	PrivateKey = ecdsa.PrivateKey
+	PublicKey  = ecdsa.PublicKey
+)
+
+// Method describes the curve used for an ECDSA signature algorithm.
+type Method struct {
+	Algorithm string
+	Curve     elliptic.Curve
+}
+
+var (
+	P256 = &Method{Algorithm: AlgorithmP256, Curve: elliptic.P256()}
+	P384 = &Method{Algorithm: AlgorithmP384, Curve: elliptic.P384()}
+)
+
+// Handler is a signatures.Signer compatible struct to sign with ECDSA
+// and a signatures.Verifier compatible struct to verify ECDSA signatures.
+type Handler struct {
+	method *Method
+}
+
+func NewHandler() signing.SignatureHandler {
+	return NewHandlerFor(P256)
+}
+
+func NewHandlerFor(m *Method) signing.SignatureHandler {
+	return &Handler{method: m}
+}
+
+func (h *Handler) Algorithm() string {
+	return h.method.Algorithm
+}
+
+func (h *Handler) checkCurve(c elliptic.Curve) error {
+	if c != h.method.Curve {
+		return fmt.Errorf("key curve %s does not match %s", c.Params().Name, h.method.Algorithm)
+	}
+	return nil
+}
+
+func (h *Handler) Sign(cctx credentials.Context, digest string, sctx signing.SigningContext) (signature *signing.Signature, err error) {
+	privateKey, err := GetPrivateKey(sctx.GetPrivateKey())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid ecdsa private key")
+	}
+	if err := h.checkCurve(privateKey.Curve); err != nil {
+		return nil, errors.Wrapf(err, "invalid ecdsa private key")
+	}
+	decodedHash, err := hex.DecodeString(digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding hash to bytes")
+	}
+	sig, err := ecdsa.SignASN1(rand.Reader, privateKey, decodedHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed signing hash, %w", err)
+	}
+
+	media := MediaType
+	value := hex.EncodeToString(sig)
+
+	var iss string
+	pub := sctx.GetPublicKey()
+	if pub != nil {
+		var pubKey *PublicKey
+		certs, err := signutils.GetCertificateChain(pub, false)
+		if err == nil && len(certs) > 0 {
+			pubKey, _, err = GetPublicKey(certs[0])
+			if err != nil {
+				return nil, errors.ErrInvalidWrap(err, "public key")
+			}
+			err = signutils.VerifyCertificate(certs[0], certs[1:], sctx.GetRootCerts(), sctx.GetIssuer())
+			if err != nil {
+				return nil, errors.Wrapf(err, "public key certificate")
+			}
+			media = MediaTypePEM
+			value = string(signutils.SignatureBytesToPem(h.Algorithm(), sig, certs...))
+			iss = certs[0].Subject.String()
+		} else {
+			pubKey, _, err = GetPublicKey(pub)
+			if err != nil {
+				return nil, errors.ErrInvalidWrap(err, "public key")
+			}
+		}
+		if !privateKey.PublicKey.Equal(pubKey) {
+			return nil, fmt.Errorf("invalid public key for private key")
+		}
+	}
+
+	return &signing.Signature{
+		Value:     value,
+		MediaType: media,
+		Algorithm: h.Algorithm(),
+		Issuer:    iss,
+	}, nil
+}
+
+// Verify checks the signature, returns an error on verification failure.
+func (h *Handler) Verify(digest string, signature *signing.Signature, sctx signing.SigningContext) (err error) {
+	var signatureBytes []byte
+
+	publicKey, name, err := GetPublicKey(sctx.GetPublicKey())
+	if err != nil {
+		return fmt.Errorf("failed to get public key: %w", err)
+	}
+	if err := h.checkCurve(publicKey.Curve); err != nil {
+		return err
+	}
+
+	switch signature.MediaType {
+	case MediaType:
+		signatureBytes, err = hex.DecodeString(signature.Value)
+		if err != nil {
+			return fmt.Errorf("unable to get signature value: failed decoding hash %s: %w", digest, err)
+		}
+	case signutils.MediaTypePEM:
+		sig, algo, _, err := signutils.GetSignatureFromPem([]byte(signature.Value))
+		if err != nil {
+			return fmt.Errorf("unable to get signature from pem: %w", err)
+		}
+		if algo != "" && algo != h.Algorithm() {
+			return errors.ErrInvalid(signutils.KIND_SIGN_ALGORITHM, algo)
+		}
+		signatureBytes = sig
+	default:
+		return fmt.Errorf("invalid signature mediaType %s", signature.MediaType)
+	}
+
+	decodedHash, err := hex.DecodeString(digest)
+	if err != nil {
+		return fmt.Errorf("failed decoding hash %s: %w", digest, err)
+	}
+
+	if name != nil && signature.Issuer != "" {
+		iss, err := signutils.ParseDN(signature.Issuer)
+		if err != nil {
+			return errors.Wrapf(err, "signature issuer")
+		}
+		if signutils.MatchDN(*iss, *name) != nil {
+			return fmt.Errorf("issuer %s does not match %s", signature.Issuer, name)
+		}
+	}
+	if !ecdsa.VerifyASN1(publicKey, decodedHash, signatureBytes) {
+		return fmt.Errorf("signature verification failed")
+	}
+	return nil
+}
+
+func (h *Handler) CreateKeyPair() (priv signutils.GenericPrivateKey, pub signutils.GenericPublicKey, err error) {
+	return CreateKeyPair(h.method.Curve)
+}
+
+// CreateKeyPair creates an ECDSA key pair for the given curve.
+// By default, P-256 is used.
+func CreateKeyPair(curve ...elliptic.Curve) (priv signutils.GenericPrivateKey, pub signutils.GenericPublicKey, err error) {
+	c := elliptic.P256()
+	if len(curve) > 0 && curve[0] != nil {
+		c = curve[0]
+	}
+	key, err := ecdsa.GenerateKey(c, rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, &key.PublicKey, nil
+}
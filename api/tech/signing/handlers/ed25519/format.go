@@ -0,0 +1,61 @@
+package ed25519
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+
+	"ocm.software/ocm/api/tech/signing/signutils"
+)
+
+func GetPublicKey(key interface{}) (ed25519.PublicKey, *pkix.Name, error) {
+	var err error
+	if data, ok := key.([]byte); ok {
+		key, err = ParseKey(data)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return k, nil, nil
+	case ed25519.PrivateKey:
+		return k.Public().(ed25519.PublicKey), nil, nil
+	case *x509.Certificate:
+		if p, ok := k.PublicKey.(ed25519.PublicKey); ok {
+			return p, &k.Subject, nil
+		}
+		return nil, nil, fmt.Errorf("unknown key public key %T in certificate", k.PublicKey)
+	default:
+		return nil, nil, fmt.Errorf("unknown key specification %T", k)
+	}
+}
+
+func GetPrivateKey(key interface{}) (ed25519.PrivateKey, error) {
+	k, err := signutils.GetPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := k.(ed25519.PrivateKey); ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("unknown key specification %T", k)
+}
+
+// ParseKey parses a PEM encoded Ed25519 key or a certificate
+// providing an Ed25519 public key.
+func ParseKey(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid key format (expected pem block)")
+	}
+	switch block.Type {
+	case "PRIVATE KEY":
+		return signutils.ParsePrivateKey(data)
+	case signutils.CertificatePEMBlockType:
+		return x509.ParseCertificate(block.Bytes)
+	}
+	return signutils.ParsePublicKey(data)
+}
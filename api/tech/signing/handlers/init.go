@@ -2,6 +2,9 @@ package handlers
 
 import (
 	_ "github.com/sigstore/cosign/v3/pkg/providers/all"
+	_ "ocm.software/ocm/api/tech/signing/handlers/ecdsa"
+	_ "ocm.software/ocm/api/tech/signing/handlers/ed25519"
+	_ "ocm.software/ocm/api/tech/signing/handlers/pkcs11"
 	_ "ocm.software/ocm/api/tech/signing/handlers/rsa"
 	_ "ocm.software/ocm/api/tech/signing/handlers/rsa-pss"
 	_ "ocm.software/ocm/api/tech/signing/handlers/rsa-pss-signingservice"
@@ -0,0 +1,85 @@
+package pkcs11
+
+import (
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/osfs"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/datacontext/attrs/vfsattr"
+	"ocm.software/ocm/api/utils/listformat"
+)
+
+const (
+	CONSUMER_TYPE = "PKCS11"
+
+	ID_TOKEN  = "token"
+	ID_SERIAL = "serial"
+
+	ATTR_PIN = "pin"
+)
+
+func init() {
+	attrs := listformat.FormatListElements("", listformat.StringElementDescriptionList{
+		ATTR_PIN, "user PIN for the token",
+	})
+	ids := listformat.FormatListElements("", listformat.StringElementDescriptionList{
+		ID_TOKEN, "token label",
+		ID_SERIAL, "(optional) token serial number",
+	})
+	cpi.RegisterStandardIdentity(CONSUMER_TYPE, cpi.PartialMatch,
+		`PKCS#11 token credential matcher
+
+This matcher matches credentials for a PKCS#11 token used to sign
+with keys held by an HSM. It uses the following identity attributes:
+`+ids,
+		attrs)
+}
+
+// GetConsumerId provides the consumer id used to look up the PIN for the
+// token described by the given URI.
+func GetConsumerId(u *URI) cpi.ConsumerIdentity {
+	id := cpi.ConsumerIdentity{
+		cpi.ID_TYPE: CONSUMER_TYPE,
+	}
+	if u.Token() != "" {
+		id[ID_TOKEN] = u.Token()
+	}
+	if u.Serial() != "" {
+		id[ID_SERIAL] = u.Serial()
+	}
+	return id
+}
+
+// GetPIN determines the user PIN for the token described by the given URI.
+// It is taken from the pin-value or pin-source query attribute, or from
+// the credentials found in the credentials context for the consumer id
+// provided by GetConsumerId. If no PIN is found, an empty PIN is returned.
+func GetPIN(cctx credentials.Context, u *URI) (string, error) {
+	if u.PinValue() != "" {
+		return u.PinValue(), nil
+	}
+	if src := u.PinSource(); src != "" {
+		path := strings.TrimPrefix(src, "file:")
+		var fs vfs.FileSystem = osfs.OsFs
+		if cctx != nil {
+			fs = vfsattr.Get(cctx)
+		}
+		data, err := vfs.ReadFile(fs, path)
+		if err != nil {
+			return "", errors.Wrapf(err, "pin source %q", src)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if cctx == nil {
+		return "", nil
+	}
+	creds, err := credentials.CredentialsForConsumer(cctx, GetConsumerId(u))
+	if err != nil || creds == nil {
+		return "", err
+	}
+	return creds.GetProperty(ATTR_PIN), nil
+}
@@ -0,0 +1,133 @@
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/mandelsoft/goutils/errors"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/tech/signing"
+	ecdsahandler "ocm.software/ocm/api/tech/signing/handlers/ecdsa"
+	rsahandler "ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/tech/signing/signutils"
+)
+
+// Name is the name of the signer using keys held by a PKCS#11 token.
+// The resulting signature uses the algorithm matching the key type
+// (RSASSA-PKCS1-V1_5, ECDSA-P256 or ECDSA-P384), so that it can be
+// verified with the standard handlers for those algorithms.
+const Name = "pkcs11"
+
+func init() {
+	signing.DefaultHandlerRegistry().RegisterSigner(Name, NewHandler())
+}
+
+// Key is a private key held by a PKCS#11 token.
+type Key interface {
+	crypto.Signer
+	io.Closer
+}
+
+// Handler is a signatures.Signer compatible struct to sign with
+// a private key held by a PKCS#11 token.
+// Instead of a private key, a PKCS#11 URI (RFC 7512) describing the
+// key is used.
+type Handler struct{}
+
+func NewHandler() signing.Signer {
+	return &Handler{}
+}
+
+func (h *Handler) Algorithm() string {
+	return Name
+}
+
+func (h *Handler) Sign(cctx credentials.Context, digest string, sctx signing.SigningContext) (signature *signing.Signature, err error) {
+	uri, err := GetURI(sctx.GetPrivateKey())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid pkcs11 key specification")
+	}
+	pin, err := GetPIN(cctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	decodedHash, err := hex.DecodeString(digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding hash to bytes")
+	}
+
+	key, err := OpenKey(uri, pin)
+	if err != nil {
+		return nil, errors.Wrapf(err, "pkcs11 key %s", uri)
+	}
+	defer key.Close()
+
+	algo, media, err := method(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	sig, err := key.Sign(rand.Reader, decodedHash, sctx.GetHash())
+	if err != nil {
+		return nil, fmt.Errorf("failed signing hash, %w", err)
+	}
+
+	value := hex.EncodeToString(sig)
+
+	var iss string
+	pub := sctx.GetPublicKey()
+	if pub != nil {
+		var pubKey interface{}
+		certs, err := signutils.GetCertificateChain(pub, false)
+		if err == nil && len(certs) > 0 {
+			pubKey = certs[0].PublicKey
+			err = signutils.VerifyCertificate(certs[0], certs[1:], sctx.GetRootCerts(), sctx.GetIssuer())
+			if err != nil {
+				return nil, errors.Wrapf(err, "public key certificate")
+			}
+			media = signutils.MediaTypePEM
+			value = string(signutils.SignatureBytesToPem(algo, sig, certs...))
+			iss = certs[0].Subject.String()
+		} else {
+			pubKey, err = signutils.GetPublicKey(pub)
+			if err != nil {
+				return nil, errors.ErrInvalidWrap(err, "public key")
+			}
+		}
+		if k, ok := pubKey.(interface{ Equal(crypto.PublicKey) bool }); !ok || !k.Equal(key.Public()) {
+			return nil, fmt.Errorf("invalid public key for private key")
+		}
+	}
+
+	return &signing.Signature{
+		Value:     value,
+		MediaType: media,
+		Algorithm: algo,
+		Issuer:    iss,
+	}, nil
+}
+
+// method determines the signature algorithm and media type
+// for the given public key of a token key.
+func method(pub crypto.PublicKey) (string, string, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return rsahandler.Algorithm, rsahandler.MediaType, nil
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return ecdsahandler.AlgorithmP256, ecdsahandler.MediaType, nil
+		case elliptic.P384():
+			return ecdsahandler.AlgorithmP384, ecdsahandler.MediaType, nil
+		}
+		return "", "", errors.ErrNotSupported("ecdsa curve", k.Curve.Params().Name)
+	default:
+		return "", "", errors.ErrNotSupported("pkcs11 key type", fmt.Sprintf("%T", pub))
+	}
+}
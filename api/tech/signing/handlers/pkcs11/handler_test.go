@@ -0,0 +1,89 @@
+package pkcs11_test
+
+import (
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/vfs/pkg/memoryfs"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/datacontext/attrs/vfsattr"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/pkcs11"
+)
+
+var _ = Describe("PKCS#11 signer", func() {
+	Context("uri", func() {
+		It("parses uri", func() {
+			u := Must(pkcs11.ParseURI("pkcs11:token=my%20token;serial=4711;object=key;id=%01%02?module-path=/usr/lib/softhsm/libsofthsm2.so&pin-value=1234"))
+			Expect(u.Token()).To(Equal("my token"))
+			Expect(u.Serial()).To(Equal("4711"))
+			Expect(u.Object()).To(Equal("key"))
+			Expect(u.Id()).To(Equal([]byte{1, 2}))
+			Expect(u.ModulePath()).To(Equal("/usr/lib/softhsm/libsofthsm2.so"))
+			Expect(u.PinValue()).To(Equal("1234"))
+			_, ok := u.SlotId()
+			Expect(ok).To(BeFalse())
+
+			Expect(u.String()).To(Equal("pkcs11:id=%01%02;object=key;serial=4711;token=my%20token?module-path=/usr/lib/softhsm/libsofthsm2.so"))
+		})
+
+		It("parses slot id", func() {
+			u := Must(pkcs11.ParseURI("pkcs11:slot-id=3;object=key"))
+			id, ok := u.SlotId()
+			Expect(ok).To(BeTrue())
+			Expect(id).To(Equal(uint(3)))
+		})
+
+		It("rejects invalid uris", func() {
+			ExpectError(pkcs11.ParseURI("pkcs12:object=key")).To(MatchError(`PKCS#11 URI "pkcs12:object=key" is invalid`))
+			ExpectError(pkcs11.ParseURI("pkcs11:token=test")).To(MatchError(`PKCS#11 URI "pkcs11:token=test" is invalid: object or id required`))
+			ExpectError(pkcs11.ParseURI("pkcs11:object=key;type=public")).To(MatchError(`PKCS#11 URI "pkcs11:object=key;type=public" is invalid: object type must be private`))
+			ExpectError(pkcs11.ParseURI("pkcs11:object=key;object=other")).To(MatchError(`PKCS#11 URI "pkcs11:object=key;object=other" is invalid: duplicate attribute "object"`))
+			ExpectError(pkcs11.ParseURI("pkcs11:object=key;slot-id=x")).To(HaveOccurred())
+		})
+	})
+
+	Context("pin", func() {
+		var ctx credentials.Context
+
+		BeforeEach(func() {
+			ctx = credentials.New()
+		})
+
+		It("uses pin value", func() {
+			u := Must(pkcs11.ParseURI("pkcs11:token=test;object=key?pin-value=1234"))
+			Expect(pkcs11.GetPIN(ctx, u)).To(Equal("1234"))
+		})
+
+		It("uses pin source", func() {
+			fs := memoryfs.New()
+			vfsattr.Set(ctx, fs)
+			MustBeSuccessful(vfs.WriteFile(fs, "/pin", []byte("5678\n"), 0o600))
+			u := Must(pkcs11.ParseURI("pkcs11:token=test;object=key?pin-source=file:/pin"))
+			Expect(pkcs11.GetPIN(ctx, u)).To(Equal("5678"))
+		})
+
+		It("uses credentials context", func() {
+			ctx.SetCredentialsForConsumer(credentials.NewConsumerIdentity(pkcs11.CONSUMER_TYPE, pkcs11.ID_TOKEN, "test"),
+				credentials.DirectCredentials{pkcs11.ATTR_PIN: "1234"})
+			ctx.SetCredentialsForConsumer(credentials.NewConsumerIdentity(pkcs11.CONSUMER_TYPE, pkcs11.ID_TOKEN, "test", pkcs11.ID_SERIAL, "4711"),
+				credentials.DirectCredentials{pkcs11.ATTR_PIN: "5678"})
+
+			Expect(pkcs11.GetPIN(ctx, Must(pkcs11.ParseURI("pkcs11:token=test;object=key")))).To(Equal("1234"))
+			Expect(pkcs11.GetPIN(ctx, Must(pkcs11.ParseURI("pkcs11:token=test;serial=4711;object=key")))).To(Equal("5678"))
+			Expect(pkcs11.GetPIN(ctx, Must(pkcs11.ParseURI("pkcs11:token=other;object=key")))).To(Equal(""))
+		})
+	})
+
+	It("is registered", func() {
+		Expect(signing.DefaultHandlerRegistry().GetSigner(pkcs11.Name)).NotTo(BeNil())
+	})
+
+	It("rejects invalid key specification", func() {
+		sctx := &signing.DefaultSigningContext{PrivateKey: []byte("no uri")}
+		ExpectError(pkcs11.NewHandler().Sign(credentials.New(), "0000", sctx)).To(MatchError(`invalid pkcs11 key specification: PKCS#11 URI "no uri" is invalid`))
+	})
+})
@@ -0,0 +1,313 @@
+//go:build cgo
+
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/miekg/pkcs11"
+)
+
+var (
+	lock    sync.Mutex
+	modules = map[string]*pkcs11.Ctx{}
+)
+
+// module provides an initialized module for the given library path.
+// A module can only be initialized once per process, therefore
+// loaded modules are kept for the lifetime of the process.
+func module(path string) (*pkcs11.Ctx, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if m := modules[path]; m != nil {
+		return m, nil
+	}
+	m := pkcs11.New(path)
+	if m == nil {
+		return nil, errors.ErrNotFound("pkcs11 module", path)
+	}
+	err := m.Initialize()
+	if err != nil && !isError(err, pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+		m.Destroy()
+		return nil, errors.Wrapf(err, "cannot initialize pkcs11 module %q", path)
+	}
+	modules[path] = m
+	return m, nil
+}
+
+func isError(err error, code uint) bool {
+	var e pkcs11.Error
+	return errors.As(err, &e) && uint(e) == code
+}
+
+type key struct {
+	lock    sync.Mutex
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	handle  pkcs11.ObjectHandle
+	public  crypto.PublicKey
+}
+
+var _ Key = (*key)(nil)
+
+// OpenKey opens a session to the token described by the URI and
+// provides access to the described private key. The session is
+// closed by closing the key.
+func OpenKey(uri *URI, pin string) (Key, error) {
+	if uri.ModulePath() == "" {
+		return nil, errors.ErrInvalidWrap(errors.New("module-path required"), KIND_PKCS11_URI, uri.String())
+	}
+	ctx, err := module(uri.ModulePath())
+	if err != nil {
+		return nil, err
+	}
+	slot, err := findSlot(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open session")
+	}
+	k := &key{ctx: ctx, session: session}
+	err = k.init(uri, pin)
+	if err != nil {
+		ctx.CloseSession(session)
+		return nil, err
+	}
+	return k, nil
+}
+
+func findSlot(ctx *pkcs11.Ctx, uri *URI) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot list slots")
+	}
+	id, useId := uri.SlotId()
+	for _, s := range slots {
+		if useId && s != id {
+			continue
+		}
+		info, err := ctx.GetTokenInfo(s)
+		if err != nil {
+			return 0, errors.Wrapf(err, "cannot get token info for slot %d", s)
+		}
+		if match(uri.Token(), info.Label) && match(uri.Serial(), info.SerialNumber) &&
+			match(uri.Manufacturer(), info.ManufacturerID) && match(uri.Model(), info.Model) {
+			return s, nil
+		}
+	}
+	return 0, errors.ErrNotFound("pkcs11 token", uri.Token())
+}
+
+func match(expected, actual string) bool {
+	return expected == "" || expected == strings.TrimRight(actual, " \x00")
+}
+
+func (k *key) init(uri *URI, pin string) error {
+	if pin != "" {
+		err := k.ctx.Login(k.session, pkcs11.CKU_USER, pin)
+		if err != nil && !isError(err, pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+			return errors.Wrapf(err, "cannot login to token")
+		}
+	}
+
+	var template []*pkcs11.Attribute
+	if uri.Object() != "" {
+		template = append(template, pkcs11.NewAttribute(pkcs11.CKA_LABEL, uri.Object()))
+	}
+	if uri.Id() != nil {
+		template = append(template, pkcs11.NewAttribute(pkcs11.CKA_ID, uri.Id()))
+	}
+
+	objs, err := k.find(append(template, pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY)))
+	if err != nil {
+		return err
+	}
+	switch len(objs) {
+	case 0:
+		return errors.ErrNotFound("pkcs11 private key", uri.String())
+	case 1:
+		k.handle = objs[0]
+	default:
+		return fmt.Errorf("pkcs11 private key %s is ambiguous", uri)
+	}
+
+	attrs, err := k.ctx.GetAttributeValue(k.session, k.handle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, nil),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "cannot get key type")
+	}
+	switch t := bytesToUint(attrs[0].Value); t {
+	case pkcs11.CKK_RSA:
+		k.public, err = k.rsaPublicKey()
+	case pkcs11.CKK_EC:
+		k.public, err = k.ecdsaPublicKey(template)
+	default:
+		return errors.ErrNotSupported("pkcs11 key type", fmt.Sprintf("%d", t))
+	}
+	return err
+}
+
+func (k *key) find(template []*pkcs11.Attribute) ([]pkcs11.ObjectHandle, error) {
+	err := k.ctx.FindObjectsInit(k.session, template)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot search key")
+	}
+	objs, _, err := k.ctx.FindObjects(k.session, 2)
+	if err != nil {
+		k.ctx.FindObjectsFinal(k.session)
+		return nil, errors.Wrapf(err, "cannot search key")
+	}
+	return objs, k.ctx.FindObjectsFinal(k.session)
+}
+
+func (k *key) rsaPublicKey() (crypto.PublicKey, error) {
+	attrs, err := k.ctx.GetAttributeValue(k.session, k.handle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_MODULUS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, nil),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get rsa public key")
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(attrs[0].Value),
+		E: int(new(big.Int).SetBytes(attrs[1].Value).Int64()),
+	}, nil
+}
+
+var (
+	oidP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidP384 = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+)
+
+// ecdsaPublicKey reads the public key from the public key object
+// matching the private key, because the EC point is not an attribute
+// of a private key object.
+func (k *key) ecdsaPublicKey(template []*pkcs11.Attribute) (crypto.PublicKey, error) {
+	objs, err := k.find(append(template, pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY)))
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) != 1 {
+		return nil, errors.ErrNotFound("pkcs11 public key for ecdsa private key")
+	}
+	attrs, err := k.ctx.GetAttributeValue(k.session, objs[0], []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get ecdsa public key")
+	}
+
+	var oid asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(attrs[0].Value, &oid); err != nil {
+		return nil, errors.Wrapf(err, "invalid ec params")
+	}
+	var curve elliptic.Curve
+	switch {
+	case oid.Equal(oidP256):
+		curve = elliptic.P256()
+	case oid.Equal(oidP384):
+		curve = elliptic.P384()
+	default:
+		return nil, errors.ErrNotSupported("ecdsa curve", oid.String())
+	}
+
+	var point []byte
+	if _, err := asn1.Unmarshal(attrs[1].Value, &point); err != nil {
+		// some modules provide the plain point instead of a DER octet string
+		point = attrs[1].Value
+	}
+	x, y := elliptic.Unmarshal(curve, point) //nolint:staticcheck // ecdh does not provide ecdsa keys
+	if x == nil {
+		return nil, fmt.Errorf("invalid ec point")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func (k *key) Public() crypto.PublicKey {
+	return k.public
+}
+
+// DigestInfo prefixes required for PKCS#1 v1.5 signatures (RFC 8017),
+// because the mechanism CKM_RSA_PKCS expects the DER encoded DigestInfo.
+var digestInfoPrefix = map[crypto.Hash][]byte{
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+// Sign signs the given digest with the token key. For RSA keys a
+// PKCS#1 v1.5 signature and for EC keys an ASN.1 encoded ECDSA
+// signature is provided.
+func (k *key) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	var mech uint
+	data := digest
+	switch k.public.(type) {
+	case *rsa.PublicKey:
+		prefix, ok := digestInfoPrefix[opts.HashFunc()]
+		if !ok {
+			return nil, errors.ErrNotSupported("hash function", opts.HashFunc().String())
+		}
+		if len(digest) != opts.HashFunc().Size() {
+			return nil, fmt.Errorf("digest length %d does not match hash function %s", len(digest), opts.HashFunc())
+		}
+		mech = pkcs11.CKM_RSA_PKCS
+		data = append(append([]byte{}, prefix...), digest...)
+	case *ecdsa.PublicKey:
+		mech = pkcs11.CKM_ECDSA
+	}
+
+	err := k.ctx.SignInit(k.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(mech, nil)}, k.handle)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot initialize signing")
+	}
+	sig, err := k.ctx.Sign(k.session, data)
+	if err != nil {
+		return nil, err
+	}
+	if mech == pkcs11.CKM_ECDSA {
+		// PKCS#11 provides the plain concatenation of r and s.
+		n := len(sig) / 2
+		return asn1.Marshal(struct{ R, S *big.Int }{
+			new(big.Int).SetBytes(sig[:n]),
+			new(big.Int).SetBytes(sig[n:]),
+		})
+	}
+	return sig, nil
+}
+
+func (k *key) Close() error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	return k.ctx.CloseSession(k.session)
+}
+
+// bytesToUint decodes a CK_ULONG attribute value, which is
+// provided in native byte order.
+func bytesToUint(b []byte) uint {
+	switch len(b) {
+	case 8:
+		return uint(binary.NativeEndian.Uint64(b))
+	case 4:
+		return uint(binary.NativeEndian.Uint32(b))
+	}
+	return 0
+}
@@ -0,0 +1,13 @@
+//go:build !cgo
+
+package pkcs11
+
+import (
+	"github.com/mandelsoft/goutils/errors"
+)
+
+// OpenKey is not supported without cgo, because PKCS#11 modules
+// are shared libraries.
+func OpenKey(uri *URI, pin string) (Key, error) {
+	return nil, errors.ErrNotSupported("PKCS#11", "binary built without cgo")
+}
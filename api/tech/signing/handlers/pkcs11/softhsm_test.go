@@ -0,0 +1,160 @@
+//go:build cgo
+
+package pkcs11_test
+
+import (
+	"crypto"
+	"encoding/asn1"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	p11 "github.com/miekg/pkcs11"
+	"github.com/opencontainers/go-digest"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/ecdsa"
+	"ocm.software/ocm/api/tech/signing/handlers/pkcs11"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+)
+
+const (
+	TOKEN = "ocm-test"
+	PIN   = "1234"
+)
+
+var softHSMModules = []string{
+	"/usr/lib/softhsm/libsofthsm2.so",
+	"/usr/lib/x86_64-linux-gnu/softhsm/libsofthsm2.so",
+	"/usr/lib64/pkcs11/libsofthsm2.so",
+	"/usr/local/lib/softhsm/libsofthsm2.so",
+	"/opt/homebrew/lib/softhsm/libsofthsm2.so",
+}
+
+func softHSMModule() string {
+	if m := os.Getenv("SOFTHSM2_MODULE"); m != "" {
+		return m
+	}
+	for _, m := range softHSMModules {
+		if _, err := os.Stat(m); err == nil {
+			return m
+		}
+	}
+	return ""
+}
+
+// generateKeys creates an RSA and an EC P-256 key pair on the token.
+func generateKeys(module string) {
+	ctx := p11.New(module)
+	Expect(ctx).NotTo(BeNil())
+	err := ctx.Initialize()
+	if err != nil {
+		Expect(err).To(Equal(p11.Error(p11.CKR_CRYPTOKI_ALREADY_INITIALIZED)))
+	}
+
+	slots := Must(ctx.GetSlotList(true))
+	var slot uint
+	found := false
+	for _, s := range slots {
+		info := Must(ctx.GetTokenInfo(s))
+		if info.Label == TOKEN {
+			slot, found = s, true
+		}
+	}
+	Expect(found).To(BeTrue())
+
+	session := Must(ctx.OpenSession(slot, p11.CKF_SERIAL_SESSION|p11.CKF_RW_SESSION))
+	defer ctx.CloseSession(session)
+	MustBeSuccessful(ctx.Login(session, p11.CKU_USER, PIN))
+
+	_, _ = Must2(ctx.GenerateKeyPair(session,
+		[]*p11.Mechanism{p11.NewMechanism(p11.CKM_RSA_PKCS_KEY_PAIR_GEN, nil)},
+		[]*p11.Attribute{
+			p11.NewAttribute(p11.CKA_TOKEN, true),
+			p11.NewAttribute(p11.CKA_LABEL, "rsa"),
+			p11.NewAttribute(p11.CKA_ID, []byte{1}),
+			p11.NewAttribute(p11.CKA_VERIFY, true),
+			p11.NewAttribute(p11.CKA_MODULUS_BITS, 2048),
+			p11.NewAttribute(p11.CKA_PUBLIC_EXPONENT, []byte{1, 0, 1}),
+		},
+		[]*p11.Attribute{
+			p11.NewAttribute(p11.CKA_TOKEN, true),
+			p11.NewAttribute(p11.CKA_LABEL, "rsa"),
+			p11.NewAttribute(p11.CKA_ID, []byte{1}),
+			p11.NewAttribute(p11.CKA_SIGN, true),
+			p11.NewAttribute(p11.CKA_PRIVATE, true),
+			p11.NewAttribute(p11.CKA_SENSITIVE, true),
+		},
+	))
+
+	params := Must(asn1.Marshal(asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}))
+	_, _ = Must2(ctx.GenerateKeyPair(session,
+		[]*p11.Mechanism{p11.NewMechanism(p11.CKM_EC_KEY_PAIR_GEN, nil)},
+		[]*p11.Attribute{
+			p11.NewAttribute(p11.CKA_TOKEN, true),
+			p11.NewAttribute(p11.CKA_LABEL, "ec"),
+			p11.NewAttribute(p11.CKA_ID, []byte{2}),
+			p11.NewAttribute(p11.CKA_VERIFY, true),
+			p11.NewAttribute(p11.CKA_EC_PARAMS, params),
+		},
+		[]*p11.Attribute{
+			p11.NewAttribute(p11.CKA_TOKEN, true),
+			p11.NewAttribute(p11.CKA_LABEL, "ec"),
+			p11.NewAttribute(p11.CKA_ID, []byte{2}),
+			p11.NewAttribute(p11.CKA_SIGN, true),
+			p11.NewAttribute(p11.CKA_PRIVATE, true),
+			p11.NewAttribute(p11.CKA_SENSITIVE, true),
+		},
+	))
+}
+
+var _ = Describe("SoftHSM", Ordered, func() {
+	var module string
+
+	BeforeAll(func() {
+		module = softHSMModule()
+		util, err := exec.LookPath("softhsm2-util")
+		if module == "" || err != nil {
+			Skip("SoftHSM not available")
+		}
+
+		dir := GinkgoT().TempDir()
+		tokens := filepath.Join(dir, "tokens")
+		MustBeSuccessful(os.Mkdir(tokens, 0o700))
+		conf := filepath.Join(dir, "softhsm2.conf")
+		MustBeSuccessful(os.WriteFile(conf, []byte(fmt.Sprintf("directories.tokendir = %s\nobjectstore.backend = file\n", tokens)), 0o600))
+		GinkgoT().Setenv("SOFTHSM2_CONF", conf)
+
+		out, err := exec.Command(util, "--init-token", "--free", "--label", TOKEN, "--pin", PIN, "--so-pin", "0000").CombinedOutput()
+		Expect(err).To(Succeed(), string(out))
+
+		generateKeys(module)
+	})
+
+	DescribeTable("signs with token key", func(object string, algo string, verifier signing.Verifier) {
+		cctx := credentials.New()
+		cctx.SetCredentialsForConsumer(credentials.NewConsumerIdentity(pkcs11.CONSUMER_TYPE, pkcs11.ID_TOKEN, TOKEN),
+			credentials.DirectCredentials{pkcs11.ATTR_PIN: PIN})
+
+		uri := fmt.Sprintf("pkcs11:token=%s;object=%s?module-path=%s", TOKEN, object, module)
+		d := digest.FromBytes([]byte("digest"))
+		sig := Must(pkcs11.NewHandler().Sign(cctx, d.Hex(), &signing.DefaultSigningContext{
+			Hash:       crypto.SHA256,
+			PrivateKey: []byte(uri),
+		}))
+		Expect(sig.Algorithm).To(Equal(algo))
+
+		key := Must(pkcs11.OpenKey(Must(pkcs11.ParseURI(uri+"&pin-value="+PIN)), ""))
+		defer key.Close()
+		MustBeSuccessful(verifier.Verify(d.Hex(), sig, &signing.DefaultSigningContext{PublicKey: key.Public()}))
+	},
+		Entry("rsa", "rsa", rsa.Algorithm, rsa.NewHandler()),
+		Entry("ecdsa", "ec", ecdsa.AlgorithmP256, ecdsa.NewHandler()),
+	)
+})
@@ -0,0 +1,218 @@
+package pkcs11
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+)
+
+// URIScheme is the scheme of a PKCS#11 URI according to RFC 7512.
+const URIScheme = "pkcs11"
+
+// path attributes.
+const (
+	URI_TOKEN        = "token"
+	URI_MANUFACTURER = "manufacturer"
+	URI_SERIAL       = "serial"
+	URI_MODEL        = "model"
+	URI_SLOT_ID      = "slot-id"
+	URI_OBJECT       = "object"
+	URI_ID           = "id"
+	URI_TYPE         = "type"
+)
+
+// query attributes.
+const (
+	URI_MODULE_PATH = "module-path"
+	URI_PIN_VALUE   = "pin-value"
+	URI_PIN_SOURCE  = "pin-source"
+)
+
+const KIND_PKCS11_URI = "PKCS#11 URI"
+
+// URI describes a key held by a PKCS#11 token as described
+// by RFC 7512.
+// The token is selected by its label, serial, manufacturer, model or
+// slot id, the key is selected by its object label and/or id.
+// The module to use is given by the query attribute module-path.
+type URI struct {
+	Path  map[string]string
+	Query map[string]string
+}
+
+// ParseURI parses a PKCS#11 URI (RFC 7512).
+func ParseURI(s string) (*URI, error) {
+	s = strings.TrimSpace(s)
+	scheme, rest, ok := strings.Cut(s, ":")
+	if !ok || scheme != URIScheme {
+		return nil, errors.ErrInvalid(KIND_PKCS11_URI, s)
+	}
+	path, query, _ := strings.Cut(rest, "?")
+
+	u := &URI{
+		Path:  map[string]string{},
+		Query: map[string]string{},
+	}
+	if err := parseAttributes(u.Path, path, ";"); err != nil {
+		return nil, errors.ErrInvalidWrap(err, KIND_PKCS11_URI, s)
+	}
+	if err := parseAttributes(u.Query, query, "&"); err != nil {
+		return nil, errors.ErrInvalidWrap(err, KIND_PKCS11_URI, s)
+	}
+	if u.Path[URI_SLOT_ID] != "" {
+		if _, err := strconv.ParseUint(u.Path[URI_SLOT_ID], 10, 32); err != nil {
+			return nil, errors.ErrInvalidWrap(err, KIND_PKCS11_URI, s)
+		}
+	}
+	if u.Path[URI_TYPE] != "" && u.Path[URI_TYPE] != "private" {
+		return nil, errors.ErrInvalidWrap(errors.New("object type must be private"), KIND_PKCS11_URI, s)
+	}
+	if u.Path[URI_OBJECT] == "" && u.Path[URI_ID] == "" {
+		return nil, errors.ErrInvalidWrap(errors.New("object or id required"), KIND_PKCS11_URI, s)
+	}
+	return u, nil
+}
+
+func parseAttributes(attrs map[string]string, s string, sep string) error {
+	if s == "" {
+		return nil
+	}
+	for _, e := range strings.Split(s, sep) {
+		if e == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(e, "=")
+		if !ok || k == "" {
+			return fmt.Errorf("invalid attribute %q", e)
+		}
+		if _, ok := attrs[k]; ok {
+			return fmt.Errorf("duplicate attribute %q", k)
+		}
+		d, err := url.PathUnescape(v)
+		if err != nil {
+			return fmt.Errorf("invalid value for attribute %q: %w", k, err)
+		}
+		attrs[k] = d
+	}
+	return nil
+}
+
+func (u *URI) Token() string {
+	return u.Path[URI_TOKEN]
+}
+
+func (u *URI) Serial() string {
+	return u.Path[URI_SERIAL]
+}
+
+func (u *URI) Manufacturer() string {
+	return u.Path[URI_MANUFACTURER]
+}
+
+func (u *URI) Model() string {
+	return u.Path[URI_MODEL]
+}
+
+// SlotId returns the optional slot id.
+func (u *URI) SlotId() (uint, bool) {
+	s, ok := u.Path[URI_SLOT_ID]
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// Object returns the label of the key object.
+func (u *URI) Object() string {
+	return u.Path[URI_OBJECT]
+}
+
+// Id returns the CKA_ID of the key object.
+func (u *URI) Id() []byte {
+	if id, ok := u.Path[URI_ID]; ok {
+		return []byte(id)
+	}
+	return nil
+}
+
+func (u *URI) ModulePath() string {
+	return u.Query[URI_MODULE_PATH]
+}
+
+func (u *URI) PinValue() string {
+	return u.Query[URI_PIN_VALUE]
+}
+
+func (u *URI) PinSource() string {
+	return u.Query[URI_PIN_SOURCE]
+}
+
+// String provides the URI with percent-encoded attribute values.
+// The pin-value attribute is omitted.
+func (u *URI) String() string {
+	s := URIScheme + ":" + formatAttributes(u.Path, ";", nil)
+	q := formatAttributes(u.Query, "&", map[string]bool{URI_PIN_VALUE: true})
+	if q != "" {
+		s += "?" + q
+	}
+	return s
+}
+
+func formatAttributes(attrs map[string]string, sep string, skip map[string]bool) string {
+	var keys []string
+	for k := range attrs {
+		if !skip[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var list []string
+	for _, k := range keys {
+		list = append(list, k+"="+escape(attrs[k]))
+	}
+	return strings.Join(list, sep)
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func isUnreserved(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("-._~:[]@!$'()*+,=/", c) >= 0
+}
+
+// GetURI provides a PKCS#11 URI for a private key specification.
+// Such a specification might be the URI string, its byte representation,
+// or an already parsed URI.
+func GetURI(key interface{}) (*URI, error) {
+	switch k := key.(type) {
+	case *URI:
+		return k, nil
+	case string:
+		return ParseURI(k)
+	case []byte:
+		return ParseURI(string(k))
+	default:
+		return nil, fmt.Errorf("unknown key specification %T", k)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/mandelsoft/goutils/sliceutils"
 
 	"ocm.software/ocm/api/tech/signing/signutils"
+	"ocm.software/ocm/api/tech/signing/tsa"
 )
 
 const DEFAULT_TSA_URL = "http://timestamp.digicert.com"
@@ -30,6 +31,12 @@ type RegistryFuncs interface {
 
 	TSAUrl() string
 	SetTSAUrl(url string)
+
+	RegisterTimestampAuthority(name string, c tsa.Client)
+	GetTimestampAuthority(name string) tsa.Client
+
+	TSARootCerts() signutils.GenericCertificatePool
+	SetTSARootCerts(pool signutils.GenericCertificatePool)
 }
 
 type HasherProvider interface {
@@ -507,7 +514,12 @@ type registry struct {
 	_HandlerRegistry
 	_KeyRegistry
 
-	tsaUrl string
+	tsaUrl       string
+	tsaRootCerts signutils.GenericCertificatePool
+
+	lock        sync.RWMutex
+	authorities map[string]tsa.Client
+	parent      RegistryFuncs
 }
 
 var _ Registry = (*registry)(nil)
@@ -528,15 +540,24 @@ func (r *registry) KeyRegistry() KeyRegistry {
 }
 
 func (r *registry) Copy() Registry {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
 	return &registry{
 		_HandlerRegistry: r.HandlerRegistry().Copy(),
 		_KeyRegistry:     r.KeyRegistry().Copy(),
 		tsaUrl:           r.tsaUrl,
+		tsaRootCerts:     r.tsaRootCerts,
+		authorities:      maps.Clone(r.authorities),
+		parent:           r.parent,
 	}
 }
 
 func (r *registry) TSAUrl() string {
 	if r.tsaUrl == "" {
+		if r.parent != nil {
+			return r.parent.TSAUrl()
+		}
 		return DEFAULT_TSA_URL
 	}
 	return r.tsaUrl
@@ -546,6 +567,44 @@ func (r *registry) SetTSAUrl(url string) {
 	r.tsaUrl = url
 }
 
+// TSARootCerts provides the root certificates used to verify
+// timestamps without network access.
+func (r *registry) TSARootCerts() signutils.GenericCertificatePool {
+	if r.tsaRootCerts == nil && r.parent != nil {
+		return r.parent.TSARootCerts()
+	}
+	return r.tsaRootCerts
+}
+
+func (r *registry) SetTSARootCerts(pool signutils.GenericCertificatePool) {
+	r.tsaRootCerts = pool
+}
+
+// RegisterTimestampAuthority registers a named timestamp authority.
+// The name can be used instead of a TSA URL.
+func (r *registry) RegisterTimestampAuthority(name string, c tsa.Client) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.authorities == nil {
+		r.authorities = map[string]tsa.Client{}
+	}
+	r.authorities[name] = c
+}
+
+func (r *registry) GetTimestampAuthority(name string) tsa.Client {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if c := r.authorities[name]; c != nil {
+		return c
+	}
+	if r.parent != nil {
+		return r.parent.GetTimestampAuthority(name)
+	}
+	return nil
+}
+
 func RegistryWithPreferredKeys(reg Registry, keys KeyRegistry) Registry {
 	if keys == nil {
 		return reg
@@ -553,6 +612,7 @@ func RegistryWithPreferredKeys(reg Registry, keys KeyRegistry) Registry {
 	return &registry{
 		_HandlerRegistry: reg.HandlerRegistry(),
 		_KeyRegistry:     NewKeyRegistry(keys, reg.KeyRegistry()),
+		parent:           reg,
 	}
 }
 
@@ -1,15 +1,21 @@
 package signing_test
 
 import (
+	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/pem"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"ocm.software/ocm/api/credentials"
 	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/ecdsa"
+	"ocm.software/ocm/api/tech/signing/handlers/ed25519"
 	"ocm.software/ocm/api/tech/signing/handlers/rsa"
 	"ocm.software/ocm/api/tech/signing/hasher/sha256"
+	"ocm.software/ocm/api/tech/signing/signutils"
 )
 
 var registry = signing.DefaultRegistry()
@@ -52,4 +58,109 @@ var _ = Describe("signing", func() {
 		hash = "A" + hash[1:]
 		Expect(registry.GetVerifier(rsa.Algorithm).Verify(hash, sig, sctx)).To(HaveOccurred())
 	})
+
+	DescribeTable("uses key based signers", func(algo, media string) {
+		hasher := registry.GetHasher(sha256.Algorithm)
+		hash, _ := signing.Hash(hasher.Create(), []byte("test"))
+
+		handler := registry.GetSigner(algo)
+		Expect(handler).NotTo(BeNil())
+		priv, pub, err := handler.(signing.KeyPairCreator).CreateKeyPair()
+		Expect(err).To(Succeed())
+
+		// keys are passed in their PEM representation
+		privData := pem.EncodeToMemory(signutils.PemBlockForPrivateKey(priv))
+		pubData := pem.EncodeToMemory(signutils.PemBlockForPublicKey(pub))
+
+		sctx := &signing.DefaultSigningContext{
+			Hash:       hasher.Crypto(),
+			PrivateKey: privData,
+			PublicKey:  pubData,
+		}
+		sig, err := handler.Sign(defaultContext, hash, sctx)
+		Expect(err).To(Succeed())
+		Expect(sig.MediaType).To(Equal(media))
+		Expect(sig.Algorithm).To(Equal(algo))
+
+		Expect(registry.GetVerifier(algo).Verify(hash, sig, sctx)).To(Succeed())
+		hash = "A" + hash[1:]
+		Expect(registry.GetVerifier(algo).Verify(hash, sig, sctx)).To(MatchError("signature verification failed"))
+	},
+		Entry("ecdsa P-256", ecdsa.AlgorithmP256, ecdsa.MediaType),
+		Entry("ecdsa P-384", ecdsa.AlgorithmP384, ecdsa.MediaType),
+		Entry("ed25519", ed25519.Algorithm, ed25519.MediaType),
+	)
+
+	It("rejects ecdsa keys with wrong curve", func() {
+		hasher := registry.GetHasher(sha256.Algorithm)
+		hash, _ := signing.Hash(hasher.Create(), []byte("test"))
+
+		priv, pub, err := ecdsa.NewHandlerFor(ecdsa.P384).(signing.KeyPairCreator).CreateKeyPair()
+		Expect(err).To(Succeed())
+		sctx := &signing.DefaultSigningContext{
+			Hash:       hasher.Crypto(),
+			PrivateKey: priv,
+			PublicKey:  pub,
+		}
+		_, err = registry.GetSigner(ecdsa.AlgorithmP256).Sign(defaultContext, hash, sctx)
+		Expect(err).To(MatchError("invalid ecdsa private key: key curve P-384 does not match ECDSA-P256"))
+	})
+
+	It("parses ecdsa public keys with generic and specific pem block type", func() {
+		_, pub, err := ecdsa.NewHandlerFor(ecdsa.P256).(signing.KeyPairCreator).CreateKeyPair()
+		Expect(err).To(Succeed())
+
+		block := signutils.PemBlockForPublicKey(pub)
+		Expect(block.Type).To(Equal("ECDSA PUBLIC KEY"))
+		key, err := ecdsa.ParseKey(pem.EncodeToMemory(block))
+		Expect(err).To(Succeed())
+		Expect(key).To(Equal(pub))
+
+		block.Type = "PUBLIC KEY"
+		key, err = ecdsa.ParseKey(pem.EncodeToMemory(block))
+		Expect(err).To(Succeed())
+		Expect(key).To(Equal(pub))
+	})
+
+	DescribeTable("signs with certificate chain", func(algo string) {
+		hasher := registry.GetHasher(sha256.Algorithm)
+		hash, _ := signing.Hash(hasher.Create(), []byte("test"))
+
+		creator := registry.GetSigner(algo).(signing.KeyPairCreator)
+		capriv, capub, err := creator.CreateKeyPair()
+		Expect(err).To(Succeed())
+		caData, err := CreateCertificate(pkix.Name{CommonName: "ca-authority"}, nil, 10*time.Hour, capub, nil, capriv, true)
+		Expect(err).To(Succeed())
+		ca, err := signutils.ParseCertificate(caData)
+		Expect(err).To(Succeed())
+
+		priv, pub, err := creator.CreateKeyPair()
+		Expect(err).To(Succeed())
+		certData, err := CreateCertificate(*ISSUER, nil, 10*time.Hour, pub, ca, capriv, false)
+		Expect(err).To(Succeed())
+
+		pool := x509.NewCertPool()
+		pool.AddCert(ca)
+
+		sctx := &signing.DefaultSigningContext{
+			Hash:       hasher.Crypto(),
+			PrivateKey: priv,
+			PublicKey:  certData,
+			RootCerts:  pool,
+			Issuer:     ISSUER,
+		}
+		sig, err := registry.GetSigner(algo).Sign(defaultContext, hash, sctx)
+		Expect(err).To(Succeed())
+		Expect(sig.MediaType).To(Equal(signutils.MediaTypePEM))
+		Expect(sig.Issuer).To(Equal("CN=mandelsoft"))
+
+		_, _, certs, err := signutils.GetSignatureFromPem([]byte(sig.Value))
+		Expect(err).To(Succeed())
+		Expect(len(certs)).To(Equal(2))
+		sctx.PublicKey = certs[0]
+		Expect(registry.GetVerifier(algo).Verify(hash, sig, sctx)).To(Succeed())
+	},
+		Entry("ecdsa", ecdsa.AlgorithmP256),
+		Entry("ed25519", ed25519.Algorithm),
+	)
 })
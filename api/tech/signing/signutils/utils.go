@@ -5,6 +5,7 @@ import (
 	"crypto"
 	"crypto/dsa" //nolint: staticcheck // yes
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -30,6 +31,8 @@ func privateKey(block *pem.Block) (interface{}, error) {
 		return x509.ParsePKCS1PrivateKey(x509Encoded)
 	case "EC PRIVATE KEY":
 		return x509.ParseECPrivateKey(x509Encoded)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(x509Encoded)
 	default:
 		return nil, fmt.Errorf("invalid pem block type %q", block.Type)
 	}
@@ -46,6 +49,12 @@ func PemBlockForPrivateKey(priv interface{}) *pem.Block {
 			os.Exit(2)
 		}
 		return &pem.Block{Type: "EC PRIVATE KEY", Bytes: b}
+	case ed25519.PrivateKey:
+		b, err := x509.MarshalPKCS8PrivateKey(k)
+		if err != nil {
+			return nil
+		}
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: b}
 	default:
 		return nil
 	}
@@ -68,6 +77,12 @@ func PemBlockForPublicKey(priv interface{}, gen ...bool) *pem.Block {
 			return nil
 		}
 		return &pem.Block{Type: "ECDSA PUBLIC KEY", Bytes: b}
+	case ed25519.PublicKey:
+		b, err := x509.MarshalPKIXPublicKey(k)
+		if err != nil {
+			return nil
+		}
+		return &pem.Block{Type: "PUBLIC KEY", Bytes: b}
 	default:
 		return nil
 	}
@@ -98,6 +113,8 @@ func ParsePublicKey(data []byte) (interface{}, error) {
 		return pub, nil
 	case *ecdsa.PublicKey:
 		return pub, nil
+	case ed25519.PublicKey:
+		return pub, nil
 	default:
 		return nil, fmt.Errorf("unknown type of public key")
 	}
@@ -188,6 +205,8 @@ func GetPrivateKey(key GenericPrivateKey) (interface{}, error) {
 		return k, nil
 	case *ecdsa.PrivateKey:
 		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
 	default:
 		return nil, errors.ErrInvalidType(KIND_PRIVATE_KEY, k)
 	}
@@ -207,6 +226,8 @@ func GetPublicKey(key GenericPublicKey) (interface{}, error) {
 		return k, nil
 	case *ecdsa.PublicKey:
 		return k, nil
+	case ed25519.PublicKey:
+		return k, nil
 	case *x509.Certificate:
 		return k.PublicKey, nil
 	case PublicKeySource:
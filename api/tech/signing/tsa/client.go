@@ -0,0 +1,52 @@
+package tsa
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mandelsoft/vfs/pkg/vfs"
+)
+
+// LOCAL_PREFIX is the prefix of a TSA URL describing a local timestamp
+// authority. The rest of the URL is the path of a PEM file containing the
+// private key and certificate chain of the authority.
+const LOCAL_PREFIX = "file:"
+
+// Client is a timestamp authority issuing RFC 3161 timestamps
+// for message imprints.
+type Client interface {
+	// Timestamp requests a timestamp for the given message imprint.
+	// It returns the timestamp token and the certified time.
+	Timestamp(mi *MessageImprint) (*TimeStamp, time.Time, error)
+}
+
+// ClientFunc is a function implementing the Client interface.
+type ClientFunc func(mi *MessageImprint) (*TimeStamp, time.Time, error)
+
+func (f ClientFunc) Timestamp(mi *MessageImprint) (*TimeStamp, time.Time, error) {
+	return f(mi)
+}
+
+type httpClient struct {
+	url string
+}
+
+// NewHTTPClient provides a client requesting timestamps
+// from a remote TSA server.
+func NewHTTPClient(url string) Client {
+	return &httpClient{url: url}
+}
+
+func (c *httpClient) Timestamp(mi *MessageImprint) (*TimeStamp, time.Time, error) {
+	return Request(c.url, mi)
+}
+
+// NewClient provides a client for a TSA URL. URLs with the prefix
+// LOCAL_PREFIX describe a local authority (see NewLocalAuthorityFromFile),
+// all other URLs are used to request timestamps from a remote TSA server.
+func NewClient(url string, fss ...vfs.FileSystem) (Client, error) {
+	if path, ok := strings.CutPrefix(url, LOCAL_PREFIX); ok {
+		return NewLocalAuthorityFromFile(path, fss...)
+	}
+	return NewHTTPClient(url), nil
+}
@@ -0,0 +1,151 @@
+package tsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	asn "github.com/InfiniteLoopSpace/go_S-MIME/asn1"
+	cms "github.com/InfiniteLoopSpace/go_S-MIME/cms/protocol"
+	"github.com/InfiniteLoopSpace/go_S-MIME/oid"
+	tsa "github.com/InfiniteLoopSpace/go_S-MIME/timestamp"
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/tech/signing/signutils"
+	"ocm.software/ocm/api/utils"
+)
+
+// AnyPolicy is the default policy used by a local timestamp authority.
+var AnyPolicy = asn1.ObjectIdentifier{2, 5, 29, 32, 0}
+
+// LocalAuthority is a timestamp authority issuing timestamps
+// with a locally available private key. It can be used for tests or
+// air-gapped environments without access to a public TSA.
+type LocalAuthority struct {
+	key    crypto.Signer
+	chain  []*x509.Certificate
+	policy asn1.ObjectIdentifier
+}
+
+var _ Client = (*LocalAuthority)(nil)
+
+// NewLocalAuthority creates a local timestamp authority for a private key
+// and a certificate chain. The first certificate must be the certificate for
+// the private key and must be usable for timestamping.
+func NewLocalAuthority(priv signutils.GenericPrivateKey, chain signutils.GenericCertificateChain) (*LocalAuthority, error) {
+	key, err := signutils.GetPrivateKey(priv)
+	if err != nil {
+		return nil, errors.Wrapf(err, "timestamp authority key")
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.Newf("timestamp authority key (%T) cannot be used for signing", key)
+	}
+	certs, err := signutils.GetCertificateChain(chain, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "timestamp authority certificate")
+	}
+	if len(certs) == 0 {
+		return nil, errors.Newf("timestamp authority certificate required")
+	}
+	if pub, ok := certs[0].PublicKey.(interface{ Equal(crypto.PublicKey) bool }); !ok || !pub.Equal(signer.Public()) {
+		return nil, errors.Newf("private key does not match timestamp authority certificate")
+	}
+	if !slices.Contains(certs[0].ExtKeyUsage, x509.ExtKeyUsageTimeStamping) {
+		return nil, errors.Newf("timestamp authority certificate %q is not valid for timestamping", certs[0].Subject.String())
+	}
+	return &LocalAuthority{
+		key:    signer,
+		chain:  certs,
+		policy: AnyPolicy,
+	}, nil
+}
+
+// NewLocalAuthorityFromFile creates a local timestamp authority from a PEM
+// file containing the private key and the certificate chain of the authority.
+func NewLocalAuthorityFromFile(path string, fss ...vfs.FileSystem) (*LocalAuthority, error) {
+	data, err := utils.ReadFile(path, utils.FileSystem(fss...))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read timestamp authority file %q", path)
+	}
+	var key interface{}
+	var certs []byte
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type == signutils.CertificatePEMBlockType {
+			certs = append(certs, pem.EncodeToMemory(block)...)
+		} else {
+			if key != nil {
+				return nil, errors.Newf("multiple private keys found in timestamp authority file %q", path)
+			}
+			key, err = signutils.ParsePrivateKey(pem.EncodeToMemory(block))
+			if err != nil {
+				return nil, errors.Wrapf(err, "timestamp authority file %q", path)
+			}
+		}
+		data = rest
+	}
+	if key == nil {
+		return nil, errors.Newf("no private key found in timestamp authority file %q", path)
+	}
+	return NewLocalAuthority(key, certs)
+}
+
+// SetPolicy sets the policy id used for issued timestamps.
+func (a *LocalAuthority) SetPolicy(policy asn1.ObjectIdentifier) {
+	a.policy = policy
+}
+
+// Certificate provides the certificate of the authority.
+func (a *LocalAuthority) Certificate() *x509.Certificate {
+	return a.chain[0]
+}
+
+func (a *LocalAuthority) Timestamp(mi *MessageImprint) (*TimeStamp, time.Time, error) {
+	if mi == nil {
+		return nil, time.Time{}, fmt.Errorf("message imprint required")
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, time.Time{}, errors.Wrapf(err, "failed to generate serial number")
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	info := tsa.TSTInfo{
+		Version:        1,
+		Policy:         a.policy,
+		MessageImprint: *mi,
+		SerialNumber:   serial,
+		GenTime:        now,
+	}
+	data, err := asn.Marshal(info)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrapf(err, "cannot marshal timestamp info")
+	}
+	sd, err := cms.NewSignedData(cms.EncapsulatedContentInfo{EContentType: oid.TSTInfo, EContent: data})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	kp := tls.Certificate{
+		PrivateKey: a.key,
+		Leaf:       a.chain[0],
+	}
+	for _, c := range a.chain {
+		kp.Certificate = append(kp.Certificate, c.Raw)
+	}
+	err = sd.AddSignerInfo(kp, nil)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrapf(err, "cannot sign timestamp")
+	}
+	return sd, now, nil
+}
@@ -1,11 +1,14 @@
 package tsa
 
 import (
+	"bytes"
 	"crypto"
+	"crypto/x509"
 	"crypto/x509/pkix"
 	"fmt"
 	"time"
 
+	asn "github.com/InfiniteLoopSpace/go_S-MIME/asn1"
 	cms "github.com/InfiniteLoopSpace/go_S-MIME/cms/protocol"
 	"github.com/InfiniteLoopSpace/go_S-MIME/oid"
 	tsa "github.com/InfiniteLoopSpace/go_S-MIME/timestamp"
@@ -99,6 +102,92 @@ func Verify(mi *tsa.MessageImprint, sd *TimeStamp, now bool, rootpool ...signuti
 	return &info.GenTime, nil
 }
 
+// VerifyOffline verifies a timestamp for a message imprint without network
+// access. In contrast to Verify, the certificate chain of the timestamp
+// authority is validated for the time certified by the timestamp, using only
+// the certificates provided by the timestamp and the given root pool.
+func VerifyOffline(mi *tsa.MessageImprint, sd *TimeStamp, rootpool signutils.GenericCertificatePool) (*time.Time, error) {
+	info, err := tsa.ParseInfo(sd.EncapContentInfo)
+	if err != nil {
+		return nil, err
+	}
+	if diff := deep.Equal(info.MessageImprint.HashAlgorithm.Algorithm, mi.HashAlgorithm.Algorithm); diff != nil {
+		return nil, fmt.Errorf("hash algorithm mismatch: %s", diff)
+	}
+	if diff := deep.Equal(info.MessageImprint.HashedMessage, mi.HashedMessage); diff != nil {
+		return nil, fmt.Errorf("digest mismatch: %s", diff)
+	}
+
+	roots, err := signutils.GetCertPool(rootpool, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "root cert pool")
+	}
+	if roots == nil {
+		return nil, errors.Newf("root certificates required for offline timestamp verification")
+	}
+	certs, err := sd.X509Certificates()
+	if err != nil {
+		return nil, errors.Wrapf(err, "timestamp certificates")
+	}
+	var list []*x509.Certificate
+	intermediates := x509.NewCertPool()
+	for _, c := range certs {
+		list = append(list, c)
+		intermediates.AddCert(c)
+	}
+	if len(sd.SignerInfos) == 0 {
+		return nil, errors.Newf("timestamp is not signed")
+	}
+	for _, si := range sd.SignerInfos {
+		cert, err := si.FindCertificate(list)
+		if err != nil {
+			return nil, errors.Wrapf(err, "timestamp signer certificate")
+		}
+		_, err = cert.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   info.GenTime,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "timestamp authority certificate")
+		}
+		err = verifySignerInfo(si, cert, sd.EncapContentInfo.EContent)
+		if err != nil {
+			return nil, errors.Wrapf(err, "timestamp signature")
+		}
+	}
+	return &info.GenTime, nil
+}
+
+func verifySignerInfo(si cms.SignerInfo, cert *x509.Certificate, content []byte) error {
+	msg := content
+	if si.SignedAttrs != nil {
+		hash, err := si.Hash()
+		if err != nil {
+			return err
+		}
+		md := hash.New()
+		md.Write(content)
+		digest, err := si.GetMessageDigestAttribute()
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(digest, md.Sum(nil)) {
+			return errors.Newf("signed hash does not match the hash of the content")
+		}
+		msg, err = asn.MarshalWithParams(si.SignedAttrs, "set")
+		if err != nil {
+			return err
+		}
+	}
+	algo, err := si.X509SignatureAlgorithm()
+	if err != nil {
+		return err
+	}
+	return cert.CheckSignature(algo, msg, si.Signature)
+}
+
 func GetTimestamp(ts *TimeStamp) (time.Time, error) {
 	info, err := tsa.ParseInfo(ts.EncapContentInfo)
 	if err != nil {
@@ -98,6 +98,12 @@ type SignatureHandler interface {
 	Verifier
 }
 
+// KeyPairCreator is an optional interface of a SignatureHandler
+// able to create key pairs suitable for its signature algorithm.
+type KeyPairCreator interface {
+	CreateKeyPair() (signutils.GenericPrivateKey, signutils.GenericPublicKey, error)
+}
+
 // Hasher creates a new hash.Hash interface.
 type Hasher interface {
 	Algorithm() string
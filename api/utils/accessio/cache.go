@@ -4,10 +4,10 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strings"
 	"sync"
 	"time"
 
+	"github.com/mandelsoft/filepath/pkg/filepath"
 	"github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/vfs/pkg/osfs"
 	"github.com/mandelsoft/vfs/pkg/projectionfs"
@@ -18,6 +18,7 @@ import (
 	"ocm.software/ocm/api/utils"
 	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
 	"ocm.software/ocm/api/utils/blobaccess/file"
+	"ocm.software/ocm/api/utils/filelock"
 	"ocm.software/ocm/api/utils/iotools"
 	common "ocm.software/ocm/api/utils/misc"
 	"ocm.software/ocm/api/utils/refmgmt"
@@ -61,6 +62,11 @@ type blobCache struct {
 	refmgmt.Allocatable
 	lock  sync.RWMutex
 	cache vfs.FileSystem
+
+	// opts is set for managed caches, only.
+	opts     *CacheOptions
+	flock    *filelock.Mutex
+	statlock sync.Mutex
 }
 
 var (
@@ -103,6 +109,42 @@ func NewStaticBlobCache(path string, fss ...vfs.FileSystem) (BlobCache, error) {
 	return NewDefaultBlobCache(fs)
 }
 
+// NewManagedBlobCache provides a persistent blob cache for the given
+// directory, which may be shared among multiple contexts and processes.
+// It keeps usage statistics and evicts entries according to the given
+// options.
+func NewManagedBlobCache(path string, opts *CacheOptions, fss ...vfs.FileSystem) (BlobCache, error) {
+	if opts == nil {
+		opts = &CacheOptions{}
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	fs := utils.FileSystem(fss...)
+	err := fs.MkdirAll(path, 0o700)
+	if err != nil {
+		return nil, err
+	}
+	var flock *filelock.Mutex
+	if osfs.IsOsFileSystem(fs) {
+		flock, err = filelock.MutexFor(filepath.Join(path, filelock.DIRECTORY_LOCK))
+		if err != nil {
+			return nil, err
+		}
+	}
+	pfs, err := projectionfs.New(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	c := &blobCache{
+		cache: pfs,
+		opts:  opts,
+		flock: flock,
+	}
+	c.Allocatable = refmgmt.NewAllocatable(c.cleanup)
+	return c, nil
+}
+
 func (c *blobCache) Root() (string, vfs.FileSystem) {
 	return vfs.PathSeparatorString, c.cache
 }
@@ -129,7 +171,7 @@ func (c *blobCache) Cleanup(p common.Printer, before *time.Time, dryrun bool) (c
 		return 0, 0, 0, 0, 0, 0, err
 	}
 	for _, e := range entries {
-		if strings.HasSuffix(e.Name(), ACCESS_SUFFIX) {
+		if !isCacheEntry(e) {
 			continue
 		}
 		base := vfs.Join(fs, path, e.Name())
@@ -183,13 +225,16 @@ func (c *blobCache) GetBlobData(digest digest.Digest) (int64, blobaccess.DataAcc
 
 		path := common.DigestToFileName(digest)
 		fi, err := c.cache.Stat(path)
+		if err == nil && c.opts != nil && c.opts.Verify {
+			err = c.verify(digest, path)
+		}
 		if err == nil {
-			vfs.WriteFile(c.cache, path+ACCESS_SUFFIX, []byte{}, 0o600)
-			// now := time.Now()
-			// c.cache.Chtimes(path+ACCESS_SUFFIX, now, now)
+			c.touch(path)
+			c.updateStatistics(func(s *CacheStatistics) { s.Hits++ })
 			return fi.Size(), file.DataAccess(c.cache, path), nil
 		}
 		if os.IsNotExist(err) {
+			c.updateStatistics(func(s *CacheStatistics) { s.Misses++ })
 			return -1, nil, blobaccess.ErrBlobNotFound(digest)
 		}
 	}
@@ -251,11 +296,24 @@ func (c *blobCache) AddBlob(blob blobaccess.BlobAccess) (int64, digest.Digest, e
 
 	c.lock.Lock()
 	defer c.lock.Unlock()
-	if ok, err = vfs.Exists(c.cache, target); err != nil || !ok {
-		err = c.cache.Rename(tmp, target)
-	}
+	err = c.withFileLock(func() error {
+		var err error
+		if ok, err = vfs.Exists(c.cache, target); err != nil || !ok {
+			err = c.cache.Rename(tmp, target)
+		}
+		return err
+	})
 	c.cache.Remove(tmp)
-	vfs.WriteFile(c.cache, target+ACCESS_SUFFIX, []byte{}, 0o600)
+	if err != nil {
+		return blobaccess.BLOB_UNKNOWN_SIZE, "", err
+	}
+	c.touch(target)
+	if !ok {
+		c.updateStatistics(func(s *CacheStatistics) { s.Additions++ })
+	}
+	if c.opts != nil && c.opts.MaxSize > 0 {
+		_, _, err = c.evict(nil, c.opts.MaxSize, false, target)
+	}
 	return size, digest, err
 }
 
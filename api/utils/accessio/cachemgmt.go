@@ -0,0 +1,349 @@
+package accessio
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	"github.com/opencontainers/go-digest"
+
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const (
+	// CACHE_STATISTICS is the name of the file used to persist the
+	// usage statistics of a managed cache.
+	CACHE_STATISTICS = ".stats"
+)
+
+type EvictionPolicy string
+
+const (
+	// EVICT_LRU evicts the least recently used entries first.
+	EVICT_LRU EvictionPolicy = "lru"
+	// EVICT_LFU evicts the least frequently used entries first.
+	EVICT_LFU EvictionPolicy = "lfu"
+)
+
+// CacheOptions describes the behaviour of a managed blob cache.
+type CacheOptions struct {
+	// MaxSize is the maximum size of the cache in bytes.
+	// If exceeded, entries are evicted according to the eviction policy.
+	// A value of 0 disables the size limit.
+	MaxSize int64 `json:"maxSize,omitempty"`
+	// Eviction is the eviction policy (default lru).
+	Eviction EvictionPolicy `json:"eviction,omitempty"`
+	// Verify enables the verification of the blob digest on read.
+	Verify bool `json:"verify,omitempty"`
+}
+
+func (o *CacheOptions) Validate() error {
+	if o.MaxSize < 0 {
+		return errors.ErrInvalid("cache size", strconv.FormatInt(o.MaxSize, 10))
+	}
+	switch o.Eviction {
+	case "", EVICT_LRU, EVICT_LFU:
+	default:
+		return errors.ErrInvalid("eviction policy", string(o.Eviction))
+	}
+	return nil
+}
+
+// CacheStatistics describes the usage of a managed cache.
+type CacheStatistics struct {
+	Hits          int64 `json:"hits"`
+	Misses        int64 `json:"misses"`
+	Additions     int64 `json:"additions"`
+	Evictions     int64 `json:"evictions"`
+	Corrupted     int64 `json:"corrupted"`
+	EvictedSize   int64 `json:"evictedSize"`
+	Entries       int   `json:"-"`
+	Size          int64 `json:"-"`
+	LastResetTime int64 `json:"lastReset,omitempty"`
+}
+
+// HitRate returns the ratio of cache hits to all lookups.
+func (s *CacheStatistics) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// StatisticsCache can be implemented by caches keeping usage statistics.
+type StatisticsCache interface {
+	Statistics() (*CacheStatistics, error)
+	ResetStatistics() error
+}
+
+// EvictingCache can be implemented by caches supporting size based eviction.
+type EvictingCache interface {
+	// Evict removes entries according to the eviction policy of the cache
+	// until the cache size does not exceed the given size.
+	// It returns the number and size of the evicted entries.
+	Evict(p common.Printer, size int64, dryrun bool) (cnt int, evicted int64, err error)
+}
+
+var (
+	_ StatisticsCache = (*blobCache)(nil)
+	_ EvictingCache   = (*blobCache)(nil)
+	_ CleanupCache    = (*blobCache)(nil)
+)
+
+func isCacheEntry(fi os.FileInfo) bool {
+	n := fi.Name()
+	return !fi.IsDir() && !strings.HasPrefix(n, ".") && !strings.HasPrefix(n, "TMP") && !strings.HasSuffix(n, ACCESS_SUFFIX)
+}
+
+// withFileLock executes the given function under the filesystem lock
+// of the cache, which synchronizes multiple processes using the same cache.
+func (c *blobCache) withFileLock(f func() error) error {
+	if c.flock == nil {
+		return f()
+	}
+	l, err := c.flock.Lock()
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	return f()
+}
+
+// touch records an access for an entry. The modification time of the
+// access file is used as last access time and for managed caches
+// the file content is used as access counter.
+func (c *blobCache) touch(path string) {
+	if c.opts == nil {
+		vfs.WriteFile(c.cache, path+ACCESS_SUFFIX, []byte{}, 0o600)
+		return
+	}
+	c.withFileLock(func() error {
+		cnt := c.accessCount(path)
+		return vfs.WriteFile(c.cache, path+ACCESS_SUFFIX, []byte(strconv.FormatInt(cnt+1, 10)), 0o600)
+	})
+}
+
+func (c *blobCache) accessCount(path string) int64 {
+	data, err := vfs.ReadFile(c.cache, path+ACCESS_SUFFIX)
+	if err != nil {
+		return 0
+	}
+	cnt, _ := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	return cnt
+}
+
+// verify checks the content of a cache entry against its digest.
+// Corrupted entries are removed and reported as not existing.
+func (c *blobCache) verify(d digest.Digest, path string) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	f, err := c.cache.Open(path)
+	if err != nil {
+		return err
+	}
+	actual, err := d.Algorithm().FromReader(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	if actual == d {
+		return nil
+	}
+	c.withFileLock(func() error {
+		c.cache.Remove(path)
+		c.cache.Remove(path + ACCESS_SUFFIX)
+		return nil
+	})
+	c.updateStatistics(func(s *CacheStatistics) { s.Corrupted++ })
+	return os.ErrNotExist
+}
+
+func (c *blobCache) readStatistics() (*CacheStatistics, error) {
+	var stats CacheStatistics
+	data, err := vfs.ReadFile(c.cache, CACHE_STATISTICS)
+	if err != nil {
+		if vfs.IsErrNotExist(err) {
+			return &stats, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		// start from scratch for corrupted statistics
+		return &CacheStatistics{}, nil
+	}
+	return &stats, nil
+}
+
+func (c *blobCache) writeStatistics(stats *CacheStatistics) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	return vfs.WriteFile(c.cache, CACHE_STATISTICS, data, 0o600)
+}
+
+// updateStatistics updates the persisted usage statistics of a managed
+// cache. Failures are ignored, statistics must never break the cache usage.
+func (c *blobCache) updateStatistics(f func(s *CacheStatistics)) {
+	if c.opts == nil {
+		return
+	}
+	c.statlock.Lock()
+	defer c.statlock.Unlock()
+	c.withFileLock(func() error {
+		stats, err := c.readStatistics()
+		if err != nil {
+			return err
+		}
+		f(stats)
+		return c.writeStatistics(stats)
+	})
+}
+
+func (c *blobCache) Statistics() (*CacheStatistics, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var stats *CacheStatistics
+	err := c.withFileLock(func() error {
+		var err error
+		stats, err = c.readStatistics()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		stats.Entries++
+		stats.Size += e.size
+	}
+	return stats, nil
+}
+
+func (c *blobCache) ResetStatistics() error {
+	c.statlock.Lock()
+	defer c.statlock.Unlock()
+	return c.withFileLock(func() error {
+		return c.writeStatistics(&CacheStatistics{LastResetTime: time.Now().Unix()})
+	})
+}
+
+type cacheEntry struct {
+	name   string
+	size   int64
+	access time.Time
+	count  int64
+}
+
+func (c *blobCache) entries() ([]*cacheEntry, error) {
+	list, err := vfs.ReadDir(c.cache, vfs.PathSeparatorString)
+	if err != nil {
+		return nil, err
+	}
+	var result []*cacheEntry
+	for _, fi := range list {
+		if !isCacheEntry(fi) {
+			continue
+		}
+		e := &cacheEntry{
+			name:   fi.Name(),
+			size:   fi.Size(),
+			access: fi.ModTime(),
+		}
+		if afi, err := c.cache.Stat(fi.Name() + ACCESS_SUFFIX); err == nil {
+			e.access = afi.ModTime()
+		}
+		e.count = c.accessCount(fi.Name())
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func (c *blobCache) Evict(p common.Printer, size int64, dryrun bool) (int, int64, error) {
+	if size < 0 {
+		return 0, 0, errors.ErrInvalid("cache size", strconv.FormatInt(size, 10))
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.evict(p, size, dryrun)
+}
+
+// evict removes entries until the cache size does not exceed the given
+// size. Entries listed in keep are never evicted.
+func (c *blobCache) evict(p common.Printer, size int64, dryrun bool, keep ...string) (int, int64, error) {
+	var cnt int
+	var evicted int64
+
+	err := c.withFileLock(func() error {
+		entries, err := c.entries()
+		if err != nil {
+			return err
+		}
+		var total int64
+		for _, e := range entries {
+			total += e.size
+		}
+		if total <= size {
+			return nil
+		}
+
+		policy := EVICT_LRU
+		if c.opts != nil && c.opts.Eviction != "" {
+			policy = c.opts.Eviction
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			if policy == EVICT_LFU && entries[i].count != entries[j].count {
+				return entries[i].count < entries[j].count
+			}
+			return entries[i].access.Before(entries[j].access)
+		})
+
+		for _, e := range entries {
+			if total <= size {
+				break
+			}
+			if contains(keep, e.name) {
+				continue
+			}
+			if p != nil {
+				p.Printf("evicting %s [%d bytes]\n", e.name, e.size)
+			}
+			if !dryrun {
+				if err := c.cache.Remove(e.name); err != nil {
+					return errors.Wrapf(err, "cannot evict %s", e.name)
+				}
+				c.cache.Remove(e.name + ACCESS_SUFFIX)
+			}
+			cnt++
+			evicted += e.size
+			total -= e.size
+		}
+		return nil
+	})
+	if err == nil && !dryrun && cnt > 0 {
+		c.updateStatistics(func(s *CacheStatistics) {
+			s.Evictions += int64(cnt)
+			s.EvictedSize += evicted
+		})
+	}
+	return cnt, evicted, err
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -10,14 +10,22 @@ import (
 	"ocm.software/ocm/api/utils/accessio/downloader"
 )
 
-// Downloader simply uses the default HTTP client to download the contents of a URL.
+// Downloader simply uses an HTTP client to download the contents of a URL.
 type Downloader struct {
-	link string
+	link   string
+	client *http.Client
 }
 
+// NewDownloader provides a downloader using the default HTTP client.
 func NewDownloader(link string) downloader.Downloader {
+	return NewDownloaderWithClient(link, http.DefaultClient)
+}
+
+// NewDownloaderWithClient provides a downloader using the given HTTP client.
+func NewDownloaderWithClient(link string, client *http.Client) downloader.Downloader {
 	return &Downloader{
-		link: link,
+		link:   link,
+		client: client,
 	}
 }
 
@@ -27,7 +35,7 @@ func (h *Downloader) Download(w io.WriterAt) error {
 		return err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := h.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to get link: %w", err)
 	}
@@ -0,0 +1,136 @@
+package accessio_test
+
+import (
+	"os"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/vfs/pkg/osfs"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	"github.com/opencontainers/go-digest"
+
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+var _ = Describe("managed cache", func() {
+	var tempfs vfs.FileSystem
+
+	BeforeEach(func() {
+		tempfs = Must(osfs.NewTempFileSystem())
+	})
+
+	AfterEach(func() {
+		vfs.Cleanup(tempfs)
+	})
+
+	add := func(cache accessio.BlobCache, data string) digest.Digest {
+		_, d := Must2(cache.AddData(blobaccess.DataAccessForData([]byte(data))))
+		return d
+	}
+
+	exists := func(d digest.Digest) bool {
+		return Must(vfs.FileExists(tempfs, "cache/"+common.DigestToFileName(d)))
+	}
+
+	It("keeps statistics", func() {
+		cache := Must(accessio.NewManagedBlobCache("cache", nil, tempfs))
+		defer cache.Unref()
+
+		d := add(cache, "testdata")
+		_, data := Must2(cache.GetBlobData(d))
+		Expect(data.Get()).To(Equal([]byte("testdata")))
+		_, _, err := cache.GetBlobData(digest.FromString("other"))
+		Expect(blobaccess.IsErrBlobNotFound(err)).To(BeTrue())
+
+		stats := Must(cache.(accessio.StatisticsCache).Statistics())
+		Expect(stats.Hits).To(Equal(int64(1)))
+		Expect(stats.Misses).To(Equal(int64(1)))
+		Expect(stats.Additions).To(Equal(int64(1)))
+		Expect(stats.Entries).To(Equal(1))
+		Expect(stats.Size).To(Equal(int64(8)))
+		Expect(stats.HitRate()).To(Equal(0.5))
+
+		// statistics are shared among cache instances
+		other := Must(accessio.NewManagedBlobCache("cache", nil, tempfs))
+		defer other.Unref()
+		Must2(other.GetBlobData(d))
+		stats = Must(cache.(accessio.StatisticsCache).Statistics())
+		Expect(stats.Hits).To(Equal(int64(2)))
+
+		MustBeSuccessful(cache.(accessio.StatisticsCache).ResetStatistics())
+		stats = Must(cache.(accessio.StatisticsCache).Statistics())
+		Expect(stats.Hits).To(Equal(int64(0)))
+		Expect(stats.Entries).To(Equal(1))
+	})
+
+	It("evicts least recently used entries", func() {
+		cache := Must(accessio.NewManagedBlobCache("cache", &accessio.CacheOptions{MaxSize: 20}, tempfs))
+		defer cache.Unref()
+
+		d1 := add(cache, "blob-one")
+		d2 := add(cache, "blob-two")
+		Must2(cache.GetBlobData(d1))
+		d3 := add(cache, "blob-3rd")
+
+		Expect(exists(d1)).To(BeTrue())
+		Expect(exists(d2)).To(BeFalse())
+		Expect(exists(d3)).To(BeTrue())
+
+		stats := Must(cache.(accessio.StatisticsCache).Statistics())
+		Expect(stats.Evictions).To(Equal(int64(1)))
+		Expect(stats.EvictedSize).To(Equal(int64(8)))
+	})
+
+	It("evicts least frequently used entries", func() {
+		cache := Must(accessio.NewManagedBlobCache("cache", &accessio.CacheOptions{Eviction: accessio.EVICT_LFU}, tempfs))
+		defer cache.Unref()
+
+		d1 := add(cache, "blob-one")
+		d2 := add(cache, "blob-two")
+		Must2(cache.GetBlobData(d1))
+		Must2(cache.GetBlobData(d2))
+		Must2(cache.GetBlobData(d2))
+
+		cnt, size := Must2(cache.(accessio.EvictingCache).Evict(nil, 8, true))
+		Expect(cnt).To(Equal(1))
+		Expect(size).To(Equal(int64(8)))
+		Expect(exists(d1)).To(BeTrue())
+
+		Must2(cache.(accessio.EvictingCache).Evict(nil, 8, false))
+		Expect(exists(d1)).To(BeFalse())
+		Expect(exists(d2)).To(BeTrue())
+	})
+
+	It("verifies content", func() {
+		cache := Must(accessio.NewManagedBlobCache("cache", &accessio.CacheOptions{Verify: true}, tempfs))
+		defer cache.Unref()
+
+		d := add(cache, "testdata")
+		MustBeSuccessful(vfs.WriteFile(tempfs, "cache/"+common.DigestToFileName(d), []byte("corrupted"), 0o600))
+
+		_, _, err := cache.GetBlobData(d)
+		Expect(blobaccess.IsErrBlobNotFound(err)).To(BeTrue())
+		Expect(exists(d)).To(BeFalse())
+
+		stats := Must(cache.(accessio.StatisticsCache).Statistics())
+		Expect(stats.Corrupted).To(Equal(int64(1)))
+	})
+
+	It("synchronizes processes on the OS filesystem", func() {
+		dir := Must(os.MkdirTemp("", "cache"))
+		defer os.RemoveAll(dir)
+
+		cache := Must(accessio.NewManagedBlobCache(dir, nil))
+		defer cache.Unref()
+
+		d := add(cache, "testdata")
+		Must2(cache.GetBlobData(d))
+		Expect(vfs.FileExists(osfs.OsFs, dir+"/.lock")).To(BeTrue())
+		stats := Must(cache.(accessio.StatisticsCache).Statistics())
+		Expect(stats.Hits).To(Equal(int64(1)))
+	})
+})
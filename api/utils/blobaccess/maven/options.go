@@ -6,9 +6,11 @@ import (
 
 	"ocm.software/ocm/api/credentials"
 	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/datacontext/attrs/retryattr"
 	"ocm.software/ocm/api/datacontext/attrs/tmpcache"
 	"ocm.software/ocm/api/tech/maven"
 	ocmlog "ocm.software/ocm/api/utils/logging"
+	"ocm.software/ocm/api/utils/retry"
 	"ocm.software/ocm/api/utils/stdopts"
 )
 
@@ -73,6 +75,9 @@ func (o *Options) ApplyTo(opts *Options) {
 	if o.Credentials.Value != nil {
 		opts.Credentials = o.Credentials
 	}
+	if o.RetryPolicy.Value != nil {
+		opts.RetryPolicy = o.RetryPolicy
+	}
 	if o.Classifier != nil {
 		opts.Classifier = o.Classifier
 	}
@@ -120,6 +125,12 @@ func WithCredentials(c credentials.Credentials) Option {
 	})
 }
 
+func WithRetryPolicy(p *retry.Policy) Option {
+	return OptionFunc(func(opts *Options) {
+		opts.SetRetryPolicy(p)
+	})
+}
+
 // //////////////////////////////////////////////////////////////////////////////
 
 type ClassifierOptionBag interface {
@@ -196,4 +207,5 @@ func (o *Options) SetDataContext(ctx datacontext.Context) {
 		o.SetCredentialContext(c.CredentialsContext())
 	}
 	o.SetCachingContext(ctx)
+	o.SetRetryPolicy(retryattr.Get(ctx))
 }
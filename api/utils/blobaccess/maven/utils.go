@@ -2,6 +2,7 @@ package maven
 
 import (
 	"io"
+	"net/http"
 
 	"github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/goutils/finalizer"
@@ -11,6 +12,7 @@ import (
 	"github.com/opencontainers/go-digest"
 
 	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/datacontext/attrs/retryattr"
 	"ocm.software/ocm/api/tech/maven"
 	"ocm.software/ocm/api/tech/maven/identity"
 	"ocm.software/ocm/api/utils/accessio"
@@ -20,6 +22,7 @@ import (
 	"ocm.software/ocm/api/utils/blobaccess/file"
 	"ocm.software/ocm/api/utils/iotools"
 	"ocm.software/ocm/api/utils/mime"
+	"ocm.software/ocm/api/utils/retry"
 	"ocm.software/ocm/api/utils/tarutils"
 )
 
@@ -36,6 +39,7 @@ func (s *spec) getBlobAccess() (_ bpi.BlobAccess, rerr error) {
 	defer finalize.FinalizeWithErrorPropagation(&rerr)
 
 	log := s.options.Logger("RepoUrl", s.repo.String())
+	s.repo = s.repo.WithHTTPClient(&http.Client{Transport: retryattr.Observe(s.options.CredentialContext.Value, retry.NewTransport(nil, s.options.GetRetryPolicy(), log))})
 	creds, err := s.options.GetCredentials(s.repo, s.GroupId)
 	if err != nil {
 		return nil, err
@@ -13,12 +13,14 @@ import (
 	"github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/vfs/pkg/vfs"
 
+	"ocm.software/ocm/api/datacontext/attrs/retryattr"
 	"ocm.software/ocm/api/tech/npm"
 	"ocm.software/ocm/api/utils/accessio"
 	"ocm.software/ocm/api/utils/accessobj"
 	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
 	"ocm.software/ocm/api/utils/iotools"
 	"ocm.software/ocm/api/utils/mime"
+	"ocm.software/ocm/api/utils/retry"
 )
 
 type PackageSpec struct {
@@ -160,7 +162,7 @@ func (a *PackageSpec) reader(tar ...string) (io.ReadCloser, error) {
 	if err != nil {
 		return nil, err
 	}
-	c := &http.Client{}
+	c := &http.Client{Transport: retryattr.Observe(a.options.CredentialContext.Value, retry.NewTransport(nil, a.options.GetRetryPolicy(), log))}
 	resp, err := c.Do(req)
 	if err != nil {
 		return nil, err
@@ -8,10 +8,12 @@ import (
 	"ocm.software/ocm/api/credentials"
 	"ocm.software/ocm/api/credentials/cpi"
 	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/datacontext/attrs/retryattr"
 	"ocm.software/ocm/api/datacontext/attrs/vfsattr"
 	"ocm.software/ocm/api/tech/npm"
 	"ocm.software/ocm/api/tech/npm/identity"
 	ocmlog "ocm.software/ocm/api/utils/logging"
+	"ocm.software/ocm/api/utils/retry"
 	"ocm.software/ocm/api/utils/stdopts"
 )
 
@@ -78,6 +80,9 @@ func (o *Options) ApplyTo(opts *Options) {
 	if o.Credentials.Value != nil {
 		opts.Credentials = o.Credentials
 	}
+	if o.RetryPolicy.Value != nil {
+		opts.RetryPolicy = o.RetryPolicy
+	}
 	if o.PathFileSystem.Value != nil {
 		opts.PathFileSystem = o.PathFileSystem
 	}
@@ -122,6 +127,12 @@ func WithCredentials(c credentials.Credentials) Option {
 	})
 }
 
+func WithRetryPolicy(p *retry.Policy) Option {
+	return OptionFunc(func(opts *Options) {
+		opts.SetRetryPolicy(p)
+	})
+}
+
 func WithPathFileSystem(fs vfs.FileSystem) Option {
 	return OptionFunc(func(opts *Options) {
 		opts.SetPathFileSystem(fs)
@@ -137,6 +148,7 @@ func (o *Options) SetDataContext(ctx datacontext.Context) {
 	}
 	o.SetPathFileSystem(vfsattr.Get(ctx.AttributesContext()))
 	o.SetCachingContext(ctx.AttributesContext())
+	o.SetRetryPolicy(retryattr.Get(ctx))
 }
 
 var _ stdopts.DataContextOptionBag = (*Options)(nil)
@@ -12,6 +12,7 @@ import (
 	"github.com/mandelsoft/goutils/optionutils"
 
 	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/datacontext/attrs/retryattr"
 	"ocm.software/ocm/api/ocm/cpi"
 	"ocm.software/ocm/api/tech/wget/identity"
 	"ocm.software/ocm/api/utils"
@@ -19,6 +20,7 @@ import (
 	"ocm.software/ocm/api/utils/blobaccess/bpi"
 	"ocm.software/ocm/api/utils/blobaccess/file"
 	ocmmime "ocm.software/ocm/api/utils/mime"
+	"ocm.software/ocm/api/utils/retry"
 )
 
 const (
@@ -73,7 +75,7 @@ func BlobAccess(url string, opts ...Option) (_ bpi.BlobAccess, rerr error) {
 
 	client := &http.Client{
 		CheckRedirect: redirectFunc,
-		Transport:     transport,
+		Transport:     retryattr.Observe(eff.CredentialContext, retry.NewTransport(transport, eff.GetRetryPolicy(), log)),
 	}
 
 	if eff.Verb == "" {
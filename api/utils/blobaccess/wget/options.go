@@ -8,9 +8,11 @@ import (
 	"github.com/mandelsoft/logging"
 
 	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/datacontext/attrs/retryattr"
 	"ocm.software/ocm/api/tech/wget/identity"
 	"ocm.software/ocm/api/utils"
 	ocmlog "ocm.software/ocm/api/utils/logging"
+	"ocm.software/ocm/api/utils/retry"
 )
 
 type Option = optionutils.Option[*Options]
@@ -30,6 +32,20 @@ type Options struct {
 	MimeType string
 	// Credentials allows to pass credentials and certificates for the http communication
 	Credentials credentials.Credentials
+	// RetryPolicy is the policy used to retry transient failures
+	RetryPolicy *retry.Policy
+}
+
+// GetRetryPolicy provides the retry policy for the http communication. If not
+// set explicitly, the policy configured for the credential context is used.
+func (o *Options) GetRetryPolicy() *retry.Policy {
+	if o.RetryPolicy != nil {
+		return o.RetryPolicy.Complete()
+	}
+	if o.CredentialContext == nil {
+		return retry.DefaultPolicy()
+	}
+	return retryattr.Get(o.CredentialContext)
 }
 
 func (o *Options) Logger(keyValuePairs ...interface{}) logging.Logger {
@@ -79,6 +95,9 @@ func (o *Options) ApplyTo(opts *Options) {
 	if o.NoRedirect != nil {
 		opts.NoRedirect = o.NoRedirect
 	}
+	if o.RetryPolicy != nil {
+		opts.RetryPolicy = o.RetryPolicy
+	}
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -186,3 +205,17 @@ func (o noredirect) ApplyTo(opts *Options) {
 func WithNoRedirect(r ...bool) Option {
 	return noredirect(utils.OptionalDefaultedBool(true, r...))
 }
+
+////////////////////////////////////////////////////////////////////////////////
+
+type retryPolicy struct {
+	*retry.Policy
+}
+
+func (o retryPolicy) ApplyTo(opts *Options) {
+	opts.RetryPolicy = o.Policy
+}
+
+func WithRetryPolicy(p *retry.Policy) Option {
+	return retryPolicy{p}
+}
@@ -7,15 +7,29 @@ import (
 	"reflect"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"ocm.software/ocm/api/datacontext"
 	"ocm.software/ocm/api/ocm/extensions/attrs/maxworkersattr"
 )
 
+type workerKey struct{}
+
+// WorkerIndex returns the (1-based) index of the pool worker executing
+// a task started by RunInWorkerPool. For sequential execution, no
+// worker index is available.
+func WorkerIndex(ctx context.Context) (int, bool) {
+	idx, ok := ctx.Value(workerKey{}).(int)
+	return idx, ok
+}
+
 // RunInWorkerPool runs f(task) concurrently for each element in tasks,
 // using up to maxWorkers workers. It waits for all tasks to complete
-// and returns a joined error if any of them fail.
+// and returns a joined error if any of them fail. The errors are joined
+// in the order of the task input, independent of the order of execution.
+// The index of the worker executing a task is passed via the context
+// (see WorkerIndex).
 //
 // If workers are set to 0 or 1, instead of spawning a traditional worker pool
 // the tasks are run sequentially in order of task input. In this case, the
@@ -23,7 +37,8 @@ import (
 //
 // For multiple workers, the worker pool does NOT cancel remaining tasks on first failure;
 // all tasks run to completion. Context cancellation will stop idle
-// workers but not interrupt running ones.
+// workers but not interrupt running ones. If tasks are skipped because
+// of a cancellation, the context error is added to the returned error.
 func RunInWorkerPool[T any](
 	ctx context.Context,
 	data datacontext.Context,
@@ -69,44 +84,51 @@ func RunInWorkerPool[T any](
 
 	logger.Debug("starting worker pool")
 
-	taskCh := make(chan T, len(tasks))
-	errCh := make(chan error, len(tasks))
+	type indexedTask struct {
+		index int
+		task  T
+	}
+
+	taskCh := make(chan indexedTask, len(tasks))
+	// every task writes its own slot, only.
+	errs := make([]error, len(tasks))
+	var executed atomic.Int64
 
 	var wg sync.WaitGroup
 
 	// start worker pool
 	for i := uint(0); i < maxWorkers; i++ {
+		wctx := context.WithValue(ctx, workerKey{}, int(i)+1)
 		wg.Go(func() {
 			for {
 				select {
-				case <-ctx.Done():
+				case <-wctx.Done():
 					return
-				case task, ok := <-taskCh:
-					if !ok {
+				case t, ok := <-taskCh:
+					if !ok || wctx.Err() != nil {
 						return
 					}
-					if err := f(ctx, task); err != nil {
-						errCh <- err
-					}
+					executed.Add(1)
+					errs[t.index] = f(wctx, t.task)
 				}
 			}
 		})
 	}
 
 	// enqueue all tasks
-	for _, t := range tasks {
-		taskCh <- t
+	for i, t := range tasks {
+		taskCh <- indexedTask{i, t}
 	}
 
 	close(taskCh)
 	wg.Wait()
-	close(errCh)
 
-	var combined error
-	for err := range errCh {
-		combined = errors.Join(combined, err)
+	if executed.Load() < int64(len(tasks)) {
+		// workers stopped because of a cancelled context,
+		// remaining tasks have not been executed.
+		errs = append(errs, ctx.Err())
 	}
-	return combined
+	return errors.Join(errs...)
 }
 
 // getCaller returns the caller of the function that calls it.
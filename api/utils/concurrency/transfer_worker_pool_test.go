@@ -0,0 +1,82 @@
+package concurrency_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/ocm/extensions/attrs/maxworkersattr"
+	"ocm.software/ocm/api/utils/concurrency"
+)
+
+var _ = Describe("worker pool", func() {
+	var ctx datacontext.Context
+
+	BeforeEach(func() {
+		ctx = datacontext.New(nil)
+	})
+
+	It("runs tasks sequentially without worker index", func() {
+		var order []int
+		Expect(concurrency.RunInWorkerPool(context.Background(), ctx, []int{1, 2, 3}, func(ctx context.Context, t int) error {
+			_, ok := concurrency.WorkerIndex(ctx)
+			Expect(ok).To(BeFalse())
+			order = append(order, t)
+			return nil
+		})).To(Succeed())
+		Expect(order).To(Equal([]int{1, 2, 3}))
+	})
+
+	It("runs tasks on workers", func() {
+		Expect(maxworkersattr.Set(ctx, 3)).To(Succeed())
+
+		var lock sync.Mutex
+		workers := map[int]bool{}
+		done := map[int]bool{}
+		Expect(concurrency.RunInWorkerPool(context.Background(), ctx, []int{1, 2, 3, 4, 5, 6}, func(ctx context.Context, t int) error {
+			idx, ok := concurrency.WorkerIndex(ctx)
+			Expect(ok).To(BeTrue())
+			time.Sleep(10 * time.Millisecond)
+			lock.Lock()
+			defer lock.Unlock()
+			workers[idx] = true
+			done[t] = true
+			return nil
+		})).To(Succeed())
+		Expect(len(done)).To(Equal(6))
+		for idx := range workers {
+			Expect(idx).To(BeNumerically(">=", 1))
+			Expect(idx).To(BeNumerically("<=", 3))
+		}
+	})
+
+	It("aggregates errors in task order", func() {
+		Expect(maxworkersattr.Set(ctx, 4)).To(Succeed())
+
+		err := concurrency.RunInWorkerPool(context.Background(), ctx, []int{1, 2, 3, 4, 5}, func(ctx context.Context, t int) error {
+			// later tasks fail first
+			time.Sleep(time.Duration(10*(5-t)) * time.Millisecond)
+			if t%2 == 1 {
+				return fmt.Errorf("task %d failed", t)
+			}
+			return nil
+		})
+		Expect(err).To(MatchError("task 1 failed\ntask 3 failed\ntask 5 failed"))
+	})
+
+	It("reports cancellation of pending tasks", func() {
+		Expect(maxworkersattr.Set(ctx, 2)).To(Succeed())
+
+		cctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := concurrency.RunInWorkerPool(cctx, ctx, []int{1, 2, 3, 4, 5, 6, 7, 8}, func(ctx context.Context, t int) error {
+			return nil
+		})
+		Expect(err).To(MatchError(context.Canceled))
+	})
+})
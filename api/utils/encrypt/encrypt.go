@@ -79,6 +79,12 @@ func NewKey(t algorithm) ([]byte, error) {
 }
 
 func Encrypt(key []byte, data []byte) ([]byte, error) {
+	return EncryptWithData(key, data, nil)
+}
+
+// EncryptWithData encrypts data with AES-GCM authenticating the given
+// additional data, which must be given again for the decryption.
+func EncryptWithData(key []byte, data []byte, additional []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
@@ -94,10 +100,16 @@ func Encrypt(key []byte, data []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	return gcm.Seal(nonce, nonce, data, nil), nil
+	return gcm.Seal(nonce, nonce, data, additional), nil
 }
 
 func Decrypt(key []byte, cipherText []byte) ([]byte, error) {
+	return DecryptWithData(key, cipherText, nil)
+}
+
+// DecryptWithData decrypts data encrypted with EncryptWithData for the
+// given additional data.
+func DecryptWithData(key []byte, cipherText []byte, additional []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
@@ -109,7 +121,7 @@ func Decrypt(key []byte, cipherText []byte) ([]byte, error) {
 
 	nonce := cipherText[:gcm.NonceSize()]
 	cipherText = cipherText[gcm.NonceSize():]
-	return gcm.Open(nil, nonce, cipherText, nil)
+	return gcm.Open(nil, nonce, cipherText, additional)
 }
 
 func KeyFromPem(data []byte) ([]byte, error) {
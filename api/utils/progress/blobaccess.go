@@ -0,0 +1,89 @@
+package progress
+
+import (
+	"io"
+
+	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
+	"ocm.software/ocm/api/utils/blobaccess/bpi"
+)
+
+// BlobAccess provides a blob access reporting the bytes read from
+// the given blob.
+func BlobAccess(t *Tracker, blob blobaccess.BlobAccess) blobaccess.BlobAccess {
+	if t == nil || blob == nil {
+		return blob
+	}
+	return &blobAccess{blob, t}
+}
+
+type blobAccess struct {
+	blobaccess.BlobAccess
+	tracker *Tracker
+}
+
+func (b *blobAccess) Get() ([]byte, error) {
+	return blobGet(b.tracker, b.BlobAccess)
+}
+
+func (b *blobAccess) Reader() (io.ReadCloser, error) {
+	return blobReader(b.tracker, b.BlobAccess)
+}
+
+func (b *blobAccess) Dup() (blobaccess.BlobAccess, error) {
+	d, err := b.BlobAccess.Dup()
+	if err != nil {
+		return nil, err
+	}
+	return &blobAccess{d, b.tracker}, nil
+}
+
+// AnnotatedBlobAccess provides a blob access reporting the bytes read from
+// the given blob. Annotations of the original blob access are preserved,
+// so consumers are still able to access the original data source.
+func AnnotatedBlobAccess[T bpi.DataAccess](t *Tracker, blob blobaccess.AnnotatedBlobAccess[T]) blobaccess.AnnotatedBlobAccess[T] {
+	if t == nil || blob == nil {
+		return blob
+	}
+	return &annotatedBlobAccess[T]{blob, t}
+}
+
+type annotatedBlobAccess[T bpi.DataAccess] struct {
+	blobaccess.AnnotatedBlobAccess[T]
+	tracker *Tracker
+}
+
+func (b *annotatedBlobAccess[T]) Get() ([]byte, error) {
+	return blobGet(b.tracker, b.AnnotatedBlobAccess)
+}
+
+func (b *annotatedBlobAccess[T]) Reader() (io.ReadCloser, error) {
+	return blobReader(b.tracker, b.AnnotatedBlobAccess)
+}
+
+func (b *annotatedBlobAccess[T]) Dup() (blobaccess.BlobAccess, error) {
+	d, err := b.AnnotatedBlobAccess.Dup()
+	if err != nil {
+		return nil, err
+	}
+	// the duplicate of an annotated blob access keeps the annotation.
+	if a, ok := d.(blobaccess.AnnotatedBlobAccess[T]); ok {
+		return &annotatedBlobAccess[T]{a, b.tracker}, nil
+	}
+	return &blobAccess{d, b.tracker}, nil
+}
+
+func blobGet(t *Tracker, blob blobaccess.DataAccess) ([]byte, error) {
+	data, err := blob.Get()
+	if err == nil {
+		t.Bytes(int64(len(data)), int64(len(data)))
+	}
+	return data, err
+}
+
+func blobReader(t *Tracker, blob blobaccess.BlobAccess) (io.ReadCloser, error) {
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	return t.ReadCloser(r, blob.Size()), nil
+}
@@ -0,0 +1,260 @@
+// Package progress provides an event based progress reporting for
+// long-running operations, like transfers, downloads or signing.
+//
+// Operations report events for the elements they process (component
+// versions, resources, sources or blobs) to a Reporter. Handlers
+// can be subscribed to a reporter to observe the progress, for example
+// to render progress bars or structured event logs.
+// A nil Reporter or Tracker is valid and just discards all events,
+// so operations can report unconditionally.
+package progress
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// EventType describes the kind of progress event.
+type EventType string
+
+const (
+	// EVENT_STARTED is reported when the processing of an element starts.
+	EVENT_STARTED EventType = "started"
+	// EVENT_FINISHED is reported when the processing of an element is
+	// finished. If the processing failed, the event carries the error.
+	EVENT_FINISHED EventType = "finished"
+	// EVENT_BYTES reports the number of bytes processed so far for an element.
+	EVENT_BYTES EventType = "bytes"
+	// EVENT_RETRY is reported when an operation step is retried.
+	EVENT_RETRY EventType = "retry"
+)
+
+// Element kinds used by the standard operations.
+const (
+	KIND_COMPONENTVERSION = "componentversion"
+	KIND_RESOURCE         = "resource"
+	KIND_SOURCE           = "source"
+	KIND_BLOB             = "blob"
+	KIND_URL              = "url"
+)
+
+// Operations reporting progress.
+const (
+	OPERATION_TRANSFER = "transfer"
+	OPERATION_DOWNLOAD = "download"
+	OPERATION_SIGN     = "sign"
+	OPERATION_VERIFY   = "verify"
+	OPERATION_ADD      = "add"
+	OPERATION_REQUEST  = "request"
+)
+
+// Event describes a progress event for an element processed
+// by an operation.
+type Event struct {
+	Time      time.Time `json:"time"`
+	Type      EventType `json:"type"`
+	Operation string    `json:"operation"`
+	Kind      string    `json:"kind"`
+	// Component is the component version the element belongs to, if known.
+	Component string `json:"component,omitempty"`
+	Element   string `json:"element"`
+	// Bytes is the number of bytes processed so far.
+	Bytes int64 `json:"bytes,omitempty"`
+	// Total is the expected number of bytes, if known.
+	Total   int64  `json:"total,omitempty"`
+	Attempt int    `json:"attempt,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Handler is used to observe progress events.
+// Events may be reported concurrently.
+type Handler interface {
+	HandleProgressEvent(e *Event)
+}
+
+// HandlerFunc is a function based Handler.
+type HandlerFunc func(e *Event)
+
+func (f HandlerFunc) HandleProgressEvent(e *Event) {
+	f(e)
+}
+
+// Reporter dispatches progress events to subscribed handlers.
+type Reporter interface {
+	Report(e *Event)
+	// Subscribe adds a handler. The returned function
+	// removes the subscription again.
+	Subscribe(h Handler) func()
+}
+
+type reporter struct {
+	lock     sync.RWMutex
+	id       int
+	handlers map[int]Handler
+}
+
+// NewReporter provides a new Reporter without subscriptions.
+func NewReporter() Reporter {
+	return &reporter{handlers: map[int]Handler{}}
+}
+
+func (r *reporter) Report(e *Event) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for _, h := range r.handlers {
+		h.HandleProgressEvent(e)
+	}
+}
+
+func (r *reporter) Subscribe(h Handler) func() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.id++
+	id := r.id
+	r.handlers[id] = h
+	return func() {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		delete(r.handlers, id)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Tracker reports events for a dedicated element processed by an
+// operation. All methods can be called on a nil Tracker.
+type Tracker struct {
+	reporter  Reporter
+	operation string
+	kind      string
+	component string
+	element   string
+}
+
+// For provides a tracker for an element. If no reporter is given,
+// nil is returned.
+func For(r Reporter, operation, kind, component, element string) *Tracker {
+	if r == nil {
+		return nil
+	}
+	return &Tracker{
+		reporter:  r,
+		operation: operation,
+		kind:      kind,
+		component: component,
+		element:   element,
+	}
+}
+
+func (t *Tracker) report(e *Event) {
+	e.Time = time.Now()
+	e.Operation = t.operation
+	e.Kind = t.kind
+	e.Component = t.component
+	e.Element = t.element
+	t.reporter.Report(e)
+}
+
+// Started reports the start of the processing of the element.
+func (t *Tracker) Started() {
+	if t != nil {
+		t.report(&Event{Type: EVENT_STARTED})
+	}
+}
+
+// Finished reports the end of the processing of the element.
+func (t *Tracker) Finished(err error) {
+	if t != nil {
+		e := &Event{Type: EVENT_FINISHED}
+		if err != nil {
+			e.Error = err.Error()
+		}
+		t.report(e)
+	}
+}
+
+// Retry reports a retry of a processing step, attempt is the number
+// of the upcoming attempt.
+func (t *Tracker) Retry(attempt int, err error) {
+	if t != nil {
+		e := &Event{Type: EVENT_RETRY, Attempt: attempt}
+		if err != nil {
+			e.Error = err.Error()
+		}
+		t.report(e)
+	}
+}
+
+// Bytes reports the number of bytes processed so far. A total
+// less or equal to zero means unknown.
+func (t *Tracker) Bytes(n, total int64) {
+	if t != nil {
+		if total < 0 {
+			total = 0
+		}
+		t.report(&Event{Type: EVENT_BYTES, Bytes: n, Total: total})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ReportInterval is the minimal interval between two
+// bytes events reported for a reader.
+var ReportInterval = 100 * time.Millisecond
+
+type reader struct {
+	io.Reader
+	tracker *Tracker
+	total   int64
+	count   int64
+	last    time.Time
+	eof     bool
+}
+
+// Reader provides a reader reporting the number of bytes read.
+// The events are reported at most every ReportInterval and
+// when the end of the stream is reached.
+func (t *Tracker) Reader(r io.Reader, total int64) io.Reader {
+	if t == nil {
+		return r
+	}
+	return &reader{Reader: r, tracker: t, total: total}
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.count += int64(n)
+	if err == io.EOF {
+		if !r.eof {
+			r.eof = true
+			r.tracker.Bytes(r.count, r.total)
+		}
+	} else if n > 0 && time.Since(r.last) >= ReportInterval {
+		r.last = time.Now()
+		r.tracker.Bytes(r.count, r.total)
+	}
+	return n, err
+}
+
+type readCloser struct {
+	*reader
+	closer io.Closer
+}
+
+// ReadCloser provides a ReadCloser reporting the number of bytes read.
+func (t *Tracker) ReadCloser(r io.ReadCloser, total int64) io.ReadCloser {
+	if t == nil {
+		return r
+	}
+	return &readCloser{&reader{Reader: r, tracker: t, total: total}, r}
+}
+
+func (r *readCloser) Close() error {
+	if !r.eof && r.count > 0 {
+		// report the final state for partially consumed streams.
+		r.eof = true
+		r.tracker.Bytes(r.count, r.total)
+	}
+	return r.closer.Close()
+}
@@ -0,0 +1,182 @@
+package progress_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/datacontext/attrs/progressattr"
+	"ocm.software/ocm/api/utils/blobaccess/blobaccess"
+	"ocm.software/ocm/api/utils/mime"
+	"ocm.software/ocm/api/utils/progress"
+)
+
+type recorder struct {
+	lock   sync.Mutex
+	events []progress.Event
+}
+
+func (r *recorder) HandleProgressEvent(e *progress.Event) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, *e)
+}
+
+func (r *recorder) Types() []progress.EventType {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var types []progress.EventType
+	for _, e := range r.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+var _ = Describe("progress", func() {
+	var rec *recorder
+	var rep progress.Reporter
+
+	BeforeEach(func() {
+		rec = &recorder{}
+		rep = progress.NewReporter()
+	})
+
+	It("tolerates nil trackers", func() {
+		t := progress.For(nil, progress.OPERATION_TRANSFER, progress.KIND_RESOURCE, "", "data")
+		Expect(t).To(BeNil())
+		t.Started()
+		t.Bytes(1, 1)
+		t.Retry(2, fmt.Errorf("failed"))
+		t.Finished(nil)
+		r := strings.NewReader("data")
+		Expect(t.Reader(r, 4)).To(BeIdenticalTo(r))
+	})
+
+	It("dispatches events to subscribed handlers", func() {
+		unsubscribe := rep.Subscribe(rec)
+		t := progress.For(rep, progress.OPERATION_TRANSFER, progress.KIND_RESOURCE, "acme.org/test:v1", "data")
+		t.Started()
+		t.Retry(2, fmt.Errorf("failed"))
+		t.Finished(fmt.Errorf("failed again"))
+		unsubscribe()
+		t.Started()
+
+		Expect(rec.Types()).To(Equal([]progress.EventType{progress.EVENT_STARTED, progress.EVENT_RETRY, progress.EVENT_FINISHED}))
+		Expect(rec.events[1].Attempt).To(Equal(2))
+		Expect(rec.events[1].Error).To(Equal("failed"))
+		Expect(rec.events[2].Error).To(Equal("failed again"))
+		for _, e := range rec.events {
+			Expect(e.Operation).To(Equal(progress.OPERATION_TRANSFER))
+			Expect(e.Kind).To(Equal(progress.KIND_RESOURCE))
+			Expect(e.Component).To(Equal("acme.org/test:v1"))
+			Expect(e.Element).To(Equal("data"))
+		}
+	})
+
+	It("reports bytes read", func() {
+		rep.Subscribe(rec)
+		t := progress.For(rep, progress.OPERATION_DOWNLOAD, progress.KIND_RESOURCE, "", "data")
+		data := Must(io.ReadAll(t.Reader(strings.NewReader("some test data"), 14)))
+		Expect(string(data)).To(Equal("some test data"))
+
+		last := rec.events[len(rec.events)-1]
+		Expect(last.Type).To(Equal(progress.EVENT_BYTES))
+		Expect(last.Bytes).To(Equal(int64(14)))
+		Expect(last.Total).To(Equal(int64(14)))
+	})
+
+	It("reports partially read streams on close", func() {
+		rep.Subscribe(rec)
+		t := progress.For(rep, progress.OPERATION_DOWNLOAD, progress.KIND_RESOURCE, "", "data")
+		r := t.ReadCloser(io.NopCloser(strings.NewReader("some test data")), 14)
+		buf := make([]byte, 4)
+		Must(io.ReadFull(r, buf))
+		MustBeSuccessful(r.Close())
+
+		last := rec.events[len(rec.events)-1]
+		Expect(last.Bytes).To(Equal(int64(4)))
+	})
+
+	It("reports bytes for blob accesses", func() {
+		rep.Subscribe(rec)
+		t := progress.For(rep, progress.OPERATION_ADD, progress.KIND_BLOB, "", "data")
+		blob := progress.BlobAccess(t, blobaccess.ForString(mime.MIME_TEXT, "some test data"))
+		defer Close(blob)
+
+		dup := Must(blob.Dup())
+		Expect(string(Must(dup.Get()))).To(Equal("some test data"))
+		MustBeSuccessful(dup.Close())
+		Expect(rec.Types()).To(Equal([]progress.EventType{progress.EVENT_BYTES}))
+		Expect(rec.events[0].Bytes).To(Equal(int64(14)))
+	})
+
+	It("reports blob size for blob readers", func() {
+		rep.Subscribe(rec)
+		t := progress.For(rep, progress.OPERATION_ADD, progress.KIND_BLOB, "", "data")
+		blob := progress.BlobAccess(t, blobaccess.ForString(mime.MIME_TEXT, "some test data"))
+		defer Close(blob)
+
+		r := Must(blob.Reader())
+		Expect(string(Must(io.ReadAll(r)))).To(Equal("some test data"))
+		MustBeSuccessful(r.Close())
+		Expect(rec.events).NotTo(BeEmpty())
+		last := rec.events[len(rec.events)-1]
+		Expect(last.Bytes).To(Equal(int64(14)))
+		Expect(last.Total).To(Equal(int64(14)))
+	})
+
+	It("subscribes via context attribute", func() {
+		ctx := datacontext.New(nil)
+		Expect(progressattr.For(ctx, progress.OPERATION_SIGN, progress.KIND_COMPONENTVERSION, "", "acme.org/test:v1")).To(BeNil())
+
+		unsubscribe := progressattr.Subscribe(ctx, rec)
+		defer unsubscribe()
+		progressattr.For(ctx, progress.OPERATION_SIGN, progress.KIND_COMPONENTVERSION, "", "acme.org/test:v1").Started()
+		Expect(rec.Types()).To(Equal([]progress.EventType{progress.EVENT_STARTED}))
+	})
+
+	Context("rendering", func() {
+		It("renders json lines", func() {
+			buf := &bytes.Buffer{}
+			rep.Subscribe(Must(progress.NewHandler(progress.MODE_AUTO, buf)))
+			t := progress.For(rep, progress.OPERATION_TRANSFER, progress.KIND_RESOURCE, "acme.org/test:v1", "data")
+			t.Started()
+			t.Finished(nil)
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			Expect(len(lines)).To(Equal(2))
+			var e progress.Event
+			MustBeSuccessful(json.Unmarshal([]byte(lines[1]), &e))
+			Expect(e.Type).To(Equal(progress.EVENT_FINISHED))
+			Expect(e.Component).To(Equal("acme.org/test:v1"))
+			Expect(e.Element).To(Equal("data"))
+		})
+
+		It("renders bars", func() {
+			buf := &bytes.Buffer{}
+			rep.Subscribe(Must(progress.NewHandler(progress.MODE_BAR, buf)))
+			progress.For(rep, progress.OPERATION_TRANSFER, progress.KIND_COMPONENTVERSION, "", "acme.org/test:v1").Started()
+			t := progress.For(rep, progress.OPERATION_TRANSFER, progress.KIND_RESOURCE, "acme.org/test:v1", "data")
+			t.Bytes(1024, 2048)
+			t.Retry(2, fmt.Errorf("timeout"))
+			progress.For(rep, progress.OPERATION_TRANSFER, progress.KIND_COMPONENTVERSION, "", "acme.org/test:v1").Finished(nil)
+
+			Expect(buf.String()).To(Equal("transfer componentversion acme.org/test:v1 started\n" +
+				"\r\033[Ktransfer resource data (acme.org/test:v1) [===============               ] 1.0 KiB/2.0 KiB" +
+				"\r\033[Ktransfer resource data (acme.org/test:v1): retry 2: timeout\n" +
+				"transfer componentversion acme.org/test:v1 finished\n"))
+		})
+
+		It("rejects unknown modes", func() {
+			ExpectError(progress.NewHandler("fancy", &bytes.Buffer{})).To(MatchError(`progress mode "fancy" is invalid`))
+		})
+	})
+})
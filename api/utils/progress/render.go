@@ -0,0 +1,157 @@
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+)
+
+// Rendering modes supported by NewHandler.
+const (
+	// MODE_AUTO renders progress bars on a terminal and JSON lines otherwise.
+	MODE_AUTO = "auto"
+	MODE_BAR  = "bar"
+	MODE_JSON = "json"
+)
+
+// NewHandler provides a rendering handler for the given mode.
+func NewHandler(mode string, w io.Writer) (Handler, error) {
+	switch mode {
+	case MODE_AUTO:
+		if IsTerminal(w) {
+			return NewBarHandler(w), nil
+		}
+		return NewJSONHandler(w), nil
+	case MODE_BAR:
+		return NewBarHandler(w), nil
+	case MODE_JSON:
+		return NewJSONHandler(w), nil
+	default:
+		return nil, errors.ErrInvalid("progress mode", mode)
+	}
+}
+
+// IsTerminal checks whether the writer is a terminal.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// NewJSONHandler provides a handler writing every event as a single
+// JSON document per line to the given writer.
+func NewJSONHandler(w io.Writer) Handler {
+	return &jsonHandler{encoder: json.NewEncoder(w)}
+}
+
+type jsonHandler struct {
+	lock    sync.Mutex
+	encoder *json.Encoder
+}
+
+func (h *jsonHandler) HandleProgressEvent(e *Event) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.encoder.Encode(e)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// BarWidth is the width of the bar rendered for elements with a known size.
+var BarWidth = 30
+
+// NewBarHandler provides a handler rendering the progress on a terminal.
+// Started and finished component versions, finished elements and retries
+// are reported as lines, the byte progress of the element processed
+// most recently is shown on a status line, which is updated in place.
+func NewBarHandler(w io.Writer) Handler {
+	return &barHandler{writer: w}
+}
+
+type barHandler struct {
+	lock   sync.Mutex
+	writer io.Writer
+	status bool
+	last   time.Time
+}
+
+func (h *barHandler) HandleProgressEvent(e *Event) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	switch e.Type {
+	case EVENT_BYTES:
+		if time.Since(h.last) < ReportInterval && (e.Total <= 0 || e.Bytes < e.Total) {
+			return
+		}
+		h.last = time.Now()
+		fmt.Fprintf(h.writer, "\r\033[K%s", Bar(e))
+		h.status = true
+	case EVENT_STARTED:
+		if e.Kind == KIND_COMPONENTVERSION {
+			h.println("%s %s started", e.Operation, Name(e))
+		}
+	case EVENT_FINISHED:
+		switch {
+		case e.Error != "":
+			h.println("%s %s failed: %s", e.Operation, Name(e), e.Error)
+		case e.Kind == KIND_COMPONENTVERSION:
+			h.println("%s %s finished", e.Operation, Name(e))
+		}
+	case EVENT_RETRY:
+		h.println("%s %s: retry %d: %s", e.Operation, Name(e), e.Attempt, e.Error)
+	}
+}
+
+func (h *barHandler) println(msg string, args ...interface{}) {
+	if h.status {
+		fmt.Fprint(h.writer, "\r\033[K")
+		h.status = false
+	}
+	fmt.Fprintf(h.writer, msg+"\n", args...)
+}
+
+// Name provides a printable name for the element of an event.
+func Name(e *Event) string {
+	if e.Component != "" && e.Kind != KIND_COMPONENTVERSION {
+		return fmt.Sprintf("%s %s (%s)", e.Kind, e.Element, e.Component)
+	}
+	return fmt.Sprintf("%s %s", e.Kind, e.Element)
+}
+
+// Bar renders the byte progress of an event.
+func Bar(e *Event) string {
+	if e.Total <= 0 {
+		return fmt.Sprintf("%s %s %s", e.Operation, Name(e), ByteSize(e.Bytes))
+	}
+	done := int(int64(BarWidth) * e.Bytes / e.Total)
+	if done > BarWidth {
+		done = BarWidth
+	}
+	return fmt.Sprintf("%s %s [%s%s] %s/%s", e.Operation, Name(e),
+		strings.Repeat("=", done), strings.Repeat(" ", BarWidth-done),
+		ByteSize(e.Bytes), ByteSize(e.Total))
+}
+
+// ByteSize provides a human-readable representation of a byte count.
+func ByteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,254 @@
+// Package retry provides a retry policy with exponential backoff for
+// transient failures of network based operations, and an HTTP transport
+// applying such a policy to requests.
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/mandelsoft/goutils/generics"
+)
+
+const (
+	DEFAULT_MAX_ATTEMPTS    = 5
+	DEFAULT_INITIAL_BACKOFF = 250 * time.Millisecond
+	DEFAULT_MAX_BACKOFF     = 10 * time.Second
+	DEFAULT_FACTOR          = 2
+	DEFAULT_JITTER          = 0.2
+)
+
+// Duration wraps time.Duration to support JSON/YAML marshaling
+// of both human-readable duration strings (e.g. "250ms", "5s")
+// and nanosecond numbers.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch value := v.(type) {
+	case float64:
+		*d = Duration(time.Duration(value))
+		return nil
+	case string:
+		tmp, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", value, err)
+		}
+		*d = Duration(tmp)
+		return nil
+	default:
+		return fmt.Errorf("duration must be a duration string or nanoseconds number, got %T", v)
+	}
+}
+
+// Policy describes how often and when failed operations are retried.
+// Fields not set are defaulted.
+type Policy struct {
+	// MaxAttempts is the maximum number of attempts including the
+	// initial one. A value of 1 disables retries.
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// InitialBackoff is the wait time before the first retry.
+	InitialBackoff Duration `json:"initialBackoff,omitempty"`
+	// MaxBackoff limits the wait time between two attempts.
+	MaxBackoff Duration `json:"maxBackoff,omitempty"`
+	// Factor is the multiplier applied to the backoff after every attempt.
+	Factor float64 `json:"factor,omitempty"`
+	// Jitter is the fraction (0..1) the backoff is randomly varied by.
+	// A value of 0 disables the variation.
+	Jitter *float64 `json:"jitter,omitempty"`
+}
+
+// DefaultPolicy provides the policy used if nothing else is configured.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		MaxAttempts:    DEFAULT_MAX_ATTEMPTS,
+		InitialBackoff: Duration(DEFAULT_INITIAL_BACKOFF),
+		MaxBackoff:     Duration(DEFAULT_MAX_BACKOFF),
+		Factor:         DEFAULT_FACTOR,
+		Jitter:         generics.Pointer(DEFAULT_JITTER),
+	}
+}
+
+// NoRetry provides a policy disabling retries.
+func NoRetry() *Policy {
+	p := DefaultPolicy()
+	p.MaxAttempts = 1
+	return p
+}
+
+// Complete provides a copy of the policy with all unset fields
+// replaced by their defaults.
+func (p *Policy) Complete() *Policy {
+	def := DefaultPolicy()
+	if p == nil {
+		return def
+	}
+	r := *p
+	if r.MaxAttempts <= 0 {
+		r.MaxAttempts = def.MaxAttempts
+	}
+	if r.InitialBackoff <= 0 {
+		r.InitialBackoff = def.InitialBackoff
+	}
+	if r.MaxBackoff <= 0 {
+		r.MaxBackoff = def.MaxBackoff
+	}
+	if r.Factor <= 0 {
+		r.Factor = def.Factor
+	}
+	if r.Jitter == nil {
+		r.Jitter = def.Jitter
+	}
+	return &r
+}
+
+// Validate checks the policy for consistency.
+func (p *Policy) Validate() error {
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("maxAttempts must not be negative")
+	}
+	if p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+		return fmt.Errorf("backoff must not be negative")
+	}
+	if p.Factor != 0 && p.Factor < 1 {
+		return fmt.Errorf("factor must be at least 1")
+	}
+	if p.Jitter != nil && (*p.Jitter < 0 || *p.Jitter > 1) {
+		return fmt.Errorf("jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+// Backoff provides the time to wait after the given (1-based) failed
+// attempt. The exponential backoff is varied by the configured jitter
+// and limited by the maximum backoff.
+func (p *Policy) Backoff(attempt int) time.Duration {
+	c := p.Complete()
+	d := float64(c.InitialBackoff) * math.Pow(c.Factor, float64(attempt-1))
+	if *c.Jitter > 0 {
+		d += d * *c.Jitter * (2*rand.Float64() - 1)
+	}
+	if d > float64(c.MaxBackoff) {
+		d = float64(c.MaxBackoff)
+	}
+	return time.Duration(d)
+}
+
+// Delay provides the time to wait after the given (1-based) failed
+// attempt with the given response. A delay requested by the server
+// with a Retry-After header is used instead of the backoff, but it is
+// limited by the maximum backoff, also.
+func (p *Policy) Delay(attempt int, resp *http.Response) time.Duration {
+	d, ok := RetryAfter(resp)
+	if !ok {
+		return p.Backoff(attempt)
+	}
+	if max := time.Duration(p.Complete().MaxBackoff); d > max {
+		return max
+	}
+	return d
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// IsRetryable checks whether the result of an HTTP request indicates
+// a transient failure: server errors (except 501), 408 and 429, as well
+// as timeouts and connection resets.
+func IsRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return IsTransientError(err)
+	}
+	if resp == nil {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	case http.StatusNotImplemented, http.StatusHTTPVersionNotSupported:
+		return false
+	}
+	return resp.StatusCode >= 500
+}
+
+// IsTransientError checks whether an error is caused by a
+// transient network failure.
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var nerr net.Error
+	return errors.As(err, &nerr) && nerr.Timeout()
+}
+
+// IsIdempotent checks whether a request may be repeated without
+// additional side effects. This holds for the idempotent HTTP methods and
+// for requests with an idempotency key header.
+func IsIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != ""
+}
+
+// RetryAfter evaluates the Retry-After header of a response. It supports
+// delays given in seconds and HTTP dates.
+func RetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if s < 0 {
+			return 0, false
+		}
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// Wait waits for the given duration or until the context is done.
+func Wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
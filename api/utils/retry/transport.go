@@ -0,0 +1,112 @@
+package retry
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/mandelsoft/logging"
+)
+
+// Transport is an http.RoundTripper retrying requests failing with
+// transient errors according to a Policy. Requests with a body are only
+// retried if the body can be recreated (see http.Request.GetBody).
+// Requests with non-idempotent methods (for example, POST) are only
+// retried if RetryNonIdempotent is set.
+// If OnRetry is set, it is called for every retry before waiting for the
+// next attempt, attempt is the number of the upcoming attempt.
+type Transport struct {
+	Base               http.RoundTripper
+	Policy             *Policy
+	Logger             logging.Logger
+	RetryNonIdempotent bool
+	OnRetry            func(req *http.Request, attempt int, cause error)
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// NewTransport provides a retrying transport for the given base transport.
+// If no base is given, http.DefaultTransport is used. Every retry is
+// logged with the given logger, if present.
+func NewTransport(base http.RoundTripper, p *Policy, logger logging.Logger) *Transport {
+	return &Transport{
+		Base:   base,
+		Policy: p.Complete(),
+		Logger: logger,
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := t.Policy.Complete()
+	ctx := req.Context()
+
+	cur := req
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base().RoundTrip(cur)
+		if attempt >= p.MaxAttempts || !IsRetryable(resp, err) || !t.repeatable(req) {
+			return resp, err
+		}
+
+		wait := p.Delay(attempt, resp)
+		if t.Logger != nil {
+			values := []interface{}{
+				"method", req.Method,
+				"url", RedactURL(req.URL),
+				"attempt", attempt + 1,
+				"maxAttempts", p.MaxAttempts,
+				"wait", wait.String(),
+			}
+			if err != nil {
+				values = append(values, "error", err.Error())
+			} else {
+				values = append(values, "status", resp.Status)
+			}
+			t.Logger.Warn("retrying request", values...)
+		}
+		if t.OnRetry != nil {
+			cause := err
+			if cause == nil {
+				cause = errors.New(resp.Status)
+			}
+			t.OnRetry(req, attempt+1, cause)
+		}
+		if resp != nil {
+			// drain the body to enable connection reuse
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		if err := Wait(ctx, wait); err != nil {
+			return nil, err
+		}
+
+		cur = req.Clone(ctx)
+		if req.Body != nil && req.Body != http.NoBody {
+			cur.Body, err = req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (t *Transport) repeatable(req *http.Request) bool {
+	if !t.RetryNonIdempotent && !IsIdempotent(req) {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// RedactURL provides the string representation of a URL
+// with a masked password.
+func RedactURL(u *url.URL) string {
+	return u.Redacted()
+}
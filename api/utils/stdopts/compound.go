@@ -4,9 +4,11 @@ import (
 	"github.com/mandelsoft/vfs/pkg/osfs"
 	"github.com/mandelsoft/vfs/pkg/vfs"
 
+	"ocm.software/ocm/api/datacontext/attrs/retryattr"
 	"ocm.software/ocm/api/datacontext/attrs/tmpcache"
 	"ocm.software/ocm/api/datacontext/attrs/vfsattr"
 	"ocm.software/ocm/api/utils"
+	"ocm.software/ocm/api/utils/retry"
 )
 
 type StandardContexts struct {
@@ -16,6 +18,7 @@ type StandardContexts struct {
 	CachingFileSystem
 	CachingPath
 	Credentials
+	RetryPolicy
 }
 
 func (o *StandardContexts) Cache() *tmpcache.Attribute {
@@ -42,3 +45,15 @@ func (o *StandardContexts) GetCachingFileSystem() vfs.FileSystem {
 	}
 	return osfs.OsFs
 }
+
+// GetRetryPolicy provides the retry policy for network access. If not
+// set explicitly, the policy configured for the credential context is used.
+func (o *StandardContexts) GetRetryPolicy() *retry.Policy {
+	if o.RetryPolicy.Value != nil {
+		return o.RetryPolicy.Value.Complete()
+	}
+	if o.CredentialContext.Value == nil {
+		return retry.DefaultPolicy()
+	}
+	return retryattr.Get(o.CredentialContext.Value)
+}
@@ -7,6 +7,7 @@ import (
 	"ocm.software/ocm/api/credentials"
 	"ocm.software/ocm/api/credentials/cpi"
 	"ocm.software/ocm/api/datacontext"
+	"ocm.software/ocm/api/utils/retry"
 )
 
 type DataContextOptionBag interface {
@@ -134,3 +135,19 @@ type CachingFileSystem struct {
 func (d *CachingFileSystem) SetCachingFileSystem(v vfs.FileSystem) {
 	d.Value = v
 }
+
+////////////////////////////////////////////////////////////////////////////////
+
+type RetryPolicyOptionBag interface {
+	SetRetryPolicy(p *retry.Policy)
+}
+
+var _ RetryPolicyOptionBag = (*RetryPolicy)(nil)
+
+type RetryPolicy struct {
+	Value *retry.Policy
+}
+
+func (d *RetryPolicy) SetRetryPolicy(p *retry.Policy) {
+	d.Value = p
+}
@@ -16,12 +16,14 @@ import (
 
 	clictx "ocm.software/ocm/api/cli"
 	"ocm.software/ocm/api/datacontext/attrs/clicfgattr"
+	"ocm.software/ocm/api/datacontext/attrs/progressattr"
 	"ocm.software/ocm/api/ocm/extensions/attrs/plugincacheattr"
 	"ocm.software/ocm/api/ocm/ocmutils/defaultconfigregistry"
 	"ocm.software/ocm/api/ocm/plugin/registration"
 	"ocm.software/ocm/api/utils/cobrautils"
 	"ocm.software/ocm/api/utils/cobrautils/logopts"
 	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/api/utils/progress"
 	"ocm.software/ocm/api/version"
 	config2 "ocm.software/ocm/cmds/ocm/clippi/config"
 	"ocm.software/ocm/cmds/ocm/commands/cachecmds"
@@ -46,15 +48,19 @@ import (
 	"ocm.software/ocm/cmds/ocm/commands/verbs/bootstrap"
 	"ocm.software/ocm/cmds/ocm/commands/verbs/check"
 	"ocm.software/ocm/cmds/ocm/commands/verbs/clean"
+	"ocm.software/ocm/cmds/ocm/commands/verbs/compact"
 	"ocm.software/ocm/cmds/ocm/commands/verbs/controller"
 	"ocm.software/ocm/cmds/ocm/commands/verbs/create"
 	"ocm.software/ocm/cmds/ocm/commands/verbs/describe"
+	"ocm.software/ocm/cmds/ocm/commands/verbs/diff"
 	"ocm.software/ocm/cmds/ocm/commands/verbs/download"
 	"ocm.software/ocm/cmds/ocm/commands/verbs/execute"
 	"ocm.software/ocm/cmds/ocm/commands/verbs/get"
 	"ocm.software/ocm/cmds/ocm/commands/verbs/hash"
 	"ocm.software/ocm/cmds/ocm/commands/verbs/install"
 	"ocm.software/ocm/cmds/ocm/commands/verbs/list"
+	"ocm.software/ocm/cmds/ocm/commands/verbs/resign"
+	"ocm.software/ocm/cmds/ocm/commands/verbs/rotate"
 	"ocm.software/ocm/cmds/ocm/commands/verbs/set"
 	"ocm.software/ocm/cmds/ocm/commands/verbs/show"
 	"ocm.software/ocm/cmds/ocm/commands/verbs/sign"
@@ -163,6 +169,14 @@ Often a tagged value can also be substituted from a file with the syntax
 <center>
 <code>&lt;attr>=@&lt;filepath></code>
 </center>
+
+With the option <code>--progress</code> the progress of long-running operations,
+like transfers, downloads, signing or adding component versions, is reported
+on the error output. The mode <code>bar</code> renders a progress line for
+the processed elements, the mode <code>json</code> writes a JSON document
+per progress event. The default mode <code>auto</code> uses progress bars
+if the error output is a terminal and JSON otherwise. Retries of network
+requests are reported as events of kind <code>url</code>.
 ` + keyoption.Usage()
 
 // NewCliCommandForArgs is the regular way to instantiate a new CLI command.
@@ -231,7 +245,9 @@ func newCliCommand(opts *CLIOptions, mod ...func(clictx.Context, *cobra.Command)
 	cmd.AddCommand(create.NewCommand(opts.Context))
 	cmd.AddCommand(add.NewCommand(opts.Context))
 	cmd.AddCommand(sign.NewCommand(opts.Context))
+	cmd.AddCommand(resign.NewCommand(opts.Context))
 	cmd.AddCommand(hash.NewCommand(opts.Context))
+	cmd.AddCommand(diff.NewCommand(opts.Context))
 	cmd.AddCommand(verify.NewCommand(opts.Context))
 	cmd.AddCommand(show.NewCommand(opts.Context))
 	cmd.AddCommand(transfer.NewCommand(opts.Context))
@@ -239,6 +255,8 @@ func newCliCommand(opts *CLIOptions, mod ...func(clictx.Context, *cobra.Command)
 	cmd.AddCommand(download.NewCommand(opts.Context))
 	cmd.AddCommand(bootstrap.NewCommand(opts.Context))
 	cmd.AddCommand(clean.NewCommand(opts.Context))
+	cmd.AddCommand(compact.NewCommand(opts.Context))
+	cmd.AddCommand(rotate.NewCommand(opts.Context))
 	cmd.AddCommand(install.NewCommand(opts.Context))
 	cmd.AddCommand(execute.NewCommand(opts.Context))
 	cmd.AddCommand(controller.NewCommand(opts.Context))
@@ -390,6 +408,15 @@ func (o *CLIOptions) Complete() error {
 	if err != nil {
 		return err
 	}
+	if o.Progress != "" {
+		h, err := progress.NewHandler(o.Progress, o.Context.StdErr())
+		if err != nil {
+			return err
+		}
+		// the reporter is attached to the credentials context, which is inherited
+		// by the OCM context, to observe network retries of blob accesses, also.
+		progressattr.Subscribe(o.Context.CredentialsContext(), h)
+	}
 	err = registration.RegisterExtensions(o.Context)
 	if err != nil {
 		return err
@@ -25,6 +25,7 @@ import (
 	logdata "ocm.software/ocm/api/utils/cobrautils/logopts/logging"
 	"ocm.software/ocm/api/utils/logging"
 	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/progress"
 	"ocm.software/ocm/api/utils/runtime"
 	"ocm.software/ocm/cmds/ocm/commands/common/options/keyoption"
 )
@@ -48,6 +49,7 @@ type Config struct {
 	Settings    []string                 `json:"settings,omitempty"`
 	Timeout     string                   `json:"timeout,omitempty"`
 	Verbose     bool                     `json:"verbose,omitempty"`
+	Progress    string                   `json:"progress,omitempty"`
 	Signing     keyoption.ConfigFragment `json:"signing,omitempty"`
 	Logging     logopts.ConfigFragment   `json:"logging,omitempty"`
 
@@ -74,6 +76,8 @@ func (c *Config) AddFlags(fs *pflag.FlagSet) {
 	fs.StringArrayVarP(&c.Settings, "attribute", "X", nil, "attribute setting")
 	fs.StringVar(&c.Timeout, "timeout", "", "client timeout (default 30s, e.g. 30s, 5m)")
 	fs.BoolVarP(&c.Verbose, "verbose", "v", false, "deprecated: enable logrus verbose logging")
+	fs.StringVarP(&c.Progress, "progress", "", "", "report progress of long-running operations on stderr (auto, bar or json)")
+	fs.Lookup("progress").NoOptDefVal = progress.MODE_AUTO
 
 	c.Logging.AddFlags(fs)
 	c.Signing.AddFlags(fs)
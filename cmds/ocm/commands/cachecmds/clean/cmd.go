@@ -7,6 +7,7 @@ import (
 	"github.com/mandelsoft/goutils/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	clictx "ocm.software/ocm/api/cli"
 	"ocm.software/ocm/api/oci/extensions/attrs/cacheattr"
@@ -36,6 +37,9 @@ type Command struct {
 	duration string
 	before   time.Time
 	dryrun   bool
+	size     string
+	maxSize  int64
+	reset    bool
 }
 
 // NewCommand creates a new artifact command.
@@ -49,10 +53,18 @@ func (o *Command) ForName(name string) *cobra.Command {
 		Short: "cleanup oci blob cache",
 		Long: `
 Cleanup all blobs stored in oci blob cache (if given).
+
+With option <code>--before</code> only entries not used since the given
+point in time are removed. With option <code>--max-size</code> entries
+are evicted according to the eviction policy of the cache (least recently
+used by default), until the cache size does not exceed the given size.
+Both options can be combined.
 	`,
 		Args: cobra.NoArgs,
 		Example: `
 $ ocm clean cache
+$ ocm clean cache --before 10d
+$ ocm clean cache --max-size 5Gi
 `,
 		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
 	}
@@ -62,6 +74,8 @@ func (o *Command) AddFlags(fs *pflag.FlagSet) {
 	o.BaseCommand.AddFlags(fs)
 	fs.StringVarP(&o.duration, "before", "b", "", "time since last usage")
 	fs.BoolVarP(&o.dryrun, "dry-run", "s", false, "show size to be removed")
+	fs.StringVarP(&o.size, "max-size", "", "", "maximum cache size to keep (for example 500Mi)")
+	fs.BoolVarP(&o.reset, "reset-statistics", "", false, "reset cache usage statistics")
 }
 
 func (o *Command) Complete(args []string) error {
@@ -74,6 +88,24 @@ func (o *Command) Complete(args []string) error {
 		return errors.Newf("cache implementation does not support cleanup")
 	}
 	o.cache = r
+	if o.size != "" {
+		if _, ok := c.(accessio.EvictingCache); !ok {
+			return errors.Newf("cache implementation does not support size based eviction")
+		}
+		q, err := resource.ParseQuantity(o.size)
+		if err != nil {
+			return errors.Wrapf(err, "invalid cache size %q", o.size)
+		}
+		if q.Value() < 0 {
+			return fmt.Errorf("invalid cache size %q", o.size)
+		}
+		o.maxSize = q.Value()
+	}
+	if o.reset {
+		if _, ok := c.(accessio.StatisticsCache); !ok {
+			return errors.Newf("cache implementation does not support statistics")
+		}
+	}
 	if o.duration != "" {
 		if t, err := utils2.ParseDeltaTime(o.duration, true); err == nil {
 			o.before = t
@@ -92,6 +124,34 @@ func (o *Command) Complete(args []string) error {
 }
 
 func (o *Command) Run() error {
+	if o.size == "" || o.duration != "" {
+		err := o.cleanup()
+		if err != nil {
+			return err
+		}
+	}
+	if o.size != "" {
+		cnt, size, err := o.cache.(accessio.EvictingCache).Evict(common.NewPrinter(o.Context.StdErr()), o.maxSize, o.dryrun)
+		if err != nil {
+			return err
+		}
+		if o.dryrun {
+			out.Outf(o.Context, "Would evict %d entries [%.3f MB]\n", cnt, float64(size)/1024/1024)
+		} else {
+			out.Outf(o.Context, "Successfully evicted %d entries [%.3f MB]\n", cnt, float64(size)/1024/1024)
+		}
+	}
+	if o.reset && !o.dryrun {
+		err := o.cache.(accessio.StatisticsCache).ResetStatistics()
+		if err != nil {
+			return err
+		}
+		out.Outf(o.Context, "Statistics reset\n")
+	}
+	return nil
+}
+
+func (o *Command) cleanup() error {
 	cnt, ncnt, fcnt, size, nsize, fsize, err := o.cache.Cleanup(common.NewPrinter(o.Context.StdErr()), &o.before, o.dryrun)
 	if err != nil {
 		return err
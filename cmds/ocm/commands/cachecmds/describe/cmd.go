@@ -1,6 +1,8 @@
 package describe
 
 import (
+	"time"
+
 	"github.com/mandelsoft/goutils/errors"
 	"github.com/spf13/cobra"
 
@@ -34,6 +36,9 @@ func (o *Command) ForName(name string) *cobra.Command {
 		Short: "show OCI blob cache information",
 		Long: `
 Show details about the OCI blob cache (if given).
+
+If the cache keeps usage statistics, the number of cache hits, misses,
+additions and evictions and the resulting hit rate are shown, also.
 	`,
 		Args: cobra.NoArgs,
 		Example: `
@@ -67,5 +72,20 @@ func (o *Command) Run() error {
 		out.Outf(o.Context, "Cache does not support more info\n")
 	}
 
+	if r, ok := o.cache.(accessio.StatisticsCache); ok {
+		stats, err := r.Statistics()
+		if err != nil {
+			return err
+		}
+		if stats.LastResetTime != 0 {
+			out.Outf(o.Context, "Statistics since %s\n", time.Unix(stats.LastResetTime, 0).Format(time.RFC3339))
+		}
+		out.Outf(o.Context, "Hits: %d, Misses: %d [hit rate %.1f%%]\n", stats.Hits, stats.Misses, stats.HitRate()*100)
+		out.Outf(o.Context, "Additions: %d, Evictions: %d [%.3f MB]\n", stats.Additions, stats.Evictions, float64(stats.EvictedSize)/1024/1024)
+		if stats.Corrupted > 0 {
+			out.Outf(o.Context, "Corrupted entries removed: %d\n", stats.Corrupted)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,166 @@
+package add
+
+import (
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/credentials/extensions/repositories/encryptedfile"
+	utils2 "ocm.software/ocm/api/utils"
+	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/out"
+	credcommon "ocm.software/ocm/cmds/ocm/commands/misccmds/credentials/common"
+	"ocm.software/ocm/cmds/ocm/commands/misccmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.Credentials
+	Verb  = verbs.Add
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	Secret *credcommon.SecretOptions
+
+	Path       string
+	Name       string
+	Settings   []string
+	Consumer   credentials.ConsumerIdentity
+	Properties common.Properties
+}
+
+var _ utils.OCMCommand = (*Command)(nil)
+
+// NewCommand creates a new command adding credentials to an encrypted credentials file.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx), Secret: credcommon.NewSecretOptions()}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] <credentials file> {<consumer property>=<value>}",
+		Short: "add credentials to an encrypted credentials file",
+		Long: `
+Add credentials for a consumer identity pattern and/or a name to an encrypted
+credentials file. The credential properties are given by the option
+<code>--property</code>. A value starting with <code>@</code> is read from the
+given file. If the file already contains credentials for the given consumer
+identity or name, they are replaced, which can be used to rotate the
+credentials of an entry. If the credentials file does not exist, it is created.
+` + credcommon.Usage(),
+		Example: `
+$ ocm add credentials --key-file creds.key creds.yaml type=OCIRegistry hostname=ghcr.io -p username=acme -p password=@token
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(set *pflag.FlagSet) {
+	o.Secret.AddFlags(set)
+	set.StringVarP(&o.Name, "name", "n", "", "name of the credentials entry")
+	set.StringArrayVarP(&o.Settings, "property", "p", nil, "credential property (<name>=<value>)")
+}
+
+func (o *Command) Complete(args []string) error {
+	if len(args) == 0 {
+		return errors.Newf("credentials file required")
+	}
+	o.Path = args[0]
+	if err := o.Secret.Complete(); err != nil {
+		return err
+	}
+	o.Consumer = credentials.ConsumerIdentity{}
+	for _, s := range args[1:] {
+		name, value, err := split(s)
+		if err != nil {
+			return errors.Wrapf(err, "consumer setting")
+		}
+		o.Consumer[name] = value
+	}
+	if len(o.Consumer) == 0 {
+		o.Consumer = nil
+		if o.Name == "" {
+			return errors.Newf("consumer identity or name required")
+		}
+	}
+	if len(o.Settings) == 0 {
+		return errors.Newf("at least one credential property required")
+	}
+	o.Properties = common.Properties{}
+	for _, s := range o.Settings {
+		name, value, err := split(s)
+		if err != nil {
+			return errors.Wrapf(err, "credential property")
+		}
+		data, err := utils2.ResolveData(value, o.FileSystem())
+		if err != nil {
+			return errors.Wrapf(err, "credential property %q", name)
+		}
+		o.Properties[name] = string(data)
+	}
+	return nil
+}
+
+func (o *Command) Run() error {
+	fs := o.FileSystem()
+	var file *encryptedfile.File
+	var key []byte
+
+	ok, err := vfs.FileExists(fs, o.Path)
+	if err != nil {
+		return err
+	}
+	secret, created, err := o.Secret.Secret(fs, !ok)
+	if err != nil {
+		return err
+	}
+	if created {
+		out.Outf(o, "created new encryption key %s\n", o.Secret.KeyFile)
+	}
+	if ok {
+		file, err = encryptedfile.ReadFile(o.Path, fs)
+		if err == nil {
+			key, err = file.Key(secret)
+		}
+	} else {
+		file, key, err = encryptedfile.NewFile(secret)
+	}
+	if err != nil {
+		return err
+	}
+
+	msg := "added"
+	if (o.Name != "" && file.Lookup(o.Name) != nil) || (o.Consumer != nil && file.Find(o.Consumer) != nil) {
+		msg = "updated"
+	}
+	err = file.Set(key, o.Name, o.Consumer, o.Properties)
+	if err != nil {
+		return err
+	}
+	err = file.WriteFile(o.Path, fs)
+	if err != nil {
+		return errors.Wrapf(err, "cannot write credentials file %q", o.Path)
+	}
+	desc := o.Name
+	if o.Consumer != nil {
+		desc = o.Consumer.String()
+	}
+	out.Outf(o, "%s credentials %s in %s\n", msg, desc, o.Path)
+	return nil
+}
+
+func split(s string) (string, string, error) {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return "", "", errors.ErrInvalid("setting", s)
+	}
+	return s[:i], s[i+1:], nil
+}
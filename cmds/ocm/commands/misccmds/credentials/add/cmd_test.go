@@ -0,0 +1,80 @@
+package add_test
+
+import (
+	"bytes"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/credentials/extensions/repositories/encryptedfile"
+	"ocm.software/ocm/api/utils/encrypt"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const (
+	CREDS = "/creds.yaml"
+	KEY   = "/creds.key"
+	TOKEN = "/token"
+)
+
+var _ = Describe("Add Credentials", func() {
+	var env *TestEnv
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+		MustBeSuccessful(vfs.WriteFile(env.FileSystem(), TOKEN, []byte("token"), 0o600))
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("creates credentials file and key", func() {
+		buf := bytes.NewBuffer(nil)
+		Expect(env.CatchOutput(buf).Execute("add", "credentials", "--key-file", KEY, CREDS, "type=OCIRegistry", "hostname=ghcr.io", "-p", "username=acme", "-p", "password=@"+TOKEN)).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+created new encryption key /creds.key
+added credentials {"hostname":"ghcr.io","type":"OCIRegistry"} in /creds.yaml
+`))
+		key := Must(encrypt.ReadKey(KEY, env.FileSystem()))
+		f := Must(encryptedfile.ReadFile(CREDS, env.FileSystem()))
+		Expect(f.Encryption.Method).To(Equal(encryptedfile.METHOD_KEY))
+		Expect(f.Entries[0].Decrypt(key)).To(Equal(common.Properties{"username": "acme", "password": "token"}))
+
+		MustBeSuccessful(env.CredentialsContext().RepositoryForSpec(encryptedfile.NewRepositorySpec(CREDS).WithKeyFile(KEY)))
+		buf.Reset()
+		Expect(env.CatchOutput(buf).Execute("get", "credentials", "type=OCIRegistry", "hostname=ghcr.io", "pathprefix=acme")).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+ATTRIBUTE VALUE
+password  token
+username  acme
+`))
+	})
+
+	It("updates credentials", func() {
+		MustBeSuccessful(vfs.WriteFile(env.FileSystem(), "/passphrase", []byte("secret\n"), 0o600))
+		Expect(env.Execute("add", "credentials", "-P", "/passphrase", CREDS, "-n", "test", "-p", "token=old")).To(Succeed())
+		Expect(env.Execute("add", "credentials", "-P", "/passphrase", CREDS, "type=OCIRegistry", "hostname=ghcr.io", "-p", "username=acme")).To(Succeed())
+
+		buf := bytes.NewBuffer(nil)
+		Expect(env.CatchOutput(buf).Execute("add", "credentials", "-P", "/passphrase", CREDS, "-n", "test", "-p", "token=new")).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+updated credentials test in /creds.yaml
+`))
+		f := Must(encryptedfile.ReadFile(CREDS, env.FileSystem()))
+		Expect(len(f.Entries)).To(Equal(2))
+		key := Must(f.Key(&encryptedfile.Secret{Passphrase: "secret"}))
+		Expect(f.Lookup("test").Decrypt(key)).To(Equal(common.Properties{"token": "new"}))
+	})
+
+	It("rejects wrong secret", func() {
+		MustBeSuccessful(vfs.WriteFile(env.FileSystem(), "/passphrase", []byte("secret"), 0o600))
+		MustBeSuccessful(vfs.WriteFile(env.FileSystem(), "/other", []byte("other"), 0o600))
+		Expect(env.Execute("add", "credentials", "-P", "/passphrase", CREDS, "-n", "test", "-p", "token=old")).To(Succeed())
+		ExpectError(env.Execute("add", "credentials", "-P", "/other", CREDS, "-n", "test", "-p", "token=new")).To(MatchError("secret does not match encrypted credentials"))
+	})
+})
@@ -4,7 +4,9 @@ import (
 	"github.com/spf13/cobra"
 
 	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/cmds/ocm/commands/misccmds/credentials/add"
 	credentials "ocm.software/ocm/cmds/ocm/commands/misccmds/credentials/get"
+	"ocm.software/ocm/cmds/ocm/commands/misccmds/credentials/rotate"
 	"ocm.software/ocm/cmds/ocm/commands/misccmds/names"
 	"ocm.software/ocm/cmds/ocm/common/utils"
 )
@@ -17,5 +19,7 @@ func NewCommand(ctx clictx.Context) *cobra.Command {
 		Short: "Commands acting on credentials",
 	}, Names...)
 	cmd.AddCommand(credentials.NewCommand(ctx, credentials.Verb))
+	cmd.AddCommand(add.NewCommand(ctx, add.Verb))
+	cmd.AddCommand(rotate.NewCommand(ctx, rotate.Verb))
 	return cmd
 }
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	"github.com/spf13/pflag"
+
+	"ocm.software/ocm/api/credentials/extensions/repositories/encryptedfile"
+	"ocm.software/ocm/api/utils/encrypt"
+)
+
+// SecretOptions describe the secret used for an encrypted credentials file.
+type SecretOptions struct {
+	prefix string
+
+	KeyFile        string
+	PassphraseFile string
+}
+
+// NewSecretOptions creates secret options. A prefix is used
+// to provide an additional set of options, e.g. for a new secret.
+func NewSecretOptions(prefix ...string) *SecretOptions {
+	if len(prefix) > 0 {
+		return &SecretOptions{prefix: prefix[0]}
+	}
+	return &SecretOptions{}
+}
+
+func (o *SecretOptions) AddFlags(fs *pflag.FlagSet) {
+	if o.prefix == "" {
+		fs.StringVarP(&o.KeyFile, "key-file", "k", "", "file with AES key used to encrypt credential values")
+		fs.StringVarP(&o.PassphraseFile, "passphrase-file", "P", "", "file with passphrase used to encrypt credential values")
+	} else {
+		fs.StringVarP(&o.KeyFile, o.prefix+"-key-file", "", "", o.prefix+" file with AES key used to encrypt credential values")
+		fs.StringVarP(&o.PassphraseFile, o.prefix+"-passphrase-file", "", "", o.prefix+" file with passphrase used to encrypt credential values")
+	}
+}
+
+func (o *SecretOptions) flag(name string) string {
+	if o.prefix == "" {
+		return "--" + name
+	}
+	return "--" + o.prefix + "-" + name
+}
+
+func (o *SecretOptions) Complete() error {
+	if o.KeyFile != "" && o.PassphraseFile != "" {
+		return errors.Newf("only one of %s or %s possible", o.flag("key-file"), o.flag("passphrase-file"))
+	}
+	if o.KeyFile == "" && o.PassphraseFile == "" {
+		return errors.Newf("one of %s or %s required", o.flag("key-file"), o.flag("passphrase-file"))
+	}
+	return nil
+}
+
+// Secret provides the configured secret. If create is set and the
+// key file does not exist, a new AES-256 key is generated and
+// stored in the key file. The second result indicates whether
+// a new key has been created.
+func (o *SecretOptions) Secret(fs vfs.FileSystem, create bool) (*encryptedfile.Secret, bool, error) {
+	if create && o.KeyFile != "" {
+		if ok, err := vfs.Exists(fs, o.KeyFile); err != nil || !ok {
+			key, err := encrypt.NewKey(encrypt.AES_256)
+			if err != nil {
+				return nil, false, errors.Wrapf(err, "cannot create new encryption key")
+			}
+			err = vfs.WriteFile(fs, o.KeyFile, encrypt.KeyToPem(key), 0o600)
+			if err != nil {
+				return nil, false, errors.Wrapf(err, "cannot write key file %q", o.KeyFile)
+			}
+			return &encryptedfile.Secret{Key: key}, true, nil
+		}
+	}
+	s, err := encryptedfile.ReadSecret(o.KeyFile, o.PassphraseFile, fs)
+	return s, false, err
+}
+
+// Usage describes the secret handling for encrypted credentials files.
+func Usage() string {
+	return `
+The credential values are encrypted either with an AES key taken from a PEM
+file (option <code>--key-file</code>) or with a key derived from a passphrase
+read from a file (option <code>--passphrase-file</code>). If the given key file
+does not exist, a new AES-256 key is generated and stored in this file.
+
+The file can be used as credential repository of type
+<code>` + encryptedfile.Type + `</code>.
+`
+}
@@ -26,11 +26,13 @@ var (
 type Command struct {
 	utils.BaseCommand
 
-	Consumer credentials.ConsumerIdentity
-	Matcher  credentials.IdentityMatcher
+	Consumer    credentials.ConsumerIdentity
+	Matcher     credentials.IdentityMatcher
+	MatcherType string
 
-	Type   string
-	Sloppy bool
+	Type    string
+	Sloppy  bool
+	Explain bool
 }
 
 var _ utils.OCMCommand = (*Command)(nil)
@@ -67,13 +69,24 @@ The following standard identity matchers are supported:
 The used matcher is derived from the consumer attribute <code>type</code>.
 For all other consumer types a matcher matching all attributes will be used.
 The usage of a dedicated matcher can be enforced by the option <code>--matcher</code>.
+
+With the option <code>--explain</code> the resolution of the credentials is
+traced. It shows the configured credential repositories and the consumer
+providers, all consumer identities evaluated with the used matcher (compared
+to the <code>partial</code> and <code>exact</code> matcher), which identity
+finally won and why. Secret credential values are masked.
+`,
+		Example: `
+$ ocm get credentials --explain type=OCIRegistry hostname=ghcr.io pathprefix=acme/repo
 `,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
 	}
 }
 
 func (o *Command) AddFlags(set *pflag.FlagSet) {
 	set.StringVarP(&o.Type, "matcher", "m", "", "matcher type override")
 	set.BoolVarP(&o.Sloppy, "sloppy", "s", false, "sloppy matching of consumer type")
+	set.BoolVarP(&o.Explain, "explain", "", false, "explain the resolution of the credentials (secrets masked)")
 }
 
 func (o *Command) Complete(args []string) error {
@@ -83,6 +96,7 @@ func (o *Command) Complete(args []string) error {
 			return errors.ErrUnknown("identity matcher", o.Type)
 		}
 		o.Matcher = m
+		o.MatcherType = o.Type
 	}
 	o.Consumer = credentials.ConsumerIdentity{}
 	for _, s := range args {
@@ -101,10 +115,12 @@ func (o *Command) Complete(args []string) error {
 		m := o.CredentialsContext().ConsumerIdentityMatchers().Get(t)
 		if m != nil {
 			o.Matcher = m
+			o.MatcherType = t
 		}
 	}
 	if o.Matcher == nil {
 		o.Matcher = credentials.PartialMatch
+		o.MatcherType = "partial"
 	}
 	return nil
 }
@@ -118,6 +134,10 @@ func (o *Command) Run() error {
 		}
 	}
 
+	if o.Explain {
+		return o.explain()
+	}
+
 	creds, err := credentials.RequiredCredentialsForConsumer(o.CredentialsContext(), o.Consumer, o.Matcher)
 	if err != nil {
 		return err
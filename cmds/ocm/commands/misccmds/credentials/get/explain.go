@@ -0,0 +1,235 @@
+package get
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+
+	"ocm.software/ocm/api/config"
+	"ocm.software/ocm/api/credentials"
+	credcfg "ocm.software/ocm/api/credentials/config"
+	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/cmds/ocm/common/output"
+)
+
+const MASK = "***"
+
+// publicAttributes are credential attributes shown in clear text.
+var publicAttributes = map[string]bool{
+	credentials.ATTR_USERNAME:       true,
+	"email":                         true,
+	credentials.ATTR_SERVER_ADDRESS: true,
+	"user":                          true,
+	"clientId":                      true,
+	"authmeth":                      true,
+}
+
+// explain shows how the credentials for the consumer are resolved.
+func (o *Command) explain() error {
+	cctx := o.CredentialsContext()
+
+	out.Outf(o, "Consumer: %s\n", o.Consumer.String())
+	out.Outf(o, "Matcher:  %s\n", o.describeMatcher())
+
+	out.Outf(o, "\nConfigured credential repositories:\n")
+	repos := o.repositories()
+	if len(repos) == 0 {
+		out.Outf(o, "  none\n")
+	} else {
+		output.FormatTable(o, "  ", append([][]string{{"TYPE", "ALIAS", "SPECIFICATION"}}, repos...))
+	}
+
+	src, trace, err := credentials.ExplainCredentialsForConsumer(cctx, o.Consumer, o.Matcher)
+	if err != nil && !errors.IsErrUnknownKind(err, credentials.KIND_CONSUMER) {
+		return err
+	}
+
+	out.Outf(o, "\nConsumer providers (in evaluation order):\n")
+	out.Outf(o, "  - explicit consumer settings\n")
+	for _, p := range trace.Providers {
+		skipped := ""
+		for _, s := range trace.Skipped {
+			if s == p {
+				skipped = " (skipped to avoid recursion)"
+			}
+		}
+		out.Outf(o, "  - %s%s\n", p, skipped)
+	}
+
+	out.Outf(o, "\nEvaluated consumer identities:\n")
+	if len(trace.Evaluations) == 0 {
+		out.Outf(o, "  none\n")
+	} else {
+		list := [][]string{{"PROVIDER", "IDENTITY", strings.ToUpper(o.MatcherType), "PARTIAL", "EXACT", "RESULT"}}
+		for _, e := range trace.Evaluations {
+			list = append(list, []string{
+				describeProvider(e),
+				e.Identity.String(),
+				matchResult(e.Matches),
+				matchResult(credentials.PartialMatch(o.Consumer, nil, e.Identity)),
+				matchResult(credentials.CompleteMatch(o.Consumer, nil, e.Identity)),
+				evaluationResult(e),
+			})
+		}
+		output.FormatTable(o, "  ", list)
+	}
+
+	out.Outf(o, "\nResult:\n")
+	selected := trace.Selected()
+	switch {
+	case selected != nil:
+		out.Outf(o, "  selected %s provided by %s\n", selected.Identity.String(), describeProvider(selected))
+		out.Outf(o, "  reason: %s\n", o.reason(selected, trace))
+	case trace.Default:
+		out.Outf(o, "  no configured identity matches, using the credentials configured for the empty consumer identity\n")
+	default:
+		out.Outf(o, "  no credentials found: no configured identity matches according to matcher %q\n", o.MatcherType)
+		return nil
+	}
+
+	creds, err := src.Credentials(cctx)
+	if err != nil {
+		out.Outf(o, "  credentials cannot be evaluated: %s\n", err)
+		return nil
+	}
+	var list [][]string
+	for k, v := range creds.Properties() {
+		if !publicAttributes[k] {
+			v = MASK
+		}
+		list = append(list, []string{k, v})
+	}
+	sort.Slice(list, func(i, j int) bool { return strings.Compare(list[i][0], list[j][0]) < 0 })
+	out.Outf(o, "\nCredentials (secrets masked):\n")
+	output.FormatTable(o, "  ", append([][]string{{"ATTRIBUTE", "VALUE"}}, list...))
+	return nil
+}
+
+func (o *Command) describeMatcher() string {
+	for _, e := range o.CredentialsContext().ConsumerIdentityMatchers().List() {
+		if e.Type == o.MatcherType {
+			desc, _, _ := strings.Cut(strings.TrimSpace(e.Description), "\n")
+			return fmt.Sprintf("%s (%s)", o.MatcherType, desc)
+		}
+	}
+	return o.MatcherType
+}
+
+func (o *Command) reason(selected *credentials.MatchEvaluation, trace *credentials.MatchTrace) string {
+	var others int
+	for _, e := range trace.Evaluations {
+		if e != selected && e.Matches {
+			others++
+		}
+	}
+	switch {
+	case others == 0:
+		return fmt.Sprintf("only identity matching according to matcher %q", o.MatcherType)
+	case selected.Explicit:
+		return fmt.Sprintf("explicit consumer setting is the best of %d matching identities according to matcher %q, no provider offers a better match", others+1, o.MatcherType)
+	default:
+		return fmt.Sprintf("best of %d matching identities according to matcher %q, later evaluated identities are no better match", others+1, o.MatcherType)
+	}
+}
+
+// repositories lists the credential repositories and aliases
+// configured by the applied credential configurations.
+func (o *Command) repositories() [][]string {
+	var list [][]string
+	_, cfgs := o.ConfigContext().GetConfig(config.AllGenerations, config.ConfigSelectorFunction(func(c config.Config) bool {
+		_, ok := c.(*credcfg.Config)
+		return ok
+	}))
+	for _, c := range cfgs {
+		cfg := c.(*credcfg.Config)
+		for _, r := range cfg.Repositories {
+			list = append(list, []string{r.Repository.GetType(), "", maskSpec(&r.Repository)})
+		}
+		names := make([]string, 0, len(cfg.Aliases))
+		for n := range cfg.Aliases {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			r := cfg.Aliases[n]
+			list = append(list, []string{r.Repository.GetType(), n, maskSpec(&r.Repository)})
+		}
+	}
+	return list
+}
+
+func describeProvider(e *credentials.MatchEvaluation) string {
+	if e.Explicit {
+		if e.Provider != "" {
+			return "explicit (" + string(e.Provider) + ")"
+		}
+		return "explicit"
+	}
+	return string(e.Provider)
+}
+
+func matchResult(b bool) string {
+	if b {
+		return "match"
+	}
+	return "-"
+}
+
+func evaluationResult(e *credentials.MatchEvaluation) string {
+	switch {
+	case e.Selected:
+		return "better match"
+	case e.Matches:
+		return "no better match than " + e.Current.String()
+	default:
+		return "no match"
+	}
+}
+
+// maskSpec provides the serialized repository specification with
+// potentially secret values masked.
+func maskSpec(spec credentials.RepositorySpec) string {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return string(data)
+	}
+	data, err = json.Marshal(maskValues(m, false))
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+	return string(data)
+}
+
+func maskValues(m map[string]interface{}, props bool) map[string]interface{} {
+	for k, v := range m {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			m[k] = maskValues(t, props || k == "properties" || k == "credentials")
+		case string:
+			if (props && !publicAttributes[k]) || isSecretField(k) {
+				m[k] = MASK
+			}
+		}
+	}
+	return m
+}
+
+func isSecretField(name string) bool {
+	l := strings.ToLower(name)
+	if strings.HasSuffix(l, "file") || strings.HasSuffix(l, "path") || strings.HasSuffix(l, "env") {
+		return false
+	}
+	for _, s := range []string{"password", "secret", "token", "key", "dockerconfig"} {
+		if strings.Contains(l, s) {
+			return true
+		}
+	}
+	return false
+}
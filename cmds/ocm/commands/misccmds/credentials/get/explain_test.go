@@ -0,0 +1,100 @@
+package get_test
+
+import (
+	"bytes"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/credentials"
+	credcfg "ocm.software/ocm/api/credentials/config"
+	"ocm.software/ocm/api/credentials/cpi"
+	"ocm.software/ocm/api/credentials/extensions/repositories/directcreds"
+	"ocm.software/ocm/api/credentials/extensions/repositories/netrc"
+	"ocm.software/ocm/api/tech/wget/identity"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const NETRC = "/netrc"
+
+var _ = Describe("Explain Credentials", func() {
+	var env *TestEnv
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+		MustBeSuccessful(vfs.WriteFile(env.FileSystem(), NETRC, []byte("machine files.acme.org login netrc password secret\n"), 0o600))
+
+		cfg := credcfg.New()
+		MustBeSuccessful(cfg.AddConsumer(credentials.NewConsumerIdentity(identity.CONSUMER_TYPE, identity.ID_HOSTNAME, "files.acme.org"),
+			directcreds.NewCredentials(common.Properties{cpi.ATTR_USERNAME: "explicit", cpi.ATTR_PASSWORD: "explicit-secret"})))
+		MustBeSuccessful(cfg.AddRepository(netrc.NewRepositorySpec(NETRC, true)))
+		MustBeSuccessful(cfg.AddAlias("direct", directcreds.NewRepositorySpec(common.Properties{cpi.ATTR_USERNAME: "alias", cpi.ATTR_PASSWORD: "alias-secret"})))
+		MustBeSuccessful(env.ConfigContext().ApplyConfig(cfg, "test"))
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("explains selected credentials", func() {
+		buf := bytes.NewBuffer(nil)
+		Expect(env.CatchOutput(buf).Execute("get", "credentials", "--explain", cpi.ID_TYPE+"="+identity.CONSUMER_TYPE, identity.ID_HOSTNAME+"=files.acme.org", identity.ID_PATHPREFIX+"=a/b")).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+Consumer: {"hostname":"files.acme.org","pathprefix":"a/b","type":"wget"}
+Matcher:  wget (wget credential matcher)
+
+Configured credential repositories:
+  TYPE        ALIAS  SPECIFICATION
+  Netrc              {"netrcFile":"/netrc","propagateConsumerIdentity":true,"type":"Netrc"}
+  Credentials direct {"properties":{"password":"***","username":"alias"},"type":"Credentials"}
+
+Consumer providers (in evaluation order):
+  - explicit consumer settings
+  - ocm.software/credentialprovider/Netrc/{"type":"Netrc","netrcFile":"/netrc"}
+
+Evaluated consumer identities:
+  PROVIDER                                                                    IDENTITY                                    WGET  PARTIAL EXACT RESULT
+  explicit                                                                    {"hostname":"files.acme.org","type":"wget"} match match   -     better match
+  ocm.software/credentialprovider/Netrc/{"type":"Netrc","netrcFile":"/netrc"} {"hostname":"files.acme.org","type":"wget"} match match   -     no better match than {"hostname":"files.acme.org","type":"wget"}
+
+Result:
+  selected {"hostname":"files.acme.org","type":"wget"} provided by explicit
+  reason: explicit consumer setting is the best of 2 matching identities according to matcher "wget", no provider offers a better match
+
+Credentials (secrets masked):
+  ATTRIBUTE VALUE
+  password  ***
+  username  explicit
+`))
+	})
+
+	It("explains missing credentials", func() {
+		buf := bytes.NewBuffer(nil)
+		Expect(env.CatchOutput(buf).Execute("get", "credentials", "--explain", cpi.ID_TYPE+"="+identity.CONSUMER_TYPE, identity.ID_HOSTNAME+"=other.acme.org")).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+Consumer: {"hostname":"other.acme.org","type":"wget"}
+Matcher:  wget (wget credential matcher)
+
+Configured credential repositories:
+  TYPE        ALIAS  SPECIFICATION
+  Netrc              {"netrcFile":"/netrc","propagateConsumerIdentity":true,"type":"Netrc"}
+  Credentials direct {"properties":{"password":"***","username":"alias"},"type":"Credentials"}
+
+Consumer providers (in evaluation order):
+  - explicit consumer settings
+  - ocm.software/credentialprovider/Netrc/{"type":"Netrc","netrcFile":"/netrc"}
+
+Evaluated consumer identities:
+  PROVIDER                                                                    IDENTITY                                    WGET PARTIAL EXACT RESULT
+  explicit                                                                    {"hostname":"files.acme.org","type":"wget"} -    -       -     no match
+  ocm.software/credentialprovider/Netrc/{"type":"Netrc","netrcFile":"/netrc"} {"hostname":"files.acme.org","type":"wget"} -    -       -     no match
+
+Result:
+  no credentials found: no configured identity matches according to matcher "wget"
+`))
+	})
+})
@@ -0,0 +1,112 @@
+package rotate
+
+import (
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/credentials/extensions/repositories/encryptedfile"
+	"ocm.software/ocm/api/utils/out"
+	credcommon "ocm.software/ocm/cmds/ocm/commands/misccmds/credentials/common"
+	"ocm.software/ocm/cmds/ocm/commands/misccmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.Credentials
+	Verb  = verbs.Rotate
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	Secret    *credcommon.SecretOptions
+	NewSecret *credcommon.SecretOptions
+
+	Path string
+}
+
+var _ utils.OCMCommand = (*Command)(nil)
+
+// NewCommand creates a new command rotating the secret of an encrypted credentials file.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{
+		BaseCommand: utils.NewBaseCommand(ctx),
+		Secret:      credcommon.NewSecretOptions(),
+		NewSecret:   credcommon.NewSecretOptions("new"),
+	}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] <credentials file>",
+		Short: "rotate the secret of an encrypted credentials file",
+		Long: `
+Re-encrypt all credential values of an encrypted credentials file with a
+new secret. The actual secret is given by the options <code>--key-file</code>
+or <code>--passphrase-file</code>, the new one by the options
+<code>--new-key-file</code> or <code>--new-passphrase-file</code>.
+If the new key file does not exist, a new AES-256 key is generated and
+stored in this file.
+
+To rotate the credentials of a dedicated entry, use the command
+<code>ocm add credentials</code>.
+` + credcommon.Usage(),
+		Example: `
+$ ocm rotate credentials --passphrase-file passphrase --new-key-file creds.key creds.yaml
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(set *pflag.FlagSet) {
+	o.Secret.AddFlags(set)
+	o.NewSecret.AddFlags(set)
+}
+
+func (o *Command) Complete(args []string) error {
+	if len(args) != 1 {
+		return errors.Newf("exactly one credentials file required")
+	}
+	o.Path = args[0]
+	if err := o.Secret.Complete(); err != nil {
+		return err
+	}
+	return o.NewSecret.Complete()
+}
+
+func (o *Command) Run() error {
+	fs := o.FileSystem()
+
+	file, err := encryptedfile.ReadFile(o.Path, fs)
+	if err != nil {
+		return err
+	}
+	secret, _, err := o.Secret.Secret(fs, false)
+	if err != nil {
+		return err
+	}
+	key, err := file.Key(secret)
+	if err != nil {
+		return err
+	}
+	secret, created, err := o.NewSecret.Secret(fs, true)
+	if err != nil {
+		return err
+	}
+	if created {
+		out.Outf(o, "created new encryption key %s\n", o.NewSecret.KeyFile)
+	}
+	_, err = file.Rotate(key, secret)
+	if err != nil {
+		return err
+	}
+	err = file.WriteFile(o.Path, fs)
+	if err != nil {
+		return errors.Wrapf(err, "cannot write credentials file %q", o.Path)
+	}
+	out.Outf(o, "rotated secret for %d credentials entries in %s\n", len(file.Entries), o.Path)
+	return nil
+}
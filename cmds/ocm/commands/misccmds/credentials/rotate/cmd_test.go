@@ -0,0 +1,65 @@
+package rotate_test
+
+import (
+	"bytes"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/credentials/extensions/repositories/encryptedfile"
+	"ocm.software/ocm/api/tech/oci/identity"
+	"ocm.software/ocm/api/utils/encrypt"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const (
+	CREDS      = "/creds.yaml"
+	KEY        = "/creds.key"
+	PASSPHRASE = "/passphrase"
+)
+
+var _ = Describe("Rotate Credentials", func() {
+	var env *TestEnv
+	props := common.Properties{"username": "acme", "password": "token"}
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+		MustBeSuccessful(vfs.WriteFile(env.FileSystem(), PASSPHRASE, []byte("secret"), 0o600))
+		f, key := Must2(encryptedfile.NewFile(&encryptedfile.Secret{Passphrase: "secret"}))
+		MustBeSuccessful(f.Set(key, "", identity.GetConsumerId("ghcr.io", "acme"), props))
+		MustBeSuccessful(f.Set(key, "test", nil, props))
+		MustBeSuccessful(f.WriteFile(CREDS, env.FileSystem()))
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("rotates to new key", func() {
+		buf := bytes.NewBuffer(nil)
+		Expect(env.CatchOutput(buf).Execute("rotate", "credentials", "--passphrase-file", PASSPHRASE, "--new-key-file", KEY, CREDS)).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+created new encryption key /creds.key
+rotated secret for 2 credentials entries in /creds.yaml
+`))
+		key := Must(encrypt.ReadKey(KEY, env.FileSystem()))
+		f := Must(encryptedfile.ReadFile(CREDS, env.FileSystem()))
+		ExpectError(f.Key(&encryptedfile.Secret{Passphrase: "secret"})).To(HaveOccurred())
+		key = Must(f.Key(&encryptedfile.Secret{Key: key}))
+		Expect(f.Lookup("test").Decrypt(key)).To(Equal(props))
+		Expect(f.Find(identity.GetConsumerId("ghcr.io", "acme")).Decrypt(key)).To(Equal(props))
+	})
+
+	It("rejects wrong secret", func() {
+		MustBeSuccessful(vfs.WriteFile(env.FileSystem(), "/other", []byte("other"), 0o600))
+		ExpectError(env.Execute("rotate", "credentials", "--passphrase-file", "/other", "--new-passphrase-file", PASSPHRASE, CREDS)).To(MatchError("secret does not match encrypted credentials"))
+	})
+
+	It("requires new secret", func() {
+		ExpectError(env.Execute("rotate", "credentials", "--passphrase-file", PASSPHRASE, CREDS)).To(MatchError("one of --new-key-file or --new-passphrase-file required"))
+	})
+})
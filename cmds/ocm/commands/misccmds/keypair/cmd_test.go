@@ -0,0 +1,98 @@
+package keypair_test
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/pem"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	"github.com/opencontainers/go-digest"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/ecdsa"
+	"ocm.software/ocm/api/tech/signing/handlers/ed25519"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/tech/signing/signutils"
+)
+
+var ISSUER = &pkix.Name{CommonName: "mandelsoft"}
+
+var _ = Describe("Test Environment", func() {
+	var env *TestEnv
+	var defaultContext credentials.Context
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+		defaultContext = credentials.New()
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	DescribeTable("create key pair", func(algo string, handler signing.SignatureHandler, privType string) {
+		buf := bytes.NewBuffer(nil)
+		Expect(env.CatchOutput(buf).Execute("create", "keypair", "--algorithm", algo)).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+created ` + algo + ` key pair key.priv[key.pub]
+`))
+		priv := Must(env.ReadFile("key.priv"))
+		block, _ := pem.Decode(priv)
+		Expect(block.Type).To(Equal(privType))
+		pub := Must(env.ReadFile("key.pub"))
+
+		sctx := &signing.DefaultSigningContext{
+			PrivateKey: priv,
+			PublicKey:  pub,
+		}
+		d := digest.FromBytes([]byte("digest"))
+		sig := Must(handler.Sign(defaultContext, d.Hex(), sctx))
+		Expect(sig.Algorithm).To(Equal(algo))
+
+		MustBeSuccessful(handler.Verify(d.Hex(), sig, &signing.DefaultSigningContext{PublicKey: pub}))
+	},
+		Entry("rsa", rsa.Algorithm, rsa.NewHandler(), "RSA PRIVATE KEY"),
+		Entry("ecdsa P-256", ecdsa.AlgorithmP256, ecdsa.NewHandler(), "EC PRIVATE KEY"),
+		Entry("ecdsa P-384", ecdsa.AlgorithmP384, ecdsa.NewHandlerFor(ecdsa.P384), "EC PRIVATE KEY"),
+		Entry("ed25519", ed25519.Algorithm, ed25519.NewHandler(), "PRIVATE KEY"),
+	)
+
+	It("creates certificate chain", func() {
+		buf := bytes.NewBuffer(nil)
+
+		Expect(env.CatchOutput(buf).Execute("create", "keypair", "-S", ecdsa.AlgorithmP256, "--ca", "CN=acme.org", "root.priv")).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+created ECDSA-P256 key pair root.priv[root.cert]
+`))
+		buf.Reset()
+		Expect(env.CatchOutput(buf).Execute("create", "keypair", "-S", ecdsa.AlgorithmP256, "CN=mandelsoft", "--ca-key", "root.priv", "--ca-cert", "root.cert", "key.priv")).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+created ECDSA-P256 key pair key.priv[key.cert]
+`))
+		root := Must(env.ReadFile("root.cert"))
+		certs := Must(env.ReadFile("key.cert"))
+		chain := Must(signutils.GetCertificateChain(certs, false))
+		Expect(len(chain)).To(Equal(2))
+		MustBeSuccessful(signing.VerifyCertDN(chain[1:], root, ISSUER, chain[0]))
+
+		sctx := &signing.DefaultSigningContext{
+			PrivateKey: Must(env.ReadFile("key.priv")),
+			PublicKey:  certs,
+			RootCerts:  root,
+			Issuer:     ISSUER,
+		}
+		d := digest.FromBytes([]byte("digest"))
+		sig := Must(ecdsa.NewHandler().Sign(defaultContext, d.Hex(), sctx))
+		Expect(sig.MediaType).To(Equal(signutils.MediaTypePEM))
+		Expect(sig.Issuer).To(Equal("CN=mandelsoft"))
+	})
+
+	It("rejects unknown algorithm", func() {
+		ExpectError(env.Execute("create", "keypair", "-S", "unknown")).To(MatchError(`signing algorithm "unknown" is unknown`))
+	})
+})
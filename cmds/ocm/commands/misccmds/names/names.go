@@ -3,6 +3,7 @@ package names
 var (
 	Hash        = []string{"hash"}
 	RSAKeyPair  = []string{"rsakeypair", "rsa"}
+	KeyPair     = []string{"keypair", "kp"}
 	Credentials = []string{"credentials", "creds", "cred"}
 	Config      = []string{"config", "cfg"}
 )
@@ -4,6 +4,7 @@ import (
 	"github.com/spf13/cobra"
 
 	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/cmds/ocm/commands/ocicmds/ctf/compact"
 	"ocm.software/ocm/cmds/ocm/commands/ocicmds/ctf/create"
 	"ocm.software/ocm/cmds/ocm/commands/ocicmds/names"
 	"ocm.software/ocm/cmds/ocm/common/utils"
@@ -17,5 +18,6 @@ func NewCommand(ctx clictx.Context) *cobra.Command {
 		Short: "Commands acting on OCI view of a Common Transport Archive",
 	}, Names...)
 	cmd.AddCommand(create.NewCommand(ctx, create.Verb))
+	cmd.AddCommand(compact.NewCommand(ctx, compact.Verb))
 	return cmd
 }
@@ -0,0 +1,92 @@
+package compact
+
+import (
+	"github.com/spf13/cobra"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/oci/extensions/repositories/ctf"
+	"ocm.software/ocm/api/utils/accessobj"
+	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/cmds/ocm/commands/ocicmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/dryrunoption"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.TransportArchive
+	Verb  = verbs.Compact
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	Path string
+}
+
+// NewCommand creates a new ctf compaction command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx, dryrunoption.New("only report the unreferenced blobs", false))}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] <path>",
+		Args:  cobra.ExactArgs(1),
+		Short: "remove unreferenced blobs from a transport archive",
+		Long: `
+Remove all blobs from an OCM/OCI transport archive, which are not referenced
+anymore by any artifact listed in the archive index. Such blobs are left over
+if artifacts or component versions are deleted or overwritten.
+The archive might be either a directory or a tar/tgz file, which is rewritten
+after the compaction.
+`,
+		Example: `
+$ ocm compact ctf ctf.tgz
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) Complete(args []string) error {
+	o.Path = args[0]
+	return nil
+}
+
+func (o *Command) Run() error {
+	dryrun := dryrunoption.From(o).DryRun
+
+	acc := accessobj.ACC_WRITABLE
+	if dryrun {
+		acc = accessobj.ACC_READONLY
+	}
+	repo, err := ctf.Open(o.Context.OCIContext(), acc, o.Path, 0, o.Context.FileSystem())
+	if err != nil {
+		return err
+	}
+
+	var result *ctf.CompactionResult
+	if dryrun {
+		result, err = repo.UnreferencedBlobs()
+	} else {
+		result, err = repo.Compact()
+	}
+	if err != nil {
+		repo.Close()
+		return err
+	}
+	err = repo.Close()
+	if err != nil {
+		return err
+	}
+
+	action := "removed"
+	if dryrun {
+		action = "unreferenced"
+	}
+	for _, b := range result.Blobs {
+		out.Outf(o.Context, "%s blob %s\n", action, b)
+	}
+	out.Outf(o.Context, "%d blob(s) %s (%d bytes)\n", len(result.Blobs), action, result.Size)
+	return nil
+}
@@ -0,0 +1,73 @@
+package compact_test
+
+import (
+	"bytes"
+	"fmt"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/api/oci/testhelper"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/oci/extensions/repositories/ctf"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+	"ocm.software/ocm/api/utils/blobaccess"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const ARCH = "/tmp/ctf"
+
+var _ = Describe("Test Environment", func() {
+	var env *TestEnv
+
+	garbage := blobaccess.ForString("", "garbage")
+	GARBAGE := common.DigestToFileName(garbage.Digest())
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+
+		env.OCICommonTransport(ARCH, accessio.FormatDirectory, func() {
+			OCIManifest1(env.Builder)
+		})
+
+		repo := Must(ctf.Open(env, accessobj.ACC_WRITABLE, ARCH, 0, env))
+		ns := Must(repo.LookupNamespace(OCINAMESPACE))
+		MustBeSuccessful(ns.AddBlob(garbage))
+		MustBeSuccessful(ns.Close())
+		MustBeSuccessful(repo.Close())
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("reports unreferenced blobs", func() {
+		buf := bytes.NewBuffer(nil)
+		MustBeSuccessful(env.CatchOutput(buf).Execute("compact", "ctf", "--dry-run", ARCH))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(fmt.Sprintf(`
+unreferenced blob %s
+1 blob(s) unreferenced (7 bytes)
+`, GARBAGE)))
+		Expect(vfs.FileExists(env, ARCH+"/"+ctf.BlobsDirectoryName+"/"+GARBAGE)).To(BeTrue())
+	})
+
+	It("removes unreferenced blobs", func() {
+		buf := bytes.NewBuffer(nil)
+		MustBeSuccessful(env.CatchOutput(buf).Execute("oci", "ctf", "compact", ARCH))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(fmt.Sprintf(`
+removed blob %s
+1 blob(s) removed (7 bytes)
+`, GARBAGE)))
+		Expect(vfs.FileExists(env, ARCH+"/"+ctf.BlobsDirectoryName+"/"+GARBAGE)).To(BeFalse())
+
+		buf.Reset()
+		MustBeSuccessful(env.CatchOutput(buf).Execute("compact", "ctf", ARCH))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+0 blob(s) removed (0 bytes)
+`))
+	})
+})
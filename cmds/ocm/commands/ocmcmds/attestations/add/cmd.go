@@ -0,0 +1,117 @@
+package add
+
+import (
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/resolvers"
+	ocmsign "ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/cmds/ocm/commands/common/options/keyoption"
+	ocmcommon "ocm.software/ocm/cmds/ocm/commands/ocmcmds/common"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/lookupoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/repooption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.Attestations
+	Verb  = verbs.Add
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	Keys keyoption.Option
+
+	Files []string
+}
+
+// NewCommand creates a new attestation add command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx, repooption.New(), lookupoption.New())}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] {<attestation-file>}",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "add the signatures of in-toto attestations to component versions",
+		Long: `
+Add the OCM signatures contained in signature attestations (see
+<CMD>ocm create attestation</CMD>) to the component versions in the
+repository given by option <code>--repo</code>.
+
+The attestations are verified like with <CMD>ocm verify attestation</CMD>.
+The digest of a component version is recalculated with the algorithms used
+for the signature and must still match the attested digest, otherwise
+the component version has been modified after the signature has been created.
+` + keyoption.Usage(),
+		Example: `
+$ ocm add attestations --repo ghcr.io/acme --public-key acme=acme.pub attestation.json
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(fs *pflag.FlagSet) {
+	o.BaseCommand.AddFlags(fs)
+	o.Keys.AddFlags(fs)
+}
+
+func (o *Command) Complete(args []string) error {
+	o.Files = args
+	if repooption.From(o).Spec == "" {
+		return errors.Newf("repository required (option --repo)")
+	}
+	return o.Keys.Configure(o.OCMContext())
+}
+
+func (o *Command) Run() (rerr error) {
+	session := ocm.NewSession(nil)
+	defer errors.PropagateError(&rerr, session.Close)
+
+	err := o.ProcessOnOptions(ocmcommon.CompleteOptionsWithSession(o, session))
+	if err != nil {
+		return err
+	}
+
+	repo := repooption.From(o).Repository
+	resolver := resolvers.NewCompoundResolver(repo, lookupoption.From(o).Resolver)
+
+	list := errors.ErrListf("adding attestations")
+	for _, f := range o.Files {
+		err := o.add(session, repo, resolver, f)
+		if err != nil {
+			out.Outf(o, "failed adding attestation %s: %s\n", f, err)
+		}
+		list.Add(errors.Wrapf(err, "%s", f))
+	}
+	return list.Result()
+}
+
+func (o *Command) add(session ocm.Session, repo ocm.Repository, resolver ocm.ComponentVersionResolver, file string) error {
+	env, err := ocmsign.LoadAttestation(file, o.FileSystem())
+	if err != nil {
+		return err
+	}
+	st, err := ocmsign.ParseStatement(env)
+	if err != nil {
+		return err
+	}
+	cv, err := session.LookupComponentVersion(repo, st.GetName(), st.GetVersion())
+	if err != nil {
+		return err
+	}
+	err = ocmsign.ImportAttestation(cv, env, ocmsign.Resolver(resolver), &o.Keys)
+	if err != nil {
+		return err
+	}
+	out.Outf(o, "added signature %s to %s:%s\n", st.Predicate.Signature.Name, st.GetName(), st.GetVersion())
+	return nil
+}
@@ -0,0 +1,106 @@
+package add_test
+
+import (
+	"bytes"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+	"ocm.software/ocm/api/utils/mime"
+)
+
+const (
+	ARCH        = "/tmp/ctf"
+	PROVIDER    = "mandelsoft"
+	VERSION     = "v1"
+	COMPONENT   = "github.com/mandelsoft/test"
+	ATTESTATION = "/tmp/attestation.json"
+)
+
+var _ = Describe("attestations", func() {
+	var env *TestEnv
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+		for _, n := range []string{"alice", "bob"} {
+			priv, pub := Must2(rsa.Handler{}.CreateKeyPair())
+			MustBeSuccessful(vfs.WriteFile(env.FileSystem(), "/tmp/"+n+".priv", Must(rsa.KeyData(priv)), 0o600))
+			MustBeSuccessful(vfs.WriteFile(env.FileSystem(), "/tmp/"+n+".pub", Must(rsa.KeyData(pub)), 0o600))
+		}
+
+		env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+			env.Component(COMPONENT, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+					env.Resource("testdata", "", "PlainText", metav1.LocalRelation, func() {
+						env.BlobStringData(mime.MIME_TEXT, "testdata")
+					})
+				})
+			})
+		})
+		MustBeSuccessful(env.Execute("sign", "componentversions", "--repo", ARCH, "-s", "alice", "--private-key", "/tmp/alice.priv", COMPONENT+":"+VERSION))
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("creates, verifies and adds an attestation", func() {
+		buf := bytes.NewBuffer(nil)
+		MustBeSuccessful(env.CatchOutput(buf).Execute("create", "attestation", "--repo", ARCH, "-s", "alice", "--private-key", "/tmp/alice.priv", "-O", ATTESTATION, COMPONENT+":"+VERSION))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`attestation for signature "alice" of github.com/mandelsoft/test:v1 written to /tmp/attestation.json`))
+
+		e := Must(signing.LoadAttestation(ATTESTATION, env.FileSystem()))
+		Expect(e.PayloadType).To(Equal(signing.InTotoPayloadType))
+		st := Must(signing.ParseStatement(e))
+		Expect(st.Subject).To(HaveLen(2))
+
+		buf.Reset()
+		MustBeSuccessful(env.CatchOutput(buf).Execute("verify", "attestation", "--repo", ARCH, "--public-key", "alice=/tmp/alice.pub", ATTESTATION))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`attestation /tmp/attestation.json verified for github.com/mandelsoft/test:v1 (signature alice)`))
+
+		buf.Reset()
+		ExpectError(env.CatchOutput(buf).Execute("verify", "attestation", "--public-key", "alice=/tmp/bob.pub", ATTESTATION)).To(HaveOccurred())
+		Expect(buf.String()).To(ContainSubstring("failed verifying /tmp/attestation.json"))
+
+		repo := Must(ctf.Open(env, accessobj.ACC_WRITABLE, ARCH, 0, env))
+		cv := Must(repo.LookupComponentVersion(COMPONENT, VERSION))
+		cv.GetDescriptor().Signatures = nil
+		MustBeSuccessful(cv.Update())
+		Close(cv, "version")
+		Close(repo, "repo")
+
+		buf.Reset()
+		MustBeSuccessful(env.CatchOutput(buf).Execute("add", "attestation", "--repo", ARCH, "--public-key", "alice=/tmp/alice.pub", ATTESTATION))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`added signature alice to github.com/mandelsoft/test:v1`))
+
+		buf.Reset()
+		MustBeSuccessful(env.CatchOutput(buf).Execute("verify", "componentversions", "--repo", ARCH, "-s", "alice", "--public-key", "alice=/tmp/alice.pub", COMPONENT+":"+VERSION))
+		Expect(buf.String()).To(ContainSubstring(`successfully verified github.com/mandelsoft/test:v1`))
+	})
+
+	It("rejects attestations for modified component version", func() {
+		MustBeSuccessful(env.Execute("create", "attestation", "--repo", ARCH, "-s", "alice", "--private-key", "/tmp/alice.priv", "-O", ATTESTATION, COMPONENT+":"+VERSION))
+
+		repo := Must(ctf.Open(env, accessobj.ACC_WRITABLE, ARCH, 0, env))
+		cv := Must(repo.LookupComponentVersion(COMPONENT, VERSION))
+		MustBeSuccessful(cv.GetDescriptor().Labels.Set("modified", true, metav1.WithSigning()))
+		MustBeSuccessful(cv.Update())
+		Close(cv, "version")
+		Close(repo, "repo")
+
+		MustBeSuccessful(env.Execute("verify", "attestation", "--public-key", "alice=/tmp/alice.pub", ATTESTATION))
+		ExpectError(env.Execute("verify", "attestation", "--repo", ARCH, "--public-key", "alice=/tmp/alice.pub", ATTESTATION)).To(MatchError(ContainSubstring("component version github.com/mandelsoft/test:v1 has been modified")))
+		ExpectError(env.Execute("add", "attestation", "--repo", ARCH, "--public-key", "alice=/tmp/alice.pub", ATTESTATION)).To(MatchError(ContainSubstring("component version github.com/mandelsoft/test:v1 has been modified")))
+	})
+})
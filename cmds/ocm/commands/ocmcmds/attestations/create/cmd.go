@@ -0,0 +1,163 @@
+package create
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/ocm"
+	ocmsign "ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/tech/signing/signutils"
+	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/cmds/ocm/commands/common/options/keyoption"
+	ocmcommon "ocm.software/ocm/cmds/ocm/commands/ocmcmds/common"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/handlers/comphdlr"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/repooption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/output"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.Attestations
+	Verb  = verbs.Create
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	Keys keyoption.Option
+
+	Ref       string
+	Signature string
+	OutFile   string
+}
+
+// NewCommand creates a new attestation creation command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx, repooption.New())}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] <component-reference>",
+		Args:  cobra.ExactArgs(1),
+		Short: "export a component version signature as in-toto attestation",
+		Long: `
+Export a signature of a component version as in-toto statement wrapped into
+a DSSE envelope (see https://github.com/secure-systems-lab/dsse). This format
+can be consumed by tools of the in-toto and sigstore ecosystem.
+
+The statement uses the predicate type
+<code>` + ocmsign.SignaturePredicateType + `</code>.
+Its subjects are the component version with the signed digest of the
+normalized component descriptor and all resources with their digests.
+The predicate contains the complete OCM signature.
+
+The signature to export is selected with option <code>--signature</code>.
+The digest of the component version is recalculated and must still match the
+signed digest. The envelope is signed with the private key given for the
+signature name, using the algorithm of the exported signature.
+
+By default, the envelope is written to standard output. With option
+<code>--outfile</code> a file can be given. It can be verified with
+<CMD>ocm verify attestation</CMD> and imported into a component version
+with <CMD>ocm add attestation</CMD>.
+` + keyoption.Usage(),
+		Example: `
+$ ocm create attestation --signature acme --private-key acme.key --outfile attestation.json ghcr.io/acme//acme.org/app:1.0.0
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(fs *pflag.FlagSet) {
+	o.BaseCommand.AddFlags(fs)
+	o.Keys.AddFlags(fs)
+	fs.StringVarP(&o.Signature, "signature", "s", "", "signature name")
+	fs.StringVarP(&o.OutFile, "outfile", "O", "", "output file for attestation")
+}
+
+func (o *Command) Complete(args []string) error {
+	o.Ref = args[0]
+	n := strings.TrimSpace(o.Signature)
+	if n == "" {
+		return errors.Newf("signature name required (option --signature)")
+	}
+	dn, err := signutils.ParseDN(n)
+	if err != nil {
+		return err
+	}
+	o.Signature = signutils.NormalizeDN(*dn)
+	o.Keys.DefaultName = o.Signature
+	return o.Keys.Configure(o.OCMContext())
+}
+
+func (o *Command) Run() (err error) {
+	session := ocm.NewSession(nil)
+	defer errors.PropagateError(&err, session.Close)
+
+	err = o.ProcessOnOptions(ocmcommon.CompleteOptionsWithSession(o, session))
+	if err != nil {
+		return err
+	}
+
+	handler := comphdlr.NewTypeHandler(o.Context.OCM(), session, repooption.From(o).Repository)
+	return utils.HandleOutput(&action{cmd: o}, handler, utils.StringElemSpecs(o.Ref)...)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type action struct {
+	cmd  *Command
+	data comphdlr.Objects
+}
+
+var _ output.Output = (*action)(nil)
+
+func (a *action) Add(e interface{}) error {
+	o, ok := e.(*comphdlr.Object)
+	if !ok {
+		return fmt.Errorf("object of type %T is not a valid comphdlr.Object", e)
+	}
+	if o.ComponentVersion == nil {
+		return errors.ErrNotFound(ocm.KIND_COMPONENTVERSION, o.Spec.String())
+	}
+	a.data = append(a.data, o)
+	return nil
+}
+
+func (a *action) Close() error {
+	return nil
+}
+
+func (a *action) Out() error {
+	if len(a.data) != 1 {
+		return fmt.Errorf("exactly one component version required, but %d found", len(a.data))
+	}
+	cv := a.data[0].ComponentVersion
+	env, err := ocmsign.CreateAttestation(cv, a.cmd.Signature, &a.cmd.Keys)
+	if err != nil {
+		return err
+	}
+	if a.cmd.OutFile != "" {
+		err = ocmsign.SaveAttestation(env, a.cmd.OutFile, a.cmd.FileSystem())
+		if err != nil {
+			return err
+		}
+		out.Outf(a.cmd, "attestation for signature %q of %s:%s written to %s\n", a.cmd.Signature, cv.GetName(), cv.GetVersion(), a.cmd.OutFile)
+		return nil
+	}
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+	out.Outf(a.cmd, "%s\n", string(data))
+	return nil
+}
@@ -0,0 +1,121 @@
+package verify
+
+import (
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/ocm"
+	ocmsign "ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/cmds/ocm/commands/common/options/keyoption"
+	ocmcommon "ocm.software/ocm/cmds/ocm/commands/ocmcmds/common"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/repooption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.Attestations
+	Verb  = verbs.Verify
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	Keys keyoption.Option
+
+	Files []string
+}
+
+// NewCommand creates a new attestation verification command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx, repooption.New())}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] {<attestation-file>}",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "verify in-toto signature attestations",
+		Long: `
+Verify signature attestations created with <CMD>ocm create attestation</CMD>.
+The DSSE envelope and the contained OCM signature are verified, either with
+public keys given by option <code>--public-key</code> for the signature name
+or with the certificate provided by the signature.
+
+If a repository is given with option <code>--repo</code>, the attested
+component version is additionally checked against the statement. Its digest
+is recalculated and the resource digests must match the attested ones.
+` + keyoption.Usage(),
+		Example: `
+$ ocm verify attestation --public-key acme=acme.pub --repo ghcr.io/acme attestation.json
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(fs *pflag.FlagSet) {
+	o.BaseCommand.AddFlags(fs)
+	o.Keys.AddFlags(fs)
+}
+
+func (o *Command) Complete(args []string) error {
+	o.Files = args
+	return o.Keys.Configure(o.OCMContext())
+}
+
+func (o *Command) Run() (rerr error) {
+	session := ocm.NewSession(nil)
+	defer errors.PropagateError(&rerr, session.Close)
+
+	err := o.ProcessOnOptions(ocmcommon.CompleteOptionsWithSession(o, session))
+	if err != nil {
+		return err
+	}
+
+	repo := repooption.From(o).Repository
+
+	list := errors.ErrListf("verifying attestations")
+	for _, f := range o.Files {
+		err := o.verify(session, repo, f)
+		if err != nil {
+			out.Outf(o, "failed verifying %s: %s\n", f, err)
+		}
+		list.Add(errors.Wrapf(err, "%s", f))
+	}
+	return list.Result()
+}
+
+func (o *Command) verify(session ocm.Session, repo ocm.Repository, file string) error {
+	env, err := ocmsign.LoadAttestation(file, o.FileSystem())
+	if err != nil {
+		return err
+	}
+	st, err := ocmsign.VerifyAttestation(o.OCMContext(), env, &o.Keys)
+	if err != nil {
+		return err
+	}
+	var keys []string
+	for _, s := range env.Signatures {
+		keys = append(keys, s.KeyID)
+	}
+	if repo == nil {
+		out.Outf(o, "attestation %s for %s:%s verified (signature %s)\n", file, st.GetName(), st.GetVersion(), strings.Join(keys, ", "))
+		return nil
+	}
+	cv, err := session.LookupComponentVersion(repo, st.GetName(), st.GetVersion())
+	if err != nil {
+		return err
+	}
+	err = ocmsign.CheckAttestation(cv, st, &o.Keys)
+	if err != nil {
+		return err
+	}
+	out.Outf(o, "attestation %s verified for %s:%s (signature %s)\n", file, st.GetName(), st.GetVersion(), strings.Join(keys, ", "))
+	return nil
+}
@@ -9,11 +9,13 @@ import (
 	"github.com/mandelsoft/goutils/sliceutils"
 
 	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/datacontext/attrs/progressattr"
 	"ocm.software/ocm/api/ocm"
 	"ocm.software/ocm/api/ocm/tools/transfer"
 	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler"
 	common "ocm.software/ocm/api/utils/misc"
 	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/api/utils/progress"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/addhdlrs"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/inputs"
 )
@@ -48,7 +50,10 @@ func ProcessComponents(ctx clictx.Context, ictx inputs.Context, repo ocm.Reposit
 
 	for _, elem := range elems {
 		loop := finalize.Nested()
+		tracker := progressattr.For(ctx.OCMContext(), progress.OPERATION_ADD, progress.KIND_COMPONENTVERSION, "", elementName(elem))
+		tracker.Started()
 		err := h.Add(ctx, ictx.Section("adding %s...", elem.Spec().Info()), elem, repo)
+		tracker.Finished(err)
 		if err != nil {
 			return errors.Wrapf(err, "failed adding component %q(%s)", elem.Spec().GetName(), elem.Source())
 		}
@@ -96,3 +101,10 @@ func ProcessComponents(ctx clictx.Context, ictx inputs.Context, repo ocm.Reposit
 	}
 	return nil
 }
+
+func elementName(elem addhdlrs.Element) string {
+	if r, ok := elem.Spec().(*ResourceSpec); ok {
+		return common.NewNameVersion(r.Name, r.Version).String()
+	}
+	return elem.Spec().GetName()
+}
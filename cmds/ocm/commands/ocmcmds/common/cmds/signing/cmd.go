@@ -18,7 +18,9 @@ import (
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/lookupoption"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/repooption"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/signoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/trustpolicyoption"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/versionconstraintsoption"
+	"ocm.software/ocm/cmds/ocm/common/options"
 	"ocm.software/ocm/cmds/ocm/common/output"
 	"ocm.software/ocm/cmds/ocm/common/utils"
 )
@@ -50,7 +52,11 @@ func newOperation(op string, sign bool, terms []string, desc string, example str
 // NewCommand creates a new ctf command.
 func NewCommand(ctx clictx.Context, op string, sign bool, terms []string, desc string, example string, names ...string) *cobra.Command {
 	spec := newOperation(op, sign, terms, desc, example)
-	return utils.SetupCommand(&SignatureCommand{spec: spec, BaseCommand: utils.NewBaseCommand(ctx, versionconstraintsoption.New(), repooption.New(), signoption.New(sign), lookupoption.New())}, names...)
+	opts := []options.Options{versionconstraintsoption.New(), repooption.New(), signoption.New(sign), lookupoption.New()}
+	if !sign {
+		opts = append(opts, trustpolicyoption.New())
+	}
+	return utils.SetupCommand(&SignatureCommand{spec: spec, BaseCommand: utils.NewBaseCommand(ctx, opts...)}, names...)
 }
 
 func (o *SignatureCommand) ForName(name string) *cobra.Command {
@@ -89,6 +95,7 @@ func (o *SignatureCommand) Run() (rerr error) {
 	handler := comphdlr.NewTypeHandler(o.Context.OCM(), session, repo, comphdlr.OptionsFor(o))
 	sopts := signing.NewOptions(sign, signing.Resolver(repo, lookup.Resolver))
 	if !o.spec.sign {
+		sopts.Eval(trustpolicyoption.From(o))
 		if len(sopts.SignatureNames) > 0 || sopts.Issuer != nil || sopts.Keyless {
 			sopts.VerifySignature = true
 		}
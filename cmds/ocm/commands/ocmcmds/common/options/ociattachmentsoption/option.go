@@ -0,0 +1,81 @@
+package ociattachmentsoption
+
+import (
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler"
+	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler/standard"
+	"ocm.software/ocm/cmds/ocm/commands/common/options/keyoption"
+	"ocm.software/ocm/cmds/ocm/common/options"
+)
+
+func From(o options.OptionSetProvider) *Option {
+	var opt *Option
+	o.AsOptionSet().Get(&opt)
+	return opt
+}
+
+var _ options.Options = (*Option)(nil)
+
+func New() *Option {
+	return &Option{}
+}
+
+type Option struct {
+	standard.TransferOptionsCreator
+
+	// Attachments enables the transfer of cosign attachments.
+	Attachments bool
+	// Signature is the name of the component version signature
+	// used to sign transferred OCI artifacts.
+	Signature string
+
+	Keys keyoption.Option
+}
+
+func (o *Option) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVarP(&o.Attachments, "copy-oci-attachments", "", false, "transfer cosign attachments and referrers of OCI artifacts copied by-value")
+	fs.StringVarP(&o.Signature, "sign-oci-artifacts", "", "", "sign OCI artifacts copied by-value based on the given component version signature")
+	o.Keys.AddFlags(fs)
+}
+
+func (o *Option) Configure(ctx clictx.Context) error {
+	o.Keys.DefaultName = o.Signature
+	return o.Keys.Configure(ctx.OCMContext())
+}
+
+func (o *Option) Usage() string {
+	s := `
+With option <code>--copy-oci-attachments</code> the cosign signatures,
+attestations and SBOMs stored for OCI artifacts (tags
+<code>sha256-&lt;digest>.sig</code>, <code>.att</code> and <code>.sbom</code>)
+and the artifacts referring to OCI artifacts by their <code>subject</code> field
+(OCI 1.1 referrers) are transferred together with OCI artifacts copied by-value.
+
+With option <code>--sign-oci-artifacts</code> the OCI artifacts copied
+by-value are additionally signed with a cosign compatible signature. The
+given name must be the name of a signature of the transferred component version.
+The private key is taken from option <code>--private-key</code> for this
+name. Before signing, the component version signature is verified (public key
+taken from option <code>--public-key</code> or a certificate provided by the
+signature) and the artifact digest must match the digest of the resource.
+The signed payload refers to the component version and its signature,
+so that admission controllers can verify the images without OCM tooling.
+`
+	return s
+}
+
+var _ transferhandler.TransferOption = (*Option)(nil)
+
+func (o *Option) ApplyTransferOption(opts transferhandler.TransferOptions) error {
+	if o.Attachments {
+		if err := standard.OCIAttachments(true).ApplyTransferOption(opts); err != nil {
+			return err
+		}
+	}
+	if o.Signature != "" {
+		return standard.SignOCIArtifacts(o.Signature, &o.Keys).ApplyTransferOption(opts)
+	}
+	return nil
+}
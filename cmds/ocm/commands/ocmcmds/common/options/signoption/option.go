@@ -1,6 +1,7 @@
 package signoption
 
 import (
+	"crypto/x509"
 	"fmt"
 	"strings"
 
@@ -16,6 +17,7 @@ import (
 	"ocm.software/ocm/api/tech/signing/handlers/rsa"
 	"ocm.software/ocm/api/tech/signing/hasher/sha256"
 	"ocm.software/ocm/api/tech/signing/signutils"
+	"ocm.software/ocm/api/utils"
 	"ocm.software/ocm/api/utils/listformat"
 	"ocm.software/ocm/cmds/ocm/commands/common/options/keyoption"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/hashoption"
@@ -56,10 +58,22 @@ type Option struct {
 	UseTSA bool
 	TSAUrl string
 
+	// TSARootCAs are files with root certificates used to verify
+	// timestamps offline.
+	TSARootCAs   []string
+	TSARootCerts []*x509.Certificate
+	// RequireTimestamp requires signatures based on certificates to be
+	// timestamped before the certificate expired.
+	RequireTimestamp bool
+
 	Hash hashoption.Option
 
 	Keyless bool
 
+	// Threshold is the minimum number of given signatures required
+	// to be verifiable.
+	Threshold int
+
 	Verified storeoption.Option
 }
 
@@ -75,10 +89,13 @@ func (o *Option) AddFlags(fs *pflag.FlagSet) {
 		fs.BoolVarP(&o.Update, "update", "", o.SignMode, "update digest in component versions")
 		fs.BoolVarP(&o.Recursively, "recursive", "R", false, "recursively sign component versions")
 		fs.BoolVarP(&o.UseTSA, "tsa", "", false, fmt.Sprintf("use timestamp authority (default server: %s)", signing.DEFAULT_TSA_URL))
-		fs.StringVarP(&o.TSAUrl, "tsa-url", "", "", "TSA server URL")
+		fs.StringVarP(&o.TSAUrl, "tsa-url", "", "", "TSA server URL, timestamp authority name or file:<path> for a local authority")
 	} else {
 		fs.BoolVarP(&o.local, "local", "L", false, "verification based on information found in component versions, only")
+		fs.IntVarP(&o.Threshold, "threshold", "", 0, "minimum number of given signatures required to be verifiable")
+		fs.BoolVarP(&o.RequireTimestamp, "require-timestamp", "", false, "require certificate based signatures to be timestamped before certificate expiration")
 	}
+	fs.StringArrayVarP(&o.TSARootCAs, "tsa-ca-cert", "", nil, "root certificate authorities for offline timestamp verification")
 	fs.BoolVarP(&o.Verify, "verify", "V", o.SignMode, "verify existing digests")
 	fs.BoolVar(&o.Keyless, "keyless", false, "use keyless signing")
 }
@@ -122,6 +139,19 @@ func (o *Option) Configure(ctx clictx.Context) error {
 		return err
 	}
 
+	o.TSARootCerts = nil
+	for _, r := range o.TSARootCAs {
+		data, err := utils.ReadFile(r, ctx.FileSystem())
+		if err != nil {
+			return errors.Wrapf(err, "TSA root CA")
+		}
+		certs, err := signutils.GetCertificateChain(data, false)
+		if err != nil {
+			return errors.Wrapf(err, "TSA root CA")
+		}
+		o.TSARootCerts = append(o.TSARootCerts, certs...)
+	}
+
 	return o.Verified.Configure(ctx)
 }
 
@@ -142,6 +172,14 @@ start with the prefix <code>!</code> or as direct string with the prefix
 		s += `
 If in signing mode a public key is specified, existing signatures for the
 given signature name will be verified, instead of recreated.
+`
+		s += `
+With option <code>--tsa</code> the signatures are timestamped by a timestamp
+authority. Option <code>--tsa-url</code> selects the authority. Besides the
+URL of an RFC 3161 server, it might be the name of a timestamp authority
+provided by a plugin or <code>file:&lt;path></code> for a local timestamp
+authority described by a PEM file containing its private key and certificate
+chain (for example, for air-gapped environments).
 `
 		s += `
 
@@ -159,7 +197,22 @@ The following hash modes are supported with option <code>--hash</code>:
 ` + listformat.FormatList(sha256.Algorithm, signing.DefaultRegistry().HasherNames()...)
 
 		signing.DefaultRegistry().HasherNames()
+	} else {
+		s += `
+With option <code>--threshold</code> it is possible to require only a minimum
+number of the signatures given with option <code>--signature</code> to be
+present and verifiable (M-of-N verification), instead of all of them.
+
+With option <code>--require-timestamp</code> signatures based on certificates
+must be timestamped and the timestamp must be before the expiration of the
+signing certificate.
+`
 	}
+	s += `
+With option <code>--tsa-ca-cert</code> root certificates for timestamp
+authorities can be given. Timestamps are then verified offline against these
+certificates.
+`
 	return s
 }
 
@@ -200,6 +253,11 @@ func (o *Option) ApplySigningOption(opts *ocmsign.Options) {
 	}
 	opts.Update = o.Update
 	opts.Keyless = o.Keyless
+	opts.Threshold = o.Threshold
+	if len(o.TSARootCerts) > 0 {
+		opts.TSARootCerts = o.TSARootCerts
+	}
+	opts.RequireTimestamp = o.RequireTimestamp
 
 	opts.VerifiedStore = o.Verified.Store
 }
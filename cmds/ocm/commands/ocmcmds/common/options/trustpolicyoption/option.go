@@ -0,0 +1,96 @@
+package trustpolicyoption
+
+import (
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/datacontext/attrs/vfsattr"
+	ocmsign "ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler"
+	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler/standard"
+	"ocm.software/ocm/cmds/ocm/common/options"
+)
+
+func From(o options.OptionSetProvider) *Option {
+	var opt *Option
+	o.AsOptionSet().Get(&opt)
+	return opt
+}
+
+var _ options.Options = (*Option)(nil)
+
+func New() *Option {
+	return &Option{}
+}
+
+type Option struct {
+	standard.TransferOptionsCreator
+
+	// File is the path of the trust policy file.
+	File   string
+	Policy *ocmsign.TrustPolicy
+}
+
+func (o *Option) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&o.File, "trust-policy", "", "", "trust policy file used to validate signatures")
+}
+
+func (o *Option) Configure(ctx clictx.Context) error {
+	var err error
+	if o.Policy == nil && o.File != "" {
+		o.Policy, err = ocmsign.LoadTrustPolicy(o.File, vfsattr.Get(ctx))
+	}
+	return err
+}
+
+func (o *Option) Usage() string {
+	s := `
+With option <code>--trust-policy</code> a trust policy file can be given,
+which describes the signatures required for component versions.
+It is a YAML document with a list of rules. Every rule applies to the
+component versions whose component name matches one of its glob patterns
+(<code>*</code> matches any character sequence, including <code>/</code>).
+A component version must fulfill all rules applying to it. If
+<code>strict</code> is set, component versions not covered by any rule are
+rejected.
+
+<center>
+    <pre>
+    strict: false
+    rules:
+      - name: acme
+        components:
+          - acme.org/*
+        signatures:              # all listed signatures are required
+          - acme
+        issuers:                 # allowed certificate subjects
+          - CN=acme,O=Acme Inc.
+        minSignatures: 1         # minimum number of qualifying signatures
+        requireTimestamp: true   # require a verified TSA timestamp
+        digestAlgorithms:        # allowed digest algorithms
+          - SHA-256
+    </pre>
+</center>
+
+Public keys are taken from the configured keys or from certificates provided
+by the signatures, which are validated against the configured root certificates.
+`
+	return s
+}
+
+var _ ocmsign.Option = (*Option)(nil)
+
+func (o *Option) ApplySigningOption(opts *ocmsign.Options) {
+	if o.Policy != nil {
+		opts.TrustPolicy = o.Policy
+	}
+}
+
+var _ transferhandler.TransferOption = (*Option)(nil)
+
+func (o *Option) ApplyTransferOption(opts transferhandler.TransferOptions) error {
+	if o.Policy != nil {
+		return standard.TrustPolicy(o.Policy).ApplyTransferOption(opts)
+	}
+	return nil
+}
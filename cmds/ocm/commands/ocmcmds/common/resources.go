@@ -15,6 +15,7 @@ import (
 	"golang.org/x/text/language"
 
 	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/datacontext/attrs/progressattr"
 	"ocm.software/ocm/api/ocm"
 	"ocm.software/ocm/api/ocm/compdesc"
 	v1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
@@ -27,6 +28,7 @@ import (
 	"ocm.software/ocm/api/utils/logging"
 	"ocm.software/ocm/api/utils/mime"
 	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/progress"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/addhdlrs"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/inputs"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/dryrunoption"
@@ -493,7 +495,10 @@ func ProcessElements(ictx inputs.Context, cv ocm.ComponentVersionAccess, elems [
 				if iv := elem.Input().Input.GetInputVersion(ictx); iv != "" && !IsVersionSet(elem.Spec().GetVersion()) {
 					elem.Spec().SetVersion(iv)
 				}
-				acc, err = cv.AddBlob(blob, elem.Type(), hint, nil)
+				tracker := progressattr.For(cv.GetContext(), progress.OPERATION_ADD, h.Key(), info.ComponentVersion.String(), info.ElementName)
+				tracker.Started()
+				acc, err = cv.AddBlob(progress.BlobAccess(tracker, blob), elem.Type(), hint, nil)
+				tracker.Finished(err)
 				blob.Close()
 				if err == nil {
 					err = CheckHint(cv, elem, acc)
@@ -0,0 +1,155 @@
+package clean
+
+import (
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/tools/retention"
+	utils2 "ocm.software/ocm/api/utils"
+	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/out"
+	ocmcommon "ocm.software/ocm/cmds/ocm/commands/ocmcmds/common"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/dryrunoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/lookupoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.Components
+	Verb  = verbs.Clean
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	RepoName   string
+	Components []string
+
+	KeepLast   int
+	KeepSigned bool
+	MaxAge     string
+	before     time.Time
+}
+
+// NewCommand creates a new clean command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx, lookupoption.New(), dryrunoption.New("only report the component versions to be deleted", false))}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] <ocm repository> {<component>}",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "delete component versions according to a retention policy",
+		Long: `
+Delete component versions from an OCM repository according to a retention
+policy. If no component is given, all components of the repository are
+cleaned.
+
+A component version is deleted, if it is expired according to all
+configured expiration rules:
+- <code>--keep-last</code>: it is not one of the latest versions of its component (in
+  semver order).
+- <code>--max-age</code>: it has been created before the given time span. Versions
+  without creation time never expire.
+
+At least one of these rules must be given. Component versions are always retained,
+if they are referenced by a retained component version. All versions of components
+not selected for cleaning are considered as retained. References are followed
+through the lookup repositories, also, to catch references leaving and re-entering
+the cleaned repository. With option <code>--keep-signed</code> signed component
+versions are retained, too.
+
+For OCI registries the registry is responsible to delete artifact blobs not
+used anymore. For *Common Transport Archives* unused blobs are kept in the
+archive.
+
+With option <code>--dry-run</code> the evaluated retention report is printed
+without deleting anything.
+`,
+		Example: `
+$ ocm clean componentversions --keep-last 5 --keep-signed ghcr.io/acme
+$ ocm clean componentversions --max-age 90d --dry-run ./ctf acme.org/app
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(fs *pflag.FlagSet) {
+	o.BaseCommand.AddFlags(fs)
+	fs.IntVarP(&o.KeepLast, "keep-last", "", 0, "number of latest versions retained per component")
+	fs.StringVarP(&o.MaxAge, "max-age", "", "", "expire versions created before the given time span (e.g. 90d)")
+	fs.BoolVarP(&o.KeepSigned, "keep-signed", "", false, "retain signed component versions")
+}
+
+func (o *Command) Complete(args []string) error {
+	o.RepoName = args[0]
+	o.Components = args[1:]
+	if o.MaxAge != "" {
+		t, err := utils2.ParseDeltaTime(o.MaxAge, true)
+		if err != nil {
+			return err
+		}
+		o.before = t
+	}
+	return o.policy().Validate()
+}
+
+func (o *Command) policy() *retention.Policy {
+	return &retention.Policy{
+		KeepLast:      o.KeepLast,
+		ExpiredBefore: o.before,
+		KeepSigned:    o.KeepSigned,
+	}
+}
+
+func (o *Command) Run() (err error) {
+	session := ocm.NewSession(nil)
+	defer errors.PropagateError(&err, session.Close)
+
+	err = o.ProcessOnOptions(ocmcommon.CompleteOptionsWithSession(o, session))
+	if err != nil {
+		return err
+	}
+
+	ref, err := ocm.ParseRepo(o.RepoName)
+	if err != nil {
+		return err
+	}
+	repo, err := session.DetermineRepositoryBySpec(o.Context.OCMContext(), &ref)
+	if err != nil {
+		return err
+	}
+
+	report, err := retention.Evaluate(repo, o.policy(), lookupoption.From(o).Resolver, o.Components...)
+	if err != nil {
+		return err
+	}
+	if dryrunoption.From(o).DryRun {
+		o.print("retained", report.Retained)
+		o.print("to be deleted", report.Deleted)
+		return nil
+	}
+	if len(report.Deleted) == 0 {
+		out.Outf(o.Context, "no component version to delete\n")
+		return nil
+	}
+	return retention.Execute(common.NewPrinter(o.Context.StdOut()), repo, report)
+}
+
+func (o *Command) print(title string, list []*retention.Entry) {
+	if len(list) == 0 {
+		return
+	}
+	out.Outf(o.Context, "%s:\n", title)
+	for _, e := range list {
+		out.Outf(o.Context, "  %s: %s\n", e, e.Reason)
+	}
+}
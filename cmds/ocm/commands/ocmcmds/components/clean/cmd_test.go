@@ -0,0 +1,85 @@
+package clean_test
+
+import (
+	"bytes"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+)
+
+const (
+	ARCH     = "/tmp/ctf"
+	COMP     = "test.de/x"
+	COMP2    = "test.de/y"
+	PROVIDER = "mandelsoft"
+)
+
+var _ = Describe("Test Environment", func() {
+	var env *TestEnv
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+
+		env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+			env.Component(COMP, func() {
+				for _, v := range []string{"1.0.0", "1.1.0", "1.2.0"} {
+					env.Version(v, func() {
+						env.Provider(PROVIDER)
+					})
+				}
+			})
+			env.Component(COMP2, func() {
+				env.Version("1.0.0", func() {
+					env.Provider(PROVIDER)
+					env.Reference("ref", COMP, "1.0.0")
+				})
+			})
+		})
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("reports component versions to delete", func() {
+		buf := bytes.NewBuffer(nil)
+		MustBeSuccessful(env.CatchOutput(buf).Execute("clean", "components", "--keep-last", "1", "--dry-run", ARCH))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+retained:
+  test.de/x:1.2.0: one of the latest 1 versions
+  test.de/x:1.0.0: referenced by test.de/y:1.0.0
+  test.de/y:1.0.0: one of the latest 1 versions
+to be deleted:
+  test.de/x:1.1.0: exceeds version limit 1
+`))
+		repo := Must(ctf.Open(env.OCMContext(), accessobj.ACC_READONLY, ARCH, 0, env))
+		defer Close(repo, "repo")
+		c := Must(repo.LookupComponent(COMP))
+		defer Close(c, "component")
+		Expect(c.ListVersions()).To(ConsistOf("1.0.0", "1.1.0", "1.2.0"))
+	})
+
+	It("deletes component versions", func() {
+		buf := bytes.NewBuffer(nil)
+		MustBeSuccessful(env.CatchOutput(buf).Execute("clean", "components", "--keep-last", "1", ARCH, COMP))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+deleting test.de/x:1.1.0 (exceeds version limit 1)
+`))
+		repo := Must(ctf.Open(env.OCMContext(), accessobj.ACC_READONLY, ARCH, 0, env))
+		defer Close(repo, "repo")
+		c := Must(repo.LookupComponent(COMP))
+		defer Close(c, "component")
+		Expect(c.ListVersions()).To(ConsistOf("1.0.0", "1.2.0"))
+	})
+
+	It("requires a retention rule", func() {
+		buf := bytes.NewBuffer(nil)
+		ExpectError(env.CatchOutput(buf).Execute("clean", "components", "--keep-signed", ARCH)).To(MatchError("retention policy requires a version limit or an expiration time"))
+	})
+})
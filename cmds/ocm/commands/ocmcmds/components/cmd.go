@@ -6,10 +6,13 @@ import (
 	clictx "ocm.software/ocm/api/cli"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/add"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/check"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/clean"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/diff"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/download"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/get"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/hash"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/list"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/resign"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/sign"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/transfer"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/verify"
@@ -34,8 +37,11 @@ func AddCommands(ctx clictx.Context, cmd *cobra.Command) {
 	cmd.AddCommand(list.NewCommand(ctx, list.Verb))
 	cmd.AddCommand(hash.NewCommand(ctx, hash.Verb))
 	cmd.AddCommand(sign.NewCommand(ctx, sign.Verb))
+	cmd.AddCommand(resign.NewCommand(ctx, resign.Verb))
 	cmd.AddCommand(transfer.NewCommand(ctx, transfer.Verb))
 	cmd.AddCommand(verify.NewCommand(ctx, verify.Verb))
 	cmd.AddCommand(download.NewCommand(ctx, download.Verb))
 	cmd.AddCommand(check.NewCommand(ctx, check.Verb))
+	cmd.AddCommand(diff.NewCommand(ctx, diff.Verb))
+	cmd.AddCommand(clean.NewCommand(ctx, clean.Verb))
 }
@@ -0,0 +1,167 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/compdesc"
+	"ocm.software/ocm/api/ocm/tools/diff"
+	"ocm.software/ocm/api/ocm/tools/signing"
+	common "ocm.software/ocm/api/utils/misc"
+	ocmcommon "ocm.software/ocm/cmds/ocm/commands/ocmcmds/common"
+	signingcmd "ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/cmds/signing"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/handlers/comphdlr"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/hashoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/lookupoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/repooption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/output"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.Components
+	Verb  = verbs.Diff
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	Refs   []string
+	Actual bool
+	Format string
+}
+
+// NewCommand creates a new diff command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx, repooption.New(), lookupoption.New(), hashoption.New())}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] <component-reference> <component-reference>",
+		Args:  cobra.ExactArgs(2),
+		Short: "compare two component versions",
+		Long: `
+Compare two component versions, which may be located in different
+repositories. Resources, sources and references are matched by their
+element identity. Added, removed and changed elements are reported, as well
+as changed labels and signatures. For resources, a changed access specification
+is distinguished from a changed content digest.
+
+If the option <code>--actual</code> is given the component descriptors actually
+found are compared as they are, otherwise missing digests are calculated on-the-fly.
+
+With option <code>--output</code> the report format can be selected. Possible
+formats are <code>text</code> (default), <code>json</code> and
+<code>markdown</code>, which is suitable for release notes.
+`,
+		Example: `
+$ ocm diff componentversion ghcr.io/acme//acme.org/app:1.0.0 ghcr.io/acme//acme.org/app:1.1.0
+$ ocm diff componentversion --repo OCIRegistry::ghcr.io/acme -o markdown acme.org/app:1.0.0 acme.org/app:1.1.0
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(fs *pflag.FlagSet) {
+	o.BaseCommand.AddFlags(fs)
+	fs.BoolVarP(&o.Actual, "actual", "", false, "use actual component descriptors")
+	fs.StringVarP(&o.Format, "output", "o", diff.FORMAT_TEXT, fmt.Sprintf("output format (%s)", strings.Join(diff.Formats, ", ")))
+}
+
+func (o *Command) Complete(args []string) error {
+	o.Refs = args
+	for _, f := range diff.Formats {
+		if f == o.Format {
+			return nil
+		}
+	}
+	return errors.ErrInvalid("output format", o.Format)
+}
+
+func (o *Command) Run() (err error) {
+	session := ocm.NewSession(nil)
+	defer errors.PropagateError(&err, session.Close)
+
+	err = o.ProcessOnOptions(ocmcommon.CompleteOptionsWithSession(o, session))
+	if err != nil {
+		return err
+	}
+
+	a := &action{cmd: o}
+	handler := comphdlr.NewTypeHandler(o.Context.OCM(), session, repooption.From(o).Repository)
+	return utils.HandleOutput(a, handler, utils.StringElemSpecs(o.Refs...)...)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type action struct {
+	cmd  *Command
+	data comphdlr.Objects
+}
+
+var _ output.Output = (*action)(nil)
+
+func (a *action) Add(e interface{}) error {
+	o, ok := e.(*comphdlr.Object)
+	if !ok {
+		return fmt.Errorf("object of type %T is not a valid comphdlr.Object", e)
+	}
+	if o.ComponentVersion == nil {
+		return errors.ErrNotFound(ocm.KIND_COMPONENTVERSION, o.Spec.String())
+	}
+	a.data = append(a.data, o)
+	return nil
+}
+
+func (a *action) Close() error {
+	return nil
+}
+
+func (a *action) Out() error {
+	if len(a.data) != 2 {
+		return fmt.Errorf("exactly two component versions required, but %d found", len(a.data))
+	}
+	var cds [2]*compdesc.ComponentDescriptor
+	for i, o := range a.data {
+		cd, err := a.descriptor(o)
+		if err != nil {
+			return errors.Wrapf(err, "%s", common.VersionedElementKey(o.ComponentVersion))
+		}
+		cds[i] = cd
+	}
+	return diff.Write(a.cmd.StdOut(), diff.Compare(cds[0], cds[1]), a.cmd.Format)
+}
+
+// descriptor provides the component descriptor used for the comparison.
+// Digests are calculated in a separate walking state for every component
+// version, because both versions may have the same name in different
+// repositories.
+func (a *action) descriptor(o *comphdlr.Object) (*compdesc.ComponentDescriptor, error) {
+	if a.cmd.Actual {
+		return o.ComponentVersion.GetDescriptor(), nil
+	}
+	repo := repooption.From(a.cmd).Repository
+	lookup := lookupoption.From(a.cmd)
+	sopts := signing.NewOptions(hashoption.From(a.cmd), signing.Resolver(repo, lookup.Resolver))
+	err := sopts.Complete(a.cmd.Context.OCMContext())
+	if err != nil {
+		return nil, err
+	}
+	_, cd, err := signingcmd.NewAction([]string{"", ""}, a.cmd.Context.OCMContext(), common.NewPrinter(nil), sopts).Digest(o)
+	if err != nil {
+		return nil, err
+	}
+	if cd == nil {
+		return o.ComponentVersion.GetDescriptor(), nil
+	}
+	return cd, nil
+}
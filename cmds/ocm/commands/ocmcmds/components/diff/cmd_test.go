@@ -0,0 +1,100 @@
+package diff_test
+
+import (
+	"bytes"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	resourcetypes "ocm.software/ocm/api/ocm/extensions/artifacttypes"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/mime"
+)
+
+const (
+	ARCH1    = "/tmp/ctf1"
+	ARCH2    = "/tmp/ctf2"
+	VERSION  = "v1"
+	COMP     = "test.de/x"
+	PROVIDER = "mandelsoft"
+
+	D_TESTDATA  = "810ff2fb242a5dee4220f2cb0e6a519891fb67f2f828a6cab4ef8894633b1f50"
+	D_OTHERDATA = "54b8007913ec5a907ca69001d59518acfd106f7b02f892eabf9cae3f8b2414b4"
+)
+
+var _ = Describe("Test Environment", func() {
+	var env *TestEnv
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+
+		env.OCMCommonTransport(ARCH1, accessio.FormatDirectory, func() {
+			env.Component(COMP, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+					env.Resource("text", "", resourcetypes.PLAIN_TEXT, metav1.LocalRelation, func() {
+						env.BlobStringData(mime.MIME_TEXT, "testdata")
+					})
+					env.Resource("obsolete", "", resourcetypes.PLAIN_TEXT, metav1.LocalRelation, func() {
+						env.BlobStringData(mime.MIME_TEXT, "obsolete")
+					})
+				})
+			})
+		})
+		env.OCMCommonTransport(ARCH2, accessio.FormatDirectory, func() {
+			env.Component(COMP, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+					env.Label("purpose", "test")
+					env.Resource("text", "", resourcetypes.PLAIN_TEXT, metav1.LocalRelation, func() {
+						env.BlobStringData(mime.MIME_TEXT, "otherdata")
+					})
+				})
+			})
+		})
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("compares component versions in different repositories", func() {
+		buf := bytes.NewBuffer(nil)
+		MustBeSuccessful(env.CatchOutput(buf).Execute("diff", "components", ARCH1+"//"+COMP+":"+VERSION, ARCH2+"//"+COMP+":"+VERSION))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+comparing test.de/x:v1 with test.de/x:v1
++ label purpose: "test"
+resource "name"="text" changed (access changed, content changed)
+  ~ access: {"localReference":"sha256:` + D_TESTDATA + `","mediaType":"text/plain","type":"localBlob"} -> {"localReference":"sha256:` + D_OTHERDATA + `","mediaType":"text/plain","type":"localBlob"}
+  ~ digest: SHA-256:` + D_TESTDATA + `[genericBlobDigest/v1] -> SHA-256:` + D_OTHERDATA + `[genericBlobDigest/v1]
+resource "name"="obsolete" removed
+`))
+	})
+
+	It("compares component versions as markdown", func() {
+		buf := bytes.NewBuffer(nil)
+		MustBeSuccessful(env.CatchOutput(buf).Execute("diff", "components", "--actual", "-o", "markdown", ARCH1+"//"+COMP+":"+VERSION, ARCH2+"//"+COMP+":"+VERSION))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+## Changes from ` + "`test.de/x:v1` to `test.de/x:v1`" + `
+
+### Component
+
+- label purpose: added ` + "`\"test\"`" + `
+
+### Resources
+
+- ` + "`\"name\"=\"text\"`" + `: changed (access changed, content changed)
+  - access: ` + "`" + `{"localReference":"sha256:` + D_TESTDATA + `","mediaType":"text/plain","type":"localBlob"}` + "` → `" + `{"localReference":"sha256:` + D_OTHERDATA + `","mediaType":"text/plain","type":"localBlob"}` + "`" + `
+  - digest: ` + "`SHA-256:" + D_TESTDATA + "[genericBlobDigest/v1]` → `SHA-256:" + D_OTHERDATA + "[genericBlobDigest/v1]`" + `
+- ` + "`\"name\"=\"obsolete\"`" + `: removed
+`))
+	})
+
+	It("requires two component versions", func() {
+		buf := bytes.NewBuffer(nil)
+		ExpectError(env.CatchOutput(buf).Execute("diff", "components", ARCH1)).To(MatchError("accepts 2 arg(s), received 1"))
+	})
+})
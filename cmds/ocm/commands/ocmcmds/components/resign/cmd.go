@@ -0,0 +1,195 @@
+package resign
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/resolvers"
+	ocmsign "ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/tech/signing/signutils"
+	common "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/cmds/ocm/commands/common/options/keyoption"
+	ocmcommon "ocm.software/ocm/cmds/ocm/commands/ocmcmds/common"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/handlers/comphdlr"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/hashoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/lookupoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/repooption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/versionconstraintsoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/output"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.Components
+	Verb  = verbs.Resign
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	Keys keyoption.Option
+
+	Refs         []string
+	OldSignature string
+	NewSignature string
+	Remove       bool
+	Algorithm    string
+}
+
+// NewCommand creates a new re-sign command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx, versionconstraintsoption.New(), repooption.New(), lookupoption.New(), hashoption.New())}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] {<component-reference>}",
+		Short: "re-sign component versions with a new key",
+		Long: `
+Re-sign component versions after a key rotation. For every selected component
+version the existing signature given by option <code>--old-signature</code>
+is verified, and a new signature given by option <code>--signature</code> is
+added. With option <code>--remove-old</code> the old signature is removed
+afterwards. If the new signature already exists, it is verified instead.
+
+If only a repository is given, all component versions found in the repository
+are re-signed. Referenced component versions are verified, but not re-signed.
+
+Because keys are assigned to signature names, the old and the new signature
+must use different names. The public key for the old signature and the private
+key for the new signature are given with the options <code>--public-key</code>
+and <code>--private-key</code>. Keys given without a name are used for the new
+signature.
+
+Finally, a report is given about the re-signed component versions and the
+ones which could not be verified or signed.
+` + keyoption.Usage(),
+		Example: `
+$ ocm resign componentversions --repo ghcr.io/acme --old-signature acme-2024 --public-key acme-2024=old.pub --signature acme-2025 --private-key acme-2025=new.key --remove-old
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(fs *pflag.FlagSet) {
+	o.BaseCommand.AddFlags(fs)
+	o.Keys.AddFlags(fs)
+	fs.StringVarP(&o.OldSignature, "old-signature", "", "", "name of the signature to replace")
+	fs.StringVarP(&o.NewSignature, "signature", "s", "", "name of the new signature")
+	fs.BoolVarP(&o.Remove, "remove-old", "", false, "remove old signature")
+	fs.StringVarP(&o.Algorithm, "algorithm", "S", rsa.Algorithm, "signature handler")
+}
+
+func (o *Command) Complete(args []string) error {
+	var err error
+
+	o.Refs = args
+	if len(args) == 0 && repooption.From(o).Spec == "" {
+		return fmt.Errorf("a repository or at least one argument that defines the reference is needed")
+	}
+	o.OldSignature, err = normalizeName("old-signature", o.OldSignature)
+	if err != nil {
+		return err
+	}
+	o.NewSignature, err = normalizeName("signature", o.NewSignature)
+	if err != nil {
+		return err
+	}
+	if o.OldSignature == o.NewSignature {
+		return errors.Newf("new signature name must differ from old signature name %q", o.OldSignature)
+	}
+	o.Keys.DefaultName = o.NewSignature
+	return o.Keys.Configure(o.OCMContext())
+}
+
+func normalizeName(opt, n string) (string, error) {
+	n = strings.TrimSpace(n)
+	if n == "" {
+		return "", errors.Newf("signature name required (option --%s)", opt)
+	}
+	dn, err := signutils.ParseDN(n)
+	if err != nil {
+		return "", err
+	}
+	return signutils.NormalizeDN(*dn), nil
+}
+
+func (o *Command) Run() (rerr error) {
+	session := ocm.NewSession(nil)
+	defer errors.PropagateError(&rerr, session.Close)
+
+	err := o.ProcessOnOptions(ocmcommon.CompleteOptionsWithSession(o, session))
+	if err != nil {
+		return err
+	}
+
+	repo := repooption.From(o).Repository
+	opts := []ocmsign.Option{
+		ocmsign.SignerByAlgo(o.Algorithm),
+		hashoption.From(o),
+		&o.Keys,
+	}
+	if def := o.Keys.Keys.GetIssuer(""); def != nil {
+		opts = append(opts, ocmsign.PKIXIssuer(*def))
+	}
+	a := &action{
+		cmd:      o,
+		printer:  common.NewPrinter(o.StdOut()),
+		resolver: resolvers.NewCompoundResolver(repo, lookupoption.From(o).Resolver),
+		opts:     opts,
+		errlist:  errors.ErrList("re-signing"),
+	}
+	handler := comphdlr.NewTypeHandler(o.Context.OCM(), session, repo, comphdlr.OptionsFor(o))
+	return utils.HandleOutput(a, handler, utils.StringElemSpecs(o.Refs...)...)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type action struct {
+	cmd      *Command
+	printer  common.Printer
+	resolver ocm.ComponentVersionResolver
+	opts     []ocmsign.Option
+	errlist  *errors.ErrorList
+	resigned int
+}
+
+var _ output.Output = (*action)(nil)
+
+func (a *action) Add(e interface{}) error {
+	o, ok := e.(*comphdlr.Object)
+	if !ok {
+		return fmt.Errorf("failed to assert %T to *comphdlr.Object", e)
+	}
+	cv := o.ComponentVersion
+	nv := common.VersionedElementKey(cv)
+	opts := append([]ocmsign.Option{ocmsign.Resolver(resolvers.NewCompoundResolver(o.Repository, a.resolver))}, a.opts...)
+	d, err := ocmsign.ResignComponentVersion(cv, a.cmd.OldSignature, a.cmd.NewSignature, a.cmd.Remove, opts...)
+	if err != nil {
+		a.errlist.Add(errors.Wrapf(err, "%s", nv))
+		a.printer.Printf("failed re-signing %s: %s\n", nv, err)
+	} else {
+		a.resigned++
+		a.printer.Printf("successfully re-signed %s (digest %s:%s)\n", nv, d.HashAlgorithm, d.Value)
+	}
+	return nil
+}
+
+func (a *action) Close() error {
+	return nil
+}
+
+func (a *action) Out() error {
+	out.Outf(a.cmd, "re-signed %d component version(s), %d failed\n", a.resigned, a.errlist.Len())
+	return a.errlist.Result()
+}
@@ -0,0 +1,114 @@
+package resign_test
+
+import (
+	"bytes"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+	"ocm.software/ocm/api/utils/mime"
+)
+
+const (
+	ARCH       = "/tmp/ctf"
+	PROVIDER   = "mandelsoft"
+	VERSION    = "v1"
+	COMPONENTA = "github.com/mandelsoft/test"
+	COMPONENTB = "github.com/mandelsoft/ref"
+	COMPONENTC = "github.com/mandelsoft/unsigned"
+)
+
+var _ = Describe("resign", func() {
+	var env *TestEnv
+
+	keys := func(names ...string) {
+		for _, n := range names {
+			priv, pub := Must2(rsa.Handler{}.CreateKeyPair())
+			MustBeSuccessful(vfs.WriteFile(env.FileSystem(), "/tmp/"+n+".priv", Must(rsa.KeyData(priv)), 0o600))
+			MustBeSuccessful(vfs.WriteFile(env.FileSystem(), "/tmp/"+n+".pub", Must(rsa.KeyData(pub)), 0o600))
+		}
+	}
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+		keys("old", "new")
+
+		env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+			env.Component(COMPONENTA, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+					env.Resource("testdata", "", "PlainText", metav1.LocalRelation, func() {
+						env.BlobStringData(mime.MIME_TEXT, "testdata")
+					})
+				})
+			})
+			env.Component(COMPONENTB, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+					env.Reference("ref", COMPONENTA, VERSION)
+				})
+			})
+			env.Component(COMPONENTC, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+				})
+			})
+		})
+		MustBeSuccessful(env.Execute("sign", "componentversions", "--repo", ARCH, "-s", "old", "--private-key", "/tmp/old.priv", COMPONENTA+":"+VERSION, COMPONENTB+":"+VERSION))
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("re-signs repository", func() {
+		buf := bytes.NewBuffer(nil)
+		ExpectError(env.CatchOutput(buf).Execute("resign", "componentversions", "--repo", ARCH, "--old-signature", "old", "--public-key", "old=/tmp/old.pub",
+			"-s", "new", "--private-key", "/tmp/new.priv", "--remove-old")).To(MatchError(`re-signing: github.com/mandelsoft/unsigned:v1: signature "old" not found`))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+successfully re-signed github.com/mandelsoft/ref:v1 (digest SHA-256:` + "${ref}" + `)
+successfully re-signed github.com/mandelsoft/test:v1 (digest SHA-256:` + "${test}" + `)
+failed re-signing github.com/mandelsoft/unsigned:v1: signature "old" not found
+re-signed 2 component version(s), 1 failed
+`, substitutions(env)))
+
+		repo := Must(ctf.Open(env, accessobj.ACC_READONLY, ARCH, 0, env))
+		defer Close(repo, "repo")
+		for _, c := range []string{COMPONENTA, COMPONENTB} {
+			cv := Must(repo.LookupComponentVersion(c, VERSION))
+			Expect(cv.GetDescriptor().Signatures).To(HaveLen(1))
+			Expect(cv.GetDescriptor().Signatures[0].Name).To(Equal("new"))
+			Close(cv, c)
+		}
+		MustBeSuccessful(env.Execute("verify", "componentversions", "--repo", ARCH, "-s", "new", "--public-key", "new=/tmp/new.pub", COMPONENTB+":"+VERSION))
+	})
+
+	It("reports failed verification", func() {
+		buf := bytes.NewBuffer(nil)
+		ExpectError(env.CatchOutput(buf).Execute("resign", "componentversions", "--repo", ARCH, "--old-signature", "old", "--public-key", "old=/tmp/new.pub",
+			"-s", "new", "--private-key", "/tmp/new.priv", COMPONENTA+":"+VERSION)).To(HaveOccurred())
+		Expect(buf.String()).To(ContainSubstring(`failed re-signing github.com/mandelsoft/test:v1: verification of signature "old" failed: `))
+		Expect(buf.String()).To(ContainSubstring(`re-signed 0 component version(s), 1 failed`))
+	})
+})
+
+func substitutions(env *TestEnv) Substitutions {
+	repo := Must(ctf.Open(env, accessobj.ACC_READONLY, ARCH, 0, env))
+	defer Close(repo, "repo")
+	subst := Substitutions{}
+	for k, c := range map[string]string{"test": COMPONENTA, "ref": COMPONENTB} {
+		cv := Must(repo.LookupComponentVersion(c, VERSION))
+		subst[k] = cv.GetDescriptor().Signatures[0].Digest.Value
+		Close(cv, c)
+	}
+	return subst
+}
@@ -2,6 +2,8 @@ package sign_test
 
 import (
 	"bytes"
+	"crypto/elliptic"
+	"encoding/pem"
 	"os"
 
 	. "github.com/mandelsoft/goutils/testutils"
@@ -21,6 +23,8 @@ import (
 	resourcetypes "ocm.software/ocm/api/ocm/extensions/artifacttypes"
 	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
 	"ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/ecdsa"
+	"ocm.software/ocm/api/tech/signing/handlers/ed25519"
 	"ocm.software/ocm/api/tech/signing/handlers/rsa"
 	"ocm.software/ocm/api/tech/signing/signutils"
 	"ocm.software/ocm/api/utils/accessio"
@@ -230,6 +234,34 @@ successfully signed github.com/mandelsoft/ref:v1 (digest SHA-256:${ref})
 			session.AddCloser(cv)
 			Expect(cv.GetDescriptor().Signatures[0].Digest.Value).To(Equal(D_COMPONENTB_V1))
 		})
+
+		DescribeTable("signs and verifies with key based algorithm", func(algo string, create func() (signutils.GenericPrivateKey, signutils.GenericPublicKey, error)) {
+			prepareEnv(env, ARCH, ARCH)
+
+			priv, pub := Must2(create())
+			data := pem.EncodeToMemory(signutils.PemBlockForPrivateKey(priv))
+			MustBeSuccessful(vfs.WriteFile(env.FileSystem(), PRIVKEY, data, os.ModePerm))
+			data = pem.EncodeToMemory(signutils.PemBlockForPublicKey(pub))
+			MustBeSuccessful(vfs.WriteFile(env.FileSystem(), PUBKEY, data, os.ModePerm))
+
+			MustBeSuccessful(env.Execute("sign", "components", "-s", SIGNATURE, "-K", PRIVKEY, "--algorithm", algo, "--repo", ARCH, COMPONENTB+":"+VERSION))
+			MustBeSuccessful(env.Execute("verify", "components", "-s", SIGNATURE, "-k", PUBKEY, "--repo", ARCH, COMPONENTB+":"+VERSION))
+
+			session := datacontext.NewSession()
+			defer session.Close()
+
+			src := Must(ctf.Open(env.OCMContext(), accessobj.ACC_READONLY, ARCH, 0, env))
+			session.AddCloser(src)
+			cv := Must(src.LookupComponentVersion(COMPONENTB, VERSION))
+			session.AddCloser(cv)
+			Expect(cv.GetDescriptor().Signatures[0].Signature.Algorithm).To(Equal(algo))
+		},
+			Entry("ECDSA-P256", ecdsa.AlgorithmP256, func() (signutils.GenericPrivateKey, signutils.GenericPublicKey, error) { return ecdsa.CreateKeyPair() }),
+			Entry("ECDSA-P384", ecdsa.AlgorithmP384, func() (signutils.GenericPrivateKey, signutils.GenericPublicKey, error) {
+				return ecdsa.CreateKeyPair(elliptic.P384())
+			}),
+			Entry("Ed25519", ed25519.Algorithm, ed25519.CreateKeyPair),
+		)
 	})
 
 	Context("incomplete ctf", func() {
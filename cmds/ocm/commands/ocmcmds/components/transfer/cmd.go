@@ -1,8 +1,10 @@
 package transfer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/goutils/maputils"
@@ -11,18 +13,23 @@ import (
 	"github.com/spf13/pflag"
 
 	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/oci/extensions/repositories/ctf"
 	"ocm.software/ocm/api/ocm"
 	"ocm.software/ocm/api/ocm/compdesc"
+	"ocm.software/ocm/api/ocm/extensions/repositories/genericocireg"
 	"ocm.software/ocm/api/ocm/tools/transfer"
 	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler"
 	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler/spiff"
 	common "ocm.software/ocm/api/utils/misc"
 	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/api/utils/runtime"
 	"ocm.software/ocm/cmds/ocm/commands/common/options/closureoption"
 	"ocm.software/ocm/cmds/ocm/commands/common/options/formatoption"
 	ocmcommon "ocm.software/ocm/cmds/ocm/commands/ocmcmds/common"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/handlers/comphdlr"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/dryrunoption"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/lookupoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/ociattachmentsoption"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/omitaccesstypeoption"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/overwriteoption"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/repooption"
@@ -31,6 +38,7 @@ import (
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/skipupdateoption"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/srcbyvalueoption"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/stoponexistingoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/trustpolicyoption"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/uploaderoption"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/versionconstraintsoption"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
@@ -52,6 +60,10 @@ type Command struct {
 	TargetName          string
 	BOMFile             string
 	DisableBlobHandlers bool
+	JournalFile         string
+	Resume              bool
+	PlanFormat          string
+	EstimateSize        bool
 }
 
 // NewCommand creates a new ctf command.
@@ -68,8 +80,11 @@ func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
 		srcbyvalueoption.New(),
 		omitaccesstypeoption.New(),
 		stoponexistingoption.New(),
+		trustpolicyoption.New(),
+		ociattachmentsoption.New(),
 		uploaderoption.New(ctx.OCMContext()),
 		scriptoption.New(),
+		dryrunoption.New("evaluate and print the transfer plan", true),
 	)}, utils.Names(Names, names...)...)
 }
 
@@ -82,11 +97,32 @@ func (o *Command) ForName(name string) *cobra.Command {
 Transfer all component versions specified to the given target repository.
 If only a component (instead of a component version) is specified all versions
 are transferred.
+
+If the option <code>--journal</code> is given, the progress of the transfer is
+recorded in the given file. It keeps track of completely transferred component
+versions and of resources transferred into separate locations of the
+target environment (for example, OCI images). Together with the option
+<code>--resume</code> a failed transfer can be continued, skipping all the work
+already recorded in the journal. For common transport archives the journal
+used by <code>--resume</code> defaults to a file <code>&lt;target>.journal</code>
+next to the target. Without one of these options no journal is written.
+After a successful transfer the journal file is removed.
+
+If the option <code>--dry-run</code> is given, nothing is transferred. Instead,
+a transfer plan is evaluated and printed (or written to the file given by
+option <code>--output</code>). It describes, which component versions would be
+created, overwritten, updated or skipped, and which resources and sources would
+be copied by value. The format can be selected with option <code>--plan-format</code>
+(<code>yaml</code> or <code>json</code>). The plan includes the estimated byte
+volume of the artifacts copied by value. Because this might require to read
+the artifact content, it can be disabled with <code>--estimate-size=false</code>.
 `,
 		Example: `
 $ ocm transfer components -t tgz ghcr.io/open-component-model/ocm//ocm.software/ocmcli:0.17.0 ./ctf.tgz
 $ ocm transfer components --latest -t tgz --repo OCIRegistry::ghcr.io/open-component-model/ocm ocm.software/ocmcli ./ctf.tgz
 $ ocm transfer components --latest --copy-resources --type directory ghcr.io/open-component-model/ocm//ocm.software/ocmcli ./ctf
+$ ocm transfer components --recursive --copy-resources --resume ghcr.io/open-component-model/ocm//ocm.software/ocmcli:0.17.0 ./ctf.tgz
+$ ocm transfer components --recursive --copy-resources --dry-run --plan-format json ghcr.io/open-component-model/ocm//ocm.software/ocmcli:0.17.0 ./ctf.tgz
 `,
 		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
 	}
@@ -96,6 +132,10 @@ func (o *Command) AddFlags(fs *pflag.FlagSet) {
 	o.BaseCommand.AddFlags(fs)
 	fs.StringVarP(&o.BOMFile, "bom-file", "B", "", "file name to write the component version BOM")
 	fs.BoolVarP(&o.DisableBlobHandlers, "disable-uploads", "", false, "disable standard upload handlers for transport")
+	fs.StringVarP(&o.JournalFile, "journal", "", "", "file name of the transfer journal")
+	fs.BoolVarP(&o.Resume, "resume", "", false, "resume a former transfer using the transfer journal")
+	fs.StringVarP(&o.PlanFormat, "plan-format", "", "yaml", "output format of the dry-run transfer plan (yaml, json)")
+	fs.BoolVarP(&o.EstimateSize, "estimate-size", "", true, "determine the byte volume of artifacts copied by value for the transfer plan")
 }
 
 func (o *Command) Complete(args []string) error {
@@ -104,6 +144,11 @@ func (o *Command) Complete(args []string) error {
 		return fmt.Errorf("a repository or at least one argument that defines the reference is required")
 	}
 	o.TargetName = args[len(args)-1]
+	switch o.PlanFormat {
+	case "yaml", "json":
+	default:
+		return errors.ErrInvalid("plan format", o.PlanFormat)
+	}
 	return nil
 }
 
@@ -126,7 +171,13 @@ func (o *Command) Run() error {
 		return err
 	}
 
-	target, err := ocm.AssureTargetRepository(session, o.Context.OCMContext(), o.TargetName, ocm.CommonTransportFormat, formatoption.From(o).ChangedFormat(), o.Context.FileSystem())
+	var target ocm.Repository
+	dr := dryrunoption.From(o)
+	if dr.DryRun {
+		target, err = o.lookupTarget(session)
+	} else {
+		target, err = ocm.AssureTargetRepository(session, o.Context.OCMContext(), o.TargetName, ocm.CommonTransportFormat, formatoption.From(o).ChangedFormat(), o.Context.FileSystem())
+	}
 	if err != nil {
 		return err
 	}
@@ -141,12 +192,53 @@ func (o *Command) Run() error {
 	if err != nil {
 		return err
 	}
+	if dr.DryRun {
+		hdlr := comphdlr.NewTypeHandler(o.Context.OCM(), session, repooption.From(o).Repository, comphdlr.OptionsFor(o))
+		err = utils.HandleOutput(&action{
+			cmd:     o,
+			target:  target,
+			handler: thdlr,
+			planner: transfer.NewPlanner(nil, o.EstimateSize),
+			errors:  errors.ErrListf("transfer plan errors"),
+		}, hdlr, utils.StringElemSpecs(o.Refs...)...)
+		if err != nil {
+			return err
+		}
+		return session.Close()
+	}
+
+	if o.Resume && o.JournalFile == "" {
+		if s, ok := target.GetSpecification().(*genericocireg.RepositorySpec); ok {
+			if c, ok := s.RepositorySpec.(*ctf.RepositorySpec); ok {
+				o.JournalFile = c.FilePath + ".journal"
+			}
+		}
+		if o.JournalFile == "" {
+			return fmt.Errorf("option --journal required to resume transfer to non-filesystem target %q", o.TargetName)
+		}
+	}
+
+	var journal transfer.Journal
+	if o.JournalFile != "" {
+		journal, err = transfer.NewJournal(o.JournalFile, o.FileSystem())
+		if err != nil {
+			return err
+		}
+		if !o.Resume {
+			err = journal.Reset()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	hdlr := comphdlr.NewTypeHandler(o.Context.OCM(), session, repooption.From(o).Repository, comphdlr.OptionsFor(o))
 	err = utils.HandleOutput(&action{
 		cmd:     o,
 		printer: common.NewPrinter(o.Context.StdOut()),
 		target:  target,
 		handler: thdlr,
+		journal: journal,
 		closure: transfer.TransportClosure{},
 		errors:  errors.ErrListf("transfer errors"),
 	}, hdlr, utils.StringElemSpecs(o.Refs...)...)
@@ -156,6 +248,27 @@ func (o *Command) Run() error {
 	return session.Close()
 }
 
+// lookupTarget determines the target repository without creating it.
+// For a non-existing archive, nil is returned.
+func (o *Command) lookupTarget(session ocm.Session) (ocm.Repository, error) {
+	ref, err := ocm.ParseRepo(o.TargetName)
+	if err != nil {
+		return nil, err
+	}
+	target, err := session.DetermineRepositoryBySpec(o.Context.OCMContext(), &ref)
+	if err != nil {
+		// only a missing archive would be created by the transfer,
+		// all other errors would let the transfer fail, also.
+		if ref.Info != "" && errors.IsErrUnknown(err) {
+			if ok, xerr := vfs.Exists(o.Context.FileSystem(), ref.Info); xerr == nil && !ok {
+				return nil, nil
+			}
+		}
+		return nil, err
+	}
+	return target, nil
+}
+
 /////////////////////////////////////////////////////////////////////////////
 
 type action struct {
@@ -163,6 +276,8 @@ type action struct {
 	printer common.Printer
 	target  ocm.Repository
 	handler transferhandler.TransferHandler
+	journal transfer.Journal
+	planner *transfer.Planner
 	closure transfer.TransportClosure
 	errors  *errors.ErrorList
 }
@@ -178,7 +293,14 @@ func (a *action) Add(e interface{}) error {
 	if err != nil {
 		return errors.Wrapf(err, "cannot transfer component version %s/%s", o.ComponentVersion.GetName(), o.ComponentVersion.GetVersion())
 	}
-	err = transfer.TransferVersion(a.printer, a.closure, sub, a.target, h)
+	if a.planner != nil {
+		err = a.planner.PlanVersion(context.Background(), sub, a.target, h)
+		sub.Close()
+		a.errors.Add(err)
+		return nil
+	}
+	ctx := transfer.WithJournalContext(common.WithPrinter(context.Background(), a.printer), a.journal)
+	err = transfer.TransferVersionWithContext(ctx, a.closure, sub, a.target, h)
 	sub.Close()
 	a.errors.Add(err)
 	if err != nil {
@@ -192,6 +314,9 @@ func (a *action) Close() error {
 }
 
 func (a *action) Out() error {
+	if a.planner != nil {
+		return a.outPlan()
+	}
 	a.printer.Printf("%d versions transferred\n", len(a.closure))
 	if a.errors.Result() != nil {
 		sum := "Error summary:"
@@ -201,6 +326,12 @@ func (a *action) Out() error {
 		return fmt.Errorf("transfer finished with %d error(s)\n%s\n", a.errors.Len(), sum)
 	}
 
+	if a.journal != nil {
+		if err := a.cmd.FileSystem().Remove(a.cmd.JournalFile); err != nil && !vfs.IsNotExist(err) {
+			return errors.Wrapf(err, "cannot remove transfer journal")
+		}
+	}
+
 	if a.cmd.BOMFile != "" {
 		bom := BOM{}
 		for _, nv := range maputils.Keys(a.closure, common.CompareNameVersion) {
@@ -228,3 +359,30 @@ type BomEntry struct {
 type BOM struct {
 	List []BomEntry `json:"componentVersions"`
 }
+
+func (a *action) outPlan() error {
+	if err := a.errors.Result(); err != nil {
+		return err
+	}
+	var data []byte
+	var err error
+	if a.cmd.PlanFormat == "json" {
+		data, err = json.MarshalIndent(a.planner.Plan(), "", "  ")
+	} else {
+		data, err = runtime.DefaultYAMLEncoding.Marshal(a.planner.Plan())
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot marshal transfer plan")
+	}
+
+	outfile := dryrunoption.From(a.cmd).Outfile
+	if outfile != "" && outfile != "-" {
+		err = vfs.WriteFile(a.cmd.FileSystem(), outfile, data, 0o640)
+		if err != nil {
+			return errors.Wrapf(err, "cannot write transfer plan")
+		}
+		return nil
+	}
+	out.Outf(a.cmd.Context, "%s\n", strings.TrimSuffix(string(data), "\n"))
+	return nil
+}
@@ -23,11 +23,13 @@ import (
 	resourcetypes "ocm.software/ocm/api/ocm/extensions/artifacttypes"
 	ctfocm "ocm.software/ocm/api/ocm/extensions/repositories/ctf"
 	ocmutils "ocm.software/ocm/api/ocm/ocmutils"
+	"ocm.software/ocm/api/ocm/tools/transfer"
 	handlercfg "ocm.software/ocm/api/ocm/tools/transfer/transferhandler/config"
 	"ocm.software/ocm/api/utils"
 	"ocm.software/ocm/api/utils/accessio"
 	"ocm.software/ocm/api/utils/accessobj"
 	"ocm.software/ocm/api/utils/mime"
+	common "ocm.software/ocm/api/utils/misc"
 )
 
 const (
@@ -204,6 +206,140 @@ transferring version "github.com/mandelsoft/test2:v1"...
 `))
 	})
 
+	It("resumes transfer with journal", func() {
+		JOURNAL := "/tmp/journal"
+		buf := bytes.NewBuffer(nil)
+		Expect(env.CatchOutput(buf).Execute("transfer", "components", "--copy-resources", ARCH, ARCH, OUT)).To(Succeed())
+
+		journal := Must(transfer.NewJournal(JOURNAL, env.FileSystem()))
+		MustBeSuccessful(journal.VersionTransferred(common.NewNameVersion(COMPONENT, VERSION)))
+
+		buf.Reset()
+		Expect(env.CatchOutput(buf).Execute("transfer", "components", "--copy-resources", "--recursive", "--lookup", ARCH, "--journal", JOURNAL, "--resume", ARCH2, ARCH2, OUT)).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+transferring version "github.com/mandelsoft/test2:v1"...
+  transferring version "github.com/mandelsoft/test:v1"...
+    version "github.com/mandelsoft/test:v1" already transferred (journal) -> skip transport
+...resource 0 otherdate[plainText]...
+...adding component version...
+2 versions transferred
+`))
+		Expect(env.FileExists(JOURNAL)).To(BeFalse())
+
+		tgt := Must(ctfocm.Open(env.OCMContext(), accessobj.ACC_READONLY, OUT, 0, accessio.PathFileSystem(env.FileSystem())))
+		defer Close(tgt, "ctf")
+		Expect(tgt.ExistsComponentVersion(COMPONENT2, VERSION)).To(BeTrue())
+		CheckComponent(env, ldesc, tgt)
+	})
+
+	It("uses default journal for ctf target", func() {
+		JOURNAL := OUT + ".journal"
+		buf := bytes.NewBuffer(nil)
+		Expect(env.CatchOutput(buf).Execute("transfer", "components", "--recursive", "--resume", ARCH+"//"+COMPONENT+":"+VERSION, ARCH2+"//"+COMPONENT2+":"+VERSION, OUT)).NotTo(Succeed())
+		Expect(env.FileExists(JOURNAL)).To(BeTrue())
+		Expect(Must(transfer.NewJournal(JOURNAL, env.FileSystem())).IsVersionTransferred(common.NewNameVersion(COMPONENT, VERSION))).To(BeTrue())
+
+		buf.Reset()
+		Expect(env.CatchOutput(buf).Execute("transfer", "components", "--recursive", "--lookup", ARCH, "--resume", ARCH2, ARCH2, OUT)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring(`version "github.com/mandelsoft/test:v1" already transferred (journal) -> skip transport`))
+		Expect(env.FileExists(JOURNAL)).To(BeFalse())
+	})
+
+	It("does not write a journal without journal options", func() {
+		buf := bytes.NewBuffer(nil)
+		Expect(env.CatchOutput(buf).Execute("transfer", "components", "--recursive", ARCH2, ARCH2, OUT)).NotTo(Succeed())
+		Expect(env.FileExists(OUT + ".journal")).To(BeFalse())
+	})
+
+	It("keeps journal for failed transfer", func() {
+		JOURNAL := "/tmp/journal"
+		buf := bytes.NewBuffer(nil)
+		Expect(env.CatchOutput(buf).Execute("transfer", "components", "--recursive", "--journal", JOURNAL, ARCH2, ARCH2, OUT)).NotTo(Succeed())
+		Expect(env.FileExists(JOURNAL)).To(BeTrue())
+	})
+
+	It("plans transfer with --dry-run", func() {
+		buf := bytes.NewBuffer(nil)
+		Expect(env.CatchOutput(buf).Execute("transfer", "components", "--dry-run", "--estimate-size=false", "--copy-resources", "--recursive", "--lookup", ARCH, ARCH2, ARCH2, OUT)).To(Succeed())
+		Expect(buf.String()).To(YAMLEqual(`
+componentVersions:
+- action: create
+  component: github.com/mandelsoft/test2
+  references:
+  - github.com/mandelsoft/test:v1
+  resources:
+  - accessType: localBlob
+    action: copy
+    identity:
+      name: otherdate
+    name: otherdate
+    type: plainText
+  version: v1
+- action: create
+  component: github.com/mandelsoft/test
+  resources:
+  - accessType: localBlob
+    action: copy
+    identity:
+      name: testdata
+    name: testdata
+    type: plainText
+  - accessType: ociArtifact
+    action: copy
+    identity:
+      name: value
+    name: value
+    type: ociImage
+  - accessType: ociArtifact
+    action: copy
+    identity:
+      name: ref
+    name: ref
+    type: ociImage
+  version: v1
+unknownSizes: 4
+`))
+		Expect(env.DirExists(OUT)).To(BeFalse())
+	})
+
+	It("rejects dry-run for invalid target", func() {
+		MustBeSuccessful(env.WriteFile(OUT, []byte("no archive"), 0o600))
+		buf := bytes.NewBuffer(nil)
+		Expect(env.CatchOutput(buf).Execute("transfer", "components", "--dry-run", ARCH, ARCH, OUT)).To(HaveOccurred())
+		Expect(buf.String()).To(Equal(""))
+	})
+
+	It("plans transfer into existing target with --dry-run", func() {
+		PLAN := "/tmp/plan.json"
+		buf := bytes.NewBuffer(nil)
+		Expect(env.CatchOutput(buf).Execute("transfer", "components", "--copy-resources", ARCH, ARCH, OUT)).To(Succeed())
+
+		buf.Reset()
+		Expect(env.CatchOutput(buf).Execute("transfer", "components", "--dry-run", "--plan-format", "json", "-O", PLAN, "--recursive", "--lookup", ARCH, ARCH2, ARCH2, OUT)).To(Succeed())
+		Expect(buf.String()).To(Equal(""))
+		Expect(Must(env.ReadFile(PLAN))).To(YAMLEqual(`
+componentVersions:
+- action: create
+  component: github.com/mandelsoft/test2
+  references:
+  - github.com/mandelsoft/test:v1
+  resources:
+  - accessType: localBlob
+    action: copy
+    identity:
+      name: otherdate
+    name: otherdate
+    size: 9
+    type: plainText
+  version: v1
+- action: skip
+  component: github.com/mandelsoft/test
+  reason: already present
+  version: v1
+estimatedSize: 9
+`))
+	})
+
 	It("transfers ctf to tgz with type option", func() {
 		buf := bytes.NewBuffer(nil)
 		Expect(env.CatchOutput(buf).Execute("transfer", "components", "--copy-resources", "--type", accessio.FormatTGZ.String(), ARCH, ARCH, OUT)).To(Succeed())
@@ -188,6 +188,62 @@ successfully verified github.com/mandelsoft/ref:v1 (digest SHA-256:${ref})
 `, substitutions))
 	})
 
+	Context("trust policy", func() {
+		const POLICY = "/tmp/policy.yaml"
+
+		WritePolicy := func(signature string) {
+			MustBeSuccessful(vfs.WriteFile(env.FileSystem(), POLICY, []byte(`
+rules:
+  - name: ref
+    components:
+      - github.com/mandelsoft/ref
+    signatures:
+      - `+signature+`
+    digestAlgorithms:
+      - SHA-256
+`), os.ModePerm))
+		}
+
+		It("verifies policy", func() {
+			Prepare()
+			WritePolicy(SIGNATURE)
+
+			buf := bytes.NewBuffer(nil)
+			MustBeSuccessful(env.CatchOutput(buf).Execute("verify", "components", "--trust-policy", POLICY, "-k", SIGNATURE+"="+PUBKEY, "--repo", ARCH, COMPONENTB+":"+VERSION))
+			Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+applying to version "github.com/mandelsoft/ref:v1"[github.com/mandelsoft/ref:v1]...
+  no digest found for "github.com/mandelsoft/test:v1"
+  applying to version "github.com/mandelsoft/test:v1"[github.com/mandelsoft/ref:v1]...
+    resource 0:  "name"="testdata": digest SHA-256:${r0}[genericBlobDigest/v1]
+    resource 1:  "name"="value": digest SHA-256:${r1}[ociArtifactDigest/v1]
+    resource 2:  "name"="ref": digest SHA-256:${r2}[ociArtifactDigest/v1]
+  reference 0:  github.com/mandelsoft/test:v1: digest SHA-256:${test}[jsonNormalisation/v1]
+  resource 0:  "name"="otherdata": digest SHA-256:${rb0}[genericBlobDigest/v1]
+successfully verified github.com/mandelsoft/ref:v1 (digest SHA-256:${ref})
+`, substitutions))
+		})
+
+		It("reports policy violations", func() {
+			Prepare()
+			WritePolicy("other")
+
+			buf := bytes.NewBuffer(nil)
+			ExpectError(env.CatchOutput(buf).Execute("verify", "components", "--trust-policy", POLICY, "-k", SIGNATURE+"="+PUBKEY, "--repo", ARCH, COMPONENTB+":"+VERSION)).To(HaveOccurred())
+			Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+applying to version "github.com/mandelsoft/ref:v1"[github.com/mandelsoft/ref:v1]...
+  no digest found for "github.com/mandelsoft/test:v1"
+  applying to version "github.com/mandelsoft/test:v1"[github.com/mandelsoft/ref:v1]...
+    resource 0:  "name"="testdata": digest SHA-256:${r0}[genericBlobDigest/v1]
+    resource 1:  "name"="value": digest SHA-256:${r1}[ociArtifactDigest/v1]
+    resource 2:  "name"="ref": digest SHA-256:${r2}[ociArtifactDigest/v1]
+  reference 0:  github.com/mandelsoft/test:v1: digest SHA-256:${test}[jsonNormalisation/v1]
+  resource 0:  "name"="otherdata": digest SHA-256:${rb0}[genericBlobDigest/v1]
+failed verifying signature of github.com/mandelsoft/ref:v1: github.com/mandelsoft/ref:v1: trust policy: component version "github.com/mandelsoft/ref:v1" violates trust policy rule "ref": required signature "other" not found; 0 qualifying signature(s) found, but 1 required
+finished with 1 error(s)
+`, substitutions))
+		})
+	})
+
 	Context("verified store", func() {
 		It("signs transport archive", func() {
 			Prepare()
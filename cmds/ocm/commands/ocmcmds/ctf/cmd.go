@@ -4,6 +4,7 @@ import (
 	"github.com/spf13/cobra"
 
 	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/cmds/ocm/commands/ocicmds/ctf/compact"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/ctf/transfer"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
 	"ocm.software/ocm/cmds/ocm/common/utils"
@@ -17,5 +18,6 @@ func NewCommand(ctx clictx.Context) *cobra.Command {
 		Short: "Commands acting on common transport archives",
 	}, Names...)
 	cmd.AddCommand(transfer.NewCommand(ctx, transfer.Verb))
+	cmd.AddCommand(compact.NewCommand(ctx, compact.Verb))
 	return cmd
 }
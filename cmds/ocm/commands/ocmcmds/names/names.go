@@ -18,6 +18,9 @@ var (
 	RoutingSlips           = []string{"routingslips", "routingslip", "rs"}
 	PubSub                 = []string{"pubsub", "ps"}
 	Verified               = []string{"verified"}
+	SBOM                   = []string{"sbom"}
+	SigningRequests        = []string{"signingrequests", "signingrequest", "sigreq"}
+	Attestations           = []string{"attestations", "attestation", "att"}
 )
 
 var Aliases = map[string][]string{}
@@ -40,6 +43,9 @@ func init() {
 		RoutingSlips,
 		PubSub,
 		Verified,
+		SBOM,
+		SigningRequests,
+		Attestations,
 	)
 }
 
@@ -0,0 +1,21 @@
+package sbom
+
+import (
+	"github.com/spf13/cobra"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/sbom/get"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var Names = names.SBOM
+
+// NewCommand creates a new command.
+func NewCommand(ctx clictx.Context) *cobra.Command {
+	cmd := utils.MassageCommand(&cobra.Command{
+		Short: "Commands acting on software bills of materials",
+	}, Names...)
+	cmd.AddCommand(get.NewCommand(ctx, get.Verb))
+	return cmd
+}
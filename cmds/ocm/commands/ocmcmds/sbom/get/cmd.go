@@ -0,0 +1,137 @@
+package get
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/ocmutils/sbom"
+	ocmcommon "ocm.software/ocm/cmds/ocm/commands/ocmcmds/common"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/handlers/comphdlr"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/lookupoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/repooption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/output"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.SBOM
+	Verb  = verbs.Get
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	Ref       string
+	Recursive bool
+	Format    string
+}
+
+// NewCommand creates a new sbom command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx, repooption.New(), lookupoption.New())}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] <component-reference>",
+		Args:  cobra.ExactArgs(1),
+		Short: "get a software bill of materials for a component version",
+		Long: `
+Generate a software bill of materials (SBOM) for a component version.
+It describes all resources and sources of the component version with
+their identity, version, digest, access location and labels, as well as
+the component references.
+
+With option <code>--recursive</code> the referenced component versions are
+included, also. They are searched in the repository of the referencing
+component version and, as fallback, in the repositories given by the
+<code>--lookup</code> option or the configured resolvers.
+
+With option <code>--output</code> the document format can be selected.
+Possible formats are <code>cyclonedx</code> (CycloneDX 1.5, default) and
+<code>spdx</code> (SPDX 2.3). Both are rendered as JSON.
+`,
+		Example: `
+$ ocm get sbom ghcr.io/acme//acme.org/app:1.0.0
+$ ocm get sbom --recursive -o spdx --repo OCIRegistry::ghcr.io/acme acme.org/app:1.0.0
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(fs *pflag.FlagSet) {
+	o.BaseCommand.AddFlags(fs)
+	fs.BoolVarP(&o.Recursive, "recursive", "r", false, "follow component references")
+	fs.StringVarP(&o.Format, "output", "o", sbom.FORMAT_CYCLONEDX, fmt.Sprintf("output format (%s)", strings.Join(sbom.Formats, ", ")))
+}
+
+func (o *Command) Complete(args []string) error {
+	o.Ref = args[0]
+	for _, f := range sbom.Formats {
+		if f == o.Format {
+			return nil
+		}
+	}
+	return errors.ErrInvalid("output format", o.Format)
+}
+
+func (o *Command) Run() (err error) {
+	session := ocm.NewSession(nil)
+	defer errors.PropagateError(&err, session.Close)
+
+	err = o.ProcessOnOptions(ocmcommon.CompleteOptionsWithSession(o, session))
+	if err != nil {
+		return err
+	}
+
+	a := &action{cmd: o}
+	handler := comphdlr.NewTypeHandler(o.Context.OCM(), session, repooption.From(o).Repository)
+	return utils.HandleOutput(a, handler, utils.StringElemSpecs(o.Ref)...)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type action struct {
+	cmd  *Command
+	data comphdlr.Objects
+}
+
+var _ output.Output = (*action)(nil)
+
+func (a *action) Add(e interface{}) error {
+	o, ok := e.(*comphdlr.Object)
+	if !ok {
+		return fmt.Errorf("object of type %T is not a valid comphdlr.Object", e)
+	}
+	if o.ComponentVersion == nil {
+		return errors.ErrNotFound(ocm.KIND_COMPONENTVERSION, o.Spec.String())
+	}
+	a.data = append(a.data, o)
+	return nil
+}
+
+func (a *action) Close() error {
+	return nil
+}
+
+func (a *action) Out() error {
+	if len(a.data) != 1 {
+		return fmt.Errorf("exactly one component version required, but %d found", len(a.data))
+	}
+	s, err := sbom.Generate(a.data[0].ComponentVersion,
+		sbom.Recursive(a.cmd.Recursive),
+		sbom.Resolver(lookupoption.From(a.cmd).Resolver),
+	)
+	if err != nil {
+		return err
+	}
+	return sbom.Write(a.cmd.StdOut(), s, a.cmd.Format)
+}
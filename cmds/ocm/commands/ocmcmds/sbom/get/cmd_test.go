@@ -0,0 +1,98 @@
+package get_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	resourcetypes "ocm.software/ocm/api/ocm/extensions/artifacttypes"
+	"ocm.software/ocm/api/ocm/ocmutils/sbom"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/mime"
+)
+
+const (
+	ARCH1    = "/tmp/ctf1"
+	ARCH2    = "/tmp/ctf2"
+	VERSION  = "v1"
+	COMP     = "test.de/x"
+	COMP2    = "test.de/y"
+	PROVIDER = "mandelsoft"
+
+	D_TESTDATA = "810ff2fb242a5dee4220f2cb0e6a519891fb67f2f828a6cab4ef8894633b1f50"
+)
+
+var _ = Describe("Test Environment", func() {
+	var env *TestEnv
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+
+		env.OCMCommonTransport(ARCH1, accessio.FormatDirectory, func() {
+			env.Component(COMP, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+					env.Resource("text", "", resourcetypes.PLAIN_TEXT, metav1.LocalRelation, func() {
+						env.BlobStringData(mime.MIME_TEXT, "testdata")
+					})
+					env.Reference("ref", COMP2, VERSION)
+				})
+			})
+		})
+		env.OCMCommonTransport(ARCH2, accessio.FormatDirectory, func() {
+			env.Component(COMP2, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+				})
+			})
+		})
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("generates CycloneDX document", func() {
+		buf := bytes.NewBuffer(nil)
+		MustBeSuccessful(env.CatchOutput(buf).Execute("get", "sbom", ARCH1+"//"+COMP+":"+VERSION))
+
+		var doc sbom.CycloneDXDocument
+		MustBeSuccessful(json.Unmarshal(buf.Bytes(), &doc))
+		Expect(doc.BOMFormat).To(Equal("CycloneDX"))
+		Expect(doc.Metadata.Component.Name).To(Equal(COMP))
+		Expect(doc.Metadata.Component.Components[0].Name).To(Equal("text"))
+		Expect(doc.Metadata.Component.Components[0].Hashes).To(Equal([]sbom.CycloneDXHash{{Algorithm: "SHA-256", Content: D_TESTDATA}}))
+		Expect(len(doc.Components)).To(Equal(1))
+		Expect(doc.Components[0].Components).To(BeNil())
+	})
+
+	It("generates recursive SPDX document with lookup", func() {
+		buf := bytes.NewBuffer(nil)
+		MustBeSuccessful(env.CatchOutput(buf).Execute("get", "sbom", "-r", "-o", "spdx", "--lookup", ARCH2, ARCH1+"//"+COMP+":"+VERSION))
+
+		var doc sbom.SPDXDocument
+		MustBeSuccessful(json.Unmarshal(buf.Bytes(), &doc))
+		Expect(doc.SPDXVersion).To(Equal("SPDX-2.3"))
+		Expect(len(doc.Packages)).To(Equal(3))
+		Expect(doc.Packages[2].Name).To(Equal(COMP2))
+		Expect(doc.Packages[2].Supplier).To(Equal("Organization: " + PROVIDER))
+		Expect(doc.Relationships).To(ContainElement(sbom.SPDXRelationship{
+			Element: "SPDXRef-ocm-test.de-x-v1",
+			Type:    "DEPENDS_ON",
+			Related: "SPDXRef-ocm-test.de-y-v1",
+		}))
+	})
+
+	It("fails for unresolvable references", func() {
+		ExpectError(env.Execute("get", "sbom", "-r", ARCH1+"//"+COMP+":"+VERSION)).To(MatchError(ContainSubstring(`component version "test.de/y:v1" not found`)))
+	})
+
+	It("rejects invalid format", func() {
+		ExpectError(env.Execute("get", "sbom", "-o", "xml", ARCH1+"//"+COMP+":"+VERSION)).To(MatchError(`output format "xml" is invalid`))
+	})
+})
@@ -0,0 +1,122 @@
+package add
+
+import (
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/resolvers"
+	ocmsign "ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/cmds/ocm/commands/common/options/keyoption"
+	ocmcommon "ocm.software/ocm/cmds/ocm/commands/ocmcmds/common"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/lookupoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/repooption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.SigningRequests
+	Verb  = verbs.Add
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	Keys keyoption.Option
+
+	Files []string
+}
+
+// NewCommand creates a new signing request add command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx, repooption.New(), lookupoption.New())}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] {<signing-request-file>}",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "add the signatures of signing requests to component versions",
+		Long: `
+Add the signatures gathered by signing requests (see
+<CMD>ocm create signingrequest</CMD> and <CMD>ocm sign signingrequest</CMD>)
+to the component versions in the repository given by option
+<code>--repo</code>.
+
+The digest of a component version is recalculated with the algorithms used
+for the request and must still match the digest of the request, otherwise
+the component version has been modified after the request has been created.
+All signatures must be verifiable, either with public keys given by
+option <code>--public-key</code> or with certificates provided by the
+signatures.
+` + keyoption.Usage(),
+		Example: `
+$ ocm add signingrequests --repo ghcr.io/acme --public-key acme=acme.pub --public-key other=other.pub request.yaml
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(fs *pflag.FlagSet) {
+	o.BaseCommand.AddFlags(fs)
+	o.Keys.AddFlags(fs)
+}
+
+func (o *Command) Complete(args []string) error {
+	o.Files = args
+	if repooption.From(o).Spec == "" {
+		return errors.Newf("repository required (option --repo)")
+	}
+	return o.Keys.Configure(o.OCMContext())
+}
+
+func (o *Command) Run() (rerr error) {
+	session := ocm.NewSession(nil)
+	defer errors.PropagateError(&rerr, session.Close)
+
+	err := o.ProcessOnOptions(ocmcommon.CompleteOptionsWithSession(o, session))
+	if err != nil {
+		return err
+	}
+
+	repo := repooption.From(o).Repository
+	resolver := resolvers.NewCompoundResolver(repo, lookupoption.From(o).Resolver)
+
+	list := errors.ErrListf("adding signatures")
+	for _, f := range o.Files {
+		err := o.add(session, repo, resolver, f)
+		if err != nil {
+			out.Outf(o, "failed adding signatures of %s: %s\n", f, err)
+		}
+		list.Add(errors.Wrapf(err, "%s", f))
+	}
+	return list.Result()
+}
+
+func (o *Command) add(session ocm.Session, repo ocm.Repository, resolver ocm.ComponentVersionResolver, file string) error {
+	req, err := ocmsign.LoadSigningRequest(file, o.FileSystem())
+	if err != nil {
+		return err
+	}
+	cv, err := session.LookupComponentVersion(repo, req.Component, req.Version)
+	if err != nil {
+		return err
+	}
+	err = ocmsign.AddSignatures(cv, req, ocmsign.Resolver(resolver), &o.Keys)
+	if err != nil {
+		return err
+	}
+	var sigs []string
+	for _, s := range req.Signatures {
+		sigs = append(sigs, s.Name)
+	}
+	out.Outf(o, "added signature(s) %s to %s:%s\n", strings.Join(sigs, ", "), req.Component, req.Version)
+	return nil
+}
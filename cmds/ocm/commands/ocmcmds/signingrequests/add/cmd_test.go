@@ -0,0 +1,112 @@
+package add_test
+
+import (
+	"bytes"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/extensions/repositories/ctf"
+	"ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/utils/accessio"
+	"ocm.software/ocm/api/utils/accessobj"
+	"ocm.software/ocm/api/utils/mime"
+)
+
+const (
+	ARCH      = "/tmp/ctf"
+	PROVIDER  = "mandelsoft"
+	VERSION   = "v1"
+	COMPONENT = "github.com/mandelsoft/test"
+	REQUEST   = "/tmp/request.yaml"
+)
+
+var _ = Describe("signing requests", func() {
+	var env *TestEnv
+
+	keys := func(names ...string) {
+		for _, n := range names {
+			priv, pub := Must2(rsa.Handler{}.CreateKeyPair())
+			MustBeSuccessful(vfs.WriteFile(env.FileSystem(), "/tmp/"+n+".priv", Must(rsa.KeyData(priv)), 0o600))
+			MustBeSuccessful(vfs.WriteFile(env.FileSystem(), "/tmp/"+n+".pub", Must(rsa.KeyData(pub)), 0o600))
+		}
+	}
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+		keys("alice", "bob")
+
+		env.OCMCommonTransport(ARCH, accessio.FormatDirectory, func() {
+			env.Component(COMPONENT, func() {
+				env.Version(VERSION, func() {
+					env.Provider(PROVIDER)
+					env.Resource("testdata", "", "PlainText", metav1.LocalRelation, func() {
+						env.BlobStringData(mime.MIME_TEXT, "testdata")
+					})
+				})
+			})
+		})
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("creates, signs and adds signatures", func() {
+		buf := bytes.NewBuffer(nil)
+		MustBeSuccessful(env.CatchOutput(buf).Execute("create", "signingrequest", "--repo", ARCH, "-O", REQUEST, COMPONENT+":"+VERSION))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`signing request for github.com/mandelsoft/test:v1 written to /tmp/request.yaml`))
+
+		req := Must(signing.LoadSigningRequest(REQUEST, env.FileSystem()))
+		Expect(req.Digest.HashAlgorithm).To(Equal("SHA-256"))
+		Expect(req.Digest.NormalisationAlgorithm).To(Equal("jsonNormalisation/v3"))
+
+		for _, n := range []string{"alice", "bob"} {
+			buf.Reset()
+			MustBeSuccessful(env.CatchOutput(buf).Execute("sign", "signingrequest", "-s", n, "--private-key", "/tmp/"+n+".priv", REQUEST))
+		}
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`signing request for github.com/mandelsoft/test:v1 signed with "bob" (2 signature(s))`))
+
+		buf.Reset()
+		MustBeSuccessful(env.CatchOutput(buf).Execute("add", "signingrequest", "--repo", ARCH, "--public-key", "alice=/tmp/alice.pub", "--public-key", "bob=/tmp/bob.pub", REQUEST))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`added signature(s) alice, bob to github.com/mandelsoft/test:v1`))
+
+		repo := Must(ctf.Open(env, accessobj.ACC_READONLY, ARCH, 0, env))
+		defer Close(repo, "repo")
+		cv := Must(repo.LookupComponentVersion(COMPONENT, VERSION))
+		defer Close(cv, "version")
+		Expect(cv.GetDescriptor().Signatures).To(HaveLen(2))
+
+		buf.Reset()
+		MustBeSuccessful(env.CatchOutput(buf).Execute("verify", "componentversions", "--repo", ARCH, "-s", "alice", "-s", "bob", "-s", "carol", "--threshold", "2",
+			"--public-key", "alice=/tmp/alice.pub", "--public-key", "bob=/tmp/bob.pub", COMPONENT+":"+VERSION))
+		Expect(buf.String()).To(ContainSubstring(`Warning: signature "carol" not found in github.com/mandelsoft/test:v1`))
+		Expect(buf.String()).To(ContainSubstring(`successfully verified github.com/mandelsoft/test:v1`))
+
+		buf.Reset()
+		ExpectError(env.CatchOutput(buf).Execute("verify", "componentversions", "--repo", ARCH, "-s", "alice", "-s", "bob", "-s", "carol", "--threshold", "3",
+			"--public-key", "alice=/tmp/alice.pub", "--public-key", "bob=/tmp/bob.pub", COMPONENT+":"+VERSION)).To(HaveOccurred())
+	})
+
+	It("rejects signatures for modified component version", func() {
+		MustBeSuccessful(env.Execute("create", "signingrequest", "--repo", ARCH, "-O", REQUEST, COMPONENT+":"+VERSION))
+		MustBeSuccessful(env.Execute("sign", "signingrequest", "-s", "alice", "--private-key", "/tmp/alice.priv", REQUEST))
+
+		repo := Must(ctf.Open(env, accessobj.ACC_WRITABLE, ARCH, 0, env))
+		cv := Must(repo.LookupComponentVersion(COMPONENT, VERSION))
+		MustBeSuccessful(cv.GetDescriptor().Labels.Set("modified", true, metav1.WithSigning()))
+		MustBeSuccessful(cv.Update())
+		Close(cv, "version")
+		Close(repo, "repo")
+
+		buf := bytes.NewBuffer(nil)
+		err := env.CatchOutput(buf).Execute("add", "signingrequest", "--repo", ARCH, "--public-key", "alice=/tmp/alice.pub", REQUEST)
+		Expect(err).To(MatchError(ContainSubstring("component version github.com/mandelsoft/test:v1 has been modified")))
+	})
+})
@@ -0,0 +1,25 @@
+package signingrequests
+
+import (
+	"github.com/spf13/cobra"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/signingrequests/add"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/signingrequests/create"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/signingrequests/sign"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var Names = names.SigningRequests
+
+// NewCommand creates a new command.
+func NewCommand(ctx clictx.Context) *cobra.Command {
+	cmd := utils.MassageCommand(&cobra.Command{
+		Short: "Commands acting on detached signing requests",
+	}, Names...)
+	cmd.AddCommand(create.NewCommand(ctx, create.Verb))
+	cmd.AddCommand(sign.NewCommand(ctx, sign.Verb))
+	cmd.AddCommand(add.NewCommand(ctx, add.Verb))
+	return cmd
+}
@@ -0,0 +1,143 @@
+package create
+
+import (
+	"fmt"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/ocm"
+	"ocm.software/ocm/api/ocm/resolvers"
+	"ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/api/utils/runtime"
+	ocmcommon "ocm.software/ocm/cmds/ocm/commands/ocmcmds/common"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/handlers/comphdlr"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/hashoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/lookupoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/repooption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/output"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.SigningRequests
+	Verb  = verbs.Create
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	Ref     string
+	OutFile string
+}
+
+// NewCommand creates a new signing request creation command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx, repooption.New(), lookupoption.New(), hashoption.New())}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] <component-reference>",
+		Args:  cobra.ExactArgs(1),
+		Short: "create a detached signing request for a component version",
+		Long: `
+Create a detached signing request for a component version. It contains the
+digest of the normalized component version, which can be signed offline by
+different parties with <CMD>ocm sign signingrequest</CMD> without access
+to the component version. The gathered signatures are finally added to the
+component version with <CMD>ocm add signingrequest</CMD>.
+
+The digests of resources and component references are calculated like for
+signing, but the component version is not modified.
+
+By default, the request is written to standard output. With option
+<code>--outfile</code> a file can be given.
+`,
+		Example: `
+$ ocm create signingrequest --outfile request.yaml ghcr.io/acme//acme.org/app:1.0.0
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(fs *pflag.FlagSet) {
+	o.BaseCommand.AddFlags(fs)
+	fs.StringVarP(&o.OutFile, "outfile", "O", "", "output file for signing request")
+}
+
+func (o *Command) Complete(args []string) error {
+	o.Ref = args[0]
+	return nil
+}
+
+func (o *Command) Run() (err error) {
+	session := ocm.NewSession(nil)
+	defer errors.PropagateError(&err, session.Close)
+
+	err = o.ProcessOnOptions(ocmcommon.CompleteOptionsWithSession(o, session))
+	if err != nil {
+		return err
+	}
+
+	handler := comphdlr.NewTypeHandler(o.Context.OCM(), session, repooption.From(o).Repository)
+	return utils.HandleOutput(&action{cmd: o}, handler, utils.StringElemSpecs(o.Ref)...)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type action struct {
+	cmd  *Command
+	data comphdlr.Objects
+}
+
+var _ output.Output = (*action)(nil)
+
+func (a *action) Add(e interface{}) error {
+	o, ok := e.(*comphdlr.Object)
+	if !ok {
+		return fmt.Errorf("object of type %T is not a valid comphdlr.Object", e)
+	}
+	if o.ComponentVersion == nil {
+		return errors.ErrNotFound(ocm.KIND_COMPONENTVERSION, o.Spec.String())
+	}
+	a.data = append(a.data, o)
+	return nil
+}
+
+func (a *action) Close() error {
+	return nil
+}
+
+func (a *action) Out() error {
+	if len(a.data) != 1 {
+		return fmt.Errorf("exactly one component version required, but %d found", len(a.data))
+	}
+	o := a.data[0]
+	req, err := signing.CreateSigningRequest(o.ComponentVersion,
+		signing.Resolver(resolvers.NewCompoundResolver(o.Repository, lookupoption.From(a.cmd).Resolver)),
+		hashoption.From(a.cmd),
+	)
+	if err != nil {
+		return err
+	}
+	if a.cmd.OutFile != "" {
+		err = signing.SaveSigningRequest(req, a.cmd.OutFile, a.cmd.FileSystem())
+		if err != nil {
+			return err
+		}
+		out.Outf(a.cmd, "signing request for %s:%s written to %s\n", req.Component, req.Version, a.cmd.OutFile)
+		return nil
+	}
+	data, err := runtime.DefaultYAMLEncoding.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = a.cmd.StdOut().Write(data)
+	return err
+}
@@ -0,0 +1,130 @@
+package sign
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	ocmsign "ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/tech/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/tech/signing/signutils"
+	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/cmds/ocm/commands/common/options/keyoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.SigningRequests
+	Verb  = verbs.Sign
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	Keys keyoption.Option
+
+	File      string
+	OutFile   string
+	Signature string
+	Algorithm string
+	UseTSA    bool
+	TSAUrl    string
+
+	Request *ocmsign.SigningRequest
+}
+
+// NewCommand creates a new signing request sign command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx)}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] <signing-request-file>",
+		Args:  cobra.ExactArgs(1),
+		Short: "sign a detached signing request",
+		Long: `
+Sign the component version digest of a signing request created with
+<CMD>ocm create signingrequest</CMD> and add the signature to the request.
+No access to the component version is required, so this can be done offline
+by different parties, each adding its own signature. An existing signature
+with the same name is replaced.
+
+The signature name is given with option <code>--signature</code>. By default,
+the signing request file is updated. With option <code>--outfile</code>
+another file can be given.
+` + keyoption.Usage(),
+		Example: `
+$ ocm sign signingrequest --signature acme --private-key acme.key request.yaml
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(fs *pflag.FlagSet) {
+	o.BaseCommand.AddFlags(fs)
+	o.Keys.AddFlags(fs)
+	fs.StringVarP(&o.Signature, "signature", "s", "", "signature name")
+	fs.StringVarP(&o.Algorithm, "algorithm", "S", rsa.Algorithm, "signature handler")
+	fs.BoolVarP(&o.UseTSA, "tsa", "", false, fmt.Sprintf("use timestamp authority (default server: %s)", signing.DEFAULT_TSA_URL))
+	fs.StringVarP(&o.TSAUrl, "tsa-url", "", "", "TSA server URL")
+	fs.StringVarP(&o.OutFile, "outfile", "O", "", "output file for signed signing request")
+}
+
+func (o *Command) Complete(args []string) error {
+	var err error
+
+	o.File = args[0]
+	n := strings.TrimSpace(o.Signature)
+	if n == "" {
+		return errors.Newf("signature name required (option --signature)")
+	}
+	dn, err := signutils.ParseDN(n)
+	if err != nil {
+		return err
+	}
+	o.Signature = signutils.NormalizeDN(*dn)
+	o.Keys.DefaultName = o.Signature
+	err = o.Keys.Configure(o.OCMContext())
+	if err != nil {
+		return err
+	}
+	if o.OutFile == "" {
+		o.OutFile = o.File
+	}
+	o.Request, err = ocmsign.LoadSigningRequest(o.File, o.FileSystem())
+	return err
+}
+
+func (o *Command) Run() error {
+	opts := []ocmsign.Option{
+		ocmsign.SignerByAlgo(o.Algorithm),
+		&o.Keys,
+	}
+	if def := o.Keys.Keys.GetIssuer(""); def != nil {
+		opts = append(opts, ocmsign.PKIXIssuer(*def))
+	}
+	if o.UseTSA || o.TSAUrl != "" {
+		opts = append(opts, ocmsign.UseTSA())
+		if o.TSAUrl != "" {
+			opts = append(opts, ocmsign.TSAUrl(o.TSAUrl))
+		}
+	}
+	err := ocmsign.SignSigningRequest(o.OCMContext(), o.Request, o.Signature, opts...)
+	if err != nil {
+		return err
+	}
+	err = ocmsign.SaveSigningRequest(o.Request, o.OutFile, o.FileSystem())
+	if err != nil {
+		return err
+	}
+	out.Outf(o, "signing request for %s:%s signed with %q (%d signature(s))\n", o.Request.Component, o.Request.Version, o.Signature, len(o.Request.Signatures))
+	return nil
+}
@@ -0,0 +1,142 @@
+package add
+
+import (
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/oci"
+	"ocm.software/ocm/api/ocm/tools/signing"
+	common2 "ocm.software/ocm/api/utils/misc"
+	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/cmds/ocm/commands/common/options/keyoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/storeoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/verified/common"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.Verified
+	Verb  = verbs.Add
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	Keys keyoption.Option
+
+	path      string
+	Overwrite bool
+	Force     bool
+	Sources   []string
+}
+
+// NewCommand creates a new command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx)}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] {<store file> | <artifact reference>}",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "merge verified component versions into the verified store",
+		Long: `
+Merge the entries of other verified stores into the local store of verified
+component versions (see option <code>--verified</code>). This can be used to
+share the verification results of several environments.
+
+A source can be a file (for example, a copy of the store file of another
+machine) or a tagged OCI artifact created with <code>ocm transfer verified</code>.
+Existing files and arguments without repository or version are taken as file
+paths, all other arguments as OCI artifact references.
+
+Because the local store is trusted by later verifications, the entries of
+the sources are verified before they are merged. An entry is accepted, if one
+of its signatures can be verified with the public keys given by option
+<code>--public-key</code> (or configured for the signing context) or with the
+certificate provided by the signature. Entries without verifiable signature
+are accepted, if they are referenced with a matching digest by another accepted
+entry or an entry of the local store. Other entries are rejected. With option
+<code>--force</code> the entries are merged without verification.
+
+Entries for the same component version are merged, if they describe the same
+component descriptor. Otherwise, the entries are reported as conflict and
+the local entry is kept. With option <code>--overwrite</code> the local entry
+is replaced.
+` + keyoption.Usage(),
+		Example: `
+$ ocm add verified --public-key acme=acme.pub verified-agent1.yaml verified-agent2.yaml
+$ ocm add verified ghcr.io/acme/verified:latest
+$ ocm add verified --overwrite ./ctf//verified:v1
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(fs *pflag.FlagSet) {
+	o.BaseCommand.AddFlags(fs)
+	fs.StringVarP(&o.path, "verified", "", storeoption.DEFAULT_VERIFIED_FILE, "verified file")
+	fs.BoolVarP(&o.Overwrite, "overwrite", "", false, "replace conflicting entries")
+	fs.BoolVarP(&o.Force, "force", "", false, "merge entries without verification")
+	o.Keys.AddFlags(fs)
+}
+
+func (o *Command) Complete(args []string) error {
+	o.Sources = args
+	return o.Keys.Configure(o.Context.OCMContext())
+}
+
+func (o *Command) Run() (err error) {
+	session := oci.NewSession(nil)
+	defer errors.PropagateError(&err, session.Close)
+
+	store, err := signing.NewVerifiedStore(o.path, o.Context.FileSystem())
+	if err != nil {
+		return err
+	}
+
+	var sources []signing.VerifiedStore
+	var rejected []common2.NameVersion
+	for _, s := range o.Sources {
+		loc, err := common.ParseLocation(o.Context, s)
+		if err != nil {
+			return err
+		}
+		src, err := common.Read(o.Context, session, loc)
+		if err != nil {
+			return errors.Wrapf(err, "source %q", s)
+		}
+		if !o.Force {
+			var list []common2.NameVersion
+			src, list, err = signing.VerifyStoreEntries(o.Context, src, store, &o.Keys)
+			if err != nil {
+				return errors.Wrapf(err, "source %q", s)
+			}
+			for _, nv := range list {
+				out.Outf(o.Context, "rejected %s from %s: no verifiable signature\n", nv, s)
+			}
+			rejected = append(rejected, list...)
+		}
+		sources = append(sources, src)
+	}
+
+	merged, merr := signing.MergeVerifiedStores(store, o.Overwrite, sources...)
+	for _, nv := range merged {
+		out.Outf(o.Context, "merged %s\n", nv)
+	}
+	if len(merged) > 0 {
+		err = store.Save()
+		if err != nil {
+			return errors.Wrapf(err, "cannot save verified store %q", o.path)
+		}
+	}
+	out.Outf(o.Context, "%d component version(s) merged\n", len(merged))
+	if merr == nil && len(rejected) > 0 {
+		return errors.Newf("%d unverifiable component version(s) rejected", len(rejected))
+	}
+	return merr
+}
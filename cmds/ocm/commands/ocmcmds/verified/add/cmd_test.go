@@ -0,0 +1,139 @@
+package add_test
+
+import (
+	"bytes"
+	"encoding/pem"
+	"os"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"ocm.software/ocm/api/credentials"
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/compdesc/versions/ocm.software/v3alpha1"
+	"ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/tech/signing/handlers/rsa"
+	"ocm.software/ocm/api/tech/signing/hasher/sha256"
+	"ocm.software/ocm/api/tech/signing/signutils"
+)
+
+const (
+	COMPONENTA = "acme.org/compa"
+	COMPONENTB = "acme.org/compb"
+	VERSION    = "v1"
+)
+
+const (
+	VERIFIED_FILE = "verified.yaml"
+	AGENT1_FILE   = "agent1.yaml"
+	AGENT2_FILE   = "agent2.yaml"
+	PUBKEY        = "/tmp/pub"
+	SIGNATURE     = "signature"
+)
+
+func descriptor(name, provider string) *compdesc.ComponentDescriptor {
+	return compdesc.DefaultComponent(&compdesc.ComponentDescriptor{
+		Metadata: compdesc.Metadata{
+			ConfiguredVersion: v3alpha1.SchemaVersion,
+		},
+		ComponentSpec: compdesc.ComponentSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:    name,
+				Version: VERSION,
+				Provider: metav1.Provider{
+					Name: metav1.ProviderName(provider),
+				},
+			},
+		},
+	})
+}
+
+func signed(cd *compdesc.ComponentDescriptor, key interface{}) *compdesc.ComponentDescriptor {
+	MustBeSuccessful(compdesc.Sign(credentials.DefaultContext(), cd, key, rsa.NewHandler(), sha256.Handler{}, SIGNATURE, "acme.org"))
+	// the v3alpha1 schema does not support issuers
+	cd.Signatures[0].Signature.Issuer = ""
+	return cd
+}
+
+func store(env *TestEnv, path string, cds ...*compdesc.ComponentDescriptor) signing.VerifiedStore {
+	s := Must(signing.NewVerifiedStore(path, env))
+	for _, cd := range cds {
+		s.Add(cd, SIGNATURE)
+	}
+	MustBeSuccessful(s.Save())
+	return s
+}
+
+var _ = Describe("add verified", func() {
+	var env *TestEnv
+
+	priv, pub := Must2(rsa.Handler{}.CreateKeyPair())
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+		store(env, VERIFIED_FILE, signed(descriptor(COMPONENTA, "acme.org"), priv))
+		MustBeSuccessful(vfs.WriteFile(env.FileSystem(), PUBKEY, pem.EncodeToMemory(signutils.PemBlockForPublicKey(pub)), os.ModePerm))
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("merges stores", func() {
+		store(env, AGENT1_FILE, signed(descriptor(COMPONENTA, "acme.org"), priv))
+		store(env, AGENT2_FILE, signed(descriptor(COMPONENTB, "acme.org"), priv))
+
+		var buf bytes.Buffer
+		Expect(env.CatchOutput(&buf).Execute("add", "verified", "--verified", VERIFIED_FILE, "--public-key", SIGNATURE+"="+PUBKEY, AGENT1_FILE, AGENT2_FILE)).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+merged acme.org/compb:v1
+1 component version(s) merged
+`))
+		s := Must(signing.NewVerifiedStore(VERIFIED_FILE, env))
+		Expect(s.Get(descriptor(COMPONENTB, "acme.org"))).NotTo(BeNil())
+	})
+
+	It("reports conflicts", func() {
+		store(env, AGENT1_FILE, signed(descriptor(COMPONENTA, "other.org"), priv))
+
+		var buf bytes.Buffer
+		Expect(env.CatchOutput(&buf).Execute("add", "verified", "--verified", VERIFIED_FILE, "--public-key", SIGNATURE+"="+PUBKEY, AGENT1_FILE)).To(MatchError(`conflicting verified entries: verified component version "acme.org/compa:v1" already exists`))
+		s := Must(signing.NewVerifiedStore(VERIFIED_FILE, env))
+		Expect(s.Get(descriptor(COMPONENTA, "")).Provider.Name).To(Equal(metav1.ProviderName("acme.org")))
+
+		buf.Reset()
+		Expect(env.CatchOutput(&buf).Execute("add", "verified", "--verified", VERIFIED_FILE, "--public-key", SIGNATURE+"="+PUBKEY, "--overwrite", AGENT1_FILE)).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+merged acme.org/compa:v1
+1 component version(s) merged
+`))
+		s = Must(signing.NewVerifiedStore(VERIFIED_FILE, env))
+		Expect(s.Get(descriptor(COMPONENTA, "")).Provider.Name).To(Equal(metav1.ProviderName("other.org")))
+	})
+
+	It("rejects unverifiable entries", func() {
+		other, _ := Must2(rsa.Handler{}.CreateKeyPair())
+		store(env, AGENT1_FILE, signed(descriptor(COMPONENTB, "acme.org"), other))
+
+		var buf bytes.Buffer
+		Expect(env.CatchOutput(&buf).Execute("add", "verified", "--verified", VERIFIED_FILE, "--public-key", SIGNATURE+"="+PUBKEY, AGENT1_FILE)).To(MatchError(`1 unverifiable component version(s) rejected`))
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+rejected acme.org/compb:v1 from agent1.yaml: no verifiable signature
+0 component version(s) merged
+`))
+		s := Must(signing.NewVerifiedStore(VERIFIED_FILE, env))
+		Expect(s.Get(descriptor(COMPONENTB, ""))).To(BeNil())
+
+		buf.Reset()
+		Expect(env.CatchOutput(&buf).Execute("add", "verified", "--verified", VERIFIED_FILE, "--force", AGENT1_FILE)).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+merged acme.org/compb:v1
+1 component version(s) merged
+`))
+	})
+})
@@ -0,0 +1,147 @@
+package check
+
+import (
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/storeoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/output"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.Verified
+	Verb  = verbs.Check
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	path    string
+	Digests []string
+}
+
+// NewCommand creates a new command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(
+		&Command{
+			BaseCommand: utils.NewBaseCommand(ctx, output.OutputOptions(outputs)),
+		},
+		utils.Names(Names, names...)...,
+	)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] {<digest>}",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "check whether resource digests are covered by verified component versions",
+		Long: `
+Check whether resources with the given digests are described by component
+versions found in the local store of verified component versions (see option
+<code>--verified</code>). All matching resources are listed. The command fails,
+if a digest is not covered by any verified component version.
+
+A digest may be given with an algorithm prefix (for example,
+<code>sha256:...</code>), which is ignored for the comparison.
+`,
+		Example: `
+$ ocm check verified sha256:810ff2fb242a5dee4220f2cb0e6a519891fb67f2f828a6cab4ef8894633b1f50
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(fs *pflag.FlagSet) {
+	o.BaseCommand.AddFlags(fs)
+	fs.StringVarP(&o.path, "verified", "", storeoption.DEFAULT_VERIFIED_FILE, "verified file")
+}
+
+func (o *Command) Complete(args []string) error {
+	o.Digests = args
+	return nil
+}
+
+func (o *Command) Run() error {
+	store, err := signing.NewVerifiedStore(o.path, o.Context.FileSystem())
+	if err != nil {
+		return err
+	}
+	hdlr := &typeHandler{store: store}
+	err = utils.HandleArgs(output.From(o), hdlr, o.Digests...)
+	if err != nil {
+		return err
+	}
+	if len(hdlr.missing) > 0 {
+		return errors.Newf("digest(s) not covered by verified component versions: %s", strings.Join(hdlr.missing, ", "))
+	}
+	return nil
+}
+
+/////////////////////////////////////////////////////////////////////////////
+
+type Object struct {
+	Digest    string          `json:"digest"`
+	Component string          `json:"component"`
+	Version   string          `json:"version"`
+	Resource  metav1.Identity `json:"resource"`
+}
+
+func (o *Object) AsManifest() interface{} {
+	return o
+}
+
+type typeHandler struct {
+	store   signing.VerifiedStore
+	missing []string
+}
+
+func (h *typeHandler) Close() error {
+	return nil
+}
+
+func (h *typeHandler) All() ([]output.Object, error) {
+	return nil, nil
+}
+
+func (h *typeHandler) Get(elemspec utils.ElemSpec) ([]output.Object, error) {
+	var result []output.Object
+
+	for _, r := range signing.FindResourcesByDigest(h.store, elemspec.String()) {
+		result = append(result, &Object{
+			Digest:    r.Digest.String(),
+			Component: r.ComponentVersion.GetName(),
+			Version:   r.ComponentVersion.GetVersion(),
+			Resource:  r.Identity,
+		})
+	}
+	if len(result) == 0 {
+		h.missing = append(h.missing, elemspec.String())
+	}
+	return result, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////
+
+var outputs = output.NewOutputs(getRegular).AddManifestOutputs()
+
+func getRegular(opts *output.Options) output.Output {
+	return (&output.TableOutput{
+		Headers: output.Fields("COMPONENT", "VERSION", "RESOURCE", "DIGEST"),
+		Options: opts,
+		Mapping: mapGetRegularOutput,
+	}).New()
+}
+
+func mapGetRegularOutput(e interface{}) interface{} {
+	p := e.(*Object)
+	return []string{p.Component, p.Version, p.Resource.String(), p.Digest}
+}
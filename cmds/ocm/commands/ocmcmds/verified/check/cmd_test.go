@@ -0,0 +1,95 @@
+package check_test
+
+import (
+	"bytes"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/compdesc/versions/ocm.software/v3alpha1"
+	"ocm.software/ocm/api/ocm/extensions/accessmethods/localblob"
+	"ocm.software/ocm/api/ocm/tools/signing"
+)
+
+const (
+	COMPONENTA = "acme.org/compa"
+	COMPONENTB = "acme.org/compb"
+	VERSION    = "v1"
+)
+
+const VERIFIED_FILE = "verified.yaml"
+
+const (
+	DIGEST1 = "810ff2fb242a5dee4220f2cb0e6a519891fb67f2f828a6cab4ef8894633b1f50"
+	DIGEST2 = "0c4abdb72cf59cb4b77f4aacb4775f9f546ebc3face189b2224a966c8826ca9f"
+)
+
+func descriptor(name string, digest string) *compdesc.ComponentDescriptor {
+	cd := compdesc.DefaultComponent(&compdesc.ComponentDescriptor{
+		Metadata: compdesc.Metadata{
+			ConfiguredVersion: v3alpha1.SchemaVersion,
+		},
+		ComponentSpec: compdesc.ComponentSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:    name,
+				Version: VERSION,
+				Provider: metav1.Provider{
+					Name: "acme.org",
+				},
+			},
+		},
+	})
+	cd.Resources = append(cd.Resources, compdesc.Resource{
+		ResourceMeta: compdesc.ResourceMeta{
+			ElementMeta: compdesc.ElementMeta{
+				Name:    "testdata",
+				Version: VERSION,
+			},
+			Type:     "PlainText",
+			Relation: metav1.LocalRelation,
+			Digest: &metav1.DigestSpec{
+				HashAlgorithm:          "SHA-256",
+				NormalisationAlgorithm: "genericBlobDigest/v1",
+				Value:                  digest,
+			},
+		},
+		Access: localblob.New("blob", "", "text/plain", nil),
+	})
+	return cd
+}
+
+var _ = Describe("check verified", func() {
+	var env *TestEnv
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+		s := Must(signing.NewVerifiedStore(VERIFIED_FILE, env))
+		s.Add(descriptor(COMPONENTA, DIGEST1), "a")
+		s.Add(descriptor(COMPONENTB, DIGEST1), "b")
+		MustBeSuccessful(s.Save())
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("lists covered resources", func() {
+		var buf bytes.Buffer
+		Expect(env.CatchOutput(&buf).Execute("check", "verified", "--verified", VERIFIED_FILE, "sha256:"+DIGEST1)).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+COMPONENT      VERSION RESOURCE          DIGEST
+acme.org/compa v1      "name"="testdata" SHA-256:` + DIGEST1 + `[genericBlobDigest/v1]
+acme.org/compb v1      "name"="testdata" SHA-256:` + DIGEST1 + `[genericBlobDigest/v1]
+`))
+	})
+
+	It("fails for uncovered digests", func() {
+		var buf bytes.Buffer
+		Expect(env.CatchOutput(&buf).Execute("check", "verified", "--verified", VERIFIED_FILE, DIGEST1, DIGEST2)).To(MatchError("digest(s) not covered by verified component versions: " + DIGEST2))
+		Expect(buf.String()).To(ContainSubstring("acme.org/compb v1"))
+	})
+})
@@ -0,0 +1,103 @@
+package clean
+
+import (
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/ocm/tools/signing"
+	utils2 "ocm.software/ocm/api/utils"
+	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/dryrunoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/storeoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.Verified
+	Verb  = verbs.Clean
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	path       string
+	Components []string
+
+	MaxAge string
+	before time.Time
+}
+
+// NewCommand creates a new command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx, dryrunoption.New("only report the entries to be removed", false))}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] {<component>}",
+		Short: "prune the verified store",
+		Long: `
+Remove entries from the local store of verified component versions (see
+option <code>--verified</code>).
+
+With option <code>--max-age</code> only entries verified before the given
+time span are removed. Entries without verification time (created by older
+versions of the ocm CLI) are always removed. If components are given, only
+entries for those components are considered. At least one of both must be
+given.
+
+With option <code>--dry-run</code> the entries are only reported.
+`,
+		Example: `
+$ ocm clean verified --max-age 30d
+$ ocm clean verified --dry-run acme.org/app
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(fs *pflag.FlagSet) {
+	o.BaseCommand.AddFlags(fs)
+	fs.StringVarP(&o.path, "verified", "", storeoption.DEFAULT_VERIFIED_FILE, "verified file")
+	fs.StringVarP(&o.MaxAge, "max-age", "", "", "remove entries verified before the given time span (e.g. 30d)")
+}
+
+func (o *Command) Complete(args []string) error {
+	o.Components = args
+	if o.MaxAge != "" {
+		t, err := utils2.ParseDeltaTime(o.MaxAge, true)
+		if err != nil {
+			return err
+		}
+		o.before = t
+	}
+	if o.MaxAge == "" && len(o.Components) == 0 {
+		return errors.Newf("option --max-age or component required")
+	}
+	return nil
+}
+
+func (o *Command) Run() error {
+	store, err := signing.NewVerifiedStore(o.path, o.Context.FileSystem())
+	if err != nil {
+		return err
+	}
+	pruned := signing.PruneVerifiedStore(store, o.before, o.Components...)
+	if len(pruned) == 0 {
+		out.Outf(o.Context, "no verified component version to remove\n")
+		return nil
+	}
+	for _, nv := range pruned {
+		out.Outf(o.Context, "removing %s\n", nv)
+	}
+	if dryrunoption.From(o).DryRun {
+		return nil
+	}
+	return errors.Wrapf(store.Save(), "cannot save verified store %q", o.path)
+}
@@ -0,0 +1,86 @@
+package clean_test
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/compdesc/versions/ocm.software/v3alpha1"
+	"ocm.software/ocm/api/ocm/tools/signing"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const (
+	COMPONENTA = "acme.org/compa"
+	COMPONENTB = "acme.org/compb"
+	VERSION    = "v1"
+)
+
+const VERIFIED_FILE = "verified.yaml"
+
+func descriptor(name string) *compdesc.ComponentDescriptor {
+	return compdesc.DefaultComponent(&compdesc.ComponentDescriptor{
+		Metadata: compdesc.Metadata{
+			ConfiguredVersion: v3alpha1.SchemaVersion,
+		},
+		ComponentSpec: compdesc.ComponentSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:    name,
+				Version: VERSION,
+				Provider: metav1.Provider{
+					Name: "acme.org",
+				},
+			},
+		},
+	})
+}
+
+var _ = Describe("clean verified", func() {
+	var env *TestEnv
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+		s := Must(signing.NewVerifiedStore(VERIFIED_FILE, env))
+		s.Add(descriptor(COMPONENTB), "b")
+		s.AddEntry(&signing.StorageEntry{
+			Signatures: []string{"a"},
+			Verified:   metav1.NewTimestampPFor(time.Now().Add(-48 * time.Hour)),
+			Descriptor: (*compdesc.GenericComponentDescriptor)(descriptor(COMPONENTA)),
+		})
+		MustBeSuccessful(s.Save())
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("prunes outdated entries", func() {
+		var buf bytes.Buffer
+		Expect(env.CatchOutput(&buf).Execute("clean", "verified", "--verified", VERIFIED_FILE, "--max-age", "1d")).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+removing acme.org/compa:v1
+`))
+		s := Must(signing.NewVerifiedStore(VERIFIED_FILE, env))
+		Expect(s.Entries()).To(ConsistOf(common.NewNameVersion(COMPONENTB, VERSION)))
+	})
+
+	It("reports entries of a component with dry-run", func() {
+		var buf bytes.Buffer
+		Expect(env.CatchOutput(&buf).Execute("clean", "verified", "--verified", VERIFIED_FILE, "--dry-run", COMPONENTB)).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+removing acme.org/compb:v1
+`))
+		s := Must(signing.NewVerifiedStore(VERIFIED_FILE, env))
+		Expect(s.Entries()).To(HaveLen(2))
+	})
+
+	It("requires a selection", func() {
+		Expect(env.Execute("clean", "verified", "--verified", VERIFIED_FILE)).To(MatchError("option --max-age or component required"))
+	})
+})
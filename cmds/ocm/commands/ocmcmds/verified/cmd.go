@@ -5,7 +5,11 @@ import (
 
 	clictx "ocm.software/ocm/api/cli"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/verified/add"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/verified/check"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/verified/clean"
 	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/verified/get"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/verified/transfer"
 	"ocm.software/ocm/cmds/ocm/common/utils"
 )
 
@@ -17,5 +21,9 @@ func NewCommand(ctx clictx.Context) *cobra.Command {
 		Short: "Commands acting on verified component versions",
 	}, Names...)
 	cmd.AddCommand(get.NewCommand(ctx, get.Verb))
+	cmd.AddCommand(add.NewCommand(ctx, add.Verb))
+	cmd.AddCommand(transfer.NewCommand(ctx, transfer.Verb))
+	cmd.AddCommand(clean.NewCommand(ctx, clean.Verb))
+	cmd.AddCommand(check.NewCommand(ctx, check.Verb))
 	return cmd
 }
@@ -0,0 +1,78 @@
+package common
+
+import (
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/oci"
+	"ocm.software/ocm/api/oci/extensions/repositories/ctf"
+	"ocm.software/ocm/api/ocm/tools/signing"
+)
+
+// Location describes the location of a verified store.
+// It is either a file or a tagged artifact in an OCI repository.
+type Location struct {
+	File     string
+	Artifact *oci.RefSpec
+}
+
+func (l *Location) String() string {
+	if l.Artifact != nil {
+		return l.Artifact.String()
+	}
+	return l.File
+}
+
+// ParseLocation parses a verified store location. Existing files and
+// specifications without a repository or version are taken as file paths,
+// all others as OCI artifact references.
+func ParseLocation(ctx clictx.Context, spec string) (*Location, error) {
+	if ok, err := vfs.FileExists(ctx.FileSystem(), spec); ok || err != nil {
+		return &Location{File: spec}, err
+	}
+	ref, err := oci.ParseRef(spec)
+	if err != nil || ref.Repository == "" || !ref.IsVersion() {
+		return &Location{File: spec}, nil
+	}
+	return &Location{Artifact: &ref}, nil
+}
+
+// Read reads a verified store from the given location.
+func Read(ctx clictx.Context, session oci.Session, loc *Location) (signing.VerifiedStore, error) {
+	if loc.Artifact == nil {
+		data, err := vfs.ReadFile(ctx.FileSystem(), loc.File)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read verified store %q", loc.File)
+		}
+		return signing.DecodeVerifiedStore(data)
+	}
+	repo, err := session.DetermineRepositoryBySpec(ctx.OCIContext(), &loc.Artifact.UniformRepositorySpec)
+	if err != nil {
+		return nil, err
+	}
+	return signing.PullVerifiedStore(repo, loc.Artifact.Repository, loc.Artifact.Version())
+}
+
+// Write writes a verified store to the given location. For OCI artifacts
+// it returns the digest of the created artifact.
+func Write(ctx clictx.Context, session oci.Session, loc *Location, store signing.VerifiedStore) (string, error) {
+	if loc.Artifact == nil {
+		data, err := signing.EncodeVerifiedStore(store)
+		if err != nil {
+			return "", err
+		}
+		return "", vfs.WriteFile(ctx.FileSystem(), loc.File, data, 0o600)
+	}
+	if loc.Artifact.Digest != nil {
+		return "", errors.Newf("verified store target %q must use a tag", loc)
+	}
+	spec := *loc.Artifact
+	spec.CreateIfMissing = true
+	spec.TypeHint = ctf.Type
+	repo, err := session.DetermineRepositoryBySpec(ctx.OCIContext(), &spec.UniformRepositorySpec)
+	if err != nil {
+		return "", err
+	}
+	return signing.PushVerifiedStore(repo, spec.Repository, *spec.Tag, store)
+}
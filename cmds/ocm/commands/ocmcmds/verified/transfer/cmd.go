@@ -0,0 +1,111 @@
+package transfer
+
+import (
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/api/oci"
+	"ocm.software/ocm/api/ocm/tools/signing"
+	"ocm.software/ocm/api/utils/out"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/common/options/storeoption"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/names"
+	"ocm.software/ocm/cmds/ocm/commands/ocmcmds/verified/common"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+var (
+	Names = names.Verified
+	Verb  = verbs.Transfer
+)
+
+type Command struct {
+	utils.BaseCommand
+
+	path       string
+	Components []string
+	Target     string
+}
+
+// NewCommand creates a new command.
+func NewCommand(ctx clictx.Context, names ...string) *cobra.Command {
+	return utils.SetupCommand(&Command{BaseCommand: utils.NewBaseCommand(ctx)}, utils.Names(Names, names...)...)
+}
+
+func (o *Command) ForName(name string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "[<options>] {<component>} <store file> | <artifact reference>",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "export the verified store",
+		Long: `
+Export the local store of verified component versions (see option
+<code>--verified</code>) to a file or a tagged OCI artifact. The target is
+taken as OCI artifact reference, if it describes a repository and a tag,
+otherwise it is a file path. Artifacts can be stored in an OCI registry or
+a *Common Transport Format* archive, which is created if it does not exist.
+
+If components are given, only the entries for those components are exported.
+The exported store can be imported with <code>ocm add verified</code>.
+`,
+		Example: `
+$ ocm transfer verified verified-export.yaml
+$ ocm transfer verified ghcr.io/acme/verified:latest
+$ ocm transfer verified acme.org/app ./ctf//verified:v1
+`,
+		Annotations: map[string]string{"ExampleCodeStyle": "bash"},
+	}
+}
+
+func (o *Command) AddFlags(fs *pflag.FlagSet) {
+	o.BaseCommand.AddFlags(fs)
+	fs.StringVarP(&o.path, "verified", "", storeoption.DEFAULT_VERIFIED_FILE, "verified file")
+}
+
+func (o *Command) Complete(args []string) error {
+	o.Target = args[len(args)-1]
+	o.Components = args[:len(args)-1]
+	return nil
+}
+
+func (o *Command) Run() (err error) {
+	session := oci.NewSession(nil)
+	defer errors.PropagateError(&err, session.Close)
+
+	store, err := signing.NewVerifiedStore(o.path, o.Context.FileSystem())
+	if err != nil {
+		return err
+	}
+	if len(o.Components) > 0 {
+		selected := signing.NewLocalVerifiedStore()
+		for _, c := range o.Components {
+			found := false
+			for _, nv := range store.Entries() {
+				if nv.GetName() == c {
+					selected.AddEntry(store.GetEntry(nv))
+					found = true
+				}
+			}
+			if !found {
+				return errors.ErrNotFound(signing.KIND_VERIFIED_ENTRY, c)
+			}
+		}
+		store = selected
+	}
+
+	loc, err := common.ParseLocation(o.Context, o.Target)
+	if err != nil {
+		return err
+	}
+	digest, err := common.Write(o.Context, session, loc, store)
+	if err != nil {
+		return errors.Wrapf(err, "cannot write verified store to %q", o.Target)
+	}
+	if digest != "" {
+		out.Outf(o.Context, "%d component version(s) transferred to %s@%s\n", len(store.Entries()), loc, digest)
+	} else {
+		out.Outf(o.Context, "%d component version(s) transferred to %s\n", len(store.Entries()), loc)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package transfer_test
+
+import (
+	"bytes"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "ocm.software/ocm/cmds/ocm/testhelper"
+
+	"ocm.software/ocm/api/ocm/compdesc"
+	metav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/compdesc/versions/ocm.software/v3alpha1"
+	"ocm.software/ocm/api/ocm/tools/signing"
+	common "ocm.software/ocm/api/utils/misc"
+)
+
+const (
+	COMPONENTA = "acme.org/compa"
+	COMPONENTB = "acme.org/compb"
+	VERSION    = "v1"
+)
+
+const (
+	VERIFIED_FILE = "verified.yaml"
+	IMPORT_FILE   = "imported.yaml"
+	EXPORT_FILE   = "export.yaml"
+	ARCH          = "/tmp/ctf"
+)
+
+func descriptor(name string) *compdesc.ComponentDescriptor {
+	return compdesc.DefaultComponent(&compdesc.ComponentDescriptor{
+		Metadata: compdesc.Metadata{
+			ConfiguredVersion: v3alpha1.SchemaVersion,
+		},
+		ComponentSpec: compdesc.ComponentSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:    name,
+				Version: VERSION,
+				Provider: metav1.Provider{
+					Name: "acme.org",
+				},
+			},
+		},
+	})
+}
+
+var _ = Describe("transfer verified", func() {
+	var env *TestEnv
+
+	BeforeEach(func() {
+		env = NewTestEnv()
+		s := Must(signing.NewVerifiedStore(VERIFIED_FILE, env))
+		s.Add(descriptor(COMPONENTA), "a")
+		s.Add(descriptor(COMPONENTB), "b")
+		MustBeSuccessful(s.Save())
+	})
+
+	AfterEach(func() {
+		env.Cleanup()
+	})
+
+	It("exports selected components to file", func() {
+		var buf bytes.Buffer
+		Expect(env.CatchOutput(&buf).Execute("transfer", "verified", "--verified", VERIFIED_FILE, COMPONENTB, EXPORT_FILE)).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+1 component version(s) transferred to export.yaml
+`))
+		s := Must(signing.DecodeVerifiedStore(Must(env.ReadFile(EXPORT_FILE))))
+		Expect(s.Entries()).To(ConsistOf(common.NewNameVersion(COMPONENTB, VERSION)))
+	})
+
+	It("exports to ctf and imports again", func() {
+		var buf bytes.Buffer
+		Expect(env.CatchOutput(&buf).Execute("transfer", "verified", "--verified", VERIFIED_FILE, ARCH+"//verified:v1")).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("2 component version(s) transferred to " + ARCH + "//verified:v1@sha256:"))
+
+		buf.Reset()
+		Expect(env.CatchOutput(&buf).Execute("add", "verified", "--verified", IMPORT_FILE, "--force", ARCH+"//verified:v1")).To(Succeed())
+		Expect(buf.String()).To(StringEqualTrimmedWithContext(`
+merged acme.org/compa:v1
+merged acme.org/compb:v1
+2 component version(s) merged
+`))
+		s := Must(signing.NewVerifiedStore(IMPORT_FILE, env))
+		Expect(s.GetEntry(descriptor(COMPONENTB)).Signatures).To(Equal([]string{"b"}))
+	})
+})
@@ -4,13 +4,17 @@ import (
 	"github.com/spf13/cobra"
 
 	clictx "ocm.software/ocm/api/cli"
+	credentials "ocm.software/ocm/cmds/ocm/commands/misccmds/credentials/add"
+	attestations "ocm.software/ocm/cmds/ocm/commands/ocmcmds/attestations/add"
 	components "ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/add"
 	references "ocm.software/ocm/cmds/ocm/commands/ocmcmds/references/add"
 	resourceconfig "ocm.software/ocm/cmds/ocm/commands/ocmcmds/resourceconfig/add"
 	resources "ocm.software/ocm/cmds/ocm/commands/ocmcmds/resources/add"
 	routingslips "ocm.software/ocm/cmds/ocm/commands/ocmcmds/routingslips/add"
+	signingrequests "ocm.software/ocm/cmds/ocm/commands/ocmcmds/signingrequests/add"
 	sourceconfig "ocm.software/ocm/cmds/ocm/commands/ocmcmds/sourceconfig/add"
 	sources "ocm.software/ocm/cmds/ocm/commands/ocmcmds/sources/add"
+	verified "ocm.software/ocm/cmds/ocm/commands/ocmcmds/verified/add"
 	"ocm.software/ocm/cmds/ocm/commands/verbs"
 	"ocm.software/ocm/cmds/ocm/common/utils"
 )
@@ -28,5 +32,9 @@ func NewCommand(ctx clictx.Context) *cobra.Command {
 	cmd.AddCommand(references.NewCommand(ctx))
 	cmd.AddCommand(components.NewCommand(ctx))
 	cmd.AddCommand(routingslips.NewCommand(ctx))
+	cmd.AddCommand(signingrequests.NewCommand(ctx))
+	cmd.AddCommand(attestations.NewCommand(ctx))
+	cmd.AddCommand(verified.NewCommand(ctx))
+	cmd.AddCommand(credentials.NewCommand(ctx))
 	return cmd
 }
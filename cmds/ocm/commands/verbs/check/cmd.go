@@ -5,6 +5,7 @@ import (
 
 	clictx "ocm.software/ocm/api/cli"
 	components "ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/check"
+	verified "ocm.software/ocm/cmds/ocm/commands/ocmcmds/verified/check"
 	"ocm.software/ocm/cmds/ocm/commands/verbs"
 	"ocm.software/ocm/cmds/ocm/common/utils"
 )
@@ -12,8 +13,9 @@ import (
 // NewCommand creates a new command.
 func NewCommand(ctx clictx.Context) *cobra.Command {
 	cmd := utils.MassageCommand(&cobra.Command{
-		Short: "check components in OCM repository",
+		Short: "check components in OCM repository or verified resource digests",
 	}, verbs.Check)
 	cmd.AddCommand(components.NewCommand(ctx))
+	cmd.AddCommand(verified.NewCommand(ctx))
 	return cmd
 }
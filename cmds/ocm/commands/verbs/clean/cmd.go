@@ -5,6 +5,8 @@ import (
 
 	clictx "ocm.software/ocm/api/cli"
 	cache "ocm.software/ocm/cmds/ocm/commands/cachecmds/clean"
+	components "ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/clean"
+	verified "ocm.software/ocm/cmds/ocm/commands/ocmcmds/verified/clean"
 	"ocm.software/ocm/cmds/ocm/commands/verbs"
 	"ocm.software/ocm/cmds/ocm/common/utils"
 )
@@ -15,5 +17,7 @@ func NewCommand(ctx clictx.Context) *cobra.Command {
 		Short: "Cleanup/re-organize elements",
 	}, verbs.Clean)
 	cmd.AddCommand(cache.NewCommand(ctx))
+	cmd.AddCommand(components.NewCommand(ctx))
+	cmd.AddCommand(verified.NewCommand(ctx))
 	return cmd
 }
@@ -0,0 +1,19 @@
+package compact
+
+import (
+	"github.com/spf13/cobra"
+
+	clictx "ocm.software/ocm/api/cli"
+	ctf "ocm.software/ocm/cmds/ocm/commands/ocicmds/ctf/compact"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+// NewCommand creates a new command.
+func NewCommand(ctx clictx.Context) *cobra.Command {
+	cmd := utils.MassageCommand(&cobra.Command{
+		Short: "Compact storage elements",
+	}, verbs.Compact)
+	cmd.AddCommand(ctf.NewCommand(ctx))
+	return cmd
+}
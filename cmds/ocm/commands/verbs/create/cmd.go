@@ -4,9 +4,12 @@ import (
 	"github.com/spf13/cobra"
 
 	clictx "ocm.software/ocm/api/cli"
+	"ocm.software/ocm/cmds/ocm/commands/misccmds/keypair"
 	rsakeypair "ocm.software/ocm/cmds/ocm/commands/misccmds/rsakeypair"
 	ctf "ocm.software/ocm/cmds/ocm/commands/ocicmds/ctf/create"
+	attestations "ocm.software/ocm/cmds/ocm/commands/ocmcmds/attestations/create"
 	comparch "ocm.software/ocm/cmds/ocm/commands/ocmcmds/componentarchive/create"
+	signingrequests "ocm.software/ocm/cmds/ocm/commands/ocmcmds/signingrequests/create"
 	"ocm.software/ocm/cmds/ocm/commands/verbs"
 	"ocm.software/ocm/cmds/ocm/common/utils"
 )
@@ -20,5 +23,8 @@ func NewCommand(ctx clictx.Context) *cobra.Command {
 	cmd.AddCommand(comparch.NewCommand(ctx))
 	cmd.AddCommand(ctf.NewCommand(ctx))
 	cmd.AddCommand(rsakeypair.NewCommand(ctx))
+	cmd.AddCommand(keypair.NewCommand(ctx))
+	cmd.AddCommand(signingrequests.NewCommand(ctx))
+	cmd.AddCommand(attestations.NewCommand(ctx))
 	return cmd
 }
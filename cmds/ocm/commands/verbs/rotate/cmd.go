@@ -0,0 +1,19 @@
+package rotate
+
+import (
+	"github.com/spf13/cobra"
+
+	clictx "ocm.software/ocm/api/cli"
+	credentials "ocm.software/ocm/cmds/ocm/commands/misccmds/credentials/rotate"
+	"ocm.software/ocm/cmds/ocm/commands/verbs"
+	"ocm.software/ocm/cmds/ocm/common/utils"
+)
+
+// NewCommand creates a new command.
+func NewCommand(ctx clictx.Context) *cobra.Command {
+	cmd := utils.MassageCommand(&cobra.Command{
+		Short: "Rotate secrets",
+	}, verbs.Rotate)
+	cmd.AddCommand(credentials.NewCommand(ctx))
+	return cmd
+}
@@ -6,6 +6,7 @@ import (
 	clictx "ocm.software/ocm/api/cli"
 	"ocm.software/ocm/cmds/ocm/commands/misccmds/hash/sign"
 	components "ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/sign"
+	signingrequests "ocm.software/ocm/cmds/ocm/commands/ocmcmds/signingrequests/sign"
 	"ocm.software/ocm/cmds/ocm/commands/verbs"
 	"ocm.software/ocm/cmds/ocm/common/utils"
 )
@@ -13,9 +14,10 @@ import (
 // NewCommand creates a new command.
 func NewCommand(ctx clictx.Context) *cobra.Command {
 	cmd := utils.MassageCommand(&cobra.Command{
-		Short: "Sign components or hashes",
+		Short: "Sign components, hashes or signing requests",
 	}, verbs.Sign)
 	cmd.AddCommand(components.NewCommand(ctx))
 	cmd.AddCommand(sign.NewCommand(ctx))
+	cmd.AddCommand(signingrequests.NewCommand(ctx))
 	return cmd
 }
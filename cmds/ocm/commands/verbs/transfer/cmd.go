@@ -8,6 +8,7 @@ import (
 	comparch "ocm.software/ocm/cmds/ocm/commands/ocmcmds/componentarchive/transfer"
 	components "ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/transfer"
 	ctf "ocm.software/ocm/cmds/ocm/commands/ocmcmds/ctf/transfer"
+	verified "ocm.software/ocm/cmds/ocm/commands/ocmcmds/verified/transfer"
 	"ocm.software/ocm/cmds/ocm/commands/verbs"
 	"ocm.software/ocm/cmds/ocm/common/utils"
 )
@@ -22,6 +23,7 @@ func NewCommand(ctx clictx.Context) *cobra.Command {
 	cmd.AddCommand(artifacts.NewCommand(ctx))
 	cmd.AddCommand(components.NewCommand(ctx))
 	cmd.AddCommand(ctf.NewCommand(ctx))
+	cmd.AddCommand(verified.NewCommand(ctx))
 
 	return cmd
 }
@@ -7,6 +7,7 @@ const (
 	Check     = "check"
 	Describe  = "describe"
 	Hash      = "hash"
+	Diff      = "diff"
 	Add       = "add"
 	Create    = "create"
 	Transfer  = "transfer"
@@ -14,9 +15,12 @@ const (
 	Bootstrap = "bootstrap"
 	Show      = "show"
 	Sign      = "sign"
+	Resign    = "resign"
 	Verify    = "verify"
 	Clean     = "clean"
+	Compact   = "compact"
 	Install   = "install"
 	Uninstall = "uninstall"
 	Execute   = "execute"
+	Rotate    = "rotate"
 )
@@ -4,6 +4,7 @@ import (
 	"github.com/spf13/cobra"
 
 	clictx "ocm.software/ocm/api/cli"
+	attestations "ocm.software/ocm/cmds/ocm/commands/ocmcmds/attestations/verify"
 	components "ocm.software/ocm/cmds/ocm/commands/ocmcmds/components/verify"
 	"ocm.software/ocm/cmds/ocm/commands/verbs"
 	"ocm.software/ocm/cmds/ocm/common/utils"
@@ -12,8 +13,9 @@ import (
 // NewCommand creates a new command.
 func NewCommand(ctx clictx.Context) *cobra.Command {
 	cmd := utils.MassageCommand(&cobra.Command{
-		Short: "Verify component version signatures",
+		Short: "Verify component version signatures and attestations",
 	}, verbs.Verify)
 	cmd.AddCommand(components.NewCommand(ctx))
+	cmd.AddCommand(attestations.NewCommand(ctx))
 	return cmd
 }